@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/doug-martin/goqu/v9"
 	"github.com/go-chi/chi/v5"
@@ -9,11 +10,16 @@ import (
 	"go.uber.org/dig"
 
 	"github.com/tusmasoma/campfinder/docker/back/config"
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
 	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
 	"github.com/tusmasoma/campfinder/docker/back/infra/mysql"
 	"github.com/tusmasoma/campfinder/docker/back/infra/redis"
 	"github.com/tusmasoma/campfinder/docker/back/interfaces/handler"
 	"github.com/tusmasoma/campfinder/docker/back/interfaces/middleware"
+	"github.com/tusmasoma/campfinder/docker/back/internal/auth"
+	"github.com/tusmasoma/campfinder/docker/back/internal/mail"
+	"github.com/tusmasoma/campfinder/docker/back/internal/oidc"
+	"github.com/tusmasoma/campfinder/docker/back/internal/storage"
 	"github.com/tusmasoma/campfinder/docker/back/usecase"
 )
 
@@ -28,6 +34,16 @@ func BuildContainer(ctx context.Context) (*dig.Container, error) {
 
 	providers := []interface{}{
 		config.NewServerConfig,
+		config.NewMailConfig,
+		config.NewLoginThrottleConfig,
+		config.NewOIDCConfig,
+		config.NewTwoFactorConfig,
+		config.NewStorageConfig,
+		config.NewModerationConfig,
+		mail.NewMailer,
+		storage.NewBlobStore,
+		oidc.NewProviders,
+		auth.DefaultKeyManager,
 		providerSQLExecutor,
 		config.NewClient,
 		provideMySQLDialect,
@@ -35,58 +51,122 @@ func BuildContainer(ctx context.Context) (*dig.Container, error) {
 		mysql.NewSpotRepository,
 		mysql.NewCommentRepository,
 		mysql.NewImageRepository,
+		mysql.NewImageReportRepository,
+		mysql.NewSpotOwnerRepository,
+		mysql.NewIdentityRepository,
+		mysql.NewRecoveryCodeRepository,
 		redis.NewSpotsRepository,
+		redis.NewSpotClustersRepository,
 		redis.NewUserRepository,
 		redis.NewCommentsRepository,
 		redis.NewImagesRepository,
+		redis.NewLoginAttemptRepository,
+		redis.NewOIDCStateRepository,
+		redis.NewTwoFactorRepository,
 		usecase.NewUserUseCase,
 		usecase.NewSpotUseCase,
 		usecase.NewCommentUseCase,
 		usecase.NewImageUseCase,
 		usecase.NewAuthUseCase,
+		usecase.NewAdminUseCase,
+		usecase.NewOIDCUseCase,
+		usecase.NewProfileUseCase,
 		handler.NewUserHandler,
 		handler.NewSpotHandler,
 		handler.NewCommentHandler,
 		handler.NewImageHandler,
+		handler.NewJWKSHandler,
+		handler.NewAdminHandler,
+		handler.NewOIDCHandler,
+		handler.NewProfileHandler,
 		middleware.NewAuthMiddleware,
+		middleware.NewAuthorizationMiddleware,
+		middleware.NewClientIPMiddleware,
 		func(
 			serverConfig *config.ServerConfig,
+			storageConfig *config.StorageConfig,
 			userHandler handler.UserHandler,
 			spotHandler handler.SpotHandler,
 			commentHandler handler.CommentHandler,
 			imgHandler handler.ImageHandler,
+			jwksHandler handler.JWKSHandler,
+			adminHandler handler.AdminHandler,
+			oidcHandler handler.OIDCHandler,
+			profileHandler handler.ProfileHandler,
 			authMiddleware middleware.AuthMiddleware,
+			authzMiddleware middleware.AuthorizationMiddleware,
+			clientIPMiddleware middleware.ClientIPMiddleware,
 		) *chi.Mux {
 			r := chi.NewRouter()
 			r.Use(cors.Handler(cors.Options{
 				AllowedOrigins:   []string{"https://*", "http://*"},
-				AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE"},
+				AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
 				AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "Origin"},
 				ExposedHeaders:   []string{"Link", "Authorization"},
 				AllowCredentials: false,
 				MaxAge:           serverConfig.PreflightCacheDurationSec,
 			}))
 			r.Use(middleware.Logging)
+			r.Use(clientIPMiddleware.ClientIP)
+
+			r.Get("/.well-known/jwks.json", jwksHandler.GetJWKS)
+			// ローカルに保存した画像はこのサーバから配信する
+			if storageConfig.Driver == storage.DriverLocal {
+				r.Handle("/uploads/*", http.StripPrefix("/uploads", storage.NewLocalFileServer(storageConfig.LocalDir)))
+			}
 
 			r.Route("/api", func(r chi.Router) {
 				r.Route("/user", func(r chi.Router) {
 					r.Post("/create", userHandler.CreateUser)
 					r.Post("/login", userHandler.Login)
+					r.Post("/refresh", userHandler.RefreshToken)
+					r.Post("/login/2fa", userHandler.VerifyTwoFactorLogin)
+					r.Post("/verify-email", userHandler.VerifyEmail)
+					r.Post("/password/forgot", userHandler.ForgotPassword)
+					r.Post("/password/reset", userHandler.ResetPassword)
+					r.Get("/oidc/{provider}/login", oidcHandler.Login)
+					r.Get("/oidc/{provider}/callback", oidcHandler.Callback)
+					r.Get("/{userID}/profile", profileHandler.GetPublicProfile)
 					r.Group(func(r chi.Router) {
 						r.Use(authMiddleware.Authenticate)
 						r.Get("/api/user/logout", userHandler.Logout)
+						r.Get("/sessions", userHandler.ListSessions)
+						r.Delete("/sessions", userHandler.RevokeAllSessions)
+						r.Delete("/sessions/{sessionID}", userHandler.RevokeSession)
+						r.Post("/verify-email/resend", userHandler.ResendVerificationEmail)
+						r.Get("/me", profileHandler.GetMe)
+						r.Put("/me", profileHandler.UpdateMe)
+						r.Delete("/me", profileHandler.DeleteMe)
+						r.Put("/me/password", profileHandler.ChangePassword)
+						r.Post("/2fa/setup", userHandler.SetupTwoFactor)
+						r.Post("/2fa/enable", userHandler.EnableTwoFactor)
+						r.Post("/2fa/disable", userHandler.DisableTwoFactor)
+						r.Post("/2fa/recovery-codes", userHandler.RegenerateRecoveryCodes)
 					})
 				})
 
 				r.Route("/spot", func(r chi.Router) {
 					r.Get("/", spotHandler.ListSpots)
+					r.Get("/nearby", spotHandler.ListNearbySpots)
+					r.Get("/search", spotHandler.SearchSpots)
+					r.Get("/export", spotHandler.ExportSpots)
+					r.Get("/tiles/{z}/{x}/{y}", spotHandler.GetSpotTile)
 					r.Get("/{spotID}", spotHandler.GetSpot)
-					r.Post("/create", spotHandler.CreateSpot)
-					r.Post("/batchcreate", spotHandler.BatchCreateSpots)
+					r.Group(func(r chi.Router) {
+						r.Use(authMiddleware.Authenticate)
+						r.Use(authzMiddleware.RequireRole(model.RoleContributor))
+						r.Post("/create", spotHandler.CreateSpot)
+						r.Post("/batchcreate", spotHandler.BatchCreateSpots)
+						r.Post("/import", spotHandler.ImportSpots)
+						r.Put("/{spotID}", spotHandler.UpdateSpot)
+						r.Patch("/{spotID}", spotHandler.PatchSpot)
+						r.Delete("/{spotID}", spotHandler.DeleteSpot)
+					})
 				})
 
 				r.Route("/comment", func(r chi.Router) {
 					r.Get("/", commentHandler.ListComments)
+					r.Get("/{commentID}/replies", commentHandler.ListReplies)
 					r.Group(func(r chi.Router) {
 						r.Use(authMiddleware.Authenticate)
 						r.Post("/create", commentHandler.CreateComment)
@@ -95,14 +175,39 @@ func BuildContainer(ctx context.Context) (*dig.Container, error) {
 					})
 				})
 
+				r.Route("/admin", func(r chi.Router) {
+					r.Use(authMiddleware.Authenticate)
+					r.Use(authzMiddleware.RequireRole(model.RoleAdmin))
+					r.Get("/login-lockouts", adminHandler.ListLoginLockouts)
+					r.Get("/users", adminHandler.ListUsers)
+					r.Put("/users/{userID}/role", adminHandler.UpdateUserRole)
+					r.Post("/users/{userID}/suspend", adminHandler.SuspendUser)
+					r.Delete("/users/{userID}/suspend", adminHandler.UnsuspendUser)
+					r.Post("/users/{userID}/password-reset", adminHandler.ForcePasswordReset)
+					r.Put("/spots/{spotID}/owners/{userID}", adminHandler.AssignSpotOwner)
+					r.Delete("/spots/{spotID}/owners/{userID}", adminHandler.RemoveSpotOwner)
+				})
+
 				r.Route("/img", func(r chi.Router) {
 					r.Get("/", imgHandler.ListImages)
 					r.Group(func(r chi.Router) {
 						r.Use(authMiddleware.Authenticate)
 						r.Post("/create", imgHandler.CreateImage)
+						r.Post("/upload", imgHandler.UploadImage)
+						r.Post("/upload-url", imgHandler.CreateUploadURL)
+						r.Post("/upload-complete", imgHandler.CompleteUpload)
 						r.Post("/delete", imgHandler.DeleteImage)
+						r.Post("/report", imgHandler.ReportImage)
 					})
 				})
+
+				r.Route("/moderation", func(r chi.Router) {
+					r.Use(authMiddleware.Authenticate)
+					r.Use(authzMiddleware.RequireRole(model.RoleModerator))
+					r.Get("/images", imgHandler.ListModerationQueue)
+					r.Post("/images/{imageID}/approve", imgHandler.ApproveImage)
+					r.Post("/images/{imageID}/reject", imgHandler.RejectImage)
+				})
 			})
 			return r
 		},
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tusmasoma/campfinder/docker/back/internal/spotimport"
+	"github.com/tusmasoma/campfinder/docker/back/usecase"
+)
+
+// runImport は `import -file spots.csv [-format csv|geojson] [-mapping name=施設名,...]` を実行し、
+// 取り込み結果のレポートをJSONで標準出力に書き出します。
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := fs.String("file", "", "path to csv or geojson file")
+	format := fs.String("format", "", "csv or geojson (default: detected from file extension)")
+	mappingArg := fs.String("mapping", "", "column mapping such as name=施設名,address=住所")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errors.New("-file is required")
+	}
+
+	importFormat := spotimport.Format(strings.ToLower(*format))
+	if importFormat == "" {
+		switch strings.ToLower(filepath.Ext(*file)) {
+		case ".csv":
+			importFormat = spotimport.FormatCSV
+		case ".geojson", ".json":
+			importFormat = spotimport.FormatGeoJSON
+		}
+	}
+	mapping, err := spotimport.ParseColumnMapping(*mappingArg)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	container, err := BuildContainer(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to build container: %w", err)
+	}
+
+	return container.Invoke(func(suc usecase.SpotUseCase) error {
+		report, importErr := suc.ImportSpots(ctx, &usecase.ImportSpotsParams{
+			Format:  importFormat,
+			Data:    f,
+			Mapping: mapping,
+		})
+		if importErr != nil {
+			return importErr
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	})
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/joho/godotenv"
 
 	"github.com/tusmasoma/campfinder/docker/back/config"
+	"github.com/tusmasoma/campfinder/docker/back/internal/auth"
 )
 
 func main() {
@@ -22,6 +23,15 @@ func main() {
 		log.Println("No .env file found")
 	}
 
+	// サブコマンド import はサーバを起動せずにファイルからSpotを一括登録する
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		if err := runImport(os.Args[2:]); err != nil {
+			log.Printf("Failed to import spots: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var addr string
 	flag.StringVar(&addr, "addr", ":8083", "tcp host:port to connect")
 	flag.Parse()
@@ -36,7 +46,10 @@ func main() {
 	}
 
 	/* ===== サーバの設定 ===== */
-	err = container.Invoke(func(router *chi.Mux, config *config.ServerConfig) {
+	err = container.Invoke(func(router *chi.Mux, config *config.ServerConfig, km *auth.KeyManager) {
+		// SIGHUPか鍵ファイルの更新で署名鍵を読み込み直す
+		go km.Watch(mainCtx, config.KeyReloadInterval)
+
 		srv := &http.Server{
 			Addr:         addr,
 			Handler:      router,
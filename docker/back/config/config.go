@@ -2,6 +2,8 @@ package config
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/sethvargo/go-envconfig"
@@ -11,6 +13,12 @@ const (
 	dbPrefix     = "MYSQL_"
 	cachePrefix  = "REDIS_"
 	serverPrefix = "SERVER_"
+	mailPrefix   = "MAIL_"
+	loginPrefix  = "LOGIN_"
+	oidcPrefix   = "OIDC_"
+	tfaPrefix    = "TWO_FACTOR_"
+	storePrefix  = "STORAGE_"
+	modPrefix    = "MODERATION_"
 )
 
 type DBConfig struct {
@@ -33,6 +41,83 @@ type ServerConfig struct {
 	IdleTimeout               time.Duration `env:"IDLE_TIMEOUT,default=15s"`
 	GracefulShutdownTimeout   time.Duration `env:"GRACEFUL_SHUTDOWN_TIMEOUT,default=5s"`
 	PreflightCacheDurationSec int           `env:"PREFLIGHT_CACHE_DURATION_SEC,default=300"`
+	// KeyReloadInterval は署名鍵のファイルが更新されたかを確認する間隔です。
+	KeyReloadInterval time.Duration `env:"KEY_RELOAD_INTERVAL,default=30s"`
+	// TrustedProxies はX-Forwarded-Forを信頼するリバースプロキシのIPアドレスまたはCIDRです。
+	// 空の場合はX-Forwarded-Forを使わず、接続元のアドレスをクライアントのIPアドレスとします。
+	TrustedProxies []string `env:"TRUSTED_PROXIES"`
+}
+
+// MailConfig はメール送信の設定です。Driverはlogかfileで、fileの場合はDirにメールを1通ずつ書き出します。
+// AppBaseURLはメール本文に記載するリンクのベースURLです。
+type MailConfig struct {
+	Driver     string `env:"DRIVER,default=log"`
+	Dir        string `env:"DIR,default=./tmp/mail"`
+	From       string `env:"FROM,default=no-reply@campfinder.local"`
+	AppBaseURL string `env:"APP_BASE_URL,default=http://localhost:3000"`
+}
+
+// LoginThrottleConfig はログインの総当たり対策の設定です。失敗するたびにBaseDelayから倍々に(MaxDelayまで)
+// 次の試行を待たせ、FailureWindow内の失敗がMaxFailures(IPアドレスごとはIPMaxFailures)に達するとLockoutDurationの間ロックします。
+type LoginThrottleConfig struct {
+	MaxFailures     int64         `env:"MAX_FAILURES,default=5"`
+	IPMaxFailures   int64         `env:"IP_MAX_FAILURES,default=20"`
+	BaseDelay       time.Duration `env:"BASE_DELAY,default=1s"`
+	MaxDelay        time.Duration `env:"MAX_DELAY,default=1m"`
+	LockoutDuration time.Duration `env:"LOCKOUT_DURATION,default=15m"`
+	FailureWindow   time.Duration `env:"FAILURE_WINDOW,default=15m"`
+}
+
+// OIDCConfig はソーシャルログインに使うOpenID Connectのプロバイダの設定です。
+// OIDC_PROVIDERSにプロバイダ名をカンマ区切りで指定し、各プロバイダはOIDC_<NAME>_ISSUERのように設定します。
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig
+}
+
+// OIDCProviderConfig のRedirectURLは、IDプロバイダに登録したこのサーバのコールバックURLです。
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string   `env:"ISSUER, required"`
+	ClientID     string   `env:"CLIENT_ID, required"`
+	ClientSecret string   `env:"CLIENT_SECRET"`
+	RedirectURL  string   `env:"REDIRECT_URL, required"`
+	Scopes       []string `env:"SCOPES,default=openid,email,profile"`
+}
+
+// TwoFactorConfig は2段階認証の設定です。Issuerは認証アプリに表示されるサービス名で、
+// RequireForAdminsを有効にすると、2段階認証を設定していない管理者はロールが必要な操作をできません。
+type TwoFactorConfig struct {
+	Issuer           string `env:"ISSUER,default=CampFinder"`
+	RequireForAdmins bool   `env:"REQUIRE_FOR_ADMINS,default=true"`
+}
+
+// StorageConfig はアップロードした画像の保存先の設定です。Driverはlocalかs3で、localの場合はLocalDirに保存し、
+// PublicBaseURLの下で配信します。s3の場合はS3互換のオブジェクトストレージ(MinIOなど)にパス形式でアクセスし、
+// PublicBaseURLが空ならS3Endpoint/S3Bucket/<key>を画像のURLにします。
+// S3PresignEndpointはクライアントに渡す署名付きURLのエンドポイントで、空ならS3Endpointを使います。
+type StorageConfig struct {
+	Driver            string `env:"DRIVER,default=local"`
+	LocalDir          string `env:"LOCAL_DIR,default=./tmp/uploads"`
+	PublicBaseURL     string `env:"PUBLIC_BASE_URL,default=http://localhost:8083/uploads"`
+	S3Endpoint        string `env:"S3_ENDPOINT"`
+	S3Region          string `env:"S3_REGION,default=us-east-1"`
+	S3Bucket          string `env:"S3_BUCKET"`
+	S3AccessKeyID     string `env:"S3_ACCESS_KEY_ID"`
+	S3SecretAccessKey string `env:"S3_SECRET_ACCESS_KEY"`
+	S3PresignEndpoint string `env:"S3_PRESIGN_ENDPOINT"`
+	// MaxUploadSize はアップロードできる画像1枚の最大バイト数です。
+	MaxUploadSize int64 `env:"MAX_UPLOAD_SIZE,default=10485760"`
+	// PresignExpiry は直接アップロードするための署名付きURLの有効期間です。
+	PresignExpiry time.Duration `env:"PRESIGN_EXPIRY,default=15m"`
+}
+
+// ModerationConfig は投稿された画像のモデレーションの方針です。
+type ModerationConfig struct {
+	// AutoApproveTrusted がtrueの場合、TrustedRole以上のロールのユーザの画像はモデレーションを待たずに公開します。
+	AutoApproveTrusted bool   `env:"AUTO_APPROVE_TRUSTED,default=true"`
+	TrustedRole        string `env:"TRUSTED_ROLE,default=contributor"`
+	// ReportThreshold は公開中の画像をモデレーション待ちに戻す通報の数です。0の場合は通報では非公開にしません。
+	ReportThreshold int `env:"REPORT_THRESHOLD,default=3"`
 }
 
 func NewDBConfig(ctx context.Context) (*DBConfig, error) {
@@ -61,3 +146,71 @@ func NewServerConfig(ctx context.Context) (*ServerConfig, error) {
 	}
 	return conf, nil
 }
+
+func NewMailConfig(ctx context.Context) (*MailConfig, error) {
+	conf := &MailConfig{}
+	pl := envconfig.PrefixLookuper(mailPrefix, envconfig.OsLookuper())
+	if err := envconfig.ProcessWith(ctx, conf, pl); err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
+
+func NewLoginThrottleConfig(ctx context.Context) (*LoginThrottleConfig, error) {
+	conf := &LoginThrottleConfig{}
+	pl := envconfig.PrefixLookuper(loginPrefix, envconfig.OsLookuper())
+	if err := envconfig.ProcessWith(ctx, conf, pl); err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
+
+func NewTwoFactorConfig(ctx context.Context) (*TwoFactorConfig, error) {
+	conf := &TwoFactorConfig{}
+	pl := envconfig.PrefixLookuper(tfaPrefix, envconfig.OsLookuper())
+	if err := envconfig.ProcessWith(ctx, conf, pl); err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
+
+func NewStorageConfig(ctx context.Context) (*StorageConfig, error) {
+	conf := &StorageConfig{}
+	pl := envconfig.PrefixLookuper(storePrefix, envconfig.OsLookuper())
+	if err := envconfig.ProcessWith(ctx, conf, pl); err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
+
+func NewModerationConfig(ctx context.Context) (*ModerationConfig, error) {
+	conf := &ModerationConfig{}
+	pl := envconfig.PrefixLookuper(modPrefix, envconfig.OsLookuper())
+	if err := envconfig.ProcessWith(ctx, conf, pl); err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
+
+func NewOIDCConfig(ctx context.Context) (*OIDCConfig, error) {
+	var names struct {
+		Providers []string `env:"PROVIDERS"`
+	}
+	lookuper := envconfig.PrefixLookuper(oidcPrefix, envconfig.OsLookuper())
+	if err := envconfig.ProcessWith(ctx, &names, lookuper); err != nil {
+		return nil, err
+	}
+
+	conf := &OIDCConfig{Providers: make([]OIDCProviderConfig, 0, len(names.Providers))}
+	for _, name := range names.Providers {
+		name = strings.ToLower(strings.TrimSpace(name))
+		provider := OIDCProviderConfig{Name: name}
+		prefix := oidcPrefix + strings.ToUpper(name) + "_"
+		pl := envconfig.PrefixLookuper(prefix, envconfig.OsLookuper())
+		if err := envconfig.ProcessWith(ctx, &provider, pl); err != nil {
+			return nil, fmt.Errorf("oidc provider %s: %w", name, err)
+		}
+		conf.Providers = append(conf.Providers, provider)
+	}
+	return conf, nil
+}
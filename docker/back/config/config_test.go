@@ -127,6 +127,7 @@ func Test_NewServerConfig(t *testing.T) {
 				IdleTimeout:               15 * time.Second,
 				GracefulShutdownTimeout:   5 * time.Second,
 				PreflightCacheDurationSec: 300,
+				KeyReloadInterval:         30 * time.Second,
 			},
 			err: nil,
 		},
@@ -139,6 +140,8 @@ func Test_NewServerConfig(t *testing.T) {
 				t.Setenv("SERVER_IDLE_TIMEOUT", "10s")
 				t.Setenv("SERVER_GRACEFUL_SHUTDOWN_TIMEOUT", "3s")
 				t.Setenv("SERVER_PREFLIGHT_CACHE_DURATION_SEC", "150")
+				t.Setenv("SERVER_KEY_RELOAD_INTERVAL", "1m")
+				t.Setenv("SERVER_TRUSTED_PROXIES", "10.0.0.1,172.16.0.0/12")
 			},
 			want: &ServerConfig{
 				ReadTimeout:               2 * time.Second,
@@ -146,6 +149,8 @@ func Test_NewServerConfig(t *testing.T) {
 				IdleTimeout:               10 * time.Second,
 				GracefulShutdownTimeout:   3 * time.Second,
 				PreflightCacheDurationSec: 150,
+				KeyReloadInterval:         time.Minute,
+				TrustedProxies:            []string{"10.0.0.1", "172.16.0.0/12"},
 			},
 		},
 	}
@@ -161,3 +166,295 @@ func Test_NewServerConfig(t *testing.T) {
 		})
 	}
 }
+
+func Test_NewMailConfig(t *testing.T) {
+	ctx := context.Background()
+
+	patterns := []struct {
+		name  string
+		setup func(t *testing.T)
+		want  *MailConfig
+	}{
+		{
+			name: "default",
+			setup: func(t *testing.T) {
+				t.Helper()
+			},
+			want: &MailConfig{
+				Driver:     "log",
+				Dir:        "./tmp/mail",
+				From:       "no-reply@campfinder.local",
+				AppBaseURL: "http://localhost:3000",
+			},
+		},
+		{
+			name: "set env",
+			setup: func(t *testing.T) {
+				t.Helper()
+				t.Setenv("MAIL_DRIVER", "file")
+				t.Setenv("MAIL_DIR", "/var/mail/campfinder")
+				t.Setenv("MAIL_FROM", "support@campfinder.example")
+				t.Setenv("MAIL_APP_BASE_URL", "https://campfinder.example")
+			},
+			want: &MailConfig{
+				Driver:     "file",
+				Dir:        "/var/mail/campfinder",
+				From:       "support@campfinder.example",
+				AppBaseURL: "https://campfinder.example",
+			},
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup(t)
+
+			got, err := NewMailConfig(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_NewStorageConfig(t *testing.T) {
+	ctx := context.Background()
+
+	patterns := []struct {
+		name  string
+		setup func(t *testing.T)
+		want  *StorageConfig
+	}{
+		{
+			name: "default",
+			setup: func(t *testing.T) {
+				t.Helper()
+			},
+			want: &StorageConfig{
+				Driver:        "local",
+				LocalDir:      "./tmp/uploads",
+				PublicBaseURL: "http://localhost:8083/uploads",
+				S3Region:      "us-east-1",
+				MaxUploadSize: 10 << 20,
+				PresignExpiry: 15 * time.Minute,
+			},
+		},
+		{
+			name: "set env",
+			setup: func(t *testing.T) {
+				t.Helper()
+				t.Setenv("STORAGE_DRIVER", "s3")
+				t.Setenv("STORAGE_PUBLIC_BASE_URL", "")
+				t.Setenv("STORAGE_S3_ENDPOINT", "http://minio:9000")
+				t.Setenv("STORAGE_S3_REGION", "ap-northeast-1")
+				t.Setenv("STORAGE_S3_BUCKET", "campfinder")
+				t.Setenv("STORAGE_S3_ACCESS_KEY_ID", "minioadmin")
+				t.Setenv("STORAGE_S3_SECRET_ACCESS_KEY", "miniosecret")
+				t.Setenv("STORAGE_S3_PRESIGN_ENDPOINT", "https://storage.campfinder.example")
+				t.Setenv("STORAGE_MAX_UPLOAD_SIZE", "1048576")
+				t.Setenv("STORAGE_PRESIGN_EXPIRY", "5m")
+			},
+			want: &StorageConfig{
+				Driver:            "s3",
+				LocalDir:          "./tmp/uploads",
+				S3Endpoint:        "http://minio:9000",
+				S3Region:          "ap-northeast-1",
+				S3Bucket:          "campfinder",
+				S3AccessKeyID:     "minioadmin",
+				S3SecretAccessKey: "miniosecret",
+				S3PresignEndpoint: "https://storage.campfinder.example",
+				MaxUploadSize:     1 << 20,
+				PresignExpiry:     5 * time.Minute,
+			},
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup(t)
+
+			got, err := NewStorageConfig(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_NewModerationConfig(t *testing.T) {
+	ctx := context.Background()
+
+	patterns := []struct {
+		name  string
+		setup func(t *testing.T)
+		want  *ModerationConfig
+	}{
+		{
+			name: "default",
+			setup: func(t *testing.T) {
+				t.Helper()
+			},
+			want: &ModerationConfig{
+				AutoApproveTrusted: true,
+				TrustedRole:        "contributor",
+				ReportThreshold:    3,
+			},
+		},
+		{
+			name: "set env",
+			setup: func(t *testing.T) {
+				t.Helper()
+				t.Setenv("MODERATION_AUTO_APPROVE_TRUSTED", "false")
+				t.Setenv("MODERATION_TRUSTED_ROLE", "moderator")
+				t.Setenv("MODERATION_REPORT_THRESHOLD", "0")
+			},
+			want: &ModerationConfig{
+				AutoApproveTrusted: false,
+				TrustedRole:        "moderator",
+				ReportThreshold:    0,
+			},
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup(t)
+
+			got, err := NewModerationConfig(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_NewLoginThrottleConfig(t *testing.T) {
+	ctx := context.Background()
+
+	patterns := []struct {
+		name  string
+		setup func(t *testing.T)
+		want  *LoginThrottleConfig
+	}{
+		{
+			name: "default",
+			setup: func(t *testing.T) {
+				t.Helper()
+			},
+			want: &LoginThrottleConfig{
+				MaxFailures:     5,
+				IPMaxFailures:   20,
+				BaseDelay:       time.Second,
+				MaxDelay:        time.Minute,
+				LockoutDuration: 15 * time.Minute,
+				FailureWindow:   15 * time.Minute,
+			},
+		},
+		{
+			name: "set env",
+			setup: func(t *testing.T) {
+				t.Helper()
+				t.Setenv("LOGIN_MAX_FAILURES", "3")
+				t.Setenv("LOGIN_IP_MAX_FAILURES", "50")
+				t.Setenv("LOGIN_BASE_DELAY", "500ms")
+				t.Setenv("LOGIN_MAX_DELAY", "30s")
+				t.Setenv("LOGIN_LOCKOUT_DURATION", "1h")
+				t.Setenv("LOGIN_FAILURE_WINDOW", "30m")
+			},
+			want: &LoginThrottleConfig{
+				MaxFailures:     3,
+				IPMaxFailures:   50,
+				BaseDelay:       500 * time.Millisecond,
+				MaxDelay:        30 * time.Second,
+				LockoutDuration: time.Hour,
+				FailureWindow:   30 * time.Minute,
+			},
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup(t)
+
+			got, err := NewLoginThrottleConfig(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_NewOIDCConfig(t *testing.T) {
+	ctx := context.Background()
+
+	patterns := []struct {
+		name  string
+		setup func(t *testing.T)
+		want  *OIDCConfig
+		err   error
+	}{
+		{
+			name: "default",
+			setup: func(t *testing.T) {
+				t.Helper()
+			},
+			want: &OIDCConfig{Providers: []OIDCProviderConfig{}},
+		},
+		{
+			name: "set env",
+			setup: func(t *testing.T) {
+				t.Helper()
+				t.Setenv("OIDC_PROVIDERS", "google,Example")
+				t.Setenv("OIDC_GOOGLE_ISSUER", "https://accounts.google.com")
+				t.Setenv("OIDC_GOOGLE_CLIENT_ID", "client-id")
+				t.Setenv("OIDC_GOOGLE_CLIENT_SECRET", "client-secret")
+				t.Setenv("OIDC_GOOGLE_REDIRECT_URL", "https://campfinder.example/api/user/oidc/google/callback")
+				t.Setenv("OIDC_EXAMPLE_ISSUER", "https://idp.example")
+				t.Setenv("OIDC_EXAMPLE_CLIENT_ID", "example-client")
+				t.Setenv("OIDC_EXAMPLE_REDIRECT_URL", "https://campfinder.example/api/user/oidc/example/callback")
+				t.Setenv("OIDC_EXAMPLE_SCOPES", "openid,email")
+			},
+			want: &OIDCConfig{Providers: []OIDCProviderConfig{
+				{
+					Name:         "google",
+					Issuer:       "https://accounts.google.com",
+					ClientID:     "client-id",
+					ClientSecret: "client-secret",
+					RedirectURL:  "https://campfinder.example/api/user/oidc/google/callback",
+					Scopes:       []string{"openid", "email", "profile"},
+				},
+				{
+					Name:        "example",
+					Issuer:      "https://idp.example",
+					ClientID:    "example-client",
+					RedirectURL: "https://campfinder.example/api/user/oidc/example/callback",
+					Scopes:      []string{"openid", "email"},
+				},
+			}},
+		},
+		{
+			name: "missing issuer",
+			setup: func(t *testing.T) {
+				t.Helper()
+				t.Setenv("OIDC_PROVIDERS", "google")
+				t.Setenv("OIDC_GOOGLE_CLIENT_ID", "client-id")
+				t.Setenv("OIDC_GOOGLE_REDIRECT_URL", "https://campfinder.example/api/user/oidc/google/callback")
+			},
+			want: nil,
+			err:  envconfig.ErrMissingRequired,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup(t)
+
+			got, err := NewOIDCConfig(ctx)
+			if err != nil {
+				require.ErrorIs(t, err, tt.err)
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -2,4 +2,8 @@ package config
 
 type ContextKey string
 
-const ContextUserIDKey ContextKey = "userID"
+const (
+	ContextUserIDKey    ContextKey = "userID"
+	ContextSessionIDKey ContextKey = "sessionID"
+	ContextClientIPKey  ContextKey = "clientIP"
+)
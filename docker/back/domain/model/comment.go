@@ -6,13 +6,37 @@ import (
 	"github.com/google/uuid"
 )
 
+// MaxCommentDepth はコメントの返信を入れ子にできる深さです。レビューが0で、レビューへの返信が1になります。
+const MaxCommentDepth = 3
+
+// CommentKind はコメントの種類です。
+type CommentKind string
+
+const (
+	// CommentKindReview はSpotへのレビューで、評価(StarRate)を持つのはレビューのみです。
+	CommentKindReview CommentKind = "review"
+	CommentKindReply  CommentKind = "reply"
+	// CommentKindOwnerResponse はSpotの管理者として確認されたユーザによる返信です。
+	CommentKindOwnerResponse CommentKind = "owner_response"
+)
+
 type Comment struct {
 	ID       uuid.UUID `db:"id"`
 	SpotID   uuid.UUID `db:"spot_id"`
 	UserID   uuid.UUID `db:"user_id"`
 	StarRate float64   `db:"star_rate" json:"starRate"`
 	Text     string    `db:"text" json:"text"`
-	Created  time.Time `db:"-"`
+	Created  time.Time `db:"created" goqu:"skipinsert,skipupdate"`
+	// ParentID は返信先のコメントで、RootIDはスレッドの起点のレビューです。レビューではどちらもNULLです。
+	ParentID uuid.NullUUID `db:"parent_id" json:"parentID"`
+	RootID   uuid.NullUUID `db:"root_id" json:"rootID"`
+	Depth    int           `db:"depth" json:"depth"`
+	Kind     CommentKind   `db:"kind" json:"kind"`
+}
+
+// IsReply はコメントがレビューではなく返信かどうかを返します。
+func (c Comment) IsReply() bool {
+	return c.ParentID.Valid
 }
 
 type Comments []Comment
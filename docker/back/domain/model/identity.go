@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Identity は外部のIDプロバイダのアカウントとユーザの紐付けです。SubjectはIDプロバイダ内で一意なユーザIDです。
+type Identity struct {
+	ID       uuid.UUID `db:"id"`
+	UserID   uuid.UUID `db:"user_id"`
+	Provider string    `db:"provider"`
+	Subject  string    `db:"subject"`
+	// Email は紐付けた時点でIDプロバイダから受け取ったメールアドレスです。
+	Email   string    `db:"email"`
+	Created time.Time `db:"created" goqu:"skipinsert,skipupdate"`
+}
+
+// OIDCAuthState は認可リクエストのstateに紐づけて保存し、コールバックで検証に使う情報です。
+type OIDCAuthState struct {
+	Provider     string    `json:"provider"`
+	Nonce        string    `json:"nonce"`
+	CodeVerifier string    `json:"codeVerifier"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
@@ -11,7 +11,51 @@ type Image struct {
 	SpotID  uuid.UUID `db:"spot_id"`
 	UserID  uuid.UUID `db:"user_id"`
 	URL     string    `db:"url"`
-	Created time.Time `db:"-"`
+	Created time.Time `db:"created" goqu:"skipinsert,skipupdate"`
+	// StorageKey はアップロードされた画像のBlobStore上のキーです。URLだけを登録した画像では空です。
+	StorageKey string `db:"storage_key"`
+	// Width, Height は向きを補正した元の画像の大きさです。
+	Width  int `db:"width"`
+	Height int `db:"height"`
+	// ThumbnailURL, MediumURL, LargeURL はJPEGの縮小版のURLで、*WebPURLは同じ大きさのWebPの縮小版のURLです。
+	// BlurHashは読み込み中に表示するプレースホルダです。アップロードされた画像にのみ設定します。
+	ThumbnailURL     string `db:"thumbnail_url"`
+	ThumbnailWebPURL string `db:"thumbnail_webp_url"`
+	MediumURL        string `db:"medium_url"`
+	MediumWebPURL    string `db:"medium_webp_url"`
+	LargeURL         string `db:"large_url"`
+	LargeWebPURL     string `db:"large_webp_url"`
+	BlurHash         string `db:"blurhash"`
+	// Status はモデレーションの状態で、ImageStatusApprovedの画像のみ一覧に表示します。
+	Status ImageStatus `db:"status"`
 }
 
 type Images []Image
+
+// ImageStatus は画像のモデレーションの状態です。
+type ImageStatus string
+
+const (
+	// ImageStatusPending はモデレーションを待っている状態です。通報が一定数を超えた画像もこの状態に戻します。
+	ImageStatusPending  ImageStatus = "pending"
+	ImageStatusApproved ImageStatus = "approved"
+	ImageStatusRejected ImageStatus = "rejected"
+)
+
+func (s ImageStatus) IsValid() bool {
+	switch s {
+	case ImageStatusPending, ImageStatusApproved, ImageStatusRejected:
+		return true
+	default:
+		return false
+	}
+}
+
+// ImageReport はユーザによる画像の通報です。同じユーザは同じ画像を一度だけ通報できます。
+type ImageReport struct {
+	ID      uuid.UUID `db:"id"`
+	ImageID uuid.UUID `db:"image_id"`
+	UserID  uuid.UUID `db:"user_id"`
+	Reason  string    `db:"reason"`
+	Created time.Time `db:"created" goqu:"skipinsert,skipupdate"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// LoginAttemptKind はログインの失敗回数を数える単位です。
+type LoginAttemptKind string
+
+const (
+	LoginAttemptKindEmail LoginAttemptKind = "email"
+	LoginAttemptKindIP    LoginAttemptKind = "ip"
+)
+
+// LoginLockout はログインの失敗が続いたメールアドレスまたはIPアドレスをロックした記録で、管理者が確認します。
+// IPはロックのきっかけになったリクエストの接続元です。
+type LoginLockout struct {
+	Kind        LoginAttemptKind `json:"kind"`
+	Identifier  string           `json:"identifier"`
+	IP          string           `json:"ip"`
+	Failures    int64            `json:"failures"`
+	LockedUntil time.Time        `json:"lockedUntil"`
+	CreatedAt   time.Time        `json:"createdAt"`
+}
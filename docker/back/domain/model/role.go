@@ -0,0 +1,32 @@
+package model
+
+// Role はユーザの権限です。admin > moderator > contributor > user の順に強くなります。
+type Role string
+
+const (
+	RoleUser        Role = "user"
+	RoleContributor Role = "contributor"
+	RoleModerator   Role = "moderator"
+	RoleAdmin       Role = "admin"
+)
+
+var roleLevels = map[Role]int{
+	RoleUser:        1,
+	RoleContributor: 2,
+	RoleModerator:   3,
+	RoleAdmin:       4,
+}
+
+func (r Role) IsValid() bool {
+	_, ok := roleLevels[r]
+	return ok
+}
+
+// AtLeast はrがrequired以上の権限を持つかを返します。未知のロールは権限を持ちません。
+func (r Role) AtLeast(required Role) bool {
+	level, ok := roleLevels[r]
+	if !ok {
+		return false
+	}
+	return level >= roleLevels[required]
+}
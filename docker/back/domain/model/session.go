@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// Session はログインした端末ごとのセッションです。IDはリフレッシュトークンのFamilyIDと同じで、
+// JTIはそのセッションで現在有効なアクセストークンのjtiです。
+type Session struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"userId"`
+	JTI        string    `json:"jti"`
+	DeviceName string    `json:"deviceName"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"userAgent"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+}
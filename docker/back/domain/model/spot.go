@@ -1,6 +1,18 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MinStarRate = 1
+	MaxStarRate = 5
+)
 
 type Spot struct {
 	ID          uuid.UUID `db:"id" json:"id"`
@@ -14,6 +26,85 @@ type Spot struct {
 	Price       string    `db:"price" json:"price"`
 	Description string    `db:"description" json:"description"`
 	IconPath    string    `db:"iconpath" json:"iconpath"`
+	Geohash     string    `db:"geohash" json:"-"`
+
+	// 評価の集計はコメントの作成・更新・削除時にのみ更新するため、Updateでは上書きしない
+	RatingAverage   float64         `db:"rating_average" goqu:"skipupdate" json:"ratingAverage"`
+	RatingCount     int             `db:"rating_count" goqu:"skipupdate" json:"ratingCount"`
+	RatingSum       float64         `db:"rating_sum" goqu:"skipupdate" json:"-"`
+	RatingHistogram RatingHistogram `db:"rating_histogram" goqu:"skipupdate" json:"ratingHistogram"`
 }
 
 type Spots []Spot
+
+// SpotWithDistance は検索地点からの距離(km)を付与したSpotです。
+type SpotWithDistance struct {
+	Spot
+	DistanceKm float64 `json:"distanceKm"`
+}
+
+// SpotWithScore は全文検索の関連度スコアを付与したSpotです。
+type SpotWithScore struct {
+	Spot
+	Score float64 `json:"score"`
+}
+
+// SpotCluster は地図タイル内の近接したSpotをまとめた点です。Lat, Lngは含まれるSpotの重心、
+// Categoryは最も多いカテゴリです。Spotが1件のみの場合はSpotIDを設定します。
+type SpotCluster struct {
+	Lat      float64 `json:"lat"`
+	Lng      float64 `json:"lng"`
+	Count    int     `json:"count"`
+	Category string  `json:"category"`
+	SpotID   string  `json:"spotID,omitempty"`
+}
+
+type SpotClusters []SpotCluster
+
+type BoundingBox struct {
+	MinLat float64
+	MinLng float64
+	MaxLat float64
+	MaxLng float64
+}
+
+func (b BoundingBox) Center() (float64, float64) {
+	return (b.MinLat + b.MaxLat) / 2, (b.MinLng + b.MaxLng) / 2
+}
+
+// RatingHistogram は星1〜5それぞれの評価数です。DBにはJSON配列として保存します。
+type RatingHistogram [MaxStarRate]int
+
+// RatingBucket はstarRateを四捨五入し、RatingHistogramのインデックスに変換します。
+func RatingBucket(starRate float64) int {
+	star := int(math.Round(starRate))
+	if star < MinStarRate {
+		star = MinStarRate
+	}
+	if star > MaxStarRate {
+		star = MaxStarRate
+	}
+	return star - MinStarRate
+}
+
+func (h RatingHistogram) Value() (driver.Value, error) {
+	b, err := json.Marshal([MaxStarRate]int(h))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (h *RatingHistogram) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*h = RatingHistogram{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, (*[MaxStarRate]int)(h))
+	case string:
+		return json.Unmarshal([]byte(v), (*[MaxStarRate]int)(h))
+	default:
+		return fmt.Errorf("unsupported type for RatingHistogram: %T", src)
+	}
+}
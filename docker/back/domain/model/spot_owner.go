@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SpotOwner は管理者によってSpotの運営者と確認されたユーザです。運営者の返信はCommentKindOwnerResponseとして表示します。
+type SpotOwner struct {
+	ID      uuid.UUID `db:"id"`
+	SpotID  uuid.UUID `db:"spot_id"`
+	UserID  uuid.UUID `db:"user_id"`
+	Created time.Time `db:"created" goqu:"skipinsert,skipupdate"`
+}
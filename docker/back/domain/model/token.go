@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// RefreshToken はリフレッシュトークンのハッシュに紐づけて保存する情報です。
+// 同じログインから発行され続けるトークンは同じFamilyIDを持ち、使用済みトークンの再利用を検知した場合はFamily全体を失効させます。
+type RefreshToken struct {
+	UserID    string    `json:"userId"`
+	FamilyID  string    `json:"familyId"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// UserTokenPurpose はメールで送る使い捨てトークンの用途です。
+type UserTokenPurpose string
+
+const (
+	UserTokenPurposeVerifyEmail   UserTokenPurpose = "verify_email"
+	UserTokenPurposeResetPassword UserTokenPurpose = "reset_password"
+)
+
+// UserToken はメール確認やパスワード再設定のためにメールで送るトークンのハッシュに紐づけて保存する情報です。
+// トークンは一度使うと削除され、Emailは発行時のメールアドレスです。
+type UserToken struct {
+	UserID    string           `json:"userId"`
+	Email     string           `json:"email"`
+	Purpose   UserTokenPurpose `json:"purpose"`
+	ExpiresAt time.Time        `json:"expiresAt"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecoveryCode は認証アプリを使えない場合にTOTPの代わりに使うリカバリーコードのハッシュです。一度使うと削除します。
+type RecoveryCode struct {
+	ID       uuid.UUID `db:"id"`
+	UserID   uuid.UUID `db:"user_id"`
+	CodeHash string    `db:"code_hash"`
+	Created  time.Time `db:"created" goqu:"skipinsert,skipupdate"`
+}
+
+// TwoFactorChallenge はパスワードを確認してから2段階目の認証までの間、チャレンジトークンのハッシュに紐づけて保存する情報です。
+// 2段階目の認証に成功したら、保存しておいた端末の情報でセッションを作成します。
+type TwoFactorChallenge struct {
+	UserID     string    `json:"userId"`
+	Email      string    `json:"email"`
+	DeviceName string    `json:"deviceName"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"userAgent"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
@@ -8,4 +8,29 @@ type User struct {
 	Email    string    `db:"email"`
 	Password string    `db:"password"` // ハッシュ化されたパスワード
 	IsAdmin  bool      `db:"is_admin"`
+	Role     Role      `db:"role"`
+	// EmailVerified はEmailの所有を確認用メールで確認済みかどうかです。
+	EmailVerified bool `db:"email_verified"`
+	// AvatarURL はプロフィールに表示する画像のURLです。空の場合は既定の画像を表示します。
+	AvatarURL string `db:"avatar_url"`
+	// Suspended は管理者によって利用を停止されているかどうかです。停止中はログインできません。
+	Suspended bool `db:"suspended"`
+	// TOTPSecret は2段階認証のTOTPの秘密鍵(Base32)で、TOTPEnabledは設定が完了して2段階認証が有効かどうかです。
+	TOTPSecret  string `db:"totp_secret"`
+	TOTPEnabled bool   `db:"totp_enabled"`
+}
+
+// EffectiveRole はユーザのロールを返します。is_adminのユーザはロールに関わらずadminとして扱います。
+func (u User) EffectiveRole() Role {
+	if u.IsAdmin {
+		return RoleAdmin
+	}
+	if u.Role == "" {
+		return RoleUser
+	}
+	return u.Role
+}
+
+func (u User) HasRole(required Role) bool {
+	return u.EffectiveRole().AtLeast(required)
 }
@@ -9,11 +9,22 @@ import (
 
 type CommentRepository interface {
 	List(ctx context.Context, qcs []QueryCondition) ([]model.Comment, error)
+	ListPage(ctx context.Context, qcs []QueryCondition, opts ListOptions) ([]model.Comment, string, error)
+	Count(ctx context.Context, qcs []QueryCondition) (int, error)
 	Get(ctx context.Context, id string) (*model.Comment, error)
 	Create(ctx context.Context, comment model.Comment) error
 	BatchCreate(ctx context.Context, comments []model.Comment) error
 	Update(ctx context.Context, id string, comment model.Comment) error
 	Delete(ctx context.Context, id string) error
+	// ListReplyPages は各rootIDのスレッドの返信を古い順にlimit件まで、1回のクエリで取得してrootIDごとに返します。
+	ListReplyPages(ctx context.Context, rootIDs []string, limit int) (map[string]ReplyPage, error)
+}
+
+// ReplyPage はスレッドの返信を古い順に並べた先頭ページです。NextCursorはroot_idで絞り込み、
+// OrderByを"created"としたListPageで続きを取得するためのカーソルで、最終ページの場合は空です。
+type ReplyPage struct {
+	Replies    []model.Comment
+	NextCursor string
 }
 
 type CommentsCacheRepository interface {
@@ -3,6 +3,13 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
+)
+
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrCacheMiss     = errors.New("cache: key not found")
 )
 
 type SQLExecutor interface {
@@ -19,7 +26,39 @@ type QueryOptions struct {
 	Executor SQLExecutor
 }
 
+type Operator string
+
+const (
+	OpEq      Operator = "eq"
+	OpNe      Operator = "ne"
+	OpLt      Operator = "lt"
+	OpLte     Operator = "lte"
+	OpGt      Operator = "gt"
+	OpGte     Operator = "gte"
+	OpIn      Operator = "in"      // Valueにはスライスを指定する
+	OpLike    Operator = "like"    // Valueには%や_を含むパターンを指定する
+	OpBetween Operator = "between" // ValueにはRangeを指定する
+	OpIsNull  Operator = "is_null" // Valueがfalseの場合はIS NOT NULLになる
+)
+
+// QueryCondition は検索条件を表します。Operatorを省略した場合はOpEqとして扱います。
+// Or/Andが指定されている場合はField, Operator, Valueを無視し、入れ子の条件をOR/ANDで結合したグループとして扱います。
 type QueryCondition struct {
-	Field string
-	Value any
+	Field    string
+	Operator Operator
+	Value    any
+	Or       []QueryCondition
+	And      []QueryCondition
+}
+
+type Range struct {
+	Start any
+	End   any
+}
+
+// ListOptions はカーソルベースのページネーション条件です。
+type ListOptions struct {
+	Limit   int    // 0の場合は件数を制限しない
+	Cursor  string // 前ページのNextCursor。空の場合は先頭から取得する
+	OrderBy string // 並び替えるカラム名。先頭に"-"を付けると降順。空の場合はid順
 }
@@ -0,0 +1,23 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+)
+
+type IdentityRepository interface {
+	List(ctx context.Context, qcs []QueryCondition) ([]model.Identity, error)
+	Create(ctx context.Context, identity model.Identity) error
+	Delete(ctx context.Context, id string) error
+}
+
+// OIDCStateCacheRepository は認可リクエストからコールバックまでの間、stateに紐づく情報を保存します。
+type OIDCStateCacheRepository interface {
+	// SetOIDCState はexpiration経過後に自動で削除される情報を保存します。
+	SetOIDCState(ctx context.Context, state string, authState model.OIDCAuthState, expiration time.Duration) error
+	// ConsumeOIDCState は情報を取得すると同時に削除します。存在しない場合はErrCacheMissを返します。
+	ConsumeOIDCState(ctx context.Context, state string) (*model.OIDCAuthState, error)
+}
@@ -9,10 +9,22 @@ import (
 
 type ImageRepository interface {
 	List(ctx context.Context, qcs []QueryCondition) ([]model.Image, error)
+	ListPage(ctx context.Context, qcs []QueryCondition, opts ListOptions) ([]model.Image, string, error)
+	Count(ctx context.Context, qcs []QueryCondition) (int, error)
+	Get(ctx context.Context, id string) (*model.Image, error)
 	Create(ctx context.Context, img model.Image) error
+	Update(ctx context.Context, id string, img model.Image) error
 	Delete(ctx context.Context, id string) error
 }
 
+type ImageReportRepository interface {
+	List(ctx context.Context, qcs []QueryCondition) ([]model.ImageReport, error)
+	Count(ctx context.Context, qcs []QueryCondition) (int, error)
+	Create(ctx context.Context, report model.ImageReport) error
+	// DeleteByImageID は画像の通報をすべて削除します。
+	DeleteByImageID(ctx context.Context, imageID string) error
+}
+
 type ImagesCacheRepository interface {
 	Set(ctx context.Context, key string, images model.Images) error
 	Get(ctx context.Context, key string) (*model.Images, error)
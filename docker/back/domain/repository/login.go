@@ -0,0 +1,25 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+)
+
+// LoginAttemptCacheRepository はメールアドレス・IPアドレスごとのログインの失敗回数とロックを保存します。
+type LoginAttemptCacheRepository interface {
+	// AddFailure は失敗回数を1増やして返します。失敗回数は最後の失敗からwindowが経過するとリセットされます。
+	AddFailure(ctx context.Context, kind model.LoginAttemptKind, identifier string, window time.Duration) (int64, error)
+	// Lock はdurationの間ログインできないようにします。
+	Lock(ctx context.Context, kind model.LoginAttemptKind, identifier string, duration time.Duration) error
+	// LockRemaining はロックが解除されるまでの時間を返します。ロックされていない場合は0です。
+	LockRemaining(ctx context.Context, kind model.LoginAttemptKind, identifier string) (time.Duration, error)
+	// Reset は失敗回数とロックを削除します。
+	Reset(ctx context.Context, kind model.LoginAttemptKind, identifier string) error
+	// AddLockout はロックの記録を追加します。記録は新しい順にmaxLen件まで保持します。
+	AddLockout(ctx context.Context, lockout model.LoginLockout, maxLen int64) error
+	// ListLockouts はロックの記録を新しい順にlimit件返します。
+	ListLockouts(ctx context.Context, limit int64) ([]model.LoginLockout, error)
+}
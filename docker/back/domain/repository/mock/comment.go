@@ -51,6 +51,21 @@ func (mr *MockCommentRepositoryMockRecorder) BatchCreate(ctx, comments interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchCreate", reflect.TypeOf((*MockCommentRepository)(nil).BatchCreate), ctx, comments)
 }
 
+// Count mocks base method.
+func (m *MockCommentRepository) Count(ctx context.Context, qcs []repository.QueryCondition) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", ctx, qcs)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockCommentRepositoryMockRecorder) Count(ctx, qcs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockCommentRepository)(nil).Count), ctx, qcs)
+}
+
 // Create mocks base method.
 func (m *MockCommentRepository) Create(ctx context.Context, comment model.Comment) error {
 	m.ctrl.T.Helper()
@@ -109,6 +124,37 @@ func (mr *MockCommentRepositoryMockRecorder) List(ctx, qcs interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockCommentRepository)(nil).List), ctx, qcs)
 }
 
+// ListPage mocks base method.
+func (m *MockCommentRepository) ListPage(ctx context.Context, qcs []repository.QueryCondition, opts repository.ListOptions) ([]model.Comment, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPage", ctx, qcs, opts)
+	ret0, _ := ret[0].([]model.Comment)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListPage indicates an expected call of ListPage.
+func (mr *MockCommentRepositoryMockRecorder) ListPage(ctx, qcs, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPage", reflect.TypeOf((*MockCommentRepository)(nil).ListPage), ctx, qcs, opts)
+}
+
+// ListReplyPages mocks base method.
+func (m *MockCommentRepository) ListReplyPages(ctx context.Context, rootIDs []string, limit int) (map[string]repository.ReplyPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReplyPages", ctx, rootIDs, limit)
+	ret0, _ := ret[0].(map[string]repository.ReplyPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReplyPages indicates an expected call of ListReplyPages.
+func (mr *MockCommentRepositoryMockRecorder) ListReplyPages(ctx, rootIDs, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReplyPages", reflect.TypeOf((*MockCommentRepository)(nil).ListReplyPages), ctx, rootIDs, limit)
+}
+
 // Update mocks base method.
 func (m *MockCommentRepository) Update(ctx context.Context, id string, comment model.Comment) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,134 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: identity.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+
+	model "github.com/tusmasoma/campfinder/docker/back/domain/model"
+	repository "github.com/tusmasoma/campfinder/docker/back/domain/repository"
+)
+
+// MockIdentityRepository is a mock of IdentityRepository interface.
+type MockIdentityRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdentityRepositoryMockRecorder
+}
+
+// MockIdentityRepositoryMockRecorder is the mock recorder for MockIdentityRepository.
+type MockIdentityRepositoryMockRecorder struct {
+	mock *MockIdentityRepository
+}
+
+// NewMockIdentityRepository creates a new mock instance.
+func NewMockIdentityRepository(ctrl *gomock.Controller) *MockIdentityRepository {
+	mock := &MockIdentityRepository{ctrl: ctrl}
+	mock.recorder = &MockIdentityRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdentityRepository) EXPECT() *MockIdentityRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockIdentityRepository) Create(ctx context.Context, identity model.Identity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, identity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIdentityRepositoryMockRecorder) Create(ctx, identity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIdentityRepository)(nil).Create), ctx, identity)
+}
+
+// Delete mocks base method.
+func (m *MockIdentityRepository) Delete(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockIdentityRepositoryMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockIdentityRepository)(nil).Delete), ctx, id)
+}
+
+// List mocks base method.
+func (m *MockIdentityRepository) List(ctx context.Context, qcs []repository.QueryCondition) ([]model.Identity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, qcs)
+	ret0, _ := ret[0].([]model.Identity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockIdentityRepositoryMockRecorder) List(ctx, qcs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockIdentityRepository)(nil).List), ctx, qcs)
+}
+
+// MockOIDCStateCacheRepository is a mock of OIDCStateCacheRepository interface.
+type MockOIDCStateCacheRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOIDCStateCacheRepositoryMockRecorder
+}
+
+// MockOIDCStateCacheRepositoryMockRecorder is the mock recorder for MockOIDCStateCacheRepository.
+type MockOIDCStateCacheRepositoryMockRecorder struct {
+	mock *MockOIDCStateCacheRepository
+}
+
+// NewMockOIDCStateCacheRepository creates a new mock instance.
+func NewMockOIDCStateCacheRepository(ctrl *gomock.Controller) *MockOIDCStateCacheRepository {
+	mock := &MockOIDCStateCacheRepository{ctrl: ctrl}
+	mock.recorder = &MockOIDCStateCacheRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOIDCStateCacheRepository) EXPECT() *MockOIDCStateCacheRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ConsumeOIDCState mocks base method.
+func (m *MockOIDCStateCacheRepository) ConsumeOIDCState(ctx context.Context, state string) (*model.OIDCAuthState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeOIDCState", ctx, state)
+	ret0, _ := ret[0].(*model.OIDCAuthState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeOIDCState indicates an expected call of ConsumeOIDCState.
+func (mr *MockOIDCStateCacheRepositoryMockRecorder) ConsumeOIDCState(ctx, state interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeOIDCState", reflect.TypeOf((*MockOIDCStateCacheRepository)(nil).ConsumeOIDCState), ctx, state)
+}
+
+// SetOIDCState mocks base method.
+func (m *MockOIDCStateCacheRepository) SetOIDCState(ctx context.Context, state string, authState model.OIDCAuthState, expiration time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOIDCState", ctx, state, authState, expiration)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetOIDCState indicates an expected call of SetOIDCState.
+func (mr *MockOIDCStateCacheRepositoryMockRecorder) SetOIDCState(ctx, state, authState, expiration interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOIDCState", reflect.TypeOf((*MockOIDCStateCacheRepository)(nil).SetOIDCState), ctx, state, authState, expiration)
+}
@@ -37,6 +37,21 @@ func (m *MockImageRepository) EXPECT() *MockImageRepositoryMockRecorder {
 	return m.recorder
 }
 
+// Count mocks base method.
+func (m *MockImageRepository) Count(ctx context.Context, qcs []repository.QueryCondition) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", ctx, qcs)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockImageRepositoryMockRecorder) Count(ctx, qcs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockImageRepository)(nil).Count), ctx, qcs)
+}
+
 // Create mocks base method.
 func (m *MockImageRepository) Create(ctx context.Context, img model.Image) error {
 	m.ctrl.T.Helper()
@@ -65,6 +80,21 @@ func (mr *MockImageRepositoryMockRecorder) Delete(ctx, id interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockImageRepository)(nil).Delete), ctx, id)
 }
 
+// Get mocks base method.
+func (m *MockImageRepository) Get(ctx context.Context, id string) (*model.Image, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(*model.Image)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockImageRepositoryMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockImageRepository)(nil).Get), ctx, id)
+}
+
 // List mocks base method.
 func (m *MockImageRepository) List(ctx context.Context, qcs []repository.QueryCondition) ([]model.Image, error) {
 	m.ctrl.T.Helper()
@@ -80,6 +110,117 @@ func (mr *MockImageRepositoryMockRecorder) List(ctx, qcs interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockImageRepository)(nil).List), ctx, qcs)
 }
 
+// ListPage mocks base method.
+func (m *MockImageRepository) ListPage(ctx context.Context, qcs []repository.QueryCondition, opts repository.ListOptions) ([]model.Image, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPage", ctx, qcs, opts)
+	ret0, _ := ret[0].([]model.Image)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListPage indicates an expected call of ListPage.
+func (mr *MockImageRepositoryMockRecorder) ListPage(ctx, qcs, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPage", reflect.TypeOf((*MockImageRepository)(nil).ListPage), ctx, qcs, opts)
+}
+
+// Update mocks base method.
+func (m *MockImageRepository) Update(ctx context.Context, id string, img model.Image) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, id, img)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockImageRepositoryMockRecorder) Update(ctx, id, img interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockImageRepository)(nil).Update), ctx, id, img)
+}
+
+// MockImageReportRepository is a mock of ImageReportRepository interface.
+type MockImageReportRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockImageReportRepositoryMockRecorder
+}
+
+// MockImageReportRepositoryMockRecorder is the mock recorder for MockImageReportRepository.
+type MockImageReportRepositoryMockRecorder struct {
+	mock *MockImageReportRepository
+}
+
+// NewMockImageReportRepository creates a new mock instance.
+func NewMockImageReportRepository(ctrl *gomock.Controller) *MockImageReportRepository {
+	mock := &MockImageReportRepository{ctrl: ctrl}
+	mock.recorder = &MockImageReportRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImageReportRepository) EXPECT() *MockImageReportRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Count mocks base method.
+func (m *MockImageReportRepository) Count(ctx context.Context, qcs []repository.QueryCondition) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", ctx, qcs)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockImageReportRepositoryMockRecorder) Count(ctx, qcs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockImageReportRepository)(nil).Count), ctx, qcs)
+}
+
+// Create mocks base method.
+func (m *MockImageReportRepository) Create(ctx context.Context, report model.ImageReport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, report)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockImageReportRepositoryMockRecorder) Create(ctx, report interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockImageReportRepository)(nil).Create), ctx, report)
+}
+
+// DeleteByImageID mocks base method.
+func (m *MockImageReportRepository) DeleteByImageID(ctx context.Context, imageID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByImageID", ctx, imageID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByImageID indicates an expected call of DeleteByImageID.
+func (mr *MockImageReportRepositoryMockRecorder) DeleteByImageID(ctx, imageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByImageID", reflect.TypeOf((*MockImageReportRepository)(nil).DeleteByImageID), ctx, imageID)
+}
+
+// List mocks base method.
+func (m *MockImageReportRepository) List(ctx context.Context, qcs []repository.QueryCondition) ([]model.ImageReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, qcs)
+	ret0, _ := ret[0].([]model.ImageReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockImageReportRepositoryMockRecorder) List(ctx, qcs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockImageReportRepository)(nil).List), ctx, qcs)
+}
+
 // MockImagesCacheRepository is a mock of ImagesCacheRepository interface.
 type MockImagesCacheRepository struct {
 	ctrl     *gomock.Controller
@@ -0,0 +1,125 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: login.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+
+	model "github.com/tusmasoma/campfinder/docker/back/domain/model"
+)
+
+// MockLoginAttemptCacheRepository is a mock of LoginAttemptCacheRepository interface.
+type MockLoginAttemptCacheRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginAttemptCacheRepositoryMockRecorder
+}
+
+// MockLoginAttemptCacheRepositoryMockRecorder is the mock recorder for MockLoginAttemptCacheRepository.
+type MockLoginAttemptCacheRepositoryMockRecorder struct {
+	mock *MockLoginAttemptCacheRepository
+}
+
+// NewMockLoginAttemptCacheRepository creates a new mock instance.
+func NewMockLoginAttemptCacheRepository(ctrl *gomock.Controller) *MockLoginAttemptCacheRepository {
+	mock := &MockLoginAttemptCacheRepository{ctrl: ctrl}
+	mock.recorder = &MockLoginAttemptCacheRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginAttemptCacheRepository) EXPECT() *MockLoginAttemptCacheRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddFailure mocks base method.
+func (m *MockLoginAttemptCacheRepository) AddFailure(ctx context.Context, kind model.LoginAttemptKind, identifier string, window time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddFailure", ctx, kind, identifier, window)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddFailure indicates an expected call of AddFailure.
+func (mr *MockLoginAttemptCacheRepositoryMockRecorder) AddFailure(ctx, kind, identifier, window interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFailure", reflect.TypeOf((*MockLoginAttemptCacheRepository)(nil).AddFailure), ctx, kind, identifier, window)
+}
+
+// AddLockout mocks base method.
+func (m *MockLoginAttemptCacheRepository) AddLockout(ctx context.Context, lockout model.LoginLockout, maxLen int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddLockout", ctx, lockout, maxLen)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddLockout indicates an expected call of AddLockout.
+func (mr *MockLoginAttemptCacheRepositoryMockRecorder) AddLockout(ctx, lockout, maxLen interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddLockout", reflect.TypeOf((*MockLoginAttemptCacheRepository)(nil).AddLockout), ctx, lockout, maxLen)
+}
+
+// ListLockouts mocks base method.
+func (m *MockLoginAttemptCacheRepository) ListLockouts(ctx context.Context, limit int64) ([]model.LoginLockout, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLockouts", ctx, limit)
+	ret0, _ := ret[0].([]model.LoginLockout)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLockouts indicates an expected call of ListLockouts.
+func (mr *MockLoginAttemptCacheRepositoryMockRecorder) ListLockouts(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLockouts", reflect.TypeOf((*MockLoginAttemptCacheRepository)(nil).ListLockouts), ctx, limit)
+}
+
+// Lock mocks base method.
+func (m *MockLoginAttemptCacheRepository) Lock(ctx context.Context, kind model.LoginAttemptKind, identifier string, duration time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", ctx, kind, identifier, duration)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockLoginAttemptCacheRepositoryMockRecorder) Lock(ctx, kind, identifier, duration interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockLoginAttemptCacheRepository)(nil).Lock), ctx, kind, identifier, duration)
+}
+
+// LockRemaining mocks base method.
+func (m *MockLoginAttemptCacheRepository) LockRemaining(ctx context.Context, kind model.LoginAttemptKind, identifier string) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockRemaining", ctx, kind, identifier)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockRemaining indicates an expected call of LockRemaining.
+func (mr *MockLoginAttemptCacheRepositoryMockRecorder) LockRemaining(ctx, kind, identifier interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockRemaining", reflect.TypeOf((*MockLoginAttemptCacheRepository)(nil).LockRemaining), ctx, kind, identifier)
+}
+
+// Reset mocks base method.
+func (m *MockLoginAttemptCacheRepository) Reset(ctx context.Context, kind model.LoginAttemptKind, identifier string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", ctx, kind, identifier)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockLoginAttemptCacheRepositoryMockRecorder) Reset(ctx, kind, identifier interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockLoginAttemptCacheRepository)(nil).Reset), ctx, kind, identifier)
+}
@@ -37,6 +37,20 @@ func (m *MockSpotRepository) EXPECT() *MockSpotRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AdjustRating mocks base method.
+func (m *MockSpotRepository) AdjustRating(ctx context.Context, spotID string, starRate float64, delta int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustRating", ctx, spotID, starRate, delta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdjustRating indicates an expected call of AdjustRating.
+func (mr *MockSpotRepositoryMockRecorder) AdjustRating(ctx, spotID, starRate, delta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustRating", reflect.TypeOf((*MockSpotRepository)(nil).AdjustRating), ctx, spotID, starRate, delta)
+}
+
 // BatchCreate mocks base method.
 func (m *MockSpotRepository) BatchCreate(ctx context.Context, spots []model.Spot) error {
 	m.ctrl.T.Helper()
@@ -51,6 +65,21 @@ func (mr *MockSpotRepositoryMockRecorder) BatchCreate(ctx, spots interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchCreate", reflect.TypeOf((*MockSpotRepository)(nil).BatchCreate), ctx, spots)
 }
 
+// Count mocks base method.
+func (m *MockSpotRepository) Count(ctx context.Context, qcs []repository.QueryCondition) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", ctx, qcs)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockSpotRepositoryMockRecorder) Count(ctx, qcs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockSpotRepository)(nil).Count), ctx, qcs)
+}
+
 // Create mocks base method.
 func (m *MockSpotRepository) Create(ctx context.Context, spot model.Spot) error {
 	m.ctrl.T.Helper()
@@ -123,6 +152,67 @@ func (mr *MockSpotRepositoryMockRecorder) List(ctx, qcs interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockSpotRepository)(nil).List), ctx, qcs)
 }
 
+// ListInBounds mocks base method.
+func (m *MockSpotRepository) ListInBounds(ctx context.Context, bounds model.BoundingBox) ([]model.SpotWithDistance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInBounds", ctx, bounds)
+	ret0, _ := ret[0].([]model.SpotWithDistance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInBounds indicates an expected call of ListInBounds.
+func (mr *MockSpotRepositoryMockRecorder) ListInBounds(ctx, bounds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInBounds", reflect.TypeOf((*MockSpotRepository)(nil).ListInBounds), ctx, bounds)
+}
+
+// ListNearby mocks base method.
+func (m *MockSpotRepository) ListNearby(ctx context.Context, lat, lng, radiusKm float64) ([]model.SpotWithDistance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNearby", ctx, lat, lng, radiusKm)
+	ret0, _ := ret[0].([]model.SpotWithDistance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNearby indicates an expected call of ListNearby.
+func (mr *MockSpotRepositoryMockRecorder) ListNearby(ctx, lat, lng, radiusKm interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNearby", reflect.TypeOf((*MockSpotRepository)(nil).ListNearby), ctx, lat, lng, radiusKm)
+}
+
+// ListPage mocks base method.
+func (m *MockSpotRepository) ListPage(ctx context.Context, qcs []repository.QueryCondition, opts repository.ListOptions) ([]model.Spot, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPage", ctx, qcs, opts)
+	ret0, _ := ret[0].([]model.Spot)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListPage indicates an expected call of ListPage.
+func (mr *MockSpotRepositoryMockRecorder) ListPage(ctx, qcs, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPage", reflect.TypeOf((*MockSpotRepository)(nil).ListPage), ctx, qcs, opts)
+}
+
+// Search mocks base method.
+func (m *MockSpotRepository) Search(ctx context.Context, query repository.SpotSearchQuery) ([]model.SpotWithScore, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, query)
+	ret0, _ := ret[0].([]model.SpotWithScore)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockSpotRepositoryMockRecorder) Search(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockSpotRepository)(nil).Search), ctx, query)
+}
+
 // Update mocks base method.
 func (m *MockSpotRepository) Update(ctx context.Context, id string, spot model.Spot) error {
 	m.ctrl.T.Helper()
@@ -137,6 +227,87 @@ func (mr *MockSpotRepositoryMockRecorder) Update(ctx, id, spot interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockSpotRepository)(nil).Update), ctx, id, spot)
 }
 
+// MockSpotOwnerRepository is a mock of SpotOwnerRepository interface.
+type MockSpotOwnerRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSpotOwnerRepositoryMockRecorder
+}
+
+// MockSpotOwnerRepositoryMockRecorder is the mock recorder for MockSpotOwnerRepository.
+type MockSpotOwnerRepositoryMockRecorder struct {
+	mock *MockSpotOwnerRepository
+}
+
+// NewMockSpotOwnerRepository creates a new mock instance.
+func NewMockSpotOwnerRepository(ctrl *gomock.Controller) *MockSpotOwnerRepository {
+	mock := &MockSpotOwnerRepository{ctrl: ctrl}
+	mock.recorder = &MockSpotOwnerRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSpotOwnerRepository) EXPECT() *MockSpotOwnerRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Count mocks base method.
+func (m *MockSpotOwnerRepository) Count(ctx context.Context, qcs []repository.QueryCondition) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", ctx, qcs)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockSpotOwnerRepositoryMockRecorder) Count(ctx, qcs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockSpotOwnerRepository)(nil).Count), ctx, qcs)
+}
+
+// Create mocks base method.
+func (m *MockSpotOwnerRepository) Create(ctx context.Context, owner model.SpotOwner) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, owner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockSpotOwnerRepositoryMockRecorder) Create(ctx, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSpotOwnerRepository)(nil).Create), ctx, owner)
+}
+
+// DeleteBySpotAndUser mocks base method.
+func (m *MockSpotOwnerRepository) DeleteBySpotAndUser(ctx context.Context, spotID, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBySpotAndUser", ctx, spotID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBySpotAndUser indicates an expected call of DeleteBySpotAndUser.
+func (mr *MockSpotOwnerRepositoryMockRecorder) DeleteBySpotAndUser(ctx, spotID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBySpotAndUser", reflect.TypeOf((*MockSpotOwnerRepository)(nil).DeleteBySpotAndUser), ctx, spotID, userID)
+}
+
+// List mocks base method.
+func (m *MockSpotOwnerRepository) List(ctx context.Context, qcs []repository.QueryCondition) ([]model.SpotOwner, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, qcs)
+	ret0, _ := ret[0].([]model.SpotOwner)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockSpotOwnerRepositoryMockRecorder) List(ctx, qcs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockSpotOwnerRepository)(nil).List), ctx, qcs)
+}
+
 // MockSpotsCacheRepository is a mock of SpotsCacheRepository interface.
 type MockSpotsCacheRepository struct {
 	ctrl     *gomock.Controller
@@ -231,3 +402,98 @@ func (mr *MockSpotsCacheRepositoryMockRecorder) Set(ctx, key, spots interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockSpotsCacheRepository)(nil).Set), ctx, key, spots)
 }
+
+// MockSpotClustersCacheRepository is a mock of SpotClustersCacheRepository interface.
+type MockSpotClustersCacheRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSpotClustersCacheRepositoryMockRecorder
+}
+
+// MockSpotClustersCacheRepositoryMockRecorder is the mock recorder for MockSpotClustersCacheRepository.
+type MockSpotClustersCacheRepositoryMockRecorder struct {
+	mock *MockSpotClustersCacheRepository
+}
+
+// NewMockSpotClustersCacheRepository creates a new mock instance.
+func NewMockSpotClustersCacheRepository(ctrl *gomock.Controller) *MockSpotClustersCacheRepository {
+	mock := &MockSpotClustersCacheRepository{ctrl: ctrl}
+	mock.recorder = &MockSpotClustersCacheRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSpotClustersCacheRepository) EXPECT() *MockSpotClustersCacheRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockSpotClustersCacheRepository) Delete(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockSpotClustersCacheRepositoryMockRecorder) Delete(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSpotClustersCacheRepository)(nil).Delete), ctx, key)
+}
+
+// Exists mocks base method.
+func (m *MockSpotClustersCacheRepository) Exists(ctx context.Context, key string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exists", ctx, key)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Exists indicates an expected call of Exists.
+func (mr *MockSpotClustersCacheRepositoryMockRecorder) Exists(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockSpotClustersCacheRepository)(nil).Exists), ctx, key)
+}
+
+// Get mocks base method.
+func (m *MockSpotClustersCacheRepository) Get(ctx context.Context, key string) (*model.SpotClusters, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, key)
+	ret0, _ := ret[0].(*model.SpotClusters)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockSpotClustersCacheRepositoryMockRecorder) Get(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSpotClustersCacheRepository)(nil).Get), ctx, key)
+}
+
+// Scan mocks base method.
+func (m *MockSpotClustersCacheRepository) Scan(ctx context.Context, match string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Scan", ctx, match)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Scan indicates an expected call of Scan.
+func (mr *MockSpotClustersCacheRepositoryMockRecorder) Scan(ctx, match interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scan", reflect.TypeOf((*MockSpotClustersCacheRepository)(nil).Scan), ctx, match)
+}
+
+// Set mocks base method.
+func (m *MockSpotClustersCacheRepository) Set(ctx context.Context, key string, clusters model.SpotClusters) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", ctx, key, clusters)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockSpotClustersCacheRepositoryMockRecorder) Set(ctx, key, clusters interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockSpotClustersCacheRepository)(nil).Set), ctx, key, clusters)
+}
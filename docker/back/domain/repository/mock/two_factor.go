@@ -0,0 +1,222 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: two_factor.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+
+	model "github.com/tusmasoma/campfinder/docker/back/domain/model"
+	repository "github.com/tusmasoma/campfinder/docker/back/domain/repository"
+)
+
+// MockRecoveryCodeRepository is a mock of RecoveryCodeRepository interface.
+type MockRecoveryCodeRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRecoveryCodeRepositoryMockRecorder
+}
+
+// MockRecoveryCodeRepositoryMockRecorder is the mock recorder for MockRecoveryCodeRepository.
+type MockRecoveryCodeRepositoryMockRecorder struct {
+	mock *MockRecoveryCodeRepository
+}
+
+// NewMockRecoveryCodeRepository creates a new mock instance.
+func NewMockRecoveryCodeRepository(ctrl *gomock.Controller) *MockRecoveryCodeRepository {
+	mock := &MockRecoveryCodeRepository{ctrl: ctrl}
+	mock.recorder = &MockRecoveryCodeRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRecoveryCodeRepository) EXPECT() *MockRecoveryCodeRepositoryMockRecorder {
+	return m.recorder
+}
+
+// BatchCreate mocks base method.
+func (m *MockRecoveryCodeRepository) BatchCreate(ctx context.Context, codes []model.RecoveryCode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchCreate", ctx, codes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BatchCreate indicates an expected call of BatchCreate.
+func (mr *MockRecoveryCodeRepositoryMockRecorder) BatchCreate(ctx, codes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchCreate", reflect.TypeOf((*MockRecoveryCodeRepository)(nil).BatchCreate), ctx, codes)
+}
+
+// Consume mocks base method.
+func (m *MockRecoveryCodeRepository) Consume(ctx context.Context, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Consume", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Consume indicates an expected call of Consume.
+func (mr *MockRecoveryCodeRepositoryMockRecorder) Consume(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consume", reflect.TypeOf((*MockRecoveryCodeRepository)(nil).Consume), ctx, id)
+}
+
+// DeleteByUserID mocks base method.
+func (m *MockRecoveryCodeRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByUserID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByUserID indicates an expected call of DeleteByUserID.
+func (mr *MockRecoveryCodeRepositoryMockRecorder) DeleteByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByUserID", reflect.TypeOf((*MockRecoveryCodeRepository)(nil).DeleteByUserID), ctx, userID)
+}
+
+// List mocks base method.
+func (m *MockRecoveryCodeRepository) List(ctx context.Context, qcs []repository.QueryCondition) ([]model.RecoveryCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, qcs)
+	ret0, _ := ret[0].([]model.RecoveryCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockRecoveryCodeRepositoryMockRecorder) List(ctx, qcs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRecoveryCodeRepository)(nil).List), ctx, qcs)
+}
+
+// MockTwoFactorCacheRepository is a mock of TwoFactorCacheRepository interface.
+type MockTwoFactorCacheRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTwoFactorCacheRepositoryMockRecorder
+}
+
+// MockTwoFactorCacheRepositoryMockRecorder is the mock recorder for MockTwoFactorCacheRepository.
+type MockTwoFactorCacheRepositoryMockRecorder struct {
+	mock *MockTwoFactorCacheRepository
+}
+
+// NewMockTwoFactorCacheRepository creates a new mock instance.
+func NewMockTwoFactorCacheRepository(ctrl *gomock.Controller) *MockTwoFactorCacheRepository {
+	mock := &MockTwoFactorCacheRepository{ctrl: ctrl}
+	mock.recorder = &MockTwoFactorCacheRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTwoFactorCacheRepository) EXPECT() *MockTwoFactorCacheRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ConsumeChallenge mocks base method.
+func (m *MockTwoFactorCacheRepository) ConsumeChallenge(ctx context.Context, challengeHash string) (*model.TwoFactorChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeChallenge", ctx, challengeHash)
+	ret0, _ := ret[0].(*model.TwoFactorChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeChallenge indicates an expected call of ConsumeChallenge.
+func (mr *MockTwoFactorCacheRepositoryMockRecorder) ConsumeChallenge(ctx, challengeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeChallenge", reflect.TypeOf((*MockTwoFactorCacheRepository)(nil).ConsumeChallenge), ctx, challengeHash)
+}
+
+// DeletePendingSecret mocks base method.
+func (m *MockTwoFactorCacheRepository) DeletePendingSecret(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePendingSecret", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePendingSecret indicates an expected call of DeletePendingSecret.
+func (mr *MockTwoFactorCacheRepositoryMockRecorder) DeletePendingSecret(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePendingSecret", reflect.TypeOf((*MockTwoFactorCacheRepository)(nil).DeletePendingSecret), ctx, userID)
+}
+
+// GetChallenge mocks base method.
+func (m *MockTwoFactorCacheRepository) GetChallenge(ctx context.Context, challengeHash string) (*model.TwoFactorChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChallenge", ctx, challengeHash)
+	ret0, _ := ret[0].(*model.TwoFactorChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChallenge indicates an expected call of GetChallenge.
+func (mr *MockTwoFactorCacheRepositoryMockRecorder) GetChallenge(ctx, challengeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChallenge", reflect.TypeOf((*MockTwoFactorCacheRepository)(nil).GetChallenge), ctx, challengeHash)
+}
+
+// GetPendingSecret mocks base method.
+func (m *MockTwoFactorCacheRepository) GetPendingSecret(ctx context.Context, userID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingSecret", ctx, userID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingSecret indicates an expected call of GetPendingSecret.
+func (mr *MockTwoFactorCacheRepositoryMockRecorder) GetPendingSecret(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingSecret", reflect.TypeOf((*MockTwoFactorCacheRepository)(nil).GetPendingSecret), ctx, userID)
+}
+
+// MarkTOTPUsed mocks base method.
+func (m *MockTwoFactorCacheRepository) MarkTOTPUsed(ctx context.Context, userID string, counter int64, expiration time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkTOTPUsed", ctx, userID, counter, expiration)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkTOTPUsed indicates an expected call of MarkTOTPUsed.
+func (mr *MockTwoFactorCacheRepositoryMockRecorder) MarkTOTPUsed(ctx, userID, counter, expiration interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkTOTPUsed", reflect.TypeOf((*MockTwoFactorCacheRepository)(nil).MarkTOTPUsed), ctx, userID, counter, expiration)
+}
+
+// SetChallenge mocks base method.
+func (m *MockTwoFactorCacheRepository) SetChallenge(ctx context.Context, challengeHash string, challenge model.TwoFactorChallenge) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetChallenge", ctx, challengeHash, challenge)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetChallenge indicates an expected call of SetChallenge.
+func (mr *MockTwoFactorCacheRepositoryMockRecorder) SetChallenge(ctx, challengeHash, challenge interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetChallenge", reflect.TypeOf((*MockTwoFactorCacheRepository)(nil).SetChallenge), ctx, challengeHash, challenge)
+}
+
+// SetPendingSecret mocks base method.
+func (m *MockTwoFactorCacheRepository) SetPendingSecret(ctx context.Context, userID, secret string, expiration time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPendingSecret", ctx, userID, secret, expiration)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPendingSecret indicates an expected call of SetPendingSecret.
+func (mr *MockTwoFactorCacheRepositoryMockRecorder) SetPendingSecret(ctx, userID, secret, expiration interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPendingSecret", reflect.TypeOf((*MockTwoFactorCacheRepository)(nil).SetPendingSecret), ctx, userID, secret, expiration)
+}
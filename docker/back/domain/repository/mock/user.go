@@ -7,6 +7,7 @@ package mock
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 
@@ -37,6 +38,21 @@ func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
 	return m.recorder
 }
 
+// Count mocks base method.
+func (m *MockUserRepository) Count(ctx context.Context, qcs []repository.QueryCondition) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", ctx, qcs)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockUserRepositoryMockRecorder) Count(ctx, qcs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockUserRepository)(nil).Count), ctx, qcs)
+}
+
 // Create mocks base method.
 func (m *MockUserRepository) Create(ctx context.Context, user model.User) error {
 	m.ctrl.T.Helper()
@@ -95,6 +111,22 @@ func (mr *MockUserRepositoryMockRecorder) List(ctx, qcs interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockUserRepository)(nil).List), ctx, qcs)
 }
 
+// ListPage mocks base method.
+func (m *MockUserRepository) ListPage(ctx context.Context, qcs []repository.QueryCondition, opts repository.ListOptions) ([]model.User, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPage", ctx, qcs, opts)
+	ret0, _ := ret[0].([]model.User)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListPage indicates an expected call of ListPage.
+func (mr *MockUserRepositoryMockRecorder) ListPage(ctx, qcs, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPage", reflect.TypeOf((*MockUserRepository)(nil).ListPage), ctx, qcs, opts)
+}
+
 // Update mocks base method.
 func (m *MockUserRepository) Update(ctx context.Context, id string, spot model.User) error {
 	m.ctrl.T.Helper()
@@ -132,6 +164,21 @@ func (m *MockUserCacheRepository) EXPECT() *MockUserCacheRepositoryMockRecorder
 	return m.recorder
 }
 
+// ConsumeUserToken mocks base method.
+func (m *MockUserCacheRepository) ConsumeUserToken(ctx context.Context, purpose model.UserTokenPurpose, tokenHash string) (*model.UserToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeUserToken", ctx, purpose, tokenHash)
+	ret0, _ := ret[0].(*model.UserToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeUserToken indicates an expected call of ConsumeUserToken.
+func (mr *MockUserCacheRepositoryMockRecorder) ConsumeUserToken(ctx, purpose, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeUserToken", reflect.TypeOf((*MockUserCacheRepository)(nil).ConsumeUserToken), ctx, purpose, tokenHash)
+}
+
 // Delete mocks base method.
 func (m *MockUserCacheRepository) Delete(ctx context.Context, key string) error {
 	m.ctrl.T.Helper()
@@ -146,6 +193,34 @@ func (mr *MockUserCacheRepositoryMockRecorder) Delete(ctx, key interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUserCacheRepository)(nil).Delete), ctx, key)
 }
 
+// DeleteRefreshTokenFamily mocks base method.
+func (m *MockUserCacheRepository) DeleteRefreshTokenFamily(ctx context.Context, familyID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRefreshTokenFamily", ctx, familyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRefreshTokenFamily indicates an expected call of DeleteRefreshTokenFamily.
+func (mr *MockUserCacheRepositoryMockRecorder) DeleteRefreshTokenFamily(ctx, familyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRefreshTokenFamily", reflect.TypeOf((*MockUserCacheRepository)(nil).DeleteRefreshTokenFamily), ctx, familyID)
+}
+
+// DeleteSession mocks base method.
+func (m *MockUserCacheRepository) DeleteSession(ctx context.Context, userID, sessionID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSession", ctx, userID, sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSession indicates an expected call of DeleteSession.
+func (mr *MockUserCacheRepositoryMockRecorder) DeleteSession(ctx, userID, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockUserCacheRepository)(nil).DeleteSession), ctx, userID, sessionID)
+}
+
 // Exists mocks base method.
 func (m *MockUserCacheRepository) Exists(ctx context.Context, key string) bool {
 	m.ctrl.T.Helper()
@@ -175,19 +250,79 @@ func (mr *MockUserCacheRepositoryMockRecorder) Get(ctx, key interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockUserCacheRepository)(nil).Get), ctx, key)
 }
 
-// GetUserSession mocks base method.
-func (m *MockUserCacheRepository) GetUserSession(ctx context.Context, userID string) (string, error) {
+// GetRefreshToken mocks base method.
+func (m *MockUserCacheRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*model.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefreshToken", ctx, tokenHash)
+	ret0, _ := ret[0].(*model.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefreshToken indicates an expected call of GetRefreshToken.
+func (mr *MockUserCacheRepositoryMockRecorder) GetRefreshToken(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshToken", reflect.TypeOf((*MockUserCacheRepository)(nil).GetRefreshToken), ctx, tokenHash)
+}
+
+// GetRefreshTokenFamily mocks base method.
+func (m *MockUserCacheRepository) GetRefreshTokenFamily(ctx context.Context, familyID string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserSession", ctx, userID)
+	ret := m.ctrl.Call(m, "GetRefreshTokenFamily", ctx, familyID)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserSession indicates an expected call of GetUserSession.
-func (mr *MockUserCacheRepositoryMockRecorder) GetUserSession(ctx, userID interface{}) *gomock.Call {
+// GetRefreshTokenFamily indicates an expected call of GetRefreshTokenFamily.
+func (mr *MockUserCacheRepositoryMockRecorder) GetRefreshTokenFamily(ctx, familyID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSession", reflect.TypeOf((*MockUserCacheRepository)(nil).GetUserSession), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshTokenFamily", reflect.TypeOf((*MockUserCacheRepository)(nil).GetRefreshTokenFamily), ctx, familyID)
+}
+
+// GetSession mocks base method.
+func (m *MockUserCacheRepository) GetSession(ctx context.Context, userID, sessionID string) (*model.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", ctx, userID, sessionID)
+	ret0, _ := ret[0].(*model.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockUserCacheRepositoryMockRecorder) GetSession(ctx, userID, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockUserCacheRepository)(nil).GetSession), ctx, userID, sessionID)
+}
+
+// IsSuspended mocks base method.
+func (m *MockUserCacheRepository) IsSuspended(ctx context.Context, userID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsSuspended", ctx, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsSuspended indicates an expected call of IsSuspended.
+func (mr *MockUserCacheRepositoryMockRecorder) IsSuspended(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsSuspended", reflect.TypeOf((*MockUserCacheRepository)(nil).IsSuspended), ctx, userID)
+}
+
+// ListSessions mocks base method.
+func (m *MockUserCacheRepository) ListSessions(ctx context.Context, userID string) ([]model.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessions", ctx, userID)
+	ret0, _ := ret[0].([]model.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MockUserCacheRepositoryMockRecorder) ListSessions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MockUserCacheRepository)(nil).ListSessions), ctx, userID)
 }
 
 // Scan mocks base method.
@@ -219,16 +354,101 @@ func (mr *MockUserCacheRepositoryMockRecorder) Set(ctx, key, user interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockUserCacheRepository)(nil).Set), ctx, key, user)
 }
 
-// SetUserSession mocks base method.
-func (m *MockUserCacheRepository) SetUserSession(ctx context.Context, userID, sessionData string) error {
+// SetRefreshToken mocks base method.
+func (m *MockUserCacheRepository) SetRefreshToken(ctx context.Context, tokenHash string, token model.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRefreshToken", ctx, tokenHash, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRefreshToken indicates an expected call of SetRefreshToken.
+func (mr *MockUserCacheRepositoryMockRecorder) SetRefreshToken(ctx, tokenHash, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRefreshToken", reflect.TypeOf((*MockUserCacheRepository)(nil).SetRefreshToken), ctx, tokenHash, token)
+}
+
+// SetRefreshTokenFamily mocks base method.
+func (m *MockUserCacheRepository) SetRefreshTokenFamily(ctx context.Context, familyID, tokenHash string, expiration time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRefreshTokenFamily", ctx, familyID, tokenHash, expiration)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRefreshTokenFamily indicates an expected call of SetRefreshTokenFamily.
+func (mr *MockUserCacheRepositoryMockRecorder) SetRefreshTokenFamily(ctx, familyID, tokenHash, expiration interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRefreshTokenFamily", reflect.TypeOf((*MockUserCacheRepository)(nil).SetRefreshTokenFamily), ctx, familyID, tokenHash, expiration)
+}
+
+// SetSession mocks base method.
+func (m *MockUserCacheRepository) SetSession(ctx context.Context, session model.Session, expiration time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSession", ctx, session, expiration)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSession indicates an expected call of SetSession.
+func (mr *MockUserCacheRepositoryMockRecorder) SetSession(ctx, session, expiration interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSession", reflect.TypeOf((*MockUserCacheRepository)(nil).SetSession), ctx, session, expiration)
+}
+
+// SetSuspended mocks base method.
+func (m *MockUserCacheRepository) SetSuspended(ctx context.Context, userID string, suspended bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSuspended", ctx, userID, suspended)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSuspended indicates an expected call of SetSuspended.
+func (mr *MockUserCacheRepositoryMockRecorder) SetSuspended(ctx, userID, suspended interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSuspended", reflect.TypeOf((*MockUserCacheRepository)(nil).SetSuspended), ctx, userID, suspended)
+}
+
+// SetUserToken mocks base method.
+func (m *MockUserCacheRepository) SetUserToken(ctx context.Context, tokenHash string, token model.UserToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserToken", ctx, tokenHash, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserToken indicates an expected call of SetUserToken.
+func (mr *MockUserCacheRepositoryMockRecorder) SetUserToken(ctx, tokenHash, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserToken", reflect.TypeOf((*MockUserCacheRepository)(nil).SetUserToken), ctx, tokenHash, token)
+}
+
+// SwapRefreshTokenFamily mocks base method.
+func (m *MockUserCacheRepository) SwapRefreshTokenFamily(ctx context.Context, familyID, oldHash, newHash string, expiration time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SwapRefreshTokenFamily", ctx, familyID, oldHash, newHash, expiration)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SwapRefreshTokenFamily indicates an expected call of SwapRefreshTokenFamily.
+func (mr *MockUserCacheRepositoryMockRecorder) SwapRefreshTokenFamily(ctx, familyID, oldHash, newHash, expiration interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SwapRefreshTokenFamily", reflect.TypeOf((*MockUserCacheRepository)(nil).SwapRefreshTokenFamily), ctx, familyID, oldHash, newHash, expiration)
+}
+
+// TouchSession mocks base method.
+func (m *MockUserCacheRepository) TouchSession(ctx context.Context, userID, sessionID string, lastSeenAt time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetUserSession", ctx, userID, sessionData)
+	ret := m.ctrl.Call(m, "TouchSession", ctx, userID, sessionID, lastSeenAt)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SetUserSession indicates an expected call of SetUserSession.
-func (mr *MockUserCacheRepositoryMockRecorder) SetUserSession(ctx, userID, sessionData interface{}) *gomock.Call {
+// TouchSession indicates an expected call of TouchSession.
+func (mr *MockUserCacheRepositoryMockRecorder) TouchSession(ctx, userID, sessionID, lastSeenAt interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserSession", reflect.TypeOf((*MockUserCacheRepository)(nil).SetUserSession), ctx, userID, sessionData)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchSession", reflect.TypeOf((*MockUserCacheRepository)(nil).TouchSession), ctx, userID, sessionID, lastSeenAt)
 }
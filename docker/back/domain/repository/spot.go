@@ -9,12 +9,36 @@ import (
 
 type SpotRepository interface {
 	List(ctx context.Context, qcs []QueryCondition) ([]model.Spot, error)
+	ListPage(ctx context.Context, qcs []QueryCondition, opts ListOptions) ([]model.Spot, string, error)
+	Count(ctx context.Context, qcs []QueryCondition) (int, error)
 	Get(ctx context.Context, id string) (*model.Spot, error)
 	Create(ctx context.Context, spot model.Spot) error
 	BatchCreate(ctx context.Context, spots []model.Spot) error
 	Update(ctx context.Context, id string, spot model.Spot) error
 	Delete(ctx context.Context, id string) error
 	CreateOrUpdate(ctx context.Context, id string, qcs []QueryCondition, spot model.Spot) error
+	ListNearby(ctx context.Context, lat, lng, radiusKm float64) ([]model.SpotWithDistance, error)
+	ListInBounds(ctx context.Context, bounds model.BoundingBox) ([]model.SpotWithDistance, error)
+	Search(ctx context.Context, query SpotSearchQuery) ([]model.SpotWithScore, error)
+	// AdjustRating は評価の集計にstarRateをdelta件(削除時は負数)加えます。
+	AdjustRating(ctx context.Context, spotID string, starRate float64, delta int) error
+}
+
+// SpotOwnerRepository はSpotの運営者として確認されたユーザを保存します。
+type SpotOwnerRepository interface {
+	List(ctx context.Context, qcs []QueryCondition) ([]model.SpotOwner, error)
+	Count(ctx context.Context, qcs []QueryCondition) (int, error)
+	Create(ctx context.Context, owner model.SpotOwner) error
+	// DeleteBySpotAndUser はSpotの運営者からユーザを外します。運営者でない場合も成功として扱います。
+	DeleteBySpotAndUser(ctx context.Context, spotID string, userID string) error
+}
+
+// SpotSearchQuery は全文検索の条件です。Categories, Boundsは指定された場合のみ絞り込みます。
+type SpotSearchQuery struct {
+	Keyword    string
+	Categories []string
+	Bounds     *model.BoundingBox
+	Limit      int
 }
 
 type SpotsCacheRepository interface {
@@ -24,3 +48,11 @@ type SpotsCacheRepository interface {
 	Exists(ctx context.Context, key string) bool
 	Scan(ctx context.Context, match string) ([]string, error)
 }
+
+type SpotClustersCacheRepository interface {
+	Set(ctx context.Context, key string, clusters model.SpotClusters) error
+	Get(ctx context.Context, key string) (*model.SpotClusters, error)
+	Delete(ctx context.Context, key string) error
+	Exists(ctx context.Context, key string) bool
+	Scan(ctx context.Context, match string) ([]string, error)
+}
@@ -0,0 +1,33 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+)
+
+type RecoveryCodeRepository interface {
+	List(ctx context.Context, qcs []QueryCondition) ([]model.RecoveryCode, error)
+	BatchCreate(ctx context.Context, codes []model.RecoveryCode) error
+	// Consume はリカバリーコードを削除し、削除できた場合はtrueを返します。同時に使われた場合も一度しか成功しません。
+	Consume(ctx context.Context, id string) (bool, error)
+	// DeleteByUserID はユーザのリカバリーコードをすべて削除します。
+	DeleteByUserID(ctx context.Context, userID string) error
+}
+
+// TwoFactorCacheRepository は2段階認証のログイン途中の情報と、設定途中の秘密鍵、使用済みのワンタイムパスワードを保存します。
+type TwoFactorCacheRepository interface {
+	// SetChallenge はExpiresAtまで有効なチャレンジを保存します。
+	SetChallenge(ctx context.Context, challengeHash string, challenge model.TwoFactorChallenge) error
+	GetChallenge(ctx context.Context, challengeHash string) (*model.TwoFactorChallenge, error)
+	// ConsumeChallenge はチャレンジを取得すると同時に削除します。存在しない場合はErrCacheMissを返します。
+	ConsumeChallenge(ctx context.Context, challengeHash string) (*model.TwoFactorChallenge, error)
+	// SetPendingSecret は設定を確認するまでの間、新しい秘密鍵を保存します。
+	SetPendingSecret(ctx context.Context, userID string, secret string, expiration time.Duration) error
+	GetPendingSecret(ctx context.Context, userID string) (string, error)
+	DeletePendingSecret(ctx context.Context, userID string) error
+	// MarkTOTPUsed はワンタイムパスワードの時間ステップを使用済みにします。既に使用済みの場合はfalseを返します。
+	MarkTOTPUsed(ctx context.Context, userID string, counter int64, expiration time.Duration) (bool, error)
+}
@@ -3,12 +3,15 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/tusmasoma/campfinder/docker/back/domain/model"
 )
 
 type UserRepository interface {
 	List(ctx context.Context, qcs []QueryCondition) ([]model.User, error)
+	ListPage(ctx context.Context, qcs []QueryCondition, opts ListOptions) ([]model.User, string, error)
+	Count(ctx context.Context, qcs []QueryCondition) (int, error)
 	Get(ctx context.Context, id string) (*model.User, error)
 	Create(ctx context.Context, user model.User) error
 	Update(ctx context.Context, id string, spot model.User) error
@@ -17,9 +20,33 @@ type UserRepository interface {
 
 type UserCacheRepository interface {
 	Set(ctx context.Context, key string, user model.User) error
-	SetUserSession(ctx context.Context, userID string, sessionData string) error
 	Get(ctx context.Context, key string) (*model.User, error)
-	GetUserSession(ctx context.Context, userID string) (string, error)
+	// SetSession はexpiration経過後に自動で削除されるセッションを保存します。
+	SetSession(ctx context.Context, session model.Session, expiration time.Duration) error
+	GetSession(ctx context.Context, userID string, sessionID string) (*model.Session, error)
+	ListSessions(ctx context.Context, userID string) ([]model.Session, error)
+	// TouchSession はセッションの有効期限を変えずにLastSeenAtを更新します。
+	TouchSession(ctx context.Context, userID string, sessionID string, lastSeenAt time.Time) error
+	DeleteSession(ctx context.Context, userID string, sessionID string) error
+	// SetRefreshToken はトークンのハッシュをキーに、ExpiresAtまで有効なリフレッシュトークンを保存します。
+	SetRefreshToken(ctx context.Context, tokenHash string, token model.RefreshToken) error
+	GetRefreshToken(ctx context.Context, tokenHash string) (*model.RefreshToken, error)
+	// SetRefreshTokenFamily はFamilyで現在有効なトークンのハッシュを保存します。
+	SetRefreshTokenFamily(ctx context.Context, familyID string, tokenHash string, expiration time.Duration) error
+	GetRefreshTokenFamily(ctx context.Context, familyID string) (string, error)
+	// SwapRefreshTokenFamily はFamilyで現在有効なトークンのハッシュがoldHashの場合に限り、不可分にnewHashへ置き換えます。
+	// 別のハッシュに置き換わっていた場合はfalseを、Familyが存在しない場合はErrCacheMissを返します。
+	SwapRefreshTokenFamily(
+		ctx context.Context, familyID string, oldHash string, newHash string, expiration time.Duration,
+	) (bool, error)
+	DeleteRefreshTokenFamily(ctx context.Context, familyID string) error
+	// SetUserToken はExpiresAtまで有効な使い捨てのトークンを保存します。同じユーザ・用途で以前に発行したトークンは無効になります。
+	SetUserToken(ctx context.Context, tokenHash string, token model.UserToken) error
+	// ConsumeUserToken はトークンを取得すると同時に削除します。存在しない場合はErrCacheMissを返します。
+	ConsumeUserToken(ctx context.Context, purpose model.UserTokenPurpose, tokenHash string) (*model.UserToken, error)
+	// SetSuspended はリクエストごとにデータベースを参照せずに利用停止中のユーザを拒否できるよう、利用停止の状態を保存します。
+	SetSuspended(ctx context.Context, userID string, suspended bool) error
+	IsSuspended(ctx context.Context, userID string) (bool, error)
 	Delete(ctx context.Context, key string) error
 	Exists(ctx context.Context, key string) bool
 	Scan(ctx context.Context, match string) ([]string, error)
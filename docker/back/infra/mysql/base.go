@@ -3,6 +3,8 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"reflect"
 
 	"github.com/doug-martin/goqu/v9"
@@ -65,11 +67,15 @@ func (b *base[T]) structScanRows(rows *sql.Rows) ([]T, error) {
 }
 
 func (b *base[T]) List(ctx context.Context, qcs []repository.QueryCondition) ([]T, error) {
-	var whereClauses []goqu.Expression
-	for _, qc := range qcs {
-		whereClauses = append(whereClauses, goqu.C(qc.Field).Eq(qc.Value))
+	whereClauses, err := buildConditions(qcs)
+	if err != nil {
+		return nil, err
 	}
+	return b.listWhere(ctx, whereClauses...)
+}
 
+// listWhereは、任意のgoqu式で絞り込んだ結果を取得するためのメソッドです。
+func (b *base[T]) listWhere(ctx context.Context, whereClauses ...goqu.Expression) ([]T, error) {
 	query, _, err := b.dialect.From(b.tableName).Select("*").Where(whereClauses...).ToSQL()
 	if err != nil {
 		return nil, err
@@ -91,6 +97,77 @@ func (b *base[T]) List(ctx context.Context, qcs []repository.QueryCondition) ([]
 	return entitys, nil
 }
 
+// ListPageは、カーソルベースでページ分割した結果と次ページのカーソルを返します。最終ページの場合、カーソルは空です。
+func (b *base[T]) ListPage(
+	ctx context.Context,
+	qcs []repository.QueryCondition,
+	opts repository.ListOptions,
+) ([]T, string, error) {
+	whereClauses, err := buildConditions(qcs)
+	if err != nil {
+		return nil, "", err
+	}
+
+	o := parseOrder(opts.OrderBy)
+	if opts.Cursor != "" {
+		value, id, decodeErr := decodeCursor[T](o, opts.Cursor)
+		if decodeErr != nil {
+			return nil, "", decodeErr
+		}
+		whereClauses = append(whereClauses, o.keysetCondition(value, id))
+	}
+
+	ds := b.dialect.From(b.tableName).Select("*").Where(whereClauses...).Order(o.orderedExpressions()...)
+	if opts.Limit > 0 {
+		// 次ページの有無を判定するために1件多く取得する
+		ds = ds.Limit(uint(opts.Limit + 1))
+	}
+	query, _, err := ds.ToSQL()
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, err := b.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	entitys, err := b.structScanRows(rows)
+	if err != nil {
+		return nil, "", err
+	}
+	if err = rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	if opts.Limit <= 0 || len(entitys) <= opts.Limit {
+		return entitys, "", nil
+	}
+	entitys = entitys[:opts.Limit]
+	nextCursor, err := encodeCursor(o, entitys[len(entitys)-1])
+	if err != nil {
+		return nil, "", err
+	}
+	return entitys, nextCursor, nil
+}
+
+func (b *base[T]) Count(ctx context.Context, qcs []repository.QueryCondition) (int, error) {
+	whereClauses, err := buildConditions(qcs)
+	if err != nil {
+		return 0, err
+	}
+	query, _, err := b.dialect.From(b.tableName).Select(goqu.COUNT("*")).Where(whereClauses...).ToSQL()
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	if err = b.db.QueryRowContext(ctx, query).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 func (b *base[T]) Get(ctx context.Context, id string) (*T, error) {
 	var entity T
 	query, _, err := b.dialect.From(b.tableName).Select("*").Where(goqu.C("id").Eq(id)).ToSQL()
@@ -98,7 +175,9 @@ func (b *base[T]) Get(ctx context.Context, id string) (*T, error) {
 		return nil, err
 	}
 	row := b.db.QueryRowContext(ctx, query)
-	if err = b.structScanRow(&entity, row); err != nil {
+	if err = b.structScanRow(&entity, row); errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	} else if err != nil {
 		return nil, err
 	}
 	return &entity, nil
@@ -151,3 +230,70 @@ func (b *base[T]) CreateOrUpdate(ctx context.Context, id string, qcs []repositor
 	}
 	return b.Create(ctx, entity)
 }
+
+// buildConditionsは、QueryConditionのスライスをgoquの式に変換します。
+func buildConditions(qcs []repository.QueryCondition) ([]goqu.Expression, error) {
+	exps := make([]goqu.Expression, 0, len(qcs))
+	for _, qc := range qcs {
+		exp, err := buildCondition(qc)
+		if err != nil {
+			return nil, err
+		}
+		exps = append(exps, exp)
+	}
+	return exps, nil
+}
+
+func buildCondition(qc repository.QueryCondition) (goqu.Expression, error) {
+	if len(qc.Or) > 0 {
+		exps, err := buildConditions(qc.Or)
+		if err != nil {
+			return nil, err
+		}
+		return goqu.Or(exps...), nil
+	}
+	if len(qc.And) > 0 {
+		exps, err := buildConditions(qc.And)
+		if err != nil {
+			return nil, err
+		}
+		return goqu.And(exps...), nil
+	}
+
+	col := goqu.C(qc.Field)
+	switch qc.Operator {
+	case "", repository.OpEq:
+		return col.Eq(qc.Value), nil
+	case repository.OpNe:
+		return col.Neq(qc.Value), nil
+	case repository.OpLt:
+		return col.Lt(qc.Value), nil
+	case repository.OpLte:
+		return col.Lte(qc.Value), nil
+	case repository.OpGt:
+		return col.Gt(qc.Value), nil
+	case repository.OpGte:
+		return col.Gte(qc.Value), nil
+	case repository.OpIn:
+		if v := reflect.ValueOf(qc.Value); v.Kind() != reflect.Slice || v.Len() == 0 {
+			return nil, fmt.Errorf("operator %s on %s requires a non-empty slice", qc.Operator, qc.Field)
+		}
+		return col.In(qc.Value), nil
+	case repository.OpLike:
+		// goquのmysql dialectではLikeがLIKE BINARYになるため、照合順序に従うILikeを使う
+		return col.ILike(qc.Value), nil
+	case repository.OpBetween:
+		r, ok := qc.Value.(repository.Range)
+		if !ok {
+			return nil, fmt.Errorf("operator %s on %s requires repository.Range", qc.Operator, qc.Field)
+		}
+		return col.Between(goqu.Range(r.Start, r.End)), nil
+	case repository.OpIsNull:
+		if isNull, ok := qc.Value.(bool); ok && !isNull {
+			return col.IsNotNull(), nil
+		}
+		return col.IsNull(), nil
+	default:
+		return nil, fmt.Errorf("unsupported operator: %s", qc.Operator)
+	}
+}
@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -89,7 +90,244 @@ func TestBase(t *testing.T) {
 	err = repo.Delete(ctx, updatedItem.ID)
 	ValidateErr(t, err, nil)
 	_, err = repo.Get(ctx, updatedItem.ID)
-	if err == nil {
-		t.Errorf("Expected error for deleted item, got nil")
+	ValidateErr(t, err, repository.ErrNotFound)
+}
+
+func TestBase_ListWithConditions(t *testing.T) {
+	dialect := goqu.Dialect("mysql")
+	ctx := context.Background()
+	userID := uuid.NewString()
+	now := time.Now()
+	items := []Item{
+		{ID: uuid.NewString(), UserID: userID, Text: "apple", Count: 1, CreatedAt: now, UpdatedAt: now},
+		{ID: uuid.NewString(), UserID: userID, Text: "banana", Count: 2, CreatedAt: now, UpdatedAt: now},
+		{ID: uuid.NewString(), UserID: userID, Text: "cherry", Count: 3, CreatedAt: now, UpdatedAt: now},
+		{ID: uuid.NewString(), UserID: userID, Text: "apricot", Count: 4, CreatedAt: now, UpdatedAt: now},
+	}
+	repo := newBase[Item](db, &dialect, "TestItems")
+	err := repo.BatchCreate(ctx, items)
+	ValidateErr(t, err, nil)
+
+	ownItems := repository.QueryCondition{Field: "user_id", Value: userID}
+
+	patterns := []struct {
+		name    string
+		qcs     []repository.QueryCondition
+		want    []Item
+		wantErr error
+	}{
+		{
+			name: "ne",
+			qcs:  []repository.QueryCondition{ownItems, {Field: "text", Operator: repository.OpNe, Value: "apple"}},
+			want: []Item{items[1], items[2], items[3]},
+		},
+		{
+			name: "lt",
+			qcs:  []repository.QueryCondition{ownItems, {Field: "count", Operator: repository.OpLt, Value: 2}},
+			want: []Item{items[0]},
+		},
+		{
+			name: "lte",
+			qcs:  []repository.QueryCondition{ownItems, {Field: "count", Operator: repository.OpLte, Value: 2}},
+			want: []Item{items[0], items[1]},
+		},
+		{
+			name: "gt",
+			qcs:  []repository.QueryCondition{ownItems, {Field: "count", Operator: repository.OpGt, Value: 3}},
+			want: []Item{items[3]},
+		},
+		{
+			name: "gte",
+			qcs:  []repository.QueryCondition{ownItems, {Field: "count", Operator: repository.OpGte, Value: 3}},
+			want: []Item{items[2], items[3]},
+		},
+		{
+			name: "in",
+			qcs: []repository.QueryCondition{
+				ownItems,
+				{Field: "text", Operator: repository.OpIn, Value: []string{"banana", "cherry"}},
+			},
+			want: []Item{items[1], items[2]},
+		},
+		{
+			name: "like",
+			qcs:  []repository.QueryCondition{ownItems, {Field: "text", Operator: repository.OpLike, Value: "AP%"}},
+			want: []Item{items[0], items[3]},
+		},
+		{
+			name: "between",
+			qcs: []repository.QueryCondition{
+				ownItems,
+				{Field: "count", Operator: repository.OpBetween, Value: repository.Range{Start: 2, End: 3}},
+			},
+			want: []Item{items[1], items[2]},
+		},
+		{
+			name: "is not null",
+			qcs:  []repository.QueryCondition{ownItems, {Field: "text", Operator: repository.OpIsNull, Value: false}},
+			want: items,
+		},
+		{
+			name: "or group",
+			qcs: []repository.QueryCondition{
+				ownItems,
+				{Or: []repository.QueryCondition{
+					{Field: "text", Value: "apple"},
+					{And: []repository.QueryCondition{
+						{Field: "count", Operator: repository.OpGt, Value: 2},
+						{Field: "text", Operator: repository.OpLike, Value: "c%"},
+					}},
+				}},
+			},
+			want: []Item{items[0], items[2]},
+		},
+		{
+			name:    "Fail: unsupported operator",
+			qcs:     []repository.QueryCondition{{Field: "text", Operator: "regexp", Value: "a.*"}},
+			wantErr: fmt.Errorf("unsupported operator: regexp"),
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.List(ctx, tt.qcs)
+			ValidateErr(t, err, tt.wantErr)
+			if tt.wantErr != nil {
+				return
+			}
+			less := func(a, b Item) bool { return a.Count < b.Count }
+			if d := cmp.Diff(
+				got,
+				tt.want,
+				cmpopts.IgnoreFields(Item{}, "CreatedAt", "UpdatedAt"),
+				cmpopts.SortSlices(less),
+			); len(d) != 0 {
+				t.Errorf("List() differs: (-got +want)\n%s", d)
+			}
+		})
+	}
+}
+
+func TestBase_ListPage(t *testing.T) {
+	dialect := goqu.Dialect("mysql")
+	ctx := context.Background()
+	userID := uuid.NewString()
+	now := time.Now()
+	items := []Item{
+		{ID: uuid.NewString(), UserID: userID, Text: "a", Count: 2, CreatedAt: now, UpdatedAt: now},
+		{ID: uuid.NewString(), UserID: userID, Text: "b", Count: 1, CreatedAt: now, UpdatedAt: now},
+		{ID: uuid.NewString(), UserID: userID, Text: "c", Count: 2, CreatedAt: now, UpdatedAt: now},
+	}
+	repo := newBase[Item](db, &dialect, "TestItems")
+	err := repo.BatchCreate(ctx, items)
+	ValidateErr(t, err, nil)
+
+	qcs := []repository.QueryCondition{{Field: "user_id", Value: userID}}
+	opts := repository.ListOptions{Limit: 2, OrderBy: "-count"}
+
+	// count降順、同値はid降順で全件を辿れること
+	var got []Item
+	for {
+		page, next, listErr := repo.ListPage(ctx, qcs, opts)
+		ValidateErr(t, listErr, nil)
+		got = append(got, page...)
+		if next == "" {
+			break
+		}
+		opts.Cursor = next
+	}
+	if len(got) != len(items) {
+		t.Fatalf("ListPage() got %d items, want %d", len(got), len(items))
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i-1].Count < got[i].Count || (got[i-1].Count == got[i].Count && got[i-1].ID < got[i].ID) {
+			t.Errorf("ListPage() items are not sorted: %v", got)
+		}
+	}
+
+	count, err := repo.Count(ctx, qcs)
+	ValidateErr(t, err, nil)
+	if count != len(items) {
+		t.Errorf("Count() got = %d, want %d", count, len(items))
+	}
+
+	// 並び替え条件が異なるカーソルは無効
+	_, _, err = repo.ListPage(ctx, qcs, repository.ListOptions{Limit: 2, Cursor: opts.Cursor})
+	ValidateErr(t, err, repository.ErrInvalidCursor)
+}
+
+func Test_buildCondition(t *testing.T) {
+	dialect := goqu.Dialect("mysql")
+
+	patterns := []struct {
+		name    string
+		qc      repository.QueryCondition
+		want    string
+		wantErr error
+	}{
+		{
+			name: "default operator is eq",
+			qc:   repository.QueryCondition{Field: "category", Value: "campsite"},
+			want: "SELECT * FROM `T` WHERE (`category` = 'campsite')",
+		},
+		{
+			name: "in",
+			qc:   repository.QueryCondition{Field: "category", Operator: repository.OpIn, Value: []string{"campsite", "spa"}},
+			want: "SELECT * FROM `T` WHERE (`category` IN ('campsite', 'spa'))",
+		},
+		{
+			name: "like",
+			qc:   repository.QueryCondition{Field: "name", Operator: repository.OpLike, Value: "%森%"},
+			want: "SELECT * FROM `T` WHERE (`name` LIKE '%森%')",
+		},
+		{
+			name: "between",
+			qc: repository.QueryCondition{
+				Field:    "lat",
+				Operator: repository.OpBetween,
+				Value:    repository.Range{Start: 43, End: 44},
+			},
+			want: "SELECT * FROM `T` WHERE (`lat` BETWEEN 43 AND 44)",
+		},
+		{
+			name: "is null",
+			qc:   repository.QueryCondition{Field: "description", Operator: repository.OpIsNull, Value: true},
+			want: "SELECT * FROM `T` WHERE (`description` IS NULL)",
+		},
+		{
+			name: "or group",
+			qc: repository.QueryCondition{Or: []repository.QueryCondition{
+				{Field: "category", Value: "spa"},
+				{Field: "lat", Operator: repository.OpGte, Value: 43.5},
+			}},
+			want: "SELECT * FROM `T` WHERE ((`category` = 'spa') OR (`lat` >= 43.5))",
+		},
+		{
+			name:    "Fail: in without slice",
+			qc:      repository.QueryCondition{Field: "category", Operator: repository.OpIn, Value: "spa"},
+			wantErr: fmt.Errorf("operator in on category requires a non-empty slice"),
+		},
+		{
+			name:    "Fail: between without range",
+			qc:      repository.QueryCondition{Field: "lat", Operator: repository.OpBetween, Value: []int{43, 44}},
+			wantErr: fmt.Errorf("operator between on lat requires repository.Range"),
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			exp, err := buildCondition(tt.qc)
+			ValidateErr(t, err, tt.wantErr)
+			if tt.wantErr != nil {
+				return
+			}
+			got, _, err := dialect.From("T").Where(exp).ToSQL()
+			ValidateErr(t, err, nil)
+			if got != tt.want {
+				t.Errorf("buildCondition() \n got = %v,\n want = %v", got, tt.want)
+			}
+		})
 	}
 }
@@ -2,12 +2,18 @@
 package mysql
 
 import (
+	"context"
+	"sort"
+
 	"github.com/doug-martin/goqu/v9"
 
 	"github.com/tusmasoma/campfinder/docker/back/domain/model"
 	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
 )
 
+// replyOrderBy は返信の並び順で、ListPageにOrderByとして"created"を指定したときと同じカーソルを返すために使います。
+const replyOrderBy = "created"
+
 type commentRepository struct {
 	*base[model.Comment]
 }
@@ -17,3 +23,78 @@ func NewCommentRepository(db repository.SQLExecutor, dialect *goqu.DialectWrappe
 		base: newBase[model.Comment](db, dialect, "Comment"),
 	}
 }
+
+// ListReplyPagesは、スレッドごとにLIMITを付けたクエリをUNION ALLでまとめて取得します。
+// 返信の多いスレッドがあっても、他のスレッドの返信を取りこぼしません。
+func (r *commentRepository) ListReplyPages(
+	ctx context.Context,
+	rootIDs []string,
+	limit int,
+) (map[string]repository.ReplyPage, error) {
+	if len(rootIDs) == 0 || limit <= 0 {
+		return map[string]repository.ReplyPage{}, nil
+	}
+
+	o := parseOrder(replyOrderBy)
+	var ds *goqu.SelectDataset
+	for _, rootID := range rootIDs {
+		// 次ページの有無を判定するために1件多く取得する
+		thread := r.dialect.From(r.tableName).Select("*").
+			Where(goqu.C("root_id").Eq(rootID)).
+			Order(o.orderedExpressions()...).
+			Limit(uint(limit + 1))
+		if ds == nil {
+			ds = thread
+		} else {
+			ds = ds.UnionAll(thread)
+		}
+	}
+	query, _, err := ds.ToSQL()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	replies, err := r.structScanRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return replyPages(replies, limit)
+}
+
+// replyPagesは、返信をスレッドごとに古い順に並べ、limit件を超えるスレッドには次ページのカーソルを付けます。
+// UNION ALLの結果の並び順は保証されないため、ここで並べ直します。
+func replyPages(replies []model.Comment, limit int) (map[string]repository.ReplyPage, error) {
+	sort.SliceStable(replies, func(i, j int) bool {
+		if !replies[i].Created.Equal(replies[j].Created) {
+			return replies[i].Created.Before(replies[j].Created)
+		}
+		return replies[i].ID.String() < replies[j].ID.String()
+	})
+
+	o := parseOrder(replyOrderBy)
+	pages := make(map[string]repository.ReplyPage)
+	for _, reply := range replies {
+		rootID := reply.RootID.UUID.String()
+		page := pages[rootID]
+		if len(page.Replies) < limit {
+			page.Replies = append(page.Replies, reply)
+		} else if page.NextCursor == "" {
+			nextCursor, err := encodeCursor(o, page.Replies[limit-1])
+			if err != nil {
+				return nil, err
+			}
+			page.NextCursor = nextCursor
+		}
+		pages[rootID] = page
+	}
+	return pages, nil
+}
@@ -1 +1,50 @@
 package mysql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+)
+
+func Test_replyPages(t *testing.T) {
+	t.Parallel()
+
+	rootA := uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554")
+	rootB := uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052")
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	reply := func(id string, root uuid.UUID, minutes int) model.Comment {
+		return model.Comment{
+			ID:      uuid.MustParse(id),
+			RootID:  uuid.NullUUID{UUID: root, Valid: true},
+			Created: created.Add(time.Duration(minutes) * time.Minute),
+		}
+	}
+	a1 := reply("00000000-0000-0000-0000-00000000000a", rootA, 1)
+	a2 := reply("00000000-0000-0000-0000-00000000000b", rootA, 2)
+	// 作成日時が同じ返信はidの順に並べる
+	a3 := reply("00000000-0000-0000-0000-000000000001", rootA, 2)
+	a4 := reply("00000000-0000-0000-0000-000000000002", rootA, 3)
+	b1 := reply("00000000-0000-0000-0000-000000000003", rootB, 5)
+
+	// UNION ALLの結果はスレッドや作成日時の順に並ぶとは限らない
+	pages, err := replyPages([]model.Comment{a4, b1, a2, a1, a3}, 2)
+	ValidateErr(t, err, nil)
+
+	a := pages[rootA.String()]
+	if len(a.Replies) != 2 || a.Replies[0].ID != a1.ID || a.Replies[1].ID != a3.ID {
+		t.Fatalf("replies of rootA = %+v, want [%v %v]", a.Replies, a1.ID, a3.ID)
+	}
+	value, id, err := decodeCursor[model.Comment](parseOrder(replyOrderBy), a.NextCursor)
+	ValidateErr(t, err, nil)
+	if value != a3.Created || id != a3.ID {
+		t.Errorf("cursor of rootA = (%v, %v), want (%v, %v)", value, id, a3.Created, a3.ID)
+	}
+
+	b := pages[rootB.String()]
+	if len(b.Replies) != 1 || b.Replies[0].ID != b1.ID || b.NextCursor != "" {
+		t.Errorf("page of rootB = %+v, want only %v without cursor", b, b1.ID)
+	}
+}
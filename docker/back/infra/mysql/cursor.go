@@ -0,0 +1,150 @@
+package mysql
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+)
+
+const idColumn = "id"
+
+// cursorは、前ページの最後の行の並び替えカラムの値とidを保持します。
+// クライアントには不透明な文字列として渡すため、base64urlでエンコードします。
+type cursor struct {
+	OrderBy string          `json:"o"`
+	Value   json.RawMessage `json:"v"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// orderは、"-"始まりを降順として並び替え条件を解釈したものです。
+type order struct {
+	column string
+	desc   bool
+}
+
+func parseOrder(orderBy string) order {
+	if orderBy == "" {
+		return order{column: idColumn}
+	}
+	if strings.HasPrefix(orderBy, "-") {
+		return order{column: strings.TrimPrefix(orderBy, "-"), desc: true}
+	}
+	return order{column: orderBy}
+}
+
+func (o order) String() string {
+	if o.desc {
+		return "-" + o.column
+	}
+	return o.column
+}
+
+func (o order) orderedExpressions() []exp.OrderedExpression {
+	columns := []string{o.column}
+	if o.column != idColumn {
+		columns = append(columns, idColumn)
+	}
+	exps := make([]exp.OrderedExpression, 0, len(columns))
+	for _, column := range columns {
+		if o.desc {
+			exps = append(exps, goqu.C(column).Desc())
+		} else {
+			exps = append(exps, goqu.C(column).Asc())
+		}
+	}
+	return exps
+}
+
+// keysetConditionは、カーソルの位置より後ろの行を取得する条件を返します。
+func (o order) keysetCondition(value, id any) goqu.Expression {
+	after := func(column string, v any) goqu.Expression {
+		if o.desc {
+			return goqu.C(column).Lt(v)
+		}
+		return goqu.C(column).Gt(v)
+	}
+	if o.column == idColumn {
+		return after(idColumn, id)
+	}
+	return goqu.Or(
+		after(o.column, value),
+		goqu.And(goqu.C(o.column).Eq(value), after(idColumn, id)),
+	)
+}
+
+// fieldByColumnは、dbタグがcolumnに一致するフィールドを返します。
+func fieldByColumn(v reflect.Value, column string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if strings.Split(t.Field(i).Tag.Get("db"), ",")[0] == column {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func encodeCursor[T any](o order, entity T) (string, error) {
+	v := reflect.ValueOf(entity)
+	orderField, ok := fieldByColumn(v, o.column)
+	if !ok {
+		return "", fmt.Errorf("unknown order column: %s", o.column)
+	}
+	idField, ok := fieldByColumn(v, idColumn)
+	if !ok {
+		return "", fmt.Errorf("unknown order column: %s", idColumn)
+	}
+
+	value, err := json.Marshal(orderField.Interface())
+	if err != nil {
+		return "", err
+	}
+	id, err := json.Marshal(idField.Interface())
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(cursor{OrderBy: o.String(), Value: value, ID: id})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursorは、カーソルをTのフィールドの型に合わせてデコードし、並び替えカラムの値とidを返します。
+func decodeCursor[T any](o order, encoded string) (any, any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, nil, repository.ErrInvalidCursor
+	}
+	var c cursor
+	if err = json.Unmarshal(data, &c); err != nil || c.OrderBy != o.String() {
+		return nil, nil, repository.ErrInvalidCursor
+	}
+
+	var entity T
+	v := reflect.ValueOf(entity)
+	orderField, ok := fieldByColumn(v, o.column)
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown order column: %s", o.column)
+	}
+	idField, ok := fieldByColumn(v, idColumn)
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown order column: %s", idColumn)
+	}
+
+	value := reflect.New(orderField.Type())
+	if err = json.Unmarshal(c.Value, value.Interface()); err != nil {
+		return nil, nil, repository.ErrInvalidCursor
+	}
+	id := reflect.New(idField.Type())
+	if err = json.Unmarshal(c.ID, id.Interface()); err != nil {
+		return nil, nil, repository.ErrInvalidCursor
+	}
+	return value.Elem().Interface(), id.Elem().Interface(), nil
+}
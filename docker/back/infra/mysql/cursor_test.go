@@ -0,0 +1,58 @@
+package mysql
+
+import (
+	"testing"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+)
+
+func Test_cursor(t *testing.T) {
+	item := Item{ID: "5c5323e9-c78f-4dac-94ef-d34ab5ea8fed", Count: 3}
+
+	patterns := []struct {
+		name      string
+		encodeBy  string
+		decodeBy  string
+		wantValue any
+		wantErr   error
+	}{
+		{
+			name:      "default order is id",
+			wantValue: item.ID,
+		},
+		{
+			name:      "desc",
+			encodeBy:  "-count",
+			decodeBy:  "-count",
+			wantValue: item.Count,
+		},
+		{
+			name:     "order mismatch",
+			encodeBy: "count",
+			decodeBy: "-count",
+			wantErr:  repository.ErrInvalidCursor,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+
+			encoded, err := encodeCursor(parseOrder(tt.encodeBy), item)
+			ValidateErr(t, err, nil)
+
+			value, id, err := decodeCursor[Item](parseOrder(tt.decodeBy), encoded)
+			ValidateErr(t, err, tt.wantErr)
+			if tt.wantErr != nil {
+				return
+			}
+			if value != tt.wantValue || id != item.ID {
+				t.Errorf("decodeCursor() got = (%v, %v), want (%v, %v)", value, id, tt.wantValue, item.ID)
+			}
+		})
+	}
+
+	_, _, err := decodeCursor[Item](parseOrder(""), "not-a-cursor")
+	ValidateErr(t, err, repository.ErrInvalidCursor)
+}
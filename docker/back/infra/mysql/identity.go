@@ -0,0 +1,18 @@
+package mysql
+
+import (
+	"github.com/doug-martin/goqu/v9"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+)
+
+type identityRepository struct {
+	*base[model.Identity]
+}
+
+func NewIdentityRepository(db repository.SQLExecutor, dialect *goqu.DialectWrapper) repository.IdentityRepository {
+	return &identityRepository{
+		base: newBase[model.Identity](db, dialect, "Identity"),
+	}
+}
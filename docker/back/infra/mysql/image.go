@@ -1,6 +1,8 @@
 package mysql
 
 import (
+	"context"
+
 	"github.com/doug-martin/goqu/v9"
 
 	"github.com/tusmasoma/campfinder/docker/back/domain/model"
@@ -16,3 +18,25 @@ func NewImageRepository(db repository.SQLExecutor, dialect *goqu.DialectWrapper)
 		base: newBase[model.Image](db, dialect, "Image"),
 	}
 }
+
+type imageReportRepository struct {
+	*base[model.ImageReport]
+}
+
+func NewImageReportRepository(
+	db repository.SQLExecutor,
+	dialect *goqu.DialectWrapper,
+) repository.ImageReportRepository {
+	return &imageReportRepository{
+		base: newBase[model.ImageReport](db, dialect, "ImageReport"),
+	}
+}
+
+func (irr *imageReportRepository) DeleteByImageID(ctx context.Context, imageID string) error {
+	query, _, err := irr.dialect.Delete(irr.tableName).Where(goqu.C("image_id").Eq(imageID)).ToSQL()
+	if err != nil {
+		return err
+	}
+	_, err = irr.db.ExecContext(ctx, query)
+	return err
+}
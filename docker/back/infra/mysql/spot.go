@@ -2,10 +2,17 @@
 package mysql
 
 import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+
 	"github.com/doug-martin/goqu/v9"
 
 	"github.com/tusmasoma/campfinder/docker/back/domain/model"
 	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+	"github.com/tusmasoma/campfinder/docker/back/internal/geo"
 )
 
 type spotRepository struct {
@@ -17,3 +24,185 @@ func NewSpotRepository(db repository.SQLExecutor, dialect *goqu.DialectWrapper)
 		base: newBase[model.Spot](db, dialect, "Spot"),
 	}
 }
+
+func (sr *spotRepository) Create(ctx context.Context, spot model.Spot) error {
+	return sr.base.Create(ctx, withGeohash(spot))
+}
+
+func (sr *spotRepository) BatchCreate(ctx context.Context, spots []model.Spot) error {
+	hashed := make([]model.Spot, 0, len(spots))
+	for _, spot := range spots {
+		hashed = append(hashed, withGeohash(spot))
+	}
+	return sr.base.BatchCreate(ctx, hashed)
+}
+
+func (sr *spotRepository) Update(ctx context.Context, id string, spot model.Spot) error {
+	return sr.base.Update(ctx, id, withGeohash(spot))
+}
+
+func (sr *spotRepository) CreateOrUpdate(
+	ctx context.Context,
+	id string,
+	qcs []repository.QueryCondition,
+	spot model.Spot,
+) error {
+	return sr.base.CreateOrUpdate(ctx, id, qcs, withGeohash(spot))
+}
+
+// ListNearby は中心点から半径radiusKm以内のSpotを距離の近い順に返します。
+func (sr *spotRepository) ListNearby(
+	ctx context.Context,
+	lat, lng, radiusKm float64,
+) ([]model.SpotWithDistance, error) {
+	spots, err := sr.listInBounds(ctx, geo.BoundsAround(lat, lng, radiusKm))
+	if err != nil {
+		return nil, err
+	}
+	return sortByDistance(spots, lat, lng, radiusKm), nil
+}
+
+// ListInBounds は矩形内のSpotを矩形の中心から近い順に返します。
+func (sr *spotRepository) ListInBounds(
+	ctx context.Context,
+	bounds model.BoundingBox,
+) ([]model.SpotWithDistance, error) {
+	spots, err := sr.listInBounds(ctx, bounds)
+	if err != nil {
+		return nil, err
+	}
+	lat, lng := bounds.Center()
+	return sortByDistance(spots, lat, lng, math.Inf(1)), nil
+}
+
+// listInBounds は矩形内のSpotを返します。
+func (sr *spotRepository) listInBounds(ctx context.Context, bounds model.BoundingBox) ([]model.Spot, error) {
+	return sr.listWhere(ctx, boundsConditions(bounds)...)
+}
+
+// Search はname, address, descriptionに対する全文検索の結果を関連度の高い順に返します。
+func (sr *spotRepository) Search(ctx context.Context, q repository.SpotSearchQuery) ([]model.SpotWithScore, error) {
+	match := goqu.L("MATCH(name, address, description) AGAINST (? IN NATURAL LANGUAGE MODE)", q.Keyword)
+
+	whereClauses := []goqu.Expression{match}
+	if len(q.Categories) > 0 {
+		whereClauses = append(whereClauses, goqu.C("category").In(q.Categories))
+	}
+	if q.Bounds != nil {
+		whereClauses = append(whereClauses, boundsConditions(*q.Bounds)...)
+	}
+
+	ds := sr.dialect.From(sr.tableName).
+		Select(goqu.Star(), match.As("score")).
+		Where(whereClauses...).
+		Order(goqu.I("score").Desc(), goqu.C(idColumn).Asc())
+	if q.Limit > 0 {
+		ds = ds.Limit(uint(q.Limit))
+	}
+	query, _, err := ds.ToSQL()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := sr.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []model.SpotWithScore
+	for rows.Next() {
+		var result model.SpotWithScore
+		v := reflect.ValueOf(&result.Spot).Elem()
+		fields := make([]interface{}, 0, v.NumField()+1)
+		for i := 0; i < v.NumField(); i++ {
+			fields = append(fields, v.Field(i).Addr().Interface())
+		}
+		fields = append(fields, &result.Score)
+		if err = rows.Scan(fields...); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// adjustRatingQuery は評価の集計を1文で更新します。
+// MySQLのUPDATEは左から順に代入し、後の式は更新後の値を参照するため、rating_averageは最後に計算します。
+const adjustRatingQuery = `UPDATE Spot SET
+	rating_count = rating_count + ?,
+	rating_sum = rating_sum + ?,
+	rating_histogram = JSON_SET(
+		COALESCE(rating_histogram, JSON_ARRAY(0, 0, 0, 0, 0)),
+		?,
+		JSON_EXTRACT(COALESCE(rating_histogram, JSON_ARRAY(0, 0, 0, 0, 0)), ?) + ?
+	),
+	rating_average = IF(rating_count > 0, rating_sum / rating_count, 0)
+WHERE id = ?`
+
+func (sr *spotRepository) AdjustRating(ctx context.Context, spotID string, starRate float64, delta int) error {
+	path := fmt.Sprintf("$[%d]", model.RatingBucket(starRate))
+	result, err := sr.db.ExecContext(
+		ctx,
+		adjustRatingQuery,
+		delta,
+		starRate*float64(delta),
+		path,
+		path,
+		delta,
+		spotID,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// boundsConditions はgeohashのプレフィックスでインデックスを使って候補を絞り込み、緯度経度で矩形内に限定する条件を返します。
+// geohashの列を追加する前から存在し、まだgeohashが空のSpotは緯度経度の条件だけで判定します。
+func boundsConditions(bounds model.BoundingBox) []goqu.Expression {
+	whereClauses := []goqu.Expression{
+		goqu.C("lat").Between(goqu.Range(bounds.MinLat, bounds.MaxLat)),
+		goqu.C("lng").Between(goqu.Range(bounds.MinLng, bounds.MaxLng)),
+	}
+
+	var prefixClauses []goqu.Expression
+	for _, prefix := range geo.CoveringPrefixes(bounds) {
+		prefixClauses = append(prefixClauses, goqu.C("geohash").Like(prefix+"%"))
+	}
+	if len(prefixClauses) > 0 {
+		prefixClauses = append(prefixClauses, goqu.C("geohash").Eq(""))
+		whereClauses = append(whereClauses, goqu.Or(prefixClauses...))
+	}
+	return whereClauses
+}
+
+func withGeohash(spot model.Spot) model.Spot {
+	spot.Geohash = geo.EncodeGeohash(spot.Lat, spot.Lng, geo.GeohashPrecision)
+	return spot
+}
+
+func sortByDistance(spots []model.Spot, lat, lng, maxDistanceKm float64) []model.SpotWithDistance {
+	results := make([]model.SpotWithDistance, 0, len(spots))
+	for _, spot := range spots {
+		distance := geo.Distance(lat, lng, spot.Lat, spot.Lng)
+		if distance > maxDistanceKm {
+			continue
+		}
+		results = append(results, model.SpotWithDistance{Spot: spot, DistanceKm: distance})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].DistanceKm < results[j].DistanceKm
+	})
+	return results
+}
@@ -0,0 +1,32 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/doug-martin/goqu/v9"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+)
+
+type spotOwnerRepository struct {
+	*base[model.SpotOwner]
+}
+
+func NewSpotOwnerRepository(db repository.SQLExecutor, dialect *goqu.DialectWrapper) repository.SpotOwnerRepository {
+	return &spotOwnerRepository{
+		base: newBase[model.SpotOwner](db, dialect, "SpotOwner"),
+	}
+}
+
+func (sor *spotOwnerRepository) DeleteBySpotAndUser(ctx context.Context, spotID string, userID string) error {
+	query, _, err := sor.dialect.Delete(sor.tableName).Where(
+		goqu.C("spot_id").Eq(spotID),
+		goqu.C("user_id").Eq(userID),
+	).ToSQL()
+	if err != nil {
+		return err
+	}
+	_, err = sor.db.ExecContext(ctx, query)
+	return err
+}
@@ -1 +1,102 @@
 package mysql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+)
+
+func TestSpotRepository_ListNearby(t *testing.T) {
+	dialect := goqu.Dialect("mysql")
+	ctx := context.Background()
+	repo := NewSpotRepository(db, &dialect)
+
+	// dml.test.sqlで登録している旭川市21世紀の森ふれあい広場の近く
+	spots, err := repo.ListNearby(ctx, 43.72, 142.67, 5)
+	ValidateErr(t, err, nil)
+	if len(spots) != 1 || spots[0].ID.String() != "5c5323e9-c78f-4dac-94ef-d34ab5ea8fed" {
+		t.Fatalf("ListNearby() = %v, want the seeded spot", spots)
+	}
+	if spots[0].DistanceKm <= 0 || spots[0].DistanceKm > 5 {
+		t.Errorf("ListNearby() distance = %v, want within 5km", spots[0].DistanceKm)
+	}
+
+	// 離れた地点からは見つからない
+	spots, err = repo.ListNearby(ctx, 35.68, 139.76, 5)
+	ValidateErr(t, err, nil)
+	if len(spots) != 0 {
+		t.Errorf("ListNearby() = %v, want empty", spots)
+	}
+}
+
+func TestSpotRepository_Search(t *testing.T) {
+	dialect := goqu.Dialect("mysql")
+	ctx := context.Background()
+	repo := NewSpotRepository(db, &dialect)
+
+	// dml.test.sqlで登録している旭川市21世紀の森ふれあい広場
+	spots, err := repo.Search(ctx, repository.SpotSearchQuery{Keyword: "旭川", Categories: []string{"campsite"}, Limit: 10})
+	ValidateErr(t, err, nil)
+	if len(spots) != 1 || spots[0].ID.String() != "5c5323e9-c78f-4dac-94ef-d34ab5ea8fed" {
+		t.Fatalf("Search() = %v, want the seeded spot", spots)
+	}
+	if spots[0].Score <= 0 {
+		t.Errorf("Search() score = %v, want positive", spots[0].Score)
+	}
+
+	// 範囲外の矩形では見つからない
+	bounds := model.BoundingBox{MinLat: 35, MinLng: 139, MaxLat: 36, MaxLng: 140}
+	spots, err = repo.Search(ctx, repository.SpotSearchQuery{Keyword: "旭川", Bounds: &bounds})
+	ValidateErr(t, err, nil)
+	if len(spots) != 0 {
+		t.Errorf("Search() = %v, want empty", spots)
+	}
+}
+
+func TestSpotRepository_AdjustRating(t *testing.T) {
+	dialect := goqu.Dialect("mysql")
+	ctx := context.Background()
+	repo := NewSpotRepository(db, &dialect)
+
+	spot := model.Spot{
+		ID:       uuid.New(),
+		Category: "campsite",
+		Name:     "評価テスト",
+		Address:  "北海道",
+		Lat:      42.0,
+		Lng:      141.0,
+	}
+	err := repo.Create(ctx, spot)
+	ValidateErr(t, err, nil)
+
+	ValidateErr(t, repo.AdjustRating(ctx, spot.ID.String(), 5, 1), nil)
+	ValidateErr(t, repo.AdjustRating(ctx, spot.ID.String(), 3.5, 1), nil)
+	ValidateErr(t, repo.AdjustRating(ctx, spot.ID.String(), 2, 1), nil)
+	ValidateErr(t, repo.AdjustRating(ctx, spot.ID.String(), 2, -1), nil)
+
+	got, err := repo.Get(ctx, spot.ID.String())
+	ValidateErr(t, err, nil)
+	if got.RatingCount != 2 || got.RatingAverage != 4.25 {
+		t.Errorf("AdjustRating() count = %v, average = %v, want 2, 4.25", got.RatingCount, got.RatingAverage)
+	}
+	if want := (model.RatingHistogram{0, 0, 0, 1, 1}); got.RatingHistogram != want {
+		t.Errorf("AdjustRating() histogram = %v, want %v", got.RatingHistogram, want)
+	}
+
+	// Updateで評価の集計は上書きされない
+	spot.Name = "評価テスト2"
+	ValidateErr(t, repo.Update(ctx, spot.ID.String(), spot), nil)
+	got, err = repo.Get(ctx, spot.ID.String())
+	ValidateErr(t, err, nil)
+	if got.RatingCount != 2 {
+		t.Errorf("Update() overwrote rating count: %v", got.RatingCount)
+	}
+
+	err = repo.AdjustRating(ctx, uuid.NewString(), 5, 1)
+	ValidateErr(t, err, repository.ErrNotFound)
+}
@@ -0,0 +1,49 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/doug-martin/goqu/v9"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+)
+
+type recoveryCodeRepository struct {
+	*base[model.RecoveryCode]
+}
+
+func NewRecoveryCodeRepository(
+	db repository.SQLExecutor,
+	dialect *goqu.DialectWrapper,
+) repository.RecoveryCodeRepository {
+	return &recoveryCodeRepository{
+		base: newBase[model.RecoveryCode](db, dialect, "RecoveryCode"),
+	}
+}
+
+// Consume は削除された行数で、他のリクエストが先に同じリカバリーコードを使っていないかを確認します。
+func (rr *recoveryCodeRepository) Consume(ctx context.Context, id string) (bool, error) {
+	query, _, err := rr.dialect.Delete(rr.tableName).Where(goqu.C("id").Eq(id)).ToSQL()
+	if err != nil {
+		return false, err
+	}
+	result, err := rr.db.ExecContext(ctx, query)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (rr *recoveryCodeRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	query, _, err := rr.dialect.Delete(rr.tableName).Where(goqu.C("user_id").Eq(userID)).ToSQL()
+	if err != nil {
+		return err
+	}
+	_, err = rr.db.ExecContext(ctx, query)
+	return err
+}
@@ -7,9 +7,11 @@ import (
 	"errors"
 
 	"github.com/go-redis/redis/v8"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
 )
 
-var ErrCacheMiss = errors.New("cache: key not found")
+var ErrCacheMiss = repository.ErrCacheMiss
 
 type base[T any] struct {
 	client *redis.Client
@@ -0,0 +1,109 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+)
+
+const loginLockoutsKey = "login_lockouts"
+
+type loginAttemptRepository struct {
+	*base[model.LoginLockout]
+}
+
+func NewLoginAttemptRepository(client *redis.Client) repository.LoginAttemptCacheRepository {
+	return &loginAttemptRepository{
+		base: newBase[model.LoginLockout](client),
+	}
+}
+
+func loginFailuresKey(kind model.LoginAttemptKind, identifier string) string {
+	return "login_failures:" + string(kind) + ":" + identifier
+}
+
+func loginLockKey(kind model.LoginAttemptKind, identifier string) string {
+	return "login_lock:" + string(kind) + ":" + identifier
+}
+
+func (lr *loginAttemptRepository) AddFailure(
+	ctx context.Context,
+	kind model.LoginAttemptKind,
+	identifier string,
+	window time.Duration,
+) (int64, error) {
+	key := loginFailuresKey(kind, identifier)
+	var incr *redis.IntCmd
+	_, err := lr.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		pipe.PExpire(ctx, key, window)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+func (lr *loginAttemptRepository) Lock(
+	ctx context.Context,
+	kind model.LoginAttemptKind,
+	identifier string,
+	duration time.Duration,
+) error {
+	return lr.client.Set(ctx, loginLockKey(kind, identifier), 1, duration).Err()
+}
+
+func (lr *loginAttemptRepository) LockRemaining(
+	ctx context.Context,
+	kind model.LoginAttemptKind,
+	identifier string,
+) (time.Duration, error) {
+	ttl, err := lr.client.PTTL(ctx, loginLockKey(kind, identifier)).Result()
+	if err != nil {
+		return 0, err
+	}
+	// キーが存在しない場合は負の値が返る
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+func (lr *loginAttemptRepository) Reset(ctx context.Context, kind model.LoginAttemptKind, identifier string) error {
+	return lr.client.Del(ctx, loginFailuresKey(kind, identifier), loginLockKey(kind, identifier)).Err()
+}
+
+func (lr *loginAttemptRepository) AddLockout(ctx context.Context, lockout model.LoginLockout, maxLen int64) error {
+	data, err := json.Marshal(lockout)
+	if err != nil {
+		return err
+	}
+	_, err = lr.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LPush(ctx, loginLockoutsKey, data)
+		pipe.LTrim(ctx, loginLockoutsKey, 0, maxLen-1)
+		return nil
+	})
+	return err
+}
+
+func (lr *loginAttemptRepository) ListLockouts(ctx context.Context, limit int64) ([]model.LoginLockout, error) {
+	values, err := lr.client.LRange(ctx, loginLockoutsKey, 0, limit-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	lockouts := make([]model.LoginLockout, 0, len(values))
+	for _, value := range values {
+		var lockout *model.LoginLockout
+		if lockout, err = lr.deserialize(value); err != nil {
+			return nil, err
+		}
+		lockouts = append(lockouts, *lockout)
+	}
+	return lockouts, nil
+}
@@ -0,0 +1,106 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+)
+
+func TestLoginAttempt(t *testing.T) {
+	ctx := context.Background()
+	email := "test@gmail.com"
+
+	repo := NewLoginAttemptRepository(client)
+
+	// add failures
+	for want := int64(1); want <= 2; want++ {
+		got, err := repo.AddFailure(ctx, model.LoginAttemptKindEmail, email, time.Minute)
+		ValidateErr(t, err, nil)
+		if got != want {
+			t.Errorf("AddFailure() = %v, want %v", got, want)
+		}
+	}
+
+	// lock
+	remaining, err := repo.LockRemaining(ctx, model.LoginAttemptKindEmail, email)
+	ValidateErr(t, err, nil)
+	if remaining != 0 {
+		t.Errorf("LockRemaining() = %v, want 0 before lock", remaining)
+	}
+	err = repo.Lock(ctx, model.LoginAttemptKindEmail, email, time.Minute)
+	ValidateErr(t, err, nil)
+	remaining, err = repo.LockRemaining(ctx, model.LoginAttemptKindEmail, email)
+	ValidateErr(t, err, nil)
+	if remaining <= 0 || remaining > time.Minute {
+		t.Errorf("LockRemaining() = %v, want within a minute", remaining)
+	}
+	// IPアドレスのロックとは別に数える
+	remaining, err = repo.LockRemaining(ctx, model.LoginAttemptKindIP, email)
+	ValidateErr(t, err, nil)
+	if remaining != 0 {
+		t.Errorf("LockRemaining(ip) = %v, want 0", remaining)
+	}
+
+	// reset
+	err = repo.Reset(ctx, model.LoginAttemptKindEmail, email)
+	ValidateErr(t, err, nil)
+	remaining, err = repo.LockRemaining(ctx, model.LoginAttemptKindEmail, email)
+	ValidateErr(t, err, nil)
+	if remaining != 0 {
+		t.Errorf("LockRemaining() = %v, want 0 after reset", remaining)
+	}
+	got, err := repo.AddFailure(ctx, model.LoginAttemptKindEmail, email, time.Minute)
+	ValidateErr(t, err, nil)
+	if got != 1 {
+		t.Errorf("AddFailure() = %v, want 1 after reset", got)
+	}
+}
+
+func TestLoginLockouts(t *testing.T) {
+	ctx := context.Background()
+	createdAt := time.Now().UTC().Truncate(time.Second)
+	lockouts := []model.LoginLockout{
+		{
+			Kind:        model.LoginAttemptKindEmail,
+			Identifier:  "test@gmail.com",
+			IP:          "192.0.2.1",
+			Failures:    5,
+			LockedUntil: createdAt.Add(15 * time.Minute),
+			CreatedAt:   createdAt,
+		},
+		{
+			Kind:        model.LoginAttemptKindIP,
+			Identifier:  "192.0.2.1",
+			IP:          "192.0.2.1",
+			Failures:    20,
+			LockedUntil: createdAt.Add(16 * time.Minute),
+			CreatedAt:   createdAt.Add(time.Minute),
+		},
+		{
+			Kind:        model.LoginAttemptKindEmail,
+			Identifier:  "other@gmail.com",
+			IP:          "192.0.2.2",
+			Failures:    5,
+			LockedUntil: createdAt.Add(17 * time.Minute),
+			CreatedAt:   createdAt.Add(2 * time.Minute),
+		},
+	}
+
+	repo := NewLoginAttemptRepository(client)
+
+	// add lockouts. 古い記録はmaxLenを超えると削除される
+	for _, lockout := range lockouts {
+		err := repo.AddLockout(ctx, lockout, 2)
+		ValidateErr(t, err, nil)
+	}
+
+	// list lockouts
+	got, err := repo.ListLockouts(ctx, 10)
+	ValidateErr(t, err, nil)
+	if want := []model.LoginLockout{lockouts[2], lockouts[1]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListLockouts() \n got = %v,\n want = %v", got, want)
+	}
+}
@@ -0,0 +1,57 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+)
+
+type oidcStateRepository struct {
+	*base[model.OIDCAuthState]
+}
+
+func NewOIDCStateRepository(client *redis.Client) repository.OIDCStateCacheRepository {
+	return &oidcStateRepository{
+		base: newBase[model.OIDCAuthState](client),
+	}
+}
+
+func oidcStateKey(state string) string {
+	return "oidc_state:" + state
+}
+
+func (sr *oidcStateRepository) SetOIDCState(
+	ctx context.Context,
+	state string,
+	authState model.OIDCAuthState,
+	expiration time.Duration,
+) error {
+	val, err := json.Marshal(authState)
+	if err != nil {
+		return err
+	}
+	return sr.client.Set(ctx, oidcStateKey(state), val, expiration).Err()
+}
+
+// ConsumeOIDCState はConsumeUserTokenと同様にGETとDELをMULTIで実行し、同じstateで二度ログインできないようにします。
+func (sr *oidcStateRepository) ConsumeOIDCState(ctx context.Context, state string) (*model.OIDCAuthState, error) {
+	key := oidcStateKey(state)
+	var get *redis.StringCmd
+	_, err := sr.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pipe.Del(ctx, key)
+		return nil
+	})
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrCacheMiss
+	} else if err != nil {
+		return nil, err
+	}
+	return sr.deserialize(get.Val())
+}
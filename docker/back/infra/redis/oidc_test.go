@@ -0,0 +1,35 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+)
+
+func TestOIDCState(t *testing.T) {
+	ctx := context.Background()
+	want := model.OIDCAuthState{
+		Provider:     "google",
+		Nonce:        "nonce",
+		CodeVerifier: "verifier",
+		CreatedAt:    time.Now().UTC().Truncate(time.Second),
+	}
+
+	repo := NewOIDCStateRepository(client)
+
+	err := repo.SetOIDCState(ctx, "state", want, time.Minute)
+	ValidateErr(t, err, nil)
+
+	got, err := repo.ConsumeOIDCState(ctx, "state")
+	ValidateErr(t, err, nil)
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("ConsumeOIDCState() = %v, want %v", *got, want)
+	}
+
+	// 一度使ったstateは使えない
+	_, err = repo.ConsumeOIDCState(ctx, "state")
+	ValidateErr(t, err, ErrCacheMiss)
+}
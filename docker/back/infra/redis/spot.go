@@ -16,3 +16,13 @@ func NewSpotsRepository(client *redis.Client) repository.SpotsCacheRepository {
 		base: newBase[model.Spots](client),
 	}
 }
+
+type spotClustersRepository struct {
+	*base[model.SpotClusters]
+}
+
+func NewSpotClustersRepository(client *redis.Client) repository.SpotClustersCacheRepository {
+	return &spotClustersRepository{
+		base: newBase[model.SpotClusters](client),
+	}
+}
@@ -0,0 +1,106 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+)
+
+type twoFactorRepository struct {
+	*base[model.TwoFactorChallenge]
+}
+
+func NewTwoFactorRepository(client *redis.Client) repository.TwoFactorCacheRepository {
+	return &twoFactorRepository{
+		base: newBase[model.TwoFactorChallenge](client),
+	}
+}
+
+func twoFactorChallengeKey(challengeHash string) string {
+	return "two_factor_challenge:" + challengeHash
+}
+
+func totpPendingSecretKey(userID string) string {
+	return "totp_pending:" + userID
+}
+
+func totpUsedKey(userID string, counter int64) string {
+	return fmt.Sprintf("totp_used:%s:%d", userID, counter)
+}
+
+func (tr *twoFactorRepository) SetChallenge(
+	ctx context.Context,
+	challengeHash string,
+	challenge model.TwoFactorChallenge,
+) error {
+	val, err := json.Marshal(challenge)
+	if err != nil {
+		return err
+	}
+	return tr.client.Set(ctx, twoFactorChallengeKey(challengeHash), val, time.Until(challenge.ExpiresAt)).Err()
+}
+
+func (tr *twoFactorRepository) GetChallenge(
+	ctx context.Context,
+	challengeHash string,
+) (*model.TwoFactorChallenge, error) {
+	return tr.Get(ctx, twoFactorChallengeKey(challengeHash))
+}
+
+// ConsumeChallenge はConsumeUserTokenと同様にGETとDELをMULTIで実行し、同じチャレンジで二度ログインできないようにします。
+func (tr *twoFactorRepository) ConsumeChallenge(
+	ctx context.Context,
+	challengeHash string,
+) (*model.TwoFactorChallenge, error) {
+	key := twoFactorChallengeKey(challengeHash)
+	var get *redis.StringCmd
+	_, err := tr.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pipe.Del(ctx, key)
+		return nil
+	})
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrCacheMiss
+	} else if err != nil {
+		return nil, err
+	}
+	return tr.deserialize(get.Val())
+}
+
+func (tr *twoFactorRepository) SetPendingSecret(
+	ctx context.Context,
+	userID string,
+	secret string,
+	expiration time.Duration,
+) error {
+	return tr.client.Set(ctx, totpPendingSecretKey(userID), secret, expiration).Err()
+}
+
+func (tr *twoFactorRepository) GetPendingSecret(ctx context.Context, userID string) (string, error) {
+	val, err := tr.client.Get(ctx, totpPendingSecretKey(userID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrCacheMiss
+	}
+	return val, err
+}
+
+func (tr *twoFactorRepository) DeletePendingSecret(ctx context.Context, userID string) error {
+	return tr.client.Del(ctx, totpPendingSecretKey(userID)).Err()
+}
+
+// MarkTOTPUsed はSETNXで時間ステップを記録し、同時に同じコードが使われた場合も一方のみを成功させます。
+func (tr *twoFactorRepository) MarkTOTPUsed(
+	ctx context.Context,
+	userID string,
+	counter int64,
+	expiration time.Duration,
+) (bool, error) {
+	return tr.client.SetNX(ctx, totpUsedKey(userID, counter), 1, expiration).Result()
+}
@@ -0,0 +1,91 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+)
+
+func TestTwoFactorChallenge(t *testing.T) {
+	ctx := context.Background()
+	want := model.TwoFactorChallenge{
+		UserID:     "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
+		Email:      "test@gmail.com",
+		DeviceName: "iPhone",
+		IP:         "203.0.113.1",
+		UserAgent:  "Mozilla/5.0",
+		ExpiresAt:  time.Now().Add(time.Minute).UTC().Truncate(time.Second),
+	}
+
+	repo := NewTwoFactorRepository(client)
+
+	err := repo.SetChallenge(ctx, "challenge", want)
+	ValidateErr(t, err, nil)
+
+	got, err := repo.GetChallenge(ctx, "challenge")
+	ValidateErr(t, err, nil)
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("GetChallenge() = %v, want %v", *got, want)
+	}
+
+	got, err = repo.ConsumeChallenge(ctx, "challenge")
+	ValidateErr(t, err, nil)
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("ConsumeChallenge() = %v, want %v", *got, want)
+	}
+
+	// 一度使ったチャレンジは使えない
+	_, err = repo.ConsumeChallenge(ctx, "challenge")
+	ValidateErr(t, err, ErrCacheMiss)
+}
+
+func TestTwoFactorPendingSecret(t *testing.T) {
+	ctx := context.Background()
+	userID := "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"
+
+	repo := NewTwoFactorRepository(client)
+
+	err := repo.SetPendingSecret(ctx, userID, "JBSWY3DPEHPK3PXP", time.Minute)
+	ValidateErr(t, err, nil)
+
+	got, err := repo.GetPendingSecret(ctx, userID)
+	ValidateErr(t, err, nil)
+	if got != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("GetPendingSecret() = %v, want %v", got, "JBSWY3DPEHPK3PXP")
+	}
+
+	err = repo.DeletePendingSecret(ctx, userID)
+	ValidateErr(t, err, nil)
+
+	_, err = repo.GetPendingSecret(ctx, userID)
+	ValidateErr(t, err, ErrCacheMiss)
+}
+
+func TestMarkTOTPUsed(t *testing.T) {
+	ctx := context.Background()
+	userID := "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"
+
+	repo := NewTwoFactorRepository(client)
+
+	ok, err := repo.MarkTOTPUsed(ctx, userID, 37037037, time.Minute)
+	ValidateErr(t, err, nil)
+	if !ok {
+		t.Errorf("MarkTOTPUsed() = false, want true for the first use")
+	}
+
+	// 同じ時間ステップのコードは二度使えない
+	ok, err = repo.MarkTOTPUsed(ctx, userID, 37037037, time.Minute)
+	ValidateErr(t, err, nil)
+	if ok {
+		t.Errorf("MarkTOTPUsed() = true, want false for a reused code")
+	}
+
+	ok, err = repo.MarkTOTPUsed(ctx, userID, 37037038, time.Minute)
+	ValidateErr(t, err, nil)
+	if !ok {
+		t.Errorf("MarkTOTPUsed() = false, want true for the next step")
+	}
+}
@@ -2,6 +2,10 @@ package redis
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 
@@ -19,10 +23,243 @@ func NewUserRepository(client *redis.Client) repository.UserCacheRepository {
 	}
 }
 
-func (ur *userRepository) GetUserSession(ctx context.Context, userID string) (string, error) {
-	return ur.client.Get(ctx, userID).Result()
+func refreshTokenKey(tokenHash string) string {
+	return "refresh_token:" + tokenHash
 }
 
-func (ur *userRepository) SetUserSession(ctx context.Context, userID string, sessionData string) error {
-	return ur.client.Set(ctx, userID, sessionData, 0).Err()
+func refreshTokenFamilyKey(familyID string) string {
+	return "refresh_family:" + familyID
+}
+
+func userTokenKey(purpose model.UserTokenPurpose, tokenHash string) string {
+	return "user_token:" + string(purpose) + ":" + tokenHash
+}
+
+// userTokenIndexKey はユーザが最後に発行した用途ごとのトークンのハッシュを保存するキーです。
+func userTokenIndexKey(purpose model.UserTokenPurpose, userID string) string {
+	return "user_token_index:" + string(purpose) + ":" + userID
+}
+
+func userSuspendedKey(userID string) string {
+	return "user_suspended:" + userID
+}
+
+func sessionKey(userID, sessionID string) string {
+	return "session:" + userID + ":" + sessionID
+}
+
+func (ur *userRepository) SetSession(ctx context.Context, session model.Session, expiration time.Duration) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return ur.client.Set(ctx, sessionKey(session.UserID, session.ID), data, expiration).Err()
+}
+
+func (ur *userRepository) GetSession(ctx context.Context, userID string, sessionID string) (*model.Session, error) {
+	return ur.getSession(ctx, sessionKey(userID, sessionID))
+}
+
+func (ur *userRepository) getSession(ctx context.Context, key string) (*model.Session, error) {
+	data, err := ur.getString(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	var session model.Session
+	if err = json.Unmarshal([]byte(data), &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListSessions はユーザの有効なセッションを作成日時順で返します。
+func (ur *userRepository) ListSessions(ctx context.Context, userID string) ([]model.Session, error) {
+	keys, err := ur.Scan(ctx, sessionKey(userID, "*"))
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]model.Session, 0, len(keys))
+	for _, key := range keys {
+		var session *model.Session
+		session, err = ur.getSession(ctx, key)
+		if errors.Is(err, ErrCacheMiss) {
+			// Scanの後に期限切れになった
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+	return sessions, nil
+}
+
+// TouchSession はRedis 5ではKEEPTTLが使えないため、残りの有効期間を取得してから保存し直します。
+func (ur *userRepository) TouchSession(
+	ctx context.Context,
+	userID string,
+	sessionID string,
+	lastSeenAt time.Time,
+) error {
+	key := sessionKey(userID, sessionID)
+	ttl, err := ur.client.PTTL(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if ttl <= 0 {
+		return ErrCacheMiss
+	}
+	session, err := ur.GetSession(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+	session.LastSeenAt = lastSeenAt
+	return ur.SetSession(ctx, *session, ttl)
+}
+
+func (ur *userRepository) DeleteSession(ctx context.Context, userID string, sessionID string) error {
+	return ur.client.Del(ctx, sessionKey(userID, sessionID)).Err()
+}
+
+func (ur *userRepository) SetRefreshToken(ctx context.Context, tokenHash string, token model.RefreshToken) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return ur.client.Set(ctx, refreshTokenKey(tokenHash), data, time.Until(token.ExpiresAt)).Err()
+}
+
+func (ur *userRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*model.RefreshToken, error) {
+	data, err := ur.getString(ctx, refreshTokenKey(tokenHash))
+	if err != nil {
+		return nil, err
+	}
+	var token model.RefreshToken
+	if err = json.Unmarshal([]byte(data), &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (ur *userRepository) SetRefreshTokenFamily(
+	ctx context.Context,
+	familyID string,
+	tokenHash string,
+	expiration time.Duration,
+) error {
+	return ur.client.Set(ctx, refreshTokenFamilyKey(familyID), tokenHash, expiration).Err()
+}
+
+func (ur *userRepository) GetRefreshTokenFamily(ctx context.Context, familyID string) (string, error) {
+	return ur.getString(ctx, refreshTokenFamilyKey(familyID))
+}
+
+// swapRefreshTokenFamilyScript は値の比較と置き換えを1つのコマンドとして実行します。
+// 戻り値は置き換えた場合は1、別の値だった場合は0、キーが存在しない場合は-1です。
+var swapRefreshTokenFamilyScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if not current then
+	return -1
+end
+if current ~= ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+return 1
+`)
+
+// SwapRefreshTokenFamily は同じリフレッシュトークンで同時にローテーションした場合に、1つだけが成功するようにします。
+func (ur *userRepository) SwapRefreshTokenFamily(
+	ctx context.Context,
+	familyID string,
+	oldHash string,
+	newHash string,
+	expiration time.Duration,
+) (bool, error) {
+	result, err := swapRefreshTokenFamilyScript.Run(
+		ctx, ur.client, []string{refreshTokenFamilyKey(familyID)}, oldHash, newHash, expiration.Milliseconds(),
+	).Int()
+	if err != nil {
+		return false, err
+	}
+	if result < 0 {
+		return false, ErrCacheMiss
+	}
+	return result == 1, nil
+}
+
+func (ur *userRepository) DeleteRefreshTokenFamily(ctx context.Context, familyID string) error {
+	return ur.client.Del(ctx, refreshTokenFamilyKey(familyID)).Err()
+}
+
+func (ur *userRepository) SetUserToken(ctx context.Context, tokenHash string, token model.UserToken) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	indexKey := userTokenIndexKey(token.Purpose, token.UserID)
+	previous, err := ur.getString(ctx, indexKey)
+	if err != nil && !errors.Is(err, ErrCacheMiss) {
+		return err
+	}
+	expiration := time.Until(token.ExpiresAt)
+	_, err = ur.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if previous != "" {
+			pipe.Del(ctx, userTokenKey(token.Purpose, previous))
+		}
+		pipe.Set(ctx, userTokenKey(token.Purpose, tokenHash), data, expiration)
+		pipe.Set(ctx, indexKey, tokenHash, expiration)
+		return nil
+	})
+	return err
+}
+
+// ConsumeUserToken はRedis 5ではGETDELが使えないため、GETとDELをMULTIで実行して同じトークンを二度使えないようにします。
+func (ur *userRepository) ConsumeUserToken(
+	ctx context.Context,
+	purpose model.UserTokenPurpose,
+	tokenHash string,
+) (*model.UserToken, error) {
+	key := userTokenKey(purpose, tokenHash)
+	var get *redis.StringCmd
+	_, err := ur.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pipe.Del(ctx, key)
+		return nil
+	})
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrCacheMiss
+	} else if err != nil {
+		return nil, err
+	}
+	var token model.UserToken
+	if err = json.Unmarshal([]byte(get.Val()), &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (ur *userRepository) SetSuspended(ctx context.Context, userID string, suspended bool) error {
+	if !suspended {
+		return ur.client.Del(ctx, userSuspendedKey(userID)).Err()
+	}
+	return ur.client.Set(ctx, userSuspendedKey(userID), 1, 0).Err()
+}
+
+func (ur *userRepository) IsSuspended(ctx context.Context, userID string) (bool, error) {
+	n, err := ur.client.Exists(ctx, userSuspendedKey(userID)).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (ur *userRepository) getString(ctx context.Context, key string) (string, error) {
+	val, err := ur.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrCacheMiss
+	}
+	return val, err
 }
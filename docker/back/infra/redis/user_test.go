@@ -2,24 +2,180 @@ package redis
 
 import (
 	"context"
+	"reflect"
 	"testing"
+	"time"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
 )
 
 func TestUserSession(t *testing.T) {
 	ctx := context.Background()
 	userID := "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"
-	jti := "d3b07384-d113-4ec6-a7d7-9a3bb5d3c8f5"
+	createdAt := time.Now().UTC().Truncate(time.Second)
+	laptop := model.Session{
+		ID:         "2b5a4b1e-3f6c-4a8e-9d6b-7e8f9a0b1c2d",
+		UserID:     userID,
+		JTI:        "d3b07384-d113-4ec6-a7d7-9a3bb5d3c8f5",
+		DeviceName: "laptop",
+		IP:         "192.0.2.1",
+		UserAgent:  "Mozilla/5.0",
+		CreatedAt:  createdAt,
+		LastSeenAt: createdAt,
+	}
+	phone := model.Session{
+		ID:         "8c1f0e6a-52a4-4d3b-9f0e-1a2b3c4d5e6f",
+		UserID:     userID,
+		JTI:        "0b2c3d4e-5f60-4718-8293-a4b5c6d7e8f9",
+		DeviceName: "phone",
+		CreatedAt:  createdAt.Add(time.Minute),
+		LastSeenAt: createdAt.Add(time.Minute),
+	}
 
 	repo := NewUserRepository(client)
 
-	// set user session
-	err := repo.SetUserSession(ctx, userID, jti)
+	// set sessions
+	err := repo.SetSession(ctx, phone, time.Minute)
+	ValidateErr(t, err, nil)
+	err = repo.SetSession(ctx, laptop, time.Minute)
+	ValidateErr(t, err, nil)
+
+	// get session
+	got, err := repo.GetSession(ctx, userID, laptop.ID)
+	ValidateErr(t, err, nil)
+	if !reflect.DeepEqual(*got, laptop) {
+		t.Errorf("GetSession() \n got = %v,\n want = %v", *got, laptop)
+	}
+
+	// list sessions
+	sessions, err := repo.ListSessions(ctx, userID)
+	ValidateErr(t, err, nil)
+	if want := []model.Session{laptop, phone}; !reflect.DeepEqual(sessions, want) {
+		t.Errorf("ListSessions() \n got = %v,\n want = %v", sessions, want)
+	}
+
+	// touch session
+	lastSeenAt := createdAt.Add(time.Hour)
+	err = repo.TouchSession(ctx, userID, laptop.ID, lastSeenAt)
+	ValidateErr(t, err, nil)
+	got, err = repo.GetSession(ctx, userID, laptop.ID)
+	ValidateErr(t, err, nil)
+	if !got.LastSeenAt.Equal(lastSeenAt) {
+		t.Errorf("TouchSession() LastSeenAt = %v, want %v", got.LastSeenAt, lastSeenAt)
+	}
+
+	// delete session
+	err = repo.DeleteSession(ctx, userID, laptop.ID)
+	ValidateErr(t, err, nil)
+	_, err = repo.GetSession(ctx, userID, laptop.ID)
+	ValidateErr(t, err, ErrCacheMiss)
+	err = repo.TouchSession(ctx, userID, laptop.ID, lastSeenAt)
+	ValidateErr(t, err, ErrCacheMiss)
+}
+
+func TestRefreshToken(t *testing.T) {
+	ctx := context.Background()
+	tokenHash := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	token := model.RefreshToken{
+		UserID:    "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
+		FamilyID:  "2b5a4b1e-3f6c-4a8e-9d6b-7e8f9a0b1c2d",
+		ExpiresAt: time.Now().Add(time.Hour).UTC().Truncate(time.Second),
+	}
+
+	repo := NewUserRepository(client)
+
+	// set refresh token
+	err := repo.SetRefreshToken(ctx, tokenHash, token)
+	ValidateErr(t, err, nil)
+
+	// get refresh token
+	got, err := repo.GetRefreshToken(ctx, tokenHash)
+	ValidateErr(t, err, nil)
+	if !reflect.DeepEqual(*got, token) {
+		t.Errorf("GetRefreshToken() \n got = %v,\n want = %v", *got, token)
+	}
+
+	// set refresh token family
+	err = repo.SetRefreshTokenFamily(ctx, token.FamilyID, tokenHash, time.Hour)
 	ValidateErr(t, err, nil)
 
-	// get user session
-	getJTI, err := repo.GetUserSession(ctx, userID)
+	// get refresh token family
+	current, err := repo.GetRefreshTokenFamily(ctx, token.FamilyID)
 	ValidateErr(t, err, nil)
-	if getJTI != jti {
-		t.Errorf("GetUserSession() \n got = %v,\n want = %v", getJTI, jti)
+	if current != tokenHash {
+		t.Errorf("GetRefreshTokenFamily() \n got = %v,\n want = %v", current, tokenHash)
+	}
+
+	// swap refresh token family only from the current hash
+	rotatedHash := "60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752"
+	swapped, err := repo.SwapRefreshTokenFamily(ctx, token.FamilyID, tokenHash, rotatedHash, time.Hour)
+	ValidateErr(t, err, nil)
+	if !swapped {
+		t.Errorf("SwapRefreshTokenFamily() = false, want true")
+	}
+	swapped, err = repo.SwapRefreshTokenFamily(ctx, token.FamilyID, tokenHash, "other", time.Hour)
+	ValidateErr(t, err, nil)
+	if swapped {
+		t.Errorf("SwapRefreshTokenFamily() with rotated hash = true, want false")
+	}
+	_, err = repo.SwapRefreshTokenFamily(ctx, "unknown-family", tokenHash, rotatedHash, time.Hour)
+	ValidateErr(t, err, ErrCacheMiss)
+
+	// delete refresh token family
+	err = repo.DeleteRefreshTokenFamily(ctx, token.FamilyID)
+	ValidateErr(t, err, nil)
+	_, err = repo.GetRefreshTokenFamily(ctx, token.FamilyID)
+	ValidateErr(t, err, ErrCacheMiss)
+}
+
+func TestUserToken(t *testing.T) {
+	ctx := context.Background()
+	firstHash := "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+	secondHash := "fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9"
+	token := model.UserToken{
+		UserID:    "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
+		Email:     "test@gmail.com",
+		Purpose:   model.UserTokenPurposeResetPassword,
+		ExpiresAt: time.Now().Add(time.Hour).UTC().Truncate(time.Second),
+	}
+
+	repo := NewUserRepository(client)
+
+	// set user tokens. 新しいトークンを発行すると以前のトークンは使えなくなる
+	err := repo.SetUserToken(ctx, firstHash, token)
+	ValidateErr(t, err, nil)
+	err = repo.SetUserToken(ctx, secondHash, token)
+	ValidateErr(t, err, nil)
+	_, err = repo.ConsumeUserToken(ctx, token.Purpose, firstHash)
+	ValidateErr(t, err, ErrCacheMiss)
+
+	// 用途が異なるトークンとしては使えない
+	_, err = repo.ConsumeUserToken(ctx, model.UserTokenPurposeVerifyEmail, secondHash)
+	ValidateErr(t, err, ErrCacheMiss)
+
+	// consume user token
+	got, err := repo.ConsumeUserToken(ctx, token.Purpose, secondHash)
+	ValidateErr(t, err, nil)
+	if !reflect.DeepEqual(*got, token) {
+		t.Errorf("ConsumeUserToken() \n got = %v,\n want = %v", *got, token)
+	}
+	_, err = repo.ConsumeUserToken(ctx, token.Purpose, secondHash)
+	ValidateErr(t, err, ErrCacheMiss)
+}
+
+func TestUserSuspended(t *testing.T) {
+	ctx := context.Background()
+	userID := "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"
+
+	repo := NewUserRepository(client)
+
+	for _, want := range []bool{true, false} {
+		err := repo.SetSuspended(ctx, userID, want)
+		ValidateErr(t, err, nil)
+		got, err := repo.IsSuspended(ctx, userID)
+		ValidateErr(t, err, nil)
+		if got != want {
+			t.Errorf("IsSuspended() = %v, want %v", got, want)
+		}
 	}
 }
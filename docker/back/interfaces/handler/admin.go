@@ -0,0 +1,274 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/tusmasoma/campfinder/docker/back/config"
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+	"github.com/tusmasoma/campfinder/docker/back/usecase"
+)
+
+type AdminHandler interface {
+	ListLoginLockouts(w http.ResponseWriter, r *http.Request)
+	ListUsers(w http.ResponseWriter, r *http.Request)
+	UpdateUserRole(w http.ResponseWriter, r *http.Request)
+	SuspendUser(w http.ResponseWriter, r *http.Request)
+	UnsuspendUser(w http.ResponseWriter, r *http.Request)
+	ForcePasswordReset(w http.ResponseWriter, r *http.Request)
+	AssignSpotOwner(w http.ResponseWriter, r *http.Request)
+	RemoveSpotOwner(w http.ResponseWriter, r *http.Request)
+}
+
+type adminHandler struct {
+	auc usecase.AdminUseCase
+}
+
+func NewAdminHandler(auc usecase.AdminUseCase) AdminHandler {
+	return &adminHandler{
+		auc: auc,
+	}
+}
+
+type LoginLockoutResponse struct {
+	Kind        string    `json:"kind"`
+	Identifier  string    `json:"identifier"`
+	IP          string    `json:"ip"`
+	Failures    int64     `json:"failures"`
+	LockedUntil time.Time `json:"lockedUntil"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type ListLoginLockoutsResponse struct {
+	Lockouts []LoginLockoutResponse `json:"lockouts"`
+}
+
+type AdminUserResponse struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Email         string `json:"email"`
+	Role          string `json:"role"`
+	EmailVerified bool   `json:"emailVerified"`
+	Suspended     bool   `json:"suspended"`
+}
+
+type ListUsersResponse struct {
+	Users      []AdminUserResponse `json:"users"`
+	NextCursor string              `json:"next_cursor"`
+	Total      int                 `json:"total"`
+}
+
+type UpdateUserRoleRequest struct {
+	Role model.Role `json:"role"`
+}
+
+// ListLoginLockouts はログインのロックの記録を新しい順に返します。limitのみ指定でき、cursorには対応していません。
+func (ah *adminHandler) ListLoginLockouts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	lq, ok := parseListQuery(r.URL.Query())
+	if !ok {
+		http.Error(w, "Invalid query parameters", http.StatusBadRequest)
+		return
+	}
+
+	lockouts, err := ah.auc.ListLoginLockouts(ctx, lq.limit)
+	if err != nil {
+		http.Error(w, "Failed to list login lockouts", http.StatusInternalServerError)
+		return
+	}
+
+	response := ListLoginLockoutsResponse{Lockouts: make([]LoginLockoutResponse, 0, len(lockouts))}
+	for _, lockout := range lockouts {
+		response.Lockouts = append(response.Lockouts, LoginLockoutResponse{
+			Kind:        string(lockout.Kind),
+			Identifier:  lockout.Identifier,
+			IP:          lockout.IP,
+			Failures:    lockout.Failures,
+			LockedUntil: lockout.LockedUntil,
+			CreatedAt:   lockout.CreatedAt,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Failed to encode login lockouts to JSON", http.StatusInternalServerError)
+		return
+	}
+}
+
+// ListUsers はユーザの一覧を返します。qで名前・メールアドレスを部分一致で検索し、role, suspendedで絞り込めます。
+func (ah *adminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params, ok := isValidListUsersRequest(r.URL.Query())
+	if !ok {
+		http.Error(w, "Invalid query parameters", http.StatusBadRequest)
+		return
+	}
+
+	result, err := ah.auc.ListUsers(ctx, params)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		http.Error(w, "Invalid cursor", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to list users", http.StatusInternalServerError)
+		return
+	}
+
+	response := ListUsersResponse{
+		Users:      make([]AdminUserResponse, 0, len(result.Users)),
+		NextCursor: result.NextCursor,
+		Total:      result.Total,
+	}
+	for i := range result.Users {
+		response.Users = append(response.Users, newAdminUserResponse(&result.Users[i]))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Failed to encode users to JSON", http.StatusInternalServerError)
+		return
+	}
+}
+
+func isValidListUsersRequest(query url.Values) (*usecase.ListUsersParams, bool) {
+	lq, ok := parseListQuery(query)
+	if !ok {
+		return nil, false
+	}
+	params := &usecase.ListUsersParams{
+		Query:  query.Get("q"),
+		Role:   model.Role(query.Get("role")),
+		Limit:  lq.limit,
+		Cursor: lq.cursor,
+	}
+	if params.Role != "" && !params.Role.IsValid() {
+		log.Printf("Invalid role: %v", params.Role)
+		return nil, false
+	}
+	if suspended := query.Get("suspended"); suspended != "" {
+		v, err := strconv.ParseBool(suspended)
+		if err != nil {
+			log.Printf("Invalid suspended: %v", suspended)
+			return nil, false
+		}
+		params.Suspended = &v
+	}
+	return params, true
+}
+
+func (ah *adminHandler) UpdateUserRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var requestBody UpdateUserRoleRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil || !requestBody.Role.IsValid() {
+		http.Error(w, "Invalid update role request", http.StatusBadRequest)
+		return
+	}
+
+	user, err := ah.auc.UpdateUserRole(ctx, actorID(r), chi.URLParam(r, "userID"), requestBody.Role)
+	if err != nil {
+		writeAdminUserError(w, err, "Failed to update role")
+		return
+	}
+	writeAdminUserResponse(w, user)
+}
+
+func (ah *adminHandler) SuspendUser(w http.ResponseWriter, r *http.Request) {
+	ah.setUserSuspended(w, r, true)
+}
+
+func (ah *adminHandler) UnsuspendUser(w http.ResponseWriter, r *http.Request) {
+	ah.setUserSuspended(w, r, false)
+}
+
+func (ah *adminHandler) setUserSuspended(w http.ResponseWriter, r *http.Request, suspended bool) {
+	ctx := r.Context()
+	user, err := ah.auc.SetUserSuspended(ctx, actorID(r), chi.URLParam(r, "userID"), suspended)
+	if err != nil {
+		writeAdminUserError(w, err, "Failed to update suspension")
+		return
+	}
+	writeAdminUserResponse(w, user)
+}
+
+// ForcePasswordReset はユーザの現在のパスワードを無効にし、パスワード再設定のメールを送ります。
+func (ah *adminHandler) ForcePasswordReset(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := ah.auc.ForcePasswordReset(ctx, chi.URLParam(r, "userID")); err != nil {
+		writeAdminUserError(w, err, "Failed to reset password")
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// AssignSpotOwner はユーザをスポットの所有者として認証します。所有者の返信はowner_responseとして表示されます。
+func (ah *adminHandler) AssignSpotOwner(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	err := ah.auc.AssignSpotOwner(ctx, chi.URLParam(r, "spotID"), chi.URLParam(r, "userID"))
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Spot or user not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to assign spot owner", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ah *adminHandler) RemoveSpotOwner(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := ah.auc.RemoveSpotOwner(ctx, chi.URLParam(r, "spotID"), chi.URLParam(r, "userID")); err != nil {
+		http.Error(w, "Failed to remove spot owner", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// actorID は操作する管理者のユーザIDで、Authenticateでコンテキストに保存されています。
+func actorID(r *http.Request) string {
+	userID, _ := r.Context().Value(config.ContextUserIDKey).(string)
+	return userID
+}
+
+func writeAdminUserError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		http.Error(w, "User not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrInvalidRole):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrCannotModifySelf):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, message, http.StatusInternalServerError)
+	}
+}
+
+func newAdminUserResponse(user *model.User) AdminUserResponse {
+	return AdminUserResponse{
+		ID:            user.ID.String(),
+		Name:          user.Name,
+		Email:         user.Email,
+		Role:          string(user.EffectiveRole()),
+		EmailVerified: user.EmailVerified,
+		Suspended:     user.Suspended,
+	}
+}
+
+func writeAdminUserResponse(w http.ResponseWriter, user *model.User) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(newAdminUserResponse(user)); err != nil {
+		http.Error(w, "Failed to encode user to JSON", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,365 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/config"
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+	"github.com/tusmasoma/campfinder/docker/back/usecase"
+	"github.com/tusmasoma/campfinder/docker/back/usecase/mock"
+)
+
+func TestAdminHandler_ListLoginLockouts(t *testing.T) {
+	createdAt := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	patterns := []struct {
+		name       string
+		setup      func(m *mock.MockAdminUseCase)
+		query      string
+		wantStatus int
+		wantBody   *ListLoginLockoutsResponse
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockAdminUseCase) {
+				m.EXPECT().ListLoginLockouts(gomock.Any(), 10).Return([]model.LoginLockout{
+					{
+						Kind:        model.LoginAttemptKindEmail,
+						Identifier:  "test@gmail.com",
+						IP:          "192.0.2.1",
+						Failures:    5,
+						LockedUntil: createdAt.Add(15 * time.Minute),
+						CreatedAt:   createdAt,
+					},
+				}, nil)
+			},
+			query:      "?limit=10",
+			wantStatus: http.StatusOK,
+			wantBody: &ListLoginLockoutsResponse{Lockouts: []LoginLockoutResponse{
+				{
+					Kind:        "email",
+					Identifier:  "test@gmail.com",
+					IP:          "192.0.2.1",
+					Failures:    5,
+					LockedUntil: createdAt.Add(15 * time.Minute),
+					CreatedAt:   createdAt,
+				},
+			}},
+		},
+		{
+			name: "success: default limit",
+			setup: func(m *mock.MockAdminUseCase) {
+				m.EXPECT().ListLoginLockouts(gomock.Any(), DefaultListLimit).Return(nil, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   &ListLoginLockoutsResponse{Lockouts: []LoginLockoutResponse{}},
+		},
+		{
+			name:       "Fail: invalid limit",
+			query:      "?limit=0",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: list lockouts",
+			setup: func(m *mock.MockAdminUseCase) {
+				m.EXPECT().ListLoginLockouts(gomock.Any(), DefaultListLimit).Return(nil, errors.New("connection refused"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			auc := mock.NewMockAdminUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(auc)
+			}
+
+			handler := NewAdminHandler(auc)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/admin/login-lockouts"+tt.query, nil)
+			handler.ListLoginLockouts(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if tt.wantBody != nil {
+				var got ListLoginLockoutsResponse
+				if err := json.NewDecoder(recorder.Body).Decode(&got); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if !reflect.DeepEqual(&got, tt.wantBody) {
+					t.Errorf("handler returned unexpected body: got %+v want %+v", got, tt.wantBody)
+				}
+			}
+		})
+	}
+}
+
+func TestAdminHandler_ListUsers(t *testing.T) {
+	userID := uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")
+	suspended := true
+
+	patterns := []struct {
+		name       string
+		setup      func(m *mock.MockAdminUseCase)
+		query      string
+		wantStatus int
+		wantBody   *ListUsersResponse
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockAdminUseCase) {
+				m.EXPECT().ListUsers(gomock.Any(), &usecase.ListUsersParams{
+					Query:     "test",
+					Role:      model.RoleAdmin,
+					Suspended: &suspended,
+					Limit:     10,
+				}).Return(&usecase.ListUsersResult{
+					Users: []model.User{{ID: userID, Name: "test", Email: "test@gmail.com", IsAdmin: true, Suspended: true}},
+					Total: 1,
+				}, nil)
+			},
+			query:      "?q=test&role=admin&suspended=true&limit=10",
+			wantStatus: http.StatusOK,
+			wantBody: &ListUsersResponse{
+				Users: []AdminUserResponse{
+					{ID: userID.String(), Name: "test", Email: "test@gmail.com", Role: "admin", Suspended: true},
+				},
+				Total: 1,
+			},
+		},
+		{
+			name:       "Fail: invalid role",
+			query:      "?role=owner",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Fail: invalid suspended",
+			query:      "?suspended=maybe",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: invalid cursor",
+			setup: func(m *mock.MockAdminUseCase) {
+				m.EXPECT().ListUsers(gomock.Any(), gomock.Any()).Return(nil, repository.ErrInvalidCursor)
+			},
+			query:      "?cursor=invalid",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			auc := mock.NewMockAdminUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(auc)
+			}
+
+			handler := NewAdminHandler(auc)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/admin/users"+tt.query, nil)
+			handler.ListUsers(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if tt.wantBody != nil {
+				var got ListUsersResponse
+				if err := json.NewDecoder(recorder.Body).Decode(&got); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if !reflect.DeepEqual(&got, tt.wantBody) {
+					t.Errorf("handler returned unexpected body: got %+v want %+v", got, tt.wantBody)
+				}
+			}
+		})
+	}
+}
+
+func TestAdminHandler_ManageUser(t *testing.T) {
+	actorID := "0b2c3d4e-5f60-4718-8293-a4b5c6d7e8f9"
+	userID := uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")
+
+	patterns := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		setup      func(m *mock.MockAdminUseCase)
+		wantStatus int
+	}{
+		{
+			name:   "success: update role",
+			method: http.MethodPut,
+			path:   "/role",
+			body:   `{"role": "moderator"}`,
+			setup: func(m *mock.MockAdminUseCase) {
+				m.EXPECT().UpdateUserRole(gomock.Any(), actorID, userID.String(), model.RoleModerator).Return(
+					&model.User{ID: userID, Role: model.RoleModerator}, nil,
+				)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Fail: update to invalid role",
+			method:     http.MethodPut,
+			path:       "/role",
+			body:       `{"role": "owner"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Fail: update own role",
+			method: http.MethodPut,
+			path:   "/role",
+			body:   `{"role": "user"}`,
+			setup: func(m *mock.MockAdminUseCase) {
+				m.EXPECT().UpdateUserRole(gomock.Any(), actorID, userID.String(), model.RoleUser).Return(
+					nil, usecase.ErrCannotModifySelf,
+				)
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:   "success: suspend",
+			method: http.MethodPost,
+			path:   "/suspend",
+			setup: func(m *mock.MockAdminUseCase) {
+				m.EXPECT().SetUserSuspended(gomock.Any(), actorID, userID.String(), true).Return(
+					&model.User{ID: userID, Suspended: true}, nil,
+				)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "success: unsuspend",
+			method: http.MethodDelete,
+			path:   "/suspend",
+			setup: func(m *mock.MockAdminUseCase) {
+				m.EXPECT().SetUserSuspended(gomock.Any(), actorID, userID.String(), false).Return(&model.User{ID: userID}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "Fail: suspend unknown user",
+			method: http.MethodPost,
+			path:   "/suspend",
+			setup: func(m *mock.MockAdminUseCase) {
+				m.EXPECT().SetUserSuspended(gomock.Any(), actorID, userID.String(), true).Return(nil, repository.ErrNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:   "success: force password reset",
+			method: http.MethodPost,
+			path:   "/password-reset",
+			setup: func(m *mock.MockAdminUseCase) {
+				m.EXPECT().ForcePasswordReset(gomock.Any(), userID.String()).Return(nil)
+			},
+			wantStatus: http.StatusAccepted,
+		},
+	}
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			auc := mock.NewMockAdminUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(auc)
+			}
+
+			handler := NewAdminHandler(auc)
+			r := chi.NewRouter()
+			r.Put("/api/admin/users/{userID}/role", handler.UpdateUserRole)
+			r.Post("/api/admin/users/{userID}/suspend", handler.SuspendUser)
+			r.Delete("/api/admin/users/{userID}/suspend", handler.UnsuspendUser)
+			r.Post("/api/admin/users/{userID}/password-reset", handler.ForcePasswordReset)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, "/api/admin/users/"+userID.String()+tt.path, bytes.NewBufferString(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), config.ContextUserIDKey, actorID))
+			r.ServeHTTP(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAdminHandler_ManageSpotOwner(t *testing.T) {
+	spotID := "fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"
+	userID := "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"
+
+	patterns := []struct {
+		name       string
+		method     string
+		setup      func(m *mock.MockAdminUseCase)
+		wantStatus int
+	}{
+		{
+			name:   "success: assign",
+			method: http.MethodPut,
+			setup: func(m *mock.MockAdminUseCase) {
+				m.EXPECT().AssignSpotOwner(gomock.Any(), spotID, userID).Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:   "Fail: assign unknown spot",
+			method: http.MethodPut,
+			setup: func(m *mock.MockAdminUseCase) {
+				m.EXPECT().AssignSpotOwner(gomock.Any(), spotID, userID).Return(repository.ErrNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:   "success: remove",
+			method: http.MethodDelete,
+			setup: func(m *mock.MockAdminUseCase) {
+				m.EXPECT().RemoveSpotOwner(gomock.Any(), spotID, userID).Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+	}
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			auc := mock.NewMockAdminUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(auc)
+			}
+
+			handler := NewAdminHandler(auc)
+			r := chi.NewRouter()
+			r.Put("/api/admin/spots/{spotID}/owners/{userID}", handler.AssignSpotOwner)
+			r.Delete("/api/admin/spots/{spotID}/owners/{userID}", handler.RemoveSpotOwner)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, "/api/admin/spots/"+spotID+"/owners/"+userID, nil)
+			r.ServeHTTP(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
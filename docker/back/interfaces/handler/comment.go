@@ -3,13 +3,17 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"net/http"
+	"strconv"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 
 	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
 	"github.com/tusmasoma/campfinder/docker/back/usecase"
 )
 
@@ -19,8 +23,12 @@ type CommentHandler interface {
 	BatchCreateComments(w http.ResponseWriter, r *http.Request)
 	UpdateComment(w http.ResponseWriter, r *http.Request)
 	DeleteComment(w http.ResponseWriter, r *http.Request)
+	ListReplies(w http.ResponseWriter, r *http.Request)
 }
 
+// DefaultReplyLimit はコメントの一覧で各スレッドに含める返信の既定の数です。
+const DefaultReplyLimit = 3
+
 type commentHandler struct {
 	cuc usecase.CommentUseCase
 	auc usecase.AuthUseCase
@@ -33,8 +41,10 @@ func NewCommentHandler(cuc usecase.CommentUseCase, auc usecase.AuthUseCase) Comm
 	}
 }
 
+// CreateCommentRequest はレビューまたは返信の作成のリクエストです。ParentIDを指定した場合は返信になり、StarRateは指定できません。
 type CreateCommentRequest struct {
 	SpotID   uuid.UUID `json:"spotID"`
+	ParentID uuid.UUID `json:"parentID"`
 	StarRate float64   `json:"starRate"`
 	Text     string    `json:"text"`
 }
@@ -51,23 +61,131 @@ type UpdateCommentRequest struct {
 	Text     string    `json:"text"`
 }
 
+// CommentThreadResponse はレビューに、そのスレッドの返信を加えたものです。
+// next_reply_cursorが空でない場合は、続きの返信を/api/comment/{commentID}/repliesで取得できます。
+type CommentThreadResponse struct {
+	model.Comment
+	Replies         []model.Comment `json:"replies"`
+	NextReplyCursor string          `json:"next_reply_cursor"`
+}
+
 type ListCommentResponse struct {
-	Comments []model.Comment `json:"comments"`
+	Comments   []CommentThreadResponse `json:"comments"`
+	NextCursor string                  `json:"next_cursor"`
+	Total      int                     `json:"total"`
+}
+
+type ListRepliesResponse struct {
+	Replies    []model.Comment `json:"replies"`
+	NextCursor string          `json:"next_cursor"`
+	Total      int             `json:"total"`
 }
 
 func (ch *commentHandler) ListComments(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	spotID := r.URL.Query().Get("spot_id")
+	query := r.URL.Query()
+
+	lq, ok := parseListQuery(query, "created", "star_rate")
+	if !ok {
+		http.Error(w, "Invalid list comments request", http.StatusBadRequest)
+		return
+	}
+	replyLimit, ok := parseReplyLimit(query.Get("reply_limit"))
+	if !ok {
+		http.Error(w, "Invalid list comments request", http.StatusBadRequest)
+		return
+	}
 
-	comments, err := ch.cuc.ListComments(ctx, spotID)
+	result, err := ch.cuc.ListComments(ctx, &usecase.ListCommentsParams{
+		SpotID:     query.Get("spot_id"),
+		Limit:      lq.limit,
+		Cursor:     lq.cursor,
+		OrderBy:    lq.orderBy,
+		ReplyLimit: replyLimit,
+	})
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		http.Error(w, "Invalid cursor", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, "Failed to get comments by spot id", http.StatusInternalServerError)
+		return
+	}
+
+	response := ListCommentResponse{
+		Comments:   make([]CommentThreadResponse, 0, len(result.Threads)),
+		NextCursor: result.NextCursor,
+		Total:      result.Total,
+	}
+	for _, thread := range result.Threads {
+		replies := thread.Replies
+		if replies == nil {
+			replies = []model.Comment{}
+		}
+		response.Comments = append(response.Comments, CommentThreadResponse{
+			Comment:         thread.Comment,
+			Replies:         replies,
+			NextReplyCursor: thread.NextReplyCursor,
+		})
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	if err = json.NewEncoder(w).Encode(ListCommentResponse{Comments: comments}); err != nil {
+	if err = json.NewEncoder(w).Encode(response); err != nil {
 		http.Error(w, "Failed to encode comments to JSON", http.StatusInternalServerError)
-		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+// parseReplyLimit はreply_limitを検証します。省略した場合はDefaultReplyLimitで、0の場合は返信を含めません。
+func parseReplyLimit(value string) (int, bool) {
+	if value == "" {
+		return DefaultReplyLimit, true
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil || v < 0 || v > MaxListLimit {
+		log.Printf("Invalid reply_limit: %v", value)
+		return 0, false
+	}
+	return v, true
+}
+
+// ListReplies はレビューを起点とするスレッドの返信を古い順に返します。
+func (ch *commentHandler) ListReplies(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	lq, ok := parseListQuery(r.URL.Query())
+	if !ok {
+		http.Error(w, "Invalid list replies request", http.StatusBadRequest)
+		return
+	}
+
+	result, err := ch.cuc.ListReplies(ctx, &usecase.ListRepliesParams{
+		CommentID: chi.URLParam(r, "commentID"),
+		Limit:     lq.limit,
+		Cursor:    lq.cursor,
+	})
+	switch {
+	case errors.Is(err, repository.ErrInvalidCursor):
+		http.Error(w, "Invalid cursor", http.StatusBadRequest)
+		return
+	case errors.Is(err, usecase.ErrCommentNotFound):
+		http.Error(w, "Comment not found", http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, "Failed to get replies", http.StatusInternalServerError)
+		return
+	}
+
+	replies := result.Replies
+	if replies == nil {
+		replies = []model.Comment{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(ListRepliesResponse{
+		Replies:    replies,
+		NextCursor: result.NextCursor,
+		Total:      result.Total,
+	}); err != nil {
+		http.Error(w, "Failed to encode replies to JSON", http.StatusInternalServerError)
 		return
 	}
 }
@@ -88,7 +206,15 @@ func (ch *commentHandler) CreateComment(w http.ResponseWriter, r *http.Request)
 	defer r.Body.Close()
 
 	params := convertCreateCommentReqeuestToParams(requestBody, user.ID)
-	if err = ch.cuc.CreateComment(ctx, params); err != nil {
+	err = ch.cuc.CreateComment(ctx, params)
+	switch {
+	case errors.Is(err, usecase.ErrCommentNotFound):
+		http.Error(w, "Parent comment not found", http.StatusNotFound)
+		return
+	case errors.Is(err, usecase.ErrCommentTooDeep), errors.Is(err, usecase.ErrInvalidStarRate):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
 		http.Error(w, "Internal server error while creating comment", http.StatusInternalServerError)
 		return
 	}
@@ -101,10 +227,15 @@ func isValidateCreateCommentRequest(body io.ReadCloser, requestBody *CreateComme
 		log.Printf("Invalid request body: %v", err)
 		return false
 	}
-	if requestBody.SpotID.String() == DefaultUUID || requestBody.StarRate == 0 || requestBody.Text == "" {
+	if requestBody.SpotID.String() == DefaultUUID || requestBody.Text == "" {
 		log.Printf("Missing required fields")
 		return false
 	}
+	// レビューには評価が必要で、返信は評価を持たない
+	if (requestBody.ParentID == uuid.Nil) == (requestBody.StarRate == 0) {
+		log.Printf("Star rate is required only for reviews")
+		return false
+	}
 	return true
 }
 
@@ -112,6 +243,7 @@ func convertCreateCommentReqeuestToParams(req CreateCommentRequest, userID uuid.
 	return &usecase.CreateCommentParams{
 		UserID:   userID,
 		SpotID:   req.SpotID,
+		ParentID: req.ParentID,
 		StarRate: req.StarRate,
 		Text:     req.Text,
 	}
@@ -197,7 +329,7 @@ func (ch *commentHandler) UpdateComment(w http.ResponseWriter, r *http.Request)
 		requestBody.Text,
 		*user,
 	); err != nil {
-		http.Error(w, "Internal server error while updating comment", http.StatusInternalServerError)
+		writeCommentWriteError(w, err, "Internal server error while updating comment")
 		return
 	}
 
@@ -212,7 +344,6 @@ func isValidateUpdateCommentRequest(body io.ReadCloser, requestBody *UpdateComme
 	if requestBody.ID.String() == DefaultUUID ||
 		requestBody.SpotID.String() == DefaultUUID ||
 		requestBody.UserID.String() == DefaultUUID ||
-		requestBody.StarRate == 0 ||
 		requestBody.Text == "" {
 		log.Printf("Missing required fields")
 		return false
@@ -235,7 +366,7 @@ func (ch *commentHandler) DeleteComment(w http.ResponseWriter, r *http.Request)
 	}
 
 	if err = ch.cuc.DeleteComment(ctx, id, userID, *user); err != nil {
-		http.Error(w, "Internal server error while deleting comment", http.StatusInternalServerError)
+		writeCommentWriteError(w, err, "Internal server error while deleting comment")
 		return
 	}
 
@@ -252,3 +383,16 @@ func isValidateDeleteCommentRequest(r *http.Request) (bool, string, string) {
 	}
 	return true, id, userID
 }
+
+func writeCommentWriteError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, usecase.ErrPermissionDenied):
+		http.Error(w, "Don't have permission to modify comment", http.StatusForbidden)
+	case errors.Is(err, usecase.ErrCommentNotFound):
+		http.Error(w, "Comment not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrInvalidStarRate):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, message, http.StatusInternalServerError)
+	}
+}
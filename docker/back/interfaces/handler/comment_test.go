@@ -9,10 +9,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 
 	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
 	"github.com/tusmasoma/campfinder/docker/back/usecase"
 	"github.com/tusmasoma/campfinder/docker/back/usecase/mock"
 )
@@ -35,19 +37,26 @@ func TestCommentHandler_ListComments(t *testing.T) {
 				created, _ := time.Parse(layout, "0001-01-01T00:00:00Z")
 				m.EXPECT().ListComments(
 					gomock.Any(),
-					"fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052",
-				).Return(
-					[]model.Comment{
+					&usecase.ListCommentsParams{
+						SpotID:     "fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052",
+						Limit:      DefaultListLimit,
+						ReplyLimit: DefaultReplyLimit,
+					},
+				).Return(&usecase.ListCommentsResult{
+					Threads: []usecase.CommentThread{
 						{
-							ID:       uuid.New(),
-							SpotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
-							UserID:   uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
-							StarRate: 2,
-							Text:     "いいスポットでした!!!",
-							Created:  created,
+							Comment: model.Comment{
+								ID:       uuid.New(),
+								SpotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
+								UserID:   uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+								StarRate: 2,
+								Text:     "いいスポットでした!!!",
+								Created:  created,
+							},
 						},
-					}, nil,
-				)
+					},
+					Total: 1,
+				}, nil)
 			},
 			in: func() *http.Request {
 				req, _ := http.NewRequest(http.MethodGet, "/api/comment?spot_id=fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052", nil)
@@ -55,6 +64,55 @@ func TestCommentHandler_ListComments(t *testing.T) {
 			},
 			wantStatus: http.StatusOK,
 		},
+		{
+			name: "success: without replies",
+			setup: func(m *mock.MockCommentUseCase, m1 *mock.MockAuthUseCase) {
+				m.EXPECT().ListComments(
+					gomock.Any(),
+					&usecase.ListCommentsParams{SpotID: "fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052", Limit: DefaultListLimit},
+				).Return(&usecase.ListCommentsResult{}, nil)
+			},
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "/api/comment?spot_id=fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052&reply_limit=0", nil)
+				return req
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Fail: invalid limit",
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "/api/comment?spot_id=fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052&limit=0", nil)
+				return req
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: invalid reply_limit",
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "/api/comment?spot_id=fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052&reply_limit=-1", nil)
+				return req
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: invalid cursor",
+			setup: func(m *mock.MockCommentUseCase, m1 *mock.MockAuthUseCase) {
+				m.EXPECT().ListComments(
+					gomock.Any(),
+					&usecase.ListCommentsParams{
+						SpotID:     "fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052",
+						Limit:      DefaultListLimit,
+						Cursor:     "invalid",
+						ReplyLimit: DefaultReplyLimit,
+					},
+				).Return(nil, repository.ErrInvalidCursor)
+			},
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "/api/comment?spot_id=fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052&cursor=invalid", nil)
+				return req
+			},
+			wantStatus: http.StatusBadRequest,
+		},
 	}
 	for _, tt := range patterns {
 		t.Run(tt.name, func(t *testing.T) {
@@ -149,6 +207,65 @@ func TestCommentHandler_CreateComment(t *testing.T) {
 			},
 			wantStatus: http.StatusBadRequest,
 		},
+		{
+			name: "success: reply",
+			setup: func(m *mock.MockCommentUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(
+					&model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")}, nil,
+				)
+				m.EXPECT().CreateComment(
+					gomock.Any(),
+					&usecase.CreateCommentParams{
+						UserID:   uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+						SpotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
+						ParentID: uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+						Text:     "ありがとうございます！",
+					},
+				).Return(nil)
+			},
+			in: func() *http.Request {
+				return newCreateReplyRequest(0)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Fail: reply with star rate",
+			setup: func(m *mock.MockCommentUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(
+					&model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")}, nil,
+				)
+			},
+			in: func() *http.Request {
+				return newCreateReplyRequest(3.0)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: reply too deep",
+			setup: func(m *mock.MockCommentUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(
+					&model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")}, nil,
+				)
+				m.EXPECT().CreateComment(gomock.Any(), gomock.Any()).Return(usecase.ErrCommentTooDeep)
+			},
+			in: func() *http.Request {
+				return newCreateReplyRequest(0)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: parent not found",
+			setup: func(m *mock.MockCommentUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(
+					&model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")}, nil,
+				)
+				m.EXPECT().CreateComment(gomock.Any(), gomock.Any()).Return(usecase.ErrCommentNotFound)
+			},
+			in: func() *http.Request {
+				return newCreateReplyRequest(0)
+			},
+			wantStatus: http.StatusNotFound,
+		},
 	}
 	for _, tt := range patterns {
 		t.Run(tt.name, func(t *testing.T) {
@@ -174,6 +291,97 @@ func TestCommentHandler_CreateComment(t *testing.T) {
 	}
 }
 
+func newCreateReplyRequest(starRate float64) *http.Request {
+	reqBody, _ := json.Marshal(CreateCommentRequest{
+		SpotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
+		ParentID: uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+		StarRate: starRate,
+		Text:     "ありがとうございます！",
+	})
+	req, _ := http.NewRequest(http.MethodPost, "/api/comment/create", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestCommentHandler_ListReplies(t *testing.T) {
+	t.Parallel()
+	commentID := "31894386-3e60-45a8-bc67-f46b72b42554"
+	reply := model.Comment{
+		ID:       uuid.MustParse("5ba6a9f5-4b3c-4e47-8d5e-0c3c1f0d6a2e"),
+		ParentID: uuid.NullUUID{UUID: uuid.MustParse(commentID), Valid: true},
+		RootID:   uuid.NullUUID{UUID: uuid.MustParse(commentID), Valid: true},
+		Depth:    1,
+		Kind:     model.CommentKindOwnerResponse,
+		Text:     "ありがとうございます！",
+	}
+
+	patterns := []struct {
+		name       string
+		setup      func(m *mock.MockCommentUseCase)
+		query      string
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockCommentUseCase) {
+				m.EXPECT().ListReplies(gomock.Any(), &usecase.ListRepliesParams{CommentID: commentID, Limit: DefaultListLimit}).Return(
+					&usecase.ListRepliesResult{Replies: []model.Comment{reply}, Total: 1}, nil,
+				)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Fail: not found",
+			setup: func(m *mock.MockCommentUseCase) {
+				m.EXPECT().ListReplies(gomock.Any(), gomock.Any()).Return(nil, usecase.ErrCommentNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "Fail: invalid cursor",
+			setup: func(m *mock.MockCommentUseCase) {
+				m.EXPECT().ListReplies(gomock.Any(), gomock.Any()).Return(nil, repository.ErrInvalidCursor)
+			},
+			query:      "?cursor=invalid",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			cuc := mock.NewMockCommentUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(cuc)
+			}
+
+			handler := NewCommentHandler(cuc, mock.NewMockAuthUseCase(ctrl))
+			r := chi.NewRouter()
+			r.Get("/api/comment/{commentID}/replies", handler.ListReplies)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/comment/"+commentID+"/replies"+tt.query, nil)
+			r.ServeHTTP(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if tt.name != "success" {
+				return
+			}
+			var got ListRepliesResponse
+			if err := json.NewDecoder(recorder.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if got.Total != 1 || len(got.Replies) != 1 || got.Replies[0].Kind != model.CommentKindOwnerResponse ||
+				got.Replies[0].ParentID.UUID.String() != commentID {
+				t.Errorf("ListReplies() = %+v", got)
+			}
+		})
+	}
+}
+
 func TestCommentHandler_BatchCreateComments(t *testing.T) {
 	t.Parallel()
 	patterns := []struct {
@@ -474,6 +682,27 @@ func TestCommentHandler_DeleteComment(t *testing.T) {
 		in         func() *http.Request
 		wantStatus int
 	}{
+		{
+			name: "Fail: not the author",
+			setup: func(m *mock.MockCommentUseCase, m1 *mock.MockAuthUseCase) {
+				user := model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e61234")}
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&user, nil)
+				m.EXPECT().DeleteComment(
+					gomock.Any(),
+					"31894386-3e60-45a8-bc67-f46b72b42554",
+					"f6db2530-cd9b-4ac1-8dc1-38c795e61234",
+					user,
+				).Return(usecase.ErrPermissionDenied)
+			},
+			in: func() *http.Request {
+				req, _ := http.NewRequest(
+					http.MethodDelete,
+					"/api/comment/delete?id=31894386-3e60-45a8-bc67-f46b72b42554&user_id=f6db2530-cd9b-4ac1-8dc1-38c795e61234",
+					nil)
+				return req
+			},
+			wantStatus: http.StatusForbidden,
+		},
 		{
 			name: "success",
 			setup: func(m *mock.MockCommentUseCase, m1 *mock.MockAuthUseCase) {
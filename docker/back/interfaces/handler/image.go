@@ -3,13 +3,16 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"net/http"
 
 	"github.com/google/uuid"
 
+	"github.com/tusmasoma/campfinder/docker/back/config"
 	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
 	"github.com/tusmasoma/campfinder/docker/back/usecase"
 )
 
@@ -20,18 +23,27 @@ const (
 type ImageHandler interface {
 	ListImages(w http.ResponseWriter, r *http.Request)
 	CreateImage(w http.ResponseWriter, r *http.Request)
+	UploadImage(w http.ResponseWriter, r *http.Request)
+	CreateUploadURL(w http.ResponseWriter, r *http.Request)
+	CompleteUpload(w http.ResponseWriter, r *http.Request)
 	DeleteImage(w http.ResponseWriter, r *http.Request)
+	ReportImage(w http.ResponseWriter, r *http.Request)
+	ListModerationQueue(w http.ResponseWriter, r *http.Request)
+	ApproveImage(w http.ResponseWriter, r *http.Request)
+	RejectImage(w http.ResponseWriter, r *http.Request)
 }
 
 type imageHandler struct {
 	iuc usecase.ImageUseCase
 	auc usecase.AuthUseCase
+	sc  *config.StorageConfig
 }
 
-func NewImageHandler(iuc usecase.ImageUseCase, auc usecase.AuthUseCase) ImageHandler {
+func NewImageHandler(iuc usecase.ImageUseCase, auc usecase.AuthUseCase, sc *config.StorageConfig) ImageHandler {
 	return &imageHandler{
 		iuc: iuc,
 		auc: auc,
+		sc:  sc,
 	}
 }
 
@@ -41,22 +53,43 @@ type CreateImageRequest struct {
 }
 
 type ListImageResponse struct {
-	Images []model.Image `json:"images"`
+	Images     []model.Image `json:"images"`
+	NextCursor string        `json:"next_cursor"`
+	Total      int           `json:"total"`
 }
 
 func (ih *imageHandler) ListImages(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	spotID := r.URL.Query().Get("spot_id")
+	query := r.URL.Query()
 
-	imgs, err := ih.iuc.ListImages(ctx, spotID)
+	lq, ok := parseListQuery(query, "created")
+	if !ok {
+		http.Error(w, "Invalid list images request", http.StatusBadRequest)
+		return
+	}
+
+	result, err := ih.iuc.ListImages(ctx, &usecase.ListImagesParams{
+		SpotID:  query.Get("spot_id"),
+		Limit:   lq.limit,
+		Cursor:  lq.cursor,
+		OrderBy: lq.orderBy,
+	})
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		http.Error(w, "Invalid cursor", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, "Failed to get images by spot id", http.StatusInternalServerError)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	if err = json.NewEncoder(w).Encode(ListImageResponse{Images: imgs}); err != nil {
-		http.Error(w, "Failed to encode spots to JSON", http.StatusInternalServerError)
-		w.WriteHeader(http.StatusInternalServerError)
+	if err = json.NewEncoder(w).Encode(ListImageResponse{
+		Images:     result.Images,
+		NextCursor: result.NextCursor,
+		Total:      result.Total,
+	}); err != nil {
+		http.Error(w, "Failed to encode images to JSON", http.StatusInternalServerError)
 		return
 	}
 }
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+	"github.com/tusmasoma/campfinder/docker/back/usecase"
+)
+
+// MaxReportReasonLength は通報の理由の最大文字数です。
+const MaxReportReasonLength = 500
+
+type ReportImageRequest struct {
+	ImageID uuid.UUID `json:"imageID"`
+	Reason  string    `json:"reason"`
+}
+
+type ImageReportResponse struct {
+	ID      string    `json:"id"`
+	UserID  string    `json:"userID"`
+	Reason  string    `json:"reason"`
+	Created time.Time `json:"created"`
+}
+
+type ModerationQueueItemResponse struct {
+	Image   model.Image           `json:"image"`
+	Reports []ImageReportResponse `json:"reports"`
+}
+
+type ModerationQueueResponse struct {
+	Items      []ModerationQueueItemResponse `json:"items"`
+	NextCursor string                        `json:"next_cursor"`
+	Total      int                           `json:"total"`
+}
+
+// ReportImage は公開中の画像を通報します。
+func (ih *imageHandler) ReportImage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, err := ih.auc.GetUserFromContext(ctx)
+	if err != nil {
+		http.Error(w, "Failed to get UserInfo from context", http.StatusInternalServerError)
+		return
+	}
+
+	var requestBody ReportImageRequest
+	if ok := isValidReportImageRequest(r.Body, &requestBody); !ok {
+		http.Error(w, "Invalid image report request", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	err = ih.iuc.ReportImage(ctx, requestBody.ImageID.String(), requestBody.Reason, *user)
+	switch {
+	case errors.Is(err, usecase.ErrImageNotFound):
+		http.Error(w, "Image not found", http.StatusNotFound)
+		return
+	case errors.Is(err, usecase.ErrAlreadyReported):
+		http.Error(w, "Image already reported", http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "Internal server error while reporting image", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func isValidReportImageRequest(body io.ReadCloser, requestBody *ReportImageRequest) bool {
+	if err := json.NewDecoder(body).Decode(requestBody); err != nil {
+		log.Printf("Invalid request body: %v", err)
+		return false
+	}
+	if requestBody.ImageID == uuid.Nil || requestBody.Reason == "" {
+		log.Printf("Missing required fields")
+		return false
+	}
+	if utf8.RuneCountInString(requestBody.Reason) > MaxReportReasonLength {
+		log.Printf("Report reason is too long")
+		return false
+	}
+	return true
+}
+
+// ListModerationQueue はstatus(既定はpending)の画像を古い順に、通報とあわせて返します。
+func (ih *imageHandler) ListModerationQueue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params, ok := isValidListModerationQueueRequest(r.URL.Query())
+	if !ok {
+		http.Error(w, "Invalid query parameters", http.StatusBadRequest)
+		return
+	}
+
+	result, err := ih.iuc.ListModerationQueue(ctx, params)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		http.Error(w, "Invalid cursor", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to list moderation queue", http.StatusInternalServerError)
+		return
+	}
+
+	response := ModerationQueueResponse{
+		Items:      make([]ModerationQueueItemResponse, 0, len(result.Items)),
+		NextCursor: result.NextCursor,
+		Total:      result.Total,
+	}
+	for _, item := range result.Items {
+		reports := make([]ImageReportResponse, 0, len(item.Reports))
+		for _, report := range item.Reports {
+			reports = append(reports, ImageReportResponse{
+				ID:      report.ID.String(),
+				UserID:  report.UserID.String(),
+				Reason:  report.Reason,
+				Created: report.Created,
+			})
+		}
+		response.Items = append(response.Items, ModerationQueueItemResponse{Image: item.Image, Reports: reports})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Failed to encode moderation queue to JSON", http.StatusInternalServerError)
+		return
+	}
+}
+
+func isValidListModerationQueueRequest(query url.Values) (*usecase.ListModerationQueueParams, bool) {
+	lq, ok := parseListQuery(query)
+	if !ok {
+		return nil, false
+	}
+	status := model.ImageStatus(query.Get("status"))
+	if status != "" && !status.IsValid() {
+		log.Printf("Invalid status: %v", status)
+		return nil, false
+	}
+	return &usecase.ListModerationQueueParams{
+		Status: status,
+		Limit:  lq.limit,
+		Cursor: lq.cursor,
+	}, true
+}
+
+func (ih *imageHandler) ApproveImage(w http.ResponseWriter, r *http.Request) {
+	ih.moderateImage(w, r, model.ImageStatusApproved)
+}
+
+func (ih *imageHandler) RejectImage(w http.ResponseWriter, r *http.Request) {
+	ih.moderateImage(w, r, model.ImageStatusRejected)
+}
+
+func (ih *imageHandler) moderateImage(w http.ResponseWriter, r *http.Request, status model.ImageStatus) {
+	ctx := r.Context()
+	moderator, err := ih.auc.GetUserFromContext(ctx)
+	if err != nil {
+		http.Error(w, "Failed to get UserInfo from context", http.StatusInternalServerError)
+		return
+	}
+
+	img, err := ih.iuc.ModerateImage(ctx, chi.URLParam(r, "imageID"), status, *moderator)
+	if errors.Is(err, usecase.ErrImageNotFound) {
+		http.Error(w, "Image not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Internal server error while moderating image", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(img); err != nil {
+		http.Error(w, "Failed to encode image to JSON", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+	"github.com/tusmasoma/campfinder/docker/back/usecase"
+	"github.com/tusmasoma/campfinder/docker/back/usecase/mock"
+)
+
+func TestImageHandler_ReportImage(t *testing.T) {
+	t.Parallel()
+	user := model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")}
+	imageID := "31894386-3e60-45a8-bc67-f46b72b42554"
+
+	patterns := []struct {
+		name       string
+		setup      func(m *mock.MockImageUseCase)
+		body       string
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockImageUseCase) {
+				m.EXPECT().ReportImage(gomock.Any(), imageID, "spam", user).Return(nil)
+			},
+			body:       `{"imageID":"` + imageID + `","reason":"spam"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Fail: missing reason",
+			body:       `{"imageID":"` + imageID + `"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Fail: reason too long",
+			body:       `{"imageID":"` + imageID + `","reason":"` + strings.Repeat("あ", MaxReportReasonLength+1) + `"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: not found",
+			setup: func(m *mock.MockImageUseCase) {
+				m.EXPECT().ReportImage(gomock.Any(), imageID, "spam", user).Return(usecase.ErrImageNotFound)
+			},
+			body:       `{"imageID":"` + imageID + `","reason":"spam"}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "Fail: already reported",
+			setup: func(m *mock.MockImageUseCase) {
+				m.EXPECT().ReportImage(gomock.Any(), imageID, "spam", user).Return(usecase.ErrAlreadyReported)
+			},
+			body:       `{"imageID":"` + imageID + `","reason":"spam"}`,
+			wantStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			iuc := mock.NewMockImageUseCase(ctrl)
+			auc := mock.NewMockAuthUseCase(ctrl)
+			auc.EXPECT().GetUserFromContext(gomock.Any()).Return(&user, nil)
+
+			if tt.setup != nil {
+				tt.setup(iuc)
+			}
+
+			handler := NewImageHandler(iuc, auc, &testStorageConfig)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/api/img/report", strings.NewReader(tt.body))
+			handler.ReportImage(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestImageHandler_ListModerationQueue(t *testing.T) {
+	t.Parallel()
+	img := model.Image{ID: uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"), Status: model.ImageStatusPending}
+	report := model.ImageReport{
+		ID:      uuid.MustParse("5ba6a9f5-4b3c-4e47-8d5e-0c3c1f0d6a2e"),
+		ImageID: img.ID,
+		UserID:  uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+		Reason:  "spam",
+	}
+
+	patterns := []struct {
+		name       string
+		setup      func(m *mock.MockImageUseCase)
+		query      string
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockImageUseCase) {
+				m.EXPECT().ListModerationQueue(gomock.Any(), &usecase.ListModerationQueueParams{Limit: DefaultListLimit}).Return(
+					&usecase.ModerationQueueResult{
+						Items: []usecase.ModerationQueueItem{{Image: img, Reports: []model.ImageReport{report}}},
+						Total: 1,
+					}, nil,
+				)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "success: rejected",
+			setup: func(m *mock.MockImageUseCase) {
+				m.EXPECT().ListModerationQueue(gomock.Any(), &usecase.ListModerationQueueParams{
+					Status: model.ImageStatusRejected,
+					Limit:  10,
+				}).Return(&usecase.ModerationQueueResult{}, nil)
+			},
+			query:      "?status=rejected&limit=10",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Fail: invalid status",
+			query:      "?status=deleted",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: invalid cursor",
+			setup: func(m *mock.MockImageUseCase) {
+				m.EXPECT().ListModerationQueue(gomock.Any(), gomock.Any()).Return(nil, repository.ErrInvalidCursor)
+			},
+			query:      "?cursor=invalid",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			iuc := mock.NewMockImageUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(iuc)
+			}
+
+			handler := NewImageHandler(iuc, mock.NewMockAuthUseCase(ctrl), &testStorageConfig)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/moderation/images"+tt.query, nil)
+			handler.ListModerationQueue(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if tt.name != "success" {
+				return
+			}
+			var got ModerationQueueResponse
+			if err := json.NewDecoder(recorder.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if got.Total != 1 || len(got.Items) != 1 || got.Items[0].Image.ID != img.ID ||
+				len(got.Items[0].Reports) != 1 || got.Items[0].Reports[0].Reason != "spam" {
+				t.Errorf("ListModerationQueue() = %+v", got)
+			}
+		})
+	}
+}
+
+func TestImageHandler_ModerateImage(t *testing.T) {
+	t.Parallel()
+	moderator := model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"), Role: model.RoleModerator}
+	imageID := "31894386-3e60-45a8-bc67-f46b72b42554"
+
+	patterns := []struct {
+		name       string
+		path       string
+		setup      func(m *mock.MockImageUseCase)
+		wantStatus int
+	}{
+		{
+			name: "success: approve",
+			path: "/approve",
+			setup: func(m *mock.MockImageUseCase) {
+				m.EXPECT().ModerateImage(gomock.Any(), imageID, model.ImageStatusApproved, moderator).Return(
+					&model.Image{Status: model.ImageStatusApproved}, nil,
+				)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "success: reject",
+			path: "/reject",
+			setup: func(m *mock.MockImageUseCase) {
+				m.EXPECT().ModerateImage(gomock.Any(), imageID, model.ImageStatusRejected, moderator).Return(
+					&model.Image{Status: model.ImageStatusRejected}, nil,
+				)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Fail: not found",
+			path: "/approve",
+			setup: func(m *mock.MockImageUseCase) {
+				m.EXPECT().ModerateImage(gomock.Any(), imageID, model.ImageStatusApproved, moderator).Return(
+					nil, usecase.ErrImageNotFound,
+				)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			iuc := mock.NewMockImageUseCase(ctrl)
+			auc := mock.NewMockAuthUseCase(ctrl)
+			auc.EXPECT().GetUserFromContext(gomock.Any()).Return(&moderator, nil)
+
+			if tt.setup != nil {
+				tt.setup(iuc)
+			}
+
+			handler := NewImageHandler(iuc, auc, &testStorageConfig)
+			r := chi.NewRouter()
+			r.Post("/api/moderation/images/{imageID}/approve", handler.ApproveImage)
+			r.Post("/api/moderation/images/{imageID}/reject", handler.RejectImage)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/api/moderation/images/"+imageID+tt.path, nil)
+			r.ServeHTTP(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/usecase"
+)
+
+type CreateUploadURLRequest struct {
+	SpotID      uuid.UUID `json:"spotID"`
+	ContentType string    `json:"contentType"`
+}
+
+// CreateUploadURLResponse はクライアントがUploadURLへHeadersを付けてMethodで画像を送るための情報です。
+type CreateUploadURLResponse struct {
+	UploadURL string            `json:"uploadURL"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	Key       string            `json:"key"`
+	ExpiresIn int64             `json:"expiresIn"`
+}
+
+type CompleteUploadRequest struct {
+	Key string `json:"key"`
+}
+
+// CreateUploadURL は画像をストレージへ直接アップロードするための署名付きURLを返します。
+// アップロードが終わったら、返したkeyをCompleteUploadに送って画像を登録します。
+func (ih *imageHandler) CreateUploadURL(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, err := ih.auc.GetUserFromContext(ctx)
+	if err != nil {
+		http.Error(w, "Failed to get UserInfo from context", http.StatusInternalServerError)
+		return
+	}
+
+	var requestBody CreateUploadURLRequest
+	if ok := isValidCreateUploadURLRequest(r.Body, &requestBody); !ok {
+		http.Error(w, "Invalid upload url request", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	upload, err := ih.iuc.CreateUploadURL(ctx, requestBody.SpotID, requestBody.ContentType, *user)
+	switch {
+	case errors.Is(err, usecase.ErrUnsupportedImageType):
+		http.Error(w, "Only JPEG and PNG images are supported", http.StatusUnsupportedMediaType)
+		return
+	case errors.Is(err, usecase.ErrSpotNotFound):
+		http.Error(w, "Spot not found", http.StatusNotFound)
+		return
+	case errors.Is(err, usecase.ErrDirectUploadUnavailable):
+		http.Error(w, "Direct upload is not available, use /api/img/upload instead", http.StatusNotImplemented)
+		return
+	case err != nil:
+		http.Error(w, "Internal server error while creating upload url", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err = json.NewEncoder(w).Encode(CreateUploadURLResponse{
+		UploadURL: upload.URL,
+		Method:    http.MethodPut,
+		Headers:   map[string]string{"Content-Type": upload.ContentType},
+		Key:       upload.Key,
+		ExpiresIn: int64(upload.ExpiresIn.Seconds()),
+	}); err != nil {
+		http.Error(w, "Failed to encode upload url to JSON", http.StatusInternalServerError)
+		return
+	}
+}
+
+func isValidCreateUploadURLRequest(body io.ReadCloser, requestBody *CreateUploadURLRequest) bool {
+	if err := json.NewDecoder(body).Decode(requestBody); err != nil {
+		log.Printf("Invalid request body: %v", err)
+		return false
+	}
+	if requestBody.SpotID == uuid.Nil || requestBody.ContentType == "" {
+		log.Printf("Missing required fields")
+		return false
+	}
+	return true
+}
+
+// CompleteUpload は直接アップロードされた画像を検査して登録し、登録したImageを返します。
+func (ih *imageHandler) CompleteUpload(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, err := ih.auc.GetUserFromContext(ctx)
+	if err != nil {
+		http.Error(w, "Failed to get UserInfo from context", http.StatusInternalServerError)
+		return
+	}
+
+	var requestBody CompleteUploadRequest
+	if ok := isValidCompleteUploadRequest(r.Body, &requestBody); !ok {
+		http.Error(w, "Invalid upload complete request", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	img, err := ih.iuc.CompleteUpload(ctx, requestBody.Key, *user)
+	switch {
+	case errors.Is(err, usecase.ErrUploadNotFound):
+		http.Error(w, "Upload not found", http.StatusNotFound)
+		return
+	case errors.Is(err, usecase.ErrUnsupportedImageType):
+		http.Error(w, "Only JPEG and PNG images are supported", http.StatusUnsupportedMediaType)
+		return
+	case errors.Is(err, usecase.ErrImageTooLarge):
+		http.Error(w, "Image is too large", http.StatusRequestEntityTooLarge)
+		return
+	case errors.Is(err, usecase.ErrInvalidImage):
+		http.Error(w, "Invalid image", http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, "Internal server error while completing upload", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(img); err != nil {
+		http.Error(w, "Failed to encode image to JSON", http.StatusInternalServerError)
+		return
+	}
+}
+
+func isValidCompleteUploadRequest(body io.ReadCloser, requestBody *CompleteUploadRequest) bool {
+	if err := json.NewDecoder(body).Decode(requestBody); err != nil {
+		log.Printf("Invalid request body: %v", err)
+		return false
+	}
+	if requestBody.Key == "" {
+		log.Printf("Missing required fields")
+		return false
+	}
+	return true
+}
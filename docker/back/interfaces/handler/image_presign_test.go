@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/usecase"
+	"github.com/tusmasoma/campfinder/docker/back/usecase/mock"
+)
+
+func TestImageHandler_CreateUploadURL(t *testing.T) {
+	t.Parallel()
+	user := model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")}
+	spotID := uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052")
+	key := "uploads/f6db2530-cd9b-4ac1-8dc1-38c795e6eec2/fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052/photo.jpg"
+
+	patterns := []struct {
+		name       string
+		setup      func(m *mock.MockImageUseCase)
+		body       string
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockImageUseCase) {
+				m.EXPECT().CreateUploadURL(gomock.Any(), spotID, "image/jpeg", user).Return(&usecase.UploadURL{
+					Key:         key,
+					URL:         "http://minio:9000/campfinder/" + key + "?X-Amz-Signature=abc",
+					ContentType: "image/jpeg",
+					ExpiresIn:   15 * time.Minute,
+				}, nil)
+			},
+			body:       `{"spotID":"fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052","contentType":"image/jpeg"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Fail: missing content type",
+			body:       `{"spotID":"fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: unsupported type",
+			setup: func(m *mock.MockImageUseCase) {
+				m.EXPECT().CreateUploadURL(gomock.Any(), spotID, "image/gif", user).Return(nil, usecase.ErrUnsupportedImageType)
+			},
+			body:       `{"spotID":"fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052","contentType":"image/gif"}`,
+			wantStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name: "Fail: spot not found",
+			setup: func(m *mock.MockImageUseCase) {
+				m.EXPECT().CreateUploadURL(gomock.Any(), spotID, "image/jpeg", user).Return(nil, usecase.ErrSpotNotFound)
+			},
+			body:       `{"spotID":"fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052","contentType":"image/jpeg"}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "Fail: local storage",
+			setup: func(m *mock.MockImageUseCase) {
+				m.EXPECT().CreateUploadURL(gomock.Any(), spotID, "image/png", user).Return(
+					nil, usecase.ErrDirectUploadUnavailable,
+				)
+			},
+			body:       `{"spotID":"fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052","contentType":"image/png"}`,
+			wantStatus: http.StatusNotImplemented,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			iuc := mock.NewMockImageUseCase(ctrl)
+			auc := mock.NewMockAuthUseCase(ctrl)
+			auc.EXPECT().GetUserFromContext(gomock.Any()).Return(&user, nil)
+
+			if tt.setup != nil {
+				tt.setup(iuc)
+			}
+
+			handler := NewImageHandler(iuc, auc, &testStorageConfig)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/api/img/upload-url", strings.NewReader(tt.body))
+			handler.CreateUploadURL(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if cc := recorder.Header().Get("Cache-Control"); cc != "no-store" {
+				t.Errorf("Cache-Control = %q, want no-store", cc)
+			}
+			var got CreateUploadURLResponse
+			if err := json.NewDecoder(recorder.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if got.Key != key || got.Method != http.MethodPut || got.Headers["Content-Type"] != "image/jpeg" ||
+				got.ExpiresIn != 900 || !strings.HasPrefix(got.UploadURL, "http://minio:9000/") {
+				t.Errorf("CreateUploadURL() = %+v", got)
+			}
+		})
+	}
+}
+
+func TestImageHandler_CompleteUpload(t *testing.T) {
+	t.Parallel()
+	user := model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")}
+	key := "uploads/f6db2530-cd9b-4ac1-8dc1-38c795e6eec2/fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052/photo.jpg"
+	img := model.Image{
+		ID:         uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+		SpotID:     uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
+		UserID:     user.ID,
+		StorageKey: "spots/fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052/31894386-3e60-45a8-bc67-f46b72b42554.jpg",
+	}
+
+	patterns := []struct {
+		name       string
+		setup      func(m *mock.MockImageUseCase)
+		body       string
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockImageUseCase) {
+				m.EXPECT().CompleteUpload(gomock.Any(), key, user).Return(&img, nil)
+			},
+			body:       `{"key":"` + key + `"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Fail: missing key",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: not uploaded",
+			setup: func(m *mock.MockImageUseCase) {
+				m.EXPECT().CompleteUpload(gomock.Any(), key, user).Return(nil, usecase.ErrUploadNotFound)
+			},
+			body:       `{"key":"` + key + `"}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "Fail: too large",
+			setup: func(m *mock.MockImageUseCase) {
+				m.EXPECT().CompleteUpload(gomock.Any(), key, user).Return(nil, usecase.ErrImageTooLarge)
+			},
+			body:       `{"key":"` + key + `"}`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "Fail: unsupported type",
+			setup: func(m *mock.MockImageUseCase) {
+				m.EXPECT().CompleteUpload(gomock.Any(), key, user).Return(nil, usecase.ErrUnsupportedImageType)
+			},
+			body:       `{"key":"` + key + `"}`,
+			wantStatus: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			iuc := mock.NewMockImageUseCase(ctrl)
+			auc := mock.NewMockAuthUseCase(ctrl)
+			auc.EXPECT().GetUserFromContext(gomock.Any()).Return(&user, nil)
+
+			if tt.setup != nil {
+				tt.setup(iuc)
+			}
+
+			handler := NewImageHandler(iuc, auc, &testStorageConfig)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/api/img/upload-complete", strings.NewReader(tt.body))
+			handler.CompleteUpload(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got model.Image
+			if err := json.NewDecoder(recorder.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if got != img {
+				t.Errorf("CompleteUpload() = %+v, want %+v", got, img)
+			}
+		})
+	}
+}
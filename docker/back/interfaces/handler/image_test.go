@@ -13,6 +13,8 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+	"github.com/tusmasoma/campfinder/docker/back/usecase"
 	"github.com/tusmasoma/campfinder/docker/back/usecase/mock"
 )
 
@@ -34,9 +36,9 @@ func TestImageHandler_ListImages(t *testing.T) {
 				created, _ := time.Parse(layout, "0001-01-01T00:00:00Z")
 				m.EXPECT().ListImages(
 					gomock.Any(),
-					"fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052",
-				).Return(
-					[]model.Image{
+					&usecase.ListImagesParams{SpotID: "fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052", Limit: DefaultListLimit},
+				).Return(&usecase.ListImagesResult{
+					Images: []model.Image{
 						{
 							ID:      uuid.New(),
 							SpotID:  uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
@@ -44,8 +46,9 @@ func TestImageHandler_ListImages(t *testing.T) {
 							URL:     "https://hoge.com/hoge",
 							Created: created,
 						},
-					}, nil,
-				)
+					},
+					Total: 1,
+				}, nil)
 			},
 			in: func() *http.Request {
 				req, _ := http.NewRequest(http.MethodGet, "/api/img?spot_id=fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052", nil)
@@ -53,6 +56,32 @@ func TestImageHandler_ListImages(t *testing.T) {
 			},
 			wantStatus: http.StatusOK,
 		},
+		{
+			name: "Fail: invalid limit",
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "/api/img?spot_id=fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052&limit=0", nil)
+				return req
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: invalid cursor",
+			setup: func(m *mock.MockImageUseCase, m1 *mock.MockAuthUseCase) {
+				m.EXPECT().ListImages(
+					gomock.Any(),
+					&usecase.ListImagesParams{
+						SpotID: "fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052",
+						Limit:  DefaultListLimit,
+						Cursor: "invalid",
+					},
+				).Return(nil, repository.ErrInvalidCursor)
+			},
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "/api/img?spot_id=fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052&cursor=invalid", nil)
+				return req
+			},
+			wantStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range patterns {
@@ -67,7 +96,7 @@ func TestImageHandler_ListImages(t *testing.T) {
 				tt.setup(iuc, auc)
 			}
 
-			handler := NewImageHandler(iuc, auc)
+			handler := NewImageHandler(iuc, auc, &testStorageConfig)
 			recorder := httptest.NewRecorder()
 
 			handler.ListImages(recorder, tt.in())
@@ -158,7 +187,7 @@ func TestImageHandler_CreateImage(t *testing.T) {
 				tt.setup(iuc, auc)
 			}
 
-			handler := NewImageHandler(iuc, auc)
+			handler := NewImageHandler(iuc, auc, &testStorageConfig)
 			recorder := httptest.NewRecorder()
 
 			handler.CreateImage(recorder, tt.in())
@@ -306,7 +335,7 @@ func TestImageHandler_DeleteImage(t *testing.T) {
 				tt.setup(iuc, auc)
 			}
 
-			handler := NewImageHandler(iuc, auc)
+			handler := NewImageHandler(iuc, auc, &testStorageConfig)
 			recorder := httptest.NewRecorder()
 
 			handler.DeleteImage(recorder, tt.in())
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/usecase"
+)
+
+// multipartOverheadBytes はmultipartの境界やspot_idなど、画像以外の部分に許すバイト数です。
+const multipartOverheadBytes = 64 << 10
+
+var errUploadTooLarge = errors.New("upload too large")
+
+// UploadImage はmultipart/form-dataのspot_idとfileを受け取り、画像を保存して登録したImageを返します。
+func (ih *imageHandler) UploadImage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, err := ih.auc.GetUserFromContext(ctx)
+	if err != nil {
+		http.Error(w, "Failed to get UserInfo from context", http.StatusInternalServerError)
+		return
+	}
+
+	defer r.Body.Close()
+	r.Body = http.MaxBytesReader(w, r.Body, ih.sc.MaxUploadSize+multipartOverheadBytes)
+	spotID, data, err := ih.readUploadImageRequest(r)
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.Is(err, errUploadTooLarge), errors.As(err, &maxBytesErr):
+		http.Error(w, "Image is too large", http.StatusRequestEntityTooLarge)
+		return
+	case err != nil:
+		log.Printf("Invalid upload image request: %v", err)
+		http.Error(w, "Invalid image upload request", http.StatusBadRequest)
+		return
+	}
+
+	img, err := ih.iuc.UploadImage(ctx, spotID, data, *user)
+	switch {
+	case errors.Is(err, usecase.ErrUnsupportedImageType):
+		http.Error(w, "Only JPEG and PNG images are supported", http.StatusUnsupportedMediaType)
+		return
+	case errors.Is(err, usecase.ErrImageTooLarge):
+		http.Error(w, "Image is too large", http.StatusRequestEntityTooLarge)
+		return
+	case errors.Is(err, usecase.ErrInvalidImage):
+		http.Error(w, "Invalid image", http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, "Internal server error while uploading image", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(img); err != nil {
+		http.Error(w, "Failed to encode image to JSON", http.StatusInternalServerError)
+		return
+	}
+}
+
+// readUploadImageRequest はファイルを一時ファイルに書き出さないよう、multipartを順に読み込みます。
+func (ih *imageHandler) readUploadImageRequest(r *http.Request) (uuid.UUID, []byte, error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+
+	var (
+		spotID uuid.UUID
+		data   []byte
+	)
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return uuid.Nil, nil, err
+		}
+		switch part.FormName() {
+		case "spot_id":
+			spotID, err = readSpotIDPart(part)
+		case "file":
+			data, err = io.ReadAll(io.LimitReader(part, ih.sc.MaxUploadSize+1))
+			if err == nil && int64(len(data)) > ih.sc.MaxUploadSize {
+				err = errUploadTooLarge
+			}
+		}
+		part.Close()
+		if err != nil {
+			return uuid.Nil, nil, err
+		}
+	}
+
+	if spotID == uuid.Nil || len(data) == 0 {
+		return uuid.Nil, nil, errors.New("missing required fields: spot_id or file")
+	}
+	return spotID, data, nil
+}
+
+func readSpotIDPart(part *multipart.Part) (uuid.UUID, error) {
+	value, err := io.ReadAll(io.LimitReader(part, 64))
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return uuid.Parse(strings.TrimSpace(string(value)))
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/config"
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/usecase"
+	"github.com/tusmasoma/campfinder/docker/back/usecase/mock"
+)
+
+var testStorageConfig = config.StorageConfig{MaxUploadSize: 1 << 10}
+
+func uploadImageRequest(t *testing.T, spotID string, file []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if spotID != "" {
+		_ = mw.WriteField("spot_id", spotID)
+	}
+	if file != nil {
+		fw, err := mw.CreateFormFile("file", "photo.jpg")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = fw.Write(file)
+	}
+	_ = mw.Close()
+	req, _ := http.NewRequest(http.MethodPost, "/api/img/upload", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestImageHandler_UploadImage(t *testing.T) {
+	t.Parallel()
+	user := model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")}
+	spotID := uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052")
+	img := model.Image{
+		ID:         uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+		SpotID:     spotID,
+		UserID:     user.ID,
+		URL:        "http://localhost:8083/uploads/spots/fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052/31894386-3e60-45a8-bc67-f46b72b42554.jpg",
+		StorageKey: "spots/fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052/31894386-3e60-45a8-bc67-f46b72b42554.jpg",
+	}
+
+	patterns := []struct {
+		name       string
+		setup      func(m *mock.MockImageUseCase)
+		spotID     string
+		file       []byte
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockImageUseCase) {
+				m.EXPECT().UploadImage(gomock.Any(), spotID, []byte("image data"), user).Return(&img, nil)
+			},
+			spotID:     spotID.String(),
+			file:       []byte("image data"),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Fail: missing file",
+			spotID:     spotID.String(),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Fail: invalid spot id",
+			spotID:     "not-a-uuid",
+			file:       []byte("image data"),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Fail: too large",
+			spotID:     spotID.String(),
+			file:       make([]byte, testStorageConfig.MaxUploadSize+1),
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "Fail: unsupported type",
+			setup: func(m *mock.MockImageUseCase) {
+				m.EXPECT().UploadImage(gomock.Any(), spotID, []byte("GIF89a"), user).Return(
+					nil, usecase.ErrUnsupportedImageType,
+				)
+			},
+			spotID:     spotID.String(),
+			file:       []byte("GIF89a"),
+			wantStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name: "Fail: broken image",
+			setup: func(m *mock.MockImageUseCase) {
+				m.EXPECT().UploadImage(gomock.Any(), spotID, []byte("image data"), user).Return(nil, usecase.ErrInvalidImage)
+			},
+			spotID:     spotID.String(),
+			file:       []byte("image data"),
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			iuc := mock.NewMockImageUseCase(ctrl)
+			auc := mock.NewMockAuthUseCase(ctrl)
+			auc.EXPECT().GetUserFromContext(gomock.Any()).Return(&user, nil)
+
+			if tt.setup != nil {
+				tt.setup(iuc)
+			}
+
+			handler := NewImageHandler(iuc, auc, &testStorageConfig)
+			recorder := httptest.NewRecorder()
+			handler.UploadImage(recorder, uploadImageRequest(t, tt.spotID, tt.file))
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got model.Image
+			if err := json.NewDecoder(recorder.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if got != img {
+				t.Errorf("UploadImage() = %+v, want %+v", got, img)
+			}
+		})
+	}
+}
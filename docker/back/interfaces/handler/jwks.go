@@ -0,0 +1,37 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/tusmasoma/campfinder/docker/back/internal/auth"
+)
+
+// JWKSCacheMaxAge は公開鍵の一覧をキャッシュさせる秒数です。ローテーション後も古い鍵はアクセストークンの有効期間中は公開し続けます。
+const JWKSCacheMaxAge = 300
+
+type JWKSHandler interface {
+	GetJWKS(w http.ResponseWriter, r *http.Request)
+}
+
+type jwksHandler struct {
+	km *auth.KeyManager
+}
+
+func NewJWKSHandler(km *auth.KeyManager) JWKSHandler {
+	return &jwksHandler{
+		km: km,
+	}
+}
+
+// GetJWKS は他のサービスがアクセストークンを検証できるように、署名に使う公開鍵をJWK Set形式で返します。
+func (jh *jwksHandler) GetJWKS(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(JWKSCacheMaxAge))
+	if err := json.NewEncoder(w).Encode(jh.km.JWKS()); err != nil {
+		http.Error(w, "Failed to encode keys to JSON", http.StatusInternalServerError)
+		return
+	}
+}
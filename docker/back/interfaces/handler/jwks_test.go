@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tusmasoma/campfinder/docker/back/internal/auth"
+)
+
+func TestJWKSHandler_GetJWKS(t *testing.T) {
+	t.Parallel()
+
+	km, err := auth.NewKeyManager(auth.KeyConfig{
+		PrivateKeyPath: "../../../../.certificate/private_key.pem",
+		PublicKeyPath:  "../../../../.certificate/public_key.pem",
+	})
+	if err != nil {
+		t.Fatalf("Failed to load keys: %v", err)
+	}
+
+	handler := NewJWKSHandler(km)
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	handler.GetJWKS(recorder, req)
+
+	if status := recorder.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	var got auth.JWKS
+	if err = json.NewDecoder(recorder.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(got.Keys) != 1 || got.Keys[0].Kid != km.SigningKeyID() || got.Keys[0].Kty != "RSA" || got.Keys[0].N == "" {
+		t.Errorf("GetJWKS() = %+v", got)
+	}
+}
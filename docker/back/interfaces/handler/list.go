@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 200
+)
+
+type listQuery struct {
+	limit   int
+	cursor  string
+	orderBy string
+}
+
+// parseListQuery は limit, cursor, order_by クエリを検証します。
+// order_by は sortable に含まれるカラムのみ許可し、先頭に"-"を付けると降順になります。
+func parseListQuery(query url.Values, sortable ...string) (listQuery, bool) {
+	lq := listQuery{
+		limit:   DefaultListLimit,
+		cursor:  query.Get("cursor"),
+		orderBy: query.Get("order_by"),
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		v, err := strconv.Atoi(limit)
+		if err != nil || v <= 0 || v > MaxListLimit {
+			log.Printf("Invalid limit: %v", limit)
+			return listQuery{}, false
+		}
+		lq.limit = v
+	}
+
+	if lq.orderBy != "" {
+		column := strings.TrimPrefix(lq.orderBy, "-")
+		allowed := false
+		for _, s := range sortable {
+			if s == column {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			log.Printf("Invalid order_by: %v", lq.orderBy)
+			return listQuery{}, false
+		}
+	}
+	return lq, true
+}
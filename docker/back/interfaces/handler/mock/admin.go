@@ -0,0 +1,131 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: admin.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	http "net/http"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAdminHandler is a mock of AdminHandler interface.
+type MockAdminHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminHandlerMockRecorder
+}
+
+// MockAdminHandlerMockRecorder is the mock recorder for MockAdminHandler.
+type MockAdminHandlerMockRecorder struct {
+	mock *MockAdminHandler
+}
+
+// NewMockAdminHandler creates a new mock instance.
+func NewMockAdminHandler(ctrl *gomock.Controller) *MockAdminHandler {
+	mock := &MockAdminHandler{ctrl: ctrl}
+	mock.recorder = &MockAdminHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminHandler) EXPECT() *MockAdminHandlerMockRecorder {
+	return m.recorder
+}
+
+// AssignSpotOwner mocks base method.
+func (m *MockAdminHandler) AssignSpotOwner(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AssignSpotOwner", w, r)
+}
+
+// AssignSpotOwner indicates an expected call of AssignSpotOwner.
+func (mr *MockAdminHandlerMockRecorder) AssignSpotOwner(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignSpotOwner", reflect.TypeOf((*MockAdminHandler)(nil).AssignSpotOwner), w, r)
+}
+
+// ForcePasswordReset mocks base method.
+func (m *MockAdminHandler) ForcePasswordReset(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ForcePasswordReset", w, r)
+}
+
+// ForcePasswordReset indicates an expected call of ForcePasswordReset.
+func (mr *MockAdminHandlerMockRecorder) ForcePasswordReset(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForcePasswordReset", reflect.TypeOf((*MockAdminHandler)(nil).ForcePasswordReset), w, r)
+}
+
+// ListLoginLockouts mocks base method.
+func (m *MockAdminHandler) ListLoginLockouts(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ListLoginLockouts", w, r)
+}
+
+// ListLoginLockouts indicates an expected call of ListLoginLockouts.
+func (mr *MockAdminHandlerMockRecorder) ListLoginLockouts(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLoginLockouts", reflect.TypeOf((*MockAdminHandler)(nil).ListLoginLockouts), w, r)
+}
+
+// ListUsers mocks base method.
+func (m *MockAdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ListUsers", w, r)
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockAdminHandlerMockRecorder) ListUsers(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockAdminHandler)(nil).ListUsers), w, r)
+}
+
+// RemoveSpotOwner mocks base method.
+func (m *MockAdminHandler) RemoveSpotOwner(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RemoveSpotOwner", w, r)
+}
+
+// RemoveSpotOwner indicates an expected call of RemoveSpotOwner.
+func (mr *MockAdminHandlerMockRecorder) RemoveSpotOwner(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveSpotOwner", reflect.TypeOf((*MockAdminHandler)(nil).RemoveSpotOwner), w, r)
+}
+
+// SuspendUser mocks base method.
+func (m *MockAdminHandler) SuspendUser(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SuspendUser", w, r)
+}
+
+// SuspendUser indicates an expected call of SuspendUser.
+func (mr *MockAdminHandlerMockRecorder) SuspendUser(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SuspendUser", reflect.TypeOf((*MockAdminHandler)(nil).SuspendUser), w, r)
+}
+
+// UnsuspendUser mocks base method.
+func (m *MockAdminHandler) UnsuspendUser(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UnsuspendUser", w, r)
+}
+
+// UnsuspendUser indicates an expected call of UnsuspendUser.
+func (mr *MockAdminHandlerMockRecorder) UnsuspendUser(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnsuspendUser", reflect.TypeOf((*MockAdminHandler)(nil).UnsuspendUser), w, r)
+}
+
+// UpdateUserRole mocks base method.
+func (m *MockAdminHandler) UpdateUserRole(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateUserRole", w, r)
+}
+
+// UpdateUserRole indicates an expected call of UpdateUserRole.
+func (mr *MockAdminHandlerMockRecorder) UpdateUserRole(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserRole", reflect.TypeOf((*MockAdminHandler)(nil).UpdateUserRole), w, r)
+}
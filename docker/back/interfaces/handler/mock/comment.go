@@ -82,6 +82,18 @@ func (mr *MockCommentHandlerMockRecorder) ListComments(w, r interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListComments", reflect.TypeOf((*MockCommentHandler)(nil).ListComments), w, r)
 }
 
+// ListReplies mocks base method.
+func (m *MockCommentHandler) ListReplies(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ListReplies", w, r)
+}
+
+// ListReplies indicates an expected call of ListReplies.
+func (mr *MockCommentHandlerMockRecorder) ListReplies(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReplies", reflect.TypeOf((*MockCommentHandler)(nil).ListReplies), w, r)
+}
+
 // UpdateComment mocks base method.
 func (m *MockCommentHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -34,6 +34,30 @@ func (m *MockImageHandler) EXPECT() *MockImageHandlerMockRecorder {
 	return m.recorder
 }
 
+// ApproveImage mocks base method.
+func (m *MockImageHandler) ApproveImage(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ApproveImage", w, r)
+}
+
+// ApproveImage indicates an expected call of ApproveImage.
+func (mr *MockImageHandlerMockRecorder) ApproveImage(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveImage", reflect.TypeOf((*MockImageHandler)(nil).ApproveImage), w, r)
+}
+
+// CompleteUpload mocks base method.
+func (m *MockImageHandler) CompleteUpload(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CompleteUpload", w, r)
+}
+
+// CompleteUpload indicates an expected call of CompleteUpload.
+func (mr *MockImageHandlerMockRecorder) CompleteUpload(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteUpload", reflect.TypeOf((*MockImageHandler)(nil).CompleteUpload), w, r)
+}
+
 // CreateImage mocks base method.
 func (m *MockImageHandler) CreateImage(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -46,6 +70,18 @@ func (mr *MockImageHandlerMockRecorder) CreateImage(w, r interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateImage", reflect.TypeOf((*MockImageHandler)(nil).CreateImage), w, r)
 }
 
+// CreateUploadURL mocks base method.
+func (m *MockImageHandler) CreateUploadURL(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CreateUploadURL", w, r)
+}
+
+// CreateUploadURL indicates an expected call of CreateUploadURL.
+func (mr *MockImageHandlerMockRecorder) CreateUploadURL(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUploadURL", reflect.TypeOf((*MockImageHandler)(nil).CreateUploadURL), w, r)
+}
+
 // DeleteImage mocks base method.
 func (m *MockImageHandler) DeleteImage(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -69,3 +105,51 @@ func (mr *MockImageHandlerMockRecorder) ListImages(w, r interface{}) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListImages", reflect.TypeOf((*MockImageHandler)(nil).ListImages), w, r)
 }
+
+// ListModerationQueue mocks base method.
+func (m *MockImageHandler) ListModerationQueue(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ListModerationQueue", w, r)
+}
+
+// ListModerationQueue indicates an expected call of ListModerationQueue.
+func (mr *MockImageHandlerMockRecorder) ListModerationQueue(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListModerationQueue", reflect.TypeOf((*MockImageHandler)(nil).ListModerationQueue), w, r)
+}
+
+// RejectImage mocks base method.
+func (m *MockImageHandler) RejectImage(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RejectImage", w, r)
+}
+
+// RejectImage indicates an expected call of RejectImage.
+func (mr *MockImageHandlerMockRecorder) RejectImage(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectImage", reflect.TypeOf((*MockImageHandler)(nil).RejectImage), w, r)
+}
+
+// ReportImage mocks base method.
+func (m *MockImageHandler) ReportImage(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReportImage", w, r)
+}
+
+// ReportImage indicates an expected call of ReportImage.
+func (mr *MockImageHandlerMockRecorder) ReportImage(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportImage", reflect.TypeOf((*MockImageHandler)(nil).ReportImage), w, r)
+}
+
+// UploadImage mocks base method.
+func (m *MockImageHandler) UploadImage(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UploadImage", w, r)
+}
+
+// UploadImage indicates an expected call of UploadImage.
+func (mr *MockImageHandlerMockRecorder) UploadImage(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadImage", reflect.TypeOf((*MockImageHandler)(nil).UploadImage), w, r)
+}
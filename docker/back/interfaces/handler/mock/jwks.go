@@ -0,0 +1,47 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: jwks.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	http "net/http"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockJWKSHandler is a mock of JWKSHandler interface.
+type MockJWKSHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockJWKSHandlerMockRecorder
+}
+
+// MockJWKSHandlerMockRecorder is the mock recorder for MockJWKSHandler.
+type MockJWKSHandlerMockRecorder struct {
+	mock *MockJWKSHandler
+}
+
+// NewMockJWKSHandler creates a new mock instance.
+func NewMockJWKSHandler(ctrl *gomock.Controller) *MockJWKSHandler {
+	mock := &MockJWKSHandler{ctrl: ctrl}
+	mock.recorder = &MockJWKSHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJWKSHandler) EXPECT() *MockJWKSHandlerMockRecorder {
+	return m.recorder
+}
+
+// GetJWKS mocks base method.
+func (m *MockJWKSHandler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetJWKS", w, r)
+}
+
+// GetJWKS indicates an expected call of GetJWKS.
+func (mr *MockJWKSHandlerMockRecorder) GetJWKS(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJWKS", reflect.TypeOf((*MockJWKSHandler)(nil).GetJWKS), w, r)
+}
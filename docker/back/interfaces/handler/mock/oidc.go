@@ -0,0 +1,59 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: oidc.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	http "net/http"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockOIDCHandler is a mock of OIDCHandler interface.
+type MockOIDCHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockOIDCHandlerMockRecorder
+}
+
+// MockOIDCHandlerMockRecorder is the mock recorder for MockOIDCHandler.
+type MockOIDCHandlerMockRecorder struct {
+	mock *MockOIDCHandler
+}
+
+// NewMockOIDCHandler creates a new mock instance.
+func NewMockOIDCHandler(ctrl *gomock.Controller) *MockOIDCHandler {
+	mock := &MockOIDCHandler{ctrl: ctrl}
+	mock.recorder = &MockOIDCHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOIDCHandler) EXPECT() *MockOIDCHandlerMockRecorder {
+	return m.recorder
+}
+
+// Callback mocks base method.
+func (m *MockOIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Callback", w, r)
+}
+
+// Callback indicates an expected call of Callback.
+func (mr *MockOIDCHandlerMockRecorder) Callback(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Callback", reflect.TypeOf((*MockOIDCHandler)(nil).Callback), w, r)
+}
+
+// Login mocks base method.
+func (m *MockOIDCHandler) Login(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Login", w, r)
+}
+
+// Login indicates an expected call of Login.
+func (mr *MockOIDCHandlerMockRecorder) Login(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockOIDCHandler)(nil).Login), w, r)
+}
@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: profile.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	http "net/http"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockProfileHandler is a mock of ProfileHandler interface.
+type MockProfileHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockProfileHandlerMockRecorder
+}
+
+// MockProfileHandlerMockRecorder is the mock recorder for MockProfileHandler.
+type MockProfileHandlerMockRecorder struct {
+	mock *MockProfileHandler
+}
+
+// NewMockProfileHandler creates a new mock instance.
+func NewMockProfileHandler(ctrl *gomock.Controller) *MockProfileHandler {
+	mock := &MockProfileHandler{ctrl: ctrl}
+	mock.recorder = &MockProfileHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProfileHandler) EXPECT() *MockProfileHandlerMockRecorder {
+	return m.recorder
+}
+
+// ChangePassword mocks base method.
+func (m *MockProfileHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ChangePassword", w, r)
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockProfileHandlerMockRecorder) ChangePassword(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockProfileHandler)(nil).ChangePassword), w, r)
+}
+
+// DeleteMe mocks base method.
+func (m *MockProfileHandler) DeleteMe(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DeleteMe", w, r)
+}
+
+// DeleteMe indicates an expected call of DeleteMe.
+func (mr *MockProfileHandlerMockRecorder) DeleteMe(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMe", reflect.TypeOf((*MockProfileHandler)(nil).DeleteMe), w, r)
+}
+
+// GetMe mocks base method.
+func (m *MockProfileHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetMe", w, r)
+}
+
+// GetMe indicates an expected call of GetMe.
+func (mr *MockProfileHandlerMockRecorder) GetMe(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMe", reflect.TypeOf((*MockProfileHandler)(nil).GetMe), w, r)
+}
+
+// GetPublicProfile mocks base method.
+func (m *MockProfileHandler) GetPublicProfile(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetPublicProfile", w, r)
+}
+
+// GetPublicProfile indicates an expected call of GetPublicProfile.
+func (mr *MockProfileHandlerMockRecorder) GetPublicProfile(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublicProfile", reflect.TypeOf((*MockProfileHandler)(nil).GetPublicProfile), w, r)
+}
+
+// UpdateMe mocks base method.
+func (m *MockProfileHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateMe", w, r)
+}
+
+// UpdateMe indicates an expected call of UpdateMe.
+func (mr *MockProfileHandlerMockRecorder) UpdateMe(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMe", reflect.TypeOf((*MockProfileHandler)(nil).UpdateMe), w, r)
+}
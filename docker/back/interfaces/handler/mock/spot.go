@@ -58,6 +58,30 @@ func (mr *MockSpotHandlerMockRecorder) CreateSpot(w, r interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSpot", reflect.TypeOf((*MockSpotHandler)(nil).CreateSpot), w, r)
 }
 
+// DeleteSpot mocks base method.
+func (m *MockSpotHandler) DeleteSpot(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DeleteSpot", w, r)
+}
+
+// DeleteSpot indicates an expected call of DeleteSpot.
+func (mr *MockSpotHandlerMockRecorder) DeleteSpot(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSpot", reflect.TypeOf((*MockSpotHandler)(nil).DeleteSpot), w, r)
+}
+
+// ExportSpots mocks base method.
+func (m *MockSpotHandler) ExportSpots(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ExportSpots", w, r)
+}
+
+// ExportSpots indicates an expected call of ExportSpots.
+func (mr *MockSpotHandlerMockRecorder) ExportSpots(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportSpots", reflect.TypeOf((*MockSpotHandler)(nil).ExportSpots), w, r)
+}
+
 // GetSpot mocks base method.
 func (m *MockSpotHandler) GetSpot(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -70,6 +94,42 @@ func (mr *MockSpotHandlerMockRecorder) GetSpot(w, r interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSpot", reflect.TypeOf((*MockSpotHandler)(nil).GetSpot), w, r)
 }
 
+// GetSpotTile mocks base method.
+func (m *MockSpotHandler) GetSpotTile(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetSpotTile", w, r)
+}
+
+// GetSpotTile indicates an expected call of GetSpotTile.
+func (mr *MockSpotHandlerMockRecorder) GetSpotTile(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSpotTile", reflect.TypeOf((*MockSpotHandler)(nil).GetSpotTile), w, r)
+}
+
+// ImportSpots mocks base method.
+func (m *MockSpotHandler) ImportSpots(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ImportSpots", w, r)
+}
+
+// ImportSpots indicates an expected call of ImportSpots.
+func (mr *MockSpotHandlerMockRecorder) ImportSpots(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportSpots", reflect.TypeOf((*MockSpotHandler)(nil).ImportSpots), w, r)
+}
+
+// ListNearbySpots mocks base method.
+func (m *MockSpotHandler) ListNearbySpots(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ListNearbySpots", w, r)
+}
+
+// ListNearbySpots indicates an expected call of ListNearbySpots.
+func (mr *MockSpotHandlerMockRecorder) ListNearbySpots(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNearbySpots", reflect.TypeOf((*MockSpotHandler)(nil).ListNearbySpots), w, r)
+}
+
 // ListSpots mocks base method.
 func (m *MockSpotHandler) ListSpots(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -81,3 +141,39 @@ func (mr *MockSpotHandlerMockRecorder) ListSpots(w, r interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSpots", reflect.TypeOf((*MockSpotHandler)(nil).ListSpots), w, r)
 }
+
+// PatchSpot mocks base method.
+func (m *MockSpotHandler) PatchSpot(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PatchSpot", w, r)
+}
+
+// PatchSpot indicates an expected call of PatchSpot.
+func (mr *MockSpotHandlerMockRecorder) PatchSpot(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchSpot", reflect.TypeOf((*MockSpotHandler)(nil).PatchSpot), w, r)
+}
+
+// SearchSpots mocks base method.
+func (m *MockSpotHandler) SearchSpots(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SearchSpots", w, r)
+}
+
+// SearchSpots indicates an expected call of SearchSpots.
+func (mr *MockSpotHandlerMockRecorder) SearchSpots(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchSpots", reflect.TypeOf((*MockSpotHandler)(nil).SearchSpots), w, r)
+}
+
+// UpdateSpot mocks base method.
+func (m *MockSpotHandler) UpdateSpot(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateSpot", w, r)
+}
+
+// UpdateSpot indicates an expected call of UpdateSpot.
+func (mr *MockSpotHandlerMockRecorder) UpdateSpot(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSpot", reflect.TypeOf((*MockSpotHandler)(nil).UpdateSpot), w, r)
+}
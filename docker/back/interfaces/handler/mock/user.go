@@ -46,6 +46,54 @@ func (mr *MockUserHandlerMockRecorder) CreateUser(w, r interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserHandler)(nil).CreateUser), w, r)
 }
 
+// DisableTwoFactor mocks base method.
+func (m *MockUserHandler) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DisableTwoFactor", w, r)
+}
+
+// DisableTwoFactor indicates an expected call of DisableTwoFactor.
+func (mr *MockUserHandlerMockRecorder) DisableTwoFactor(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTwoFactor", reflect.TypeOf((*MockUserHandler)(nil).DisableTwoFactor), w, r)
+}
+
+// EnableTwoFactor mocks base method.
+func (m *MockUserHandler) EnableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "EnableTwoFactor", w, r)
+}
+
+// EnableTwoFactor indicates an expected call of EnableTwoFactor.
+func (mr *MockUserHandlerMockRecorder) EnableTwoFactor(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTwoFactor", reflect.TypeOf((*MockUserHandler)(nil).EnableTwoFactor), w, r)
+}
+
+// ForgotPassword mocks base method.
+func (m *MockUserHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ForgotPassword", w, r)
+}
+
+// ForgotPassword indicates an expected call of ForgotPassword.
+func (mr *MockUserHandlerMockRecorder) ForgotPassword(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForgotPassword", reflect.TypeOf((*MockUserHandler)(nil).ForgotPassword), w, r)
+}
+
+// ListSessions mocks base method.
+func (m *MockUserHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ListSessions", w, r)
+}
+
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MockUserHandlerMockRecorder) ListSessions(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MockUserHandler)(nil).ListSessions), w, r)
+}
+
 // Login mocks base method.
 func (m *MockUserHandler) Login(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -69,3 +117,111 @@ func (mr *MockUserHandlerMockRecorder) Logout(w, r interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockUserHandler)(nil).Logout), w, r)
 }
+
+// RefreshToken mocks base method.
+func (m *MockUserHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RefreshToken", w, r)
+}
+
+// RefreshToken indicates an expected call of RefreshToken.
+func (mr *MockUserHandlerMockRecorder) RefreshToken(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MockUserHandler)(nil).RefreshToken), w, r)
+}
+
+// RegenerateRecoveryCodes mocks base method.
+func (m *MockUserHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RegenerateRecoveryCodes", w, r)
+}
+
+// RegenerateRecoveryCodes indicates an expected call of RegenerateRecoveryCodes.
+func (mr *MockUserHandlerMockRecorder) RegenerateRecoveryCodes(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegenerateRecoveryCodes", reflect.TypeOf((*MockUserHandler)(nil).RegenerateRecoveryCodes), w, r)
+}
+
+// ResendVerificationEmail mocks base method.
+func (m *MockUserHandler) ResendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ResendVerificationEmail", w, r)
+}
+
+// ResendVerificationEmail indicates an expected call of ResendVerificationEmail.
+func (mr *MockUserHandlerMockRecorder) ResendVerificationEmail(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResendVerificationEmail", reflect.TypeOf((*MockUserHandler)(nil).ResendVerificationEmail), w, r)
+}
+
+// ResetPassword mocks base method.
+func (m *MockUserHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ResetPassword", w, r)
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockUserHandlerMockRecorder) ResetPassword(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockUserHandler)(nil).ResetPassword), w, r)
+}
+
+// RevokeAllSessions mocks base method.
+func (m *MockUserHandler) RevokeAllSessions(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RevokeAllSessions", w, r)
+}
+
+// RevokeAllSessions indicates an expected call of RevokeAllSessions.
+func (mr *MockUserHandlerMockRecorder) RevokeAllSessions(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllSessions", reflect.TypeOf((*MockUserHandler)(nil).RevokeAllSessions), w, r)
+}
+
+// RevokeSession mocks base method.
+func (m *MockUserHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RevokeSession", w, r)
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockUserHandlerMockRecorder) RevokeSession(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockUserHandler)(nil).RevokeSession), w, r)
+}
+
+// SetupTwoFactor mocks base method.
+func (m *MockUserHandler) SetupTwoFactor(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetupTwoFactor", w, r)
+}
+
+// SetupTwoFactor indicates an expected call of SetupTwoFactor.
+func (mr *MockUserHandlerMockRecorder) SetupTwoFactor(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetupTwoFactor", reflect.TypeOf((*MockUserHandler)(nil).SetupTwoFactor), w, r)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockUserHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "VerifyEmail", w, r)
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockUserHandlerMockRecorder) VerifyEmail(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockUserHandler)(nil).VerifyEmail), w, r)
+}
+
+// VerifyTwoFactorLogin mocks base method.
+func (m *MockUserHandler) VerifyTwoFactorLogin(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "VerifyTwoFactorLogin", w, r)
+}
+
+// VerifyTwoFactorLogin indicates an expected call of VerifyTwoFactorLogin.
+func (mr *MockUserHandlerMockRecorder) VerifyTwoFactorLogin(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyTwoFactorLogin", reflect.TypeOf((*MockUserHandler)(nil).VerifyTwoFactorLogin), w, r)
+}
@@ -0,0 +1,90 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/tusmasoma/campfinder/docker/back/internal/oidc"
+	"github.com/tusmasoma/campfinder/docker/back/usecase"
+)
+
+type OIDCHandler interface {
+	Login(w http.ResponseWriter, r *http.Request)
+	Callback(w http.ResponseWriter, r *http.Request)
+}
+
+type oidcHandler struct {
+	ouc usecase.OIDCUseCase
+}
+
+func NewOIDCHandler(ouc usecase.OIDCUseCase) OIDCHandler {
+	return &oidcHandler{
+		ouc: ouc,
+	}
+}
+
+// Login はIDプロバイダの認可エンドポイントにリダイレクトします。
+func (oh *oidcHandler) Login(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	authURL, err := oh.ouc.StartLogin(ctx, chi.URLParam(r, "provider"))
+	if errors.Is(err, oidc.ErrUnknownProvider) {
+		http.Error(w, "Unknown provider", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to start login", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// Callback はIDプロバイダからのリダイレクトを受け取り、ログインしたユーザのトークンを返します。
+func (oh *oidcHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+	// ユーザが認可を拒否した場合などは、IDプロバイダからerrorが返される
+	if idpErr := query.Get("error"); idpErr != "" {
+		log.Printf("Authorization failed at identity provider: %v", idpErr)
+		http.Error(w, "Authorization failed", http.StatusBadRequest)
+		return
+	}
+	state, code := query.Get("state"), query.Get("code")
+	if state == "" || code == "" {
+		http.Error(w, "Invalid callback request", http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := oh.ouc.CompleteLogin(ctx, chi.URLParam(r, "provider"), state, code, requestDevice(r, ""))
+	var challenge *usecase.TwoFactorRequiredError
+	switch {
+	case errors.As(err, &challenge):
+		writeTwoFactorChallenge(w, challenge)
+		return
+	case errors.Is(err, oidc.ErrUnknownProvider):
+		http.Error(w, "Unknown provider", http.StatusNotFound)
+		return
+	case errors.Is(err, usecase.ErrInvalidOIDCState), errors.Is(err, usecase.ErrOIDCEmailRequired):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, oidc.ErrInvalidIDToken), errors.Is(err, oidc.ErrTokenExchange):
+		http.Error(w, "Failed to verify identity", http.StatusUnauthorized)
+		return
+	case errors.Is(err, usecase.ErrOIDCAccountConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, usecase.ErrAccountSuspended):
+		http.Error(w, "Account is suspended", http.StatusForbidden)
+		return
+	case err != nil:
+		http.Error(w, "Failed to Login or generate token", http.StatusInternalServerError)
+		return
+	}
+
+	writeTokenResponse(w, tokens)
+}
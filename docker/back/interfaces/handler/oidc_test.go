@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+
+	"github.com/tusmasoma/campfinder/docker/back/internal/oidc"
+	"github.com/tusmasoma/campfinder/docker/back/usecase"
+	"github.com/tusmasoma/campfinder/docker/back/usecase/mock"
+)
+
+func TestOIDCHandler_Login(t *testing.T) {
+	patterns := []struct {
+		name         string
+		provider     string
+		setup        func(m *mock.MockOIDCUseCase)
+		wantStatus   int
+		wantLocation string
+	}{
+		{
+			name:     "success",
+			provider: "google",
+			setup: func(m *mock.MockOIDCUseCase) {
+				m.EXPECT().StartLogin(gomock.Any(), "google").Return("https://idp.example/authorize?state=abc", nil)
+			},
+			wantStatus:   http.StatusFound,
+			wantLocation: "https://idp.example/authorize?state=abc",
+		},
+		{
+			name:     "Fail: unknown provider",
+			provider: "github",
+			setup: func(m *mock.MockOIDCUseCase) {
+				m.EXPECT().StartLogin(gomock.Any(), "github").Return("", oidc.ErrUnknownProvider)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			ouc := mock.NewMockOIDCUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ouc)
+			}
+
+			handler := NewOIDCHandler(ouc)
+			r := chi.NewRouter()
+			r.Get("/api/user/oidc/{provider}/login", handler.Login)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/user/oidc/"+tt.provider+"/login", nil)
+			r.ServeHTTP(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if location := recorder.Header().Get("Location"); location != tt.wantLocation {
+				t.Errorf("handler returned wrong location: got %v want %v", location, tt.wantLocation)
+			}
+		})
+	}
+}
+
+func TestOIDCHandler_Callback(t *testing.T) {
+	patterns := []struct {
+		name       string
+		query      string
+		setup      func(m *mock.MockOIDCUseCase)
+		wantStatus int
+	}{
+		{
+			name:  "success",
+			query: "?state=abc&code=xyz",
+			setup: func(m *mock.MockOIDCUseCase) {
+				m.EXPECT().CompleteLogin(gomock.Any(), "google", "abc", "xyz", gomock.Any()).Return(
+					&usecase.TokenPair{AccessToken: "access", RefreshToken: "refresh", ExpiresIn: 900}, nil,
+				)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Fail: denied at identity provider",
+			query:      "?state=abc&error=access_denied",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Fail: missing code",
+			query:      "?state=abc",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "Fail: invalid state",
+			query: "?state=abc&code=xyz",
+			setup: func(m *mock.MockOIDCUseCase) {
+				m.EXPECT().CompleteLogin(gomock.Any(), "google", "abc", "xyz", gomock.Any()).Return(
+					nil, usecase.ErrInvalidOIDCState,
+				)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "Fail: invalid id token",
+			query: "?state=abc&code=xyz",
+			setup: func(m *mock.MockOIDCUseCase) {
+				m.EXPECT().CompleteLogin(gomock.Any(), "google", "abc", "xyz", gomock.Any()).Return(
+					nil, oidc.ErrInvalidIDToken,
+				)
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:  "Fail: account conflict",
+			query: "?state=abc&code=xyz",
+			setup: func(m *mock.MockOIDCUseCase) {
+				m.EXPECT().CompleteLogin(gomock.Any(), "google", "abc", "xyz", gomock.Any()).Return(
+					nil, usecase.ErrOIDCAccountConflict,
+				)
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:  "Fail: internal error",
+			query: "?state=abc&code=xyz",
+			setup: func(m *mock.MockOIDCUseCase) {
+				m.EXPECT().CompleteLogin(gomock.Any(), "google", "abc", "xyz", gomock.Any()).Return(
+					nil, errors.New("connection refused"),
+				)
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			ouc := mock.NewMockOIDCUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ouc)
+			}
+
+			handler := NewOIDCHandler(ouc)
+			r := chi.NewRouter()
+			r.Get("/api/user/oidc/{provider}/callback", handler.Callback)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/user/oidc/google/callback"+tt.query, nil)
+			r.ServeHTTP(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && recorder.Header().Get("Authorization") != "Bearer access" {
+				t.Errorf("handler returned wrong authorization header: %v", recorder.Header().Get("Authorization"))
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/tusmasoma/campfinder/docker/back/config"
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+	"github.com/tusmasoma/campfinder/docker/back/usecase"
+)
+
+const (
+	// MaxDisplayNameLength は表示名の最大文字数で、Userテーブルのnameの長さに合わせています。
+	MaxDisplayNameLength = usecase.MaxDisplayNameLength
+	// MaxAvatarURLLength はアバター画像のURLの最大バイト数で、Userテーブルのavatar_urlの長さに合わせています。
+	MaxAvatarURLLength = 255
+)
+
+type ProfileHandler interface {
+	GetMe(w http.ResponseWriter, r *http.Request)
+	UpdateMe(w http.ResponseWriter, r *http.Request)
+	ChangePassword(w http.ResponseWriter, r *http.Request)
+	DeleteMe(w http.ResponseWriter, r *http.Request)
+	GetPublicProfile(w http.ResponseWriter, r *http.Request)
+}
+
+type profileHandler struct {
+	puc usecase.ProfileUseCase
+	auc usecase.AuthUseCase
+}
+
+func NewProfileHandler(puc usecase.ProfileUseCase, auc usecase.AuthUseCase) ProfileHandler {
+	return &profileHandler{
+		puc: puc,
+		auc: auc,
+	}
+}
+
+type UserResponse struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Email         string `json:"email"`
+	AvatarURL     string `json:"avatarURL"`
+	Role          string `json:"role"`
+	EmailVerified bool   `json:"emailVerified"`
+	// TwoFactorEnabled は2段階認証を設定済みかどうかで、クライアントが設定画面の表示を切り替えるために使います。
+	TwoFactorEnabled bool `json:"twoFactorEnabled"`
+}
+
+type UpdateProfileRequest struct {
+	Name      string `json:"name"`
+	AvatarURL string `json:"avatarURL"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+type PublicProfileResponse struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	AvatarURL   string          `json:"avatarURL"`
+	ReviewCount int             `json:"reviewCount"`
+	Reviews     []model.Comment `json:"reviews"`
+	NextCursor  string          `json:"next_cursor"`
+}
+
+func (ph *profileHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, err := ph.auc.GetUserFromContext(ctx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get UserInfo from context: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeUserResponse(w, user)
+}
+
+func (ph *profileHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, err := ph.auc.GetUserFromContext(ctx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get UserInfo from context: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	var requestBody UpdateProfileRequest
+	if ok := isValidUpdateProfileRequest(r.Body, &requestBody); !ok {
+		http.Error(w, "Invalid update profile request", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	updated, err := ph.puc.UpdateProfile(ctx, user.ID.String(), &usecase.UpdateProfileParams{
+		Name:      requestBody.Name,
+		AvatarURL: requestBody.AvatarURL,
+	})
+	if err != nil {
+		http.Error(w, "Failed to update profile", http.StatusInternalServerError)
+		return
+	}
+	writeUserResponse(w, updated)
+}
+
+// isValidUpdateProfileRequest は表示名の前後の空白を取り除いてから検証します。アバター画像のURLは空にすると削除できます。
+func isValidUpdateProfileRequest(body io.ReadCloser, requestBody *UpdateProfileRequest) bool {
+	if err := json.NewDecoder(body).Decode(requestBody); err != nil {
+		log.Printf("Invalid request body: %v", err)
+		return false
+	}
+	requestBody.Name = strings.TrimSpace(requestBody.Name)
+	if requestBody.Name == "" || utf8.RuneCountInString(requestBody.Name) > MaxDisplayNameLength {
+		log.Printf("Invalid name: %v", requestBody.Name)
+		return false
+	}
+	if requestBody.AvatarURL != "" && !isValidAvatarURL(requestBody.AvatarURL) {
+		log.Printf("Invalid avatar url: %v", requestBody.AvatarURL)
+		return false
+	}
+	return true
+}
+
+func isValidAvatarURL(avatarURL string) bool {
+	if len(avatarURL) > MaxAvatarURLLength {
+		return false
+	}
+	u, err := url.Parse(avatarURL)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func (ph *profileHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, err := ph.auc.GetUserFromContext(ctx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get UserInfo from context: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	var requestBody ChangePasswordRequest
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(&requestBody); err != nil ||
+		requestBody.CurrentPassword == "" || requestBody.NewPassword == "" {
+		http.Error(w, "Invalid change password request", http.StatusBadRequest)
+		return
+	}
+
+	sessionID, _ := ctx.Value(config.ContextSessionIDKey).(string)
+	err = ph.puc.ChangePassword(ctx, user.ID.String(), sessionID, requestBody.CurrentPassword, requestBody.NewPassword)
+	if errors.Is(err, usecase.ErrIncorrectPassword) {
+		http.Error(w, "Current password is incorrect", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to change password", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (ph *profileHandler) DeleteMe(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, err := ph.auc.GetUserFromContext(ctx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get UserInfo from context: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	var requestBody DeleteAccountRequest
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+		http.Error(w, "Invalid delete account request", http.StatusBadRequest)
+		return
+	}
+
+	err = ph.puc.DeleteAccount(ctx, user.ID.String(), requestBody.Password)
+	if errors.Is(err, usecase.ErrIncorrectPassword) {
+		http.Error(w, "Password is incorrect", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to delete account", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetPublicProfile はユーザの表示名・アバター画像と投稿した口コミを返します。メールアドレスなどは含めません。
+func (ph *profileHandler) GetPublicProfile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	lq, ok := parseListQuery(r.URL.Query(), "created", "star_rate")
+	if !ok {
+		http.Error(w, "Invalid public profile request", http.StatusBadRequest)
+		return
+	}
+
+	profile, err := ph.puc.GetPublicProfile(ctx, chi.URLParam(r, "userID"), &usecase.ListReviewsParams{
+		Limit:   lq.limit,
+		Cursor:  lq.cursor,
+		OrderBy: lq.orderBy,
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		http.Error(w, "Invalid cursor", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to get public profile", http.StatusInternalServerError)
+		return
+	}
+
+	reviews := profile.Reviews
+	if reviews == nil {
+		reviews = []model.Comment{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(PublicProfileResponse{
+		ID:          profile.User.ID.String(),
+		Name:        profile.User.Name,
+		AvatarURL:   profile.User.AvatarURL,
+		ReviewCount: profile.ReviewCount,
+		Reviews:     reviews,
+		NextCursor:  profile.NextCursor,
+	}); err != nil {
+		http.Error(w, "Failed to encode public profile to JSON", http.StatusInternalServerError)
+		return
+	}
+}
+
+func writeUserResponse(w http.ResponseWriter, user *model.User) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(UserResponse{
+		ID:               user.ID.String(),
+		Name:             user.Name,
+		Email:            user.Email,
+		AvatarURL:        user.AvatarURL,
+		Role:             string(user.EffectiveRole()),
+		EmailVerified:    user.EmailVerified,
+		TwoFactorEnabled: user.TOTPEnabled,
+	}); err != nil {
+		http.Error(w, "Failed to encode user to JSON", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,304 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/config"
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+	"github.com/tusmasoma/campfinder/docker/back/usecase"
+	"github.com/tusmasoma/campfinder/docker/back/usecase/mock"
+)
+
+func TestProfileHandler_GetMe(t *testing.T) {
+	user := model.User{
+		ID:            uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+		Name:          "test",
+		Email:         "test@gmail.com",
+		Password:      "hashed",
+		EmailVerified: true,
+	}
+
+	ctrl := gomock.NewController(t)
+	auc := mock.NewMockAuthUseCase(ctrl)
+	auc.EXPECT().GetUserFromContext(gomock.Any()).Return(&user, nil)
+
+	handler := NewProfileHandler(mock.NewMockProfileUseCase(ctrl), auc)
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/user/me", nil)
+	handler.GetMe(recorder, req)
+
+	if status := recorder.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	var got UserResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	want := UserResponse{ID: user.ID.String(), Name: "test", Email: "test@gmail.com", Role: "user", EmailVerified: true}
+	if got != want {
+		t.Errorf("GetMe() = %+v, want %+v", got, want)
+	}
+}
+
+func TestProfileHandler_UpdateMe(t *testing.T) {
+	user := model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"), Email: "test@gmail.com"}
+
+	patterns := []struct {
+		name       string
+		setup      func(m *mock.MockProfileUseCase)
+		body       string
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockProfileUseCase) {
+				m.EXPECT().UpdateProfile(gomock.Any(), user.ID.String(), &usecase.UpdateProfileParams{
+					Name:      "Camper",
+					AvatarURL: "https://example.com/avatar.png",
+				}).Return(&model.User{ID: user.ID, Name: "Camper", AvatarURL: "https://example.com/avatar.png"}, nil)
+			},
+			body:       `{"name": " Camper ", "avatarURL": "https://example.com/avatar.png"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Fail: empty name",
+			body:       `{"name": "  "}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Fail: name too long",
+			body:       `{"name": "` + strings.Repeat("あ", MaxDisplayNameLength+1) + `"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Fail: avatar url is not http",
+			body:       `{"name": "Camper", "avatarURL": "javascript:alert(1)"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			puc := mock.NewMockProfileUseCase(ctrl)
+			auc := mock.NewMockAuthUseCase(ctrl)
+			auc.EXPECT().GetUserFromContext(gomock.Any()).Return(&user, nil)
+
+			if tt.setup != nil {
+				tt.setup(puc)
+			}
+
+			handler := NewProfileHandler(puc, auc)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPut, "/api/user/me", bytes.NewBufferString(tt.body))
+			handler.UpdateMe(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestProfileHandler_ChangePassword(t *testing.T) {
+	user := model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")}
+	sessionID := "2b5a4b1e-3f6c-4a8e-9d6b-7e8f9a0b1c2d"
+
+	patterns := []struct {
+		name       string
+		setup      func(m *mock.MockProfileUseCase)
+		body       string
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockProfileUseCase) {
+				m.EXPECT().ChangePassword(gomock.Any(), user.ID.String(), sessionID, "password123", "newpassword").Return(nil)
+			},
+			body:       `{"currentPassword": "password123", "newPassword": "newpassword"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Fail: incorrect current password",
+			setup: func(m *mock.MockProfileUseCase) {
+				m.EXPECT().ChangePassword(gomock.Any(), user.ID.String(), sessionID, "wrong", "newpassword").Return(
+					usecase.ErrIncorrectPassword,
+				)
+			},
+			body:       `{"currentPassword": "wrong", "newPassword": "newpassword"}`,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Fail: missing new password",
+			body:       `{"currentPassword": "password123"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			puc := mock.NewMockProfileUseCase(ctrl)
+			auc := mock.NewMockAuthUseCase(ctrl)
+			auc.EXPECT().GetUserFromContext(gomock.Any()).Return(&user, nil)
+
+			if tt.setup != nil {
+				tt.setup(puc)
+			}
+
+			handler := NewProfileHandler(puc, auc)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPut, "/api/user/me/password", bytes.NewBufferString(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), config.ContextSessionIDKey, sessionID))
+			handler.ChangePassword(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestProfileHandler_DeleteMe(t *testing.T) {
+	user := model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")}
+
+	patterns := []struct {
+		name       string
+		setup      func(m *mock.MockProfileUseCase)
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockProfileUseCase) {
+				m.EXPECT().DeleteAccount(gomock.Any(), user.ID.String(), "password123").Return(nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Fail: incorrect password",
+			setup: func(m *mock.MockProfileUseCase) {
+				m.EXPECT().DeleteAccount(gomock.Any(), user.ID.String(), "password123").Return(usecase.ErrIncorrectPassword)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			puc := mock.NewMockProfileUseCase(ctrl)
+			auc := mock.NewMockAuthUseCase(ctrl)
+			auc.EXPECT().GetUserFromContext(gomock.Any()).Return(&user, nil)
+
+			if tt.setup != nil {
+				tt.setup(puc)
+			}
+
+			handler := NewProfileHandler(puc, auc)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodDelete, "/api/user/me", bytes.NewBufferString(`{"password": "password123"}`))
+			handler.DeleteMe(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestProfileHandler_GetPublicProfile(t *testing.T) {
+	userID := uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")
+
+	patterns := []struct {
+		name       string
+		setup      func(m *mock.MockProfileUseCase)
+		query      string
+		wantStatus int
+		wantBody   *PublicProfileResponse
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockProfileUseCase) {
+				m.EXPECT().GetPublicProfile(gomock.Any(), userID.String(), &usecase.ListReviewsParams{Limit: 10}).Return(
+					&usecase.PublicProfile{
+						User:        model.User{ID: userID, Name: "test", Email: "test@gmail.com"},
+						ReviewCount: 0,
+					}, nil,
+				)
+			},
+			query:      "?limit=10",
+			wantStatus: http.StatusOK,
+			wantBody:   &PublicProfileResponse{ID: userID.String(), Name: "test", Reviews: []model.Comment{}},
+		},
+		{
+			name: "Fail: user not found",
+			setup: func(m *mock.MockProfileUseCase) {
+				m.EXPECT().GetPublicProfile(gomock.Any(), userID.String(), gomock.Any()).Return(nil, repository.ErrNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "Fail: internal error",
+			setup: func(m *mock.MockProfileUseCase) {
+				m.EXPECT().GetPublicProfile(gomock.Any(), userID.String(), gomock.Any()).Return(
+					nil, errors.New("connection refused"),
+				)
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "Fail: invalid order_by",
+			query:      "?order_by=email",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			puc := mock.NewMockProfileUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(puc)
+			}
+
+			handler := NewProfileHandler(puc, mock.NewMockAuthUseCase(ctrl))
+			r := chi.NewRouter()
+			r.Get("/api/user/{userID}/profile", handler.GetPublicProfile)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/user/"+userID.String()+"/profile"+tt.query, nil)
+			r.ServeHTTP(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if tt.wantBody != nil {
+				body := recorder.Body.String()
+				var got PublicProfileResponse
+				if err := json.NewDecoder(strings.NewReader(body)).Decode(&got); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if !reflect.DeepEqual(&got, tt.wantBody) {
+					t.Errorf("GetPublicProfile() = %+v, want %+v", got, tt.wantBody)
+				}
+				// メールアドレスは公開しない
+				if strings.Contains(body, "test@gmail.com") {
+					t.Errorf("GetPublicProfile() must not expose email")
+				}
+			}
+		})
+	}
+}
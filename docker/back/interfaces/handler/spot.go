@@ -3,30 +3,60 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+	"github.com/tusmasoma/campfinder/docker/back/internal/geo"
+	"github.com/tusmasoma/campfinder/docker/back/internal/spotexport"
+	"github.com/tusmasoma/campfinder/docker/back/internal/spotimport"
 	"github.com/tusmasoma/campfinder/docker/back/usecase"
 )
 
+const (
+	MaxNearbyRadiusKm      = 200.0
+	MaxNearbyBBoxSpanKm    = 2 * MaxNearbyRadiusKm
+	MaxSearchKeywordLength = 100
+	SortByRating           = "rating"
+	MaxImportBodyBytes     = 10 << 20
+
+	bboxParts = 4
+)
+
 type SpotHandler interface {
 	CreateSpot(w http.ResponseWriter, r *http.Request)
 	BatchCreateSpots(w http.ResponseWriter, r *http.Request)
 	ListSpots(w http.ResponseWriter, r *http.Request)
 	GetSpot(w http.ResponseWriter, r *http.Request)
+	ListNearbySpots(w http.ResponseWriter, r *http.Request)
+	SearchSpots(w http.ResponseWriter, r *http.Request)
+	UpdateSpot(w http.ResponseWriter, r *http.Request)
+	PatchSpot(w http.ResponseWriter, r *http.Request)
+	DeleteSpot(w http.ResponseWriter, r *http.Request)
+	ImportSpots(w http.ResponseWriter, r *http.Request)
+	ExportSpots(w http.ResponseWriter, r *http.Request)
+	GetSpotTile(w http.ResponseWriter, r *http.Request)
 }
 
 type spotHandler struct {
 	suc usecase.SpotUseCase
+	auc usecase.AuthUseCase
 }
 
-func NewSpotHandler(suc usecase.SpotUseCase) SpotHandler {
+func NewSpotHandler(suc usecase.SpotUseCase, auc usecase.AuthUseCase) SpotHandler {
 	return &spotHandler{
 		suc: suc,
+		auc: auc,
 	}
 }
 
@@ -43,18 +73,59 @@ type CreateSpotRequest struct {
 	IconPath    string  `json:"iconpath"`
 }
 
+type UpdateSpotRequest struct {
+	Category    string  `json:"category"`
+	Name        string  `json:"name"`
+	Address     string  `json:"address"`
+	Lat         float64 `json:"lat"`
+	Lng         float64 `json:"lng"`
+	Period      string  `json:"period"`
+	Phone       string  `json:"phone"`
+	Price       string  `json:"price"`
+	Description string  `json:"description"`
+	IconPath    string  `json:"iconpath"`
+}
+
+// PatchSpotRequest は省略したフィールドを更新しません。
+type PatchSpotRequest struct {
+	Category    *string  `json:"category"`
+	Name        *string  `json:"name"`
+	Address     *string  `json:"address"`
+	Lat         *float64 `json:"lat"`
+	Lng         *float64 `json:"lng"`
+	Period      *string  `json:"period"`
+	Phone       *string  `json:"phone"`
+	Price       *string  `json:"price"`
+	Description *string  `json:"description"`
+	IconPath    *string  `json:"iconpath"`
+}
+
 type BatchCreateSpotsRequest struct {
 	Spots []CreateSpotRequest `json:"spots"`
 }
 
 type ListSpotsResponse struct {
-	Spots []model.Spot `json:"spots"`
+	Spots      []model.Spot `json:"spots"`
+	NextCursor string       `json:"next_cursor"`
+	Total      int          `json:"total"`
+}
+
+type GetSpotTileResponse struct {
+	Clusters model.SpotClusters `json:"clusters"`
 }
 
 type GetSpotResponse struct {
 	Spot model.Spot `json:"spot"`
 }
 
+type ListNearbySpotsResponse struct {
+	Spots []model.SpotWithDistance `json:"spots"`
+}
+
+type SearchSpotsResponse struct {
+	Spots []model.SpotWithScore `json:"spots"`
+}
+
 func (sh *spotHandler) CreateSpot(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	var requestBody CreateSpotRequest
@@ -157,18 +228,78 @@ func convertBatchCreateSpotsRequestToParams(req BatchCreateSpotsRequest) usecase
 
 func (sh *spotHandler) ListSpots(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	categories := r.URL.Query()["category"]
+	query := r.URL.Query()
+
+	params, ok := isValidateListSpotsRequest(query)
+	if !ok {
+		http.Error(w, "Invalid list spots request", http.StatusBadRequest)
+		return
+	}
 
-	allSpots := sh.suc.ListSpots(ctx, categories)
+	result, err := sh.suc.ListSpots(ctx, params)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		http.Error(w, "Invalid cursor", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Internal server error while listing spots", http.StatusInternalServerError)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(ListSpotsResponse{Spots: allSpots}); err != nil {
+	if err = json.NewEncoder(w).Encode(ListSpotsResponse{
+		Spots:      result.Spots,
+		NextCursor: result.NextCursor,
+		Total:      result.Total,
+	}); err != nil {
 		http.Error(w, "Failed to encode spots to JSON", http.StatusInternalServerError)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 }
 
+// isValidateListSpotsRequest は category, min_rating と一覧取得のクエリを検証します。
+// sort=rating は評価の高い順で、order_by=-rating_average と同じです。
+func isValidateListSpotsRequest(query url.Values) (*usecase.ListSpotsParams, bool) {
+	lq, ok := parseListQuery(query, "name", "category", "lat", "lng", "rating_average")
+	if !ok {
+		return nil, false
+	}
+
+	if sort := query.Get("sort"); sort != "" {
+		if sort != SortByRating || lq.orderBy != "" {
+			log.Printf("Invalid sort: %v", sort)
+			return nil, false
+		}
+		lq.orderBy = "-rating_average"
+	}
+
+	params := &usecase.ListSpotsParams{
+		Categories: query["category"],
+		Limit:      lq.limit,
+		Cursor:     lq.cursor,
+		OrderBy:    lq.orderBy,
+	}
+	if params.MinRating, ok = parseMinRating(query); !ok {
+		return nil, false
+	}
+	return params, true
+}
+
+// parseMinRating は任意の min_rating を検証します。指定がない場合は0を返します。
+func parseMinRating(query url.Values) (float64, bool) {
+	minRating := query.Get("min_rating")
+	if minRating == "" {
+		return 0, true
+	}
+	v, err := strconv.ParseFloat(minRating, 64)
+	if err != nil || v < model.MinStarRate || v > model.MaxStarRate {
+		log.Printf("Invalid min_rating: %v", minRating)
+		return 0, false
+	}
+	return v, true
+}
+
 func (sh *spotHandler) GetSpot(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	spotID := chi.URLParam(r, "spotID")
@@ -182,3 +313,523 @@ func (sh *spotHandler) GetSpot(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+func (sh *spotHandler) ListNearbySpots(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	params, ok := isValidateListNearbySpotsRequest(r.URL.Query())
+	if !ok {
+		http.Error(w, "Invalid nearby spots request", http.StatusBadRequest)
+		return
+	}
+
+	spots, err := sh.suc.ListNearbySpots(ctx, params)
+	if err != nil {
+		http.Error(w, "Internal server error while listing nearby spots", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(ListNearbySpotsResponse{Spots: spots}); err != nil {
+		http.Error(w, "Failed to encode spots to JSON", http.StatusInternalServerError)
+		return
+	}
+}
+
+// isValidateListNearbySpotsRequest は ?bbox=minLng,minLat,maxLng,maxLat または ?lat=&lng=&radius_km= を検証します。
+func isValidateListNearbySpotsRequest(query url.Values) (*usecase.ListNearbySpotsParams, bool) {
+	if bbox := query.Get("bbox"); bbox != "" {
+		bounds, ok := parseBoundingBox(bbox)
+		if !ok {
+			log.Printf("Invalid bbox: %v", bbox)
+			return nil, false
+		}
+		// 半径の上限の円を囲む矩形より広い範囲は一度に返さない
+		if widthKm, heightKm := geo.SpanKm(bounds); widthKm > MaxNearbyBBoxSpanKm || heightKm > MaxNearbyBBoxSpanKm {
+			log.Printf("bbox is too large: %v", bbox)
+			return nil, false
+		}
+		return &usecase.ListNearbySpotsParams{Bounds: &bounds}, true
+	}
+
+	lat, latErr := strconv.ParseFloat(query.Get("lat"), 64)
+	lng, lngErr := strconv.ParseFloat(query.Get("lng"), 64)
+	radiusKm, radiusErr := strconv.ParseFloat(query.Get("radius_km"), 64)
+	if latErr != nil || lngErr != nil || radiusErr != nil {
+		log.Printf("Missing required fields: lat, lng or radius_km")
+		return nil, false
+	}
+	if !geo.IsValidCoordinate(lat, lng) || radiusKm <= 0 || radiusKm > MaxNearbyRadiusKm {
+		log.Printf("Out of range: lat=%v, lng=%v, radius_km=%v", lat, lng, radiusKm)
+		return nil, false
+	}
+	return &usecase.ListNearbySpotsParams{Lat: lat, Lng: lng, RadiusKm: radiusKm}, true
+}
+
+func (sh *spotHandler) SearchSpots(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	params, ok := isValidateSearchSpotsRequest(r.URL.Query())
+	if !ok {
+		http.Error(w, "Invalid search spots request", http.StatusBadRequest)
+		return
+	}
+
+	spots, err := sh.suc.SearchSpots(ctx, params)
+	if err != nil {
+		http.Error(w, "Internal server error while searching spots", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(SearchSpotsResponse{Spots: spots}); err != nil {
+		http.Error(w, "Failed to encode spots to JSON", http.StatusInternalServerError)
+		return
+	}
+}
+
+// isValidateSearchSpotsRequest は ?q= に加え、任意の category, limit と
+// ListNearbySpotsと同じ形式の位置での絞り込み(bbox または lat, lng, radius_km)を検証します。
+func isValidateSearchSpotsRequest(query url.Values) (*usecase.SearchSpotsParams, bool) {
+	keyword := strings.TrimSpace(query.Get("q"))
+	if keyword == "" || utf8.RuneCountInString(keyword) > MaxSearchKeywordLength {
+		log.Printf("Invalid keyword: %v", keyword)
+		return nil, false
+	}
+
+	// 関連度順のためカーソルと並び替えは受け付けない
+	lq, ok := parseListQuery(query)
+	if !ok || lq.cursor != "" {
+		return nil, false
+	}
+
+	params := &usecase.SearchSpotsParams{
+		Keyword:    keyword,
+		Categories: query["category"],
+		Limit:      lq.limit,
+	}
+	if query.Has("bbox") || query.Has("lat") || query.Has("lng") || query.Has("radius_km") {
+		geoParams, geoOK := isValidateListNearbySpotsRequest(query)
+		if !geoOK {
+			return nil, false
+		}
+		params.Lat = geoParams.Lat
+		params.Lng = geoParams.Lng
+		params.RadiusKm = geoParams.RadiusKm
+		params.Bounds = geoParams.Bounds
+	}
+	return params, true
+}
+
+func (sh *spotHandler) UpdateSpot(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, err := sh.auc.GetUserFromContext(ctx)
+	if err != nil {
+		http.Error(w, "Failed to get UserInfo from context", http.StatusInternalServerError)
+		return
+	}
+
+	var requestBody UpdateSpotRequest
+	defer r.Body.Close()
+	if ok := isValidateUpdateSpotRequest(r.Body, &requestBody); !ok {
+		http.Error(w, "Invalid spot update request", http.StatusBadRequest)
+		return
+	}
+
+	params := &usecase.UpdateSpotParams{
+		ID:          chi.URLParam(r, "spotID"),
+		Category:    requestBody.Category,
+		Name:        requestBody.Name,
+		Address:     requestBody.Address,
+		Lat:         requestBody.Lat,
+		Lng:         requestBody.Lng,
+		Period:      requestBody.Period,
+		Phone:       requestBody.Phone,
+		Price:       requestBody.Price,
+		Description: requestBody.Description,
+		IconPath:    requestBody.IconPath,
+	}
+	if err = sh.suc.UpdateSpot(ctx, params, *user); err != nil {
+		writeSpotWriteError(w, err, "Internal server error while updating spot")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func isValidateUpdateSpotRequest(body io.ReadCloser, requestBody *UpdateSpotRequest) bool {
+	if err := json.NewDecoder(body).Decode(requestBody); err != nil {
+		log.Printf("Invalid request body: %v", err)
+		return false
+	}
+	if requestBody.Category == "" ||
+		requestBody.Name == "" ||
+		requestBody.Address == "" ||
+		requestBody.Lat == 0 ||
+		requestBody.Lng == 0 {
+		log.Printf("Missing required fields")
+		return false
+	}
+	if !geo.IsValidCoordinate(requestBody.Lat, requestBody.Lng) {
+		log.Printf("Out of range: lat=%v, lng=%v", requestBody.Lat, requestBody.Lng)
+		return false
+	}
+	return true
+}
+
+func (sh *spotHandler) PatchSpot(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, err := sh.auc.GetUserFromContext(ctx)
+	if err != nil {
+		http.Error(w, "Failed to get UserInfo from context", http.StatusInternalServerError)
+		return
+	}
+
+	var requestBody PatchSpotRequest
+	defer r.Body.Close()
+	if ok := isValidatePatchSpotRequest(r.Body, &requestBody); !ok {
+		http.Error(w, "Invalid spot patch request", http.StatusBadRequest)
+		return
+	}
+
+	params := &usecase.PatchSpotParams{
+		ID:          chi.URLParam(r, "spotID"),
+		Category:    requestBody.Category,
+		Name:        requestBody.Name,
+		Address:     requestBody.Address,
+		Lat:         requestBody.Lat,
+		Lng:         requestBody.Lng,
+		Period:      requestBody.Period,
+		Phone:       requestBody.Phone,
+		Price:       requestBody.Price,
+		Description: requestBody.Description,
+		IconPath:    requestBody.IconPath,
+	}
+	if err = sh.suc.PatchSpot(ctx, params, *user); err != nil {
+		writeSpotWriteError(w, err, "Internal server error while patching spot")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// isValidatePatchSpotRequest は指定されたフィールドのみを検証します。必須フィールドを空にすることはできません。
+func isValidatePatchSpotRequest(body io.ReadCloser, requestBody *PatchSpotRequest) bool {
+	if err := json.NewDecoder(body).Decode(requestBody); err != nil {
+		log.Printf("Invalid request body: %v", err)
+		return false
+	}
+	if (requestBody.Category != nil && *requestBody.Category == "") ||
+		(requestBody.Name != nil && *requestBody.Name == "") ||
+		(requestBody.Address != nil && *requestBody.Address == "") {
+		log.Printf("Required fields must not be empty")
+		return false
+	}
+	if requestBody.Lat != nil && (*requestBody.Lat == 0 || *requestBody.Lat < geo.MinLat || *requestBody.Lat > geo.MaxLat) {
+		log.Printf("Invalid lat: %v", *requestBody.Lat)
+		return false
+	}
+	if requestBody.Lng != nil && (*requestBody.Lng == 0 || *requestBody.Lng < geo.MinLng || *requestBody.Lng > geo.MaxLng) {
+		log.Printf("Invalid lng: %v", *requestBody.Lng)
+		return false
+	}
+	if *requestBody == (PatchSpotRequest{}) {
+		log.Printf("No fields to patch")
+		return false
+	}
+	return true
+}
+
+func (sh *spotHandler) DeleteSpot(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, err := sh.auc.GetUserFromContext(ctx)
+	if err != nil {
+		http.Error(w, "Failed to get UserInfo from context", http.StatusInternalServerError)
+		return
+	}
+
+	if err = sh.suc.DeleteSpot(ctx, chi.URLParam(r, "spotID"), *user); err != nil {
+		writeSpotWriteError(w, err, "Internal server error while deleting spot")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// ImportSpots は ?format=csv|geojson のファイルを本文で受け取り、行ごとの取り込み結果を返します。
+// formatを省略した場合はContent-Typeから判定します。列名が異なる場合は ?mapping=name=施設名,address=住所 で指定します。
+func (sh *spotHandler) ImportSpots(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	params, ok := isValidateImportSpotsRequest(r)
+	if !ok {
+		http.Error(w, "Invalid import spots request", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+	params.Data = http.MaxBytesReader(w, r.Body, MaxImportBodyBytes)
+
+	report, err := sh.suc.ImportSpots(ctx, params)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesErr):
+			http.Error(w, "Import file is too large", http.StatusRequestEntityTooLarge)
+		case errors.Is(err, spotimport.ErrInvalidData):
+			http.Error(w, "Invalid import file", http.StatusBadRequest)
+		default:
+			http.Error(w, "Internal server error while importing spots", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(report); err != nil {
+		http.Error(w, "Failed to encode import report to JSON", http.StatusInternalServerError)
+		return
+	}
+}
+
+// ExportSpots は ?format=geojson|gpx|kml でSpotを書き出します。ListSpotsと同じ category, min_rating と、
+// ListNearbySpotsと同じ形式の位置(bbox または lat, lng, radius_km)で絞り込めます。
+func (sh *spotHandler) ExportSpots(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	format, params, ok := isValidateExportSpotsRequest(r.URL.Query())
+	if !ok {
+		http.Error(w, "Invalid export spots request", http.StatusBadRequest)
+		return
+	}
+
+	// 1件目の取得に失敗した場合に500を返せるよう、ヘッダの書き出しは最初のSpotまで遅らせる
+	var encoder spotexport.Encoder
+	begin := func() error {
+		w.Header().Set("Content-Type", spotexport.ContentType(format))
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="spots.%s"`, format))
+		var err error
+		encoder, err = spotexport.NewEncoder(format, w)
+		return err
+	}
+
+	err := sh.suc.ExportSpots(ctx, params, func(spot model.Spot) error {
+		if encoder == nil {
+			if err := begin(); err != nil {
+				return err
+			}
+		}
+		return encoder.Encode(spot)
+	})
+	if err != nil && encoder == nil {
+		http.Error(w, "Internal server error while exporting spots", http.StatusInternalServerError)
+		return
+	}
+	if err != nil {
+		// 書き出しを始めた後はステータスを変更できないため、途中までの内容で終了する
+		log.Printf("Failed to export spots: %v", err)
+		return
+	}
+
+	if encoder == nil {
+		if err = begin(); err != nil {
+			log.Printf("Failed to export spots: %v", err)
+			return
+		}
+	}
+	if err = encoder.Close(); err != nil {
+		log.Printf("Failed to export spots: %v", err)
+	}
+}
+
+// GetSpotTile はタイル内のクラスタを返します。Acceptが application/geo+json の場合はGeoJSONのFeatureCollectionで返します。
+func (sh *spotHandler) GetSpotTile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	z, x, y, ok := isValidateGetSpotTileRequest(r)
+	if !ok {
+		http.Error(w, "Invalid tile request", http.StatusBadRequest)
+		return
+	}
+	contentType, ok := negotiateTileContentType(r.Header.Get("Accept"))
+	if !ok {
+		http.Error(w, "Not acceptable", http.StatusNotAcceptable)
+		return
+	}
+
+	clusters, err := sh.suc.GetSpotTile(ctx, z, x, y)
+	if err != nil {
+		http.Error(w, "Internal server error while getting tile", http.StatusInternalServerError)
+		return
+	}
+
+	var body any = GetSpotTileResponse{Clusters: clusters}
+	if contentType == contentTypeGeoJSON {
+		body = clustersToGeoJSON(clusters)
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Vary", "Accept")
+	if err = json.NewEncoder(w).Encode(body); err != nil {
+		http.Error(w, "Failed to encode tile to JSON", http.StatusInternalServerError)
+		return
+	}
+}
+
+func isValidateGetSpotTileRequest(r *http.Request) (int, int, int, bool) {
+	z, zErr := strconv.Atoi(chi.URLParam(r, "z"))
+	x, xErr := strconv.Atoi(chi.URLParam(r, "x"))
+	y, yErr := strconv.Atoi(chi.URLParam(r, "y"))
+	if zErr != nil || xErr != nil || yErr != nil || !geo.IsValidTile(z, x, y) {
+		log.Printf("Invalid tile: %v/%v/%v", chi.URLParam(r, "z"), chi.URLParam(r, "x"), chi.URLParam(r, "y"))
+		return 0, 0, 0, false
+	}
+	return z, x, y, true
+}
+
+const (
+	contentTypeJSON    = "application/json"
+	contentTypeGeoJSON = "application/geo+json"
+)
+
+// negotiateTileContentType はAcceptに列挙された順に、対応している形式を選びます。品質値(q)は考慮しません。
+func negotiateTileContentType(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return contentTypeJSON, true
+	}
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, _, _ := strings.Cut(mediaRange, ";")
+		switch strings.TrimSpace(mediaType) {
+		case contentTypeGeoJSON:
+			return contentTypeGeoJSON, true
+		case contentTypeJSON, "application/*", "*/*":
+			return contentTypeJSON, true
+		}
+	}
+	return "", false
+}
+
+type clusterFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []clusterFeature `json:"features"`
+}
+
+type clusterFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   clusterGeometry        `json:"geometry"`
+	Properties clusterFeatureProperty `json:"properties"`
+}
+
+type clusterGeometry struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+type clusterFeatureProperty struct {
+	Count    int    `json:"count"`
+	Category string `json:"category"`
+	SpotID   string `json:"spotID,omitempty"`
+}
+
+func clustersToGeoJSON(clusters model.SpotClusters) clusterFeatureCollection {
+	features := make([]clusterFeature, 0, len(clusters))
+	for _, cluster := range clusters {
+		features = append(features, clusterFeature{
+			Type: "Feature",
+			Geometry: clusterGeometry{
+				Type:        "Point",
+				Coordinates: [2]float64{cluster.Lng, cluster.Lat},
+			},
+			Properties: clusterFeatureProperty{
+				Count:    cluster.Count,
+				Category: cluster.Category,
+				SpotID:   cluster.SpotID,
+			},
+		})
+	}
+	return clusterFeatureCollection{Type: "FeatureCollection", Features: features}
+}
+
+func isValidateExportSpotsRequest(query url.Values) (spotexport.Format, *usecase.ExportSpotsParams, bool) {
+	format := spotexport.Format(strings.ToLower(query.Get("format")))
+	if format == "" {
+		format = spotexport.FormatGeoJSON
+	}
+	if !spotexport.IsValidFormat(format) {
+		log.Printf("Invalid export format: %v", format)
+		return "", nil, false
+	}
+
+	params := &usecase.ExportSpotsParams{Categories: query["category"]}
+	var ok bool
+	if params.MinRating, ok = parseMinRating(query); !ok {
+		return "", nil, false
+	}
+	if query.Has("bbox") || query.Has("lat") || query.Has("lng") || query.Has("radius_km") {
+		geoParams, geoOK := isValidateListNearbySpotsRequest(query)
+		if !geoOK {
+			return "", nil, false
+		}
+		params.Lat = geoParams.Lat
+		params.Lng = geoParams.Lng
+		params.RadiusKm = geoParams.RadiusKm
+		params.Bounds = geoParams.Bounds
+	}
+	return format, params, true
+}
+
+func isValidateImportSpotsRequest(r *http.Request) (*usecase.ImportSpotsParams, bool) {
+	query := r.URL.Query()
+
+	format := spotimport.Format(strings.ToLower(query.Get("format")))
+	if format == "" {
+		contentType := r.Header.Get("Content-Type")
+		switch {
+		case strings.HasPrefix(contentType, "text/csv"):
+			format = spotimport.FormatCSV
+		case strings.HasPrefix(contentType, "application/geo+json"), strings.HasPrefix(contentType, "application/json"):
+			format = spotimport.FormatGeoJSON
+		}
+	}
+	if format != spotimport.FormatCSV && format != spotimport.FormatGeoJSON {
+		log.Printf("Invalid import format: %v", format)
+		return nil, false
+	}
+
+	mapping, err := spotimport.ParseColumnMapping(query.Get("mapping"))
+	if err != nil {
+		log.Printf("Invalid mapping: %v", err)
+		return nil, false
+	}
+
+	return &usecase.ImportSpotsParams{
+		Format:  format,
+		Mapping: mapping,
+	}, true
+}
+
+func writeSpotWriteError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, usecase.ErrPermissionDenied):
+		http.Error(w, "Don't have permission to modify spot", http.StatusForbidden)
+	case errors.Is(err, repository.ErrNotFound):
+		http.Error(w, "Spot not found", http.StatusNotFound)
+	default:
+		http.Error(w, message, http.StatusInternalServerError)
+	}
+}
+
+// parseBoundingBox はGeoJSONと同じ minLng,minLat,maxLng,maxLat の順で矩形をパースします。
+func parseBoundingBox(bbox string) (model.BoundingBox, bool) {
+	parts := strings.Split(bbox, ",")
+	if len(parts) != bboxParts {
+		return model.BoundingBox{}, false
+	}
+	values := make([]float64, 0, bboxParts)
+	for _, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return model.BoundingBox{}, false
+		}
+		values = append(values, v)
+	}
+	bounds := model.BoundingBox{MinLng: values[0], MinLat: values[1], MaxLng: values[2], MaxLat: values[3]}
+	return bounds, geo.IsValidBoundingBox(bounds)
+}
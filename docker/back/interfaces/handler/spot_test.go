@@ -2,10 +2,13 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/go-chi/chi/v5"
@@ -13,6 +16,8 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+	"github.com/tusmasoma/campfinder/docker/back/internal/spotimport"
 	"github.com/tusmasoma/campfinder/docker/back/usecase"
 	"github.com/tusmasoma/campfinder/docker/back/usecase/mock"
 )
@@ -135,7 +140,7 @@ func TestSpotHandler_CreateSpot(t *testing.T) {
 				tt.setup(repo)
 			}
 
-			handler := NewSpotHandler(repo)
+			handler := NewSpotHandler(repo, mock.NewMockAuthUseCase(ctrl))
 			recorder := httptest.NewRecorder()
 
 			handler.CreateSpot(recorder, tt.in())
@@ -243,7 +248,7 @@ func TestSpotHandler_BatchCreateSpots(t *testing.T) {
 				tt.setup(repo)
 			}
 
-			handler := NewSpotHandler(repo)
+			handler := NewSpotHandler(repo, mock.NewMockAuthUseCase(ctrl))
 			recorder := httptest.NewRecorder()
 
 			handler.BatchCreateSpots(recorder, tt.in())
@@ -270,8 +275,11 @@ func TestSpotHandler_ListSpots(t *testing.T) {
 			setup: func(
 				m *mock.MockSpotUseCase,
 			) {
-				m.EXPECT().ListSpots(gomock.Any(), []string{"campsite", "spa"}).Return(
-					[]model.Spot{
+				m.EXPECT().ListSpots(
+					gomock.Any(),
+					&usecase.ListSpotsParams{Categories: []string{"campsite", "spa"}, Limit: DefaultListLimit},
+				).Return(&usecase.ListSpotsResult{
+					Spots: []model.Spot{
 						{
 							ID:          uuid.MustParse("5c5323e9-c78f-4dac-94ef-d34ab5ea8fed"),
 							Category:    "campsite",
@@ -312,7 +320,8 @@ func TestSpotHandler_ListSpots(t *testing.T) {
 							IconPath:    "/static/img/spaflag.jpeg",
 						},
 					},
-				)
+					Total: 3,
+				}, nil)
 			},
 
 			in: func() *http.Request {
@@ -325,6 +334,90 @@ func TestSpotHandler_ListSpots(t *testing.T) {
 			},
 			wantStatus: http.StatusOK,
 		},
+		{
+			name: "success: with pagination",
+			setup: func(
+				m *mock.MockSpotUseCase,
+			) {
+				m.EXPECT().ListSpots(
+					gomock.Any(),
+					&usecase.ListSpotsParams{Limit: 10, Cursor: "cursor", OrderBy: "-name"},
+				).Return(&usecase.ListSpotsResult{NextCursor: "next", Total: 30}, nil)
+			},
+			in: func() *http.Request {
+				req, _ := http.NewRequest(
+					http.MethodGet,
+					"/api/spot?limit=10&cursor=cursor&order_by=-name",
+					nil,
+				)
+				return req
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "success: min rating and sort by rating",
+			setup: func(
+				m *mock.MockSpotUseCase,
+			) {
+				m.EXPECT().ListSpots(
+					gomock.Any(),
+					&usecase.ListSpotsParams{MinRating: 4.5, Limit: DefaultListLimit, OrderBy: "-rating_average"},
+				).Return(&usecase.ListSpotsResult{Total: 0}, nil)
+			},
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "/api/spot?min_rating=4.5&sort=rating", nil)
+				return req
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Fail: min rating out of range",
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "/api/spot?min_rating=6", nil)
+				return req
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: unknown sort",
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "/api/spot?sort=popular", nil)
+				return req
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: invalid limit",
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "/api/spot?limit=1000", nil)
+				return req
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: invalid order_by",
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "/api/spot?order_by=password", nil)
+				return req
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: invalid cursor",
+			setup: func(
+				m *mock.MockSpotUseCase,
+			) {
+				m.EXPECT().ListSpots(
+					gomock.Any(),
+					&usecase.ListSpotsParams{Limit: DefaultListLimit, Cursor: "invalid"},
+				).Return(nil, repository.ErrInvalidCursor)
+			},
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "/api/spot?cursor=invalid", nil)
+				return req
+			},
+			wantStatus: http.StatusBadRequest,
+		},
 	}
 	for _, tt := range patterns {
 		t.Run(tt.name, func(t *testing.T) {
@@ -337,7 +430,7 @@ func TestSpotHandler_ListSpots(t *testing.T) {
 				tt.setup(repo)
 			}
 
-			handler := NewSpotHandler(repo)
+			handler := NewSpotHandler(repo, mock.NewMockAuthUseCase(ctrl))
 			recorder := httptest.NewRecorder()
 
 			handler.ListSpots(recorder, tt.in())
@@ -413,7 +506,7 @@ func TestSpotHandler_GetSpot(t *testing.T) {
 				tt.setup(repo)
 			}
 
-			handler := NewSpotHandler(repo)
+			handler := NewSpotHandler(repo, mock.NewMockAuthUseCase(ctrl))
 			recorder := httptest.NewRecorder()
 
 			r := chi.NewRouter()
@@ -428,3 +521,831 @@ func TestSpotHandler_GetSpot(t *testing.T) {
 		})
 	}
 }
+
+func TestSpotHandler_ListNearbySpots(t *testing.T) {
+	t.Parallel()
+	spots := []model.SpotWithDistance{
+		{
+			Spot: model.Spot{
+				ID:       uuid.MustParse("5c5323e9-c78f-4dac-94ef-d34ab5ea8fed"),
+				Category: "campsite",
+				Name:     "旭川市21世紀の森ふれあい広場",
+				Lat:      43.7172721,
+				Lng:      142.6674615,
+			},
+			DistanceKm: 1.2,
+		},
+	}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockSpotUseCase,
+		)
+		query      string
+		wantStatus int
+	}{
+		{
+			name: "success: radius",
+			setup: func(m *mock.MockSpotUseCase) {
+				m.EXPECT().ListNearbySpots(
+					gomock.Any(),
+					&usecase.ListNearbySpotsParams{Lat: 43.72, Lng: 142.68, RadiusKm: 5},
+				).Return(spots, nil)
+			},
+			query:      "lat=43.72&lng=142.68&radius_km=5",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "success: bounding box",
+			setup: func(m *mock.MockSpotUseCase) {
+				m.EXPECT().ListNearbySpots(
+					gomock.Any(),
+					&usecase.ListNearbySpotsParams{
+						Bounds: &model.BoundingBox{MinLat: 43, MinLng: 142, MaxLat: 44, MaxLng: 143},
+					},
+				).Return(spots, nil)
+			},
+			query:      "bbox=142,43,143,44",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Fail: missing radius",
+			query:      "lat=43.72&lng=142.68",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Fail: radius too large",
+			query:      "lat=43.72&lng=142.68&radius_km=1000",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Fail: invalid bbox",
+			query:      "bbox=143,44,142,43",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Fail: bbox too large",
+			query:      "bbox=-180,-90,180,90",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: list nearby spots",
+			setup: func(m *mock.MockSpotUseCase) {
+				m.EXPECT().ListNearbySpots(
+					gomock.Any(),
+					&usecase.ListNearbySpotsParams{Lat: 43.72, Lng: 142.68, RadiusKm: 5},
+				).Return(nil, fmt.Errorf("fail to list nearby spots"))
+			},
+			query:      "lat=43.72&lng=142.68&radius_km=5",
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			repo := mock.NewMockSpotUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(repo)
+			}
+
+			handler := NewSpotHandler(repo, mock.NewMockAuthUseCase(ctrl))
+			recorder := httptest.NewRecorder()
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/spot/nearby?"+tt.query, nil)
+			handler.ListNearbySpots(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSpotHandler_SearchSpots(t *testing.T) {
+	t.Parallel()
+	spots := []model.SpotWithScore{
+		{
+			Spot: model.Spot{
+				ID:       uuid.MustParse("5c5323e9-c78f-4dac-94ef-d34ab5ea8fed"),
+				Category: "campsite",
+				Name:     "旭川市21世紀の森ふれあい広場",
+				Lat:      43.7172721,
+				Lng:      142.6674615,
+			},
+			Score: 2.5,
+		},
+	}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockSpotUseCase,
+		)
+		query      string
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockSpotUseCase) {
+				m.EXPECT().SearchSpots(
+					gomock.Any(),
+					&usecase.SearchSpotsParams{
+						Keyword:    "river campsite",
+						Categories: []string{"campsite"},
+						Limit:      10,
+					},
+				).Return(spots, nil)
+			},
+			query:      "q=river+campsite&category=campsite&limit=10",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "success: radius",
+			setup: func(m *mock.MockSpotUseCase) {
+				m.EXPECT().SearchSpots(
+					gomock.Any(),
+					&usecase.SearchSpotsParams{
+						Keyword:  "キャンプ",
+						Lat:      43.72,
+						Lng:      142.68,
+						RadiusKm: 5,
+						Limit:    DefaultListLimit,
+					},
+				).Return(spots, nil)
+			},
+			query:      "q=%E3%82%AD%E3%83%A3%E3%83%B3%E3%83%97&lat=43.72&lng=142.68&radius_km=5",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "success: bounding box",
+			setup: func(m *mock.MockSpotUseCase) {
+				m.EXPECT().SearchSpots(
+					gomock.Any(),
+					&usecase.SearchSpotsParams{
+						Keyword: "森",
+						Bounds:  &model.BoundingBox{MinLat: 43, MinLng: 142, MaxLat: 44, MaxLng: 143},
+						Limit:   DefaultListLimit,
+					},
+				).Return(spots, nil)
+			},
+			query:      "q=%E6%A3%AE&bbox=142,43,143,44",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Fail: missing keyword",
+			query:      "q=+",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Fail: incomplete geo filter",
+			query:      "q=campsite&lat=43.72",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Fail: cursor is not supported",
+			query:      "q=campsite&cursor=abc",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: search spots",
+			setup: func(m *mock.MockSpotUseCase) {
+				m.EXPECT().SearchSpots(
+					gomock.Any(),
+					&usecase.SearchSpotsParams{Keyword: "campsite", Limit: DefaultListLimit},
+				).Return(nil, fmt.Errorf("fail to search spots"))
+			},
+			query:      "q=campsite",
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			repo := mock.NewMockSpotUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(repo)
+			}
+
+			handler := NewSpotHandler(repo, mock.NewMockAuthUseCase(ctrl))
+			recorder := httptest.NewRecorder()
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/spot/search?"+tt.query, nil)
+			handler.SearchSpots(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSpotHandler_UpdateSpot(t *testing.T) {
+	t.Parallel()
+	admin := model.User{
+		ID:      uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+		Name:    "admin",
+		Email:   "admin@gmail.com",
+		IsAdmin: true,
+	}
+	body := `{"category":"campsite","name":"旭川市21世紀の森ふれあい広場","address":"北海道旭川市東旭川町瑞穂4288",` +
+		`"lat":43.7172721,"lng":142.6674615,"phone":"0166-76-2108"}`
+	params := &usecase.UpdateSpotParams{
+		ID:       "5c5323e9-c78f-4dac-94ef-d34ab5ea8fed",
+		Category: "campsite",
+		Name:     "旭川市21世紀の森ふれあい広場",
+		Address:  "北海道旭川市東旭川町瑞穂4288",
+		Lat:      43.7172721,
+		Lng:      142.6674615,
+		Phone:    "0166-76-2108",
+	}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockSpotUseCase,
+			m1 *mock.MockAuthUseCase,
+		)
+		body       string
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockSpotUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&admin, nil)
+				m.EXPECT().UpdateSpot(gomock.Any(), params, admin).Return(nil)
+			},
+			body:       body,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Fail: missing required fields",
+			setup: func(m *mock.MockSpotUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&admin, nil)
+			},
+			body:       `{"category":"campsite"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: not admin",
+			setup: func(m *mock.MockSpotUseCase, m1 *mock.MockAuthUseCase) {
+				user := model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")}
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&user, nil)
+				m.EXPECT().UpdateSpot(gomock.Any(), params, user).Return(usecase.ErrPermissionDenied)
+			},
+			body:       body,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "Fail: spot not found",
+			setup: func(m *mock.MockSpotUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&admin, nil)
+				m.EXPECT().UpdateSpot(gomock.Any(), params, admin).Return(repository.ErrNotFound)
+			},
+			body:       body,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			suc := mock.NewMockSpotUseCase(ctrl)
+			auc := mock.NewMockAuthUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(suc, auc)
+			}
+
+			handler := NewSpotHandler(suc, auc)
+			recorder := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Put("/api/spot/{spotID}", handler.UpdateSpot)
+
+			req, _ := http.NewRequest(
+				http.MethodPut,
+				"/api/spot/5c5323e9-c78f-4dac-94ef-d34ab5ea8fed",
+				strings.NewReader(tt.body),
+			)
+			r.ServeHTTP(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSpotHandler_PatchSpot(t *testing.T) {
+	t.Parallel()
+	admin := model.User{
+		ID:      uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+		Name:    "admin",
+		Email:   "admin@gmail.com",
+		IsAdmin: true,
+	}
+	phone := "0166-76-2108"
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockSpotUseCase,
+			m1 *mock.MockAuthUseCase,
+		)
+		body       string
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockSpotUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&admin, nil)
+				m.EXPECT().PatchSpot(
+					gomock.Any(),
+					&usecase.PatchSpotParams{ID: "5c5323e9-c78f-4dac-94ef-d34ab5ea8fed", Phone: &phone},
+					admin,
+				).Return(nil)
+			},
+			body:       `{"phone":"0166-76-2108"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Fail: empty patch",
+			setup: func(m *mock.MockSpotUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&admin, nil)
+			},
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: clear required field",
+			setup: func(m *mock.MockSpotUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&admin, nil)
+			},
+			body:       `{"name":""}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: lat out of range",
+			setup: func(m *mock.MockSpotUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&admin, nil)
+			},
+			body:       `{"lat":100}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: not admin",
+			setup: func(m *mock.MockSpotUseCase, m1 *mock.MockAuthUseCase) {
+				user := model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")}
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&user, nil)
+				m.EXPECT().PatchSpot(
+					gomock.Any(),
+					&usecase.PatchSpotParams{ID: "5c5323e9-c78f-4dac-94ef-d34ab5ea8fed", Phone: &phone},
+					user,
+				).Return(usecase.ErrPermissionDenied)
+			},
+			body:       `{"phone":"0166-76-2108"}`,
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			suc := mock.NewMockSpotUseCase(ctrl)
+			auc := mock.NewMockAuthUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(suc, auc)
+			}
+
+			handler := NewSpotHandler(suc, auc)
+			recorder := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Patch("/api/spot/{spotID}", handler.PatchSpot)
+
+			req, _ := http.NewRequest(
+				http.MethodPatch,
+				"/api/spot/5c5323e9-c78f-4dac-94ef-d34ab5ea8fed",
+				strings.NewReader(tt.body),
+			)
+			r.ServeHTTP(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSpotHandler_DeleteSpot(t *testing.T) {
+	t.Parallel()
+	admin := model.User{
+		ID:      uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+		Name:    "admin",
+		Email:   "admin@gmail.com",
+		IsAdmin: true,
+	}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockSpotUseCase,
+			m1 *mock.MockAuthUseCase,
+		)
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockSpotUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&admin, nil)
+				m.EXPECT().DeleteSpot(gomock.Any(), "5c5323e9-c78f-4dac-94ef-d34ab5ea8fed", admin).Return(nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Fail: get user from context",
+			setup: func(m *mock.MockSpotUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(nil, fmt.Errorf("user name not found in request context"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name: "Fail: not admin",
+			setup: func(m *mock.MockSpotUseCase, m1 *mock.MockAuthUseCase) {
+				user := model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")}
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&user, nil)
+				m.EXPECT().DeleteSpot(
+					gomock.Any(),
+					"5c5323e9-c78f-4dac-94ef-d34ab5ea8fed",
+					user,
+				).Return(usecase.ErrPermissionDenied)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "Fail: delete spot",
+			setup: func(m *mock.MockSpotUseCase, m1 *mock.MockAuthUseCase) {
+				m1.EXPECT().GetUserFromContext(gomock.Any()).Return(&admin, nil)
+				m.EXPECT().DeleteSpot(
+					gomock.Any(),
+					"5c5323e9-c78f-4dac-94ef-d34ab5ea8fed",
+					admin,
+				).Return(fmt.Errorf("fail to delete spot"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			suc := mock.NewMockSpotUseCase(ctrl)
+			auc := mock.NewMockAuthUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(suc, auc)
+			}
+
+			handler := NewSpotHandler(suc, auc)
+			recorder := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Delete("/api/spot/{spotID}", handler.DeleteSpot)
+
+			req, _ := http.NewRequest(http.MethodDelete, "/api/spot/5c5323e9-c78f-4dac-94ef-d34ab5ea8fed", nil)
+			r.ServeHTTP(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSpotHandler_ImportSpots(t *testing.T) {
+	t.Parallel()
+	body := "施設名,category,address,lat,lng\n旭川市21世紀の森,campsite,北海道旭川市,43.7172721,142.6674615\n"
+	report := &usecase.ImportSpotsReport{
+		Inserted: 1,
+		Results:  []usecase.ImportSpotResult{{Row: 1, Status: usecase.ImportStatusInserted, Name: "旭川市21世紀の森"}},
+	}
+
+	patterns := []struct {
+		name        string
+		setup       func(m *mock.MockSpotUseCase)
+		query       string
+		contentType string
+		wantStatus  int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockSpotUseCase) {
+				m.EXPECT().ImportSpots(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, params *usecase.ImportSpotsParams) (*usecase.ImportSpotsReport, error) {
+						if params.Format != spotimport.FormatCSV || params.Mapping["name"] != "施設名" {
+							t.Errorf("unexpected params: %+v", params)
+						}
+						return report, nil
+					},
+				)
+			},
+			query:      "?format=csv&mapping=name=施設名",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "success: format from content type",
+			setup: func(m *mock.MockSpotUseCase) {
+				m.EXPECT().ImportSpots(gomock.Any(), gomock.Any()).Return(report, nil)
+			},
+			contentType: "text/csv; charset=utf-8",
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:       "Fail: unsupported format",
+			query:      "?format=kml",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Fail: invalid mapping",
+			query:      "?format=csv&mapping=unknown=列",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: invalid file",
+			setup: func(m *mock.MockSpotUseCase) {
+				m.EXPECT().ImportSpots(gomock.Any(), gomock.Any()).Return(nil, spotimport.ErrInvalidData)
+			},
+			query:      "?format=csv",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: import spots",
+			setup: func(m *mock.MockSpotUseCase) {
+				m.EXPECT().ImportSpots(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("fail to create spot"))
+			},
+			query:      "?format=csv",
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			suc := mock.NewMockSpotUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(suc)
+			}
+
+			handler := NewSpotHandler(suc, mock.NewMockAuthUseCase(ctrl))
+			recorder := httptest.NewRecorder()
+
+			req, _ := http.NewRequest(http.MethodPost, "/api/spot/import"+tt.query, strings.NewReader(body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			handler.ImportSpots(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK {
+				var got usecase.ImportSpotsReport
+				if err := json.NewDecoder(recorder.Body).Decode(&got); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if !reflect.DeepEqual(&got, report) {
+					t.Errorf("handler returned unexpected body: got %+v want %+v", got, report)
+				}
+			}
+		})
+	}
+}
+
+func TestSpotHandler_ExportSpots(t *testing.T) {
+	t.Parallel()
+	spot := model.Spot{
+		ID:       uuid.MustParse("5c5323e9-c78f-4dac-94ef-d34ab5ea8fed"),
+		Category: "campsite",
+		Name:     "旭川市21世紀の森",
+		Lat:      43.7172721,
+		Lng:      142.6674615,
+	}
+
+	patterns := []struct {
+		name            string
+		setup           func(m *mock.MockSpotUseCase)
+		query           string
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name: "success: geojson",
+			setup: func(m *mock.MockSpotUseCase) {
+				m.EXPECT().ExportSpots(
+					gomock.Any(),
+					&usecase.ExportSpotsParams{Categories: []string{"campsite"}, MinRating: 3},
+					gomock.Any(),
+				).DoAndReturn(func(_ context.Context, _ *usecase.ExportSpotsParams, fn func(model.Spot) error) error {
+					return fn(spot)
+				})
+			},
+			query:           "?category=campsite&min_rating=3",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/geo+json",
+			wantBody:        "旭川市21世紀の森",
+		},
+		{
+			name: "success: empty kml in bbox",
+			setup: func(m *mock.MockSpotUseCase) {
+				m.EXPECT().ExportSpots(
+					gomock.Any(),
+					&usecase.ExportSpotsParams{
+						Bounds: &model.BoundingBox{MinLat: 43.0, MinLng: 142.0, MaxLat: 44.0, MaxLng: 143.0},
+					},
+					gomock.Any(),
+				).Return(nil)
+			},
+			query:           "?format=kml&bbox=142.0,43.0,143.0,44.0",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/vnd.google-earth.kml+xml",
+			wantBody:        "</kml>",
+		},
+		{
+			name:       "Fail: unsupported format",
+			query:      "?format=shp",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Fail: invalid min_rating",
+			query:      "?format=gpx&min_rating=6",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Fail: missing radius",
+			query:      "?format=gpx&lat=43.7&lng=142.6",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: export spots",
+			setup: func(m *mock.MockSpotUseCase) {
+				m.EXPECT().ExportSpots(gomock.Any(), gomock.Any(), gomock.Any()).Return(fmt.Errorf("fail to list spots"))
+			},
+			query:      "?format=gpx",
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			suc := mock.NewMockSpotUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(suc)
+			}
+
+			handler := NewSpotHandler(suc, mock.NewMockAuthUseCase(ctrl))
+			recorder := httptest.NewRecorder()
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/spot/export"+tt.query, nil)
+			handler.ExportSpots(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if got := recorder.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("handler returned wrong content type: got %v want %v", got, tt.wantContentType)
+			}
+			if !strings.Contains(recorder.Body.String(), tt.wantBody) {
+				t.Errorf("handler returned unexpected body: got %v want %v", recorder.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestSpotHandler_GetSpotTile(t *testing.T) {
+	t.Parallel()
+	clusters := model.SpotClusters{
+		{Lat: 43.73, Lng: 142.67, Count: 2, Category: "campsite"},
+	}
+
+	patterns := []struct {
+		name            string
+		setup           func(m *mock.MockSpotUseCase)
+		path            string
+		accept          string
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name: "success: json",
+			setup: func(m *mock.MockSpotUseCase) {
+				m.EXPECT().GetSpotTile(gomock.Any(), 9, 458, 186).Return(clusters, nil)
+			},
+			path:            "/api/spot/tiles/9/458/186",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantBody:        `{"clusters":[{"lat":43.73,"lng":142.67,"count":2,"category":"campsite"}]}`,
+		},
+		{
+			name: "success: geojson",
+			setup: func(m *mock.MockSpotUseCase) {
+				m.EXPECT().GetSpotTile(gomock.Any(), 9, 458, 186).Return(clusters, nil)
+			},
+			path:            "/api/spot/tiles/9/458/186",
+			accept:          "application/geo+json, application/json;q=0.9",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/geo+json",
+			wantBody: `{"type":"FeatureCollection","features":[{"type":"Feature",` +
+				`"geometry":{"type":"Point","coordinates":[142.67,43.73]},"properties":{"count":2,"category":"campsite"}}]}`,
+		},
+		{
+			name:       "Fail: not acceptable",
+			path:       "/api/spot/tiles/9/458/186",
+			accept:     "application/x-protobuf",
+			wantStatus: http.StatusNotAcceptable,
+		},
+		{
+			name:       "Fail: tile out of range",
+			path:       "/api/spot/tiles/2/4/0",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Fail: invalid zoom",
+			path:       "/api/spot/tiles/z/0/0",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: get tile",
+			setup: func(m *mock.MockSpotUseCase) {
+				m.EXPECT().GetSpotTile(gomock.Any(), 9, 458, 186).Return(nil, fmt.Errorf("fail to list spots"))
+			},
+			path:       "/api/spot/tiles/9/458/186",
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			suc := mock.NewMockSpotUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(suc)
+			}
+
+			handler := NewSpotHandler(suc, mock.NewMockAuthUseCase(ctrl))
+			recorder := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Get("/api/spot/tiles/{z}/{x}/{y}", handler.GetSpotTile)
+
+			req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			r.ServeHTTP(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if got := recorder.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("handler returned wrong content type: got %v want %v", got, tt.wantContentType)
+			}
+			if got := strings.TrimSpace(recorder.Body.String()); got != tt.wantBody {
+				t.Errorf("handler returned unexpected body: got %v want %v", got, tt.wantBody)
+			}
+		})
+	}
+}
@@ -3,11 +3,20 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/go-chi/chi/v5"
+
+	"github.com/tusmasoma/campfinder/docker/back/config"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
 	"github.com/tusmasoma/campfinder/docker/back/usecase"
 )
 
@@ -15,6 +24,19 @@ type UserHandler interface {
 	CreateUser(w http.ResponseWriter, r *http.Request)
 	Login(w http.ResponseWriter, r *http.Request)
 	Logout(w http.ResponseWriter, r *http.Request)
+	RefreshToken(w http.ResponseWriter, r *http.Request)
+	ListSessions(w http.ResponseWriter, r *http.Request)
+	RevokeSession(w http.ResponseWriter, r *http.Request)
+	RevokeAllSessions(w http.ResponseWriter, r *http.Request)
+	VerifyEmail(w http.ResponseWriter, r *http.Request)
+	ResendVerificationEmail(w http.ResponseWriter, r *http.Request)
+	ForgotPassword(w http.ResponseWriter, r *http.Request)
+	ResetPassword(w http.ResponseWriter, r *http.Request)
+	VerifyTwoFactorLogin(w http.ResponseWriter, r *http.Request)
+	SetupTwoFactor(w http.ResponseWriter, r *http.Request)
+	EnableTwoFactor(w http.ResponseWriter, r *http.Request)
+	DisableTwoFactor(w http.ResponseWriter, r *http.Request)
+	RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request)
 }
 
 type userHandler struct {
@@ -29,13 +51,55 @@ func NewUserHandler(uur usecase.UserUseCase, auc usecase.AuthUseCase) UserHandle
 	}
 }
 
+// CreateUserRequest と LoginRequest のdeviceNameは任意で、セッション一覧で端末を見分けるために使います。
 type CreateUserRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email      string `json:"email"`
+	Password   string `json:"password"`
+	DeviceName string `json:"deviceName"`
 }
 
 type LoginRequest struct {
-	Email    string `json:"email"`
+	Email      string `json:"email"`
+	Password   string `json:"password"`
+	DeviceName string `json:"deviceName"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// TokenResponse のaccessTokenは、従来どおりAuthorizationヘッダにも設定します。
+type TokenResponse struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	TokenType    string `json:"tokenType"`
+	ExpiresIn    int64  `json:"expiresIn"`
+}
+
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	DeviceName string    `json:"deviceName"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"userAgent"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	Current    bool      `json:"current"`
+}
+
+type ListSessionsResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
 	Password string `json:"password"`
 }
 
@@ -49,14 +113,18 @@ func (uh *userHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	}
 	defer r.Body.Close()
 
-	jwt, err := uh.uur.CreateUserAndGenerateToken(ctx, requestBody.Email, requestBody.Password)
+	tokens, err := uh.uur.CreateUserAndGenerateToken(
+		ctx,
+		requestBody.Email,
+		requestBody.Password,
+		requestDevice(r, requestBody.DeviceName),
+	)
 	if err != nil {
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Authorization", "Bearer "+jwt)
-	w.WriteHeader(http.StatusOK)
+	writeTokenResponse(w, tokens)
 }
 
 func isValidCreateUserRequest(body io.ReadCloser, requestBody *CreateUserRequest) bool {
@@ -81,14 +149,38 @@ func (uh *userHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 	defer r.Body.Close()
 
-	jwt, err := uh.uur.LoginAndGenerateToken(ctx, requestBody.Email, requestBody.Password)
+	tokens, err := uh.uur.LoginAndGenerateToken(
+		ctx,
+		requestBody.Email,
+		requestBody.Password,
+		requestDevice(r, requestBody.DeviceName),
+	)
+	var throttled *usecase.LoginThrottledError
+	if errors.As(err, &throttled) {
+		w.Header().Set("Retry-After", retryAfterSeconds(throttled.RetryAfter))
+		http.Error(w, "Too many login attempts", http.StatusTooManyRequests)
+		return
+	}
+	var challenge *usecase.TwoFactorRequiredError
+	if errors.As(err, &challenge) {
+		writeTwoFactorChallenge(w, challenge)
+		return
+	}
+	if errors.Is(err, usecase.ErrAccountSuspended) {
+		http.Error(w, "Account is suspended", http.StatusForbidden)
+		return
+	}
 	if err != nil {
 		http.Error(w, "Failed to Login or generate token", http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Authorization", "Bearer "+jwt)
-	w.WriteHeader(http.StatusOK)
+	writeTokenResponse(w, tokens)
+}
+
+// retryAfterSeconds はRetry-Afterヘッダの秒数で、待ち時間より早く再試行させないよう切り上げます。
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
 }
 
 func isValidLoginRequest(body io.ReadCloser, requestBody *LoginRequest) bool {
@@ -112,10 +204,248 @@ func (uh *userHandler) Logout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = uh.uur.LogoutUser(ctx, user.ID.String()); err != nil {
+	sessionID, ok := ctx.Value(config.ContextSessionIDKey).(string)
+	if !ok {
+		http.Error(w, "Failed to get session from context", http.StatusInternalServerError)
+		return
+	}
+
+	if err = uh.uur.LogoutUser(ctx, user.ID.String(), sessionID); err != nil {
 		http.Error(w, "Failed to logout", http.StatusInternalServerError)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
+
+func (uh *userHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var requestBody RefreshTokenRequest
+	defer r.Body.Close()
+	if ok := isValidRefreshTokenRequest(r.Body, &requestBody); !ok {
+		http.Error(w, "Invalid refresh token request", http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := uh.uur.RefreshToken(ctx, requestBody.RefreshToken)
+	if errors.Is(err, usecase.ErrInvalidRefreshToken) || errors.Is(err, usecase.ErrRefreshTokenReused) {
+		http.Error(w, "Invalid or revoked refresh token", http.StatusUnauthorized)
+		return
+	}
+	if errors.Is(err, usecase.ErrAccountSuspended) {
+		http.Error(w, "Account is suspended", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to refresh token", http.StatusInternalServerError)
+		return
+	}
+
+	writeTokenResponse(w, tokens)
+}
+
+func isValidRefreshTokenRequest(body io.ReadCloser, requestBody *RefreshTokenRequest) bool {
+	if err := json.NewDecoder(body).Decode(requestBody); err != nil {
+		log.Printf("Invalid request body: %v", err)
+		return false
+	}
+	if requestBody.RefreshToken == "" {
+		log.Printf("Missing required fields: refreshToken")
+		return false
+	}
+	return true
+}
+
+func (uh *userHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, err := uh.auc.GetUserFromContext(ctx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get UserInfo from context: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	sessions, err := uh.uur.ListSessions(ctx, user.ID.String())
+	if err != nil {
+		http.Error(w, "Failed to list sessions", http.StatusInternalServerError)
+		return
+	}
+
+	currentID, _ := ctx.Value(config.ContextSessionIDKey).(string)
+	response := ListSessionsResponse{Sessions: make([]SessionResponse, 0, len(sessions))}
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, SessionResponse{
+			ID:         session.ID,
+			DeviceName: session.DeviceName,
+			IP:         session.IP,
+			UserAgent:  session.UserAgent,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			Current:    session.ID == currentID,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Failed to encode sessions to JSON", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (uh *userHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, err := uh.auc.GetUserFromContext(ctx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get UserInfo from context: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	err = uh.uur.RevokeSession(ctx, user.ID.String(), chi.URLParam(r, "sessionID"))
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to revoke session", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (uh *userHandler) RevokeAllSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, err := uh.auc.GetUserFromContext(ctx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get UserInfo from context: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err = uh.uur.RevokeAllSessions(ctx, user.ID.String()); err != nil {
+		http.Error(w, "Failed to revoke sessions", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (uh *userHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var requestBody VerifyEmailRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil || requestBody.Token == "" {
+		http.Error(w, "Invalid verify email request", http.StatusBadRequest)
+		return
+	}
+
+	err := uh.uur.VerifyEmail(ctx, requestBody.Token)
+	if errors.Is(err, usecase.ErrInvalidUserToken) {
+		http.Error(w, "Invalid or expired token", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to verify email", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (uh *userHandler) ResendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, err := uh.auc.GetUserFromContext(ctx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get UserInfo from context: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	err = uh.uur.RequestEmailVerification(ctx, user.ID.String())
+	if errors.Is(err, usecase.ErrEmailAlreadyVerified) {
+		http.Error(w, "Email already verified", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to send verification email", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ForgotPassword は登録済みのメールアドレスかどうかに関わらず202を返します。
+func (uh *userHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var requestBody ForgotPasswordRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil || requestBody.Email == "" {
+		http.Error(w, "Invalid forgot password request", http.StatusBadRequest)
+		return
+	}
+
+	if err := uh.uur.RequestPasswordReset(ctx, requestBody.Email); err != nil {
+		http.Error(w, "Failed to send password reset email", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (uh *userHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var requestBody ResetPasswordRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil ||
+		requestBody.Token == "" || requestBody.Password == "" {
+		http.Error(w, "Invalid reset password request", http.StatusBadRequest)
+		return
+	}
+
+	err := uh.uur.ResetPassword(ctx, requestBody.Token, requestBody.Password)
+	if errors.Is(err, usecase.ErrInvalidUserToken) {
+		http.Error(w, "Invalid or expired token", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to reset password", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// requestDevice はリクエストからセッションに保存する端末情報を取得します。
+func requestDevice(r *http.Request, deviceName string) usecase.Device {
+	return usecase.Device{
+		Name:      deviceName,
+		IP:        clientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+}
+
+// clientIP はClientIPミドルウェアがコンテキストに保存したクライアントのIPアドレスを返します。
+// 保存されていない場合は接続元のアドレスを使い、X-Forwarded-Forは信頼しません。
+func clientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(config.ContextClientIPKey).(string); ok && ip != "" {
+		return ip
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func writeTokenResponse(w http.ResponseWriter, tokens *usecase.TokenPair) {
+	w.Header().Set("Authorization", "Bearer "+tokens.AccessToken)
+	w.Header().Set("Content-Type", "application/json")
+	// トークンを含むレスポンスはキャッシュさせない
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(TokenResponse{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    tokens.ExpiresIn,
+	}); err != nil {
+		http.Error(w, "Failed to encode token to JSON", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/tusmasoma/campfinder/docker/back/usecase"
+)
+
+// TwoFactorChallengeResponse はパスワードは正しいが2段階目の認証が必要な場合のレスポンスで、
+// challengeTokenとコードをVerifyTwoFactorRequestで送るとトークンを発行します。
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"twoFactorRequired"`
+	ChallengeToken    string `json:"challengeToken"`
+	ExpiresIn         int64  `json:"expiresIn"`
+}
+
+// VerifyTwoFactorRequest のcodeは認証アプリの6桁のコードまたはリカバリーコードです。
+type VerifyTwoFactorRequest struct {
+	ChallengeToken string `json:"challengeToken"`
+	Code           string `json:"code"`
+}
+
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningURI"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// writeTwoFactorChallenge はトークンを発行せずにチャレンジトークンを返します。ログインは完了していないため202を返します。
+func writeTwoFactorChallenge(w http.ResponseWriter, challenge *usecase.TwoFactorRequiredError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(TwoFactorChallengeResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    challenge.ChallengeToken,
+		ExpiresIn:         int64(challenge.ExpiresIn.Seconds()),
+	}); err != nil {
+		log.Printf("Failed to encode two-factor challenge to JSON: %v", err)
+	}
+}
+
+func (uh *userHandler) VerifyTwoFactorLogin(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var requestBody VerifyTwoFactorRequest
+	if ok := isValidVerifyTwoFactorRequest(r.Body, &requestBody); !ok {
+		http.Error(w, "Invalid two-factor request", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	tokens, err := uh.uur.VerifyTwoFactorLogin(ctx, requestBody.ChallengeToken, requestBody.Code)
+	var throttled *usecase.LoginThrottledError
+	switch {
+	case errors.As(err, &throttled):
+		w.Header().Set("Retry-After", retryAfterSeconds(throttled.RetryAfter))
+		http.Error(w, "Too many login attempts", http.StatusTooManyRequests)
+		return
+	case errors.Is(err, usecase.ErrInvalidTwoFactorChallenge), errors.Is(err, usecase.ErrInvalidTwoFactorCode):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	case errors.Is(err, usecase.ErrAccountSuspended):
+		http.Error(w, "Account is suspended", http.StatusForbidden)
+		return
+	case err != nil:
+		http.Error(w, "Failed to Login or generate token", http.StatusInternalServerError)
+		return
+	}
+
+	writeTokenResponse(w, tokens)
+}
+
+func isValidVerifyTwoFactorRequest(body io.ReadCloser, requestBody *VerifyTwoFactorRequest) bool {
+	if err := json.NewDecoder(body).Decode(requestBody); err != nil {
+		log.Printf("Invalid request body: %v", err)
+		return false
+	}
+	requestBody.Code = strings.TrimSpace(requestBody.Code)
+	if requestBody.ChallengeToken == "" || requestBody.Code == "" {
+		log.Printf("Missing required fields: challengeToken or code")
+		return false
+	}
+	return true
+}
+
+func (uh *userHandler) SetupTwoFactor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, err := uh.auc.GetUserFromContext(ctx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get UserInfo from context: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	setup, err := uh.uur.SetupTwoFactor(ctx, user.ID.String())
+	if errors.Is(err, usecase.ErrTwoFactorAlreadyEnabled) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to set up two-factor authentication", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err = json.NewEncoder(w).Encode(TwoFactorSetupResponse{
+		Secret:          setup.Secret,
+		ProvisioningURI: setup.ProvisioningURI,
+	}); err != nil {
+		http.Error(w, "Failed to encode two-factor setup to JSON", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (uh *userHandler) EnableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, err := uh.auc.GetUserFromContext(ctx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get UserInfo from context: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	var requestBody TwoFactorCodeRequest
+	if ok := isValidTwoFactorCodeRequest(r.Body, &requestBody); !ok {
+		http.Error(w, "Invalid two-factor request", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	codes, err := uh.uur.EnableTwoFactor(ctx, user.ID.String(), requestBody.Code)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+	writeRecoveryCodesResponse(w, codes)
+}
+
+func (uh *userHandler) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, err := uh.auc.GetUserFromContext(ctx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get UserInfo from context: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	var requestBody DisableTwoFactorRequest
+	if ok := isValidDisableTwoFactorRequest(r.Body, &requestBody); !ok {
+		http.Error(w, "Invalid two-factor request", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if err = uh.uur.DisableTwoFactor(ctx, user.ID.String(), requestBody.Password, requestBody.Code); err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// isValidDisableTwoFactorRequest はパスワードを持たないユーザもいるため、passwordの有無はユースケースで確認します。
+func isValidDisableTwoFactorRequest(body io.ReadCloser, requestBody *DisableTwoFactorRequest) bool {
+	if err := json.NewDecoder(body).Decode(requestBody); err != nil {
+		log.Printf("Invalid request body: %v", err)
+		return false
+	}
+	requestBody.Code = strings.TrimSpace(requestBody.Code)
+	if requestBody.Code == "" {
+		log.Printf("Missing required fields: code")
+		return false
+	}
+	return true
+}
+
+func (uh *userHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, err := uh.auc.GetUserFromContext(ctx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get UserInfo from context: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	var requestBody TwoFactorCodeRequest
+	if ok := isValidTwoFactorCodeRequest(r.Body, &requestBody); !ok {
+		http.Error(w, "Invalid two-factor request", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	codes, err := uh.uur.RegenerateRecoveryCodes(ctx, user.ID.String(), requestBody.Code)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+	writeRecoveryCodesResponse(w, codes)
+}
+
+func isValidTwoFactorCodeRequest(body io.ReadCloser, requestBody *TwoFactorCodeRequest) bool {
+	if err := json.NewDecoder(body).Decode(requestBody); err != nil {
+		log.Printf("Invalid request body: %v", err)
+		return false
+	}
+	requestBody.Code = strings.TrimSpace(requestBody.Code)
+	if requestBody.Code == "" {
+		log.Printf("Missing required fields: code")
+		return false
+	}
+	return true
+}
+
+// writeTwoFactorError は2段階認証の設定の変更で発生したエラーをステータスコードに対応付けます。
+func writeTwoFactorError(w http.ResponseWriter, err error) {
+	var throttled *usecase.LoginThrottledError
+	switch {
+	case errors.As(err, &throttled):
+		w.Header().Set("Retry-After", retryAfterSeconds(throttled.RetryAfter))
+		http.Error(w, "Too many attempts", http.StatusTooManyRequests)
+	case errors.Is(err, usecase.ErrInvalidTwoFactorCode),
+		errors.Is(err, usecase.ErrIncorrectPassword),
+		errors.Is(err, usecase.ErrTwoFactorMandatory):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, usecase.ErrTwoFactorAlreadyEnabled), errors.Is(err, usecase.ErrTwoFactorNotEnabled):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, usecase.ErrTwoFactorSetupNotStarted):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, "Failed to update two-factor authentication", http.StatusInternalServerError)
+	}
+}
+
+// writeRecoveryCodesResponse はリカバリーコードの平文を返します。再表示はできないため、キャッシュさせません。
+func writeRecoveryCodesResponse(w http.ResponseWriter, codes []string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(RecoveryCodesResponse{RecoveryCodes: codes}); err != nil {
+		http.Error(w, "Failed to encode recovery codes to JSON", http.StatusInternalServerError)
+		return
+	}
+}
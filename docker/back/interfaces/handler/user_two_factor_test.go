@@ -0,0 +1,299 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/usecase"
+	"github.com/tusmasoma/campfinder/docker/back/usecase/mock"
+)
+
+func TestUserHandler_VerifyTwoFactorLogin(t *testing.T) {
+	patterns := []struct {
+		name           string
+		setup          func(m *mock.MockUserUseCase)
+		body           VerifyTwoFactorRequest
+		wantStatus     int
+		wantRetryAfter string
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockUserUseCase) {
+				m.EXPECT().VerifyTwoFactorLogin(gomock.Any(), "challenge-token", "123456").Return(
+					&usecase.TokenPair{AccessToken: "access-token", RefreshToken: "refresh-token", ExpiresIn: 900},
+					nil,
+				)
+			},
+			body:       VerifyTwoFactorRequest{ChallengeToken: "challenge-token", Code: " 123456 "},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Fail: missing code",
+			body:       VerifyTwoFactorRequest{ChallengeToken: "challenge-token"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: invalid code",
+			setup: func(m *mock.MockUserUseCase) {
+				m.EXPECT().VerifyTwoFactorLogin(gomock.Any(), "challenge-token", "000000").Return(
+					nil, usecase.ErrInvalidTwoFactorCode,
+				)
+			},
+			body:       VerifyTwoFactorRequest{ChallengeToken: "challenge-token", Code: "000000"},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "Fail: expired challenge",
+			setup: func(m *mock.MockUserUseCase) {
+				m.EXPECT().VerifyTwoFactorLogin(gomock.Any(), "challenge-token", "123456").Return(
+					nil, usecase.ErrInvalidTwoFactorChallenge,
+				)
+			},
+			body:       VerifyTwoFactorRequest{ChallengeToken: "challenge-token", Code: "123456"},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "Fail: too many attempts",
+			setup: func(m *mock.MockUserUseCase) {
+				m.EXPECT().VerifyTwoFactorLogin(gomock.Any(), "challenge-token", "123456").Return(
+					nil, &usecase.LoginThrottledError{RetryAfter: 4 * time.Second},
+				)
+			},
+			body:           VerifyTwoFactorRequest{ChallengeToken: "challenge-token", Code: "123456"},
+			wantStatus:     http.StatusTooManyRequests,
+			wantRetryAfter: "4",
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			uuc := mock.NewMockUserUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(uuc)
+			}
+
+			handler := NewUserHandler(uuc, mock.NewMockAuthUseCase(ctrl))
+			recorder := httptest.NewRecorder()
+			reqBody, _ := json.Marshal(tt.body)
+			req, _ := http.NewRequest(http.MethodPost, "/api/user/login/2fa", bytes.NewBuffer(reqBody))
+			handler.VerifyTwoFactorLogin(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if got := recorder.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("handler returned wrong Retry-After header: got %v want %v", got, tt.wantRetryAfter)
+			}
+			if tt.wantStatus == http.StatusOK && recorder.Header().Get("Authorization") != "Bearer access-token" {
+				t.Errorf("Expected Authorization header to be set")
+			}
+		})
+	}
+}
+
+func TestUserHandler_SetupTwoFactor(t *testing.T) {
+	user := model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")}
+
+	ctrl := gomock.NewController(t)
+	uuc := mock.NewMockUserUseCase(ctrl)
+	auc := mock.NewMockAuthUseCase(ctrl)
+	auc.EXPECT().GetUserFromContext(gomock.Any()).Return(&user, nil)
+	uuc.EXPECT().SetupTwoFactor(gomock.Any(), user.ID.String()).Return(&usecase.TwoFactorSetup{
+		Secret:          "JBSWY3DPEHPK3PXP",
+		ProvisioningURI: "otpauth://totp/CampFinder:test@gmail.com?secret=JBSWY3DPEHPK3PXP",
+	}, nil)
+
+	handler := NewUserHandler(uuc, auc)
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/user/2fa/setup", nil)
+	handler.SetupTwoFactor(recorder, req)
+
+	if status := recorder.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	if cc := recorder.Header().Get("Cache-Control"); cc != "no-store" {
+		t.Errorf("Cache-Control = %v, want no-store", cc)
+	}
+	var got TwoFactorSetupResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	want := TwoFactorSetupResponse{
+		Secret:          "JBSWY3DPEHPK3PXP",
+		ProvisioningURI: "otpauth://totp/CampFinder:test@gmail.com?secret=JBSWY3DPEHPK3PXP",
+	}
+	if got != want {
+		t.Errorf("SetupTwoFactor() = %+v, want %+v", got, want)
+	}
+}
+
+func TestUserHandler_EnableTwoFactor(t *testing.T) {
+	user := model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")}
+	codes := []string{"abcde-fghij", "klmno-pqrst"}
+
+	patterns := []struct {
+		name       string
+		setup      func(m *mock.MockUserUseCase)
+		body       TwoFactorCodeRequest
+		wantStatus int
+		wantCodes  []string
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockUserUseCase) {
+				m.EXPECT().EnableTwoFactor(gomock.Any(), user.ID.String(), "123456").Return(codes, nil)
+			},
+			body:       TwoFactorCodeRequest{Code: "123456"},
+			wantStatus: http.StatusOK,
+			wantCodes:  codes,
+		},
+		{
+			name:       "Fail: missing code",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: wrong code",
+			setup: func(m *mock.MockUserUseCase) {
+				m.EXPECT().EnableTwoFactor(gomock.Any(), user.ID.String(), "000000").Return(nil, usecase.ErrInvalidTwoFactorCode)
+			},
+			body:       TwoFactorCodeRequest{Code: "000000"},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "Fail: too many attempts",
+			setup: func(m *mock.MockUserUseCase) {
+				m.EXPECT().EnableTwoFactor(gomock.Any(), user.ID.String(), "000000").Return(
+					nil, &usecase.LoginThrottledError{RetryAfter: time.Minute},
+				)
+			},
+			body:       TwoFactorCodeRequest{Code: "000000"},
+			wantStatus: http.StatusTooManyRequests,
+		},
+		{
+			name: "Fail: setup not started",
+			setup: func(m *mock.MockUserUseCase) {
+				m.EXPECT().EnableTwoFactor(gomock.Any(), user.ID.String(), "123456").Return(nil, usecase.ErrTwoFactorSetupNotStarted)
+			},
+			body:       TwoFactorCodeRequest{Code: "123456"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: already enabled",
+			setup: func(m *mock.MockUserUseCase) {
+				m.EXPECT().EnableTwoFactor(gomock.Any(), user.ID.String(), "123456").Return(nil, usecase.ErrTwoFactorAlreadyEnabled)
+			},
+			body:       TwoFactorCodeRequest{Code: "123456"},
+			wantStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			uuc := mock.NewMockUserUseCase(ctrl)
+			auc := mock.NewMockAuthUseCase(ctrl)
+			auc.EXPECT().GetUserFromContext(gomock.Any()).Return(&user, nil)
+
+			if tt.setup != nil {
+				tt.setup(uuc)
+			}
+
+			handler := NewUserHandler(uuc, auc)
+			recorder := httptest.NewRecorder()
+			reqBody, _ := json.Marshal(tt.body)
+			req, _ := http.NewRequest(http.MethodPost, "/api/user/2fa/enable", bytes.NewBuffer(reqBody))
+			handler.EnableTwoFactor(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got RecoveryCodesResponse
+			if err := json.NewDecoder(recorder.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if !reflect.DeepEqual(got.RecoveryCodes, tt.wantCodes) {
+				t.Errorf("EnableTwoFactor() = %v, want %v", got.RecoveryCodes, tt.wantCodes)
+			}
+		})
+	}
+}
+
+func TestUserHandler_DisableTwoFactor(t *testing.T) {
+	user := model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")}
+
+	patterns := []struct {
+		name       string
+		setup      func(m *mock.MockUserUseCase)
+		body       DisableTwoFactorRequest
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockUserUseCase) {
+				m.EXPECT().DisableTwoFactor(gomock.Any(), user.ID.String(), "password123", "123456").Return(nil)
+			},
+			body:       DisableTwoFactorRequest{Password: "password123", Code: "123456"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Fail: mandatory for admin",
+			setup: func(m *mock.MockUserUseCase) {
+				m.EXPECT().DisableTwoFactor(gomock.Any(), user.ID.String(), "password123", "123456").Return(
+					usecase.ErrTwoFactorMandatory,
+				)
+			},
+			body:       DisableTwoFactorRequest{Password: "password123", Code: "123456"},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "Fail: not enabled",
+			setup: func(m *mock.MockUserUseCase) {
+				m.EXPECT().DisableTwoFactor(gomock.Any(), user.ID.String(), "password123", "123456").Return(
+					usecase.ErrTwoFactorNotEnabled,
+				)
+			},
+			body:       DisableTwoFactorRequest{Password: "password123", Code: "123456"},
+			wantStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			uuc := mock.NewMockUserUseCase(ctrl)
+			auc := mock.NewMockAuthUseCase(ctrl)
+			auc.EXPECT().GetUserFromContext(gomock.Any()).Return(&user, nil)
+
+			if tt.setup != nil {
+				tt.setup(uuc)
+			}
+
+			handler := NewUserHandler(uuc, auc)
+			recorder := httptest.NewRecorder()
+			reqBody, _ := json.Marshal(tt.body)
+			req, _ := http.NewRequest(http.MethodPost, "/api/user/2fa/disable", bytes.NewBuffer(reqBody))
+			handler.DisableTwoFactor(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -5,15 +5,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/tusmasoma/campfinder/docker/back/config"
 	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
 	"github.com/tusmasoma/campfinder/docker/back/internal/auth"
 )
 
-var ErrCacheMiss = errors.New("cache: key not found")
+var ErrCacheMiss = repository.ErrCacheMiss
+
+// SessionTouchInterval はセッションの最終アクセス日時を更新する間隔です。
+const SessionTouchInterval = time.Minute
 
 type AuthMiddleware interface {
 	Authenticate(nextFunc http.Handler) http.Handler
@@ -64,26 +69,44 @@ func (am *authMiddleware) Authenticate(next http.Handler) http.Handler {
 			return
 		}
 
-		// 該当のuserIdが存在するかキャッシュに問い合わせ
-		jti, err := am.rr.GetUserSession(ctx, payload.UserID)
+		// トークンを発行したセッションが有効かキャッシュに問い合わせ
+		session, err := am.rr.GetSession(ctx, payload.UserID, payload.SessionID)
 		if errors.Is(err, ErrCacheMiss) {
-			http.Error(w, "Authentication failed: userId is not exit on cache", http.StatusUnauthorized)
+			http.Error(w, "Authentication failed: session is not exit on cache", http.StatusUnauthorized)
 			return
 		} else if err != nil {
-			http.Error(w, "Authentication failed: missing userId on cache", http.StatusUnauthorized)
+			http.Error(w, "Authentication failed: missing session on cache", http.StatusUnauthorized)
 			return
 		}
 
-		// Redisから取得したjtiとJWTのjtiを比較
-		if payload.JTI != jti {
+		// Redisから取得したセッションのjtiとJWTのjtiを比較
+		if payload.JTI != session.JTI {
 			http.Error(w, "Authentication failed: jwt does not match", http.StatusUnauthorized)
 			return
 		}
 
-		// 今後有効期限の確認も行う
+		// 利用停止時にセッションは失効させるが、失効に失敗したセッションも拒否する
+		suspended, err := am.rr.IsSuspended(ctx, payload.UserID)
+		if err != nil {
+			log.Printf("Failed to get suspension of %v: %v", payload.UserID, err)
+			http.Error(w, "Authentication failed: missing suspension on cache", http.StatusInternalServerError)
+			return
+		}
+		if suspended {
+			http.Error(w, "Authentication failed: account is suspended", http.StatusForbidden)
+			return
+		}
+
+		// リクエストごとの書き込みを避けるため、一定間隔でのみ最終アクセス日時を更新する
+		if now := time.Now(); now.Sub(session.LastSeenAt) >= SessionTouchInterval {
+			if err = am.rr.TouchSession(ctx, session.UserID, session.ID, now); err != nil {
+				log.Printf("Failed to update last seen of session %v: %v", session.ID, err)
+			}
+		}
 
-		// コンテキストに userID を保存
+		// コンテキストに userID とセッションIDを保存
 		ctx = context.WithValue(ctx, config.ContextUserIDKey, payload.UserID)
+		ctx = context.WithValue(ctx, config.ContextSessionIDKey, payload.SessionID)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -4,11 +4,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 
 	"github.com/tusmasoma/campfinder/docker/back/config"
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
 	"github.com/tusmasoma/campfinder/docker/back/domain/repository/mock"
 	"github.com/tusmasoma/campfinder/docker/back/internal/auth"
 )
@@ -28,7 +30,18 @@ func TestAuthMiddleware_Authenticate(t *testing.T) {
 	userID := uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")
 	email := "test@gmail.com"
 
-	jwt, jti := auth.GenerateToken(userID.String(), email)
+	sessionID := "2b5a4b1e-3f6c-4a8e-9d6b-7e8f9a0b1c2d"
+
+	jwt, jti, err := auth.GenerateToken(userID.String(), email, sessionID)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	session := model.Session{
+		ID:         sessionID,
+		UserID:     userID.String(),
+		JTI:        jti,
+		LastSeenAt: time.Now(),
+	}
 
 	patterns := []struct {
 		name  string
@@ -41,13 +54,43 @@ func TestAuthMiddleware_Authenticate(t *testing.T) {
 		{
 			name: "success",
 			setup: func(m *mock.MockUserCacheRepository) {
-				m.EXPECT().GetUserSession(
+				m.EXPECT().GetSession(
+					gomock.Any(),
+					"f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
+					sessionID,
+				).Return(
+					&session,
+					nil,
+				)
+				m.EXPECT().IsSuspended(gomock.Any(), "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2").Return(false, nil)
+			},
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "/", nil)
+				req.Header.Set("Authorization", "Bearer "+jwt)
+				return req
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "success: update last seen",
+			setup: func(m *mock.MockUserCacheRepository) {
+				staleSession := session
+				staleSession.LastSeenAt = time.Now().Add(-SessionTouchInterval)
+				m.EXPECT().GetSession(
 					gomock.Any(),
 					"f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
+					sessionID,
 				).Return(
-					jti,
+					&staleSession,
 					nil,
 				)
+				m.EXPECT().IsSuspended(gomock.Any(), "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2").Return(false, nil)
+				m.EXPECT().TouchSession(
+					gomock.Any(),
+					"f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
+					sessionID,
+					gomock.Any(),
+				).Return(nil)
 			},
 			in: func() *http.Request {
 				req, _ := http.NewRequest(http.MethodGet, "/", nil)
@@ -83,13 +126,14 @@ func TestAuthMiddleware_Authenticate(t *testing.T) {
 			wantStatus: http.StatusUnauthorized,
 		},
 		{
-			name: "Fail: User ID Not In Cache",
+			name: "Fail: Session Not In Cache",
 			setup: func(m *mock.MockUserCacheRepository) {
-				m.EXPECT().GetUserSession(
+				m.EXPECT().GetSession(
 					gomock.Any(),
 					"f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
+					sessionID,
 				).Return(
-					"",
+					nil,
 					ErrCacheMiss,
 				)
 			},
@@ -100,14 +144,37 @@ func TestAuthMiddleware_Authenticate(t *testing.T) {
 			},
 			wantStatus: http.StatusUnauthorized,
 		},
+		{
+			name: "Fail: Suspended User",
+			setup: func(m *mock.MockUserCacheRepository) {
+				m.EXPECT().GetSession(
+					gomock.Any(),
+					"f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
+					sessionID,
+				).Return(
+					&session,
+					nil,
+				)
+				m.EXPECT().IsSuspended(gomock.Any(), "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2").Return(true, nil)
+			},
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "/", nil)
+				req.Header.Set("Authorization", "Bearer "+jwt)
+				return req
+			},
+			wantStatus: http.StatusForbidden,
+		},
 		{
 			name: "Fail: jti in Cache != jti in Payload",
 			setup: func(m *mock.MockUserCacheRepository) {
-				m.EXPECT().GetUserSession(
+				otherSession := session
+				otherSession.JTI = "invalid jti"
+				m.EXPECT().GetSession(
 					gomock.Any(),
 					"f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
+					sessionID,
 				).Return(
-					"invalid jti",
+					&otherSession,
 					nil,
 				)
 			},
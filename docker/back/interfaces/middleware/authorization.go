@@ -0,0 +1,110 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/tusmasoma/campfinder/docker/back/config"
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+)
+
+const (
+	ErrorCodeUnauthenticated = "unauthenticated"
+	ErrorCodeForbidden       = "forbidden"
+	ErrorCodeInternal        = "internal_error"
+	// ErrorCodeTwoFactorRequired は2段階認証が必須のユーザが、2段階認証を設定していない場合のエラーです。
+	ErrorCodeTwoFactorRequired = "two_factor_required"
+)
+
+type AuthorizationMiddleware interface {
+	RequireRole(required model.Role) func(http.Handler) http.Handler
+}
+
+type authorizationMiddleware struct {
+	ur repository.UserRepository
+	tc *config.TwoFactorConfig
+}
+
+func NewAuthorizationMiddleware(ur repository.UserRepository, tc *config.TwoFactorConfig) AuthorizationMiddleware {
+	return &authorizationMiddleware{
+		ur: ur,
+		tc: tc,
+	}
+}
+
+type ErrorResponse struct {
+	Error ErrorDetail `json:"error"`
+}
+
+type ErrorDetail struct {
+	Code         string     `json:"code"`
+	Message      string     `json:"message"`
+	RequiredRole model.Role `json:"requiredRole,omitempty"`
+}
+
+// RequireRole Authenticateでコンテキストに保存されたユーザを取得し、required以上のロールを持つか確認する
+// 2段階認証が必須の管理者は、2段階認証を設定するまでロールが必要な操作をできない
+func (am *authorizationMiddleware) RequireRole(required model.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			userID, ok := ctx.Value(config.ContextUserIDKey).(string)
+			if !ok || userID == "" {
+				writeError(w, http.StatusUnauthorized, ErrorDetail{
+					Code:    ErrorCodeUnauthenticated,
+					Message: "Authorization failed: userId is not found in context",
+				})
+				return
+			}
+
+			user, err := am.ur.Get(ctx, userID)
+			if errors.Is(err, repository.ErrNotFound) {
+				writeError(w, http.StatusUnauthorized, ErrorDetail{
+					Code:    ErrorCodeUnauthenticated,
+					Message: "Authorization failed: user does not exist",
+				})
+				return
+			} else if err != nil {
+				log.Printf("Failed to get user of %v: %v", userID, err)
+				writeError(w, http.StatusInternalServerError, ErrorDetail{
+					Code:    ErrorCodeInternal,
+					Message: "Internal server error while authorizing user",
+				})
+				return
+			}
+
+			if !user.HasRole(required) {
+				writeError(w, http.StatusForbidden, ErrorDetail{
+					Code:         ErrorCodeForbidden,
+					Message:      fmt.Sprintf("Authorization failed: role %s is required", required),
+					RequiredRole: required,
+				})
+				return
+			}
+
+			if am.tc.RequireForAdmins && user.HasRole(model.RoleAdmin) && !user.TOTPEnabled {
+				writeError(w, http.StatusForbidden, ErrorDetail{
+					Code:    ErrorCodeTwoFactorRequired,
+					Message: "Authorization failed: two-factor authentication must be enabled for admin accounts",
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, detail ErrorDetail) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(ErrorResponse{Error: detail}); err != nil {
+		log.Printf("Failed to encode error response: %v", err)
+	}
+}
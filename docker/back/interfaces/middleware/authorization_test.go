@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/config"
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository/mock"
+)
+
+func TestAuthorizationMiddleware_RequireRole(t *testing.T) {
+	t.Parallel()
+	userID := uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockUserRepository,
+		)
+		userID     string
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name: "success: contributor",
+			setup: func(m *mock.MockUserRepository) {
+				m.EXPECT().Get(gomock.Any(), userID.String()).Return(
+					&model.User{ID: userID, Role: model.RoleContributor},
+					nil,
+				)
+			},
+			userID:     userID.String(),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "success: higher role",
+			setup: func(m *mock.MockUserRepository) {
+				m.EXPECT().Get(gomock.Any(), userID.String()).Return(
+					&model.User{ID: userID, Role: model.RoleModerator},
+					nil,
+				)
+			},
+			userID:     userID.String(),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "success: is_admin user",
+			setup: func(m *mock.MockUserRepository) {
+				m.EXPECT().Get(gomock.Any(), userID.String()).Return(
+					&model.User{ID: userID, IsAdmin: true, TOTPEnabled: true},
+					nil,
+				)
+			},
+			userID:     userID.String(),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Fail: admin without two-factor authentication",
+			setup: func(m *mock.MockUserRepository) {
+				m.EXPECT().Get(gomock.Any(), userID.String()).Return(
+					&model.User{ID: userID, Role: model.RoleAdmin},
+					nil,
+				)
+			},
+			userID:     userID.String(),
+			wantStatus: http.StatusForbidden,
+			wantCode:   ErrorCodeTwoFactorRequired,
+		},
+		{
+			name: "Fail: insufficient role",
+			setup: func(m *mock.MockUserRepository) {
+				m.EXPECT().Get(gomock.Any(), userID.String()).Return(
+					&model.User{ID: userID, Role: model.RoleUser},
+					nil,
+				)
+			},
+			userID:     userID.String(),
+			wantStatus: http.StatusForbidden,
+			wantCode:   ErrorCodeForbidden,
+		},
+		{
+			name:       "Fail: not authenticated",
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   ErrorCodeUnauthenticated,
+		},
+		{
+			name: "Fail: user not found",
+			setup: func(m *mock.MockUserRepository) {
+				m.EXPECT().Get(gomock.Any(), userID.String()).Return(nil, repository.ErrNotFound)
+			},
+			userID:     userID.String(),
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   ErrorCodeUnauthenticated,
+		},
+		{
+			name: "Fail: get user",
+			setup: func(m *mock.MockUserRepository) {
+				m.EXPECT().Get(gomock.Any(), userID.String()).Return(nil, fmt.Errorf("fail to get user"))
+			},
+			userID:     userID.String(),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   ErrorCodeInternal,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			repo := mock.NewMockUserRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(repo)
+			}
+
+			am := NewAuthorizationMiddleware(repo, &config.TwoFactorConfig{RequireForAdmins: true})
+			handler := am.RequireRole(model.RoleContributor)(http.HandlerFunc(dummyTestHandler))
+
+			req, _ := http.NewRequest(http.MethodPost, "/api/spot/create", nil)
+			if tt.userID != "" {
+				req = req.WithContext(context.WithValue(req.Context(), config.ContextUserIDKey, tt.userID))
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if tt.wantCode == "" {
+				return
+			}
+			var body ErrorResponse
+			if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+				t.Fatalf("Failed to decode error response: %v", err)
+			}
+			if body.Error.Code != tt.wantCode {
+				t.Errorf("error code = %v, want %v", body.Error.Code, tt.wantCode)
+			}
+		})
+	}
+}
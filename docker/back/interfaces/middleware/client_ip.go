@@ -0,0 +1,95 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/tusmasoma/campfinder/docker/back/config"
+)
+
+type ClientIPMiddleware interface {
+	ClientIP(next http.Handler) http.Handler
+}
+
+type clientIPMiddleware struct {
+	trusted []*net.IPNet
+}
+
+// NewClientIPMiddleware はsc.TrustedProxiesのIPアドレスまたはCIDRを信頼するプロキシとします。
+func NewClientIPMiddleware(sc *config.ServerConfig) (ClientIPMiddleware, error) {
+	trusted := make([]*net.IPNet, 0, len(sc.TrustedProxies))
+	for _, proxy := range sc.TrustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy: %q", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			trusted = append(trusted, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy: %q", proxy)
+		}
+		trusted = append(trusted, ipNet)
+	}
+	return &clientIPMiddleware{trusted: trusted}, nil
+}
+
+// ClientIP クライアントのIPアドレスをContextへ保存する
+// X-Forwarded-Forは接続元が信頼するプロキシの場合だけ使い、末尾から信頼するプロキシを除いた最初のアドレスをクライアントとする
+// それより前の値はクライアントが自由に設定できるため使わない
+func (cm *clientIPMiddleware) ClientIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), config.ContextClientIPKey, cm.resolve(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (cm *clientIPMiddleware) resolve(r *http.Request) string {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ip = host
+	}
+	if !cm.isTrusted(ip) {
+		return ip
+	}
+	forwarded := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(forwarded[i])
+		if hop == "" {
+			continue
+		}
+		if net.ParseIP(hop) == nil {
+			// 不正な値より前はプロキシが追加した値とみなせない
+			return ip
+		}
+		ip = hop
+		if !cm.isTrusted(hop) {
+			break
+		}
+	}
+	return ip
+}
+
+func (cm *clientIPMiddleware) isTrusted(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range cm.trusted {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tusmasoma/campfinder/docker/back/config"
+)
+
+func TestClientIPMiddleware_ClientIP(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		want       string
+	}{
+		{
+			name:       "direct connection",
+			remoteAddr: "203.0.113.7:54321",
+			want:       "203.0.113.7",
+		},
+		{
+			// 信頼するプロキシ以外からのX-Forwarded-Forは偽装できるため使わない
+			name:       "untrusted peer sends X-Forwarded-For",
+			remoteAddr: "203.0.113.7:54321",
+			forwarded:  "198.51.100.1",
+			want:       "203.0.113.7",
+		},
+		{
+			// nginxは受け取ったX-Forwarded-Forの末尾に接続元を追加する
+			name:       "trusted proxy",
+			remoteAddr: "172.18.0.3:54321",
+			forwarded:  "198.51.100.1, 203.0.113.7",
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "trusted proxy chain",
+			remoteAddr: "172.18.0.3:54321",
+			forwarded:  "198.51.100.1, 203.0.113.7, 10.0.0.1",
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "trusted proxy with invalid X-Forwarded-For",
+			remoteAddr: "172.18.0.3:54321",
+			forwarded:  "unknown",
+			want:       "172.18.0.3",
+		},
+	}
+
+	cm, err := NewClientIPMiddleware(&config.ServerConfig{TrustedProxies: []string{"172.16.0.0/12", "10.0.0.1"}})
+	if err != nil {
+		t.Fatalf("NewClientIPMiddleware() error = %v", err)
+	}
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+
+			var got string
+			handler := cm.ClientIP(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				got, _ = r.Context().Value(config.ContextClientIPKey).(string)
+			}))
+			req, _ := http.NewRequest(http.MethodPost, "/api/user/login", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.want {
+				t.Errorf("client IP = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewClientIPMiddleware_InvalidProxy(t *testing.T) {
+	t.Parallel()
+	if _, err := NewClientIPMiddleware(&config.ServerConfig{TrustedProxies: []string{"nginx"}}); err == nil {
+		t.Error("NewClientIPMiddleware() error = nil, want error")
+	}
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: authorization.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	http "net/http"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+
+	model "github.com/tusmasoma/campfinder/docker/back/domain/model"
+)
+
+// MockAuthorizationMiddleware is a mock of AuthorizationMiddleware interface.
+type MockAuthorizationMiddleware struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthorizationMiddlewareMockRecorder
+}
+
+// MockAuthorizationMiddlewareMockRecorder is the mock recorder for MockAuthorizationMiddleware.
+type MockAuthorizationMiddlewareMockRecorder struct {
+	mock *MockAuthorizationMiddleware
+}
+
+// NewMockAuthorizationMiddleware creates a new mock instance.
+func NewMockAuthorizationMiddleware(ctrl *gomock.Controller) *MockAuthorizationMiddleware {
+	mock := &MockAuthorizationMiddleware{ctrl: ctrl}
+	mock.recorder = &MockAuthorizationMiddlewareMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuthorizationMiddleware) EXPECT() *MockAuthorizationMiddlewareMockRecorder {
+	return m.recorder
+}
+
+// RequireRole mocks base method.
+func (m *MockAuthorizationMiddleware) RequireRole(required model.Role) func(http.Handler) http.Handler {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequireRole", required)
+	ret0, _ := ret[0].(func(http.Handler) http.Handler)
+	return ret0
+}
+
+// RequireRole indicates an expected call of RequireRole.
+func (mr *MockAuthorizationMiddlewareMockRecorder) RequireRole(required interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequireRole", reflect.TypeOf((*MockAuthorizationMiddleware)(nil).RequireRole), required)
+}
@@ -0,0 +1,49 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: client_ip.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	http "net/http"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockClientIPMiddleware is a mock of ClientIPMiddleware interface.
+type MockClientIPMiddleware struct {
+	ctrl     *gomock.Controller
+	recorder *MockClientIPMiddlewareMockRecorder
+}
+
+// MockClientIPMiddlewareMockRecorder is the mock recorder for MockClientIPMiddleware.
+type MockClientIPMiddlewareMockRecorder struct {
+	mock *MockClientIPMiddleware
+}
+
+// NewMockClientIPMiddleware creates a new mock instance.
+func NewMockClientIPMiddleware(ctrl *gomock.Controller) *MockClientIPMiddleware {
+	mock := &MockClientIPMiddleware{ctrl: ctrl}
+	mock.recorder = &MockClientIPMiddlewareMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClientIPMiddleware) EXPECT() *MockClientIPMiddlewareMockRecorder {
+	return m.recorder
+}
+
+// ClientIP mocks base method.
+func (m *MockClientIPMiddleware) ClientIP(next http.Handler) http.Handler {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClientIP", next)
+	ret0, _ := ret[0].(http.Handler)
+	return ret0
+}
+
+// ClientIP indicates an expected call of ClientIP.
+func (mr *MockClientIPMiddlewareMockRecorder) ClientIP(next interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClientIP", reflect.TypeOf((*MockClientIPMiddleware)(nil).ClientIP), next)
+}
@@ -11,6 +11,16 @@ func PasswordEncrypt(password string) (string, error) {
 	return string(hash), err
 }
 
+// UnusablePasswordHash はどのパスワードとも一致しないハッシュを返します。推測できないランダムな値をハッシュ化するため、
+// パスワードを持たないユーザを表す空のパスワードとは区別され、パスワードの確認は常に失敗します。
+func UnusablePasswordHash() (string, error) {
+	token, err := generateRandomToken()
+	if err != nil {
+		return "", err
+	}
+	return PasswordEncrypt(token)
+}
+
 func CompareHashAndPassword(hash, password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 }
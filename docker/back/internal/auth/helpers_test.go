@@ -81,6 +81,15 @@ func Test_CompareHashAndPassword(t *testing.T) {
 	}
 }
 
+func Test_UnusablePasswordHash(t *testing.T) {
+	t.Parallel()
+
+	hash, err := UnusablePasswordHash()
+	require.NoError(t, err)
+	require.NotEmpty(t, hash)
+	require.ErrorIs(t, CompareHashAndPassword(hash, ""), bcrypt.ErrMismatchedHashAndPassword)
+}
+
 func Test_ExtractUsernameFromEmail(t *testing.T) {
 	t.Parallel()
 
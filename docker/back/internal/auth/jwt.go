@@ -1,82 +1,51 @@
 package auth
 
 import (
-	"crypto"
 	"crypto/rand"
-	"crypto/rsa"
 	"crypto/sha256"
-	"crypto/x509"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
-	"encoding/pem"
+	"errors"
 	"fmt"
 	"log"
-	"os"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Payload struct {
-	JTI    string `json:"jti"`
-	UserID string `json:"userId"`
+	JTI       string `json:"jti"`
+	UserID    string `json:"userId"`
+	SessionID string `json:"sid"`
+	Exp       int64  `json:"exp"`
+	Iat       int64  `json:"iat"`
+	Nbf       int64  `json:"nbf"`
 }
 
-const expectedTokenParts = 3
+const (
+	// AccessTokenTTL はアクセストークンの有効期間です。期限切れ後はリフレッシュトークンで再発行します。
+	AccessTokenTTL = 15 * time.Minute
+	// RefreshTokenTTL はリフレッシュトークンの有効期間です。
+	RefreshTokenTTL = 30 * 24 * time.Hour
 
-func loadPrivateKeyFromFile(filename string) (*rsa.PrivateKey, error) {
-	// ファイルから秘密鍵をバイトスライスとして読み込む
-	keyBytes, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, fmt.Errorf("error reading the key file: %w", err)
-	}
-
-	// PEMエンコードされたデータからPEMブロックをデコード
-	block, _ := pem.Decode(keyBytes)
-	if block == nil || (block.Type != "RSA PRIVATE KEY" && block.Type != "PRIVATE KEY") {
-		return nil, fmt.Errorf("failed to decode PEM block containing the key")
-	}
-
-	// PEMブロックからRSA秘密鍵をパース
-	privInterface, err := x509.ParsePKCS8PrivateKey(block.Bytes)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse private key: %w", err)
-	}
-
-	privKey, ok := privInterface.(*rsa.PrivateKey)
-	if !ok {
-		return nil, fmt.Errorf("not RSA private key")
-	}
+	// TokenType はヘッダのtypです。
+	TokenType = "JWT"
 
-	return privKey, nil
-}
-
-func loadPublicKeyFromFile(filename string) (*rsa.PublicKey, error) {
-	// ファイルから公開鍵をバイトスライスとして読み込む
-	keyBytes, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, fmt.Errorf("error reading the key file: %w", err)
-	}
-
-	// PEMエンコードされたデータからPEMブロックをデコード
-	block, _ := pem.Decode(keyBytes)
-	if block == nil || block.Type != "PUBLIC KEY" {
-		return nil, fmt.Errorf("failed to decode PEM block containing the key")
-	}
-
-	// PEMブロックからRSA公開鍵をパース
-	pubInterface, err := x509.ParsePKIXPublicKey(block.Bytes)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse public key: %w", err)
-	}
+	expectedTokenParts = 3
+	randomTokenBytes   = 32
+)
 
-	pubKey, ok := pubInterface.(*rsa.PublicKey)
-	if !ok {
-		return nil, fmt.Errorf("not RSA public key")
-	}
+var (
+	ErrTokenExpired        = errors.New("token is expired")
+	ErrTokenNotYetValid    = errors.New("token is not valid yet")
+	ErrAlgorithmNotAllowed = errors.New("token algorithm is not allowed")
+	ErrInvalidTokenType    = errors.New("invalid token type")
+)
 
-	return pubKey, nil
-}
+// timeNow はテストで現在時刻を差し替えるための変数です。
+var timeNow = time.Now
 
 // Base64Urlエンコード
 func base64UrlEncode(b []byte) string {
@@ -88,22 +57,48 @@ func base64UrlDecode(s string) ([]byte, error) {
 	return base64.RawURLEncoding.DecodeString(s)
 }
 
-// アクセストークン(JWT形式)の生成
-func GenerateToken(userID, email string) (string, string) {
+// Header はJWTのヘッダです。kidは署名した鍵のIDで、kidを付けていなかった頃のトークンでは空です。
+type Header struct {
+	Typ string `json:"typ"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// アクセストークン(JWT形式)の生成。sessionIDはトークンを発行したセッション(端末)のIDです。
+func GenerateToken(userID, email, sessionID string) (string, string, error) {
+	km, err := DefaultKeyManager()
+	if err != nil {
+		return "", "", err
+	}
+	kid, privKey, err := km.signer()
+	if err != nil {
+		return "", "", err
+	}
+	method, err := GetSigningMethod(km.SigningAlg())
+	if err != nil {
+		return "", "", err
+	}
+
 	// ヘッダの作成
-	header := map[string]string{
-		"typ": "JWT",
-		"alg": "RS256",
+	header := Header{
+		Typ: TokenType,
+		Alg: method.Alg(),
+		Kid: kid,
 	}
 	headerBytes, _ := json.Marshal(header)
 	encodedHeader := base64UrlEncode(headerBytes)
 
 	// ペイロードの作成
 	jti := uuid.New().String()
-	payload := map[string]string{
+	now := timeNow()
+	payload := map[string]any{
 		"jti":    jti,
 		"userId": userID,
 		"Email":  email,
+		"sid":    sessionID,
+		"iat":    now.Unix(),
+		"nbf":    now.Unix(),
+		"exp":    now.Add(AccessTokenTTL).Unix(),
 	}
 	payloadBytes, _ := json.Marshal(payload)
 	encodedPayload := base64UrlEncode(payloadBytes)
@@ -111,25 +106,17 @@ func GenerateToken(userID, email string) (string, string) {
 	// エンコードされたヘッダとペイロードを結合
 	jwtWithoutSignature := fmt.Sprintf("%s.%s", encodedHeader, encodedPayload)
 
-	// SHA-256ハッシュを計算
-	hashed := sha256.Sum256([]byte(jwtWithoutSignature))
-
 	// 署名作成
-	privateKeyPath := os.Getenv("PRIVATE_KEY_PATH")
-	privKey, err := loadPrivateKeyFromFile(privateKeyPath)
-	if err != nil {
-		panic(err)
-	}
-	signature, err := rsa.SignPKCS1v15(rand.Reader, privKey, crypto.SHA256, hashed[:])
+	signature, err := method.Sign(privKey, []byte(jwtWithoutSignature))
 	if err != nil {
-		panic(err)
+		return "", "", fmt.Errorf("failed to sign token: %w", err)
 	}
 	encodedSignature := base64UrlEncode(signature)
 
 	// JWTを完成
 	jwt := fmt.Sprintf("%s.%s", jwtWithoutSignature, encodedSignature)
 
-	return jwt, jti
+	return jwt, jti, nil
 }
 
 func ValidateAccessToken(jwt string) error {
@@ -138,10 +125,21 @@ func ValidateAccessToken(jwt string) error {
 	if len(parts) != expectedTokenParts {
 		return fmt.Errorf("invalid token")
 	}
+	header, err := getHeaderFromToken(parts[0])
+	if err != nil {
+		return err
+	}
+
+	// 検証。ローテーション前の鍵で署名されたトークンもkidで公開鍵を選んで検証する
+	km, err := DefaultKeyManager()
+	if err != nil {
+		return err
+	}
+	if !km.IsAllowedTyp(header.Typ) {
+		return fmt.Errorf("%w: %q", ErrInvalidTokenType, header.Typ)
+	}
 	// エンコードされたヘッダとペイロードを結合
 	jwtWithoutSignature := fmt.Sprintf("%s.%s", parts[0], parts[1])
-	// SHA-256ハッシュを計算
-	hashed := sha256.Sum256([]byte(jwtWithoutSignature))
 
 	// 著名作成
 	signature, err := base64UrlDecode(parts[2])
@@ -149,22 +147,57 @@ func ValidateAccessToken(jwt string) error {
 		return fmt.Errorf("decoding failed: %w", err)
 	}
 
-	// 検証
-	publicKeyPath := os.Getenv("PUBLIC_KEY_PATH")
-	pubKey, err := loadPublicKeyFromFile(publicKeyPath)
+	// "none"や許可していないアルゴリズムのトークンは署名を確認せずに拒否する
+	if !km.IsAllowedAlg(header.Alg) {
+		return fmt.Errorf("%w: %q", ErrAlgorithmNotAllowed, header.Alg)
+	}
+	pubKey, keyAlg, err := km.PublicKey(header.Kid)
+	if err != nil {
+		return err
+	}
+	// 公開鍵を別のアルゴリズムの鍵として使わせない
+	if keyAlg != header.Alg {
+		return fmt.Errorf("%w: %q for %s key", ErrAlgorithmNotAllowed, header.Alg, keyAlg)
+	}
+	method, err := GetSigningMethod(header.Alg)
 	if err != nil {
 		return err
 	}
 
-	err = rsa.VerifyPKCS1v15(pubKey, crypto.SHA256, hashed[:], signature)
-	log.Print(err)
+	err = method.Verify(pubKey, []byte(jwtWithoutSignature), signature)
 	if err != nil {
+		log.Print(err)
 		return fmt.Errorf("signature verification failed: %w", err)
 	}
 
+	// 有効期限の検証
+	payload, err := GetPayloadFromToken(jwt)
+	if err != nil {
+		return err
+	}
+	now := timeNow().Unix()
+	if payload.Exp == 0 || now >= payload.Exp {
+		return ErrTokenExpired
+	}
+	if now < payload.Nbf {
+		return ErrTokenNotYetValid
+	}
+
 	return nil
 }
 
+func getHeaderFromToken(encodedHeader string) (Header, error) {
+	var header Header
+	headerBytes, err := base64UrlDecode(encodedHeader)
+	if err != nil {
+		return header, fmt.Errorf("decoding failed: %w", err)
+	}
+	if err = json.Unmarshal(headerBytes, &header); err != nil {
+		return header, fmt.Errorf("JSON unmarshalling failed")
+	}
+	return header, nil
+}
+
 func GetPayloadFromToken(jwt string) (Payload, error) {
 	var emptyPayload Payload
 	//　アクセストークンの検証
@@ -189,3 +222,32 @@ func GetPayloadFromToken(jwt string) (Payload, error) {
 
 	return payload, nil
 }
+
+// GenerateRefreshToken はリフレッシュトークンを生成します。リフレッシュトークンはJWTではなく推測できないランダムな文字列で、
+// サーバ側ではHashRefreshTokenのハッシュのみを保存します。
+func GenerateRefreshToken() (string, error) {
+	return generateRandomToken()
+}
+
+func HashRefreshToken(token string) string {
+	return HashToken(token)
+}
+
+// GenerateUserToken はメール確認やパスワード再設定のためにメールで送る使い捨てのトークンを生成します。
+// リフレッシュトークンと同様に、サーバ側ではHashTokenのハッシュのみを保存します。
+func GenerateUserToken() (string, error) {
+	return generateRandomToken()
+}
+
+func HashToken(token string) string {
+	hashed := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hashed[:])
+}
+
+func generateRandomToken() (string, error) {
+	b := make([]byte, randomTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64UrlEncode(b), nil
+}
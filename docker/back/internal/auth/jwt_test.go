@@ -1,8 +1,14 @@
 package auth
 
 import (
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	jwtgo "github.com/dgrijalva/jwt-go"
 	"github.com/google/uuid"
@@ -16,18 +22,29 @@ func Test_JWTToken(t *testing.T) {
 
 	userID := uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")
 	email := "test@gmail.com"
+	sessionID := "2b5a4b1e-3f6c-4a8e-9d6b-7e8f9a0b1c2d"
 
 	// GenerateToken test
-	jwt, jti := GenerateToken(userID.String(), email)
+	jwt, jti, err := GenerateToken(userID.String(), email, sessionID)
+	if err != nil {
+		t.Fatalf("Failed to GenerateToken: %s", err)
+	}
 
 	// JWTのフォーマットが正しいことを確認
+	pubKeys, err := loadPublicKeysFromFile(publicKeyPath)
+	if err != nil {
+		t.Fatalf("Failed to load public key: %s", err)
+	}
 	token, err := jwtgo.Parse(jwt, func(token *jwtgo.Token) (interface{}, error) {
 		// ここで公開キーを使って署名を検証する（公開キーは環境に依存する）
-		return loadPublicKeyFromFile(publicKeyPath) // loadPublicKeyは公開キーをロードする関数
+		return pubKeys[0], nil
 	})
 	if err != nil {
 		t.Errorf("Failed to parse JWT: %s", err)
 	}
+	if token.Header["kid"] != keyID(pubKeys[0]) {
+		t.Errorf("Expected kid of the signing key, got %v", token.Header["kid"])
+	}
 
 	// クレームを検証
 	claims, ok := token.Claims.(jwtgo.MapClaims)
@@ -54,8 +71,201 @@ func Test_JWTToken(t *testing.T) {
 	if err != nil {
 		t.Errorf("Failed to GetPayloadFromToken: %s", err)
 	}
-	wantPayload := Payload{JTI: jti, UserID: userID.String()}
+	wantPayload := Payload{
+		JTI:       jti,
+		UserID:    userID.String(),
+		SessionID: sessionID,
+		Exp:       payload.Iat + int64(AccessTokenTTL.Seconds()),
+		Iat:       payload.Iat,
+		Nbf:       payload.Iat,
+	}
 	if !reflect.DeepEqual(payload, wantPayload) {
 		t.Errorf("GetPayloadFromToken() \n got = %v,\n want = %v", payload, wantPayload)
 	}
 }
+
+func Test_ValidateAccessToken_Expiry(t *testing.T) {
+	t.Setenv("PRIVATE_KEY_PATH", "../../../../.certificate/private_key.pem")
+	t.Setenv("PUBLIC_KEY_PATH", "../../../../.certificate/public_key.pem")
+
+	issuedAt := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return issuedAt }
+	t.Cleanup(func() { timeNow = time.Now })
+	jwt, _, _ := GenerateToken(
+		"f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
+		"test@gmail.com",
+		"2b5a4b1e-3f6c-4a8e-9d6b-7e8f9a0b1c2d",
+	)
+
+	patterns := []struct {
+		name    string
+		now     time.Time
+		wantErr error
+	}{
+		{
+			name: "valid",
+			now:  issuedAt.Add(AccessTokenTTL - time.Second),
+		},
+		{
+			name:    "expired",
+			now:     issuedAt.Add(AccessTokenTTL),
+			wantErr: ErrTokenExpired,
+		},
+		{
+			name:    "not yet valid",
+			now:     issuedAt.Add(-time.Minute),
+			wantErr: ErrTokenNotYetValid,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			now := tt.now
+			timeNow = func() time.Time { return now }
+
+			if err := ValidateAccessToken(jwt); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateAccessToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_GenerateRefreshToken(t *testing.T) {
+	t.Parallel()
+
+	token1, err := GenerateRefreshToken()
+	if err != nil {
+		t.Fatalf("GenerateRefreshToken() error = %v", err)
+	}
+	token2, _ := GenerateRefreshToken()
+	if token1 == token2 || len(token1) < randomTokenBytes {
+		t.Errorf("GenerateRefreshToken() = %v, %v", token1, token2)
+	}
+	if HashRefreshToken(token1) != HashRefreshToken(token1) || HashRefreshToken(token1) == HashRefreshToken(token2) {
+		t.Errorf("HashRefreshToken() is not deterministic or collides")
+	}
+}
+
+func Test_GenerateToken_Algorithms(t *testing.T) {
+	for _, alg := range []string{AlgRS256, AlgES256, AlgEdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			key := generateTestSigner(t, alg)
+			privateKeyPath, publicKeyPath := writeTestKeys(t, t.TempDir(), key, key.Public())
+			t.Setenv("PRIVATE_KEY_PATH", privateKeyPath)
+			t.Setenv("PUBLIC_KEY_PATH", publicKeyPath)
+			t.Setenv("JWT_SIGNING_ALG", alg)
+
+			jwt, _, err := GenerateToken("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2", "test@gmail.com", "session")
+			if err != nil {
+				t.Fatalf("GenerateToken() error = %v", err)
+			}
+			header, err := getHeaderFromToken(strings.Split(jwt, ".")[0])
+			if err != nil || header.Alg != alg || header.Typ != TokenType || header.Kid != keyID(key.Public()) {
+				t.Errorf("GenerateToken() header = %+v, %v", header, err)
+			}
+			if err = ValidateAccessToken(jwt); err != nil {
+				t.Errorf("ValidateAccessToken() error = %v", err)
+			}
+		})
+	}
+}
+
+func Test_ValidateAccessToken_Allowlist(t *testing.T) {
+	ecKey := generateTestSigner(t, AlgES256)
+	rsaKey := generateTestSigner(t, AlgRS256)
+	dir := t.TempDir()
+	privateKeyPath, publicKeyPath := writeTestKeys(t, dir, ecKey, ecKey.Public(), rsaKey.Public())
+	t.Setenv("PRIVATE_KEY_PATH", privateKeyPath)
+	t.Setenv("PUBLIC_KEY_PATH", publicKeyPath)
+	t.Setenv("JWT_SIGNING_ALG", AlgES256)
+
+	payload := base64UrlEncode([]byte(fmt.Sprintf(`{"userId":"f6db2530-cd9b-4ac1-8dc1-38c795e6eec2","exp":%d}`,
+		time.Now().Add(time.Minute).Unix())))
+	signedToken := func(method SigningMethod, key crypto.Signer, header Header) string {
+		headerBytes, _ := json.Marshal(header)
+		signingInput := base64UrlEncode(headerBytes) + "." + payload
+		signature, err := method.Sign(key, []byte(signingInput))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signingInput + "." + base64UrlEncode(signature)
+	}
+
+	patterns := []struct {
+		name        string
+		allowedAlgs string
+		allowedTyps string
+		jwt         func() string
+		wantErr     error
+	}{
+		{
+			name: "success",
+			jwt: func() string {
+				return signedToken(es256{}, ecKey, Header{Typ: TokenType, Alg: AlgES256, Kid: keyID(ecKey.Public())})
+			},
+		},
+		{
+			name: "Fail: alg none",
+			jwt: func() string {
+				headerBytes, _ := json.Marshal(Header{Typ: TokenType, Alg: "none"})
+				return base64UrlEncode(headerBytes) + "." + payload + "."
+			},
+			wantErr: ErrAlgorithmNotAllowed,
+		},
+		{
+			name: "Fail: typ mismatch",
+			jwt: func() string {
+				return signedToken(es256{}, ecKey, Header{Typ: "JWS", Alg: AlgES256, Kid: keyID(ecKey.Public())})
+			},
+			wantErr: ErrInvalidTokenType,
+		},
+		{
+			name:        "success: additional typ allowed",
+			allowedTyps: "JWT,at+jwt",
+			jwt: func() string {
+				return signedToken(es256{}, ecKey, Header{Typ: "at+jwt", Alg: AlgES256, Kid: keyID(ecKey.Public())})
+			},
+		},
+		{
+			name:        "Fail: typ not in allowlist",
+			allowedTyps: "JWT,at+jwt",
+			jwt: func() string {
+				return signedToken(es256{}, ecKey, Header{Typ: "JWS", Alg: AlgES256, Kid: keyID(ecKey.Public())})
+			},
+			wantErr: ErrInvalidTokenType,
+		},
+		{
+			name: "Fail: alg not in allowlist",
+			jwt: func() string {
+				return signedToken(rs256{}, rsaKey, Header{Typ: TokenType, Alg: AlgRS256, Kid: keyID(rsaKey.Public())})
+			},
+			wantErr: ErrAlgorithmNotAllowed,
+		},
+		{
+			name:        "success: previous alg allowed during migration",
+			allowedAlgs: "ES256,RS256",
+			jwt: func() string {
+				return signedToken(rs256{}, rsaKey, Header{Typ: TokenType, Alg: AlgRS256, Kid: keyID(rsaKey.Public())})
+			},
+		},
+		{
+			name:        "Fail: alg does not match key",
+			allowedAlgs: "ES256,RS256",
+			jwt: func() string {
+				return signedToken(rs256{}, rsaKey, Header{Typ: TokenType, Alg: AlgRS256, Kid: keyID(ecKey.Public())})
+			},
+			wantErr: ErrAlgorithmNotAllowed,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("JWT_ALLOWED_ALGS", tt.allowedAlgs)
+			t.Setenv("JWT_ALLOWED_TYPS", tt.allowedTyps)
+
+			if err := ValidateAccessToken(tt.jwt()); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateAccessToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
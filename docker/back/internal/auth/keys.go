@@ -0,0 +1,526 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"os/signal"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrUnknownKeyID = errors.New("unknown key id")
+	ErrNoSigningKey = errors.New("signing key is not loaded")
+)
+
+// KeyConfig は鍵ファイルと署名アルゴリズムの設定です。AllowedAlgsは検証を許可するアルゴリズムで、
+// 空の場合はSigningAlgのみを許可します。アルゴリズムを移行する間は移行前のアルゴリズムも含めます。
+// AllowedTypsは検証を許可するヘッダのtypで、空の場合はTokenTypeのみを許可します。
+type KeyConfig struct {
+	PrivateKeyPath string
+	PublicKeyPath  string
+	SigningAlg     string
+	AllowedAlgs    []string
+	AllowedTyps    []string
+}
+
+// withDefaults は未設定の項目をデフォルト値にしたconfigを返します。
+func (config KeyConfig) withDefaults() KeyConfig {
+	if config.SigningAlg == "" {
+		config.SigningAlg = AlgRS256
+	}
+	if len(config.AllowedAlgs) == 0 {
+		config.AllowedAlgs = []string{config.SigningAlg}
+	}
+	if len(config.AllowedTyps) == 0 {
+		config.AllowedTyps = []string{TokenType}
+	}
+	return config
+}
+
+// KeyManager はJWTの署名鍵と検証用の公開鍵をメモリに保持します。鍵ファイルはReloadを呼ぶまで読み込み直しません。
+// 公開鍵はkid(RFC 7638のJWK Thumbprint)で識別します。ローテーションで使われなくなった公開鍵も、
+// その鍵で署名したアクセストークンが期限切れになるまでは検証とJWKSに残します。
+type KeyManager struct {
+	config KeyConfig
+
+	mu         sync.RWMutex
+	signingKey crypto.Signer
+	signingKID string
+	publicKeys map[string]*publicKey
+	modTimes   [2]time.Time
+}
+
+type publicKey struct {
+	key crypto.PublicKey
+	alg string
+	// retiredAt はローテーションで鍵ファイルから削除された日時です。使用中の鍵はゼロ値です。
+	retiredAt time.Time
+}
+
+// JWK はRFC 7517の公開鍵です。RSAはn・e、ECはcrv・x・y、OKP(Ed25519)はcrv・xを持ちます。
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewKeyManager は秘密鍵と公開鍵のPEMファイルを読み込みます。公開鍵のファイルには複数の鍵を含めることができ、
+// 他のインスタンスが先に新しい鍵で署名したトークンも検証できるようにローテーション前に公開鍵だけを追加しておけます。
+func NewKeyManager(config KeyConfig) (*KeyManager, error) {
+	config = config.withDefaults()
+	for _, alg := range config.AllowedAlgs {
+		if _, err := GetSigningMethod(alg); err != nil {
+			return nil, err
+		}
+	}
+	if !slices.Contains(config.AllowedAlgs, config.SigningAlg) {
+		return nil, fmt.Errorf("signing algorithm %s is not in allowed algorithms %v", config.SigningAlg, config.AllowedAlgs)
+	}
+	// 発行するトークンのtypを許可しないと、自身が発行したトークンを検証できない
+	if !slices.Contains(config.AllowedTyps, TokenType) {
+		return nil, fmt.Errorf("token type %s is not in allowed types %v", TokenType, config.AllowedTyps)
+	}
+
+	km := &KeyManager{
+		config:     config,
+		publicKeys: map[string]*publicKey{},
+	}
+	if err := km.Reload(); err != nil {
+		return nil, err
+	}
+	return km, nil
+}
+
+var (
+	defaultKeyManagerMu sync.Mutex
+	defaultKeyManager   *KeyManager
+)
+
+// DefaultKeyManager は環境変数の設定で鍵を読み込んだKeyManagerを返します。
+// PRIVATE_KEY_PATH・PUBLIC_KEY_PATHは鍵ファイル、JWT_SIGNING_ALGは署名アルゴリズム(デフォルトはRS256)、
+// JWT_ALLOWED_ALGSは検証を許可するアルゴリズム、JWT_ALLOWED_TYPSは検証を許可するヘッダのtypのカンマ区切りです。
+// GenerateTokenとValidateAccessTokenもこのKeyManagerを使います。読み込みは初回と設定が変わった場合のみです。
+func DefaultKeyManager() (*KeyManager, error) {
+	config := KeyConfig{
+		PrivateKeyPath: os.Getenv("PRIVATE_KEY_PATH"),
+		PublicKeyPath:  os.Getenv("PUBLIC_KEY_PATH"),
+		SigningAlg:     os.Getenv("JWT_SIGNING_ALG"),
+		AllowedAlgs:    splitList(os.Getenv("JWT_ALLOWED_ALGS")),
+		AllowedTyps:    splitList(os.Getenv("JWT_ALLOWED_TYPS")),
+	}
+
+	defaultKeyManagerMu.Lock()
+	defer defaultKeyManagerMu.Unlock()
+	if defaultKeyManager != nil && defaultKeyManager.configuredWith(config) {
+		return defaultKeyManager, nil
+	}
+	km, err := NewKeyManager(config)
+	if err != nil {
+		return nil, err
+	}
+	defaultKeyManager = km
+	return km, nil
+}
+
+// splitList はカンマ区切りの値を分割します。空の場合はnilを返します。
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		values = append(values, strings.TrimSpace(v))
+	}
+	return values
+}
+
+// configuredWith は未設定の項目をデフォルト値とみなしてconfigと同じ設定かを返します。
+func (km *KeyManager) configuredWith(config KeyConfig) bool {
+	config = config.withDefaults()
+	return km.config.PrivateKeyPath == config.PrivateKeyPath &&
+		km.config.PublicKeyPath == config.PublicKeyPath &&
+		km.config.SigningAlg == config.SigningAlg &&
+		slices.Equal(km.config.AllowedAlgs, config.AllowedAlgs) &&
+		slices.Equal(km.config.AllowedTyps, config.AllowedTyps)
+}
+
+// Reload は鍵ファイルを読み込み直します。読み込みに失敗した場合は現在の鍵を使い続けます。
+// 秘密鍵の種類がSigningAlgと一致しない場合はエラーを返します。
+func (km *KeyManager) Reload() error {
+	modTimes, err := km.statKeyFiles()
+	if err != nil {
+		return err
+	}
+	signingKey, err := loadPrivateKeyFromFile(km.config.PrivateKeyPath)
+	if err != nil {
+		return err
+	}
+	keys, err := loadPublicKeysFromFile(km.config.PublicKeyPath)
+	if err != nil {
+		return err
+	}
+
+	active := make(map[string]*publicKey, len(keys)+1)
+	for _, key := range append([]crypto.PublicKey{signingKey.Public()}, keys...) {
+		var alg string
+		if alg, err = algorithmForKey(key); err != nil {
+			return err
+		}
+		active[keyID(key)] = &publicKey{key: key, alg: alg}
+	}
+	signingKID := keyID(signingKey.Public())
+	if alg := active[signingKID].alg; alg != km.config.SigningAlg {
+		return fmt.Errorf("signing key is for %s, but signing algorithm is %s", alg, km.config.SigningAlg)
+	}
+
+	now := timeNow()
+	km.mu.Lock()
+	defer km.mu.Unlock()
+	for kid, pk := range km.publicKeys {
+		if _, ok := active[kid]; ok {
+			continue
+		}
+		if pk.retiredAt.IsZero() {
+			pk.retiredAt = now
+		} else if pk.expired(now) {
+			delete(km.publicKeys, kid)
+		}
+	}
+	for kid, pk := range active {
+		km.publicKeys[kid] = pk
+	}
+	km.signingKey = signingKey
+	km.signingKID = signingKID
+	km.modTimes = modTimes
+	return nil
+}
+
+// Watch はSIGHUPを受け取るか、intervalごとの確認で鍵ファイルの更新を検知した場合に鍵を読み込み直します。
+// ctxがキャンセルされるまでブロックします。
+func (km *KeyManager) Watch(ctx context.Context, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			km.reload("SIGHUP")
+		case <-ticker.C:
+			if km.modified() {
+				km.reload("key file changed")
+			}
+		}
+	}
+}
+
+func (km *KeyManager) reload(reason string) {
+	if err := km.Reload(); err != nil {
+		log.Printf("Failed to reload signing keys (%s): %v", reason, err)
+		return
+	}
+	log.Printf("Reloaded signing keys (%s) - kid: %v", reason, km.SigningKeyID())
+}
+
+func (km *KeyManager) modified() bool {
+	modTimes, err := km.statKeyFiles()
+	if err != nil {
+		return false
+	}
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+	return modTimes != km.modTimes
+}
+
+func (km *KeyManager) statKeyFiles() ([2]time.Time, error) {
+	var modTimes [2]time.Time
+	for i, path := range []string{km.config.PrivateKeyPath, km.config.PublicKeyPath} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return modTimes, fmt.Errorf("error reading the key file: %w", err)
+		}
+		modTimes[i] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+// SigningKeyID は新しいトークンの署名に使う鍵のkidを返します。
+func (km *KeyManager) SigningKeyID() string {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+	return km.signingKID
+}
+
+// SigningAlg は新しいトークンの署名に使うアルゴリズムを返します。
+func (km *KeyManager) SigningAlg() string {
+	return km.config.SigningAlg
+}
+
+// IsAllowedAlg はalgのトークンの検証を許可するかを返します。
+func (km *KeyManager) IsAllowedAlg(alg string) bool {
+	return slices.Contains(km.config.AllowedAlgs, alg)
+}
+
+// IsAllowedTyp はヘッダのtypがtypのトークンの検証を許可するかを返します。
+func (km *KeyManager) IsAllowedTyp(typ string) bool {
+	return slices.Contains(km.config.AllowedTyps, typ)
+}
+
+// signer は署名鍵とkidを返します。ローテーションと競合しても組み合わせがずれないように同時に取得します。
+func (km *KeyManager) signer() (string, crypto.Signer, error) {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+	if km.signingKey == nil {
+		return "", nil, ErrNoSigningKey
+	}
+	return km.signingKID, km.signingKey, nil
+}
+
+// PublicKey はkidの公開鍵とそのアルゴリズムを返します。kidが空の場合はkidを付けていなかった頃のトークンとみなし、
+// 署名鍵の公開鍵を返します。
+func (km *KeyManager) PublicKey(kid string) (crypto.PublicKey, string, error) {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+	if kid == "" {
+		kid = km.signingKID
+	}
+	pk, ok := km.publicKeys[kid]
+	if !ok || pk.expired(timeNow()) {
+		return nil, "", ErrUnknownKeyID
+	}
+	return pk.key, pk.alg, nil
+}
+
+// JWKS は検証に使える公開鍵をkid順で返します。
+func (km *KeyManager) JWKS() JWKS {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+	now := timeNow()
+	jwks := JWKS{Keys: make([]JWK, 0, len(km.publicKeys))}
+	for kid, pk := range km.publicKeys {
+		if pk.expired(now) {
+			continue
+		}
+		jwk := publicJWK(pk.key)
+		jwk.Use = "sig"
+		jwk.Alg = pk.alg
+		jwk.Kid = kid
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool {
+		return jwks.Keys[i].Kid < jwks.Keys[j].Kid
+	})
+	return jwks
+}
+
+// expired は削除された鍵で署名したアクセストークンがすべて期限切れになったかを返します。
+func (pk *publicKey) expired(now time.Time) bool {
+	return !pk.retiredAt.IsZero() && !now.Before(pk.retiredAt.Add(AccessTokenTTL))
+}
+
+// publicJWK は公開鍵の種類ごとの必須メンバーのみを設定したJWKを返します。
+func publicJWK(pub crypto.PublicKey) JWK {
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			N:   base64UrlEncode(key.N.Bytes()),
+			E:   base64UrlEncode(big.NewInt(int64(key.E)).Bytes()),
+		}
+	case *ecdsa.PublicKey:
+		x := make([]byte, es256CoordinateSize)
+		y := make([]byte, es256CoordinateSize)
+		return JWK{
+			Kty: "EC",
+			Crv: key.Curve.Params().Name,
+			X:   base64UrlEncode(key.X.FillBytes(x)),
+			Y:   base64UrlEncode(key.Y.FillBytes(y)),
+		}
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64UrlEncode(key),
+		}
+	default:
+		return JWK{}
+	}
+}
+
+// PublicKey はJWKの公開鍵と、その鍵で検証するアルゴリズムを返します。外部のIDプロバイダのJWKSの読み込みに使います。
+// ECはP-256、OKPはEd25519のみに対応します。
+func (k JWK) PublicKey() (crypto.PublicKey, string, error) {
+	var pub crypto.PublicKey
+	switch k.Kty {
+	case "RSA":
+		n, err := base64UrlDecode(k.N)
+		if err != nil || len(n) == 0 {
+			return nil, "", fmt.Errorf("invalid RSA modulus")
+		}
+		e, err := base64UrlDecode(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, "", fmt.Errorf("invalid RSA exponent")
+		}
+		pub = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	case "EC":
+		if k.Crv != elliptic.P256().Params().Name {
+			return nil, "", fmt.Errorf("%w: curve %s", ErrUnsupportedAlgorithm, k.Crv)
+		}
+		x, errX := base64UrlDecode(k.X)
+		y, errY := base64UrlDecode(k.Y)
+		if errX != nil || errY != nil || len(x) != es256CoordinateSize || len(y) != es256CoordinateSize {
+			return nil, "", fmt.Errorf("invalid EC coordinates")
+		}
+		// 曲線上の点かどうかはcrypto/ecdhで検証する
+		point := append(append([]byte{4}, x...), y...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, "", fmt.Errorf("invalid EC public key: %w", err)
+		}
+		pub = &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	case "OKP":
+		x, err := base64UrlDecode(k.X)
+		if k.Crv != "Ed25519" || err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, "", fmt.Errorf("%w: OKP curve %s", ErrUnsupportedAlgorithm, k.Crv)
+		}
+		pub = ed25519.PublicKey(x)
+	default:
+		return nil, "", fmt.Errorf("%w: key type %s", ErrUnsupportedAlgorithm, k.Kty)
+	}
+
+	alg, err := algorithmForKey(pub)
+	if err != nil {
+		return nil, "", err
+	}
+	if k.Alg != "" && k.Alg != alg {
+		return nil, "", fmt.Errorf("%w: %s for %s key", ErrUnsupportedAlgorithm, k.Alg, k.Kty)
+	}
+	return pub, alg, nil
+}
+
+// keyID はRFC 7638のJWK Thumbprintを返します。必須メンバーのみを辞書順に並べたJSONのSHA-256です。
+// JSONのフィールドはomitemptyで鍵の種類ごとの必須メンバーだけになり、構造体の定義順が辞書順になっています。
+func keyID(pub crypto.PublicKey) string {
+	jwk := publicJWK(pub)
+	thumbprint, _ := json.Marshal(struct {
+		Crv string `json:"crv,omitempty"`
+		E   string `json:"e,omitempty"`
+		Kty string `json:"kty"`
+		N   string `json:"n,omitempty"`
+		X   string `json:"x,omitempty"`
+		Y   string `json:"y,omitempty"`
+	}{
+		Crv: jwk.Crv,
+		E:   jwk.E,
+		Kty: jwk.Kty,
+		N:   jwk.N,
+		X:   jwk.X,
+		Y:   jwk.Y,
+	})
+	hashed := sha256.Sum256(thumbprint)
+	return base64UrlEncode(hashed[:])
+}
+
+// loadPrivateKeyFromFile はPKCS #8の秘密鍵(RSA・ECDSA・Ed25519)を読み込みます。ECDSAはSEC 1形式も読み込めます。
+func loadPrivateKeyFromFile(filename string) (crypto.Signer, error) {
+	// ファイルから秘密鍵をバイトスライスとして読み込む
+	keyBytes, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the key file: %w", err)
+	}
+
+	// PEMエンコードされたデータからPEMブロックをデコード
+	block, _ := pem.Decode(keyBytes)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block containing the key")
+	}
+
+	// PEMブロックから秘密鍵をパース
+	var privInterface any
+	switch block.Type {
+	case "PRIVATE KEY", "RSA PRIVATE KEY":
+		privInterface, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		privInterface, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("failed to decode PEM block containing the key")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	privKey, ok := privInterface.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", privInterface)
+	}
+
+	return privKey, nil
+}
+
+// loadPublicKeysFromFile はファイル内のすべての公開鍵のPEMブロックを読み込みます。
+func loadPublicKeysFromFile(filename string) ([]crypto.PublicKey, error) {
+	// ファイルから公開鍵をバイトスライスとして読み込む
+	keyBytes, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the key file: %w", err)
+	}
+
+	var keys []crypto.PublicKey
+	for {
+		// PEMエンコードされたデータからPEMブロックをデコード
+		var block *pem.Block
+		block, keyBytes = pem.Decode(keyBytes)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			return nil, fmt.Errorf("failed to decode PEM block containing the key")
+		}
+
+		// PEMブロックから公開鍵をパース
+		var pubKey crypto.PublicKey
+		pubKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key: %w", err)
+		}
+		keys = append(keys, pubKey)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("failed to decode PEM block containing the key")
+	}
+
+	return keys, nil
+}
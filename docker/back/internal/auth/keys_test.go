@@ -0,0 +1,311 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testKeyBits = 2048
+
+func writeTestKeys(t *testing.T, dir string, signingKey crypto.Signer, publicKeys ...crypto.PublicKey) (string, string) {
+	t.Helper()
+	privBytes, err := x509.MarshalPKCS8PrivateKey(signingKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKeyPath := filepath.Join(dir, "private_key.pem")
+	privPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privBytes})
+	if err = os.WriteFile(privateKeyPath, privPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var pubPEM []byte
+	for _, key := range publicKeys {
+		var pubBytes []byte
+		pubBytes, err = x509.MarshalPKIXPublicKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubPEM = append(pubPEM, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubBytes})...)
+	}
+	publicKeyPath := filepath.Join(dir, "public_key.pem")
+	if err = os.WriteFile(publicKeyPath, pubPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return privateKeyPath, publicKeyPath
+}
+
+func generateTestKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, testKeyBits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// generateTestSigner はalgの署名に使う秘密鍵を生成します。
+func generateTestSigner(t *testing.T, alg string) crypto.Signer {
+	t.Helper()
+	var (
+		key crypto.Signer
+		err error
+	)
+	switch alg {
+	case AlgRS256:
+		key, err = rsa.GenerateKey(rand.Reader, testKeyBits)
+	case AlgES256:
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgEdDSA:
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		t.Fatalf("unknown alg %v", alg)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestKeyManager_Reload(t *testing.T) {
+	dir := t.TempDir()
+	oldKey := generateTestKey(t)
+	newKey := generateTestKey(t)
+	oldKID, newKID := keyID(&oldKey.PublicKey), keyID(&newKey.PublicKey)
+
+	// ローテーション前に新しい公開鍵を追加しておく
+	privateKeyPath, publicKeyPath := writeTestKeys(t, dir, oldKey, &oldKey.PublicKey, &newKey.PublicKey)
+	km, err := NewKeyManager(KeyConfig{PrivateKeyPath: privateKeyPath, PublicKeyPath: publicKeyPath})
+	if err != nil {
+		t.Fatalf("NewKeyManager() error = %v", err)
+	}
+	if got := km.SigningKeyID(); got != oldKID {
+		t.Errorf("SigningKeyID() = %v, want %v", got, oldKID)
+	}
+	if _, _, err = km.PublicKey(newKID); err != nil {
+		t.Errorf("PublicKey(new) error = %v", err)
+	}
+
+	// 新しい鍵に切り替え、古い公開鍵はファイルから削除する
+	rotatedAt := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return rotatedAt }
+	t.Cleanup(func() { timeNow = time.Now })
+	writeTestKeys(t, dir, newKey, &newKey.PublicKey)
+	if err = km.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got := km.SigningKeyID(); got != newKID {
+		t.Errorf("SigningKeyID() = %v, want %v", got, newKID)
+	}
+	if got := km.JWKS(); len(got.Keys) != 2 {
+		t.Errorf("JWKS() = %+v, want old and new keys", got)
+	}
+
+	// 古い鍵で署名したトークンが期限切れになるまでは検証できる
+	timeNow = func() time.Time { return rotatedAt.Add(AccessTokenTTL - time.Second) }
+	if _, _, err = km.PublicKey(oldKID); err != nil {
+		t.Errorf("PublicKey(old) error = %v", err)
+	}
+	timeNow = func() time.Time { return rotatedAt.Add(AccessTokenTTL) }
+	if _, _, err = km.PublicKey(oldKID); !errors.Is(err, ErrUnknownKeyID) {
+		t.Errorf("PublicKey(old) error = %v, want %v", err, ErrUnknownKeyID)
+	}
+	if got := km.JWKS(); len(got.Keys) != 1 || got.Keys[0].Kid != newKID {
+		t.Errorf("JWKS() = %+v, want only new key", got)
+	}
+
+	// kidがない場合は署名鍵で検証する
+	key, alg, err := km.PublicKey("")
+	if err != nil || !newKey.PublicKey.Equal(key) || alg != AlgRS256 {
+		t.Errorf("PublicKey(\"\") = %v, %v, %v", key, alg, err)
+	}
+}
+
+func TestKeyManager_ReloadFailure(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	key := generateTestKey(t)
+	privateKeyPath, publicKeyPath := writeTestKeys(t, dir, key, &key.PublicKey)
+	km, err := NewKeyManager(KeyConfig{PrivateKeyPath: privateKeyPath, PublicKeyPath: publicKeyPath})
+	if err != nil {
+		t.Fatalf("NewKeyManager() error = %v", err)
+	}
+
+	// 書き込み途中の壊れたファイルでは現在の鍵を使い続ける
+	if err = os.WriteFile(publicKeyPath, []byte("-----BEGIN PUBLIC"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err = km.Reload(); err == nil {
+		t.Error("Reload() error = nil, want error")
+	}
+	if got := km.SigningKeyID(); got != keyID(&key.PublicKey) {
+		t.Errorf("SigningKeyID() = %v, want %v", got, keyID(&key.PublicKey))
+	}
+
+	missing := KeyConfig{PrivateKeyPath: filepath.Join(dir, "missing.pem"), PublicKeyPath: publicKeyPath}
+	if _, err = NewKeyManager(missing); err == nil {
+		t.Error("NewKeyManager() error = nil, want error for missing key")
+	}
+}
+
+func TestKeyManager_modified(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	key := generateTestKey(t)
+	privateKeyPath, publicKeyPath := writeTestKeys(t, dir, key, &key.PublicKey)
+	km, err := NewKeyManager(KeyConfig{PrivateKeyPath: privateKeyPath, PublicKeyPath: publicKeyPath})
+	if err != nil {
+		t.Fatalf("NewKeyManager() error = %v", err)
+	}
+	if km.modified() {
+		t.Error("modified() = true before any change")
+	}
+
+	later := time.Now().Add(time.Minute)
+	if err = os.Chtimes(privateKeyPath, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if !km.modified() {
+		t.Error("modified() = false after key file changed")
+	}
+}
+
+func Test_keyID(t *testing.T) {
+	t.Parallel()
+
+	// RFC 7638 3.1 の例
+	n, _ := base64UrlDecode("0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECP" +
+		"ebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb" +
+		"9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFC" +
+		"ur-kEgU8awapJzKnqDKgw")
+	key := &rsa.PublicKey{E: 65537}
+	key.N = new(big.Int).SetBytes(n)
+
+	if got, want := keyID(key), "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"; got != want {
+		t.Errorf("keyID() = %v, want %v", got, want)
+	}
+}
+
+func TestNewKeyManager_Algorithms(t *testing.T) {
+	t.Parallel()
+
+	rsaKey := generateTestSigner(t, AlgRS256)
+	ecKey := generateTestSigner(t, AlgES256)
+	edKey := generateTestSigner(t, AlgEdDSA)
+
+	patterns := []struct {
+		name       string
+		signingKey crypto.Signer
+		config     KeyConfig
+		wantErr    bool
+	}{
+		{
+			name:       "RS256 by default",
+			signingKey: rsaKey,
+		},
+		{
+			name:       "ES256",
+			signingKey: ecKey,
+			config:     KeyConfig{SigningAlg: AlgES256},
+		},
+		{
+			name:       "EdDSA with RS256 allowed during migration",
+			signingKey: edKey,
+			config:     KeyConfig{SigningAlg: AlgEdDSA, AllowedAlgs: []string{AlgEdDSA, AlgRS256}},
+		},
+		{
+			name:       "Fail: key does not match signing algorithm",
+			signingKey: rsaKey,
+			config:     KeyConfig{SigningAlg: AlgES256},
+			wantErr:    true,
+		},
+		{
+			name:       "Fail: signing algorithm is not allowed",
+			signingKey: ecKey,
+			config:     KeyConfig{SigningAlg: AlgES256, AllowedAlgs: []string{AlgRS256}},
+			wantErr:    true,
+		},
+		{
+			name:       "Fail: issued typ is not allowed",
+			signingKey: rsaKey,
+			config:     KeyConfig{AllowedTyps: []string{"at+jwt"}},
+			wantErr:    true,
+		},
+		{
+			name:       "Fail: unsupported algorithm",
+			signingKey: rsaKey,
+			config:     KeyConfig{AllowedAlgs: []string{AlgRS256, "none"}},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+
+			config := tt.config
+			config.PrivateKeyPath, config.PublicKeyPath = writeTestKeys(t, t.TempDir(), tt.signingKey, tt.signingKey.Public())
+			km, err := NewKeyManager(config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewKeyManager() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			jwks := km.JWKS()
+			wantAlg, _ := algorithmForKey(tt.signingKey.Public())
+			if len(jwks.Keys) != 1 || jwks.Keys[0].Alg != wantAlg || jwks.Keys[0].Kid != km.SigningKeyID() {
+				t.Errorf("JWKS() = %+v, want %v key", jwks, wantAlg)
+			}
+		})
+	}
+}
+
+func TestJWK_PublicKey(t *testing.T) {
+	t.Parallel()
+
+	for _, alg := range []string{AlgRS256, AlgES256, AlgEdDSA} {
+		pub := generateTestSigner(t, alg).Public()
+		jwk := publicJWK(pub)
+		jwk.Alg = alg
+		got, gotAlg, err := jwk.PublicKey()
+		if err != nil {
+			t.Fatalf("PublicKey(%v) error = %v", alg, err)
+		}
+		if !pub.(interface{ Equal(crypto.PublicKey) bool }).Equal(got) || gotAlg != alg {
+			t.Errorf("PublicKey(%v) = %v, %v, want %v", alg, got, gotAlg, pub)
+		}
+	}
+
+	ecJWK := publicJWK(generateTestSigner(t, AlgES256).Public())
+	offCurve := ecJWK
+	offCurve.Y = ecJWK.X
+	mismatch := publicJWK(generateTestSigner(t, AlgRS256).Public())
+	mismatch.Alg = AlgES256
+	for name, jwk := range map[string]JWK{
+		"point not on curve": offCurve,
+		"alg does not match": mismatch,
+		"unsupported kty":    {Kty: "oct"},
+		"unsupported curve":  {Kty: "EC", Crv: "P-384", X: ecJWK.X, Y: ecJWK.Y},
+	} {
+		if _, _, err := jwk.PublicKey(); err == nil {
+			t.Errorf("PublicKey(%v) error = nil, want error", name)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// JWTの署名アルゴリズム(RFC 7518・RFC 8037)です。
+const (
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgEdDSA = "EdDSA"
+)
+
+// es256CoordinateSize はP-256の座標と署名のr・sのバイト数です。
+const es256CoordinateSize = 32
+
+var (
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+	ErrInvalidSignature     = errors.New("invalid signature")
+)
+
+// SigningMethod はJWTの署名と検証を行います。keyとpubの型はアルゴリズムごとに異なり、
+// RS256は*rsa、ES256は*ecdsa(P-256)、EdDSAはed25519の鍵です。
+type SigningMethod interface {
+	Alg() string
+	Sign(key crypto.Signer, data []byte) ([]byte, error)
+	Verify(pub crypto.PublicKey, data []byte, signature []byte) error
+}
+
+var signingMethods = map[string]SigningMethod{
+	AlgRS256: rs256{},
+	AlgES256: es256{},
+	AlgEdDSA: edDSA{},
+}
+
+// GetSigningMethod はalgの署名方式を返します。
+func GetSigningMethod(alg string) (SigningMethod, error) {
+	method, ok := signingMethods[alg]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, alg)
+	}
+	return method, nil
+}
+
+// algorithmForKey は公開鍵の種類に対応するアルゴリズムを返します。1つの鍵は1つのアルゴリズムでのみ使います。
+func algorithmForKey(pub crypto.PublicKey) (string, error) {
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		return AlgRS256, nil
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return "", fmt.Errorf("%w: curve %s", ErrUnsupportedAlgorithm, key.Curve.Params().Name)
+		}
+		return AlgES256, nil
+	case ed25519.PublicKey:
+		return AlgEdDSA, nil
+	default:
+		return "", fmt.Errorf("%w: key type %T", ErrUnsupportedAlgorithm, pub)
+	}
+}
+
+type rs256 struct{}
+
+func (rs256) Alg() string { return AlgRS256 }
+
+func (rs256) Sign(key crypto.Signer, data []byte) ([]byte, error) {
+	privKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("not RSA private key")
+	}
+	hashed := sha256.Sum256(data)
+	return rsa.SignPKCS1v15(rand.Reader, privKey, crypto.SHA256, hashed[:])
+}
+
+func (rs256) Verify(pub crypto.PublicKey, data []byte, signature []byte) error {
+	pubKey, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("not RSA public key")
+	}
+	hashed := sha256.Sum256(data)
+	if err := rsa.VerifyPKCS1v15(pubKey, crypto.SHA256, hashed[:], signature); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	return nil
+}
+
+// es256 の署名はASN.1ではなく、JWSの仕様どおりr・sを32バイトずつ連結した64バイトです。
+type es256 struct{}
+
+func (es256) Alg() string { return AlgES256 }
+
+func (es256) Sign(key crypto.Signer, data []byte) ([]byte, error) {
+	privKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("not ECDSA private key")
+	}
+	hashed := sha256.Sum256(data)
+	r, s, err := ecdsa.Sign(rand.Reader, privKey, hashed[:])
+	if err != nil {
+		return nil, err
+	}
+	signature := make([]byte, 2*es256CoordinateSize)
+	r.FillBytes(signature[:es256CoordinateSize])
+	s.FillBytes(signature[es256CoordinateSize:])
+	return signature, nil
+}
+
+func (es256) Verify(pub crypto.PublicKey, data []byte, signature []byte) error {
+	pubKey, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("not ECDSA public key")
+	}
+	if len(signature) != 2*es256CoordinateSize {
+		return ErrInvalidSignature
+	}
+	r := new(big.Int).SetBytes(signature[:es256CoordinateSize])
+	s := new(big.Int).SetBytes(signature[es256CoordinateSize:])
+	hashed := sha256.Sum256(data)
+	if !ecdsa.Verify(pubKey, hashed[:], r, s) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// edDSA はEd25519で署名します。Ed25519は内部でハッシュを計算するため、データをそのまま署名します。
+type edDSA struct{}
+
+func (edDSA) Alg() string { return AlgEdDSA }
+
+func (edDSA) Sign(key crypto.Signer, data []byte) ([]byte, error) {
+	privKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("not Ed25519 private key")
+	}
+	return ed25519.Sign(privKey, data), nil
+}
+
+func (edDSA) Verify(pub crypto.PublicKey, data []byte, signature []byte) error {
+	pubKey, ok := pub.(ed25519.PublicKey)
+	if !ok {
+		return fmt.Errorf("not Ed25519 public key")
+	}
+	if !ed25519.Verify(pubKey, data, signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSigningMethod_SignAndVerify(t *testing.T) {
+	t.Parallel()
+
+	data := []byte("eyJhbGciOiJFUzI1NiJ9.eyJ1c2VySWQiOiJ0ZXN0In0")
+	for _, alg := range []string{AlgRS256, AlgES256, AlgEdDSA} {
+		alg := alg
+		t.Run(alg, func(t *testing.T) {
+			t.Parallel()
+
+			method, err := GetSigningMethod(alg)
+			if err != nil {
+				t.Fatalf("GetSigningMethod() error = %v", err)
+			}
+			key := generateTestSigner(t, alg)
+			signature, err := method.Sign(key, data)
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			if err = method.Verify(key.Public(), data, signature); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+
+			// 改ざんされたデータや別の鍵では検証に失敗する
+			if err = method.Verify(key.Public(), append([]byte("x"), data...), signature); err == nil {
+				t.Error("Verify() with tampered data error = nil")
+			}
+			if err = method.Verify(generateTestSigner(t, alg).Public(), data, signature); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Verify() with other key error = %v, want %v", err, ErrInvalidSignature)
+			}
+		})
+	}
+}
+
+func TestSigningMethod_ES256SignatureSize(t *testing.T) {
+	t.Parallel()
+
+	// JWSのES256の署名はASN.1ではなくr||sの64バイト
+	signature, err := es256{}.Sign(generateTestSigner(t, AlgES256), []byte("data"))
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if len(signature) != 2*es256CoordinateSize {
+		t.Errorf("len(Sign()) = %v, want %v", len(signature), 2*es256CoordinateSize)
+	}
+}
+
+func TestGetSigningMethod(t *testing.T) {
+	t.Parallel()
+
+	for _, alg := range []string{"none", "HS256", "rs256"} {
+		if _, err := GetSigningMethod(alg); !errors.Is(err, ErrUnsupportedAlgorithm) {
+			t.Errorf("GetSigningMethod(%q) error = %v, want %v", alg, err, ErrUnsupportedAlgorithm)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rfc6238Secret はRFC 6238の付録Bのテストベクトルで使われるSHA-1の秘密鍵"12345678901234567890"です。
+var rfc6238Secret = totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func Test_TOTPCode(t *testing.T) {
+	t.Parallel()
+	// RFC 6238の8桁の値の下6桁
+	patterns := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+		{unix: 20000000000, want: "353130"},
+	}
+
+	for _, tt := range patterns {
+		got, err := TOTPCode(rfc6238Secret, time.Unix(tt.unix, 0))
+		require.NoError(t, err)
+		if got != tt.want {
+			t.Errorf("TOTPCode(%d) = %v, want %v", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func Test_ValidateTOTP(t *testing.T) {
+	t.Parallel()
+	now := time.Unix(1111111111, 0)
+	current, _ := TOTPCode(rfc6238Secret, now)
+	previous, _ := TOTPCode(rfc6238Secret, now.Add(-TOTPPeriod))
+	tooOld, _ := TOTPCode(rfc6238Secret, now.Add(-2*TOTPPeriod))
+
+	patterns := []struct {
+		name        string
+		secret      string
+		code        string
+		wantOK      bool
+		wantCounter int64
+	}{
+		{
+			name:        "current step",
+			secret:      rfc6238Secret,
+			code:        current,
+			wantOK:      true,
+			wantCounter: now.Unix() / 30,
+		},
+		{
+			name:        "previous step within skew",
+			secret:      rfc6238Secret,
+			code:        previous,
+			wantOK:      true,
+			wantCounter: now.Unix()/30 - 1,
+		},
+		{
+			name:   "lowercase secret with spaces",
+			secret: strings.ToLower(rfc6238Secret[:4] + " " + rfc6238Secret[4:]),
+			code:   current,
+			wantOK: true,
+			// 時間ステップは秘密鍵の表記に依らない
+			wantCounter: now.Unix() / 30,
+		},
+		{
+			name:   "outside skew",
+			secret: rfc6238Secret,
+			code:   tooOld,
+		},
+		{
+			name:   "wrong length",
+			secret: rfc6238Secret,
+			code:   current[:5],
+		},
+		{
+			name:   "invalid secret",
+			secret: "not base32!",
+			code:   current,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			counter, ok := ValidateTOTP(tt.secret, tt.code, now)
+			if ok != tt.wantOK {
+				t.Fatalf("ValidateTOTP() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && counter != tt.wantCounter {
+				t.Errorf("ValidateTOTP() counter = %v, want %v", counter, tt.wantCounter)
+			}
+		})
+	}
+}
+
+func Test_GenerateTOTPSecret(t *testing.T) {
+	t.Parallel()
+	secret, err := GenerateTOTPSecret()
+	require.NoError(t, err)
+	key, err := decodeTOTPSecret(secret)
+	require.NoError(t, err)
+	if len(key) != totpSecretBytes {
+		t.Errorf("len(key) = %v, want %v", len(key), totpSecretBytes)
+	}
+}
+
+func Test_TOTPProvisioningURI(t *testing.T) {
+	t.Parallel()
+	got := TOTPProvisioningURI("Camp Finder", "user@example.com", "JBSWY3DPEHPK3PXP")
+	want := "otpauth://totp/Camp%20Finder:user@example.com" +
+		"?algorithm=SHA1&digits=6&issuer=Camp+Finder&period=30&secret=JBSWY3DPEHPK3PXP"
+	if got != want {
+		t.Errorf("TOTPProvisioningURI() = %v, want %v", got, want)
+	}
+}
+
+func Test_RecoveryCodes(t *testing.T) {
+	t.Parallel()
+	codes, err := GenerateRecoveryCodes()
+	require.NoError(t, err)
+	if len(codes) != RecoveryCodeCount {
+		t.Fatalf("len(codes) = %v, want %v", len(codes), RecoveryCodeCount)
+	}
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		if len(code) != recoveryCodeLength+1 || code[recoveryCodeLength/2] != '-' {
+			t.Errorf("unexpected recovery code format: %v", code)
+		}
+		if IsTOTPCodeFormat(code) {
+			t.Errorf("recovery code %v must not look like a TOTP code", code)
+		}
+		seen[code] = true
+	}
+	if len(seen) != len(codes) {
+		t.Errorf("recovery codes are not unique: %v", codes)
+	}
+
+	// 区切りや大文字・小文字が違っても同じコードとして扱う
+	code := codes[0]
+	variant := strings.ToUpper(strings.ReplaceAll(code, "-", ""))
+	if HashRecoveryCode(code) != HashRecoveryCode(variant) {
+		t.Errorf("HashRecoveryCode(%v) != HashRecoveryCode(%v)", code, variant)
+	}
+}
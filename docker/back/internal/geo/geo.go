@@ -0,0 +1,75 @@
+package geo
+
+import (
+	"math"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+)
+
+const (
+	EarthRadiusKm = 6371.0
+
+	MinLat = -90.0
+	MaxLat = 90.0
+	MinLng = -180.0
+	MaxLng = 180.0
+
+	degreesPerRadian = 180 / math.Pi
+)
+
+func toRadians(deg float64) float64 {
+	return deg / degreesPerRadian
+}
+
+// Distance は2点間の大円距離(km)をハバーサイン公式で計算します。
+func Distance(lat1, lng1, lat2, lng2 float64) float64 {
+	dLat := toRadians(lat2 - lat1)
+	dLng := toRadians(lng2 - lng1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// BoundsAround は中心点から半径radiusKmの円を囲む矩形を返します。
+func BoundsAround(lat, lng, radiusKm float64) model.BoundingBox {
+	dLat := radiusKm / EarthRadiusKm * degreesPerRadian
+
+	// 極付近では経度方向の幅が発散するため、全経度を対象にする
+	cosLat := math.Cos(toRadians(lat))
+	dLng := MaxLng
+	if cosLat > 0 {
+		dLng = math.Min(MaxLng, dLat/cosLat)
+	}
+
+	return model.BoundingBox{
+		MinLat: math.Max(MinLat, lat-dLat),
+		MinLng: math.Max(MinLng, lng-dLng),
+		MaxLat: math.Min(MaxLat, lat+dLat),
+		MaxLng: math.Min(MaxLng, lng+dLng),
+	}
+}
+
+// SpanKm は矩形の東西と南北の幅(km)を返します。東西の幅は赤道に最も近い緯度で測ります。
+func SpanKm(b model.BoundingBox) (float64, float64) {
+	lat := 0.0
+	if b.MinLat > 0 {
+		lat = b.MinLat
+	} else if b.MaxLat < 0 {
+		lat = b.MaxLat
+	}
+	widthKm := toRadians(b.MaxLng-b.MinLng) * EarthRadiusKm * math.Cos(toRadians(lat))
+	heightKm := toRadians(b.MaxLat-b.MinLat) * EarthRadiusKm
+	return widthKm, heightKm
+}
+
+func IsValidCoordinate(lat, lng float64) bool {
+	return lat >= MinLat && lat <= MaxLat && lng >= MinLng && lng <= MaxLng
+}
+
+func IsValidBoundingBox(b model.BoundingBox) bool {
+	return IsValidCoordinate(b.MinLat, b.MinLng) &&
+		IsValidCoordinate(b.MaxLat, b.MaxLng) &&
+		b.MinLat <= b.MaxLat &&
+		b.MinLng <= b.MaxLng
+}
@@ -0,0 +1,135 @@
+package geo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+)
+
+func Test_Distance(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name   string
+		lat1   float64
+		lng1   float64
+		lat2   float64
+		lng2   float64
+		wantKm float64
+	}{
+		{
+			name:   "same point",
+			lat1:   43.7172721,
+			lng1:   142.6674615,
+			lat2:   43.7172721,
+			lng2:   142.6674615,
+			wantKm: 0,
+		},
+		{
+			name:   "tokyo to osaka",
+			lat1:   35.681236,
+			lng1:   139.767125,
+			lat2:   34.702485,
+			lng2:   135.495951,
+			wantKm: 403.5,
+		},
+		{
+			name:   "one degree of latitude",
+			lat1:   0,
+			lng1:   0,
+			lat2:   1,
+			lng2:   0,
+			wantKm: 111.19,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := Distance(tt.lat1, tt.lng1, tt.lat2, tt.lng2)
+			if math.Abs(got-tt.wantKm) > 0.5 {
+				t.Errorf("Distance() = %v, want %v", got, tt.wantKm)
+			}
+		})
+	}
+}
+
+func Test_BoundsAround(t *testing.T) {
+	t.Parallel()
+
+	lat, lng, radiusKm := 43.7172721, 142.6674615, 10.0
+	bounds := BoundsAround(lat, lng, radiusKm)
+
+	// 矩形の各辺の中点は中心からradiusKmの距離にある
+	edges := [][2]float64{
+		{bounds.MinLat, lng},
+		{bounds.MaxLat, lng},
+		{lat, bounds.MinLng},
+		{lat, bounds.MaxLng},
+	}
+	for _, e := range edges {
+		if d := Distance(lat, lng, e[0], e[1]); d < radiusKm-0.1 {
+			t.Errorf("BoundsAround() edge %v is %vkm from center, want >= %vkm", e, d, radiusKm)
+		}
+	}
+
+	if got := BoundsAround(89.99, 0, 100); got.MinLng != MinLng || got.MaxLng != MaxLng || got.MaxLat != MaxLat {
+		t.Errorf("BoundsAround() near pole = %+v, want whole longitude range", got)
+	}
+}
+
+func Test_SpanKm(t *testing.T) {
+	t.Parallel()
+
+	// 南北の幅は緯度1度あたり約111km
+	width, height := SpanKm(model.BoundingBox{MinLat: 43, MinLng: 142, MaxLat: 44, MaxLng: 143})
+	if math.Abs(height-111.2) > 0.5 || math.Abs(width-81.3) > 0.5 {
+		t.Errorf("SpanKm() = %v, %v, want about 81.3, 111.2", width, height)
+	}
+
+	// 赤道をまたぐ場合は赤道上で東西の幅を測る
+	width, _ = SpanKm(model.BoundingBox{MinLat: -10, MinLng: 0, MaxLat: 10, MaxLng: 1})
+	if math.Abs(width-111.2) > 0.5 {
+		t.Errorf("SpanKm() width across equator = %v, want about 111.2", width)
+	}
+}
+
+func Test_IsValidBoundingBox(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name   string
+		bounds model.BoundingBox
+		want   bool
+	}{
+		{
+			name:   "valid",
+			bounds: model.BoundingBox{MinLat: 43, MinLng: 142, MaxLat: 44, MaxLng: 143},
+			want:   true,
+		},
+		{
+			name:   "min greater than max",
+			bounds: model.BoundingBox{MinLat: 44, MinLng: 142, MaxLat: 43, MaxLng: 143},
+			want:   false,
+		},
+		{
+			name:   "out of range",
+			bounds: model.BoundingBox{MinLat: -91, MinLng: 142, MaxLat: 43, MaxLng: 143},
+			want:   false,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := IsValidBoundingBox(tt.bounds); got != tt.want {
+				t.Errorf("IsValidBoundingBox() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
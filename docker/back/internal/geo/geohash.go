@@ -0,0 +1,97 @@
+package geo
+
+import (
+	"math"
+	"strings"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+)
+
+const (
+	// GeohashPrecision はSpotテーブルに保存するgeohashの桁数です(約5m四方)。
+	GeohashPrecision = 9
+
+	geohashBase32      = "0123456789bcdefghjkmnpqrstuvwxyz"
+	geohashBitsPerChar = 5
+)
+
+// EncodeGeohash は緯度経度を指定桁数のgeohashに変換します。
+func EncodeGeohash(lat, lng float64, precision int) string {
+	latRange := [2]float64{MinLat, MaxLat}
+	lngRange := [2]float64{MinLng, MaxLng}
+
+	var sb strings.Builder
+	var ch, bit int
+	evenBit := true
+	for sb.Len() < precision {
+		if evenBit {
+			mid := (lngRange[0] + lngRange[1]) / 2
+			if lng >= mid {
+				ch = ch<<1 | 1
+				lngRange[0] = mid
+			} else {
+				ch <<= 1
+				lngRange[1] = mid
+			}
+		} else {
+			mid := (latRange[0] + latRange[1]) / 2
+			if lat >= mid {
+				ch = ch<<1 | 1
+				latRange[0] = mid
+			} else {
+				ch <<= 1
+				latRange[1] = mid
+			}
+		}
+		evenBit = !evenBit
+
+		bit++
+		if bit == geohashBitsPerChar {
+			sb.WriteByte(geohashBase32[ch])
+			bit, ch = 0, 0
+		}
+	}
+	return sb.String()
+}
+
+// geohashCellSize は指定桁数のgeohashセル1つの幅と高さ(度)を返します。
+func geohashCellSize(precision int) (float64, float64) {
+	bits := precision * geohashBitsPerChar
+	lngBits := (bits + 1) / 2
+	latBits := bits / 2
+	return (MaxLng - MinLng) / math.Pow(2, float64(lngBits)), (MaxLat - MinLat) / math.Pow(2, float64(latBits))
+}
+
+// CoveringPrefixes は矩形を覆うgeohashのプレフィックス一覧を返します。
+// セルが矩形より大きくなる最大の桁数を選ぶため、矩形は高々2x2のセルに収まり、四隅のgeohashで覆えます。
+// 矩形が広すぎてプレフィックスで絞り込めない場合はnilを返します。
+func CoveringPrefixes(b model.BoundingBox) []string {
+	precision := 0
+	for p := GeohashPrecision; p > 0; p-- {
+		width, height := geohashCellSize(p)
+		if width >= b.MaxLng-b.MinLng && height >= b.MaxLat-b.MinLat {
+			precision = p
+			break
+		}
+	}
+	if precision == 0 {
+		return nil
+	}
+
+	corners := [][2]float64{
+		{b.MinLat, b.MinLng},
+		{b.MinLat, b.MaxLng},
+		{b.MaxLat, b.MinLng},
+		{b.MaxLat, b.MaxLng},
+	}
+	seen := make(map[string]bool, len(corners))
+	var prefixes []string
+	for _, c := range corners {
+		prefix := EncodeGeohash(c[0], c[1], precision)
+		if !seen[prefix] {
+			seen[prefix] = true
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
@@ -0,0 +1,102 @@
+package geo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+)
+
+func Test_EncodeGeohash(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name      string
+		lat       float64
+		lng       float64
+		precision int
+		want      string
+	}{
+		{
+			name:      "reference point",
+			lat:       57.64911,
+			lng:       10.40744,
+			precision: 11,
+			want:      "u4pruydqqvj",
+		},
+		{
+			name:      "asahikawa",
+			lat:       43.7172721,
+			lng:       142.6674615,
+			precision: GeohashPrecision,
+			want:      "xpv2wqrr8",
+		},
+		{
+			name:      "short precision",
+			lat:       43.7172721,
+			lng:       142.6674615,
+			precision: 3,
+			want:      "xpv",
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := EncodeGeohash(tt.lat, tt.lng, tt.precision); got != tt.want {
+				t.Errorf("EncodeGeohash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_CoveringPrefixes(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name   string
+		bounds model.BoundingBox
+		points [][2]float64
+		want   int
+	}{
+		{
+			name:   "small box",
+			bounds: BoundsAround(43.7172721, 142.6674615, 5),
+			points: [][2]float64{{43.7172721, 142.6674615}, {43.74, 142.70}, {43.69, 142.63}},
+			want:   4,
+		},
+		{
+			name:   "whole world",
+			bounds: model.BoundingBox{MinLat: MinLat, MinLng: MinLng, MaxLat: MaxLat, MaxLng: MaxLng},
+			want:   0,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			prefixes := CoveringPrefixes(tt.bounds)
+			if len(prefixes) > tt.want {
+				t.Fatalf("CoveringPrefixes() returned %d prefixes, want at most %d", len(prefixes), tt.want)
+			}
+
+			// 矩形内の点は必ずいずれかのプレフィックスに一致する
+			for _, p := range tt.points {
+				hash := EncodeGeohash(p[0], p[1], GeohashPrecision)
+				covered := false
+				for _, prefix := range prefixes {
+					if strings.HasPrefix(hash, prefix) {
+						covered = true
+					}
+				}
+				if !covered {
+					t.Errorf("CoveringPrefixes() = %v does not cover %v (%v)", prefixes, p, hash)
+				}
+			}
+		})
+	}
+}
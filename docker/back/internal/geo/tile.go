@@ -0,0 +1,50 @@
+package geo
+
+import (
+	"math"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+)
+
+const (
+	// MaxTileZoom はタイルとして受け付ける最大のズームレベルです。
+	MaxTileZoom = 22
+	// TileSize はWebメルカトルのタイル1枚のピクセル数です。
+	TileSize = 256
+)
+
+// IsValidTile はズームレベルzのタイル座標x, yが範囲内かを返します。
+func IsValidTile(z, x, y int) bool {
+	if z < 0 || z > MaxTileZoom {
+		return false
+	}
+	n := 1 << z
+	return x >= 0 && x < n && y >= 0 && y < n
+}
+
+// TileBounds はWebメルカトルのタイル(z/x/y)が覆う範囲を緯度経度で返します。
+func TileBounds(z, x, y int) model.BoundingBox {
+	n := float64(int(1) << z)
+	return model.BoundingBox{
+		MinLat: tileLat(float64(y+1), n),
+		MinLng: float64(x)/n*360 - MaxLng,
+		MaxLat: tileLat(float64(y), n),
+		MaxLng: float64(x+1)/n*360 - MaxLng,
+	}
+}
+
+func tileLat(y, n float64) float64 {
+	return math.Atan(math.Sinh(math.Pi*(1-2*y/n))) * degreesPerRadian
+}
+
+// TilePixel は緯度経度をズームレベルzでの全体のピクセル座標に変換します。
+func TilePixel(lat, lng float64, z int) (float64, float64) {
+	worldSize := float64(TileSize * (int(1) << z))
+	sinLat := math.Sin(toRadians(lat))
+	// 極ではメルカトル図法の座標が発散するため、端のピクセルに収める
+	sinLat = math.Max(-0.9999, math.Min(0.9999, sinLat))
+
+	px := (lng + MaxLng) / 360 * worldSize
+	py := (0.5 - math.Log((1+sinLat)/(1-sinLat))/(4*math.Pi)) * worldSize
+	return px, py
+}
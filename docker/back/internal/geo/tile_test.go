@@ -0,0 +1,91 @@
+package geo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+)
+
+func Test_TileBounds(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name    string
+		z, x, y int
+		want    model.BoundingBox
+	}{
+		{
+			name: "world",
+			z:    0, x: 0, y: 0,
+			want: model.BoundingBox{MinLat: -85.0511287798, MinLng: -180, MaxLat: 85.0511287798, MaxLng: 180},
+		},
+		{
+			name: "north east quarter",
+			z:    1, x: 1, y: 0,
+			want: model.BoundingBox{MinLat: 0, MinLng: 0, MaxLat: 85.0511287798, MaxLng: 180},
+		},
+		{
+			name: "asahikawa",
+			z:    10, x: 917, y: 373,
+			want: model.BoundingBox{MinLat: 43.5803908556, MinLng: 142.3828125, MaxLat: 43.8345267822, MaxLng: 142.734375},
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := TileBounds(tt.z, tt.x, tt.y)
+			if math.Abs(got.MinLat-tt.want.MinLat) > 1e-6 ||
+				math.Abs(got.MinLng-tt.want.MinLng) > 1e-6 ||
+				math.Abs(got.MaxLat-tt.want.MaxLat) > 1e-6 ||
+				math.Abs(got.MaxLng-tt.want.MaxLng) > 1e-6 {
+				t.Errorf("TileBounds() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_TilePixel(t *testing.T) {
+	t.Parallel()
+
+	// タイルの内側の点は、そのタイルのピクセル範囲に変換される
+	px, py := TilePixel(43.7172721, 142.6674615, 10)
+	if int(px)/TileSize != 917 || int(py)/TileSize != 373 {
+		t.Errorf("TilePixel() = %v, %v, want in tile 917/373", px, py)
+	}
+
+	px, py = TilePixel(0, 0, 1)
+	if px != TileSize || math.Abs(py-TileSize) > 1e-9 {
+		t.Errorf("TilePixel() = %v, %v, want %v, %v", px, py, TileSize, TileSize)
+	}
+}
+
+func Test_IsValidTile(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name    string
+		z, x, y int
+		want    bool
+	}{
+		{name: "world", z: 0, x: 0, y: 0, want: true},
+		{name: "last tile", z: 3, x: 7, y: 7, want: true},
+		{name: "x out of range", z: 3, x: 8, y: 0, want: false},
+		{name: "negative y", z: 3, x: 0, y: -1, want: false},
+		{name: "zoom too deep", z: MaxTileZoom + 1, x: 0, y: 0, want: false},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := IsValidTile(tt.z, tt.x, tt.y); got != tt.want {
+				t.Errorf("IsValidTile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+// Package imaging はアップロードされた画像の検査と、公開前に取り除くべきメタデータの削除を行います。
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	_ "image/jpeg" // image.DecodeConfigでJPEGを扱うため
+	_ "image/png"  // image.DecodeConfigでPNGを扱うため
+	"net/http"
+)
+
+const (
+	ContentTypeJPEG = "image/jpeg"
+	ContentTypePNG  = "image/png"
+	// ContentTypeWebP は縮小版の形式で、アップロードは受け付けません。
+	ContentTypeWebP = "image/webp"
+)
+
+// MaxPixels はデコードする画像の最大の画素数です。小さなファイルで巨大な画像を展開させる攻撃を防ぎます。
+const MaxPixels = 50_000_000
+
+var (
+	ErrUnsupportedType = errors.New("unsupported image type")
+	ErrInvalidImage    = errors.New("invalid image")
+	ErrTooManyPixels   = errors.New("image has too many pixels")
+)
+
+// Extension はcontentTypeの画像を保存するときの拡張子を返します。
+func Extension(contentType string) string {
+	switch contentType {
+	case ContentTypeJPEG:
+		return ".jpg"
+	case ContentTypePNG:
+		return ".png"
+	default:
+		return ""
+	}
+}
+
+// Inspect はdataの中身から画像の形式を判定し、デコードできる画像かを確認してContent-Typeを返します。
+// クライアントが申告したContent-Typeや拡張子は信用しません。
+func Inspect(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	if Extension(contentType) == "" {
+		return "", ErrUnsupportedType
+	}
+	conf, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", ErrInvalidImage
+	}
+	if conf.Width*conf.Height > MaxPixels {
+		return "", ErrTooManyPixels
+	}
+	return contentType, nil
+}
+
+// StripGPS は撮影場所が漏れないよう、画像に埋め込まれた位置情報を取り除いたコピーを返します。
+// JPEGはExifのGPS IFDを消して向きなどの他のタグは残し、位置情報を含みうるXMPは丸ごと取り除きます。
+// PNGはeXIfチャンクとXMPのiTXtチャンクを取り除きます。
+func StripGPS(data []byte, contentType string) ([]byte, error) {
+	switch contentType {
+	case ContentTypeJPEG:
+		return stripJPEG(data)
+	case ContentTypePNG:
+		return stripPNG(data)
+	default:
+		return nil, ErrUnsupportedType
+	}
+}
+
+const (
+	markerSOI  = 0xD8
+	markerSOS  = 0xDA
+	markerAPP1 = 0xE1
+)
+
+var (
+	exifHeader = []byte("Exif\x00\x00")
+	xmpHeader  = []byte("http://ns.adobe.com/xap/1.0/\x00")
+)
+
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != markerSOI {
+		return nil, ErrInvalidImage
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:2]...)
+
+	pos := 2
+	for {
+		if pos+4 > len(data) || data[pos] != 0xFF {
+			return nil, ErrInvalidImage
+		}
+		marker := data[pos+1]
+		// 0xFFの詰め物は読み飛ばす
+		if marker == 0xFF {
+			pos++
+			continue
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, ErrInvalidImage
+		}
+		// SOS以降は圧縮された画像データなので、そのまま書き写す
+		if marker == markerSOS {
+			return append(out, data[pos:]...), nil
+		}
+
+		segment := data[pos:end]
+		payload := segment[4:]
+		if marker == markerAPP1 {
+			switch {
+			case bytes.HasPrefix(payload, xmpHeader):
+				pos = end
+				continue
+			case bytes.HasPrefix(payload, exifHeader):
+				segment = append([]byte(nil), segment...)
+				// 解釈できないExifは位置情報が残らないよう丸ごと取り除く
+				if err := stripExifGPS(segment[4+len(exifHeader):]); err != nil {
+					pos = end
+					continue
+				}
+			}
+		}
+		out = append(out, segment...)
+		pos = end
+	}
+}
+
+const (
+	tagGPSInfo = 0x8825
+	ifdEntry   = 12
+)
+
+// exifTypeSizes はTIFFのフィールドの型ごとの1要素のバイト数です。
+var exifTypeSizes = map[uint16]int{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8}
+
+// stripExifGPS はTIFF形式のExif本体tiffから、IFD0のGPS IFDへのポインタを削除し、GPS IFDとその値をゼロで埋めます。
+// 他のタグのオフセットがずれないよう、tiffの長さは変えません。
+func stripExifGPS(tiff []byte) error {
+	if len(tiff) < 8 {
+		return ErrInvalidImage
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return ErrInvalidImage
+	}
+
+	ifd0 := int(order.Uint32(tiff[4:]))
+	if ifd0 < 8 || ifd0+2 > len(tiff) {
+		return ErrInvalidImage
+	}
+	n := int(order.Uint16(tiff[ifd0:]))
+	// エントリの後ろに次のIFDへのオフセット(4バイト)が続く
+	ifdEnd := ifd0 + 2 + n*ifdEntry + 4
+	if ifdEnd > len(tiff) {
+		return ErrInvalidImage
+	}
+
+	for i := 0; i < n; i++ {
+		entry := ifd0 + 2 + i*ifdEntry
+		if order.Uint16(tiff[entry:]) != tagGPSInfo {
+			continue
+		}
+		gps := int(order.Uint32(tiff[entry+8:]))
+		copy(tiff[entry:], tiff[entry+ifdEntry:ifdEnd])
+		clear(tiff[ifdEnd-ifdEntry : ifdEnd])
+		order.PutUint16(tiff[ifd0:], uint16(n-1))
+		return clearIFD(tiff, gps, order)
+	}
+	return nil
+}
+
+// clearIFD はoffsetのIFDと、そこから参照される値をゼロで埋めます。
+func clearIFD(tiff []byte, offset int, order binary.ByteOrder) error {
+	if offset < 8 || offset+2 > len(tiff) {
+		return ErrInvalidImage
+	}
+	n := int(order.Uint16(tiff[offset:]))
+	end := offset + 2 + n*ifdEntry + 4
+	if end > len(tiff) {
+		return ErrInvalidImage
+	}
+	for i := 0; i < n; i++ {
+		entry := offset + 2 + i*ifdEntry
+		size := exifTypeSizes[order.Uint16(tiff[entry+2:])] * int(order.Uint32(tiff[entry+4:]))
+		// 4バイト以下の値はエントリ内に直接入っている
+		if size <= 4 {
+			continue
+		}
+		value := int(order.Uint32(tiff[entry+8:]))
+		if value < 0 || value+size > len(tiff) {
+			return ErrInvalidImage
+		}
+		clear(tiff[value : value+size])
+	}
+	clear(tiff[offset:end])
+	return nil
+}
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+const xmpKeyword = "XML:com.adobe.xmp\x00"
+
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, ErrInvalidImage
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+
+	pos := len(pngSignature)
+	for pos < len(data) {
+		// 長さ(4バイト)、種類(4バイト)、データ、CRC(4バイト)
+		if pos+12 > len(data) {
+			return nil, ErrInvalidImage
+		}
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, ErrInvalidImage
+		}
+		chunkType := string(data[pos+4 : pos+8])
+		chunkData := data[pos+8 : pos+8+length]
+		if binary.BigEndian.Uint32(data[end-4:]) != crc32.ChecksumIEEE(data[pos+4:pos+8+length]) {
+			return nil, ErrInvalidImage
+		}
+
+		switch {
+		case chunkType == "eXIf":
+		case chunkType == "iTXt" && bytes.HasPrefix(chunkData, []byte(xmpKeyword)):
+		default:
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+		if chunkType == "IEND" {
+			break
+		}
+	}
+	return out, nil
+}
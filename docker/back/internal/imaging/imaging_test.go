@@ -0,0 +1,226 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func testImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 6))
+	for x := 0; x < 8; x++ {
+		for y := 0; y < 6; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 30), G: uint8(y * 40), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodePNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage()); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// gpsLatitude はGPS IFDに入れる緯度(35/1, 39/1, 2917/100)で、削除後に残っていないことを確認するための値です。
+var gpsLatitude = []uint32{35, 1, 39, 1, 2917, 100}
+
+// exifWithGPS はIFD0に向き(Orientation)とGPS IFDへのポインタを持つ、リトルエンディアンのExifを組み立てます。
+func exifWithGPS() []byte {
+	le := binary.LittleEndian
+	tiff := make([]byte, 0, 128)
+	tiff = append(tiff, 'I', 'I', 42, 0, 8, 0, 0, 0)
+
+	// IFD0 (offset 8): 2エントリ + 次のIFDなし
+	const gpsIFD = 8 + 2 + 2*ifdEntry + 4
+	tiff = le.AppendUint16(tiff, 2)
+	tiff = le.AppendUint16(tiff, 0x0112) // Orientation
+	tiff = le.AppendUint16(tiff, 3)
+	tiff = le.AppendUint32(tiff, 1)
+	tiff = le.AppendUint32(tiff, 6)
+	tiff = le.AppendUint16(tiff, tagGPSInfo)
+	tiff = le.AppendUint16(tiff, 4)
+	tiff = le.AppendUint32(tiff, 1)
+	tiff = le.AppendUint32(tiff, gpsIFD)
+	tiff = le.AppendUint32(tiff, 0)
+
+	// GPS IFD: GPSLatitudeRef と GPSLatitude
+	const latitude = gpsIFD + 2 + 2*ifdEntry + 4
+	tiff = le.AppendUint16(tiff, 2)
+	tiff = le.AppendUint16(tiff, 0x0001)
+	tiff = le.AppendUint16(tiff, 2)
+	tiff = le.AppendUint32(tiff, 2)
+	tiff = append(tiff, 'N', 0, 0, 0)
+	tiff = le.AppendUint16(tiff, 0x0002)
+	tiff = le.AppendUint16(tiff, 5)
+	tiff = le.AppendUint32(tiff, 3)
+	tiff = le.AppendUint32(tiff, latitude)
+	tiff = le.AppendUint32(tiff, 0)
+	for _, v := range gpsLatitude {
+		tiff = le.AppendUint32(tiff, v)
+	}
+	return append(append([]byte(nil), exifHeader...), tiff...)
+}
+
+func app1(payload []byte) []byte {
+	seg := []byte{0xFF, markerAPP1}
+	seg = binary.BigEndian.AppendUint16(seg, uint16(len(payload)+2))
+	return append(seg, payload...)
+}
+
+func insertAfterSOI(data []byte, segments ...[]byte) []byte {
+	out := append([]byte(nil), data[:2]...)
+	for _, seg := range segments {
+		out = append(out, seg...)
+	}
+	return append(out, data[2:]...)
+}
+
+func pngChunk(chunkType string, data []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	chunk = append(chunk, chunkType...)
+	chunk = append(chunk, data...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+// insertAfterIHDR はシグネチャ(8バイト)とIHDRチャンク(25バイト)の後ろにチャンクを挿入します。
+func insertAfterIHDR(data []byte, chunks ...[]byte) []byte {
+	const ihdrEnd = 8 + 25
+	out := append([]byte(nil), data[:ihdrEnd]...)
+	for _, chunk := range chunks {
+		out = append(out, chunk...)
+	}
+	return append(out, data[ihdrEnd:]...)
+}
+
+func latitudeBytes() []byte {
+	var b []byte
+	for _, v := range gpsLatitude {
+		b = binary.LittleEndian.AppendUint32(b, v)
+	}
+	return b
+}
+
+func TestInspect(t *testing.T) {
+	jpg := encodeJPEG(t)
+	patterns := []struct {
+		name string
+		data []byte
+		want string
+		err  error
+	}{
+		{name: "jpeg", data: jpg, want: ContentTypeJPEG},
+		{name: "png", data: encodePNG(t), want: ContentTypePNG},
+		{name: "Fail: gif", data: []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;"), err: ErrUnsupportedType},
+		{name: "Fail: text", data: []byte("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"), err: ErrUnsupportedType},
+		{name: "Fail: truncated jpeg", data: jpg[:20], err: ErrInvalidImage},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Inspect(tt.data)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Inspect() error = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("Inspect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripGPS_JPEG(t *testing.T) {
+	xmp := append(append([]byte(nil), xmpHeader...), `<x:xmpmeta><exif:GPSLatitude>35,39.49N</exif:GPSLatitude></x:xmpmeta>`...)
+	data := insertAfterSOI(encodeJPEG(t), app1(exifWithGPS()), app1(xmp))
+
+	got, err := StripGPS(data, ContentTypeJPEG)
+	if err != nil {
+		t.Fatalf("StripGPS() error = %v", err)
+	}
+	if _, err = jpeg.Decode(bytes.NewReader(got)); err != nil {
+		t.Fatalf("stripped image cannot be decoded: %v", err)
+	}
+	if bytes.Contains(got, latitudeBytes()) || bytes.Contains(got, []byte("GPSLatitude")) {
+		t.Errorf("stripped image still contains GPS data")
+	}
+
+	// ExifのIFD0には向きのタグだけが残る
+	idx := bytes.Index(got, exifHeader)
+	if idx < 0 {
+		t.Fatalf("Exif segment was removed")
+	}
+	tiff := got[idx+len(exifHeader):]
+	if n := binary.LittleEndian.Uint16(tiff[8:]); n != 1 {
+		t.Errorf("IFD0 entries = %d, want 1", n)
+	}
+	if tag := binary.LittleEndian.Uint16(tiff[10:]); tag != 0x0112 {
+		t.Errorf("remaining tag = %#x, want Orientation", tag)
+	}
+	if len(got) != len(data)-len(app1(xmp)) {
+		t.Errorf("len = %d, want %d", len(got), len(data)-len(app1(xmp)))
+	}
+}
+
+func TestStripGPS_JPEGBrokenExif(t *testing.T) {
+	broken := append(append([]byte(nil), exifHeader...), "XX\x2a\x00"...)
+	data := insertAfterSOI(encodeJPEG(t), app1(broken))
+
+	got, err := StripGPS(data, ContentTypeJPEG)
+	if err != nil {
+		t.Fatalf("StripGPS() error = %v", err)
+	}
+	if bytes.Contains(got, exifHeader) {
+		t.Errorf("broken Exif segment should be removed")
+	}
+}
+
+func TestStripGPS_PNG(t *testing.T) {
+	xmp := append([]byte(xmpKeyword), "\x00\x00\x00\x00<exif:GPSLatitude>35,39.49N</exif:GPSLatitude>"...)
+	text := pngChunk("tEXt", []byte("Title\x00campfinder"))
+	data := insertAfterIHDR(encodePNG(t), pngChunk("eXIf", exifWithGPS()[len(exifHeader):]), pngChunk("iTXt", xmp), text)
+
+	got, err := StripGPS(data, ContentTypePNG)
+	if err != nil {
+		t.Fatalf("StripGPS() error = %v", err)
+	}
+	if _, err = png.Decode(bytes.NewReader(got)); err != nil {
+		t.Fatalf("stripped image cannot be decoded: %v", err)
+	}
+	if bytes.Contains(got, []byte("eXIf")) || bytes.Contains(got, []byte("GPSLatitude")) {
+		t.Errorf("stripped image still contains GPS data")
+	}
+	if !bytes.Contains(got, text) {
+		t.Errorf("unrelated chunks should be kept")
+	}
+}
+
+func TestStripGPS_Invalid(t *testing.T) {
+	if _, err := StripGPS([]byte("not an image"), ContentTypeJPEG); !errors.Is(err, ErrInvalidImage) {
+		t.Errorf("StripGPS() error = %v, want %v", err, ErrInvalidImage)
+	}
+	if _, err := StripGPS(encodePNG(t)[:40], ContentTypePNG); !errors.Is(err, ErrInvalidImage) {
+		t.Errorf("StripGPS() error = %v, want %v", err, ErrInvalidImage)
+	}
+	if _, err := StripGPS(encodePNG(t), "image/gif"); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("StripGPS() error = %v, want %v", err, ErrUnsupportedType)
+	}
+}
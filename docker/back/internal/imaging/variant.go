@@ -0,0 +1,180 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+	"image/jpeg"
+)
+
+// VariantQuality は縮小版をJPEGで書き出すときの品質です。
+const VariantQuality = 82
+
+// Decode はdataをデコードし、JPEGのExifの向き(Orientation)を反映した画像を返します。
+// スマートフォンの写真は縦向きでも横長のまま保存され、向きはExifでのみ指定されていることが多いためです。
+func Decode(data []byte) (*image.RGBA, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	b := src.Bounds()
+	img := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(img, img.Bounds(), src, b.Min, draw.Src)
+	return orient(img, jpegOrientation(data)), nil
+}
+
+// Resize はimgを縦横比を保ったまま、長辺がmaxSize以下になるよう縮小します。拡大はしません。
+// 縮小元の画素の平均を取る(エリア平均)ため、大きく縮小してもモアレが出にくくなります。
+func Resize(img *image.RGBA, maxSize int) *image.RGBA {
+	sw, sh := img.Bounds().Dx(), img.Bounds().Dy()
+	dw, dh := sw, sh
+	if sw >= sh && sw > maxSize {
+		dw, dh = maxSize, max(1, sh*maxSize/sw)
+	} else if sh > sw && sh > maxSize {
+		dw, dh = max(1, sw*maxSize/sh), maxSize
+	}
+	if dw == sw && dh == sh {
+		return img
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*sh/dh, max((y+1)*sh/dh, y*sh/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*sw/dw, max((x+1)*sw/dw, x*sw/dw+1)
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := img.Pix[sy*img.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					sum[0] += int(p[0])
+					sum[1] += int(p[1])
+					sum[2] += int(p[2])
+					sum[3] += int(p[3])
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			d := dst.Pix[y*dst.Stride+x*4:]
+			for i := range sum {
+				d[i] = uint8((sum[i] + n/2) / n)
+			}
+		}
+	}
+	return dst
+}
+
+// EncodeJPEG は縮小版をJPEGで書き出します。JPEGは透過を扱えないため、透過したPNGは白い背景に重ねます。
+func EncodeJPEG(img *image.RGBA) ([]byte, error) {
+	if !img.Opaque() {
+		flat := image.NewRGBA(img.Bounds())
+		draw.Draw(flat, flat.Bounds(), image.White, image.Point{}, draw.Src)
+		draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+		img = flat
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: VariantQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+const tagOrientation = 0x0112
+
+// jpegOrientation はJPEGのExifのOrientationを返します。Exifがない・読めない場合は1(回転なし)です。
+func jpegOrientation(data []byte) int {
+	tiff := jpegExif(data)
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder = binary.BigEndian
+	if string(tiff[:2]) == "II" {
+		order = binary.LittleEndian
+	}
+	ifd0 := int(order.Uint32(tiff[4:]))
+	if ifd0 < 8 || ifd0+2 > len(tiff) {
+		return 1
+	}
+	n := int(order.Uint16(tiff[ifd0:]))
+	for i := 0; i < n; i++ {
+		entry := ifd0 + 2 + i*ifdEntry
+		if entry+ifdEntry > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == tagOrientation {
+			if o := int(order.Uint16(tiff[entry+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// jpegExif はJPEGの最初のExifセグメントのTIFF部分を返します。
+func jpegExif(data []byte) []byte {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != markerSOI {
+		return nil
+	}
+	pos := 2
+	for pos+4 <= len(data) && data[pos] == 0xFF {
+		marker := data[pos+1]
+		if marker == 0xFF {
+			pos++
+			continue
+		}
+		if marker == markerSOS {
+			return nil
+		}
+		end := pos + 2 + int(binary.BigEndian.Uint16(data[pos+2:]))
+		if end > len(data) {
+			return nil
+		}
+		if payload := data[pos+4 : end]; marker == markerAPP1 && bytes.HasPrefix(payload, exifHeader) {
+			return payload[len(exifHeader):]
+		}
+		pos = end
+	}
+	return nil
+}
+
+// orient はExifのOrientation(1〜8)に従ってimgを回転・反転します。
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dw, dh := w, h
+	// 5〜8は90度回転を含むため縦横が入れ替わる
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	// srcは向きを直した画像の(x, y)に対応する元の画像の座標を返す
+	var src func(x, y int) (int, int)
+	switch orientation {
+	case 2:
+		src = func(x, y int) (int, int) { return w - 1 - x, y }
+	case 3:
+		src = func(x, y int) (int, int) { return w - 1 - x, h - 1 - y }
+	case 4:
+		src = func(x, y int) (int, int) { return x, h - 1 - y }
+	case 5:
+		src = func(x, y int) (int, int) { return y, x }
+	case 6:
+		src = func(x, y int) (int, int) { return y, h - 1 - x }
+	case 7:
+		src = func(x, y int) (int, int) { return w - 1 - y, h - 1 - x }
+	case 8:
+		src = func(x, y int) (int, int) { return w - 1 - y, x }
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			sx, sy := src(x, y)
+			copy(dst.Pix[y*dst.Stride+x*4:y*dst.Stride+x*4+4], img.Pix[sy*img.Stride+sx*4:])
+		}
+	}
+	return dst
+}
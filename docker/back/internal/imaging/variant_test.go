@@ -0,0 +1,173 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// exifWithOrientation はIFD0にOrientationだけを持つ、ビッグエンディアンのExifを組み立てます。
+func exifWithOrientation(orientation uint16) []byte {
+	be := binary.BigEndian
+	tiff := []byte{'M', 'M', 0, 42, 0, 0, 0, 8}
+	tiff = be.AppendUint16(tiff, 1)
+	tiff = be.AppendUint16(tiff, tagOrientation)
+	tiff = be.AppendUint16(tiff, 3)
+	tiff = be.AppendUint32(tiff, 1)
+	tiff = be.AppendUint16(tiff, orientation)
+	tiff = be.AppendUint16(tiff, 0)
+	tiff = be.AppendUint32(tiff, 0)
+	return append(append([]byte(nil), exifHeader...), tiff...)
+}
+
+func TestDecode_Orientation(t *testing.T) {
+	data := encodeJPEG(t)
+
+	patterns := []struct {
+		name        string
+		orientation uint16
+		wantW       int
+		wantH       int
+	}{
+		{name: "no exif", wantW: 8, wantH: 6},
+		{name: "rotate 180", orientation: 3, wantW: 8, wantH: 6},
+		{name: "rotate 90 CW", orientation: 6, wantW: 6, wantH: 8},
+		{name: "rotate 270 CW", orientation: 8, wantW: 6, wantH: 8},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			in := data
+			if tt.orientation != 0 {
+				in = insertAfterSOI(data, app1(exifWithOrientation(tt.orientation)))
+			}
+			img, err := Decode(in)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if w, h := img.Bounds().Dx(), img.Bounds().Dy(); w != tt.wantW || h != tt.wantH {
+				t.Errorf("Decode() size = %dx%d, want %dx%d", w, h, tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// 3x2の画像の各画素に番号を振り、回転後の並びを確認する
+	//   0 1 2
+	//   3 4 5
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	for i := 0; i < 6; i++ {
+		img.Set(i%3, i/3, color.RGBA{R: uint8(i), A: 255})
+	}
+	pixels := func(img *image.RGBA) []uint8 {
+		var got []uint8
+		for y := 0; y < img.Bounds().Dy(); y++ {
+			for x := 0; x < img.Bounds().Dx(); x++ {
+				got = append(got, img.RGBAAt(x, y).R)
+			}
+		}
+		return got
+	}
+
+	patterns := []struct {
+		orientation int
+		want        []uint8
+	}{
+		{orientation: 1, want: []uint8{0, 1, 2, 3, 4, 5}},
+		{orientation: 2, want: []uint8{2, 1, 0, 5, 4, 3}},
+		{orientation: 3, want: []uint8{5, 4, 3, 2, 1, 0}},
+		{orientation: 4, want: []uint8{3, 4, 5, 0, 1, 2}},
+		{orientation: 5, want: []uint8{0, 3, 1, 4, 2, 5}},
+		{orientation: 6, want: []uint8{3, 0, 4, 1, 5, 2}},
+		{orientation: 7, want: []uint8{5, 2, 4, 1, 3, 0}},
+		{orientation: 8, want: []uint8{2, 5, 1, 4, 0, 3}},
+	}
+
+	for _, tt := range patterns {
+		got := pixels(orient(img, tt.orientation))
+		if string(got) != string(tt.want) {
+			t.Errorf("orient(%d) = %v, want %v", tt.orientation, got, tt.want)
+		}
+	}
+}
+
+func TestResize(t *testing.T) {
+	patterns := []struct {
+		name    string
+		w, h    int
+		maxSize int
+		wantW   int
+		wantH   int
+	}{
+		{name: "landscape", w: 4000, h: 3000, maxSize: 320, wantW: 320, wantH: 240},
+		{name: "portrait", w: 3000, h: 4000, maxSize: 320, wantW: 240, wantH: 320},
+		{name: "smaller than max", w: 200, h: 100, maxSize: 320, wantW: 200, wantH: 100},
+		{name: "panorama", w: 10000, h: 10, maxSize: 320, wantW: 320, wantH: 1},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got := Resize(image.NewRGBA(image.Rect(0, 0, tt.w, tt.h)), tt.maxSize)
+			if w, h := got.Bounds().Dx(), got.Bounds().Dy(); w != tt.wantW || h != tt.wantH {
+				t.Errorf("Resize() size = %dx%d, want %dx%d", w, h, tt.wantW, tt.wantH)
+			}
+		})
+	}
+
+	// エリア平均で縮小するため、白黒の縞模様は灰色になる
+	stripes := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 2; y++ {
+			stripes.Set(x, y, color.Gray{Y: uint8(255 * (x % 2))})
+		}
+	}
+	if got := Resize(stripes, 2).RGBAAt(0, 0); got.R != 128 || got.A != 255 {
+		t.Errorf("Resize() pixel = %v, want gray", got)
+	}
+}
+
+func TestEncodeJPEG_Transparent(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	data, err := EncodeJPEG(img)
+	if err != nil {
+		t.Fatalf("EncodeJPEG() error = %v", err)
+	}
+	decoded, err := Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 透過した部分は黒ではなく白になる
+	if got := decoded.RGBAAt(4, 4); got.R < 250 || got.G < 250 || got.B < 250 {
+		t.Errorf("transparent pixel = %v, want white", got)
+	}
+}
+
+func TestBlurHash(t *testing.T) {
+	solid := image.NewRGBA(image.Rect(0, 0, 32, 24))
+	for x := 0; x < 32; x++ {
+		for y := 0; y < 24; y++ {
+			solid.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+	// 成分が1つだけの場合は平均色(DC成分)のみで、赤(0xFF0000)は"TI:j"になる
+	if got := BlurHash(solid, 1, 1); got != "00TI:j" {
+		t.Errorf("BlurHash(1x1) = %v, want 00TI:j", got)
+	}
+	// 4x3成分では先頭の"L"が成分数を表し、平均色の後ろに交流成分が2文字ずつ11個続く
+	got := BlurHash(solid, BlurHashXComponents, BlurHashYComponents)
+	if len(got) != 28 || got[0] != 'L' || got[2:6] != "TI:j" {
+		t.Errorf("BlurHash(4x3) = %v", got)
+	}
+
+	img, err := Decode(encodeJPEG(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash := BlurHash(img, BlurHashXComponents, BlurHashYComponents); len(hash) != 28 || hash == got {
+		t.Errorf("BlurHash() = %v", hash)
+	}
+}
@@ -0,0 +1,426 @@
+package imaging
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+)
+
+// webpMaxSize はWebPで表せる幅と高さの最大値です。
+const webpMaxSize = 1 << 14
+
+var ErrImageTooLargeForWebP = errors.New("image is too large for webp")
+
+// VP8Lのビットストリームの定数です。https://developers.google.com/speed/webp/docs/webp_lossless_bitstream_specification
+const (
+	vp8lSignature          = 0x2f
+	transformPredictor     = 0
+	transformSubtractGreen = 2
+	// predictorBits は予測変換のブロックの大きさ(1<<predictorBits)で、画像全体に同じ予測を使うため最大にします。
+	predictorBits = 9
+	// predictorAverageLT は左と上の画素の平均から予測するモードで、写真では左や上だけの予測より残差が小さくなります。
+	predictorAverageLT = 7
+
+	numLiteralCodes  = 256
+	numLengthCodes   = 24
+	numDistanceCodes = 40
+	maxCodeLength    = 15
+	// maxCodeLengthCodeLength は符号長を符号化する符号の最大の長さで、3ビットで書き出すため7までです。
+	maxCodeLengthCodeLength = 7
+	numCodeLengthCodes      = 19
+)
+
+var codeLengthCodeOrder = [numCodeLengthCodes]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// EncodeWebP は縮小版をロスレスのWebP(VP8L)で書き出します。標準ライブラリにWebPのエンコーダがないため、
+// 緑の減算と予測変換、ハフマン符号化のみを行う簡易なエンコーダで、後方参照やカラーキャッシュは使いません。
+func EncodeWebP(img *image.RGBA) ([]byte, error) {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if width < 1 || height < 1 {
+		return nil, ErrInvalidImage
+	}
+	if width > webpMaxSize || height > webpMaxSize {
+		return nil, ErrImageTooLargeForWebP
+	}
+
+	argb := make([]uint32, 0, width*height)
+	alphaUsed := false
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.NRGBAModel.Convert(img.At(img.Bounds().Min.X+x, img.Bounds().Min.Y+y)).(color.NRGBA)
+			alphaUsed = alphaUsed || c.A != 0xff
+			argb = append(argb, uint32(c.A)<<24|uint32(c.R)<<16|uint32(c.G)<<8|uint32(c.B))
+		}
+	}
+
+	w := &bitWriter{}
+	w.writeBits(vp8lSignature, 8)
+	w.writeBits(uint32(width-1), 14)
+	w.writeBits(uint32(height-1), 14)
+	if alphaUsed {
+		w.writeBits(1, 1)
+	} else {
+		w.writeBits(0, 1)
+	}
+	w.writeBits(0, 3) // version
+
+	// デコーダは変換を逆順に戻すため、緑の減算をしてから予測する
+	subtractGreen(argb)
+	w.writeBits(1, 1)
+	w.writeBits(transformSubtractGreen, 2)
+
+	w.writeBits(1, 1)
+	w.writeBits(transformPredictor, 2)
+	w.writeBits(predictorBits-2, 3)
+	blocks := make([]uint32, subSampleSize(width, predictorBits)*subSampleSize(height, predictorBits))
+	for i := range blocks {
+		blocks[i] = predictorAverageLT << 8
+	}
+	writeEntropyImage(w, blocks, false)
+	residuals := predictAverageLT(argb, width, height)
+	w.writeBits(0, 1) // 変換の終わり
+
+	writeEntropyImage(w, residuals, true)
+	return riffWebP(w.bytes()), nil
+}
+
+func riffWebP(vp8l []byte) []byte {
+	size := len(vp8l) + len(vp8l)%2
+	out := make([]byte, 0, 20+size)
+	out = append(out, "RIFF"...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(4+8+size))
+	out = append(out, "WEBPVP8L"...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(vp8l)))
+	out = append(out, vp8l...)
+	if len(vp8l)%2 == 1 {
+		out = append(out, 0)
+	}
+	return out
+}
+
+func subSampleSize(size, bits int) int {
+	return (size + 1<<bits - 1) >> bits
+}
+
+func subtractGreen(argb []uint32) {
+	for i, p := range argb {
+		g := (p >> 8) & 0xff
+		r := ((p >> 16) - g) & 0xff
+		b := (p - g) & 0xff
+		argb[i] = p&0xff00ff00 | r<<16 | b
+	}
+}
+
+// predictAverageLT は予測との差(残差)を返します。左上の画素は不透明な黒から、1行目は左から、1列目は上から予測します。
+func predictAverageLT(argb []uint32, width, height int) []uint32 {
+	residuals := make([]uint32, len(argb))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*width + x
+			var pred uint32
+			switch {
+			case x == 0 && y == 0:
+				pred = 0xff000000
+			case y == 0:
+				pred = argb[i-1]
+			case x == 0:
+				pred = argb[i-width]
+			default:
+				pred = average2(argb[i-1], argb[i-width])
+			}
+			residuals[i] = subPixels(argb[i], pred)
+		}
+	}
+	return residuals
+}
+
+func average2(a, b uint32) uint32 {
+	return (((a ^ b) & 0xfefefefe) >> 1) + (a & b)
+}
+
+func subPixels(a, b uint32) uint32 {
+	alphaGreen := 0x00ff00ff + (a & 0xff00ff00) - (b & 0xff00ff00)
+	redBlue := 0xff00ff00 + (a & 0x00ff00ff) - (b & 0x00ff00ff)
+	return alphaGreen&0xff00ff00 | redBlue&0x00ff00ff
+}
+
+// writeEntropyImage は画素をリテラルのみでハフマン符号化して書き出します。
+// 変換のデータなどの補助的な画像では、メタ符号の有無を表すビットを書きません。
+func writeEntropyImage(w *bitWriter, argb []uint32, main bool) {
+	green := make([]int, numLiteralCodes+numLengthCodes)
+	red := make([]int, numLiteralCodes)
+	blue := make([]int, numLiteralCodes)
+	alpha := make([]int, numLiteralCodes)
+	for _, p := range argb {
+		green[(p>>8)&0xff]++
+		red[(p>>16)&0xff]++
+		blue[p&0xff]++
+		alpha[p>>24]++
+	}
+
+	w.writeBits(0, 1) // カラーキャッシュを使わない
+	if main {
+		w.writeBits(0, 1) // メタ符号を使わない
+	}
+	codes := [4]*huffmanCode{
+		writeHuffmanCode(w, green),
+		writeHuffmanCode(w, red),
+		writeHuffmanCode(w, blue),
+		writeHuffmanCode(w, alpha),
+	}
+	writeHuffmanCode(w, make([]int, numDistanceCodes))
+
+	for _, p := range argb {
+		codes[0].writeSymbol(w, int((p>>8)&0xff))
+		codes[1].writeSymbol(w, int((p>>16)&0xff))
+		codes[2].writeSymbol(w, int(p&0xff))
+		codes[3].writeSymbol(w, int(p>>24))
+	}
+}
+
+type huffmanCode struct {
+	lengths []uint8
+	codes   []uint32
+	// single は使われる記号が一つだけの符号で、記号を0ビットで表します。
+	single bool
+}
+
+func (c *huffmanCode) writeSymbol(w *bitWriter, symbol int) {
+	if c.single {
+		return
+	}
+	w.writeBits(c.codes[symbol], uint(c.lengths[symbol]))
+}
+
+// writeHuffmanCode はcountsの頻度から符号を作って書き出します。使われる記号が2つ以下の場合は単純な符号にします。
+func writeHuffmanCode(w *bitWriter, counts []int) *huffmanCode {
+	var used []int
+	for symbol, count := range counts {
+		if count > 0 {
+			used = append(used, symbol)
+		}
+	}
+	if len(used) == 0 {
+		used = []int{0}
+	}
+
+	if len(used) <= 2 && used[len(used)-1] < numLiteralCodes {
+		w.writeBits(1, 1)
+		w.writeBits(uint32(len(used)-1), 1)
+		if used[0] < 2 {
+			w.writeBits(0, 1)
+			w.writeBits(uint32(used[0]), 1)
+		} else {
+			w.writeBits(1, 1)
+			w.writeBits(uint32(used[0]), 8)
+		}
+		if len(used) == 2 {
+			w.writeBits(uint32(used[1]), 8)
+		}
+		lengths := make([]uint8, len(counts))
+		for _, symbol := range used {
+			lengths[symbol] = 1
+		}
+		return newHuffmanCode(lengths)
+	}
+
+	w.writeBits(0, 1)
+	lengths := huffmanLengths(counts, maxCodeLength)
+	writeCodeLengths(w, lengths)
+	return newHuffmanCode(lengths)
+}
+
+// writeCodeLengths は符号長を、0の連続を17と18でまとめて符号化して書き出します。
+func writeCodeLengths(w *bitWriter, lengths []uint8) {
+	type token struct {
+		symbol, extra int
+	}
+	var tokens []token
+	for i := 0; i < len(lengths); {
+		if lengths[i] != 0 {
+			tokens = append(tokens, token{symbol: int(lengths[i])})
+			i++
+			continue
+		}
+		run := 0
+		for i+run < len(lengths) && lengths[i+run] == 0 {
+			run++
+		}
+		i += run
+		for run > 0 {
+			switch {
+			case run >= 11:
+				n := min(run, 138)
+				tokens = append(tokens, token{symbol: 18, extra: n - 11})
+				run -= n
+			case run >= 3:
+				tokens = append(tokens, token{symbol: 17, extra: run - 3})
+				run = 0
+			default:
+				tokens = append(tokens, token{symbol: 0})
+				run--
+			}
+		}
+	}
+
+	counts := make([]int, numCodeLengthCodes)
+	for _, t := range tokens {
+		counts[t.symbol]++
+	}
+	code := newHuffmanCode(huffmanLengths(counts, maxCodeLengthCodeLength))
+	n := numCodeLengthCodes
+	for n > 4 && code.lengths[codeLengthCodeOrder[n-1]] == 0 {
+		n--
+	}
+	w.writeBits(uint32(n-4), 4)
+	for _, symbol := range codeLengthCodeOrder[:n] {
+		w.writeBits(uint32(code.lengths[symbol]), 3)
+	}
+	w.writeBits(0, 1) // すべての記号の符号長を書く
+
+	for _, t := range tokens {
+		code.writeSymbol(w, t.symbol)
+		switch t.symbol {
+		case 17:
+			w.writeBits(uint32(t.extra), 3)
+		case 18:
+			w.writeBits(uint32(t.extra), 7)
+		}
+	}
+}
+
+// newHuffmanCode は符号長から、短い符号から順に記号の順で割り当てる正規ハフマン符号を作ります。
+// ビットストリームは下位ビットから詰めるため、符号はビットを反転して保持します。
+func newHuffmanCode(lengths []uint8) *huffmanCode {
+	c := &huffmanCode{lengths: lengths, codes: make([]uint32, len(lengths))}
+	var count [maxCodeLength + 1]uint32
+	used := 0
+	for _, l := range lengths {
+		if l > 0 {
+			count[l]++
+			used++
+		}
+	}
+	c.single = used <= 1
+	var next [maxCodeLength + 1]uint32
+	code := uint32(0)
+	for l := 1; l <= maxCodeLength; l++ {
+		code = (code + count[l-1]) << 1
+		next[l] = code
+	}
+	for symbol, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		c.codes[symbol] = reverseBits(next[l], uint(l))
+		next[l]++
+	}
+	return c
+}
+
+func reverseBits(v uint32, n uint) uint32 {
+	var r uint32
+	for i := uint(0); i < n; i++ {
+		r = r<<1 | (v>>i)&1
+	}
+	return r
+}
+
+// huffmanLengths はcountsの頻度からハフマン符号の符号長を求めます。maxLengthを超える場合は、
+// 低い頻度を底上げして木を浅くしてから作り直します。
+func huffmanLengths(counts []int, maxLength int) []uint8 {
+	lengths := make([]uint8, len(counts))
+	for minCount := 1; ; minCount *= 2 {
+		h := &huffmanHeap{}
+		for symbol, count := range counts {
+			if count > 0 {
+				h.nodes = append(h.nodes, huffmanNode{weight: max(count, minCount), symbol: symbol, left: -1, right: -1})
+				h.order = append(h.order, len(h.nodes)-1)
+			}
+		}
+		switch len(h.order) {
+		case 0:
+			return lengths
+		case 1:
+			lengths[h.nodes[0].symbol] = 1
+			return lengths
+		}
+		heap.Init(h)
+		for h.Len() > 1 {
+			a := heap.Pop(h).(int)
+			b := heap.Pop(h).(int)
+			h.nodes = append(h.nodes, huffmanNode{
+				weight: h.nodes[a].weight + h.nodes[b].weight, symbol: -1, left: a, right: b,
+			})
+			heap.Push(h, len(h.nodes)-1)
+		}
+		if setDepths(h.nodes, h.order[0], 0, lengths) <= maxLength {
+			return lengths
+		}
+	}
+}
+
+// setDepths はnodeより下の葉の深さをlengthsに設定し、最大の深さを返します。
+func setDepths(nodes []huffmanNode, node, depth int, lengths []uint8) int {
+	n := nodes[node]
+	if n.symbol >= 0 {
+		lengths[n.symbol] = uint8(min(depth, 255))
+		return depth
+	}
+	return max(setDepths(nodes, n.left, depth+1, lengths), setDepths(nodes, n.right, depth+1, lengths))
+}
+
+type huffmanNode struct {
+	weight      int
+	symbol      int
+	left, right int
+}
+
+// huffmanHeap は重みの小さい節から取り出すヒープで、orderにnodesの添字を持ちます。
+type huffmanHeap struct {
+	nodes []huffmanNode
+	order []int
+}
+
+func (h *huffmanHeap) Len() int { return len(h.order) }
+func (h *huffmanHeap) Less(i, j int) bool {
+	a, b := h.nodes[h.order[i]], h.nodes[h.order[j]]
+	if a.weight != b.weight {
+		return a.weight < b.weight
+	}
+	return h.order[i] < h.order[j]
+}
+func (h *huffmanHeap) Swap(i, j int) { h.order[i], h.order[j] = h.order[j], h.order[i] }
+func (h *huffmanHeap) Push(x any)    { h.order = append(h.order, x.(int)) }
+func (h *huffmanHeap) Pop() any {
+	x := h.order[len(h.order)-1]
+	h.order = h.order[:len(h.order)-1]
+	return x
+}
+
+// bitWriter はVP8Lのビットストリームのように、値を下位ビットから順に詰めて書き出します。
+type bitWriter struct {
+	buf  []byte
+	acc  uint64
+	nacc uint
+}
+
+func (w *bitWriter) writeBits(v uint32, n uint) {
+	w.acc |= uint64(v) << w.nacc
+	w.nacc += n
+	for w.nacc >= 8 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc >>= 8
+		w.nacc -= 8
+	}
+}
+
+func (w *bitWriter) bytes() []byte {
+	if w.nacc > 0 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc, w.nacc = 0, 0
+	}
+	return w.buf
+}
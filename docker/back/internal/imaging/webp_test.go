@@ -0,0 +1,462 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+// webpBitReader はVP8Lのビットストリームを下位ビットから読みます。
+type webpBitReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *webpBitReader) read(n int) (uint32, error) {
+	var v uint32
+	for i := 0; i < n; i++ {
+		if r.pos>>3 >= len(r.data) {
+			return 0, errors.New("unexpected end of bitstream")
+		}
+		v |= uint32(r.data[r.pos>>3]>>(r.pos&7)&1) << i
+		r.pos++
+	}
+	return v, nil
+}
+
+// testCode は符号長から組み立てた正規ハフマン符号で、ビット長と符号の組から記号を引きます。
+type testCode struct {
+	single  bool
+	symbol  int
+	symbols map[[2]int]int
+}
+
+func newTestCode(lengths []int) (*testCode, error) {
+	var used []int
+	for symbol, l := range lengths {
+		if l > 0 {
+			used = append(used, symbol)
+		}
+	}
+	switch len(used) {
+	case 0:
+		return nil, errors.New("empty code")
+	case 1:
+		return &testCode{single: true, symbol: used[0]}, nil
+	}
+
+	// libwebpは不完全な符号を受け付けないため、クラフトの不等式が等号で成り立つことを確かめる
+	kraft := 0
+	for _, symbol := range used {
+		kraft += 1 << (15 - lengths[symbol])
+	}
+	if kraft != 1<<15 {
+		return nil, errors.New("incomplete code")
+	}
+
+	c := &testCode{symbols: map[[2]int]int{}}
+	code := 0
+	for l := 1; l <= 15; l++ {
+		for symbol, sl := range lengths {
+			if sl == l {
+				c.symbols[[2]int{l, code}] = symbol
+				code++
+			}
+		}
+		code <<= 1
+	}
+	return c, nil
+}
+
+func (c *testCode) decode(r *webpBitReader) (int, error) {
+	if c.single {
+		return c.symbol, nil
+	}
+	code := 0
+	for l := 1; l <= 15; l++ {
+		bit, err := r.read(1)
+		if err != nil {
+			return 0, err
+		}
+		code = code<<1 | int(bit)
+		if symbol, ok := c.symbols[[2]int{l, code}]; ok {
+			return symbol, nil
+		}
+	}
+	return 0, errors.New("invalid code")
+}
+
+func readTestCode(r *webpBitReader, alphabetSize int) (*testCode, error) {
+	lengths := make([]int, alphabetSize)
+	simple, err := r.read(1)
+	if err != nil {
+		return nil, err
+	}
+	if simple == 1 {
+		n, _ := r.read(1)
+		firstBits, _ := r.read(1)
+		first, _ := r.read(1 + 7*int(firstBits))
+		lengths[first] = 1
+		if n == 1 {
+			second, err := r.read(8)
+			if err != nil {
+				return nil, err
+			}
+			lengths[second] = 1
+		}
+		return newTestCode(lengths)
+	}
+
+	numCodes, _ := r.read(4)
+	clLengths := make([]int, numCodeLengthCodes)
+	for i := 0; i < int(numCodes)+4; i++ {
+		l, err := r.read(3)
+		if err != nil {
+			return nil, err
+		}
+		clLengths[codeLengthCodeOrder[i]] = int(l)
+	}
+	clCode, err := newTestCode(clLengths)
+	if err != nil {
+		return nil, err
+	}
+	maxSymbol := alphabetSize
+	if useMax, _ := r.read(1); useMax == 1 {
+		lengthBits, _ := r.read(3)
+		v, _ := r.read(2 + 2*int(lengthBits))
+		maxSymbol = 2 + int(v)
+	}
+
+	prev := 8
+	for symbol := 0; symbol < alphabetSize && maxSymbol > 0; maxSymbol-- {
+		c, err := clCode.decode(r)
+		if err != nil {
+			return nil, err
+		}
+		if c < 16 {
+			lengths[symbol] = c
+			symbol++
+			if c != 0 {
+				prev = c
+			}
+			continue
+		}
+		repeat, value := 0, 0
+		switch c {
+		case 16:
+			v, _ := r.read(2)
+			repeat, value = 3+int(v), prev
+		case 17:
+			v, _ := r.read(3)
+			repeat = 3 + int(v)
+		case 18:
+			v, _ := r.read(7)
+			repeat = 11 + int(v)
+		}
+		if symbol+repeat > alphabetSize {
+			return nil, errors.New("too many code lengths")
+		}
+		for ; repeat > 0; repeat-- {
+			lengths[symbol] = value
+			symbol++
+		}
+	}
+	return newTestCode(lengths)
+}
+
+func readTestEntropyImage(r *webpBitReader, width, height int, main bool) ([]uint32, error) {
+	if cache, _ := r.read(1); cache != 0 {
+		return nil, errors.New("color cache is not supported")
+	}
+	if main {
+		if meta, _ := r.read(1); meta != 0 {
+			return nil, errors.New("meta prefix codes are not supported")
+		}
+	}
+	sizes := []int{numLiteralCodes + numLengthCodes, numLiteralCodes, numLiteralCodes, numLiteralCodes, numDistanceCodes}
+	codes := make([]*testCode, len(sizes))
+	for i, size := range sizes {
+		c, err := readTestCode(r, size)
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = c
+	}
+
+	argb := make([]uint32, width*height)
+	for i := range argb {
+		var v [4]int
+		for j := range v {
+			symbol, err := codes[j].decode(r)
+			if err != nil {
+				return nil, err
+			}
+			v[j] = symbol
+		}
+		if v[0] >= numLiteralCodes {
+			return nil, errors.New("backward references are not supported")
+		}
+		argb[i] = uint32(v[3])<<24 | uint32(v[1])<<16 | uint32(v[0])<<8 | uint32(v[2])
+	}
+	return argb, nil
+}
+
+func addTestPixels(a, b uint32) uint32 {
+	var out uint32
+	for shift := 0; shift < 32; shift += 8 {
+		out |= ((a>>shift + b>>shift) & 0xff) << shift
+	}
+	return out
+}
+
+func averageTestPixels(a, b uint32) uint32 {
+	var out uint32
+	for shift := 0; shift < 32; shift += 8 {
+		out |= ((a>>shift&0xff + b>>shift&0xff) / 2) << shift
+	}
+	return out
+}
+
+// decodeTestWebP はEncodeWebPが使う機能に限ってロスレスのWebPを復号します。
+func decodeTestWebP(data []byte) (*image.NRGBA, error) {
+	if len(data) < 20 || string(data[:4]) != "RIFF" || string(data[8:16]) != "WEBPVP8L" {
+		return nil, errors.New("not a lossless webp")
+	}
+	if int(binary.LittleEndian.Uint32(data[4:]))+8 != len(data) {
+		return nil, errors.New("invalid riff size")
+	}
+	size := int(binary.LittleEndian.Uint32(data[16:]))
+	if 20+size > len(data) {
+		return nil, errors.New("invalid chunk size")
+	}
+	r := &webpBitReader{data: data[20 : 20+size]}
+
+	if signature, _ := r.read(8); signature != vp8lSignature {
+		return nil, errors.New("invalid signature")
+	}
+	w, _ := r.read(14)
+	h, _ := r.read(14)
+	width, height := int(w)+1, int(h)+1
+	r.read(1) //nolint:errcheck // アルファの有無は画素から分かる
+	if version, _ := r.read(3); version != 0 {
+		return nil, errors.New("invalid version")
+	}
+
+	type transform struct {
+		kind  uint32
+		bits  int
+		image []uint32
+	}
+	var transforms []transform
+	for {
+		more, err := r.read(1)
+		if err != nil {
+			return nil, err
+		}
+		if more == 0 {
+			break
+		}
+		kind, _ := r.read(2)
+		switch kind {
+		case transformSubtractGreen:
+			transforms = append(transforms, transform{kind: kind})
+		case transformPredictor:
+			bits, _ := r.read(3)
+			t := transform{kind: kind, bits: int(bits) + 2}
+			t.image, err = readTestEntropyImage(r, subSampleSize(width, t.bits), subSampleSize(height, t.bits), false)
+			if err != nil {
+				return nil, err
+			}
+			transforms = append(transforms, t)
+		default:
+			return nil, errors.New("unsupported transform")
+		}
+	}
+
+	argb, err := readTestEntropyImage(r, width, height, true)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(transforms) - 1; i >= 0; i-- {
+		t := transforms[i]
+		switch t.kind {
+		case transformSubtractGreen:
+			for j, p := range argb {
+				g := p >> 8 & 0xff
+				argb[j] = p&0xff00ff00 | ((p>>16+g)&0xff)<<16 | (p+g)&0xff
+			}
+		case transformPredictor:
+			blocksPerRow := subSampleSize(width, t.bits)
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					j := y*width + x
+					var pred uint32
+					switch {
+					case x == 0 && y == 0:
+						pred = 0xff000000
+					case y == 0:
+						pred = argb[j-1]
+					case x == 0:
+						pred = argb[j-width]
+					default:
+						switch mode := t.image[(y>>t.bits)*blocksPerRow+x>>t.bits] >> 8 & 0xf; mode {
+						case 7:
+							pred = averageTestPixels(argb[j-1], argb[j-width])
+						default:
+							return nil, errors.New("unsupported predictor")
+						}
+					}
+					argb[j] = addTestPixels(argb[j], pred)
+				}
+			}
+		}
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for i, p := range argb {
+		img.Pix[i*4] = byte(p >> 16)
+		img.Pix[i*4+1] = byte(p >> 8)
+		img.Pix[i*4+2] = byte(p)
+		img.Pix[i*4+3] = byte(p >> 24)
+	}
+	return img, nil
+}
+
+func TestEncodeWebP(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	patterns := []struct {
+		name  string
+		img   func() *image.RGBA
+		alpha bool
+	}{
+		{
+			name: "1x1",
+			img: func() *image.RGBA {
+				img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+				img.Set(0, 0, color.RGBA{R: 10, G: 200, B: 30, A: 255})
+				return img
+			},
+		},
+		{
+			name: "gradient",
+			img: func() *image.RGBA {
+				img := image.NewRGBA(image.Rect(0, 0, 64, 48))
+				for y := 0; y < 48; y++ {
+					for x := 0; x < 64; x++ {
+						img.Set(x, y, color.RGBA{R: uint8(x * 4), G: uint8(y * 5), B: uint8(x + y), A: 255})
+					}
+				}
+				return img
+			},
+		},
+		{
+			name: "random noise",
+			img: func() *image.RGBA {
+				img := image.NewRGBA(image.Rect(0, 0, 37, 23))
+				rng.Read(img.Pix)
+				for i := 3; i < len(img.Pix); i += 4 {
+					img.Pix[i] = 255
+				}
+				return img
+			},
+		},
+		{
+			name: "wider than a predictor block",
+			img: func() *image.RGBA {
+				img := image.NewRGBA(image.Rect(0, 0, 600, 3))
+				for y := 0; y < 3; y++ {
+					for x := 0; x < 600; x++ {
+						img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(x * y), A: 255})
+					}
+				}
+				return img
+			},
+		},
+		{
+			name: "transparent",
+			img: func() *image.RGBA {
+				img := image.NewRGBA(image.Rect(0, 0, 5, 4))
+				img.Set(1, 1, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+				img.Set(2, 1, color.RGBA{R: 64, A: 128})
+				return img
+			},
+			alpha: true,
+		},
+		{
+			name: "sub image",
+			img: func() *image.RGBA {
+				img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+				for i := range img.Pix {
+					img.Pix[i] = uint8(i)
+				}
+				for i := 3; i < len(img.Pix); i += 4 {
+					img.Pix[i] = 255
+				}
+				return img.SubImage(image.Rect(2, 3, 7, 6)).(*image.RGBA)
+			},
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			src := tt.img()
+			data, err := EncodeWebP(src)
+			if err != nil {
+				t.Fatalf("EncodeWebP() error = %v", err)
+			}
+			if alpha := data[20+4]&0x10 != 0; alpha != tt.alpha {
+				t.Errorf("alpha flag = %v, want %v", alpha, tt.alpha)
+			}
+			got, err := decodeTestWebP(data)
+			if err != nil {
+				t.Fatalf("decode error = %v", err)
+			}
+			if got.Bounds().Size() != src.Bounds().Size() {
+				t.Fatalf("size = %v, want %v", got.Bounds().Size(), src.Bounds().Size())
+			}
+			min := src.Bounds().Min
+			for y := 0; y < got.Bounds().Dy(); y++ {
+				for x := 0; x < got.Bounds().Dx(); x++ {
+					want := color.NRGBAModel.Convert(src.At(min.X+x, min.Y+y))
+					if c := got.NRGBAAt(x, y); c != want {
+						t.Fatalf("pixel (%d, %d) = %v, want %v", x, y, c, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeWebP_TooLarge(t *testing.T) {
+	if _, err := EncodeWebP(image.NewRGBA(image.Rect(0, 0, webpMaxSize+1, 1))); !errors.Is(err, ErrImageTooLargeForWebP) {
+		t.Errorf("EncodeWebP() error = %v, want %v", err, ErrImageTooLargeForWebP)
+	}
+}
+
+func TestHuffmanLengths_Limit(t *testing.T) {
+	// フィボナッチ数列の頻度は、制限しなければ記号の数だけ深い木になる
+	counts := make([]int, 30)
+	a, b := 1, 1
+	for i := range counts {
+		counts[i] = a
+		a, b = b, a+b
+	}
+
+	lengths := huffmanLengths(counts, maxCodeLength)
+	kraft := 0
+	for symbol, l := range lengths {
+		if l < 1 || l > maxCodeLength {
+			t.Fatalf("length of %d = %d, want 1..%d", symbol, l, maxCodeLength)
+		}
+		kraft += 1 << (maxCodeLength - int(l))
+	}
+	if kraft != 1<<maxCodeLength {
+		t.Errorf("code is not complete: kraft sum = %d", kraft)
+	}
+}
@@ -0,0 +1,89 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/tusmasoma/campfinder/docker/back/config"
+)
+
+const (
+	DriverLog  = "log"
+	DriverFile = "file"
+)
+
+// Message は送信するメールです。本文はテキストのみです。
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer はメールを送信します。SMTPなどの実装に差し替えられるように、usecaseはこのインターフェースのみに依存します。
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewMailer は設定に応じたMailerを返します。
+func NewMailer(conf *config.MailConfig) (Mailer, error) {
+	switch conf.Driver {
+	case DriverLog:
+		return NewLogMailer(), nil
+	case DriverFile:
+		return NewFileMailer(conf.Dir)
+	default:
+		return nil, fmt.Errorf("unknown mail driver: %q", conf.Driver)
+	}
+}
+
+// logMailer はメールを送信せずログに出力します。ローカル開発用です。
+type logMailer struct{}
+
+func NewLogMailer() Mailer {
+	return logMailer{}
+}
+
+func (logMailer) Send(_ context.Context, msg Message) error {
+	log.Printf("Mail to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// fileMailer はメールを1通ずつ.emlファイルとしてdirに書き出します。ローカル開発とテスト用です。
+type fileMailer struct {
+	dir string
+	seq atomic.Uint64
+}
+
+func NewFileMailer(dir string) (Mailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &fileMailer{dir: dir}, nil
+}
+
+// timeNow はテストで現在時刻を差し替えるための変数です。
+var timeNow = time.Now
+
+func (fm *fileMailer) Send(_ context.Context, msg Message) error {
+	now := timeNow()
+	// 同じ時刻に送信したメールを上書きしないよう連番を付ける
+	name := fmt.Sprintf("%s-%04d.eml", now.UTC().Format("20060102T150405.000000000Z"), fm.seq.Add(1))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", msg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(msg.Body)
+
+	return os.WriteFile(filepath.Join(fm.dir, name), []byte(b.String()), 0o600)
+}
@@ -0,0 +1,79 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tusmasoma/campfinder/docker/back/config"
+)
+
+func TestFileMailer_Send(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	sentAt := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return sentAt }
+	t.Cleanup(func() { timeNow = time.Now })
+
+	mailer, err := NewFileMailer(dir)
+	if err != nil {
+		t.Fatalf("NewFileMailer() error = %v", err)
+	}
+	msg := Message{
+		From:    "no-reply@campfinder.local",
+		To:      "test@gmail.com",
+		Subject: "Verify your email",
+		Body:    "https://campfinder.example/verify-email?token=abc",
+	}
+	// 同じ時刻に送っても別のファイルになる
+	for i := 0; i < 2; i++ {
+		if err = mailer.Send(context.Background(), msg); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Send() wrote %d files, want 2", len(files))
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"To: test@gmail.com\r\n", "Subject: Verify your email\r\n", msg.Body} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("mail = %q, want to contain %q", data, want)
+		}
+	}
+}
+
+func TestNewMailer(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name    string
+		driver  string
+		wantErr bool
+	}{
+		{name: "log", driver: DriverLog},
+		{name: "file", driver: DriverFile},
+		{name: "Fail: unknown driver", driver: "smtp", wantErr: true},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+
+			_, err := NewMailer(&config.MailConfig{Driver: tt.driver, Dir: t.TempDir()})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewMailer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,434 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tusmasoma/campfinder/docker/back/config"
+	"github.com/tusmasoma/campfinder/docker/back/internal/auth"
+)
+
+const (
+	discoveryPath = "/.well-known/openid-configuration"
+	// CodeChallengeMethod はPKCEのcode_challenge_methodです。plainは使いません。
+	CodeChallengeMethod = "S256"
+
+	randomTokenBytes  = 32
+	httpClientTimeout = 10 * time.Second
+	maxResponseBytes  = 1 << 20
+	expectedJWTParts  = 3
+	// clockSkew はIDプロバイダとの時刻のずれの許容範囲です。
+	clockSkew = time.Minute
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown oidc provider")
+	ErrInvalidIDToken  = errors.New("invalid id token")
+	ErrTokenExchange   = errors.New("oidc token exchange failed")
+)
+
+// timeNow はテストで現在時刻を差し替えるための変数です。
+var timeNow = time.Now
+
+// Claims はIDトークンから取り出すユーザの情報です。SubjectはIDプロバイダ内で一意なユーザのIDです。
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Provider は1つのIDプロバイダに対する、認可コードフロー(PKCE)のRelying Partyです。
+type Provider interface {
+	Name() string
+	// AuthCodeURL はユーザをリダイレクトさせる認可エンドポイントのURLを返します。
+	AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error)
+	// Exchange は認可コードをトークンに交換し、検証したIDトークンのクレームを返します。
+	Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (*Claims, error)
+}
+
+// Providers はプロバイダ名をキーにしたProviderです。
+type Providers map[string]Provider
+
+func NewProviders(conf *config.OIDCConfig) Providers {
+	client := &http.Client{Timeout: httpClientTimeout}
+	providers := make(Providers, len(conf.Providers))
+	for _, pc := range conf.Providers {
+		providers[pc.Name] = NewProvider(pc, client)
+	}
+	return providers
+}
+
+func (p Providers) Get(name string) (Provider, error) {
+	provider, ok := p[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownProvider, name)
+	}
+	return provider, nil
+}
+
+// RandomToken はstate・nonce・PKCEのcode_verifierに使う推測できないランダムな文字列を返します。
+func RandomToken() (string, error) {
+	b := make([]byte, randomTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CodeChallenge はcode_verifierからS256のcode_challengeを計算します(RFC 7636)。
+func CodeChallenge(codeVerifier string) string {
+	hashed := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(hashed[:])
+}
+
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type verificationKey struct {
+	key any
+	alg string
+}
+
+// provider はディスカバリの結果とJWKSをメモリに保持します。起動時にIDプロバイダが停止していてもサーバを起動できるよう、
+// 初めて使うときに取得します。JWKSは知らないkidのIDトークンを受け取ったときに取得し直します。
+type provider struct {
+	conf   config.OIDCProviderConfig
+	client *http.Client
+
+	mu       sync.Mutex
+	metadata *metadata
+	keys     map[string]verificationKey
+}
+
+func NewProvider(conf config.OIDCProviderConfig, client *http.Client) Provider {
+	return &provider{
+		conf:   conf,
+		client: client,
+	}
+}
+
+func (p *provider) Name() string {
+	return p.conf.Name
+}
+
+func (p *provider) AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+	md, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(md.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+	query := u.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.conf.ClientID)
+	query.Set("redirect_uri", p.conf.RedirectURL)
+	query.Set("scope", strings.Join(p.conf.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", CodeChallengeMethod)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+type tokenResponse struct {
+	IDToken string `json:"id_token"`
+	Error   string `json:"error"`
+}
+
+func (p *provider) Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (*Claims, error) {
+	md, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.conf.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	if p.conf.ClientSecret == "" {
+		// シークレットを持たないパブリッククライアント
+		form.Set("client_id", p.conf.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, md.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.conf.ClientSecret != "" {
+		// client_secret_basic。RFC 6749 2.3.1によりIDとシークレットはフォームエンコードする
+		req.SetBasicAuth(url.QueryEscape(p.conf.ClientID), url.QueryEscape(p.conf.ClientSecret))
+	}
+
+	var tr tokenResponse
+	status, err := p.doJSON(req, &tr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTokenExchange, err)
+	}
+	if status != http.StatusOK || tr.Error != "" {
+		return nil, fmt.Errorf("%w: status %d %s", ErrTokenExchange, status, tr.Error)
+	}
+	if tr.IDToken == "" {
+		return nil, fmt.Errorf("%w: id_token is missing", ErrTokenExchange)
+	}
+	return p.verifyIDToken(ctx, md, tr.IDToken, nonce)
+}
+
+// discover はOpenID Connect Discoveryでエンドポイントを取得します。issuerが設定と異なる場合はなりすましとして拒否します。
+func (p *provider) discover(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	discoveryURL := strings.TrimSuffix(p.conf.Issuer, "/") + discoveryPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	var md metadata
+	status, err := p.doJSON(req, &md)
+	if err != nil {
+		return nil, fmt.Errorf("oidc discovery failed: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("oidc discovery failed: status %d", status)
+	}
+	if md.Issuer != p.conf.Issuer {
+		return nil, fmt.Errorf("oidc discovery issuer %q does not match %q", md.Issuer, p.conf.Issuer)
+	}
+	if md.AuthorizationEndpoint == "" || md.TokenEndpoint == "" || md.JWKSURI == "" {
+		return nil, fmt.Errorf("oidc discovery document is missing endpoints")
+	}
+	p.metadata = &md
+	return p.metadata, nil
+}
+
+// verificationKey はkidの公開鍵を返します。kidを付けないIDプロバイダの場合は鍵が1つのときのみ使います。
+func (p *provider) verificationKey(ctx context.Context, md *metadata, kid string) (verificationKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	// 鍵のローテーションで知らないkidになった場合はJWKSを取得し直す
+	if err := p.fetchKeys(ctx, md); err != nil {
+		return verificationKey{}, err
+	}
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return verificationKey{}, fmt.Errorf("%w: unknown kid %q", ErrInvalidIDToken, kid)
+}
+
+func (p *provider) lookupKey(kid string) (verificationKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (p *provider) fetchKeys(ctx context.Context, md *metadata) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, md.JWKSURI, nil)
+	if err != nil {
+		return err
+	}
+	var jwks auth.JWKS
+	status, err := p.doJSON(req, &jwks)
+	if err != nil {
+		return fmt.Errorf("failed to fetch oidc jwks: %w", err)
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("failed to fetch oidc jwks: status %d", status)
+	}
+
+	keys := make(map[string]verificationKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		// 暗号化用の鍵や対応していない種類の鍵は無視する
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		pub, alg, keyErr := jwk.PublicKey()
+		if keyErr != nil {
+			continue
+		}
+		keys[jwk.Kid] = verificationKey{key: pub, alg: alg}
+	}
+	p.keys = keys
+	return nil
+}
+
+type idTokenHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type idTokenClaims struct {
+	Issuer          string   `json:"iss"`
+	Subject         string   `json:"sub"`
+	Audience        audience `json:"aud"`
+	AuthorizedParty string   `json:"azp"`
+	Expiry          int64    `json:"exp"`
+	IssuedAt        int64    `json:"iat"`
+	Nonce           string   `json:"nonce"`
+	Email           string   `json:"email"`
+	EmailVerified   flexBool `json:"email_verified"`
+	Name            string   `json:"name"`
+}
+
+// verifyIDToken はIDトークンの署名とクレームを検証します(OpenID Connect Core 3.1.3.7)。
+func (p *provider) verifyIDToken(ctx context.Context, md *metadata, idToken string, nonce string) (*Claims, error) {
+	parts := strings.Split(idToken, ".")
+	if len(parts) != expectedJWTParts {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidIDToken)
+	}
+	var header idTokenHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidIDToken, err)
+	}
+	// "none"やHS256などの対称鍵のアルゴリズムはGetSigningMethodで拒否される
+	method, err := auth.GetSigningMethod(header.Alg)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidIDToken, err)
+	}
+	key, err := p.verificationKey(ctx, md, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if key.alg != header.Alg {
+		return nil, fmt.Errorf("%w: alg %s for %s key", ErrInvalidIDToken, header.Alg, key.alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidIDToken, err)
+	}
+	if err = method.Verify(key.key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidIDToken, err)
+	}
+
+	var claims idTokenClaims
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidIDToken, err)
+	}
+	if err = p.validateClaims(md, claims, nonce); err != nil {
+		return nil, err
+	}
+	return &Claims{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+	}, nil
+}
+
+func (p *provider) validateClaims(md *metadata, claims idTokenClaims, nonce string) error {
+	now := timeNow()
+	switch {
+	case claims.Issuer != md.Issuer:
+		return fmt.Errorf("%w: issuer %q", ErrInvalidIDToken, claims.Issuer)
+	case claims.Subject == "":
+		return fmt.Errorf("%w: sub is missing", ErrInvalidIDToken)
+	case !slices.Contains(claims.Audience, p.conf.ClientID):
+		return fmt.Errorf("%w: audience %v", ErrInvalidIDToken, claims.Audience)
+	case len(claims.Audience) > 1 && claims.AuthorizedParty != p.conf.ClientID:
+		return fmt.Errorf("%w: azp %q", ErrInvalidIDToken, claims.AuthorizedParty)
+	case claims.Expiry == 0 || !now.Before(time.Unix(claims.Expiry, 0).Add(clockSkew)):
+		return fmt.Errorf("%w: token is expired", ErrInvalidIDToken)
+	case time.Unix(claims.IssuedAt, 0).After(now.Add(clockSkew)):
+		return fmt.Errorf("%w: token is issued in the future", ErrInvalidIDToken)
+	case nonce == "" || claims.Nonce != nonce:
+		// 認可リクエストに付けたnonceと一致しないIDトークンは、リプレイされた可能性がある
+		return fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+	return nil
+}
+
+func (p *provider) doJSON(req *http.Request, v any) (int, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	// エラーレスポンスはJSONでない場合もあるため、ステータスコードのみ返す
+	if err = json.Unmarshal(body, v); err != nil && resp.StatusCode == http.StatusOK {
+		return resp.StatusCode, fmt.Errorf("invalid JSON response: %w", err)
+	}
+	return resp.StatusCode, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("decoding failed: %w", err)
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("JSON unmarshalling failed: %w", err)
+	}
+	return nil
+}
+
+// audience はaudクレームで、文字列と文字列の配列のどちらも受け付けます。
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*a = multiple
+	return nil
+}
+
+// flexBool はemail_verifiedで、文字列の"true"を返すIDプロバイダにも対応します。
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch value := v.(type) {
+	case bool:
+		*b = flexBool(value)
+	case string:
+		*b = flexBool(value == "true")
+	default:
+		*b = false
+	}
+	return nil
+}
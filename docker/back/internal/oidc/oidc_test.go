@@ -0,0 +1,358 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tusmasoma/campfinder/docker/back/config"
+	"github.com/tusmasoma/campfinder/docker/back/internal/auth"
+)
+
+const (
+	testClientID     = "campfinder"
+	testClientSecret = "s3cret&"
+	testRedirectURL  = "https://campfinder.example/api/user/oidc/stub/callback"
+)
+
+// stubIdP はテスト用のIDプロバイダです。認可リクエストの内容を保存して認可コードを発行し、
+// トークンエンドポイントでPKCEを検証してからES256で署名したIDトークンを返します。
+type stubIdP struct {
+	*httptest.Server
+	t *testing.T
+
+	mu     sync.Mutex
+	key    *ecdsa.PrivateKey
+	kid    string
+	grants map[string]url.Values
+	// header と claims はIDトークンを書き換えるテストで使います。
+	header func(map[string]any)
+	claims func(map[string]any)
+}
+
+func newStubIdP(t *testing.T) *stubIdP {
+	t.Helper()
+	idp := &stubIdP{t: t, grants: map[string]url.Values{}}
+	idp.rotateKey("key-1")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(discoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{
+			"issuer":                 idp.URL,
+			"authorization_endpoint": idp.URL + "/authorize",
+			"token_endpoint":         idp.URL + "/token",
+			"jwks_uri":               idp.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", idp.handleJWKS)
+	mux.HandleFunc("/token", idp.handleToken)
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+	return idp
+}
+
+func (idp *stubIdP) rotateKey(kid string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	idp.key, idp.kid = key, kid
+}
+
+func (idp *stubIdP) handleJWKS(w http.ResponseWriter, _ *http.Request) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+	writeJSON(w, http.StatusOK, auth.JWKS{Keys: []auth.JWK{
+		// 暗号化用の鍵は検証に使わない
+		{Kty: "RSA", Use: "enc", Kid: "enc-key", N: "AQAB", E: "AQAB"},
+		{
+			Kty: "EC",
+			Use: "sig",
+			Alg: auth.AlgES256,
+			Kid: idp.kid,
+			Crv: "P-256",
+			X:   base64.RawURLEncoding.EncodeToString(idp.key.X.FillBytes(x)),
+			Y:   base64.RawURLEncoding.EncodeToString(idp.key.Y.FillBytes(y)),
+		},
+	}})
+}
+
+// authorize はユーザが認可エンドポイントでログインしたものとして、認可コードを返します。
+func (idp *stubIdP) authorize(authURL string) string {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+	query := u.Query()
+	if query.Get("response_type") != "code" || query.Get("client_id") != testClientID ||
+		query.Get("redirect_uri") != testRedirectURL || query.Get("code_challenge_method") != CodeChallengeMethod {
+		idp.t.Fatalf("unexpected authorization request: %v", authURL)
+	}
+	code, _ := RandomToken()
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	idp.grants[code] = query
+	return code
+}
+
+func (idp *stubIdP) handleToken(w http.ResponseWriter, r *http.Request) {
+	id, secret, ok := r.BasicAuth()
+	clientID, _ := url.QueryUnescape(id)
+	clientSecret, _ := url.QueryUnescape(secret)
+	if !ok || clientID != testClientID || clientSecret != testClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	// 認可コードは一度しか使えない
+	grant, ok := idp.grants[r.PostForm.Get("code")]
+	delete(idp.grants, r.PostForm.Get("code"))
+	if !ok || r.PostForm.Get("redirect_uri") != grant.Get("redirect_uri") ||
+		CodeChallenge(r.PostForm.Get("code_verifier")) != grant.Get("code_challenge") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := timeNow()
+	header := map[string]any{"alg": auth.AlgES256, "kid": idp.kid, "typ": "JWT"}
+	claims := map[string]any{
+		"iss":            idp.URL,
+		"sub":            "stub-user-1",
+		"aud":            testClientID,
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          grant.Get("nonce"),
+		"email":          "test@gmail.com",
+		"email_verified": true,
+		"name":           "Test User",
+	}
+	if idp.header != nil {
+		idp.header(header)
+	}
+	if idp.claims != nil {
+		idp.claims(claims)
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"access_token": "stub-access-token",
+		"token_type":   "Bearer",
+		"id_token":     idp.sign(header, claims),
+	})
+}
+
+func (idp *stubIdP) sign(header, claims map[string]any) string {
+	headerBytes, _ := json.Marshal(header)
+	claimsBytes, _ := json.Marshal(claims)
+	data := base64.RawURLEncoding.EncodeToString(headerBytes) + "." + base64.RawURLEncoding.EncodeToString(claimsBytes)
+	method, _ := auth.GetSigningMethod(auth.AlgES256)
+	signature, err := method.Sign(crypto.Signer(idp.key), []byte(data))
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+	return data + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func newTestProvider(idp *stubIdP) Provider {
+	return NewProvider(config.OIDCProviderConfig{
+		Name:         "stub",
+		Issuer:       idp.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+		Scopes:       []string{"openid", "email"},
+	}, idp.Client())
+}
+
+// login は認可リクエストからトークンの交換までを行います。
+func login(t *testing.T, idp *stubIdP, p Provider, nonce string) (*Claims, error) {
+	t.Helper()
+	ctx := context.Background()
+	verifier, _ := RandomToken()
+	authURL, err := p.AuthCodeURL(ctx, "state-1", nonce, CodeChallenge(verifier))
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+	return p.Exchange(ctx, idp.authorize(authURL), verifier, nonce)
+}
+
+func TestProvider_Login(t *testing.T) {
+	t.Parallel()
+
+	idp := newStubIdP(t)
+	p := newTestProvider(idp)
+
+	authURL, err := p.AuthCodeURL(context.Background(), "state-1", "nonce-1", CodeChallenge("verifier"))
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+	if !strings.HasPrefix(authURL, idp.URL+"/authorize?") || !strings.Contains(authURL, "scope=openid+email") ||
+		!strings.Contains(authURL, "state=state-1") || !strings.Contains(authURL, "nonce=nonce-1") {
+		t.Errorf("AuthCodeURL() = %v", authURL)
+	}
+
+	got, err := login(t, idp, p, "nonce-1")
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	want := Claims{Subject: "stub-user-1", Email: "test@gmail.com", EmailVerified: true, Name: "Test User"}
+	if *got != want {
+		t.Errorf("Exchange() = %+v, want %+v", *got, want)
+	}
+
+	// IDプロバイダが鍵をローテーションしても、JWKSを取得し直して検証できる
+	idp.rotateKey("key-2")
+	if _, err = login(t, idp, p, "nonce-2"); err != nil {
+		t.Errorf("Exchange() after key rotation error = %v", err)
+	}
+}
+
+func TestProvider_Exchange_PKCE(t *testing.T) {
+	t.Parallel()
+
+	idp := newStubIdP(t)
+	p := newTestProvider(idp)
+	ctx := context.Background()
+
+	authURL, err := p.AuthCodeURL(ctx, "state-1", "nonce-1", CodeChallenge("verifier"))
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+	// 認可コードを盗んでもcode_verifierを知らなければ交換できない
+	_, err = p.Exchange(ctx, idp.authorize(authURL), "stolen", "nonce-1")
+	if !errors.Is(err, ErrTokenExchange) {
+		t.Errorf("Exchange() error = %v, want %v", err, ErrTokenExchange)
+	}
+}
+
+func TestProvider_Exchange_InvalidIDToken(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name   string
+		header func(map[string]any)
+		claims func(map[string]any)
+		nonce  string
+	}{
+		{
+			name:  "nonce mismatch",
+			nonce: "other-nonce",
+		},
+		{
+			name:   "issuer mismatch",
+			claims: func(c map[string]any) { c["iss"] = "https://evil.example" },
+		},
+		{
+			name:   "audience mismatch",
+			claims: func(c map[string]any) { c["aud"] = "other-client" },
+		},
+		{
+			name:   "multiple audiences without azp",
+			claims: func(c map[string]any) { c["aud"] = []string{testClientID, "other-client"} },
+		},
+		{
+			name:   "expired",
+			claims: func(c map[string]any) { c["exp"] = timeNow().Add(-2 * clockSkew).Unix() },
+		},
+		{
+			name:   "missing sub",
+			claims: func(c map[string]any) { delete(c, "sub") },
+		},
+		{
+			name:   "alg none",
+			header: func(h map[string]any) { h["alg"] = "none" },
+		},
+		{
+			name:   "HS256",
+			header: func(h map[string]any) { h["alg"] = "HS256" },
+		},
+		{
+			name:   "alg does not match key",
+			header: func(h map[string]any) { h["alg"] = auth.AlgRS256 },
+		},
+		{
+			name:   "unknown kid",
+			header: func(h map[string]any) { h["kid"] = "unknown" },
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+
+			idp := newStubIdP(t)
+			idp.header, idp.claims = tt.header, tt.claims
+			nonce := "nonce-1"
+			p := newTestProvider(idp)
+			ctx := context.Background()
+
+			verifier, _ := RandomToken()
+			authURL, err := p.AuthCodeURL(ctx, "state-1", nonce, CodeChallenge(verifier))
+			if err != nil {
+				t.Fatalf("AuthCodeURL() error = %v", err)
+			}
+			code := idp.authorize(authURL)
+			if tt.nonce != "" {
+				nonce = tt.nonce
+			}
+			if _, err = p.Exchange(ctx, code, verifier, nonce); !errors.Is(err, ErrInvalidIDToken) {
+				t.Errorf("Exchange() error = %v, want %v", err, ErrInvalidIDToken)
+			}
+		})
+	}
+}
+
+func TestProvider_DiscoveryIssuerMismatch(t *testing.T) {
+	t.Parallel()
+
+	idp := newStubIdP(t)
+	p := NewProvider(config.OIDCProviderConfig{
+		Name:        "stub",
+		Issuer:      idp.URL + "/",
+		ClientID:    testClientID,
+		RedirectURL: testRedirectURL,
+	}, idp.Client())
+
+	if _, err := p.AuthCodeURL(context.Background(), "state", "nonce", "challenge"); err == nil {
+		t.Error("AuthCodeURL() error = nil, want issuer mismatch")
+	}
+}
+
+func TestProviders_Get(t *testing.T) {
+	t.Parallel()
+
+	providers := NewProviders(&config.OIDCConfig{Providers: []config.OIDCProviderConfig{{Name: "google"}}})
+	if p, err := providers.Get("google"); err != nil || p.Name() != "google" {
+		t.Errorf("Get(google) = %v, %v", p, err)
+	}
+	if _, err := providers.Get("github"); !errors.Is(err, ErrUnknownProvider) {
+		t.Errorf("Get(github) error = %v, want %v", err, ErrUnknownProvider)
+	}
+}
@@ -0,0 +1,255 @@
+package spotexport
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+)
+
+type Format string
+
+const (
+	FormatGeoJSON Format = "geojson"
+	FormatGPX     Format = "gpx"
+	FormatKML     Format = "kml"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+// Encoder はSpotを1件ずつ書き出します。NewEncoderでヘッダを書き出し、Closeでフッタを書き出します。
+type Encoder interface {
+	Encode(spot model.Spot) error
+	Close() error
+}
+
+func NewEncoder(format Format, w io.Writer) (Encoder, error) {
+	switch format {
+	case FormatGeoJSON:
+		return newGeoJSONEncoder(w)
+	case FormatGPX:
+		return newGPXEncoder(w)
+	case FormatKML:
+		return newKMLEncoder(w)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+}
+
+func ContentType(format Format) string {
+	switch format {
+	case FormatGeoJSON:
+		return "application/geo+json"
+	case FormatGPX:
+		return "application/gpx+xml"
+	case FormatKML:
+		return "application/vnd.google-earth.kml+xml"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+func IsValidFormat(format Format) bool {
+	return format == FormatGeoJSON || format == FormatGPX || format == FormatKML
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+type geoJSONEncoder struct {
+	w     io.Writer
+	count int
+}
+
+type geoJSONFeature struct {
+	Type       string            `json:"type"`
+	ID         string            `json:"id"`
+	Geometry   geoJSONPoint      `json:"geometry"`
+	Properties geoJSONProperties `json:"properties"`
+}
+
+type geoJSONPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+type geoJSONProperties struct {
+	Name          string  `json:"name"`
+	Category      string  `json:"category"`
+	Address       string  `json:"address"`
+	Price         string  `json:"price"`
+	RatingAverage float64 `json:"ratingAverage"`
+	RatingCount   int     `json:"ratingCount"`
+}
+
+func newGeoJSONEncoder(w io.Writer) (*geoJSONEncoder, error) {
+	if _, err := io.WriteString(w, `{"type":"FeatureCollection","features":[`); err != nil {
+		return nil, err
+	}
+	return &geoJSONEncoder{w: w}, nil
+}
+
+func (e *geoJSONEncoder) Encode(spot model.Spot) error {
+	b, err := json.Marshal(geoJSONFeature{
+		Type: "Feature",
+		ID:   spot.ID.String(),
+		// GeoJSONの座標は経度, 緯度の順
+		Geometry: geoJSONPoint{Type: "Point", Coordinates: [2]float64{spot.Lng, spot.Lat}},
+		Properties: geoJSONProperties{
+			Name:          spot.Name,
+			Category:      spot.Category,
+			Address:       spot.Address,
+			Price:         spot.Price,
+			RatingAverage: spot.RatingAverage,
+			RatingCount:   spot.RatingCount,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if e.count > 0 {
+		if _, err = io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	e.count++
+	_, err = e.w.Write(b)
+	return err
+}
+
+func (e *geoJSONEncoder) Close() error {
+	_, err := io.WriteString(e.w, "]}\n")
+	return err
+}
+
+// xmlEncoder はGPXとKMLで共通の、要素を1件ずつ書き出す処理です。
+type xmlEncoder struct {
+	w      io.Writer
+	enc    *xml.Encoder
+	footer string
+}
+
+func newXMLEncoder(w io.Writer, header, footer string) (*xmlEncoder, error) {
+	if _, err := io.WriteString(w, xml.Header+header); err != nil {
+		return nil, err
+	}
+	return &xmlEncoder{w: w, enc: xml.NewEncoder(w), footer: footer}, nil
+}
+
+func (e *xmlEncoder) encode(v any) error {
+	if err := e.enc.Encode(v); err != nil {
+		return err
+	}
+	return e.enc.Flush()
+}
+
+func (e *xmlEncoder) Close() error {
+	_, err := io.WriteString(e.w, e.footer)
+	return err
+}
+
+// gpxNamespace はGPXのextensionsに出力する、GPX標準にない項目の名前空間です。
+const gpxNamespace = "urn:campfinder:spot"
+
+type gpxEncoder struct {
+	*xmlEncoder
+}
+
+type gpxWaypoint struct {
+	XMLName    xml.Name      `xml:"wpt"`
+	Lat        string        `xml:"lat,attr"`
+	Lon        string        `xml:"lon,attr"`
+	Name       string        `xml:"name"`
+	Desc       string        `xml:"desc,omitempty"`
+	Type       string        `xml:"type,omitempty"`
+	Extensions gpxExtensions `xml:"extensions"`
+}
+
+type gpxExtensions struct {
+	Category      string `xml:"campfinder:category"`
+	Address       string `xml:"campfinder:address"`
+	Price         string `xml:"campfinder:price"`
+	RatingAverage string `xml:"campfinder:ratingAverage"`
+	RatingCount   int    `xml:"campfinder:ratingCount"`
+}
+
+func newGPXEncoder(w io.Writer) (*gpxEncoder, error) {
+	e, err := newXMLEncoder(
+		w,
+		`<gpx version="1.1" creator="campfinder" xmlns="http://www.topografix.com/GPX/1/1" `+
+			`xmlns:campfinder="`+gpxNamespace+`">`+"\n",
+		"\n</gpx>\n",
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &gpxEncoder{e}, nil
+}
+
+func (e *gpxEncoder) Encode(spot model.Spot) error {
+	return e.encode(gpxWaypoint{
+		Lat:  formatFloat(spot.Lat),
+		Lon:  formatFloat(spot.Lng),
+		Name: spot.Name,
+		Desc: spot.Address,
+		Type: spot.Category,
+		Extensions: gpxExtensions{
+			Category:      spot.Category,
+			Address:       spot.Address,
+			Price:         spot.Price,
+			RatingAverage: formatFloat(spot.RatingAverage),
+			RatingCount:   spot.RatingCount,
+		},
+	})
+}
+
+type kmlEncoder struct {
+	*xmlEncoder
+}
+
+type kmlPlacemark struct {
+	XMLName      xml.Name  `xml:"Placemark"`
+	ID           string    `xml:"id,attr"`
+	Name         string    `xml:"name"`
+	Address      string    `xml:"address"`
+	ExtendedData []kmlData `xml:"ExtendedData>Data"`
+	Coordinates  string    `xml:"Point>coordinates"`
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+func newKMLEncoder(w io.Writer) (*kmlEncoder, error) {
+	e, err := newXMLEncoder(
+		w,
+		`<kml xmlns="http://www.opengis.net/kml/2.2">`+"\n<Document>\n",
+		"\n</Document>\n</kml>\n",
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &kmlEncoder{e}, nil
+}
+
+func (e *kmlEncoder) Encode(spot model.Spot) error {
+	return e.encode(kmlPlacemark{
+		ID:      spot.ID.String(),
+		Name:    spot.Name,
+		Address: spot.Address,
+		ExtendedData: []kmlData{
+			{Name: "category", Value: spot.Category},
+			{Name: "price", Value: spot.Price},
+			{Name: "ratingAverage", Value: formatFloat(spot.RatingAverage)},
+			{Name: "ratingCount", Value: strconv.Itoa(spot.RatingCount)},
+		},
+		// KMLの座標も経度, 緯度の順
+		Coordinates: formatFloat(spot.Lng) + "," + formatFloat(spot.Lat),
+	})
+}
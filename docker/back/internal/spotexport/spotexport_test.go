@@ -0,0 +1,155 @@
+package spotexport
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+)
+
+var testSpots = []model.Spot{
+	{
+		ID:            uuid.MustParse("5c5323e9-c78f-4dac-94ef-d34ab5ea8fed"),
+		Category:      "campsite",
+		Name:          "旭川市21世紀の森 & キャンプ場",
+		Address:       "北海道旭川市",
+		Lat:           43.7172721,
+		Lng:           142.6674615,
+		Price:         "無料",
+		RatingAverage: 4.5,
+		RatingCount:   2,
+	},
+	{
+		ID:       uuid.MustParse("2b5a4b1e-3f6c-4a8e-9d6b-7e8f9a0b1c2d"),
+		Category: "spa",
+		Name:     "層雲峡温泉",
+		Lat:      43.7,
+		Lng:      142.9,
+	},
+}
+
+func encodeAll(t *testing.T, format Format, spots []model.Spot) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	encoder, err := NewEncoder(format, &buf)
+	if err != nil {
+		t.Fatalf("NewEncoder() error = %v", err)
+	}
+	for _, spot := range spots {
+		if err = encoder.Encode(spot); err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+	}
+	if err = encoder.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func Test_GeoJSONEncoder(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name  string
+		spots []model.Spot
+	}{
+		{name: "spots", spots: testSpots},
+		{name: "empty", spots: nil},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got struct {
+				Type     string           `json:"type"`
+				Features []geoJSONFeature `json:"features"`
+			}
+			if err := json.Unmarshal(encodeAll(t, FormatGeoJSON, tt.spots), &got); err != nil {
+				t.Fatalf("invalid geojson: %v", err)
+			}
+			if got.Type != "FeatureCollection" || len(got.Features) != len(tt.spots) {
+				t.Fatalf("got %+v", got)
+			}
+			for i, spot := range tt.spots {
+				f := got.Features[i]
+				if f.Geometry.Coordinates != [2]float64{spot.Lng, spot.Lat} ||
+					f.Properties.Name != spot.Name ||
+					f.Properties.Category != spot.Category ||
+					f.Properties.Address != spot.Address ||
+					f.Properties.Price != spot.Price ||
+					f.Properties.RatingAverage != spot.RatingAverage {
+					t.Errorf("feature[%d] = %+v, spot %+v", i, f, spot)
+				}
+			}
+		})
+	}
+}
+
+func Test_GPXEncoder(t *testing.T) {
+	t.Parallel()
+
+	var got struct {
+		XMLName   xml.Name `xml:"gpx"`
+		Waypoints []struct {
+			Lat        string `xml:"lat,attr"`
+			Lon        string `xml:"lon,attr"`
+			Name       string `xml:"name"`
+			Extensions struct {
+				Price         string `xml:"price"`
+				RatingAverage string `xml:"ratingAverage"`
+			} `xml:"extensions"`
+		} `xml:"wpt"`
+	}
+	if err := xml.Unmarshal(encodeAll(t, FormatGPX, testSpots), &got); err != nil {
+		t.Fatalf("invalid gpx: %v", err)
+	}
+	if len(got.Waypoints) != len(testSpots) {
+		t.Fatalf("got %d waypoints, want %d", len(got.Waypoints), len(testSpots))
+	}
+	wpt := got.Waypoints[0]
+	if wpt.Lat != "43.7172721" || wpt.Lon != "142.6674615" || wpt.Name != testSpots[0].Name ||
+		wpt.Extensions.Price != "無料" || wpt.Extensions.RatingAverage != "4.5" {
+		t.Errorf("waypoint = %+v", wpt)
+	}
+}
+
+func Test_KMLEncoder(t *testing.T) {
+	t.Parallel()
+
+	var got struct {
+		XMLName    xml.Name `xml:"kml"`
+		Placemarks []struct {
+			Name        string    `xml:"name"`
+			Address     string    `xml:"address"`
+			Data        []kmlData `xml:"ExtendedData>Data"`
+			Coordinates string    `xml:"Point>coordinates"`
+		} `xml:"Document>Placemark"`
+	}
+	if err := xml.Unmarshal(encodeAll(t, FormatKML, testSpots), &got); err != nil {
+		t.Fatalf("invalid kml: %v", err)
+	}
+	if len(got.Placemarks) != len(testSpots) {
+		t.Fatalf("got %d placemarks, want %d", len(got.Placemarks), len(testSpots))
+	}
+	placemark := got.Placemarks[0]
+	if placemark.Name != testSpots[0].Name || placemark.Address != "北海道旭川市" ||
+		placemark.Coordinates != "142.6674615,43.7172721" ||
+		len(placemark.Data) != 4 || placemark.Data[0] != (kmlData{Name: "category", Value: "campsite"}) {
+		t.Errorf("placemark = %+v", placemark)
+	}
+}
+
+func Test_NewEncoder(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewEncoder("csv", &bytes.Buffer{}); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("NewEncoder() error = %v, want %v", err, ErrUnsupportedFormat)
+	}
+}
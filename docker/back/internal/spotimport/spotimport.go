@@ -0,0 +1,232 @@
+package spotimport
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+)
+
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatGeoJSON Format = "geojson"
+
+	geoJSONPointCoordinates = 2
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported import format")
+	ErrInvalidData       = errors.New("invalid import data")
+)
+
+// Fields はインポートで読み込むSpotのフィールド名です。
+var Fields = []string{
+	"category",
+	"name",
+	"address",
+	"lat",
+	"lng",
+	"period",
+	"phone",
+	"price",
+	"description",
+	"iconpath",
+}
+
+// ColumnMapping はSpotのフィールド名から、CSVのヘッダ名またはGeoJSONのプロパティ名への対応です。
+// 指定のないフィールドはフィールド名と同じ名前の列を読み込みます。
+type ColumnMapping map[string]string
+
+func (m ColumnMapping) column(field string) string {
+	if column, ok := m[field]; ok && column != "" {
+		return column
+	}
+	return field
+}
+
+// ParseColumnMapping は "name=施設名,address=住所" 形式の対応を読み込みます。
+func ParseColumnMapping(s string) (ColumnMapping, error) {
+	mapping := ColumnMapping{}
+	if strings.TrimSpace(s) == "" {
+		return mapping, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		field, column, ok := strings.Cut(pair, "=")
+		field = strings.TrimSpace(field)
+		if !ok || !isField(field) || strings.TrimSpace(column) == "" {
+			return nil, fmt.Errorf("invalid column mapping: %s", pair)
+		}
+		mapping[field] = strings.TrimSpace(column)
+	}
+	return mapping, nil
+}
+
+func isField(field string) bool {
+	for _, f := range Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Record は入力の1行(GeoJSONでは1Feature)の読み込み結果です。Rowは1始まりで、CSVのヘッダ行は含みません。
+// 読み込めなかった場合はErrに理由を設定します。
+type Record struct {
+	Row  int
+	Spot model.Spot
+	Err  error
+}
+
+func Parse(format Format, r io.Reader, mapping ColumnMapping) ([]Record, error) {
+	switch format {
+	case FormatCSV:
+		return ParseCSV(r, mapping)
+	case FormatGeoJSON:
+		return ParseGeoJSON(r, mapping)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+}
+
+// ParseCSV はヘッダ付きのCSVを読み込みます。ヘッダが読めない場合のみエラーを返し、行ごとのエラーはRecordに設定します。
+func ParseCSV(r io.Reader, mapping ColumnMapping) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read csv header: %w", ErrInvalidData, err)
+	}
+	indexes := make(map[string]int, len(header))
+	for i, column := range header {
+		// Excelで保存したCSVのBOMを取り除く
+		indexes[strings.TrimPrefix(strings.TrimSpace(column), "\ufeff")] = i
+	}
+
+	var records []Record
+	for row := 1; ; row++ {
+		values, readErr := reader.Read()
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			records = append(records, Record{Row: row, Err: readErr})
+			continue
+		}
+
+		props := make(map[string]string, len(Fields))
+		for _, field := range Fields {
+			if i, ok := indexes[mapping.column(field)]; ok && i < len(values) {
+				props[field] = strings.TrimSpace(values[i])
+			}
+		}
+		spot, convErr := toSpot(props)
+		records = append(records, Record{Row: row, Spot: spot, Err: convErr})
+	}
+	return records, nil
+}
+
+type featureCollection struct {
+	Type     string    `json:"type"`
+	Features []feature `json:"features"`
+}
+
+type feature struct {
+	Type       string                     `json:"type"`
+	Geometry   *geometry                  `json:"geometry"`
+	Properties map[string]json.RawMessage `json:"properties"`
+}
+
+type geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// point はPointの座標を返します。GeoJSONの座標は経度, 緯度の順です。Point以外のgeometryはfalseです。
+func (g *geometry) point() (float64, float64, bool) {
+	if g == nil || g.Type != "Point" {
+		return 0, 0, false
+	}
+	var coordinates []float64
+	if err := json.Unmarshal(g.Coordinates, &coordinates); err != nil || len(coordinates) < geoJSONPointCoordinates {
+		return 0, 0, false
+	}
+	return coordinates[0], coordinates[1], true
+}
+
+// ParseGeoJSON はPointのFeatureからなるFeatureCollectionを読み込みます。緯度経度はgeometryから取得します。
+func ParseGeoJSON(r io.Reader, mapping ColumnMapping) ([]Record, error) {
+	var fc featureCollection
+	if err := json.NewDecoder(r).Decode(&fc); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode geojson: %w", ErrInvalidData, err)
+	}
+	if fc.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("%w: geojson type must be FeatureCollection: %s", ErrInvalidData, fc.Type)
+	}
+
+	records := make([]Record, 0, len(fc.Features))
+	for i, f := range fc.Features {
+		row := i + 1
+		lng, lat, ok := f.Geometry.point()
+		if !ok {
+			records = append(records, Record{Row: row, Err: errors.New("geometry must be a Point")})
+			continue
+		}
+
+		props := make(map[string]string, len(Fields))
+		for _, field := range Fields {
+			if raw, ok := f.Properties[mapping.column(field)]; ok {
+				props[field] = propertyString(raw)
+			}
+		}
+		props["lng"] = strconv.FormatFloat(lng, 'f', -1, 64)
+		props["lat"] = strconv.FormatFloat(lat, 'f', -1, 64)
+
+		spot, err := toSpot(props)
+		records = append(records, Record{Row: row, Spot: spot, Err: err})
+	}
+	return records, nil
+}
+
+// propertyString は文字列以外のプロパティ(数値など)もそのまま文字列として扱います。
+func propertyString(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return strings.TrimSpace(s)
+	}
+	if string(raw) == "null" {
+		return ""
+	}
+	return strings.TrimSpace(string(raw))
+}
+
+func toSpot(props map[string]string) (model.Spot, error) {
+	spot := model.Spot{
+		Category:    props["category"],
+		Name:        props["name"],
+		Address:     props["address"],
+		Period:      props["period"],
+		Phone:       props["phone"],
+		Price:       props["price"],
+		Description: props["description"],
+		IconPath:    props["iconpath"],
+	}
+
+	var err error
+	if spot.Lat, err = strconv.ParseFloat(props["lat"], 64); err != nil {
+		return spot, fmt.Errorf("invalid lat: %q", props["lat"])
+	}
+	if spot.Lng, err = strconv.ParseFloat(props["lng"], 64); err != nil {
+		return spot, fmt.Errorf("invalid lng: %q", props["lng"])
+	}
+	return spot, nil
+}
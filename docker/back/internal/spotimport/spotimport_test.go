@@ -0,0 +1,217 @@
+package spotimport
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+)
+
+func Test_ParseCSV(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name    string
+		input   string
+		mapping ColumnMapping
+		want    []Record
+		wantErr error
+	}{
+		{
+			name: "default columns",
+			input: "category,name,address,lat,lng,period,phone,price,description,iconpath\n" +
+				"campsite,旭川市21世紀の森,北海道旭川市,43.7172721,142.6674615,4月~10月,0166-00-0000,無料,説明,/static/a.png\n",
+			want: []Record{
+				{
+					Row: 1,
+					Spot: model.Spot{
+						Category:    "campsite",
+						Name:        "旭川市21世紀の森",
+						Address:     "北海道旭川市",
+						Lat:         43.7172721,
+						Lng:         142.6674615,
+						Period:      "4月~10月",
+						Phone:       "0166-00-0000",
+						Price:       "無料",
+						Description: "説明",
+						IconPath:    "/static/a.png",
+					},
+				},
+			},
+		},
+		{
+			name:    "mapped columns",
+			input:   "\ufeff種別,施設名,住所,緯度,経度\ncampsite,旭川市21世紀の森,北海道旭川市,43.7172721,142.6674615\n",
+			mapping: ColumnMapping{"category": "種別", "name": "施設名", "address": "住所", "lat": "緯度", "lng": "経度"},
+			want: []Record{
+				{
+					Row: 1,
+					Spot: model.Spot{
+						Category: "campsite",
+						Name:     "旭川市21世紀の森",
+						Address:  "北海道旭川市",
+						Lat:      43.7172721,
+						Lng:      142.6674615,
+					},
+				},
+			},
+		},
+		{
+			name:  "invalid lat",
+			input: "category,name,address,lat,lng\ncampsite,森,北海道,abc,142.6\n",
+			want: []Record{
+				{
+					Row:  1,
+					Spot: model.Spot{Category: "campsite", Name: "森", Address: "北海道"},
+					Err:  errors.New(`invalid lat: "abc"`),
+				},
+			},
+		},
+		{
+			name:    "Fail: empty input",
+			input:   "",
+			wantErr: ErrInvalidData,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseCSV(strings.NewReader(tt.input), tt.mapping)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseCSV() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assertRecords(t, got, tt.want)
+		})
+	}
+}
+
+func Test_ParseGeoJSON(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name    string
+		input   string
+		mapping ColumnMapping
+		want    []Record
+		wantErr error
+	}{
+		{
+			name: "point features",
+			input: `{"type":"FeatureCollection","features":[
+				{"type":"Feature","geometry":{"type":"Point","coordinates":[142.6674615,43.7172721]},
+				 "properties":{"category":"campsite","施設名":"旭川市21世紀の森","address":"北海道旭川市","price":0}},
+				{"type":"Feature","geometry":{"type":"LineString","coordinates":[[142.6,43.7],[142.7,43.8]]},
+				 "properties":{"category":"campsite"}}
+			]}`,
+			mapping: ColumnMapping{"name": "施設名"},
+			want: []Record{
+				{
+					Row: 1,
+					Spot: model.Spot{
+						Category: "campsite",
+						Name:     "旭川市21世紀の森",
+						Address:  "北海道旭川市",
+						Lat:      43.7172721,
+						Lng:      142.6674615,
+						Price:    "0",
+					},
+				},
+				{
+					Row: 2,
+					Err: errors.New("geometry must be a Point"),
+				},
+			},
+		},
+		{
+			name:    "Fail: not a feature collection",
+			input:   `{"type":"Feature"}`,
+			wantErr: ErrInvalidData,
+		},
+		{
+			name:    "Fail: invalid json",
+			input:   `{"type":`,
+			wantErr: ErrInvalidData,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseGeoJSON(strings.NewReader(tt.input), tt.mapping)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseGeoJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assertRecords(t, got, tt.want)
+		})
+	}
+}
+
+func Test_ParseColumnMapping(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name    string
+		input   string
+		want    ColumnMapping
+		wantErr bool
+	}{
+		{
+			name:  "empty",
+			input: "",
+			want:  ColumnMapping{},
+		},
+		{
+			name:  "pairs",
+			input: "name=施設名, address = 住所",
+			want:  ColumnMapping{"name": "施設名", "address": "住所"},
+		},
+		{
+			name:    "Fail: unknown field",
+			input:   "rating=評価",
+			wantErr: true,
+		},
+		{
+			name:    "Fail: missing column",
+			input:   "name=",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseColumnMapping(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseColumnMapping() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseColumnMapping() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func assertRecords(t *testing.T, got, want []Record) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d records, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Row != want[i].Row || !reflect.DeepEqual(got[i].Spot, want[i].Spot) {
+			t.Errorf("record[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+		if (got[i].Err == nil) != (want[i].Err == nil) ||
+			(got[i].Err != nil && got[i].Err.Error() != want[i].Err.Error()) {
+			t.Errorf("record[%d] error = %v, want %v", i, got[i].Err, want[i].Err)
+		}
+	}
+}
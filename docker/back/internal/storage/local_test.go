@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tusmasoma/campfinder/docker/back/config"
+)
+
+func TestLocalStore(t *testing.T) {
+	dir := t.TempDir()
+	bs, err := NewLocalStore(dir, "http://localhost:8083/uploads/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	key := "spots/5fbc5e1c-0d63-4a4c-a1a4-1d3c2e6f7b90/photo.jpg"
+
+	if err = bs.Put(ctx, key, []byte("jpeg data"), "image/jpeg"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "spots", "5fbc5e1c-0d63-4a4c-a1a4-1d3c2e6f7b90", "photo.jpg"))
+	if err != nil || string(got) != "jpeg data" {
+		t.Fatalf("stored file = %q, %v", got, err)
+	}
+	if url := bs.URL(key); url != "http://localhost:8083/uploads/"+key {
+		t.Errorf("URL() = %v", url)
+	}
+
+	// 配信はファイルのみで、ディレクトリの一覧は返さない
+	server := NewLocalFileServer(dir)
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/"+key, nil))
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "jpeg data" {
+		t.Errorf("GET file = %d %q", recorder.Code, recorder.Body.String())
+	}
+	recorder = httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/spots/", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("GET directory = %d, want %d", recorder.Code, http.StatusNotFound)
+	}
+
+	rc, err := bs.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	rc.Close()
+	if _, err = bs.PresignPut(key, "image/jpeg", time.Minute); !errors.Is(err, ErrPresignNotSupported) {
+		t.Errorf("PresignPut() error = %v, want %v", err, ErrPresignNotSupported)
+	}
+
+	if err = bs.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err = bs.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() of deleted file error = %v, want %v", err, ErrNotFound)
+	}
+	if err = bs.Delete(ctx, key); err != nil {
+		t.Errorf("Delete() of missing file error = %v", err)
+	}
+
+	for _, invalid := range []string{"", "/etc/passwd", "../photo.jpg", "spots//photo.jpg", "spots/..\\photo.jpg"} {
+		if err = bs.Put(ctx, invalid, []byte("x"), "image/jpeg"); err != ErrInvalidKey {
+			t.Errorf("Put(%q) error = %v, want %v", invalid, err, ErrInvalidKey)
+		}
+	}
+}
+
+func TestNewBlobStore(t *testing.T) {
+	if _, err := NewBlobStore(&config.StorageConfig{Driver: DriverLocal, LocalDir: t.TempDir()}); err != nil {
+		t.Errorf("NewBlobStore(local) error = %v", err)
+	}
+	if _, err := NewBlobStore(&config.StorageConfig{Driver: "ftp"}); err == nil {
+		t.Errorf("NewBlobStore(ftp) should fail")
+	}
+}
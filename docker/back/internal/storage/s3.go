@@ -0,0 +1,332 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tusmasoma/campfinder/docker/back/config"
+)
+
+// s3Store はS3互換のオブジェクトストレージ(AWS S3、MinIOなど)にファイルを保存します。
+// MinIOなどで仮想ホスト形式が使えないことが多いため、endpoint/bucket/keyのパス形式でアクセスします。
+type s3Store struct {
+	client   *http.Client
+	endpoint *url.URL
+	// presignEndpoint はクライアントに渡す署名付きURLのエンドポイントです。
+	// Hostも署名の対象になるため、クライアントから見えるアドレスで署名します。
+	presignEndpoint *url.URL
+	bucket          string
+	signer          *s3Signer
+	baseURL         string
+}
+
+func NewS3Store(conf *config.StorageConfig) (BlobStore, error) {
+	if conf.S3Endpoint == "" || conf.S3Bucket == "" || conf.S3AccessKeyID == "" || conf.S3SecretAccessKey == "" {
+		return nil, errors.New("s3 storage requires endpoint, bucket, access key id and secret access key")
+	}
+	endpoint, err := parseEndpoint(conf.S3Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	presignEndpoint := endpoint
+	if conf.S3PresignEndpoint != "" {
+		if presignEndpoint, err = parseEndpoint(conf.S3PresignEndpoint); err != nil {
+			return nil, err
+		}
+	}
+	return &s3Store{
+		client:          &http.Client{Timeout: 30 * time.Second},
+		endpoint:        endpoint,
+		presignEndpoint: presignEndpoint,
+		bucket:          conf.S3Bucket,
+		signer: &s3Signer{
+			region:          conf.S3Region,
+			accessKeyID:     conf.S3AccessKeyID,
+			secretAccessKey: conf.S3SecretAccessKey,
+		},
+		baseURL: conf.PublicBaseURL,
+	}, nil
+}
+
+func parseEndpoint(raw string) (*url.URL, error) {
+	endpoint, err := url.Parse(raw)
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("invalid s3 endpoint: %q", raw)
+	}
+	return endpoint, nil
+}
+
+func (ss *s3Store) objectURL(endpoint *url.URL, key string) *url.URL {
+	u := *endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + ss.bucket + "/" + key
+	u.RawQuery = ""
+	return &u
+}
+
+func (ss *s3Store) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if !validKey(key) {
+		return ErrInvalidKey
+	}
+	u := ss.objectURL(ss.endpoint, key).String()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	sum := sha256.Sum256(data)
+	ss.signer.sign(req, hex.EncodeToString(sum[:]), timeNow())
+	return ss.do(req, http.StatusOK)
+}
+
+func (ss *s3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if !validKey(key) {
+		return nil, ErrInvalidKey
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ss.objectURL(ss.endpoint, key).String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	ss.signer.sign(req, emptyPayloadHash, timeNow())
+	resp, err := ss.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	default:
+		defer resp.Body.Close()
+		return nil, statusError(req, resp)
+	}
+}
+
+// PresignPut はContent-Typeも署名の対象にして、指定以外の形式でアップロードできないようにします。
+// 署名付きのPUTではサイズを制限できないため、アップロード後にサイズを確認してください。
+func (ss *s3Store) PresignPut(key, contentType string, expires time.Duration) (string, error) {
+	if !validKey(key) {
+		return "", ErrInvalidKey
+	}
+	u := ss.objectURL(ss.presignEndpoint, key)
+	headers := map[string]string{"host": u.Host, "content-type": contentType}
+	return ss.signer.presign(http.MethodPut, u, headers, expires, timeNow()), nil
+}
+
+// Delete は存在しないオブジェクトの削除を成功として扱います。S3も存在しないキーの削除に204を返します。
+func (ss *s3Store) Delete(ctx context.Context, key string) error {
+	if !validKey(key) {
+		return ErrInvalidKey
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, ss.objectURL(ss.endpoint, key).String(), nil)
+	if err != nil {
+		return err
+	}
+	ss.signer.sign(req, emptyPayloadHash, timeNow())
+	return ss.do(req, http.StatusOK, http.StatusNoContent, http.StatusNotFound)
+}
+
+func (ss *s3Store) URL(key string) string {
+	if ss.baseURL != "" {
+		return joinURL(ss.baseURL, key)
+	}
+	return ss.objectURL(ss.endpoint, key).String()
+}
+
+func (ss *s3Store) do(req *http.Request, okStatuses ...int) error {
+	resp, err := ss.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	for _, status := range okStatuses {
+		if resp.StatusCode == status {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			return nil
+		}
+	}
+	return statusError(req, resp)
+}
+
+// statusError はS3がエラーの内容をXMLで返すので、原因がわかるよう先頭だけエラーに含めます。
+func statusError(req *http.Request, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("s3 %s %s: unexpected status %d: %s", req.Method, req.URL.Path, resp.StatusCode, body)
+}
+
+// timeNow はテストで現在時刻を差し替えるための変数です。
+var timeNow = time.Now
+
+const (
+	s3Algorithm = "AWS4-HMAC-SHA256"
+	s3Service   = "s3"
+	// emptyPayloadHash は空のボディのSHA-256です。
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+)
+
+// s3Signer はAWS Signature Version 4でリクエストに署名します。SDKに依存しないよう、S3で使う範囲だけを実装しています。
+type s3Signer struct {
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+}
+
+// sign はreqのヘッダ(Hostを含む)すべてに署名し、Authorizationヘッダを設定します。
+// payloadHashはボディのSHA-256の16進表記です。
+func (s *s3Signer) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": requestHost(req)}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	canonical, signedHeaders := canonicalRequest(req.Method, req.URL, req.URL.Query(), headers, payloadHash)
+
+	scope := s.scope(amzDate)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.accessKeyID, scope, signedHeaders, s.signature(amzDate, scope, canonical)))
+}
+
+// presign はheaders(小文字の名前とHostを含む)に署名し、署名をクエリ文字列に含めたURLを返します。
+// ボディは署名の対象にしません。
+func (s *s3Signer) presign(
+	method string, u *url.URL, headers map[string]string, expires time.Duration, now time.Time,
+) string {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	scope := s.scope(amzDate)
+	names := sortedKeys(headers)
+
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", s.accessKeyID+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires.Seconds())))
+	query.Set("X-Amz-SignedHeaders", strings.Join(names, ";"))
+
+	canonical, _ := canonicalRequest(method, u, query, headers, unsignedPayload)
+	query.Set("X-Amz-Signature", s.signature(amzDate, scope, canonical))
+
+	signed := *u
+	signed.RawQuery = canonicalQuery(query)
+	return signed.String()
+}
+
+// canonicalRequest はSigV4の正規化したリクエストと、署名したヘッダの名前の一覧を返します。
+func canonicalRequest(
+	method string, u *url.URL, query url.Values, headers map[string]string, payloadHash string,
+) (string, string) {
+	names := sortedKeys(headers)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	return strings.Join([]string{
+		method,
+		canonicalURI(u),
+		canonicalQuery(query),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n"), signedHeaders
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *s3Signer) scope(amzDate string) string {
+	return amzDate[:8] + "/" + s.region + "/" + s3Service + "/aws4_request"
+}
+
+func (s *s3Signer) signature(amzDate, scope, canonicalRequest string) string {
+	hashed := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := s3Algorithm + "\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hashed[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.secretAccessKey), amzDate[:8])
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func requestHost(req *http.Request) string {
+	if req.Host != "" {
+		return req.Host
+	}
+	return req.URL.Host
+}
+
+// canonicalURI はパスの各要素をURIエンコードします。S3では"/"を区切りとしてそのまま残します。
+func canonicalURI(u *url.URL) string {
+	path := u.Path
+	if path == "" {
+		return "/"
+	}
+	return uriEncode(path, false)
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(query))
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(key, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode はSigV4の規則どおり、英数字と"-._~"以外をすべて%XXにエンコードします。
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '.', c == '_', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
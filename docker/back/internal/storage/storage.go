@@ -0,0 +1,68 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/tusmasoma/campfinder/docker/back/config"
+)
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+var (
+	ErrInvalidKey = errors.New("invalid storage key")
+	// ErrNotFound は、keyのファイルが存在しない場合に返します。
+	ErrNotFound = errors.New("object not found")
+	// ErrPresignNotSupported は、署名付きURLでの直接のアップロードに対応していないBlobStoreが返します。
+	ErrPresignNotSupported = errors.New("presigned upload is not supported")
+)
+
+// BlobStore はアップロードされたファイルを保存します。ローカルのディスクやS3互換のストレージに差し替えられるように、
+// usecaseはこのインターフェースのみに依存します。keyは"spots/<spotID>/<id>.jpg"のような"/"区切りのパスです。
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// PresignPut はクライアントがサーバを経由せずにkeyへPUTできる、expiresの間だけ有効なURLを返します。
+	// PUTするときはcontentTypeと同じContent-Typeヘッダを付ける必要があります。
+	PresignPut(key, contentType string, expires time.Duration) (string, error)
+	// URL はkeyのファイルを配信するURLを返します。
+	URL(key string) string
+}
+
+// NewBlobStore は設定に応じたBlobStoreを返します。
+func NewBlobStore(conf *config.StorageConfig) (BlobStore, error) {
+	switch conf.Driver {
+	case DriverLocal:
+		return NewLocalStore(conf.LocalDir, conf.PublicBaseURL)
+	case DriverS3:
+		return NewS3Store(conf)
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %q", conf.Driver)
+	}
+}
+
+// validKey はkeyが保存先の外を指したり、空の要素を含んだりしないかを確認します。
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return false
+	}
+	for _, elem := range strings.Split(key, "/") {
+		if elem == "" || elem == "." || elem == ".." || strings.Contains(elem, "\\") {
+			return false
+		}
+	}
+	return true
+}
+
+func joinURL(base, key string) string {
+	return strings.TrimSuffix(base, "/") + "/" + key
+}
@@ -0,0 +1,280 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/config"
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+	"github.com/tusmasoma/campfinder/docker/back/internal/auth"
+	"github.com/tusmasoma/campfinder/docker/back/internal/mail"
+)
+
+// AdminUseCase は管理者向けの操作です。ロールの確認はAuthorizationMiddlewareで行います。
+// actorIDは操作する管理者のユーザIDで、自分自身のロールの変更や利用停止を防ぐために使います。
+type AdminUseCase interface {
+	ListLoginLockouts(ctx context.Context, limit int) ([]model.LoginLockout, error)
+	ListUsers(ctx context.Context, params *ListUsersParams) (*ListUsersResult, error)
+	UpdateUserRole(ctx context.Context, actorID string, userID string, role model.Role) (*model.User, error)
+	SetUserSuspended(ctx context.Context, actorID string, userID string, suspended bool) (*model.User, error)
+	ForcePasswordReset(ctx context.Context, userID string) error
+	AssignSpotOwner(ctx context.Context, spotID string, userID string) error
+	RemoveSpotOwner(ctx context.Context, spotID string, userID string) error
+}
+
+type adminUseCase struct {
+	lr     repository.LoginAttemptCacheRepository
+	ur     repository.UserRepository
+	cr     repository.UserCacheRepository
+	sr     repository.SpotRepository
+	sor    repository.SpotOwnerRepository
+	mailer mail.Mailer
+	mc     *config.MailConfig
+}
+
+func NewAdminUseCase(
+	lr repository.LoginAttemptCacheRepository,
+	ur repository.UserRepository,
+	cr repository.UserCacheRepository,
+	sr repository.SpotRepository,
+	sor repository.SpotOwnerRepository,
+	mailer mail.Mailer,
+	mc *config.MailConfig,
+) AdminUseCase {
+	return &adminUseCase{
+		lr:     lr,
+		ur:     ur,
+		cr:     cr,
+		sr:     sr,
+		sor:    sor,
+		mailer: mailer,
+		mc:     mc,
+	}
+}
+
+// ListLoginLockouts はログインのロックの記録を新しい順に返します。
+func (auc *adminUseCase) ListLoginLockouts(ctx context.Context, limit int) ([]model.LoginLockout, error) {
+	lockouts, err := auc.lr.ListLockouts(ctx, int64(limit))
+	if err != nil {
+		log.Printf("Failed to list login lockouts: %v", err)
+		return nil, err
+	}
+	return lockouts, nil
+}
+
+// ListUsersParams はユーザ一覧の絞り込み条件です。Queryは名前またはメールアドレスの部分一致で、
+// Role, Suspendedは指定された場合のみ絞り込みます。
+type ListUsersParams struct {
+	Query     string
+	Role      model.Role
+	Suspended *bool
+	Limit     int
+	Cursor    string
+}
+
+type ListUsersResult struct {
+	Users      []model.User
+	NextCursor string
+	Total      int
+}
+
+func (auc *adminUseCase) ListUsers(ctx context.Context, params *ListUsersParams) (*ListUsersResult, error) {
+	var qcs []repository.QueryCondition
+	if params.Query != "" {
+		pattern := "%" + escapeLike(params.Query) + "%"
+		qcs = append(qcs, repository.QueryCondition{Or: []repository.QueryCondition{
+			{Field: "name", Operator: repository.OpLike, Value: pattern},
+			{Field: "email", Operator: repository.OpLike, Value: pattern},
+		}})
+	}
+	if params.Role != "" {
+		qcs = append(qcs, roleConditions(params.Role)...)
+	}
+	if params.Suspended != nil {
+		qcs = append(qcs, repository.QueryCondition{Field: "suspended", Value: *params.Suspended})
+	}
+
+	users, nextCursor, err := auc.ur.ListPage(ctx, qcs, repository.ListOptions{
+		Limit:  params.Limit,
+		Cursor: params.Cursor,
+	})
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return nil, err
+	}
+	if err != nil {
+		log.Printf("Failed to list users: %v", err)
+		return nil, err
+	}
+	total, err := auc.ur.Count(ctx, qcs)
+	if err != nil {
+		log.Printf("Failed to count users: %v", err)
+		return nil, err
+	}
+	return &ListUsersResult{Users: users, NextCursor: nextCursor, Total: total}, nil
+}
+
+// roleConditions はEffectiveRoleがroleのユーザの条件を返します。is_adminのユーザは保存されたロールに関わらずadminです。
+func roleConditions(role model.Role) []repository.QueryCondition {
+	if role == model.RoleAdmin {
+		return []repository.QueryCondition{{Or: []repository.QueryCondition{
+			{Field: "role", Value: string(role)},
+			{Field: "is_admin", Value: true},
+		}}}
+	}
+	return []repository.QueryCondition{
+		{Field: "role", Value: string(role)},
+		{Or: []repository.QueryCondition{
+			{Field: "is_admin", Value: false},
+			{Field: "is_admin", Operator: repository.OpIsNull, Value: true},
+		}},
+	}
+}
+
+// escapeLike はLIKEのパターンとして解釈されないよう、%, _ とエスケープ文字をエスケープします。
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// UpdateUserRole はユーザのロールを変更します。is_adminのユーザも指定したロールになるよう、is_adminは解除します。
+func (auc *adminUseCase) UpdateUserRole(
+	ctx context.Context,
+	actorID string,
+	userID string,
+	role model.Role,
+) (*model.User, error) {
+	if !role.IsValid() {
+		return nil, ErrInvalidRole
+	}
+	if actorID == userID {
+		return nil, ErrCannotModifySelf
+	}
+	user, err := auc.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Role = role
+	user.IsAdmin = false
+	if err = auc.ur.Update(ctx, userID, *user); err != nil {
+		log.Printf("Failed to update user: %v", err)
+		return nil, err
+	}
+	log.Printf("Role of user %v was changed to %v by %v", userID, role, actorID)
+	return user, nil
+}
+
+// SetUserSuspended はユーザの利用を停止または再開します。利用を停止した場合は、すべてのセッションを失効させます。
+func (auc *adminUseCase) SetUserSuspended(
+	ctx context.Context,
+	actorID string,
+	userID string,
+	suspended bool,
+) (*model.User, error) {
+	if actorID == userID {
+		return nil, ErrCannotModifySelf
+	}
+	user, err := auc.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Suspended = suspended
+	if err = auc.ur.Update(ctx, userID, *user); err != nil {
+		log.Printf("Failed to update user: %v", err)
+		return nil, err
+	}
+	if err = auc.cr.SetSuspended(ctx, userID, suspended); err != nil {
+		log.Printf("Failed to set suspension of %v in cache: %v", userID, err)
+		return nil, err
+	}
+	if suspended {
+		if err = revokeSessions(ctx, auc.cr, userID, ""); err != nil {
+			return nil, err
+		}
+	}
+	log.Printf("Suspension of user %v was set to %v by %v", userID, suspended, actorID)
+	return user, nil
+}
+
+// ForcePasswordReset は現在のパスワードでログインできないようにしてすべてのセッションを失効させ、
+// パスワード再設定のメールを送ります。空のパスワードはパスワードを持たない外部ログインのユーザを表し、
+// アカウント削除などでパスワードの確認が省略されるため、どのパスワードとも一致しないハッシュに置き換えます。
+func (auc *adminUseCase) ForcePasswordReset(ctx context.Context, userID string) error {
+	user, err := auc.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.Password, err = auth.UnusablePasswordHash(); err != nil {
+		log.Printf("Failed to generate password hash: %v", err)
+		return err
+	}
+	if err = auc.ur.Update(ctx, userID, *user); err != nil {
+		log.Printf("Failed to update user: %v", err)
+		return err
+	}
+	if err = revokeSessions(ctx, auc.cr, userID, ""); err != nil {
+		return err
+	}
+	return sendPasswordResetEmail(ctx, auc.cr, auc.mailer, auc.mc, user)
+}
+
+// AssignSpotOwner はユーザをSpotの運営者として登録します。運営者がSpotのレビューに返信すると、運営者の返信として表示されます。
+// 既に運営者の場合は何もしません。
+func (auc *adminUseCase) AssignSpotOwner(ctx context.Context, spotID string, userID string) error {
+	spot, err := auc.sr.Get(ctx, spotID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			log.Printf("Failed to get spot: %v", err)
+		}
+		return err
+	}
+	user, err := auc.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	owners, err := auc.sor.Count(ctx, []repository.QueryCondition{
+		{Field: "spot_id", Value: spotID},
+		{Field: "user_id", Value: userID},
+	})
+	if err != nil {
+		log.Printf("Failed to get owners of spot %v: %v", spotID, err)
+		return err
+	}
+	if owners > 0 {
+		return nil
+	}
+	if err = auc.sor.Create(ctx, model.SpotOwner{ID: uuid.New(), SpotID: spot.ID, UserID: user.ID}); err != nil {
+		log.Printf("Failed to create owner of spot %v: %v", spotID, err)
+		return err
+	}
+	log.Printf("User %v was assigned as an owner of spot %v", userID, spotID)
+	return nil
+}
+
+func (auc *adminUseCase) RemoveSpotOwner(ctx context.Context, spotID string, userID string) error {
+	if err := auc.sor.DeleteBySpotAndUser(ctx, spotID, userID); err != nil {
+		log.Printf("Failed to delete owner of spot %v: %v", spotID, err)
+		return err
+	}
+	log.Printf("User %v was removed from owners of spot %v", userID, spotID)
+	return nil
+}
+
+func (auc *adminUseCase) getUser(ctx context.Context, userID string) (*model.User, error) {
+	user, err := auc.ur.Get(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			log.Printf("Failed to get user: %v", err)
+		}
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,376 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository/mock"
+	"github.com/tusmasoma/campfinder/docker/back/internal/auth"
+	"github.com/tusmasoma/campfinder/docker/back/internal/mail"
+	mailmock "github.com/tusmasoma/campfinder/docker/back/internal/mail/mock"
+)
+
+func TestAdminUseCase_ListUsers(t *testing.T) {
+	suspended := true
+
+	ctrl := gomock.NewController(t)
+	ur := mock.NewMockUserRepository(ctrl)
+	// LIKEのワイルドカードはエスケープして検索する
+	qcs := []repository.QueryCondition{
+		{Or: []repository.QueryCondition{
+			{Field: "name", Operator: repository.OpLike, Value: `%test\_1%`},
+			{Field: "email", Operator: repository.OpLike, Value: `%test\_1%`},
+		}},
+		{Field: "role", Value: "moderator"},
+		// is_adminのユーザはadminとして扱うため除く
+		{Or: []repository.QueryCondition{
+			{Field: "is_admin", Value: false},
+			{Field: "is_admin", Operator: repository.OpIsNull, Value: true},
+		}},
+		{Field: "suspended", Value: true},
+	}
+	ur.EXPECT().ListPage(gomock.Any(), qcs, repository.ListOptions{Limit: 10, Cursor: "cursor"}).Return(
+		[]model.User{{Name: "test_1"}}, "next", nil,
+	)
+	ur.EXPECT().Count(gomock.Any(), qcs).Return(11, nil)
+
+	usecase := NewAdminUseCase(
+		mock.NewMockLoginAttemptCacheRepository(ctrl),
+		ur,
+		mock.NewMockUserCacheRepository(ctrl),
+		mock.NewMockSpotRepository(ctrl),
+		mock.NewMockSpotOwnerRepository(ctrl),
+		mailmock.NewMockMailer(ctrl),
+		testMailConfig,
+	)
+	got, err := usecase.ListUsers(context.Background(), &ListUsersParams{
+		Query:     "test_1",
+		Role:      model.RoleModerator,
+		Suspended: &suspended,
+		Limit:     10,
+		Cursor:    "cursor",
+	})
+	if err != nil {
+		t.Fatalf("ListUsers() error = %v", err)
+	}
+	if len(got.Users) != 1 || got.NextCursor != "next" || got.Total != 11 {
+		t.Errorf("ListUsers() = %+v", got)
+	}
+}
+
+func TestAdminUseCase_ListUsers_LegacyAdmin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ur := mock.NewMockUserRepository(ctrl)
+	// is_adminのユーザも保存されたロールに関わらずadminとして検索する
+	qcs := []repository.QueryCondition{
+		{Or: []repository.QueryCondition{
+			{Field: "role", Value: "admin"},
+			{Field: "is_admin", Value: true},
+		}},
+	}
+	ur.EXPECT().ListPage(gomock.Any(), qcs, repository.ListOptions{Limit: 10}).Return(
+		[]model.User{{Name: "legacy", IsAdmin: true}}, "", nil,
+	)
+	ur.EXPECT().Count(gomock.Any(), qcs).Return(1, nil)
+
+	usecase := NewAdminUseCase(
+		mock.NewMockLoginAttemptCacheRepository(ctrl),
+		ur,
+		mock.NewMockUserCacheRepository(ctrl),
+		mock.NewMockSpotRepository(ctrl),
+		mock.NewMockSpotOwnerRepository(ctrl),
+		mailmock.NewMockMailer(ctrl),
+		testMailConfig,
+	)
+	got, err := usecase.ListUsers(context.Background(), &ListUsersParams{Role: model.RoleAdmin, Limit: 10})
+	if err != nil {
+		t.Fatalf("ListUsers() error = %v", err)
+	}
+	if len(got.Users) != 1 || got.Total != 1 {
+		t.Errorf("ListUsers() = %+v", got)
+	}
+}
+
+func TestAdminUseCase_UpdateUserRole(t *testing.T) {
+	actorID := uuid.New()
+	userID := uuid.New()
+
+	patterns := []struct {
+		name    string
+		setup   func(m *mock.MockUserRepository)
+		actorID string
+		role    model.Role
+		wantErr error
+	}{
+		{
+			name: "success: demote legacy admin",
+			setup: func(m *mock.MockUserRepository) {
+				m.EXPECT().Get(gomock.Any(), userID.String()).Return(&model.User{ID: userID, IsAdmin: true}, nil)
+				m.EXPECT().Update(gomock.Any(), userID.String(), model.User{ID: userID, Role: model.RoleModerator}).Return(nil)
+			},
+			actorID: actorID.String(),
+			role:    model.RoleModerator,
+		},
+		{
+			name:    "Fail: invalid role",
+			actorID: actorID.String(),
+			role:    "owner",
+			wantErr: ErrInvalidRole,
+		},
+		{
+			name:    "Fail: own account",
+			actorID: userID.String(),
+			role:    model.RoleUser,
+			wantErr: ErrCannotModifySelf,
+		},
+		{
+			name: "Fail: user not found",
+			setup: func(m *mock.MockUserRepository) {
+				m.EXPECT().Get(gomock.Any(), userID.String()).Return(nil, repository.ErrNotFound)
+			},
+			actorID: actorID.String(),
+			role:    model.RoleUser,
+			wantErr: repository.ErrNotFound,
+		},
+	}
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			ur := mock.NewMockUserRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ur)
+			}
+
+			usecase := NewAdminUseCase(
+				mock.NewMockLoginAttemptCacheRepository(ctrl),
+				ur,
+				mock.NewMockUserCacheRepository(ctrl),
+				mock.NewMockSpotRepository(ctrl),
+				mock.NewMockSpotOwnerRepository(ctrl),
+				mailmock.NewMockMailer(ctrl),
+				testMailConfig,
+			)
+			user, err := usecase.UpdateUserRole(context.Background(), tt.actorID, userID.String(), tt.role)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateUserRole() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && user.EffectiveRole() != tt.role {
+				t.Errorf("UpdateUserRole() role = %v, want %v", user.EffectiveRole(), tt.role)
+			}
+		})
+	}
+}
+
+func TestAdminUseCase_SetUserSuspended(t *testing.T) {
+	actorID := uuid.New()
+	userID := uuid.New()
+
+	patterns := []struct {
+		name      string
+		setup     func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository)
+		actorID   string
+		suspended bool
+		wantErr   error
+	}{
+		{
+			name: "success: suspend",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository) {
+				m.EXPECT().Get(gomock.Any(), userID.String()).Return(&model.User{ID: userID}, nil)
+				m.EXPECT().Update(gomock.Any(), userID.String(), model.User{ID: userID, Suspended: true}).Return(nil)
+				m1.EXPECT().SetSuspended(gomock.Any(), userID.String(), true).Return(nil)
+				// すべてのセッションを失効させる
+				m1.EXPECT().ListSessions(gomock.Any(), userID.String()).Return([]model.Session{{ID: "session"}}, nil)
+				m1.EXPECT().DeleteSession(gomock.Any(), userID.String(), "session").Return(nil)
+				m1.EXPECT().DeleteRefreshTokenFamily(gomock.Any(), "session").Return(nil)
+			},
+			actorID:   actorID.String(),
+			suspended: true,
+		},
+		{
+			name: "success: unsuspend",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository) {
+				m.EXPECT().Get(gomock.Any(), userID.String()).Return(&model.User{ID: userID, Suspended: true}, nil)
+				m.EXPECT().Update(gomock.Any(), userID.String(), model.User{ID: userID}).Return(nil)
+				m1.EXPECT().SetSuspended(gomock.Any(), userID.String(), false).Return(nil)
+			},
+			actorID: actorID.String(),
+		},
+		{
+			name:      "Fail: own account",
+			actorID:   userID.String(),
+			suspended: true,
+			wantErr:   ErrCannotModifySelf,
+		},
+	}
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			ur := mock.NewMockUserRepository(ctrl)
+			cr := mock.NewMockUserCacheRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ur, cr)
+			}
+
+			usecase := NewAdminUseCase(
+				mock.NewMockLoginAttemptCacheRepository(ctrl),
+				ur,
+				cr,
+				mock.NewMockSpotRepository(ctrl),
+				mock.NewMockSpotOwnerRepository(ctrl),
+				mailmock.NewMockMailer(ctrl),
+				testMailConfig,
+			)
+			_, err := usecase.SetUserSuspended(context.Background(), tt.actorID, userID.String(), tt.suspended)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("SetUserSuspended() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAdminUseCase_ForcePasswordReset(t *testing.T) {
+	userID := uuid.New()
+
+	ctrl := gomock.NewController(t)
+	ur := mock.NewMockUserRepository(ctrl)
+	cr := mock.NewMockUserCacheRepository(ctrl)
+	mm := mailmock.NewMockMailer(ctrl)
+
+	ur.EXPECT().Get(gomock.Any(), userID.String()).Return(
+		&model.User{ID: userID, Email: "test@gmail.com", Password: "hashed"}, nil,
+	)
+	// 現在のパスワードではログインできないようにし、パスワードを持たないユーザとは区別する
+	ur.EXPECT().Update(gomock.Any(), userID.String(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, user model.User) error {
+			if user.Password == "" || user.Password == "hashed" {
+				t.Errorf("unexpected password: %q", user.Password)
+			}
+			if err := auth.CompareHashAndPassword(user.Password, ""); err == nil {
+				t.Error("password hash matches an empty password")
+			}
+			return nil
+		},
+	)
+	cr.EXPECT().ListSessions(gomock.Any(), userID.String()).Return([]model.Session{{ID: "session"}}, nil)
+	cr.EXPECT().DeleteSession(gomock.Any(), userID.String(), "session").Return(nil)
+	cr.EXPECT().DeleteRefreshTokenFamily(gomock.Any(), "session").Return(nil)
+	cr.EXPECT().SetUserToken(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, token model.UserToken) error {
+			if token.Purpose != model.UserTokenPurposeResetPassword || token.UserID != userID.String() {
+				t.Errorf("unexpected user token: %+v", token)
+			}
+			return nil
+		},
+	)
+	mm.EXPECT().Send(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, msg mail.Message) error {
+			if msg.To != "test@gmail.com" || !strings.Contains(msg.Body, "https://campfinder.example/reset-password?token=") {
+				t.Errorf("unexpected message: %+v", msg)
+			}
+			return nil
+		},
+	)
+
+	usecase := NewAdminUseCase(
+		mock.NewMockLoginAttemptCacheRepository(ctrl),
+		ur,
+		cr,
+		mock.NewMockSpotRepository(ctrl),
+		mock.NewMockSpotOwnerRepository(ctrl),
+		mm,
+		testMailConfig,
+	)
+	if err := usecase.ForcePasswordReset(context.Background(), userID.String()); err != nil {
+		t.Errorf("ForcePasswordReset() error = %v", err)
+	}
+}
+
+func TestAdminUseCase_AssignSpotOwner(t *testing.T) {
+	spotID := uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052")
+	userID := uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")
+	owners := []repository.QueryCondition{
+		{Field: "spot_id", Value: spotID.String()},
+		{Field: "user_id", Value: userID.String()},
+	}
+
+	patterns := []struct {
+		name    string
+		setup   func(sr *mock.MockSpotRepository, ur *mock.MockUserRepository, sor *mock.MockSpotOwnerRepository)
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(sr *mock.MockSpotRepository, ur *mock.MockUserRepository, sor *mock.MockSpotOwnerRepository) {
+				sr.EXPECT().Get(gomock.Any(), spotID.String()).Return(&model.Spot{ID: spotID}, nil)
+				ur.EXPECT().Get(gomock.Any(), userID.String()).Return(&model.User{ID: userID}, nil)
+				sor.EXPECT().Count(gomock.Any(), owners).Return(0, nil)
+				sor.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, owner model.SpotOwner) error {
+						if owner.SpotID != spotID || owner.UserID != userID {
+							t.Errorf("unexpected owner: %+v", owner)
+						}
+						return nil
+					},
+				)
+			},
+		},
+		{
+			name: "success: already owner",
+			setup: func(sr *mock.MockSpotRepository, ur *mock.MockUserRepository, sor *mock.MockSpotOwnerRepository) {
+				sr.EXPECT().Get(gomock.Any(), spotID.String()).Return(&model.Spot{ID: spotID}, nil)
+				ur.EXPECT().Get(gomock.Any(), userID.String()).Return(&model.User{ID: userID}, nil)
+				sor.EXPECT().Count(gomock.Any(), owners).Return(1, nil)
+			},
+		},
+		{
+			name: "Fail: spot not found",
+			setup: func(sr *mock.MockSpotRepository, _ *mock.MockUserRepository, _ *mock.MockSpotOwnerRepository) {
+				sr.EXPECT().Get(gomock.Any(), spotID.String()).Return(nil, repository.ErrNotFound)
+			},
+			wantErr: repository.ErrNotFound,
+		},
+		{
+			name: "Fail: user not found",
+			setup: func(sr *mock.MockSpotRepository, ur *mock.MockUserRepository, _ *mock.MockSpotOwnerRepository) {
+				sr.EXPECT().Get(gomock.Any(), spotID.String()).Return(&model.Spot{ID: spotID}, nil)
+				ur.EXPECT().Get(gomock.Any(), userID.String()).Return(nil, repository.ErrNotFound)
+			},
+			wantErr: repository.ErrNotFound,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			sr := mock.NewMockSpotRepository(ctrl)
+			ur := mock.NewMockUserRepository(ctrl)
+			sor := mock.NewMockSpotOwnerRepository(ctrl)
+
+			tt.setup(sr, ur, sor)
+
+			usecase := NewAdminUseCase(
+				mock.NewMockLoginAttemptCacheRepository(ctrl),
+				ur,
+				mock.NewMockUserCacheRepository(ctrl),
+				sr,
+				sor,
+				mailmock.NewMockMailer(ctrl),
+				testMailConfig,
+			)
+			if err := usecase.AssignSpotOwner(context.Background(), spotID.String(), userID.String()); !errors.Is(err, tt.wantErr) {
+				t.Errorf("AssignSpotOwner() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
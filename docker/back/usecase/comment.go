@@ -3,17 +3,21 @@ package usecase
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"log"
 
 	"github.com/google/uuid"
 
+	"github.com/tusmasoma/campfinder/docker/back/config"
 	"github.com/tusmasoma/campfinder/docker/back/domain/model"
 	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
 )
 
+// DefaultCommentsOrderBy は新しい順に並べるための既定の並び順です。
+const DefaultCommentsOrderBy = "-created"
+
 type CommentUseCase interface {
-	ListComments(ctx context.Context, spotID string) ([]model.Comment, error)
+	ListComments(ctx context.Context, params *ListCommentsParams) (*ListCommentsResult, error)
 	CreateComment(ctx context.Context, params *CreateCommentParams) error
 	BatchCreateComments(ctx context.Context, params *BatchCreateCommentsParams) error
 	UpdateComment(
@@ -26,55 +30,263 @@ type CommentUseCase interface {
 		user model.User,
 	) error
 	DeleteComment(ctx context.Context, id string, userID string, user model.User) error
+	ListReplies(ctx context.Context, params *ListRepliesParams) (*ListRepliesResult, error)
 }
 
 type commentUseCase struct {
-	cr repository.CommentRepository
-	cc repository.CommentsCacheRepository
+	cr  repository.CommentRepository
+	cc  repository.CommentsCacheRepository
+	sr  repository.SpotRepository
+	sor repository.SpotOwnerRepository
+	tc  *config.TwoFactorConfig
 }
 
-func NewCommentUseCase(cr repository.CommentRepository, cc repository.CommentsCacheRepository) CommentUseCase {
+func NewCommentUseCase(
+	cr repository.CommentRepository,
+	cc repository.CommentsCacheRepository,
+	sr repository.SpotRepository,
+	sor repository.SpotOwnerRepository,
+	tc *config.TwoFactorConfig,
+) CommentUseCase {
 	return &commentUseCase{
-		cr: cr,
-		cc: cc,
+		cr:  cr,
+		cc:  cc,
+		sr:  sr,
+		sor: sor,
+		tc:  tc,
+	}
+}
+
+// ListCommentsParams はレビューの一覧の条件です。ReplyLimitは各スレッドに含める返信の数で、0の場合は返信を含めません。
+type ListCommentsParams struct {
+	SpotID     string
+	Limit      int
+	Cursor     string
+	OrderBy    string
+	ReplyLimit int
+}
+
+// CommentThread はレビューと、そのレビューを起点とする返信です。返信は入れ子の深さに関わらず古い順に並べるため、
+// 木構造はParentIDで組み立ててください。NextReplyCursorは続きの返信をListRepliesで取得するためのカーソルです。
+type CommentThread struct {
+	Comment         model.Comment
+	Replies         []model.Comment
+	NextReplyCursor string
+}
+
+type ListCommentsResult struct {
+	Threads    []CommentThread
+	NextCursor string
+	Total      int
+}
+
+// ListComments はSpotのレビューをページ分割し、各レビューにReplyLimit件までの返信を付けて返します。
+func (cuc *commentUseCase) ListComments(ctx context.Context, params *ListCommentsParams) (*ListCommentsResult, error) {
+	qcs := []repository.QueryCondition{
+		{Field: "spot_id", Value: params.SpotID},
+		{Field: "parent_id", Operator: repository.OpIsNull, Value: true},
+	}
+	orderBy := params.OrderBy
+	if orderBy == "" {
+		orderBy = DefaultCommentsOrderBy
+	}
+
+	comments, nextCursor, err := cuc.cr.ListPage(ctx, qcs, repository.ListOptions{
+		Limit:   params.Limit,
+		Cursor:  params.Cursor,
+		OrderBy: orderBy,
+	})
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return nil, err
+	}
+	if err != nil {
+		log.Printf("Failed to get comments of %v: %v", params.SpotID, err)
+		// 先頭ページのみキャッシュのマスターデータで代替する
+		if params.Cursor != "" {
+			return nil, err
+		}
+		comments = cuc.getMasterData(ctx, params.SpotID)
+		return &ListCommentsResult{Threads: newCommentThreads(comments), Total: len(comments)}, nil
+	}
+
+	total, err := cuc.cr.Count(ctx, qcs)
+	if err != nil {
+		log.Printf("Failed to count comments of %v: %v", params.SpotID, err)
+		return nil, err
+	}
+
+	// 全件が1ページに収まっている場合のみ、マスターデータを更新する
+	if params.Cursor == "" && nextCursor == "" {
+		if cacheErr := cuc.setMasterData(ctx, params.SpotID, comments); cacheErr != nil {
+			log.Printf("Failed to set comments data of %v: %v", params.SpotID, cacheErr)
+		}
+	}
+
+	threads := newCommentThreads(comments)
+	if err = cuc.setReplies(ctx, threads, params.ReplyLimit); err != nil {
+		return nil, err
+	}
+	return &ListCommentsResult{Threads: threads, NextCursor: nextCursor, Total: total}, nil
+}
+
+// setReplies は各スレッドに古い順にlimit件までの返信を付けます。レビューの件数に関わらず1回のクエリで取得します。
+func (cuc *commentUseCase) setReplies(ctx context.Context, threads []CommentThread, limit int) error {
+	if limit <= 0 || len(threads) == 0 {
+		return nil
+	}
+	rootIDs := make([]string, 0, len(threads))
+	for _, thread := range threads {
+		rootIDs = append(rootIDs, thread.Comment.ID.String())
+	}
+	pages, err := cuc.cr.ListReplyPages(ctx, rootIDs, limit)
+	if err != nil {
+		log.Printf("Failed to get replies of comments: %v", err)
+		return err
+	}
+	for i := range threads {
+		page := pages[threads[i].Comment.ID.String()]
+		threads[i].Replies = page.Replies
+		threads[i].NextReplyCursor = page.NextCursor
 	}
+	return nil
+}
+
+func newCommentThreads(comments []model.Comment) []CommentThread {
+	threads := make([]CommentThread, 0, len(comments))
+	for _, comment := range comments {
+		threads = append(threads, CommentThread{Comment: comment})
+	}
+	return threads
+}
+
+// ListRepliesParams はスレッドの返信の一覧の条件です。CommentIDはスレッドの起点のレビューです。
+type ListRepliesParams struct {
+	CommentID string
+	Limit     int
+	Cursor    string
 }
 
-func (cuc *commentUseCase) ListComments(ctx context.Context, spotID string) ([]model.Comment, error) {
-	comments, err := cuc.cr.List(ctx, []repository.QueryCondition{{Field: "SpotID", Value: spotID}})
+type ListRepliesResult struct {
+	Replies    []model.Comment
+	NextCursor string
+	Total      int
+}
+
+// ListReplies はレビューを起点とするスレッドの返信を古い順にページ分割して返します。
+func (cuc *commentUseCase) ListReplies(ctx context.Context, params *ListRepliesParams) (*ListRepliesResult, error) {
+	root, err := cuc.getComment(ctx, params.CommentID)
 	if err != nil {
-		log.Printf("Failed to get comments of %v: %v", spotID, err)
-		comments = cuc.getMasterData(ctx, spotID)
-		return comments, nil
+		return nil, err
 	}
-	if cacheErr := cuc.setMasterData(ctx, spotID, comments); cacheErr != nil {
-		log.Printf("Failed to set comments data of %v: %v", spotID, cacheErr)
+	if root.IsReply() {
+		return nil, ErrCommentNotFound
+	}
+
+	result, err := cuc.listThread(ctx, params.CommentID, params.Limit, params.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	result.Total, err = cuc.cr.Count(ctx, []repository.QueryCondition{{Field: "root_id", Value: params.CommentID}})
+	if err != nil {
+		log.Printf("Failed to count replies of %v: %v", params.CommentID, err)
+		return nil, err
 	}
-	return comments, nil
+	return result, nil
 }
 
+func (cuc *commentUseCase) listThread(
+	ctx context.Context, rootID string, limit int, cursor string,
+) (*ListRepliesResult, error) {
+	replies, nextCursor, err := cuc.cr.ListPage(ctx, []repository.QueryCondition{{Field: "root_id", Value: rootID}},
+		repository.ListOptions{Limit: limit, Cursor: cursor, OrderBy: "created"})
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return nil, err
+	}
+	if err != nil {
+		log.Printf("Failed to get replies of %v: %v", rootID, err)
+		return nil, err
+	}
+	return &ListRepliesResult{Replies: replies, NextCursor: nextCursor}, nil
+}
+
+// CreateCommentParams はコメントの作成の条件です。ParentIDを指定した場合は、そのコメントへの返信になります。
+// 返信は評価を持たないため、StarRateは無視します。
 type CreateCommentParams struct {
 	UserID   uuid.UUID
 	SpotID   uuid.UUID
+	ParentID uuid.UUID
 	StarRate float64
 	Text     string
 }
 
 func (cuc *commentUseCase) CreateComment(ctx context.Context, params *CreateCommentParams) error {
 	comment := model.Comment{
+		ID:       uuid.New(),
 		SpotID:   params.SpotID,
 		UserID:   params.UserID,
 		StarRate: params.StarRate,
 		Text:     params.Text,
+		Kind:     model.CommentKindReview,
+	}
+	if params.ParentID != uuid.Nil {
+		if err := cuc.setReplyTo(ctx, &comment, params.ParentID); err != nil {
+			return err
+		}
+	} else if !isValidStarRate(comment.StarRate) {
+		return ErrInvalidStarRate
 	}
 
 	if err := cuc.cr.Create(ctx, comment); err != nil {
 		log.Printf("Failed to create comment: %v", err)
 		return err
 	}
+	if comment.IsReply() {
+		return nil
+	}
+	return cuc.adjustRating(ctx, comment.SpotID, comment.StarRate, 1)
+}
+
+// setReplyTo はcommentをparentIDへの返信にします。返信者がSpotの運営者の場合は運営者の返信として扱います。
+func (cuc *commentUseCase) setReplyTo(ctx context.Context, comment *model.Comment, parentID uuid.UUID) error {
+	parent, err := cuc.getComment(ctx, parentID.String())
+	if err != nil {
+		return err
+	}
+	if parent.SpotID != comment.SpotID {
+		return ErrCommentNotFound
+	}
+	if parent.Depth+1 > model.MaxCommentDepth {
+		return ErrCommentTooDeep
+	}
+
+	rootID := parent.ID
+	if parent.RootID.Valid {
+		rootID = parent.RootID.UUID
+	}
+	comment.ParentID = uuid.NullUUID{UUID: parent.ID, Valid: true}
+	comment.RootID = uuid.NullUUID{UUID: rootID, Valid: true}
+	comment.Depth = parent.Depth + 1
+	comment.StarRate = 0
+	comment.Kind = model.CommentKindReply
+
+	owners, err := cuc.sor.Count(ctx, []repository.QueryCondition{
+		{Field: "spot_id", Value: comment.SpotID.String()},
+		{Field: "user_id", Value: comment.UserID.String()},
+	})
+	if err != nil {
+		log.Printf("Failed to get owners of spot %v: %v", comment.SpotID, err)
+		return err
+	}
+	if owners > 0 {
+		comment.Kind = model.CommentKindOwnerResponse
+	}
 	return nil
 }
 
+func isValidStarRate(starRate float64) bool {
+	return starRate >= model.MinStarRate && starRate <= model.MaxStarRate
+}
+
 type BatchCreateCommentsParams struct {
 	Comments []CreateCommentParams
 }
@@ -83,10 +295,12 @@ func (cuc *commentUseCase) BatchCreateComments(ctx context.Context, params *Batc
 	var comments []model.Comment
 	for _, param := range params.Comments {
 		comment := model.Comment{
+			ID:       uuid.New(),
 			UserID:   param.UserID,
 			SpotID:   param.SpotID,
 			StarRate: param.StarRate,
 			Text:     param.Text,
+			Kind:     model.CommentKindReview,
 		}
 		comments = append(comments, comment)
 	}
@@ -94,9 +308,16 @@ func (cuc *commentUseCase) BatchCreateComments(ctx context.Context, params *Batc
 		log.Printf("Failed to batch create comments: %v", err)
 		return err
 	}
+	for _, comment := range comments {
+		if err := cuc.adjustRating(ctx, comment.SpotID, comment.StarRate, 1); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// UpdateComment はコメントを更新します。権限は保存済みのコメントの作成者で確認し、userIDは作成者の変更には使いません。
+// 返信とスレッドが別のSpotに分かれないよう、レビューのSpotも変更せず、spotIDは使いません。
 func (cuc *commentUseCase) UpdateComment(
 	ctx context.Context,
 	id uuid.UUID,
@@ -106,38 +327,98 @@ func (cuc *commentUseCase) UpdateComment(
 	text string,
 	user model.User,
 ) error {
-	if !user.IsAdmin && user.ID != userID {
-		log.Print("Don't have permission to update comment")
-		return fmt.Errorf("don't have permission to update comment")
+	current, err := cuc.getComment(ctx, id.String())
+	if err != nil {
+		return err
+	}
+	if !cuc.canModify(user, *current) {
+		log.Printf("Don't have permission to update comment: user=%v, comment=%v, userID=%v", user.ID, id, userID)
+		return ErrPermissionDenied
+	}
+
+	// 返信は本文のみ変更でき、スレッドやSpotを移すことはできない
+	if current.IsReply() {
+		reply := *current
+		reply.Text = text
+		if err = cuc.cr.Update(ctx, id.String(), reply); err != nil {
+			log.Printf("Failed to update comment: %v", err)
+			return err
+		}
+		return nil
+	}
+	if !isValidStarRate(starRate) {
+		return ErrInvalidStarRate
 	}
 
 	comment := model.Comment{
 		ID:       id,
-		SpotID:   spotID,
-		UserID:   userID,
+		SpotID:   current.SpotID,
+		UserID:   current.UserID,
 		StarRate: starRate,
 		Text:     text,
+		Kind:     current.Kind,
 	}
-	if err := cuc.cr.Update(ctx, id.String(), comment); err != nil {
+	if err = cuc.cr.Update(ctx, id.String(), comment); err != nil {
 		log.Printf("Failed to update comment: %v", err)
 		return err
 	}
-	return nil
+
+	if current.StarRate == starRate {
+		return nil
+	}
+	if err = cuc.adjustRating(ctx, current.SpotID, current.StarRate, -1); err != nil {
+		return err
+	}
+	return cuc.adjustRating(ctx, current.SpotID, starRate, 1)
 }
 
 func (cuc *commentUseCase) DeleteComment(ctx context.Context, id string, userID string, user model.User) error {
-	if !user.IsAdmin && user.ID.String() != userID {
-		log.Print("Don't have permission to delete comment")
-		return fmt.Errorf("don't have permission to delete comment")
+	current, err := cuc.getComment(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !cuc.canModify(user, *current) {
+		log.Printf("Don't have permission to delete comment: user=%v, comment=%v, userID=%v", user.ID, id, userID)
+		return ErrPermissionDenied
 	}
 
-	if err := cuc.cr.Delete(ctx, id); err != nil {
+	// レビューへの返信は外部キーによってあわせて削除される
+	if err = cuc.cr.Delete(ctx, id); err != nil {
 		log.Printf("Failed to delete comment: %v", err)
 		return err
 	}
+	if current.IsReply() {
+		return nil
+	}
+	return cuc.adjustRating(ctx, current.SpotID, current.StarRate, -1)
+}
+
+// canModify はコメントを変更・削除できるのが作成者と管理者だけであることを表します。
+func (cuc *commentUseCase) canModify(user model.User, comment model.Comment) bool {
+	return user.ID == comment.UserID || actsAsAdmin(cuc.tc, user)
+}
+
+// adjustRating はコメントの評価をSpotの評価の集計に反映します。
+func (cuc *commentUseCase) adjustRating(ctx context.Context, spotID uuid.UUID, starRate float64, delta int) error {
+	if err := cuc.sr.AdjustRating(ctx, spotID.String(), starRate, delta); err != nil {
+		log.Printf("Failed to adjust rating of spot %v: %v", spotID, err)
+		return err
+	}
 	return nil
 }
 
+func (cuc *commentUseCase) getComment(ctx context.Context, id string) (*model.Comment, error) {
+	comment, err := cuc.cr.Get(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrCommentNotFound
+	}
+	if err != nil {
+		log.Printf("Failed to get comment of %v: %v", id, err)
+		return nil, err
+	}
+	return comment, nil
+}
+
 func (cuc *commentUseCase) getMasterData(ctx context.Context, spotID string) []model.Comment {
 	comments, cacheErr := cuc.cc.Get(ctx, "comments_"+spotID)
 	if cacheErr != nil {
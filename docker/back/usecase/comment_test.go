@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
@@ -47,78 +48,240 @@ func TestCommentUseCase_ListComments(t *testing.T) {
 			Created:  created,
 		},
 	}
+	spotID := "fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"
+	qcs := []repository.QueryCondition{
+		{Field: "spot_id", Value: spotID},
+		{Field: "parent_id", Operator: repository.OpIsNull, Value: true},
+	}
+	threads := []CommentThread{{Comment: comments[0]}}
+	reply := model.Comment{
+		ID:       uuid.New(),
+		SpotID:   comments[0].SpotID,
+		UserID:   uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+		Text:     "ありがとうございます",
+		ParentID: uuid.NullUUID{UUID: comments[0].ID, Valid: true},
+		RootID:   uuid.NullUUID{UUID: comments[0].ID, Valid: true},
+		Depth:    1,
+		Kind:     model.CommentKindOwnerResponse,
+	}
+	other := model.Comment{
+		ID:       uuid.New(),
+		SpotID:   comments[0].SpotID,
+		UserID:   uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+		StarRate: 4.0,
+		Text:     "また来たいです",
+		Created:  created,
+	}
+
 	patterns := []struct {
 		name  string
 		setup func(
 			m *mock.MockCommentRepository,
 			m1 *mock.MockCommentsCacheRepository,
 		)
-		arg struct {
-			ctx    context.Context
-			spotID string
-		}
-		want struct {
-			comments []model.Comment
-			err      error
+		params *ListCommentsParams
+		want   struct {
+			result *ListCommentsResult
+			err    error
 		}
 	}{
 		{
 			name: "success",
 			setup: func(m *mock.MockCommentRepository, m1 *mock.MockCommentsCacheRepository) {
-				m.EXPECT().List(
+				m.EXPECT().ListPage(
 					gomock.Any(),
-					[]repository.QueryCondition{{Field: "SpotID", Value: "fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"}},
+					qcs,
+					repository.ListOptions{Limit: 50, OrderBy: DefaultCommentsOrderBy},
 				).Return(
-					comments, nil,
+					[]model.Comment(comments), "", nil,
 				)
+				m.EXPECT().Count(gomock.Any(), qcs).Return(1, nil)
 				m1.EXPECT().Set(
 					gomock.Any(),
 					"comments_fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052",
-					comments,
+					[]model.Comment(comments),
 				).Return(nil)
 			},
-			arg: struct {
-				ctx    context.Context
-				spotID string
+			params: &ListCommentsParams{SpotID: spotID, Limit: 50},
+			want: struct {
+				result *ListCommentsResult
+				err    error
 			}{
-				ctx:    context.Background(),
-				spotID: "fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052",
+				result: &ListCommentsResult{Threads: threads, Total: 1},
+				err:    nil,
+			},
+		},
+		{
+			name: "success: with replies",
+			setup: func(m *mock.MockCommentRepository, m1 *mock.MockCommentsCacheRepository) {
+				m.EXPECT().ListPage(
+					gomock.Any(),
+					qcs,
+					repository.ListOptions{Limit: 50, OrderBy: DefaultCommentsOrderBy},
+				).Return(
+					[]model.Comment(comments), "", nil,
+				)
+				m.EXPECT().Count(gomock.Any(), qcs).Return(1, nil)
+				m1.EXPECT().Set(gomock.Any(), "comments_fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052", []model.Comment(comments)).Return(nil)
+				m.EXPECT().ListReplyPages(gomock.Any(), []string{comments[0].ID.String()}, 1).Return(
+					map[string]repository.ReplyPage{
+						comments[0].ID.String(): {Replies: []model.Comment{reply}, NextCursor: "reply_cursor"},
+					}, nil,
+				)
+			},
+			params: &ListCommentsParams{SpotID: spotID, Limit: 50, ReplyLimit: 1},
+			want: struct {
+				result *ListCommentsResult
+				err    error
+			}{
+				result: &ListCommentsResult{
+					Threads: []CommentThread{
+						{Comment: comments[0], Replies: []model.Comment{reply}, NextReplyCursor: "reply_cursor"},
+					},
+					Total: 1,
+				},
+				err: nil,
+			},
+		},
+		{
+			name: "success: replies of all reviews in one query",
+			setup: func(m *mock.MockCommentRepository, m1 *mock.MockCommentsCacheRepository) {
+				m.EXPECT().ListPage(
+					gomock.Any(),
+					qcs,
+					repository.ListOptions{Limit: 50, OrderBy: DefaultCommentsOrderBy},
+				).Return(
+					[]model.Comment{comments[0], other}, "", nil,
+				)
+				m.EXPECT().Count(gomock.Any(), qcs).Return(2, nil)
+				m1.EXPECT().Set(gomock.Any(), "comments_fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052", gomock.Any()).Return(nil)
+				m.EXPECT().ListReplyPages(gomock.Any(), []string{comments[0].ID.String(), other.ID.String()}, 2).Return(
+					map[string]repository.ReplyPage{comments[0].ID.String(): {Replies: []model.Comment{reply}}}, nil,
+				)
+			},
+			params: &ListCommentsParams{SpotID: spotID, Limit: 50, ReplyLimit: 2},
+			want: struct {
+				result *ListCommentsResult
+				err    error
+			}{
+				result: &ListCommentsResult{
+					Threads: []CommentThread{
+						{Comment: comments[0], Replies: []model.Comment{reply}},
+						{Comment: other},
+					},
+					Total: 2,
+				},
+				err: nil,
+			},
+		},
+		{
+			name: "Fail: fail to get replies",
+			setup: func(m *mock.MockCommentRepository, m1 *mock.MockCommentsCacheRepository) {
+				m.EXPECT().ListPage(
+					gomock.Any(),
+					qcs,
+					repository.ListOptions{Limit: 50, OrderBy: DefaultCommentsOrderBy},
+				).Return(
+					[]model.Comment(comments), "", nil,
+				)
+				m.EXPECT().Count(gomock.Any(), qcs).Return(1, nil)
+				m1.EXPECT().Set(gomock.Any(), "comments_fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052", []model.Comment(comments)).Return(nil)
+				m.EXPECT().ListReplyPages(gomock.Any(), []string{comments[0].ID.String()}, 1).Return(
+					nil, fmt.Errorf("fail to get replies from db"),
+				)
+			},
+			params: &ListCommentsParams{SpotID: spotID, Limit: 50, ReplyLimit: 1},
+			want: struct {
+				result *ListCommentsResult
+				err    error
+			}{
+				result: nil,
+				err:    fmt.Errorf("fail to get replies from db"),
+			},
+		},
+		{
+			name: "success: has next page",
+			setup: func(m *mock.MockCommentRepository, m1 *mock.MockCommentsCacheRepository) {
+				m.EXPECT().ListPage(
+					gomock.Any(),
+					qcs,
+					repository.ListOptions{Limit: 1, Cursor: "cursor1", OrderBy: "created"},
+				).Return(
+					[]model.Comment(comments), "cursor2", nil,
+				)
+				m.EXPECT().Count(gomock.Any(), qcs).Return(3, nil)
 			},
+			params: &ListCommentsParams{SpotID: spotID, Limit: 1, Cursor: "cursor1", OrderBy: "created"},
 			want: struct {
-				comments []model.Comment
-				err      error
+				result *ListCommentsResult
+				err    error
 			}{
-				comments: comments,
-				err:      nil,
+				result: &ListCommentsResult{Threads: threads, NextCursor: "cursor2", Total: 3},
+				err:    nil,
 			},
 		},
 		{
 			name: "success: fail to get comments from db, but success to get comments from masterdata",
 			setup: func(m *mock.MockCommentRepository, m1 *mock.MockCommentsCacheRepository) {
-				m.EXPECT().List(
+				m.EXPECT().ListPage(
 					gomock.Any(),
-					[]repository.QueryCondition{{Field: "SpotID", Value: "fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"}},
+					qcs,
+					repository.ListOptions{Limit: 50, OrderBy: DefaultCommentsOrderBy},
 				).Return(
-					nil, fmt.Errorf("fail to get comments from db"),
+					nil, "", fmt.Errorf("fail to get comments from db"),
 				)
 				m1.EXPECT().Get(
 					gomock.Any(),
 					"comments_fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052",
 				).Return(&comments, nil)
 			},
-			arg: struct {
-				ctx    context.Context
-				spotID string
+			params: &ListCommentsParams{SpotID: spotID, Limit: 50},
+			want: struct {
+				result *ListCommentsResult
+				err    error
 			}{
-				ctx:    context.Background(),
-				spotID: "fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052",
+				result: &ListCommentsResult{Threads: threads, Total: 1},
+				err:    nil,
 			},
+		},
+		{
+			name: "Fail: fail to get next page from db",
+			setup: func(m *mock.MockCommentRepository, m1 *mock.MockCommentsCacheRepository) {
+				m.EXPECT().ListPage(
+					gomock.Any(),
+					qcs,
+					repository.ListOptions{Limit: 50, Cursor: "cursor1", OrderBy: DefaultCommentsOrderBy},
+				).Return(
+					nil, "", fmt.Errorf("fail to get comments from db"),
+				)
+			},
+			params: &ListCommentsParams{SpotID: spotID, Limit: 50, Cursor: "cursor1"},
 			want: struct {
-				comments []model.Comment
-				err      error
+				result *ListCommentsResult
+				err    error
 			}{
-				comments: comments,
-				err:      nil,
+				result: nil,
+				err:    fmt.Errorf("fail to get comments from db"),
+			},
+		},
+		{
+			name: "Fail: invalid cursor",
+			setup: func(m *mock.MockCommentRepository, m1 *mock.MockCommentsCacheRepository) {
+				m.EXPECT().ListPage(
+					gomock.Any(),
+					qcs,
+					repository.ListOptions{Limit: 50, Cursor: "invalid", OrderBy: DefaultCommentsOrderBy},
+				).Return(
+					nil, "", repository.ErrInvalidCursor,
+				)
+			},
+			params: &ListCommentsParams{SpotID: spotID, Limit: 50, Cursor: "invalid"},
+			want: struct {
+				result *ListCommentsResult
+				err    error
+			}{
+				result: nil,
+				err:    repository.ErrInvalidCursor,
 			},
 		},
 	}
@@ -129,22 +292,23 @@ func TestCommentUseCase_ListComments(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			cr := mock.NewMockCommentRepository(ctrl)
 			cc := mock.NewMockCommentsCacheRepository(ctrl)
+			sr := mock.NewMockSpotRepository(ctrl)
 
 			if tt.setup != nil {
 				tt.setup(cr, cc)
 			}
 
-			usecase := NewCommentUseCase(cr, cc)
+			usecase := NewCommentUseCase(cr, cc, sr, mock.NewMockSpotOwnerRepository(ctrl), testTwoFactorConfig)
 
-			getComments, err := usecase.ListComments(tt.arg.ctx, tt.arg.spotID)
+			result, err := usecase.ListComments(context.Background(), tt.params)
 
 			if (err != nil) != (tt.want.err != nil) {
 				t.Errorf("ListComments() error = %v, wantErr %v", err, tt.want.err)
 			} else if err != nil && tt.want.err != nil && err.Error() != tt.want.err.Error() {
 				t.Errorf("ListComments() error = %v, wantErr %v", err, tt.want.err)
 			}
-			if !reflect.DeepEqual(getComments, tt.want.comments) {
-				t.Errorf("GetSpot() \n got = %v,\n want %v", getComments, tt.want.comments)
+			if !reflect.DeepEqual(result, tt.want.result) {
+				t.Errorf("ListComments() \n got = %v,\n want %v", result, tt.want.result)
 			}
 		})
 	}
@@ -158,20 +322,17 @@ func TestCommentUseCase_CreateComment(t *testing.T) {
 		name  string
 		setup func(
 			m *mock.MockCommentRepository,
+			m1 *mock.MockSpotRepository,
 		)
 		params  *CreateCommentParams
 		wantErr error
 	}{
 		{
 			name: "success",
-			setup: func(m *mock.MockCommentRepository) {
-				comment := model.Comment{
-					SpotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
-					UserID:   uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
-					StarRate: 5.0,
-					Text:     "いいスポットでした！!!",
-				}
-				m.EXPECT().Create(gomock.Any(), comment).Return(nil)
+			setup: func(m *mock.MockCommentRepository, m1 *mock.MockSpotRepository) {
+				// IDは作成時に採番されるため比較しない
+				m.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+				m1.EXPECT().AdjustRating(gomock.Any(), "fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052", 5.0, 1).Return(nil)
 			},
 			params: &CreateCommentParams{
 				UserID:   uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
@@ -181,6 +342,25 @@ func TestCommentUseCase_CreateComment(t *testing.T) {
 			},
 			wantErr: nil,
 		},
+		{
+			name: "Fail: adjust rating",
+			setup: func(m *mock.MockCommentRepository, m1 *mock.MockSpotRepository) {
+				m.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+				m1.EXPECT().AdjustRating(
+					gomock.Any(),
+					"fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052",
+					5.0,
+					1,
+				).Return(repository.ErrNotFound)
+			},
+			params: &CreateCommentParams{
+				UserID:   uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+				SpotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
+				StarRate: 5.0,
+				Text:     "いいスポットでした！!!",
+			},
+			wantErr: repository.ErrNotFound,
+		},
 	}
 	for _, tt := range patterns {
 		t.Run(tt.name, func(t *testing.T) {
@@ -189,12 +369,13 @@ func TestCommentUseCase_CreateComment(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			cr := mock.NewMockCommentRepository(ctrl)
 			cc := mock.NewMockCommentsCacheRepository(ctrl)
+			sr := mock.NewMockSpotRepository(ctrl)
 
 			if tt.setup != nil {
-				tt.setup(cr)
+				tt.setup(cr, sr)
 			}
 
-			usecase := NewCommentUseCase(cr, cc)
+			usecase := NewCommentUseCase(cr, cc, sr, mock.NewMockSpotOwnerRepository(ctrl), testTwoFactorConfig)
 
 			err := usecase.CreateComment(ctx, tt.params)
 
@@ -213,31 +394,21 @@ func TestCommentuseCase_BatchCreateComments(t *testing.T) {
 		name  string
 		setup func(
 			m *mock.MockCommentRepository,
+			m1 *mock.MockSpotRepository,
 		)
 		params  *BatchCreateCommentsParams
 		wantErr error
 	}{
 		{
 			name: "success",
-			setup: func(m *mock.MockCommentRepository) {
-				comments := []model.Comment{
-					{
-						SpotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
-						UserID:   uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
-						StarRate: 5.0,
-						Text:     "いいスポットでした！!!",
-					},
-					{
-						SpotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b505312"),
-						UserID:   uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec3"),
-						StarRate: 4.0,
-						Text:     "最高のスポットでした！!!",
-					},
-				}
+			setup: func(m *mock.MockCommentRepository, m1 *mock.MockSpotRepository) {
+				// IDは作成時に採番されるため比較しない
 				m.EXPECT().BatchCreate(
 					gomock.Any(),
-					comments,
+					gomock.Len(2),
 				).Return(nil)
+				m1.EXPECT().AdjustRating(gomock.Any(), "fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052", 5.0, 1).Return(nil)
+				m1.EXPECT().AdjustRating(gomock.Any(), "fb816fc7-ddcf-4fa0-9be0-d1fd0b505312", 4.0, 1).Return(nil)
 			},
 			params: &BatchCreateCommentsParams{
 				Comments: []CreateCommentParams{
@@ -265,12 +436,13 @@ func TestCommentuseCase_BatchCreateComments(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			cr := mock.NewMockCommentRepository(ctrl)
 			cc := mock.NewMockCommentsCacheRepository(ctrl)
+			sr := mock.NewMockSpotRepository(ctrl)
 
 			if tt.setup != nil {
-				tt.setup(cr)
+				tt.setup(cr, sr)
 			}
 
-			usecase := NewCommentUseCase(cr, cc)
+			usecase := NewCommentUseCase(cr, cc, sr, mock.NewMockSpotOwnerRepository(ctrl), testTwoFactorConfig)
 
 			err := usecase.BatchCreateComments(
 				context.Background(),
@@ -292,13 +464,14 @@ func TestCommentUseCase_UpdateComment(t *testing.T) {
 		name  string
 		setup func(
 			m *mock.MockCommentRepository,
+			m1 *mock.MockSpotRepository,
 		)
 		arg     CommentUpdateArg
 		wantErr error
 	}{
 		{
 			name: "success",
-			setup: func(m *mock.MockCommentRepository) {
+			setup: func(m *mock.MockCommentRepository, m1 *mock.MockSpotRepository) {
 				comment := model.Comment{
 					ID:       uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
 					SpotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
@@ -306,7 +479,16 @@ func TestCommentUseCase_UpdateComment(t *testing.T) {
 					StarRate: 5.0,
 					Text:     "いいスポットでした！!!",
 				}
+				m.EXPECT().Get(gomock.Any(), "31894386-3e60-45a8-bc67-f46b72b42554").Return(&model.Comment{
+					ID:       uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+					SpotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
+					UserID:   uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+					StarRate: 3.0,
+					Text:     "いいスポットでした！!!",
+				}, nil)
 				m.EXPECT().Update(gomock.Any(), "31894386-3e60-45a8-bc67-f46b72b42554", comment).Return(nil)
+				m1.EXPECT().AdjustRating(gomock.Any(), "fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052", 3.0, -1).Return(nil)
+				m1.EXPECT().AdjustRating(gomock.Any(), "fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052", 5.0, 1).Return(nil)
 			},
 			arg: CommentUpdateArg{
 				ctx:      context.Background(),
@@ -327,7 +509,7 @@ func TestCommentUseCase_UpdateComment(t *testing.T) {
 		},
 		{
 			name: "success: Super User",
-			setup: func(m *mock.MockCommentRepository) {
+			setup: func(m *mock.MockCommentRepository, m1 *mock.MockSpotRepository) {
 				comment := model.Comment{
 					ID:       uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
 					SpotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
@@ -335,7 +517,15 @@ func TestCommentUseCase_UpdateComment(t *testing.T) {
 					StarRate: 5.0,
 					Text:     "いいスポットでした！!!",
 				}
+				m.EXPECT().Get(gomock.Any(), "31894386-3e60-45a8-bc67-f46b72b42554").Return(&model.Comment{
+					ID:       uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+					SpotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
+					UserID:   uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+					StarRate: 5.0,
+					Text:     "いいスポットでした！!!",
+				}, nil)
 				m.EXPECT().Update(gomock.Any(), "31894386-3e60-45a8-bc67-f46b72b42554", comment).Return(nil)
+				// 評価が変わらない場合は集計を更新しない
 			},
 			arg: CommentUpdateArg{
 				ctx:      context.Background(),
@@ -345,17 +535,65 @@ func TestCommentUseCase_UpdateComment(t *testing.T) {
 				starRate: 5.0,
 				text:     "いいスポットでした！!!",
 				user: model.User{
-					ID:       uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e61234"),
-					Name:     "super_user",
-					Email:    "super_user@gmail.com",
+					ID:          uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e61234"),
+					Name:        "super_user",
+					Email:       "super_user@gmail.com",
+					Password:    "password123",
+					IsAdmin:     true,
+					TOTPEnabled: true,
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "success: spot is not changed",
+			setup: func(m *mock.MockCommentRepository, m1 *mock.MockSpotRepository) {
+				comment := model.Comment{
+					ID:       uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+					SpotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
+					UserID:   uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+					StarRate: 4.0,
+					Text:     "いいスポットでした！!!",
+				}
+				m.EXPECT().Get(gomock.Any(), "31894386-3e60-45a8-bc67-f46b72b42554").Return(&model.Comment{
+					ID:       uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+					SpotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
+					UserID:   uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+					StarRate: 5.0,
+					Text:     "いいスポットでした！!!",
+				}, nil)
+				// 返信とスレッドが分かれないよう、リクエストのspotIDではなく保存済みのSpotのまま更新する
+				m.EXPECT().Update(gomock.Any(), "31894386-3e60-45a8-bc67-f46b72b42554", comment).Return(nil)
+				m1.EXPECT().AdjustRating(gomock.Any(), "fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052", 5.0, -1).Return(nil)
+				m1.EXPECT().AdjustRating(gomock.Any(), "fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052", 4.0, 1).Return(nil)
+			},
+			arg: CommentUpdateArg{
+				ctx:      context.Background(),
+				id:       uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+				spotID:   uuid.MustParse("6f4b3a1e-2c8d-4e5f-9a0b-1c2d3e4f5a6b"),
+				userID:   uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+				starRate: 4.0,
+				text:     "いいスポットでした！!!",
+				user: model.User{
+					ID:       uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+					Name:     "test",
+					Email:    "test@gmail.com",
 					Password: "password123",
-					IsAdmin:  true,
+					IsAdmin:  false,
 				},
 			},
 			wantErr: nil,
 		},
 		{
 			name: "Fail: Not authorized to update",
+			setup: func(m *mock.MockCommentRepository, m1 *mock.MockSpotRepository) {
+				m.EXPECT().Get(gomock.Any(), "31894386-3e60-45a8-bc67-f46b72b42554").Return(&model.Comment{
+					ID:       uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+					SpotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
+					UserID:   uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+					StarRate: 3.0,
+				}, nil)
+			},
 			arg: CommentUpdateArg{
 				ctx:      context.Background(),
 				id:       uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
@@ -371,7 +609,67 @@ func TestCommentUseCase_UpdateComment(t *testing.T) {
 					IsAdmin:  false,
 				},
 			},
-			wantErr: fmt.Errorf("don't have permission to update comment"),
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			// リクエストのuserIDを自分のIDにしても、他のユーザのレビューは更新できない
+			name: "Fail: non-owner passes own userID",
+			setup: func(m *mock.MockCommentRepository, m1 *mock.MockSpotRepository) {
+				m.EXPECT().Get(gomock.Any(), "31894386-3e60-45a8-bc67-f46b72b42554").Return(&model.Comment{
+					ID:       uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+					SpotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
+					UserID:   uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+					StarRate: 3.0,
+				}, nil)
+			},
+			arg: CommentUpdateArg{
+				ctx:      context.Background(),
+				id:       uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+				spotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
+				userID:   uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e61234"),
+				starRate: 1.0,
+				text:     "最悪でした",
+				user:     model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e61234")},
+			},
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			// 2段階認証が必須の管理者は、設定するまで他のユーザのレビューを更新できない
+			name: "Fail: admin without two-factor",
+			setup: func(m *mock.MockCommentRepository, m1 *mock.MockSpotRepository) {
+				m.EXPECT().Get(gomock.Any(), "31894386-3e60-45a8-bc67-f46b72b42554").Return(&model.Comment{
+					ID:       uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+					SpotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
+					UserID:   uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+					StarRate: 3.0,
+				}, nil)
+			},
+			arg: CommentUpdateArg{
+				ctx:      context.Background(),
+				id:       uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+				spotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
+				userID:   uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+				starRate: 1.0,
+				text:     "最悪でした",
+				user:     model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e61234"), Role: model.RoleAdmin},
+			},
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			name: "Fail: comment not found",
+			setup: func(m *mock.MockCommentRepository, m1 *mock.MockSpotRepository) {
+				m.EXPECT().Get(gomock.Any(), "31894386-3e60-45a8-bc67-f46b72b42554").Return(nil, repository.ErrNotFound)
+			},
+			arg: CommentUpdateArg{
+				ctx:      context.Background(),
+				id:       uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+				spotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
+				userID:   uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+				starRate: 5.0,
+				text:     "いいスポットでした！!!",
+				user:     model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")},
+			},
+			wantErr: ErrCommentNotFound,
 		},
 	}
 	for _, tt := range patterns {
@@ -381,12 +679,13 @@ func TestCommentUseCase_UpdateComment(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			cr := mock.NewMockCommentRepository(ctrl)
 			cc := mock.NewMockCommentsCacheRepository(ctrl)
+			sr := mock.NewMockSpotRepository(ctrl)
 
 			if tt.setup != nil {
-				tt.setup(cr)
+				tt.setup(cr, sr)
 			}
 
-			usecase := NewCommentUseCase(cr, cc)
+			usecase := NewCommentUseCase(cr, cc, sr, mock.NewMockSpotOwnerRepository(ctrl), testTwoFactorConfig)
 
 			err := usecase.UpdateComment(
 				tt.arg.ctx,
@@ -413,17 +712,25 @@ func TestCommentUseCase_DeleteComment(t *testing.T) {
 		name  string
 		setup func(
 			m *mock.MockCommentRepository,
+			m1 *mock.MockSpotRepository,
 		)
 		arg     CommentDeleteArg
 		wantErr error
 	}{
 		{
 			name: "success",
-			setup: func(m *mock.MockCommentRepository) {
+			setup: func(m *mock.MockCommentRepository, m1 *mock.MockSpotRepository) {
+				m.EXPECT().Get(gomock.Any(), "31894386-3e60-45a8-bc67-f46b72b42554").Return(&model.Comment{
+					ID:       uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+					SpotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
+					UserID:   uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+					StarRate: 4.0,
+				}, nil)
 				m.EXPECT().Delete(
 					gomock.Any(),
 					"31894386-3e60-45a8-bc67-f46b72b42554",
 				).Return(nil)
+				m1.EXPECT().AdjustRating(gomock.Any(), "fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052", 4.0, -1).Return(nil)
 			},
 			arg: CommentDeleteArg{
 				ctx:    context.Background(),
@@ -441,28 +748,44 @@ func TestCommentUseCase_DeleteComment(t *testing.T) {
 		},
 		{
 			name: "success: Super User",
-			setup: func(m *mock.MockCommentRepository) {
+			setup: func(m *mock.MockCommentRepository, m1 *mock.MockSpotRepository) {
+				m.EXPECT().Get(gomock.Any(), "31894386-3e60-45a8-bc67-f46b72b42554").Return(&model.Comment{
+					ID:       uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+					SpotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
+					UserID:   uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+					StarRate: 4.0,
+				}, nil)
 				m.EXPECT().Delete(
 					gomock.Any(),
 					"31894386-3e60-45a8-bc67-f46b72b42554",
 				).Return(nil)
+				m1.EXPECT().AdjustRating(gomock.Any(), "fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052", 4.0, -1).Return(nil)
 			},
 			arg: CommentDeleteArg{
 				ctx:    context.Background(),
 				id:     "31894386-3e60-45a8-bc67-f46b72b42554",
 				userID: "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
 				user: model.User{
-					ID:       uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e61234"),
-					Name:     "super_user",
-					Email:    "super_user@gmail.com",
-					Password: "password123",
-					IsAdmin:  true,
+					ID:          uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e61234"),
+					Name:        "super_user",
+					Email:       "super_user@gmail.com",
+					Password:    "password123",
+					IsAdmin:     true,
+					TOTPEnabled: true,
 				},
 			},
 			wantErr: nil,
 		},
 		{
 			name: "Fail: Not authorized to delete",
+			setup: func(m *mock.MockCommentRepository, m1 *mock.MockSpotRepository) {
+				m.EXPECT().Get(gomock.Any(), "31894386-3e60-45a8-bc67-f46b72b42554").Return(&model.Comment{
+					ID:       uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+					SpotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
+					UserID:   uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+					StarRate: 4.0,
+				}, nil)
+			},
 			arg: CommentDeleteArg{
 				ctx:    context.Background(),
 				id:     "31894386-3e60-45a8-bc67-f46b72b42554",
@@ -475,7 +798,45 @@ func TestCommentUseCase_DeleteComment(t *testing.T) {
 					IsAdmin:  false,
 				},
 			},
-			wantErr: fmt.Errorf("don't have permission to delete comment"),
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			// リクエストのuserIDを自分のIDにしても、他のユーザのレビューは削除できない
+			name: "Fail: non-owner passes own userID",
+			setup: func(m *mock.MockCommentRepository, m1 *mock.MockSpotRepository) {
+				m.EXPECT().Get(gomock.Any(), "31894386-3e60-45a8-bc67-f46b72b42554").Return(&model.Comment{
+					ID:       uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+					SpotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
+					UserID:   uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+					StarRate: 4.0,
+				}, nil)
+			},
+			arg: CommentDeleteArg{
+				ctx:    context.Background(),
+				id:     "31894386-3e60-45a8-bc67-f46b72b42554",
+				userID: "f6db2530-cd9b-4ac1-8dc1-38c795e61234",
+				user:   model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e61234")},
+			},
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			// 2段階認証が必須の管理者は、設定するまで他のユーザのレビューを削除できない
+			name: "Fail: admin without two-factor",
+			setup: func(m *mock.MockCommentRepository, m1 *mock.MockSpotRepository) {
+				m.EXPECT().Get(gomock.Any(), "31894386-3e60-45a8-bc67-f46b72b42554").Return(&model.Comment{
+					ID:       uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+					SpotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
+					UserID:   uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+					StarRate: 4.0,
+				}, nil)
+			},
+			arg: CommentDeleteArg{
+				ctx:    context.Background(),
+				id:     "31894386-3e60-45a8-bc67-f46b72b42554",
+				userID: "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
+				user:   model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e61234"), Role: model.RoleAdmin},
+			},
+			wantErr: ErrPermissionDenied,
 		},
 	}
 	for _, tt := range patterns {
@@ -485,12 +846,13 @@ func TestCommentUseCase_DeleteComment(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			cr := mock.NewMockCommentRepository(ctrl)
 			cc := mock.NewMockCommentsCacheRepository(ctrl)
+			sr := mock.NewMockSpotRepository(ctrl)
 
 			if tt.setup != nil {
-				tt.setup(cr)
+				tt.setup(cr, sr)
 			}
 
-			usecase := NewCommentUseCase(cr, cc)
+			usecase := NewCommentUseCase(cr, cc, sr, mock.NewMockSpotOwnerRepository(ctrl), testTwoFactorConfig)
 
 			err := usecase.DeleteComment(
 				tt.arg.ctx,
@@ -507,3 +869,262 @@ func TestCommentUseCase_DeleteComment(t *testing.T) {
 		})
 	}
 }
+
+func TestCommentUseCase_CreateReply(t *testing.T) {
+	t.Parallel()
+	spotID := uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052")
+	userID := uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")
+	review := model.Comment{ID: uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"), SpotID: spotID, StarRate: 4}
+	reply := model.Comment{
+		ID:       uuid.MustParse("5ba6a9f5-4b3c-4e47-8d5e-0c3c1f0d6a2e"),
+		SpotID:   spotID,
+		ParentID: uuid.NullUUID{UUID: review.ID, Valid: true},
+		RootID:   uuid.NullUUID{UUID: review.ID, Valid: true},
+		Depth:    1,
+	}
+	owners := []repository.QueryCondition{
+		{Field: "spot_id", Value: spotID.String()},
+		{Field: "user_id", Value: userID.String()},
+	}
+
+	patterns := []struct {
+		name     string
+		parentID uuid.UUID
+		setup    func(m *mock.MockCommentRepository, m1 *mock.MockSpotOwnerRepository)
+		want     model.Comment
+		wantErr  error
+	}{
+		{
+			name:     "success: reply to review",
+			parentID: review.ID,
+			setup: func(m *mock.MockCommentRepository, m1 *mock.MockSpotOwnerRepository) {
+				m.EXPECT().Get(gomock.Any(), review.ID.String()).Return(&review, nil)
+				m1.EXPECT().Count(gomock.Any(), owners).Return(0, nil)
+			},
+			want: model.Comment{ParentID: reply.ParentID, RootID: reply.RootID, Depth: 1, Kind: model.CommentKindReply},
+		},
+		{
+			name:     "success: owner response to nested reply",
+			parentID: reply.ID,
+			setup: func(m *mock.MockCommentRepository, m1 *mock.MockSpotOwnerRepository) {
+				m.EXPECT().Get(gomock.Any(), reply.ID.String()).Return(&reply, nil)
+				m1.EXPECT().Count(gomock.Any(), owners).Return(1, nil)
+			},
+			want: model.Comment{
+				ParentID: uuid.NullUUID{UUID: reply.ID, Valid: true},
+				RootID:   reply.RootID,
+				Depth:    2,
+				Kind:     model.CommentKindOwnerResponse,
+			},
+		},
+		{
+			name:     "Fail: too deep",
+			parentID: reply.ID,
+			setup: func(m *mock.MockCommentRepository, _ *mock.MockSpotOwnerRepository) {
+				deep := reply
+				deep.Depth = model.MaxCommentDepth
+				m.EXPECT().Get(gomock.Any(), reply.ID.String()).Return(&deep, nil)
+			},
+			wantErr: ErrCommentTooDeep,
+		},
+		{
+			name:     "Fail: parent of another spot",
+			parentID: review.ID,
+			setup: func(m *mock.MockCommentRepository, _ *mock.MockSpotOwnerRepository) {
+				other := review
+				other.SpotID = uuid.New()
+				m.EXPECT().Get(gomock.Any(), review.ID.String()).Return(&other, nil)
+			},
+			wantErr: ErrCommentNotFound,
+		},
+		{
+			name:     "Fail: parent not found",
+			parentID: review.ID,
+			setup: func(m *mock.MockCommentRepository, _ *mock.MockSpotOwnerRepository) {
+				m.EXPECT().Get(gomock.Any(), review.ID.String()).Return(nil, repository.ErrNotFound)
+			},
+			wantErr: ErrCommentNotFound,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			cr := mock.NewMockCommentRepository(ctrl)
+			sor := mock.NewMockSpotOwnerRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(cr, sor)
+			}
+			if tt.wantErr == nil {
+				// 返信は評価を持たないため、Spotの評価は更新しない
+				cr.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, c model.Comment) error {
+					if c.ParentID != tt.want.ParentID || c.RootID != tt.want.RootID || c.Depth != tt.want.Depth ||
+						c.Kind != tt.want.Kind || c.StarRate != 0 {
+						t.Errorf("Create() comment = %+v, want %+v", c, tt.want)
+					}
+					return nil
+				})
+			}
+
+			usecase := NewCommentUseCase(cr, mock.NewMockCommentsCacheRepository(ctrl), mock.NewMockSpotRepository(ctrl), sor, testTwoFactorConfig)
+			err := usecase.CreateComment(context.Background(), &CreateCommentParams{
+				UserID:   userID,
+				SpotID:   spotID,
+				ParentID: tt.parentID,
+				StarRate: 5,
+				Text:     "ありがとうございます",
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CreateComment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCommentUseCase_ListReplies(t *testing.T) {
+	t.Parallel()
+	review := model.Comment{ID: uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554")}
+	reply := model.Comment{
+		ID:       uuid.MustParse("5ba6a9f5-4b3c-4e47-8d5e-0c3c1f0d6a2e"),
+		ParentID: uuid.NullUUID{UUID: review.ID, Valid: true},
+		RootID:   uuid.NullUUID{UUID: review.ID, Valid: true},
+		Depth:    1,
+	}
+	thread := []repository.QueryCondition{{Field: "root_id", Value: review.ID.String()}}
+
+	patterns := []struct {
+		name    string
+		params  *ListRepliesParams
+		setup   func(m *mock.MockCommentRepository)
+		want    *ListRepliesResult
+		wantErr error
+	}{
+		{
+			name:   "success",
+			params: &ListRepliesParams{CommentID: review.ID.String(), Limit: 1, Cursor: "cursor1"},
+			setup: func(m *mock.MockCommentRepository) {
+				m.EXPECT().Get(gomock.Any(), review.ID.String()).Return(&review, nil)
+				m.EXPECT().ListPage(gomock.Any(), thread, repository.ListOptions{Limit: 1, Cursor: "cursor1", OrderBy: "created"}).
+					Return([]model.Comment{reply}, "cursor2", nil)
+				m.EXPECT().Count(gomock.Any(), thread).Return(3, nil)
+			},
+			want: &ListRepliesResult{Replies: []model.Comment{reply}, NextCursor: "cursor2", Total: 3},
+		},
+		{
+			name:   "Fail: not a thread",
+			params: &ListRepliesParams{CommentID: reply.ID.String(), Limit: 1},
+			setup: func(m *mock.MockCommentRepository) {
+				m.EXPECT().Get(gomock.Any(), reply.ID.String()).Return(&reply, nil)
+			},
+			wantErr: ErrCommentNotFound,
+		},
+		{
+			name:   "Fail: invalid cursor",
+			params: &ListRepliesParams{CommentID: review.ID.String(), Limit: 1, Cursor: "invalid"},
+			setup: func(m *mock.MockCommentRepository) {
+				m.EXPECT().Get(gomock.Any(), review.ID.String()).Return(&review, nil)
+				m.EXPECT().ListPage(gomock.Any(), thread, repository.ListOptions{Limit: 1, Cursor: "invalid", OrderBy: "created"}).
+					Return(nil, "", repository.ErrInvalidCursor)
+			},
+			wantErr: repository.ErrInvalidCursor,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			cr := mock.NewMockCommentRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(cr)
+			}
+
+			usecase := NewCommentUseCase(
+				cr, mock.NewMockCommentsCacheRepository(ctrl), mock.NewMockSpotRepository(ctrl),
+				mock.NewMockSpotOwnerRepository(ctrl), testTwoFactorConfig,
+			)
+			got, err := usecase.ListReplies(context.Background(), tt.params)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ListReplies() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListReplies() \n got = %v,\n want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommentUseCase_UpdateAndDeleteReply(t *testing.T) {
+	t.Parallel()
+	userID := uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")
+	user := model.User{ID: userID}
+	reply := model.Comment{
+		ID:       uuid.MustParse("5ba6a9f5-4b3c-4e47-8d5e-0c3c1f0d6a2e"),
+		SpotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
+		UserID:   userID,
+		Text:     "ありがとうございます",
+		ParentID: uuid.NullUUID{UUID: uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"), Valid: true},
+		RootID:   uuid.NullUUID{UUID: uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"), Valid: true},
+		Depth:    1,
+		Kind:     model.CommentKindReply,
+	}
+	ctrl := gomock.NewController(t)
+	cr := mock.NewMockCommentRepository(ctrl)
+	usecase := NewCommentUseCase(
+		cr, mock.NewMockCommentsCacheRepository(ctrl), mock.NewMockSpotRepository(ctrl), mock.NewMockSpotOwnerRepository(ctrl),
+		testTwoFactorConfig,
+	)
+
+	// 返信は本文のみ更新し、評価やSpotは変更せず、Spotの評価も更新しない
+	updated := reply
+	updated.Text = "また来てください"
+	cr.EXPECT().Get(gomock.Any(), reply.ID.String()).Return(&reply, nil)
+	cr.EXPECT().Update(gomock.Any(), reply.ID.String(), updated).Return(nil)
+	if err := usecase.UpdateComment(
+		context.Background(), reply.ID, uuid.New(), userID, 5, "また来てください", user,
+	); err != nil {
+		t.Fatalf("UpdateComment() error = %v", err)
+	}
+
+	cr.EXPECT().Get(gomock.Any(), reply.ID.String()).Return(&reply, nil)
+	cr.EXPECT().Delete(gomock.Any(), reply.ID.String()).Return(nil)
+	if err := usecase.DeleteComment(context.Background(), reply.ID.String(), userID.String(), user); err != nil {
+		t.Fatalf("DeleteComment() error = %v", err)
+	}
+}
+
+func TestCommentUseCase_UpdateOwnerResponse_NotOwner(t *testing.T) {
+	t.Parallel()
+	ownerID := uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")
+	otherID := uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e61234")
+	response := model.Comment{
+		ID:       uuid.MustParse("5ba6a9f5-4b3c-4e47-8d5e-0c3c1f0d6a2e"),
+		SpotID:   uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
+		UserID:   ownerID,
+		Text:     "ご利用ありがとうございました",
+		ParentID: uuid.NullUUID{UUID: uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"), Valid: true},
+		RootID:   uuid.NullUUID{UUID: uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"), Valid: true},
+		Depth:    1,
+		Kind:     model.CommentKindOwnerResponse,
+	}
+	ctrl := gomock.NewController(t)
+	cr := mock.NewMockCommentRepository(ctrl)
+	usecase := NewCommentUseCase(
+		cr, mock.NewMockCommentsCacheRepository(ctrl), mock.NewMockSpotRepository(ctrl), mock.NewMockSpotOwnerRepository(ctrl),
+		testTwoFactorConfig,
+	)
+
+	// リクエストのuserIDをオーナーのIDにしても、オーナー以外はオーナーの返信を書き換えられない
+	cr.EXPECT().Get(gomock.Any(), response.ID.String()).Return(&response, nil)
+	err := usecase.UpdateComment(
+		context.Background(), response.ID, response.SpotID, ownerID, 0, "書き換えました", model.User{ID: otherID},
+	)
+	if !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("UpdateComment() error = %v, wantErr %v", err, ErrPermissionDenied)
+	}
+}
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrPermissionDenied は、ユーザに操作の権限がない場合に返します。
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrInvalidRefreshToken は、リフレッシュトークンが存在しない・期限切れ・失効済みの場合に返します。
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused は、ローテーション済みのリフレッシュトークンが再利用された場合に返します。
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+	// ErrInvalidUserToken は、メール確認やパスワード再設定のトークンが存在しない・期限切れ・使用済みの場合に返します。
+	ErrInvalidUserToken = errors.New("invalid or expired token")
+	// ErrEmailAlreadyVerified は、確認済みのメールアドレスに確認メールを再送しようとした場合に返します。
+	ErrEmailAlreadyVerified = errors.New("email already verified")
+	// ErrTooManyLoginAttempts は、ログインの失敗が続いて一時的にログインできない場合に返します。
+	ErrTooManyLoginAttempts = errors.New("too many login attempts")
+	// ErrInvalidOIDCState は、外部ログインのコールバックのstateが存在しない・期限切れ・使用済みの場合に返します。
+	ErrInvalidOIDCState = errors.New("invalid oidc state")
+	// ErrOIDCEmailRequired は、IDプロバイダがメールアドレスを返さず、ユーザを作成できない場合に返します。
+	ErrOIDCEmailRequired = errors.New("oidc provider did not return an email")
+	// ErrOIDCAccountConflict は、外部ログインのメールアドレスのユーザが既に存在し、安全に紐付けられない場合に返します。
+	ErrOIDCAccountConflict = errors.New("an account with this email already exists")
+	// ErrIncorrectPassword は、パスワードの変更やアカウントの削除で確認のために入力したパスワードが誤っている場合に返します。
+	ErrIncorrectPassword = errors.New("incorrect password")
+	// ErrAccountSuspended は、管理者によって利用を停止されたユーザがログインしようとした場合に返します。
+	ErrAccountSuspended = errors.New("account suspended")
+	// ErrInvalidRole は、存在しないロールを指定した場合に返します。
+	ErrInvalidRole = errors.New("invalid role")
+	// ErrCannotModifySelf は、管理者が自分自身のロールの変更や利用停止をしようとした場合に返します。
+	ErrCannotModifySelf = errors.New("cannot modify own account")
+	// ErrTwoFactorRequired は、パスワードは正しいが2段階目の認証が必要な場合に返します。
+	ErrTwoFactorRequired = errors.New("two-factor authentication required")
+	// ErrInvalidTwoFactorChallenge は、2段階目の認証のチャレンジトークンが存在しない・期限切れ・使用済みの場合に返します。
+	ErrInvalidTwoFactorChallenge = errors.New("invalid or expired two-factor challenge")
+	// ErrInvalidTwoFactorCode は、ワンタイムパスワードやリカバリーコードが誤っている・使用済みの場合に返します。
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	// ErrTwoFactorAlreadyEnabled は、2段階認証が有効なユーザが設定をやり直そうとした場合に返します。
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication already enabled")
+	// ErrTwoFactorNotEnabled は、2段階認証が無効なユーザが無効化やリカバリーコードの再発行をしようとした場合に返します。
+	ErrTwoFactorNotEnabled = errors.New("two-factor authentication not enabled")
+	// ErrTwoFactorSetupNotStarted は、設定を開始していない・期限切れの状態で2段階認証を有効にしようとした場合に返します。
+	ErrTwoFactorSetupNotStarted = errors.New("two-factor setup not started or expired")
+	// ErrTwoFactorMandatory は、2段階認証が必須のユーザが2段階認証を無効にしようとした場合に返します。
+	ErrTwoFactorMandatory = errors.New("two-factor authentication is mandatory for this account")
+	// ErrUnsupportedImageType は、アップロードされたファイルがJPEG・PNG以外の場合に返します。
+	ErrUnsupportedImageType = errors.New("unsupported image type")
+	// ErrImageTooLarge は、アップロードされた画像が上限のサイズを超えている場合に返します。
+	ErrImageTooLarge = errors.New("image too large")
+	// ErrInvalidImage は、アップロードされた画像が壊れていて読み込めない場合に返します。
+	ErrInvalidImage = errors.New("invalid image")
+	// ErrDirectUploadUnavailable は、保存先が署名付きURLでの直接のアップロードに対応していない場合に返します。
+	ErrDirectUploadUnavailable = errors.New("direct upload is not available")
+	// ErrUploadNotFound は、直接のアップロードを完了しようとしたファイルが存在しない・他のユーザのものの場合に返します。
+	ErrUploadNotFound = errors.New("upload not found")
+	// ErrSpotNotFound は、画像を直接アップロードしようとしたSpotが存在しない場合に返します。
+	ErrSpotNotFound = errors.New("spot not found")
+	// ErrImageNotFound は、通報やモデレーションの対象の画像が存在しない・公開されていない場合に返します。
+	ErrImageNotFound = errors.New("image not found")
+	// ErrAlreadyReported は、同じユーザが同じ画像を再び通報しようとした場合に返します。
+	ErrAlreadyReported = errors.New("image already reported")
+	// ErrInvalidImageStatus は、モデレーションで承認・却下以外の状態を指定した場合に返します。
+	ErrInvalidImageStatus = errors.New("invalid image status")
+	// ErrCommentNotFound は、返信先やスレッドのコメントが存在しない・別のSpotのものの場合に返します。
+	ErrCommentNotFound = errors.New("comment not found")
+	// ErrCommentTooDeep は、返信の入れ子がmodel.MaxCommentDepthを超える場合に返します。
+	ErrCommentTooDeep = errors.New("comment reply nesting too deep")
+	// ErrInvalidStarRate は、レビューの評価が1〜5の範囲外の場合に返します。
+	ErrInvalidStarRate = errors.New("invalid star rate")
+)
+
+// LoginThrottledError はログインできるようになるまでの時間を持つErrTooManyLoginAttemptsです。
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("%v: retry after %v", ErrTooManyLoginAttempts, e.RetryAfter)
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return ErrTooManyLoginAttempts
+}
+
+// TwoFactorRequiredError は2段階目の認証に使うチャレンジトークンを持つErrTwoFactorRequiredです。
+type TwoFactorRequiredError struct {
+	ChallengeToken string
+	ExpiresIn      time.Duration
+}
+
+func (e *TwoFactorRequiredError) Error() string {
+	return ErrTwoFactorRequired.Error()
+}
+
+func (e *TwoFactorRequiredError) Unwrap() error {
+	return ErrTwoFactorRequired
+}
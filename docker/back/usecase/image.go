@@ -3,44 +3,120 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 
 	"github.com/google/uuid"
 
+	"github.com/tusmasoma/campfinder/docker/back/config"
 	"github.com/tusmasoma/campfinder/docker/back/domain/model"
 	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+	"github.com/tusmasoma/campfinder/docker/back/internal/storage"
 )
 
+// DefaultImagesOrderBy は新しい順に並べるための既定の並び順です。
+const DefaultImagesOrderBy = "-created"
+
 type ImageUseCase interface {
-	ListImages(ctx context.Context, spotID string) ([]model.Image, error)
+	ListImages(ctx context.Context, params *ListImagesParams) (*ListImagesResult, error)
 	CreateImage(ctx context.Context, spotID uuid.UUID, url string, user model.User) error
+	UploadImage(ctx context.Context, spotID uuid.UUID, data []byte, user model.User) (*model.Image, error)
+	CreateUploadURL(ctx context.Context, spotID uuid.UUID, contentType string, user model.User) (*UploadURL, error)
+	CompleteUpload(ctx context.Context, key string, user model.User) (*model.Image, error)
 	DeleteImage(ctx context.Context, id string, userID string, user model.User) error
+	ReportImage(ctx context.Context, id string, reason string, user model.User) error
+	ListModerationQueue(ctx context.Context, params *ListModerationQueueParams) (*ModerationQueueResult, error)
+	ModerateImage(ctx context.Context, id string, status model.ImageStatus, moderator model.User) (*model.Image, error)
 }
 
 type imageUseCase struct {
-	ir repository.ImageRepository
-	ic repository.ImagesCacheRepository
+	ir  repository.ImageRepository
+	ic  repository.ImagesCacheRepository
+	irr repository.ImageReportRepository
+	sr  repository.SpotRepository
+	bs  storage.BlobStore
+	sc  *config.StorageConfig
+	mc  *config.ModerationConfig
+	tc  *config.TwoFactorConfig
 }
 
-func NewImageUseCase(ir repository.ImageRepository, ic repository.ImagesCacheRepository) ImageUseCase {
+func NewImageUseCase(
+	ir repository.ImageRepository,
+	ic repository.ImagesCacheRepository,
+	irr repository.ImageReportRepository,
+	sr repository.SpotRepository,
+	bs storage.BlobStore,
+	sc *config.StorageConfig,
+	mc *config.ModerationConfig,
+	tc *config.TwoFactorConfig,
+) ImageUseCase {
 	return &imageUseCase{
-		ir: ir,
-		ic: ic,
+		ir:  ir,
+		ic:  ic,
+		irr: irr,
+		sr:  sr,
+		bs:  bs,
+		sc:  sc,
+		mc:  mc,
+		tc:  tc,
 	}
 }
 
-func (ih *imageUseCase) ListImages(ctx context.Context, spotID string) ([]model.Image, error) {
-	images, err := ih.ir.List(ctx, []repository.QueryCondition{{Field: "SpotID", Value: spotID}})
+type ListImagesParams struct {
+	SpotID  string
+	Limit   int
+	Cursor  string
+	OrderBy string
+}
+
+type ListImagesResult struct {
+	Images     []model.Image
+	NextCursor string
+	Total      int
+}
+
+func (ih *imageUseCase) ListImages(ctx context.Context, params *ListImagesParams) (*ListImagesResult, error) {
+	qcs := []repository.QueryCondition{
+		{Field: "spot_id", Value: params.SpotID},
+		{Field: "status", Value: string(model.ImageStatusApproved)},
+	}
+	orderBy := params.OrderBy
+	if orderBy == "" {
+		orderBy = DefaultImagesOrderBy
+	}
+
+	images, nextCursor, err := ih.ir.ListPage(ctx, qcs, repository.ListOptions{
+		Limit:   params.Limit,
+		Cursor:  params.Cursor,
+		OrderBy: orderBy,
+	})
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return nil, err
+	}
 	if err != nil {
-		log.Printf("Failed to get images of %v: %v", spotID, err)
-		images = ih.getMasterData(ctx, spotID)
-		return images, nil
+		log.Printf("Failed to get images of %v: %v", params.SpotID, err)
+		// 先頭ページのみキャッシュのマスターデータで代替する
+		if params.Cursor != "" {
+			return nil, err
+		}
+		images = ih.getMasterData(ctx, params.SpotID)
+		return &ListImagesResult{Images: images, Total: len(images)}, nil
 	}
-	if cacheErr := ih.setMasterData(ctx, spotID, images); cacheErr != nil {
-		log.Printf("Failed to set images data of %v: %v", spotID, cacheErr)
+
+	total, err := ih.ir.Count(ctx, qcs)
+	if err != nil {
+		log.Printf("Failed to count images of %v: %v", params.SpotID, err)
+		return nil, err
+	}
+
+	// 全件が1ページに収まっている場合のみ、マスターデータを更新する
+	if params.Cursor == "" && nextCursor == "" {
+		if cacheErr := ih.setMasterData(ctx, params.SpotID, images); cacheErr != nil {
+			log.Printf("Failed to set images data of %v: %v", params.SpotID, cacheErr)
+		}
 	}
-	return images, nil
+	return &ListImagesResult{Images: images, NextCursor: nextCursor, Total: total}, nil
 }
 
 func (ih *imageUseCase) CreateImage(ctx context.Context, spotID uuid.UUID, url string, user model.User) error {
@@ -48,6 +124,7 @@ func (ih *imageUseCase) CreateImage(ctx context.Context, spotID uuid.UUID, url s
 		SpotID: spotID,
 		UserID: user.ID,
 		URL:    url,
+		Status: ih.initialStatus(user),
 	}
 	if err := ih.ir.Create(ctx, img); err != nil {
 		log.Printf("Failed to create image: %v", err)
@@ -57,15 +134,26 @@ func (ih *imageUseCase) CreateImage(ctx context.Context, spotID uuid.UUID, url s
 }
 
 func (ih *imageUseCase) DeleteImage(ctx context.Context, id string, userID string, user model.User) error {
-	if !user.IsAdmin && user.ID.String() != userID {
+	img, err := ih.ir.Get(ctx, id)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		log.Printf("Failed to get image %v: %v", id, err)
+		return err
+	}
+	// userIDはリクエストの値のため、作成者は保存済みの画像で確認する
+	isOwner := img != nil && img.UserID == user.ID && user.ID.String() == userID
+	if !isOwner && !actsAsAdmin(ih.tc, user) {
 		log.Print("Don't have permission to delete images")
 		return fmt.Errorf("don't have permission to delete images")
 	}
 
-	if err := ih.ir.Delete(ctx, id); err != nil {
+	if err = ih.ir.Delete(ctx, id); err != nil {
 		log.Print("Internal server error while deleting image")
 		return err
 	}
+	// 画像の行は削除済みのため、ファイルの削除に失敗してもエラーにはしない
+	if img != nil {
+		ih.deleteBlobs(ctx, imageKeys(img))
+	}
 	return nil
 }
 
@@ -81,3 +169,11 @@ func (ih *imageUseCase) getMasterData(ctx context.Context, spotID string) []mode
 func (ih *imageUseCase) setMasterData(ctx context.Context, spotID string, images []model.Image) error {
 	return ih.ic.Set(ctx, "images_"+spotID, images)
 }
+
+// deleteMasterData は画像の状態が変わったスポットのマスターデータを削除し、非公開にした画像を代替の一覧に残さないようにします。
+// 画像は更新済みのため、失敗してもログに残すのみです。
+func (ih *imageUseCase) deleteMasterData(ctx context.Context, spotID string) {
+	if err := ih.ic.Delete(ctx, "images_"+spotID); err != nil {
+		log.Printf("Failed to delete images cache of %v: %v", spotID, err)
+	}
+}
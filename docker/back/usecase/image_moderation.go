@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+)
+
+// initialStatus は新しく投稿された画像の状態を返します。信頼できるロールのユーザの画像のみ、設定に応じてすぐに公開します。
+// TrustedRoleが存在しないロールの場合は、すべてのユーザの画像をモデレーション待ちにします。
+func (ih *imageUseCase) initialStatus(user model.User) model.ImageStatus {
+	trusted := model.Role(ih.mc.TrustedRole)
+	if ih.mc.AutoApproveTrusted && trusted.IsValid() && user.HasRole(trusted) {
+		return model.ImageStatusApproved
+	}
+	return model.ImageStatusPending
+}
+
+// ReportImage は公開中の画像を通報します。通報の数がReportThresholdに達した画像は、モデレーション待ちに戻して非公開にします。
+func (ih *imageUseCase) ReportImage(ctx context.Context, id string, reason string, user model.User) error {
+	img, err := ih.getImage(ctx, id)
+	if err != nil {
+		return err
+	}
+	if img.Status != model.ImageStatusApproved {
+		return ErrImageNotFound
+	}
+
+	reported, err := ih.irr.Count(ctx, []repository.QueryCondition{
+		{Field: "image_id", Value: id},
+		{Field: "user_id", Value: user.ID.String()},
+	})
+	if err != nil {
+		log.Printf("Failed to count reports of image %v: %v", id, err)
+		return err
+	}
+	if reported > 0 {
+		return ErrAlreadyReported
+	}
+	if err = ih.irr.Create(ctx, model.ImageReport{
+		ID:      uuid.New(),
+		ImageID: img.ID,
+		UserID:  user.ID,
+		Reason:  reason,
+	}); err != nil {
+		log.Printf("Failed to create report of image %v: %v", id, err)
+		return err
+	}
+
+	if ih.mc.ReportThreshold <= 0 {
+		return nil
+	}
+	reports, err := ih.irr.Count(ctx, []repository.QueryCondition{{Field: "image_id", Value: id}})
+	if err != nil {
+		log.Printf("Failed to count reports of image %v: %v", id, err)
+		return err
+	}
+	if reports < ih.mc.ReportThreshold {
+		return nil
+	}
+	img.Status = model.ImageStatusPending
+	if err = ih.ir.Update(ctx, id, *img); err != nil {
+		log.Printf("Failed to update image %v: %v", id, err)
+		return err
+	}
+	ih.deleteMasterData(ctx, img.SpotID.String())
+	log.Printf("Image %v was returned to the moderation queue after %d reports", id, reports)
+	return nil
+}
+
+// ListModerationQueueParams はモデレーションの一覧の条件です。Statusを省略した場合はモデレーション待ちの画像を返します。
+type ListModerationQueueParams struct {
+	Status model.ImageStatus
+	Limit  int
+	Cursor string
+}
+
+// ModerationQueueItem はモデレーションの対象の画像と、その画像への通報です。
+type ModerationQueueItem struct {
+	Image   model.Image
+	Reports []model.ImageReport
+}
+
+type ModerationQueueResult struct {
+	Items      []ModerationQueueItem
+	NextCursor string
+	Total      int
+}
+
+// ListModerationQueue は指定した状態の画像を古い順に、通報とあわせて返します。
+func (ih *imageUseCase) ListModerationQueue(
+	ctx context.Context, params *ListModerationQueueParams,
+) (*ModerationQueueResult, error) {
+	status := params.Status
+	if status == "" {
+		status = model.ImageStatusPending
+	}
+	if !status.IsValid() {
+		return nil, ErrInvalidImageStatus
+	}
+	qcs := []repository.QueryCondition{{Field: "status", Value: string(status)}}
+
+	images, nextCursor, err := ih.ir.ListPage(ctx, qcs, repository.ListOptions{
+		Limit:   params.Limit,
+		Cursor:  params.Cursor,
+		OrderBy: "created",
+	})
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return nil, err
+	}
+	if err != nil {
+		log.Printf("Failed to list %v images: %v", status, err)
+		return nil, err
+	}
+	total, err := ih.ir.Count(ctx, qcs)
+	if err != nil {
+		log.Printf("Failed to count %v images: %v", status, err)
+		return nil, err
+	}
+
+	reports, err := ih.listReports(ctx, images)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]ModerationQueueItem, 0, len(images))
+	for _, img := range images {
+		items = append(items, ModerationQueueItem{Image: img, Reports: reports[img.ID]})
+	}
+	return &ModerationQueueResult{Items: items, NextCursor: nextCursor, Total: total}, nil
+}
+
+// listReports はimagesへの通報を1回のクエリで取得し、画像ごとにまとめて返します。
+func (ih *imageUseCase) listReports(
+	ctx context.Context, images []model.Image,
+) (map[uuid.UUID][]model.ImageReport, error) {
+	if len(images) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, 0, len(images))
+	for _, img := range images {
+		ids = append(ids, img.ID.String())
+	}
+	reports, err := ih.irr.List(ctx, []repository.QueryCondition{
+		{Field: "image_id", Operator: repository.OpIn, Value: ids},
+	})
+	if err != nil {
+		log.Printf("Failed to list image reports: %v", err)
+		return nil, err
+	}
+	byImage := make(map[uuid.UUID][]model.ImageReport, len(images))
+	for _, report := range reports {
+		byImage[report.ImageID] = append(byImage[report.ImageID], report)
+	}
+	return byImage, nil
+}
+
+// ModerateImage は画像を承認または却下します。承認した場合は、再び通報を数え直せるようそれまでの通報を削除します。
+// 却下した画像も、判断を取り消せるようファイルと通報は残しておきます。
+func (ih *imageUseCase) ModerateImage(
+	ctx context.Context, id string, status model.ImageStatus, moderator model.User,
+) (*model.Image, error) {
+	if status != model.ImageStatusApproved && status != model.ImageStatusRejected {
+		return nil, ErrInvalidImageStatus
+	}
+	img, err := ih.getImage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	img.Status = status
+	if err = ih.ir.Update(ctx, id, *img); err != nil {
+		log.Printf("Failed to update image %v: %v", id, err)
+		return nil, err
+	}
+	ih.deleteMasterData(ctx, img.SpotID.String())
+	if status == model.ImageStatusApproved {
+		if err = ih.irr.DeleteByImageID(ctx, id); err != nil {
+			log.Printf("Failed to delete reports of image %v: %v", id, err)
+		}
+	}
+	log.Printf("Image %v was %v by %v", id, status, moderator.ID)
+	return img, nil
+}
+
+func (ih *imageUseCase) getImage(ctx context.Context, id string) (*model.Image, error) {
+	img, err := ih.ir.Get(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrImageNotFound
+	}
+	if err != nil {
+		log.Printf("Failed to get image %v: %v", id, err)
+		return nil, err
+	}
+	return img, nil
+}
@@ -0,0 +1,329 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/config"
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository/mock"
+	storagemock "github.com/tusmasoma/campfinder/docker/back/internal/storage/mock"
+)
+
+var testModerationConfig = config.ModerationConfig{
+	AutoApproveTrusted: true,
+	TrustedRole:        string(model.RoleContributor),
+	ReportThreshold:    2,
+}
+
+func TestImageUseCase_initialStatus(t *testing.T) {
+	t.Parallel()
+	patterns := []struct {
+		name string
+		conf config.ModerationConfig
+		user model.User
+		want model.ImageStatus
+	}{
+		{
+			name: "user is queued",
+			conf: testModerationConfig,
+			user: model.User{Role: model.RoleUser},
+			want: model.ImageStatusPending,
+		},
+		{
+			name: "trusted role is approved",
+			conf: testModerationConfig,
+			user: model.User{Role: model.RoleContributor},
+			want: model.ImageStatusApproved,
+		},
+		{
+			name: "admin is approved",
+			conf: testModerationConfig,
+			user: model.User{IsAdmin: true},
+			want: model.ImageStatusApproved,
+		},
+		{
+			name: "auto approval disabled",
+			conf: config.ModerationConfig{AutoApproveTrusted: false, TrustedRole: string(model.RoleContributor)},
+			user: model.User{IsAdmin: true},
+			want: model.ImageStatusPending,
+		},
+		{
+			name: "unknown trusted role",
+			conf: config.ModerationConfig{AutoApproveTrusted: true, TrustedRole: "owner"},
+			user: model.User{Role: model.RoleModerator},
+			want: model.ImageStatusPending,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ih := &imageUseCase{mc: &tt.conf}
+			if got := ih.initialStatus(tt.user); got != tt.want {
+				t.Errorf("initialStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImageUseCase_ReportImage(t *testing.T) {
+	t.Parallel()
+	id := "31894386-3e60-45a8-bc67-f46b72b42554"
+	user := model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")}
+	spotID := uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052")
+	approved := model.Image{ID: uuid.MustParse(id), Status: model.ImageStatusApproved}
+	byUser := []repository.QueryCondition{
+		{Field: "image_id", Value: id},
+		{Field: "user_id", Value: user.ID.String()},
+	}
+	byImage := []repository.QueryCondition{{Field: "image_id", Value: id}}
+
+	patterns := []struct {
+		name    string
+		setup   func(m *mock.MockImageRepository, m1 *mock.MockImageReportRepository, m2 *mock.MockImagesCacheRepository)
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockImageRepository, m1 *mock.MockImageReportRepository, _ *mock.MockImagesCacheRepository) {
+				m.EXPECT().Get(gomock.Any(), id).Return(&approved, nil)
+				m1.EXPECT().Count(gomock.Any(), byUser).Return(0, nil)
+				m1.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, report model.ImageReport) error {
+						if report.ImageID != approved.ID || report.UserID != user.ID || report.Reason != "spam" {
+							t.Errorf("unexpected report: %+v", report)
+						}
+						return nil
+					},
+				)
+				m1.EXPECT().Count(gomock.Any(), byImage).Return(1, nil)
+			},
+		},
+		{
+			name: "success: threshold returns image to queue",
+			setup: func(m *mock.MockImageRepository, m1 *mock.MockImageReportRepository, m2 *mock.MockImagesCacheRepository) {
+				m.EXPECT().Get(gomock.Any(), id).Return(
+					&model.Image{ID: approved.ID, SpotID: spotID, Status: model.ImageStatusApproved}, nil,
+				)
+				m1.EXPECT().Count(gomock.Any(), byUser).Return(0, nil)
+				m1.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+				m1.EXPECT().Count(gomock.Any(), byImage).Return(2, nil)
+				m.EXPECT().Update(
+					gomock.Any(), id, model.Image{ID: approved.ID, SpotID: spotID, Status: model.ImageStatusPending},
+				).Return(nil)
+				// 非公開にした画像がキャッシュの一覧に残らないよう削除する
+				m2.EXPECT().Delete(gomock.Any(), "images_"+spotID.String()).Return(nil)
+			},
+		},
+		{
+			name: "Fail: already reported",
+			setup: func(m *mock.MockImageRepository, m1 *mock.MockImageReportRepository, _ *mock.MockImagesCacheRepository) {
+				m.EXPECT().Get(gomock.Any(), id).Return(&approved, nil)
+				m1.EXPECT().Count(gomock.Any(), byUser).Return(1, nil)
+			},
+			wantErr: ErrAlreadyReported,
+		},
+		{
+			name: "Fail: not public",
+			setup: func(m *mock.MockImageRepository, _ *mock.MockImageReportRepository, _ *mock.MockImagesCacheRepository) {
+				m.EXPECT().Get(gomock.Any(), id).Return(&model.Image{ID: approved.ID, Status: model.ImageStatusRejected}, nil)
+			},
+			wantErr: ErrImageNotFound,
+		},
+		{
+			name: "Fail: not found",
+			setup: func(m *mock.MockImageRepository, _ *mock.MockImageReportRepository, _ *mock.MockImagesCacheRepository) {
+				m.EXPECT().Get(gomock.Any(), id).Return(nil, repository.ErrNotFound)
+			},
+			wantErr: ErrImageNotFound,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			ir := mock.NewMockImageRepository(ctrl)
+			ic := mock.NewMockImagesCacheRepository(ctrl)
+			irr := mock.NewMockImageReportRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ir, irr, ic)
+			}
+
+			usecase := NewImageUseCase(
+				ir, ic, irr, mock.NewMockSpotRepository(ctrl), storagemock.NewMockBlobStore(ctrl),
+				&testStorageConfig, &testModerationConfig, testTwoFactorConfig,
+			)
+			if err := usecase.ReportImage(context.Background(), id, "spam", user); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ReportImage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestImageUseCase_ListModerationQueue(t *testing.T) {
+	t.Parallel()
+	img1 := model.Image{ID: uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"), Status: model.ImageStatusPending}
+	img2 := model.Image{ID: uuid.MustParse("5ba6a9f5-4b3c-4e47-8d5e-0c3c1f0d6a2e"), Status: model.ImageStatusPending}
+	report := model.ImageReport{ID: uuid.New(), ImageID: img2.ID, Reason: "spam"}
+	pending := []repository.QueryCondition{{Field: "status", Value: "pending"}}
+
+	patterns := []struct {
+		name    string
+		params  *ListModerationQueueParams
+		setup   func(m *mock.MockImageRepository, m1 *mock.MockImageReportRepository)
+		want    *ModerationQueueResult
+		wantErr error
+	}{
+		{
+			name:   "success",
+			params: &ListModerationQueueParams{Limit: 2},
+			setup: func(m *mock.MockImageRepository, m1 *mock.MockImageReportRepository) {
+				m.EXPECT().ListPage(gomock.Any(), pending, repository.ListOptions{Limit: 2, OrderBy: "created"}).Return(
+					[]model.Image{img1, img2}, "next", nil,
+				)
+				m.EXPECT().Count(gomock.Any(), pending).Return(3, nil)
+				m1.EXPECT().List(gomock.Any(), []repository.QueryCondition{
+					{Field: "image_id", Operator: repository.OpIn, Value: []string{img1.ID.String(), img2.ID.String()}},
+				}).Return([]model.ImageReport{report}, nil)
+			},
+			want: &ModerationQueueResult{
+				Items: []ModerationQueueItem{
+					{Image: img1},
+					{Image: img2, Reports: []model.ImageReport{report}},
+				},
+				NextCursor: "next",
+				Total:      3,
+			},
+		},
+		{
+			name:   "success: empty",
+			params: &ListModerationQueueParams{Status: model.ImageStatusRejected, Limit: 2},
+			setup: func(m *mock.MockImageRepository, _ *mock.MockImageReportRepository) {
+				rejected := []repository.QueryCondition{{Field: "status", Value: "rejected"}}
+				m.EXPECT().ListPage(gomock.Any(), rejected, repository.ListOptions{Limit: 2, OrderBy: "created"}).Return(
+					nil, "", nil,
+				)
+				m.EXPECT().Count(gomock.Any(), rejected).Return(0, nil)
+			},
+			want: &ModerationQueueResult{Items: []ModerationQueueItem{}},
+		},
+		{
+			name:    "Fail: invalid status",
+			params:  &ListModerationQueueParams{Status: "deleted"},
+			wantErr: ErrInvalidImageStatus,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			ir := mock.NewMockImageRepository(ctrl)
+			irr := mock.NewMockImageReportRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ir, irr)
+			}
+
+			usecase := NewImageUseCase(
+				ir, mock.NewMockImagesCacheRepository(ctrl), irr, mock.NewMockSpotRepository(ctrl),
+				storagemock.NewMockBlobStore(ctrl),
+				&testStorageConfig, &testModerationConfig, testTwoFactorConfig,
+			)
+			got, err := usecase.ListModerationQueue(context.Background(), tt.params)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ListModerationQueue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListModerationQueue() \n got = %+v,\n want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImageUseCase_ModerateImage(t *testing.T) {
+	t.Parallel()
+	id := "31894386-3e60-45a8-bc67-f46b72b42554"
+	spotID := uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052")
+	moderator := model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"), Role: model.RoleModerator}
+
+	patterns := []struct {
+		name    string
+		status  model.ImageStatus
+		setup   func(m *mock.MockImageRepository, m1 *mock.MockImageReportRepository, m2 *mock.MockImagesCacheRepository)
+		wantErr error
+	}{
+		{
+			name:   "success: approve clears reports",
+			status: model.ImageStatusApproved,
+			setup: func(m *mock.MockImageRepository, m1 *mock.MockImageReportRepository, m2 *mock.MockImagesCacheRepository) {
+				m.EXPECT().Get(gomock.Any(), id).Return(&model.Image{SpotID: spotID, Status: model.ImageStatusPending}, nil)
+				m.EXPECT().Update(gomock.Any(), id, model.Image{SpotID: spotID, Status: model.ImageStatusApproved}).Return(nil)
+				m2.EXPECT().Delete(gomock.Any(), "images_"+spotID.String()).Return(nil)
+				m1.EXPECT().DeleteByImageID(gomock.Any(), id).Return(nil)
+			},
+		},
+		{
+			name:   "success: reject",
+			status: model.ImageStatusRejected,
+			setup: func(m *mock.MockImageRepository, _ *mock.MockImageReportRepository, m2 *mock.MockImagesCacheRepository) {
+				m.EXPECT().Get(gomock.Any(), id).Return(&model.Image{SpotID: spotID, Status: model.ImageStatusApproved}, nil)
+				m.EXPECT().Update(gomock.Any(), id, model.Image{SpotID: spotID, Status: model.ImageStatusRejected}).Return(nil)
+				// 却下した画像がキャッシュの一覧に残らないよう削除する。削除に失敗しても却下は成功する
+				m2.EXPECT().Delete(gomock.Any(), "images_"+spotID.String()).Return(errors.New("cache error"))
+			},
+		},
+		{
+			name:    "Fail: invalid status",
+			status:  model.ImageStatusPending,
+			wantErr: ErrInvalidImageStatus,
+		},
+		{
+			name:   "Fail: not found",
+			status: model.ImageStatusApproved,
+			setup: func(m *mock.MockImageRepository, _ *mock.MockImageReportRepository, _ *mock.MockImagesCacheRepository) {
+				m.EXPECT().Get(gomock.Any(), id).Return(nil, repository.ErrNotFound)
+			},
+			wantErr: ErrImageNotFound,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			ir := mock.NewMockImageRepository(ctrl)
+			ic := mock.NewMockImagesCacheRepository(ctrl)
+			irr := mock.NewMockImageReportRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ir, irr, ic)
+			}
+
+			usecase := NewImageUseCase(
+				ir, ic, irr, mock.NewMockSpotRepository(ctrl), storagemock.NewMockBlobStore(ctrl),
+				&testStorageConfig, &testModerationConfig, testTwoFactorConfig,
+			)
+			img, err := usecase.ModerateImage(context.Background(), id, tt.status, moderator)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ModerateImage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && img.Status != tt.status {
+				t.Errorf("ModerateImage() status = %v, want %v", img.Status, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+	"github.com/tusmasoma/campfinder/docker/back/internal/imaging"
+	"github.com/tusmasoma/campfinder/docker/back/internal/storage"
+)
+
+// pendingUploadPrefix は直接アップロードされ、まだ検査していないファイルのキーの接頭辞です。
+// 完了されずに残ったファイルは、ストレージのライフサイクルルールでこの接頭辞ごと期限切れにしてください。
+const pendingUploadPrefix = "uploads/"
+
+// UploadURL はクライアントがサーバを経由せずに画像をPUTするための署名付きURLです。
+// PUTするときはContentTypeをContent-Typeヘッダに指定し、完了したらKeyをCompleteUploadに渡します。
+type UploadURL struct {
+	Key         string
+	URL         string
+	ContentType string
+	ExpiresIn   time.Duration
+}
+
+// CreateUploadURL はspotIDの画像を直接アップロードするための署名付きURLを発行します。
+// キーにユーザとSpotを含めておき、CompleteUploadで他のユーザのファイルを登録できないようにします。
+// 存在しないSpotでは登録できずにファイルが残るため、URLを発行しません。
+func (ih *imageUseCase) CreateUploadURL(
+	ctx context.Context, spotID uuid.UUID, contentType string, user model.User,
+) (*UploadURL, error) {
+	ext := imaging.Extension(contentType)
+	if ext == "" {
+		return nil, ErrUnsupportedImageType
+	}
+	spots, err := ih.sr.Count(ctx, []repository.QueryCondition{{Field: "id", Value: spotID.String()}})
+	if err != nil {
+		log.Printf("Failed to get spot %v: %v", spotID, err)
+		return nil, err
+	}
+	if spots == 0 {
+		return nil, ErrSpotNotFound
+	}
+
+	key := pendingUploadPrefix + user.ID.String() + "/" + spotID.String() + "/" + uuid.New().String() + ext
+	url, err := ih.bs.PresignPut(key, contentType, ih.sc.PresignExpiry)
+	if errors.Is(err, storage.ErrPresignNotSupported) {
+		return nil, ErrDirectUploadUnavailable
+	}
+	if err != nil {
+		log.Printf("Failed to presign upload url: %v", err)
+		return nil, err
+	}
+	return &UploadURL{Key: key, URL: url, ContentType: contentType, ExpiresIn: ih.sc.PresignExpiry}, nil
+}
+
+// CompleteUpload は直接アップロードされたファイルが存在することを確認し、UploadImageと同じくサイズと形式を検査して、
+// 位置情報を取り除いた画像と縮小版を保存してImageを登録します。アップロードされたファイル自体は削除します。
+func (ih *imageUseCase) CompleteUpload(ctx context.Context, key string, user model.User) (*model.Image, error) {
+	spotID, ok := parsePendingUploadKey(key, user.ID)
+	if !ok {
+		return nil, ErrUploadNotFound
+	}
+
+	rc, err := ih.bs.Get(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrUploadNotFound
+	}
+	if err != nil {
+		log.Printf("Failed to get uploaded file %v: %v", key, err)
+		return nil, err
+	}
+	// 上限を1バイト超えて読めた場合はUploadImageがErrImageTooLargeを返す
+	data, err := io.ReadAll(io.LimitReader(rc, ih.sc.MaxUploadSize+1))
+	rc.Close()
+	if err != nil {
+		log.Printf("Failed to read uploaded file %v: %v", key, err)
+		return nil, err
+	}
+
+	img, err := ih.UploadImage(ctx, spotID, data, user)
+	// 検査で不合格になったファイルはやり直してもそのままでは登録できないため、成功した場合と同じく削除する。
+	// それ以外のエラーでは、もう一度完了できるよう残しておく
+	if err == nil || errors.Is(err, ErrImageTooLarge) || errors.Is(err, ErrUnsupportedImageType) ||
+		errors.Is(err, ErrInvalidImage) {
+		ih.deleteBlobs(ctx, []string{key})
+	}
+	return img, err
+}
+
+// parsePendingUploadKey は"uploads/<userID>/<spotID>/<id>.jpg"のキーがuserIDのものかを確認し、spotIDを返します。
+func parsePendingUploadKey(key string, userID uuid.UUID) (uuid.UUID, bool) {
+	parts := strings.Split(strings.TrimPrefix(key, pendingUploadPrefix), "/")
+	if !strings.HasPrefix(key, pendingUploadPrefix) || len(parts) != 3 || parts[0] != userID.String() {
+		return uuid.Nil, false
+	}
+	spotID, err := uuid.Parse(parts[1])
+	if err != nil {
+		return uuid.Nil, false
+	}
+	ext := path.Ext(parts[2])
+	if ext != imaging.Extension(imaging.ContentTypeJPEG) && ext != imaging.Extension(imaging.ContentTypePNG) {
+		return uuid.Nil, false
+	}
+	if _, err = uuid.Parse(strings.TrimSuffix(parts[2], ext)); err != nil {
+		return uuid.Nil, false
+	}
+	return spotID, true
+}
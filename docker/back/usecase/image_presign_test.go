@@ -0,0 +1,221 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository/mock"
+	"github.com/tusmasoma/campfinder/docker/back/internal/storage"
+	storagemock "github.com/tusmasoma/campfinder/docker/back/internal/storage/mock"
+)
+
+func TestImageUseCase_CreateUploadURL(t *testing.T) {
+	t.Parallel()
+	spotID := uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052")
+	user := model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")}
+	conf := testStorageConfig
+	conf.PresignExpiry = 15 * time.Minute
+	keyPrefix := "uploads/" + user.ID.String() + "/" + spotID.String() + "/"
+	bySpot := []repository.QueryCondition{{Field: "id", Value: spotID.String()}}
+
+	patterns := []struct {
+		name        string
+		contentType string
+		setup       func(sr *mock.MockSpotRepository, bs *storagemock.MockBlobStore)
+		wantErr     error
+	}{
+		{
+			name:        "success",
+			contentType: "image/png",
+			setup: func(sr *mock.MockSpotRepository, bs *storagemock.MockBlobStore) {
+				sr.EXPECT().Count(gomock.Any(), bySpot).Return(1, nil)
+				bs.EXPECT().PresignPut(gomock.Any(), "image/png", 15*time.Minute).DoAndReturn(
+					func(key, _ string, _ time.Duration) (string, error) {
+						if !strings.HasPrefix(key, keyPrefix) || !strings.HasSuffix(key, ".png") {
+							return "", fmt.Errorf("unexpected key: %v", key)
+						}
+						return "http://minio:9000/campfinder/" + key + "?X-Amz-Signature=abc", nil
+					},
+				)
+			},
+		},
+		{
+			name:        "Fail: unsupported type",
+			contentType: "image/gif",
+			wantErr:     ErrUnsupportedImageType,
+		},
+		{
+			name:        "Fail: spot not found",
+			contentType: "image/jpeg",
+			setup: func(sr *mock.MockSpotRepository, _ *storagemock.MockBlobStore) {
+				// 存在しないSpotの画像は登録できずにファイルが残るため、URLを発行しない
+				sr.EXPECT().Count(gomock.Any(), bySpot).Return(0, nil)
+			},
+			wantErr: ErrSpotNotFound,
+		},
+		{
+			name:        "Fail: local storage",
+			contentType: "image/jpeg",
+			setup: func(sr *mock.MockSpotRepository, bs *storagemock.MockBlobStore) {
+				sr.EXPECT().Count(gomock.Any(), bySpot).Return(1, nil)
+				bs.EXPECT().PresignPut(gomock.Any(), "image/jpeg", 15*time.Minute).Return("", storage.ErrPresignNotSupported)
+			},
+			wantErr: ErrDirectUploadUnavailable,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			sr := mock.NewMockSpotRepository(ctrl)
+			bs := storagemock.NewMockBlobStore(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(sr, bs)
+			}
+
+			usecase := NewImageUseCase(
+				mock.NewMockImageRepository(ctrl), mock.NewMockImagesCacheRepository(ctrl),
+				mock.NewMockImageReportRepository(ctrl), sr, bs, &conf, &testModerationConfig, testTwoFactorConfig,
+			)
+			got, err := usecase.CreateUploadURL(context.Background(), spotID, tt.contentType, user)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateUploadURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !strings.HasPrefix(got.URL, "http://minio:9000/campfinder/"+got.Key) || got.ExpiresIn != 15*time.Minute ||
+				got.ContentType != tt.contentType {
+				t.Errorf("CreateUploadURL() = %+v", got)
+			}
+		})
+	}
+}
+
+func TestImageUseCase_CompleteUpload(t *testing.T) {
+	t.Parallel()
+	spotID := uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052")
+	user := model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")}
+	key := "uploads/" + user.ID.String() + "/" + spotID.String() + "/31894386-3e60-45a8-bc67-f46b72b42554.jpg"
+	jpg := testJPEG(t)
+
+	patterns := []struct {
+		name    string
+		key     string
+		setup   func(m *mock.MockImageRepository, bs *storagemock.MockBlobStore)
+		wantErr error
+	}{
+		{
+			name: "success",
+			key:  key,
+			setup: func(m *mock.MockImageRepository, bs *storagemock.MockBlobStore) {
+				bs.EXPECT().Get(gomock.Any(), key).Return(io.NopCloser(bytes.NewReader(jpg)), nil)
+				bs.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), "image/jpeg").Return(nil).Times(4)
+				bs.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), "image/webp").Return(nil).Times(3)
+				bs.EXPECT().URL(gomock.Any()).Return("http://minio:9000/campfinder/spots/x.jpg").Times(7)
+				m.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, img model.Image) error {
+					if img.SpotID != spotID || img.UserID != user.ID || !strings.HasPrefix(img.StorageKey, "spots/") {
+						return fmt.Errorf("unexpected image: %+v", img)
+					}
+					return nil
+				})
+				bs.EXPECT().Delete(gomock.Any(), key).Return(nil)
+			},
+		},
+		{
+			name:    "Fail: other user's upload",
+			key:     "uploads/00000000-0000-0000-0000-000000000001/" + spotID.String() + "/31894386-3e60-45a8-bc67-f46b72b42554.jpg",
+			wantErr: ErrUploadNotFound,
+		},
+		{
+			name:    "Fail: not a pending upload",
+			key:     "spots/" + spotID.String() + "/31894386-3e60-45a8-bc67-f46b72b42554.jpg",
+			wantErr: ErrUploadNotFound,
+		},
+		{
+			name: "Fail: not uploaded",
+			key:  key,
+			setup: func(_ *mock.MockImageRepository, bs *storagemock.MockBlobStore) {
+				bs.EXPECT().Get(gomock.Any(), key).Return(nil, storage.ErrNotFound)
+			},
+			wantErr: ErrUploadNotFound,
+		},
+		{
+			name: "Fail: too large removes upload",
+			key:  key,
+			setup: func(_ *mock.MockImageRepository, bs *storagemock.MockBlobStore) {
+				bs.EXPECT().Get(gomock.Any(), key).Return(
+					io.NopCloser(bytes.NewReader(make([]byte, testStorageConfig.MaxUploadSize*2))), nil,
+				)
+				bs.EXPECT().Delete(gomock.Any(), key).Return(nil)
+			},
+			wantErr: ErrImageTooLarge,
+		},
+		{
+			name: "Fail: not an image removes upload",
+			key:  key,
+			setup: func(_ *mock.MockImageRepository, bs *storagemock.MockBlobStore) {
+				bs.EXPECT().Get(gomock.Any(), key).Return(io.NopCloser(strings.NewReader("<html></html>")), nil)
+				bs.EXPECT().Delete(gomock.Any(), key).Return(nil)
+			},
+			wantErr: ErrUnsupportedImageType,
+		},
+		{
+			name: "Fail: db error keeps upload",
+			key:  key,
+			setup: func(m *mock.MockImageRepository, bs *storagemock.MockBlobStore) {
+				bs.EXPECT().Get(gomock.Any(), key).Return(io.NopCloser(bytes.NewReader(jpg)), nil)
+				bs.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), "image/jpeg").Return(nil).Times(4)
+				bs.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), "image/webp").Return(nil).Times(3)
+				bs.EXPECT().URL(gomock.Any()).Return("http://minio:9000/campfinder/spots/x.jpg").Times(7)
+				m.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errDBFailure)
+				// 保存した画像とJPEG・WebPの縮小版の7つだけを削除する
+				bs.EXPECT().Delete(gomock.Any(), gomock.Not(key)).Return(nil).Times(7)
+			},
+			wantErr: errDBFailure,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			ir := mock.NewMockImageRepository(ctrl)
+			bs := storagemock.NewMockBlobStore(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ir, bs)
+			}
+
+			usecase := NewImageUseCase(
+				ir, mock.NewMockImagesCacheRepository(ctrl), mock.NewMockImageReportRepository(ctrl),
+				mock.NewMockSpotRepository(ctrl), bs,
+				&testStorageConfig, &testModerationConfig, testTwoFactorConfig,
+			)
+			img, err := usecase.CompleteUpload(context.Background(), tt.key, user)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CompleteUpload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && img.SpotID != spotID {
+				t.Errorf("CompleteUpload() = %+v", img)
+			}
+		})
+	}
+}
+
+var errDBFailure = errors.New("db error")
@@ -13,6 +13,7 @@ import (
 	"github.com/tusmasoma/campfinder/docker/back/domain/model"
 	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
 	"github.com/tusmasoma/campfinder/docker/back/domain/repository/mock"
+	storagemock "github.com/tusmasoma/campfinder/docker/back/internal/storage/mock"
 )
 
 type ImageCreateArg struct {
@@ -43,82 +44,136 @@ func TestImageUseCase_ListImages(t *testing.T) {
 			Created: created,
 		},
 	}
+	spotID := "fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"
+	qcs := []repository.QueryCondition{
+		{Field: "spot_id", Value: spotID},
+		{Field: "status", Value: string(model.ImageStatusApproved)},
+	}
+
 	patterns := []struct {
 		name  string
 		setup func(
 			m *mock.MockImageRepository,
 			m1 *mock.MockImagesCacheRepository,
 		)
-		arg struct {
-			ctx    context.Context
-			spotID string
-		}
-		want struct {
-			images []model.Image
+		params *ListImagesParams
+		want   struct {
+			result *ListImagesResult
 			err    error
 		}
 	}{
 		{
 			name: "success",
 			setup: func(m *mock.MockImageRepository, m1 *mock.MockImagesCacheRepository) {
-				m.EXPECT().List(
+				m.EXPECT().ListPage(
 					gomock.Any(),
-					[]repository.QueryCondition{{Field: "SpotID", Value: "fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"}},
+					qcs,
+					repository.ListOptions{Limit: 50, OrderBy: DefaultImagesOrderBy},
 				).Return(
-					images, nil,
+					[]model.Image(images), "", nil,
 				)
+				m.EXPECT().Count(gomock.Any(), qcs).Return(1, nil)
 				m1.EXPECT().Set(
 					gomock.Any(),
 					"images_fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052",
-					images,
+					[]model.Image(images),
 				).Return(nil)
 			},
-			arg: struct {
-				ctx    context.Context
-				spotID string
+			params: &ListImagesParams{SpotID: spotID, Limit: 50},
+			want: struct {
+				result *ListImagesResult
+				err    error
 			}{
-				ctx:    context.Background(),
-				spotID: "fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052",
+				result: &ListImagesResult{Images: images, Total: 1},
+				err:    nil,
 			},
+		},
+		{
+			name: "success: has next page",
+			setup: func(m *mock.MockImageRepository, m1 *mock.MockImagesCacheRepository) {
+				m.EXPECT().ListPage(
+					gomock.Any(),
+					qcs,
+					repository.ListOptions{Limit: 1, Cursor: "cursor1", OrderBy: "created"},
+				).Return(
+					[]model.Image(images), "cursor2", nil,
+				)
+				m.EXPECT().Count(gomock.Any(), qcs).Return(3, nil)
+			},
+			params: &ListImagesParams{SpotID: spotID, Limit: 1, Cursor: "cursor1", OrderBy: "created"},
 			want: struct {
-				images []model.Image
+				result *ListImagesResult
 				err    error
 			}{
-				images: images,
+				result: &ListImagesResult{Images: images, NextCursor: "cursor2", Total: 3},
 				err:    nil,
 			},
 		},
 		{
 			name: "success: fail to get images from db, but success to get images from masterdata",
 			setup: func(m *mock.MockImageRepository, m1 *mock.MockImagesCacheRepository) {
-				m.EXPECT().List(
+				m.EXPECT().ListPage(
 					gomock.Any(),
-					[]repository.QueryCondition{{Field: "SpotID", Value: "fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"}},
+					qcs,
+					repository.ListOptions{Limit: 50, OrderBy: DefaultImagesOrderBy},
 				).Return(
-					nil, fmt.Errorf("fail to get images from db"),
+					nil, "", fmt.Errorf("fail to get images from db"),
 				)
 				m1.EXPECT().Get(
 					gomock.Any(),
 					"images_fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052",
 				).Return(&images, nil)
 			},
-			arg: struct {
-				ctx    context.Context
-				spotID string
+			params: &ListImagesParams{SpotID: spotID, Limit: 50},
+			want: struct {
+				result *ListImagesResult
+				err    error
 			}{
-				ctx:    context.Background(),
-				spotID: "fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052",
+				result: &ListImagesResult{Images: images, Total: 1},
+				err:    nil,
+			},
+		},
+		{
+			name: "Fail: fail to get next page from db",
+			setup: func(m *mock.MockImageRepository, m1 *mock.MockImagesCacheRepository) {
+				m.EXPECT().ListPage(
+					gomock.Any(),
+					qcs,
+					repository.ListOptions{Limit: 50, Cursor: "cursor1", OrderBy: DefaultImagesOrderBy},
+				).Return(
+					nil, "", fmt.Errorf("fail to get images from db"),
+				)
 			},
+			params: &ListImagesParams{SpotID: spotID, Limit: 50, Cursor: "cursor1"},
 			want: struct {
-				images []model.Image
+				result *ListImagesResult
 				err    error
 			}{
-				images: images,
-				err:    nil,
+				result: nil,
+				err:    fmt.Errorf("fail to get images from db"),
+			},
+		},
+		{
+			name: "Fail: invalid cursor",
+			setup: func(m *mock.MockImageRepository, m1 *mock.MockImagesCacheRepository) {
+				m.EXPECT().ListPage(
+					gomock.Any(),
+					qcs,
+					repository.ListOptions{Limit: 50, Cursor: "invalid", OrderBy: DefaultImagesOrderBy},
+				).Return(
+					nil, "", repository.ErrInvalidCursor,
+				)
+			},
+			params: &ListImagesParams{SpotID: spotID, Limit: 50, Cursor: "invalid"},
+			want: struct {
+				result *ListImagesResult
+				err    error
+			}{
+				result: nil,
+				err:    repository.ErrInvalidCursor,
 			},
 		},
 	}
-
 	for _, tt := range patterns {
 		t.Run(tt.name, func(t *testing.T) {
 			tt := tt
@@ -131,18 +186,21 @@ func TestImageUseCase_ListImages(t *testing.T) {
 				tt.setup(ir, ic)
 			}
 
-			usecase := NewImageUseCase(ir, ic)
+			usecase := NewImageUseCase(
+				ir, ic, mock.NewMockImageReportRepository(ctrl), mock.NewMockSpotRepository(ctrl),
+				storagemock.NewMockBlobStore(ctrl),
+				&testStorageConfig, &testModerationConfig, testTwoFactorConfig,
+			)
 
-			getImages, err := usecase.ListImages(tt.arg.ctx, tt.arg.spotID)
+			result, err := usecase.ListImages(context.Background(), tt.params)
 
 			if (err != nil) != (tt.want.err != nil) {
 				t.Errorf("ListImages() error = %v, wantErr %v", err, tt.want.err)
 			} else if err != nil && tt.want.err != nil && err.Error() != tt.want.err.Error() {
 				t.Errorf("ListImages() error = %v, wantErr %v", err, tt.want.err)
 			}
-
-			if !reflect.DeepEqual(getImages, tt.want.images) {
-				t.Errorf("ListImages() \n got = %v,\n want %v", getImages, tt.want)
+			if !reflect.DeepEqual(result, tt.want.result) {
+				t.Errorf("ListImages() \n got = %v,\n want %v", result, tt.want.result)
 			}
 		})
 	}
@@ -165,6 +223,7 @@ func TestImageUseCase_CreateImage(t *testing.T) {
 					SpotID: uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052"),
 					UserID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
 					URL:    "https://hoge.com/hoge",
+					Status: model.ImageStatusPending,
 				}
 				m.EXPECT().Create(
 					gomock.Any(),
@@ -198,7 +257,11 @@ func TestImageUseCase_CreateImage(t *testing.T) {
 				tt.setup(ir)
 			}
 
-			usecase := NewImageUseCase(ir, ic)
+			usecase := NewImageUseCase(
+				ir, ic, mock.NewMockImageReportRepository(ctrl), mock.NewMockSpotRepository(ctrl),
+				storagemock.NewMockBlobStore(ctrl),
+				&testStorageConfig, &testModerationConfig, testTwoFactorConfig,
+			)
 
 			err := usecase.CreateImage(tt.arg.ctx, tt.arg.spotID, tt.arg.url, tt.arg.user)
 
@@ -217,17 +280,34 @@ func TestImageUseCase_DeleteImage(t *testing.T) {
 		name  string
 		setup func(
 			m *mock.MockImageRepository,
+			bs *storagemock.MockBlobStore,
 		)
 		arg     ImageDeleteArg
 		wantErr error
 	}{
 		{
 			name: "success",
-			setup: func(m *mock.MockImageRepository) {
+			setup: func(m *mock.MockImageRepository, bs *storagemock.MockBlobStore) {
+				m.EXPECT().Get(gomock.Any(), "31894386-3e60-45a8-bc67-f46b72b42554").Return(&model.Image{
+					ID:         uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+					UserID:     uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+					StorageKey: "spots/fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052/31894386-3e60-45a8-bc67-f46b72b42554.jpg",
+				}, nil)
 				m.EXPECT().Delete(
 					gomock.Any(),
 					"31894386-3e60-45a8-bc67-f46b72b42554",
 				).Return(nil)
+				for _, key := range []string{
+					"spots/fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052/31894386-3e60-45a8-bc67-f46b72b42554.jpg",
+					"spots/fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052/31894386-3e60-45a8-bc67-f46b72b42554_thumb.jpg",
+					"spots/fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052/31894386-3e60-45a8-bc67-f46b72b42554_thumb.webp",
+					"spots/fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052/31894386-3e60-45a8-bc67-f46b72b42554_medium.jpg",
+					"spots/fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052/31894386-3e60-45a8-bc67-f46b72b42554_medium.webp",
+					"spots/fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052/31894386-3e60-45a8-bc67-f46b72b42554_large.jpg",
+					"spots/fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052/31894386-3e60-45a8-bc67-f46b72b42554_large.webp",
+				} {
+					bs.EXPECT().Delete(gomock.Any(), key).Return(fmt.Errorf("storage unavailable"))
+				}
 			},
 			arg: ImageDeleteArg{
 				ctx:    context.Background(),
@@ -245,7 +325,11 @@ func TestImageUseCase_DeleteImage(t *testing.T) {
 		},
 		{
 			name: "success: Super User",
-			setup: func(m *mock.MockImageRepository) {
+			setup: func(m *mock.MockImageRepository, _ *storagemock.MockBlobStore) {
+				m.EXPECT().Get(gomock.Any(), "31894386-3e60-45a8-bc67-f46b72b42554").Return(&model.Image{
+					ID:  uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+					URL: "https://hoge.com/hoge",
+				}, nil)
 				m.EXPECT().Delete(
 					gomock.Any(),
 					"31894386-3e60-45a8-bc67-f46b72b42554",
@@ -256,17 +340,24 @@ func TestImageUseCase_DeleteImage(t *testing.T) {
 				id:     "31894386-3e60-45a8-bc67-f46b72b42554",
 				userID: "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
 				user: model.User{
-					ID:       uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e61234"),
-					Name:     "super_user",
-					Email:    "super_user@gmail.com",
-					Password: "password123",
-					IsAdmin:  true,
+					ID:          uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e61234"),
+					Name:        "super_user",
+					Email:       "super_user@gmail.com",
+					Password:    "password123",
+					IsAdmin:     true,
+					TOTPEnabled: true,
 				},
 			},
 			wantErr: nil,
 		},
 		{
 			name: "Fail: Not authorized to update",
+			setup: func(m *mock.MockImageRepository, _ *storagemock.MockBlobStore) {
+				m.EXPECT().Get(gomock.Any(), "31894386-3e60-45a8-bc67-f46b72b42554").Return(&model.Image{
+					ID:     uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+					UserID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+				}, nil)
+			},
 			arg: ImageDeleteArg{
 				ctx:    context.Background(),
 				id:     "31894386-3e60-45a8-bc67-f46b72b42554",
@@ -281,6 +372,40 @@ func TestImageUseCase_DeleteImage(t *testing.T) {
 			},
 			wantErr: fmt.Errorf("don't have permission to delete images"),
 		},
+		{
+			// リクエストのuserIDを自分のIDにしても、他のユーザの画像は削除できない
+			name: "Fail: non-owner passes own userID",
+			setup: func(m *mock.MockImageRepository, _ *storagemock.MockBlobStore) {
+				m.EXPECT().Get(gomock.Any(), "31894386-3e60-45a8-bc67-f46b72b42554").Return(&model.Image{
+					ID:     uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+					UserID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+				}, nil)
+			},
+			arg: ImageDeleteArg{
+				ctx:    context.Background(),
+				id:     "31894386-3e60-45a8-bc67-f46b72b42554",
+				userID: "f6db2530-cd9b-4ac1-8dc1-38c795e61234",
+				user:   model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e61234")},
+			},
+			wantErr: fmt.Errorf("don't have permission to delete images"),
+		},
+		{
+			// 2段階認証が必須の管理者は、設定するまで他のユーザの画像を削除できない
+			name: "Fail: admin without two-factor",
+			setup: func(m *mock.MockImageRepository, _ *storagemock.MockBlobStore) {
+				m.EXPECT().Get(gomock.Any(), "31894386-3e60-45a8-bc67-f46b72b42554").Return(&model.Image{
+					ID:     uuid.MustParse("31894386-3e60-45a8-bc67-f46b72b42554"),
+					UserID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
+				}, nil)
+			},
+			arg: ImageDeleteArg{
+				ctx:    context.Background(),
+				id:     "31894386-3e60-45a8-bc67-f46b72b42554",
+				userID: "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
+				user:   model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e61234"), Role: model.RoleAdmin},
+			},
+			wantErr: fmt.Errorf("don't have permission to delete images"),
+		},
 	}
 	for _, tt := range patterns {
 		t.Run(tt.name, func(t *testing.T) {
@@ -289,12 +414,16 @@ func TestImageUseCase_DeleteImage(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			ir := mock.NewMockImageRepository(ctrl)
 			ic := mock.NewMockImagesCacheRepository(ctrl)
+			bs := storagemock.NewMockBlobStore(ctrl)
 
 			if tt.setup != nil {
-				tt.setup(ir)
+				tt.setup(ir, bs)
 			}
 
-			usecase := NewImageUseCase(ir, ic)
+			usecase := NewImageUseCase(
+				ir, ic, mock.NewMockImageReportRepository(ctrl), mock.NewMockSpotRepository(ctrl), bs,
+				&testStorageConfig, &testModerationConfig, testTwoFactorConfig,
+			)
 
 			err := usecase.DeleteImage(tt.arg.ctx, tt.arg.id, tt.arg.userID, tt.arg.user)
 			if (err != nil) != (tt.wantErr != nil) {
@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"image"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/internal/imaging"
+	"github.com/tusmasoma/campfinder/docker/back/internal/storage"
+)
+
+// imageVariant は画像の登録時に作る縮小版で、長辺がmaxSize以下のJPEGとWebPとして元の画像の隣に保存します。
+// WebPに対応していないブラウザのため、JPEGも残します。
+type imageVariant struct {
+	suffix  string
+	maxSize int
+	setURLs func(img *model.Image, jpegURL, webpURL string)
+}
+
+var imageVariants = []imageVariant{
+	{suffix: "thumb", maxSize: 320, setURLs: func(img *model.Image, jpegURL, webpURL string) {
+		img.ThumbnailURL, img.ThumbnailWebPURL = jpegURL, webpURL
+	}},
+	{suffix: "medium", maxSize: 960, setURLs: func(img *model.Image, jpegURL, webpURL string) {
+		img.MediumURL, img.MediumWebPURL = jpegURL, webpURL
+	}},
+	{suffix: "large", maxSize: 1920, setURLs: func(img *model.Image, jpegURL, webpURL string) {
+		img.LargeURL, img.LargeWebPURL = jpegURL, webpURL
+	}},
+}
+
+// variantFormat は縮小版を保存する形式です。
+type variantFormat struct {
+	contentType string
+	extension   string
+	encode      func(img *image.RGBA) ([]byte, error)
+}
+
+var (
+	variantJPEG    = variantFormat{contentType: imaging.ContentTypeJPEG, extension: ".jpg", encode: imaging.EncodeJPEG}
+	variantWebP    = variantFormat{contentType: imaging.ContentTypeWebP, extension: ".webp", encode: imaging.EncodeWebP}
+	variantFormats = []variantFormat{variantJPEG, variantWebP}
+)
+
+// blurHashSize はBlurHashを計算する前に縮小する長辺の大きさです。
+const blurHashSize = 32
+
+// variantKey は"spots/<spotID>/<id>.png"の縮小版のキーを"spots/<spotID>/<id>_thumb.jpg"のように返します。
+func variantKey(key, suffix string, format variantFormat) string {
+	return strings.TrimSuffix(key, path.Ext(key)) + "_" + suffix + format.extension
+}
+
+// imageKeys はImageに対応してBlobStoreに保存しうるすべてのキーを返します。
+// BlobStoreは存在しないキーの削除を成功として扱うため、作成途中で失敗した場合の後片付けにも使えます。
+func imageKeys(img *model.Image) []string {
+	if img.StorageKey == "" {
+		return nil
+	}
+	keys := []string{img.StorageKey}
+	for _, v := range imageVariants {
+		for _, format := range variantFormats {
+			keys = append(keys, variantKey(img.StorageKey, v.suffix, format))
+		}
+	}
+	return keys
+}
+
+// UploadImage はアップロードされた画像を検査し、位置情報を取り除いてBlobStoreに保存したうえで、そのURLでImageを登録します。
+// 画像の形式はdataの中身から判定し、JPEGとPNGのみ受け付けます。縮小版とBlurHashも作成します。
+func (ih *imageUseCase) UploadImage(
+	ctx context.Context, spotID uuid.UUID, data []byte, user model.User,
+) (*model.Image, error) {
+	if int64(len(data)) > ih.sc.MaxUploadSize {
+		return nil, ErrImageTooLarge
+	}
+	contentType, err := imaging.Inspect(data)
+	if err != nil {
+		return nil, imagingError(err)
+	}
+	data, err = imaging.StripGPS(data, contentType)
+	if err != nil {
+		return nil, imagingError(err)
+	}
+
+	id := uuid.New()
+	key := "spots/" + spotID.String() + "/" + id.String() + imaging.Extension(contentType)
+	img := model.Image{
+		ID:         id,
+		SpotID:     spotID,
+		UserID:     user.ID,
+		StorageKey: key,
+		Status:     ih.initialStatus(user),
+	}
+	if err = ih.bs.Put(ctx, key, data, contentType); err != nil {
+		log.Printf("Failed to store image %v: %v", key, err)
+		return nil, err
+	}
+	img.URL = ih.bs.URL(key)
+
+	if err = ih.createVariants(ctx, &img, data); err != nil {
+		ih.deleteBlobs(ctx, imageKeys(&img))
+		return nil, err
+	}
+	if err = ih.ir.Create(ctx, img); err != nil {
+		log.Printf("Failed to create image: %v", err)
+		// 参照されないファイルが残らないよう削除する
+		ih.deleteBlobs(ctx, imageKeys(&img))
+		return nil, err
+	}
+	return &img, nil
+}
+
+// createVariants はimg.StorageKeyに保存した画像dataの縮小版を保存し、大きさ・縮小版のURL・BlurHashをimgに設定します。
+func (ih *imageUseCase) createVariants(ctx context.Context, img *model.Image, data []byte) error {
+	decoded, err := imaging.Decode(data)
+	if err != nil {
+		return ErrInvalidImage
+	}
+	img.Width, img.Height = decoded.Bounds().Dx(), decoded.Bounds().Dy()
+	img.BlurHash = imaging.BlurHash(
+		imaging.Resize(decoded, blurHashSize), imaging.BlurHashXComponents, imaging.BlurHashYComponents,
+	)
+
+	// 大きい縮小版から順に作ると、小さい縮小版を前の結果から作れて速い
+	resized := decoded
+	for i := len(imageVariants) - 1; i >= 0; i-- {
+		v := imageVariants[i]
+		resized = imaging.Resize(resized, v.maxSize)
+		jpegURL, err := ih.storeVariant(ctx, img.StorageKey, v.suffix, resized, variantJPEG)
+		if err != nil {
+			return err
+		}
+		webpURL, err := ih.storeVariant(ctx, img.StorageKey, v.suffix, resized, variantWebP)
+		if err != nil {
+			return err
+		}
+		v.setURLs(img, jpegURL, webpURL)
+	}
+	return nil
+}
+
+// storeVariant は縮小版をformatの形式で保存し、そのURLを返します。
+func (ih *imageUseCase) storeVariant(
+	ctx context.Context, storageKey, suffix string, img *image.RGBA, format variantFormat,
+) (string, error) {
+	encoded, err := format.encode(img)
+	if err != nil {
+		return "", err
+	}
+	key := variantKey(storageKey, suffix, format)
+	if err = ih.bs.Put(ctx, key, encoded, format.contentType); err != nil {
+		log.Printf("Failed to store image variant %v: %v", key, err)
+		return "", err
+	}
+	return ih.bs.URL(key), nil
+}
+
+func (ih *imageUseCase) deleteBlobs(ctx context.Context, keys []string) {
+	deleteBlobs(ctx, ih.bs, keys)
+}
+
+// deleteBlobs はkeysのファイルを削除します。後片付けのため、失敗してもログに残すだけです。
+func deleteBlobs(ctx context.Context, bs storage.BlobStore, keys []string) {
+	for _, key := range keys {
+		if err := bs.Delete(ctx, key); err != nil {
+			log.Printf("Failed to delete image file %v: %v", key, err)
+		}
+	}
+}
+
+func imagingError(err error) error {
+	switch {
+	case errors.Is(err, imaging.ErrUnsupportedType):
+		return ErrUnsupportedImageType
+	case errors.Is(err, imaging.ErrTooManyPixels):
+		return ErrImageTooLarge
+	default:
+		return ErrInvalidImage
+	}
+}
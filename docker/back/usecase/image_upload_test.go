@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/config"
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository/mock"
+	storagemock "github.com/tusmasoma/campfinder/docker/back/internal/storage/mock"
+)
+
+var testStorageConfig = config.StorageConfig{
+	Driver:        "local",
+	PublicBaseURL: "http://localhost:8083/uploads",
+	MaxUploadSize: 1 << 20,
+}
+
+func testJPEG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 4, 4)), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestImageUseCase_UploadImage(t *testing.T) {
+	t.Parallel()
+	spotID := uuid.MustParse("fb816fc7-ddcf-4fa0-9be0-d1fd0b8b5052")
+	user := model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")}
+	jpg := testJPEG(t)
+	keyPrefix := "spots/" + spotID.String() + "/"
+
+	patterns := []struct {
+		name    string
+		data    []byte
+		setup   func(m *mock.MockImageRepository, bs *storagemock.MockBlobStore)
+		wantErr error
+	}{
+		{
+			name: "success",
+			data: jpg,
+			setup: func(m *mock.MockImageRepository, bs *storagemock.MockBlobStore) {
+				stored := map[string][]byte{}
+				bs.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, k string, data []byte, contentType string) error {
+						if want := map[string]string{".jpg": "image/jpeg", ".webp": "image/webp"}[path.Ext(k)]; contentType != want {
+							return fmt.Errorf("unexpected content type of %v: %v", k, contentType)
+						}
+						stored[k] = data
+						return nil
+					},
+				).Times(7)
+				bs.EXPECT().URL(gomock.Any()).DoAndReturn(func(k string) string {
+					return "http://localhost:8083/uploads/" + k
+				}).Times(7)
+				m.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, img model.Image) error {
+					if !strings.HasPrefix(img.StorageKey, keyPrefix) || !bytes.Equal(stored[img.StorageKey], jpg) {
+						return fmt.Errorf("unexpected storage key: %v", img.StorageKey)
+					}
+					if img.URL != "http://localhost:8083/uploads/"+img.StorageKey || img.SpotID != spotID || img.UserID != user.ID {
+						return fmt.Errorf("unexpected image: %+v", img)
+					}
+					base := strings.TrimSuffix(img.StorageKey, ".jpg")
+					for url, key := range map[string]string{
+						img.ThumbnailURL:     base + "_thumb.jpg",
+						img.ThumbnailWebPURL: base + "_thumb.webp",
+						img.MediumURL:        base + "_medium.jpg",
+						img.MediumWebPURL:    base + "_medium.webp",
+						img.LargeURL:         base + "_large.jpg",
+						img.LargeWebPURL:     base + "_large.webp",
+					} {
+						if _, ok := stored[key]; !ok || url != "http://localhost:8083/uploads/"+key {
+							return fmt.Errorf("variant %v was not stored: %v", key, url)
+						}
+					}
+					if img.Width != 4 || img.Height != 4 || len(img.BlurHash) != 28 {
+						return fmt.Errorf("unexpected size or blurhash: %+v", img)
+					}
+					return nil
+				})
+			},
+		},
+		{
+			name: "Fail: storing variant failed removes stored files",
+			data: jpg,
+			setup: func(m *mock.MockImageRepository, bs *storagemock.MockBlobStore) {
+				bs.EXPECT().Put(gomock.Any(), gomock.Any(), jpg, "image/jpeg").Return(nil)
+				bs.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), "image/jpeg").Return(fmt.Errorf("storage error"))
+				bs.EXPECT().URL(gomock.Any()).Return("http://localhost:8083/uploads/spots/x.jpg")
+				bs.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil).Times(7)
+			},
+			wantErr: fmt.Errorf("storage error"),
+		},
+		{
+			name: "Fail: create failed removes stored file",
+			data: jpg,
+			setup: func(m *mock.MockImageRepository, bs *storagemock.MockBlobStore) {
+				bs.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), "image/jpeg").Return(nil).Times(4)
+				bs.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), "image/webp").Return(nil).Times(3)
+				bs.EXPECT().URL(gomock.Any()).Return("http://localhost:8083/uploads/spots/x.jpg").Times(7)
+				m.EXPECT().Create(gomock.Any(), gomock.Any()).Return(fmt.Errorf("db error"))
+				bs.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil).Times(7)
+			},
+			wantErr: fmt.Errorf("db error"),
+		},
+		{
+			name:    "Fail: unsupported type",
+			data:    []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;"),
+			wantErr: ErrUnsupportedImageType,
+		},
+		{
+			name:    "Fail: broken image",
+			data:    jpg[:32],
+			wantErr: ErrInvalidImage,
+		},
+		{
+			name:    "Fail: too large",
+			data:    make([]byte, testStorageConfig.MaxUploadSize+1),
+			wantErr: ErrImageTooLarge,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			ir := mock.NewMockImageRepository(ctrl)
+			ic := mock.NewMockImagesCacheRepository(ctrl)
+			bs := storagemock.NewMockBlobStore(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ir, bs)
+			}
+
+			usecase := NewImageUseCase(
+				ir, ic, mock.NewMockImageReportRepository(ctrl), mock.NewMockSpotRepository(ctrl), bs,
+				&testStorageConfig, &testModerationConfig, testTwoFactorConfig,
+			)
+			img, err := usecase.UploadImage(context.Background(), spotID, tt.data, user)
+			if tt.wantErr != nil {
+				if err == nil || (!errors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) {
+					t.Errorf("UploadImage() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UploadImage() error = %v", err)
+			}
+			if img.ID == uuid.Nil || !strings.HasSuffix(img.StorageKey, img.ID.String()+".jpg") {
+				t.Errorf("UploadImage() = %+v", img)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: admin.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+
+	model "github.com/tusmasoma/campfinder/docker/back/domain/model"
+	usecase "github.com/tusmasoma/campfinder/docker/back/usecase"
+)
+
+// MockAdminUseCase is a mock of AdminUseCase interface.
+type MockAdminUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminUseCaseMockRecorder
+}
+
+// MockAdminUseCaseMockRecorder is the mock recorder for MockAdminUseCase.
+type MockAdminUseCaseMockRecorder struct {
+	mock *MockAdminUseCase
+}
+
+// NewMockAdminUseCase creates a new mock instance.
+func NewMockAdminUseCase(ctrl *gomock.Controller) *MockAdminUseCase {
+	mock := &MockAdminUseCase{ctrl: ctrl}
+	mock.recorder = &MockAdminUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminUseCase) EXPECT() *MockAdminUseCaseMockRecorder {
+	return m.recorder
+}
+
+// AssignSpotOwner mocks base method.
+func (m *MockAdminUseCase) AssignSpotOwner(ctx context.Context, spotID, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignSpotOwner", ctx, spotID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AssignSpotOwner indicates an expected call of AssignSpotOwner.
+func (mr *MockAdminUseCaseMockRecorder) AssignSpotOwner(ctx, spotID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignSpotOwner", reflect.TypeOf((*MockAdminUseCase)(nil).AssignSpotOwner), ctx, spotID, userID)
+}
+
+// ForcePasswordReset mocks base method.
+func (m *MockAdminUseCase) ForcePasswordReset(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForcePasswordReset", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForcePasswordReset indicates an expected call of ForcePasswordReset.
+func (mr *MockAdminUseCaseMockRecorder) ForcePasswordReset(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForcePasswordReset", reflect.TypeOf((*MockAdminUseCase)(nil).ForcePasswordReset), ctx, userID)
+}
+
+// ListLoginLockouts mocks base method.
+func (m *MockAdminUseCase) ListLoginLockouts(ctx context.Context, limit int) ([]model.LoginLockout, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLoginLockouts", ctx, limit)
+	ret0, _ := ret[0].([]model.LoginLockout)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLoginLockouts indicates an expected call of ListLoginLockouts.
+func (mr *MockAdminUseCaseMockRecorder) ListLoginLockouts(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLoginLockouts", reflect.TypeOf((*MockAdminUseCase)(nil).ListLoginLockouts), ctx, limit)
+}
+
+// ListUsers mocks base method.
+func (m *MockAdminUseCase) ListUsers(ctx context.Context, params *usecase.ListUsersParams) (*usecase.ListUsersResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", ctx, params)
+	ret0, _ := ret[0].(*usecase.ListUsersResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockAdminUseCaseMockRecorder) ListUsers(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockAdminUseCase)(nil).ListUsers), ctx, params)
+}
+
+// RemoveSpotOwner mocks base method.
+func (m *MockAdminUseCase) RemoveSpotOwner(ctx context.Context, spotID, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveSpotOwner", ctx, spotID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveSpotOwner indicates an expected call of RemoveSpotOwner.
+func (mr *MockAdminUseCaseMockRecorder) RemoveSpotOwner(ctx, spotID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveSpotOwner", reflect.TypeOf((*MockAdminUseCase)(nil).RemoveSpotOwner), ctx, spotID, userID)
+}
+
+// SetUserSuspended mocks base method.
+func (m *MockAdminUseCase) SetUserSuspended(ctx context.Context, actorID, userID string, suspended bool) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserSuspended", ctx, actorID, userID, suspended)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserSuspended indicates an expected call of SetUserSuspended.
+func (mr *MockAdminUseCaseMockRecorder) SetUserSuspended(ctx, actorID, userID, suspended interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserSuspended", reflect.TypeOf((*MockAdminUseCase)(nil).SetUserSuspended), ctx, actorID, userID, suspended)
+}
+
+// UpdateUserRole mocks base method.
+func (m *MockAdminUseCase) UpdateUserRole(ctx context.Context, actorID, userID string, role model.Role) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserRole", ctx, actorID, userID, role)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserRole indicates an expected call of UpdateUserRole.
+func (mr *MockAdminUseCaseMockRecorder) UpdateUserRole(ctx, actorID, userID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserRole", reflect.TypeOf((*MockAdminUseCase)(nil).UpdateUserRole), ctx, actorID, userID, role)
+}
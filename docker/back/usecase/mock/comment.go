@@ -81,18 +81,33 @@ func (mr *MockCommentUseCaseMockRecorder) DeleteComment(ctx, id, userID, user in
 }
 
 // ListComments mocks base method.
-func (m *MockCommentUseCase) ListComments(ctx context.Context, spotID string) ([]model.Comment, error) {
+func (m *MockCommentUseCase) ListComments(ctx context.Context, params *usecase.ListCommentsParams) (*usecase.ListCommentsResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListComments", ctx, spotID)
-	ret0, _ := ret[0].([]model.Comment)
+	ret := m.ctrl.Call(m, "ListComments", ctx, params)
+	ret0, _ := ret[0].(*usecase.ListCommentsResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListComments indicates an expected call of ListComments.
-func (mr *MockCommentUseCaseMockRecorder) ListComments(ctx, spotID interface{}) *gomock.Call {
+func (mr *MockCommentUseCaseMockRecorder) ListComments(ctx, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListComments", reflect.TypeOf((*MockCommentUseCase)(nil).ListComments), ctx, spotID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListComments", reflect.TypeOf((*MockCommentUseCase)(nil).ListComments), ctx, params)
+}
+
+// ListReplies mocks base method.
+func (m *MockCommentUseCase) ListReplies(ctx context.Context, params *usecase.ListRepliesParams) (*usecase.ListRepliesResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReplies", ctx, params)
+	ret0, _ := ret[0].(*usecase.ListRepliesResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReplies indicates an expected call of ListReplies.
+func (mr *MockCommentUseCaseMockRecorder) ListReplies(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReplies", reflect.TypeOf((*MockCommentUseCase)(nil).ListReplies), ctx, params)
 }
 
 // UpdateComment mocks base method.
@@ -12,6 +12,7 @@ import (
 	uuid "github.com/google/uuid"
 
 	model "github.com/tusmasoma/campfinder/docker/back/domain/model"
+	usecase "github.com/tusmasoma/campfinder/docker/back/usecase"
 )
 
 // MockImageUseCase is a mock of ImageUseCase interface.
@@ -37,6 +38,21 @@ func (m *MockImageUseCase) EXPECT() *MockImageUseCaseMockRecorder {
 	return m.recorder
 }
 
+// CompleteUpload mocks base method.
+func (m *MockImageUseCase) CompleteUpload(ctx context.Context, key string, user model.User) (*model.Image, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteUpload", ctx, key, user)
+	ret0, _ := ret[0].(*model.Image)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteUpload indicates an expected call of CompleteUpload.
+func (mr *MockImageUseCaseMockRecorder) CompleteUpload(ctx, key, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteUpload", reflect.TypeOf((*MockImageUseCase)(nil).CompleteUpload), ctx, key, user)
+}
+
 // CreateImage mocks base method.
 func (m *MockImageUseCase) CreateImage(ctx context.Context, spotID uuid.UUID, url string, user model.User) error {
 	m.ctrl.T.Helper()
@@ -51,6 +67,21 @@ func (mr *MockImageUseCaseMockRecorder) CreateImage(ctx, spotID, url, user inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateImage", reflect.TypeOf((*MockImageUseCase)(nil).CreateImage), ctx, spotID, url, user)
 }
 
+// CreateUploadURL mocks base method.
+func (m *MockImageUseCase) CreateUploadURL(ctx context.Context, spotID uuid.UUID, contentType string, user model.User) (*usecase.UploadURL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUploadURL", ctx, spotID, contentType, user)
+	ret0, _ := ret[0].(*usecase.UploadURL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUploadURL indicates an expected call of CreateUploadURL.
+func (mr *MockImageUseCaseMockRecorder) CreateUploadURL(ctx, spotID, contentType, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUploadURL", reflect.TypeOf((*MockImageUseCase)(nil).CreateUploadURL), ctx, spotID, contentType, user)
+}
+
 // DeleteImage mocks base method.
 func (m *MockImageUseCase) DeleteImage(ctx context.Context, id, userID string, user model.User) error {
 	m.ctrl.T.Helper()
@@ -66,16 +97,75 @@ func (mr *MockImageUseCaseMockRecorder) DeleteImage(ctx, id, userID, user interf
 }
 
 // ListImages mocks base method.
-func (m *MockImageUseCase) ListImages(ctx context.Context, spotID string) ([]model.Image, error) {
+func (m *MockImageUseCase) ListImages(ctx context.Context, params *usecase.ListImagesParams) (*usecase.ListImagesResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListImages", ctx, spotID)
-	ret0, _ := ret[0].([]model.Image)
+	ret := m.ctrl.Call(m, "ListImages", ctx, params)
+	ret0, _ := ret[0].(*usecase.ListImagesResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListImages indicates an expected call of ListImages.
-func (mr *MockImageUseCaseMockRecorder) ListImages(ctx, spotID interface{}) *gomock.Call {
+func (mr *MockImageUseCaseMockRecorder) ListImages(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListImages", reflect.TypeOf((*MockImageUseCase)(nil).ListImages), ctx, params)
+}
+
+// ListModerationQueue mocks base method.
+func (m *MockImageUseCase) ListModerationQueue(ctx context.Context, params *usecase.ListModerationQueueParams) (*usecase.ModerationQueueResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListModerationQueue", ctx, params)
+	ret0, _ := ret[0].(*usecase.ModerationQueueResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListModerationQueue indicates an expected call of ListModerationQueue.
+func (mr *MockImageUseCaseMockRecorder) ListModerationQueue(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListModerationQueue", reflect.TypeOf((*MockImageUseCase)(nil).ListModerationQueue), ctx, params)
+}
+
+// ModerateImage mocks base method.
+func (m *MockImageUseCase) ModerateImage(ctx context.Context, id string, status model.ImageStatus, moderator model.User) (*model.Image, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ModerateImage", ctx, id, status, moderator)
+	ret0, _ := ret[0].(*model.Image)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ModerateImage indicates an expected call of ModerateImage.
+func (mr *MockImageUseCaseMockRecorder) ModerateImage(ctx, id, status, moderator interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModerateImage", reflect.TypeOf((*MockImageUseCase)(nil).ModerateImage), ctx, id, status, moderator)
+}
+
+// ReportImage mocks base method.
+func (m *MockImageUseCase) ReportImage(ctx context.Context, id, reason string, user model.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReportImage", ctx, id, reason, user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReportImage indicates an expected call of ReportImage.
+func (mr *MockImageUseCaseMockRecorder) ReportImage(ctx, id, reason, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportImage", reflect.TypeOf((*MockImageUseCase)(nil).ReportImage), ctx, id, reason, user)
+}
+
+// UploadImage mocks base method.
+func (m *MockImageUseCase) UploadImage(ctx context.Context, spotID uuid.UUID, data []byte, user model.User) (*model.Image, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadImage", ctx, spotID, data, user)
+	ret0, _ := ret[0].(*model.Image)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadImage indicates an expected call of UploadImage.
+func (mr *MockImageUseCaseMockRecorder) UploadImage(ctx, spotID, data, user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListImages", reflect.TypeOf((*MockImageUseCase)(nil).ListImages), ctx, spotID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadImage", reflect.TypeOf((*MockImageUseCase)(nil).UploadImage), ctx, spotID, data, user)
 }
@@ -0,0 +1,67 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: oidc.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+
+	usecase "github.com/tusmasoma/campfinder/docker/back/usecase"
+)
+
+// MockOIDCUseCase is a mock of OIDCUseCase interface.
+type MockOIDCUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockOIDCUseCaseMockRecorder
+}
+
+// MockOIDCUseCaseMockRecorder is the mock recorder for MockOIDCUseCase.
+type MockOIDCUseCaseMockRecorder struct {
+	mock *MockOIDCUseCase
+}
+
+// NewMockOIDCUseCase creates a new mock instance.
+func NewMockOIDCUseCase(ctrl *gomock.Controller) *MockOIDCUseCase {
+	mock := &MockOIDCUseCase{ctrl: ctrl}
+	mock.recorder = &MockOIDCUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOIDCUseCase) EXPECT() *MockOIDCUseCaseMockRecorder {
+	return m.recorder
+}
+
+// CompleteLogin mocks base method.
+func (m *MockOIDCUseCase) CompleteLogin(ctx context.Context, provider, state, code string, device usecase.Device) (*usecase.TokenPair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteLogin", ctx, provider, state, code, device)
+	ret0, _ := ret[0].(*usecase.TokenPair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteLogin indicates an expected call of CompleteLogin.
+func (mr *MockOIDCUseCaseMockRecorder) CompleteLogin(ctx, provider, state, code, device interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteLogin", reflect.TypeOf((*MockOIDCUseCase)(nil).CompleteLogin), ctx, provider, state, code, device)
+}
+
+// StartLogin mocks base method.
+func (m *MockOIDCUseCase) StartLogin(ctx context.Context, provider string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartLogin", ctx, provider)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartLogin indicates an expected call of StartLogin.
+func (mr *MockOIDCUseCaseMockRecorder) StartLogin(ctx, provider interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartLogin", reflect.TypeOf((*MockOIDCUseCase)(nil).StartLogin), ctx, provider)
+}
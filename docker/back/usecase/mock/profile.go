@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: profile.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+
+	model "github.com/tusmasoma/campfinder/docker/back/domain/model"
+	usecase "github.com/tusmasoma/campfinder/docker/back/usecase"
+)
+
+// MockProfileUseCase is a mock of ProfileUseCase interface.
+type MockProfileUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockProfileUseCaseMockRecorder
+}
+
+// MockProfileUseCaseMockRecorder is the mock recorder for MockProfileUseCase.
+type MockProfileUseCaseMockRecorder struct {
+	mock *MockProfileUseCase
+}
+
+// NewMockProfileUseCase creates a new mock instance.
+func NewMockProfileUseCase(ctrl *gomock.Controller) *MockProfileUseCase {
+	mock := &MockProfileUseCase{ctrl: ctrl}
+	mock.recorder = &MockProfileUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProfileUseCase) EXPECT() *MockProfileUseCaseMockRecorder {
+	return m.recorder
+}
+
+// ChangePassword mocks base method.
+func (m *MockProfileUseCase) ChangePassword(ctx context.Context, userID, sessionID, currentPassword, newPassword string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, userID, sessionID, currentPassword, newPassword)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockProfileUseCaseMockRecorder) ChangePassword(ctx, userID, sessionID, currentPassword, newPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockProfileUseCase)(nil).ChangePassword), ctx, userID, sessionID, currentPassword, newPassword)
+}
+
+// DeleteAccount mocks base method.
+func (m *MockProfileUseCase) DeleteAccount(ctx context.Context, userID, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", ctx, userID, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockProfileUseCaseMockRecorder) DeleteAccount(ctx, userID, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockProfileUseCase)(nil).DeleteAccount), ctx, userID, password)
+}
+
+// GetPublicProfile mocks base method.
+func (m *MockProfileUseCase) GetPublicProfile(ctx context.Context, userID string, params *usecase.ListReviewsParams) (*usecase.PublicProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPublicProfile", ctx, userID, params)
+	ret0, _ := ret[0].(*usecase.PublicProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPublicProfile indicates an expected call of GetPublicProfile.
+func (mr *MockProfileUseCaseMockRecorder) GetPublicProfile(ctx, userID, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublicProfile", reflect.TypeOf((*MockProfileUseCase)(nil).GetPublicProfile), ctx, userID, params)
+}
+
+// UpdateProfile mocks base method.
+func (m *MockProfileUseCase) UpdateProfile(ctx context.Context, userID string, params *usecase.UpdateProfileParams) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", ctx, userID, params)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile.
+func (mr *MockProfileUseCaseMockRecorder) UpdateProfile(ctx, userID, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockProfileUseCase)(nil).UpdateProfile), ctx, userID, params)
+}
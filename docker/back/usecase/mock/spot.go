@@ -65,6 +65,34 @@ func (mr *MockSpotUseCaseMockRecorder) CreateSpot(ctx, params interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSpot", reflect.TypeOf((*MockSpotUseCase)(nil).CreateSpot), ctx, params)
 }
 
+// DeleteSpot mocks base method.
+func (m *MockSpotUseCase) DeleteSpot(ctx context.Context, spotID string, user model.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSpot", ctx, spotID, user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSpot indicates an expected call of DeleteSpot.
+func (mr *MockSpotUseCaseMockRecorder) DeleteSpot(ctx, spotID, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSpot", reflect.TypeOf((*MockSpotUseCase)(nil).DeleteSpot), ctx, spotID, user)
+}
+
+// ExportSpots mocks base method.
+func (m *MockSpotUseCase) ExportSpots(ctx context.Context, params *usecase.ExportSpotsParams, fn func(model.Spot) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportSpots", ctx, params, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportSpots indicates an expected call of ExportSpots.
+func (mr *MockSpotUseCaseMockRecorder) ExportSpots(ctx, params, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportSpots", reflect.TypeOf((*MockSpotUseCase)(nil).ExportSpots), ctx, params, fn)
+}
+
 // GetSpot mocks base method.
 func (m *MockSpotUseCase) GetSpot(ctx context.Context, spotID string) model.Spot {
 	m.ctrl.T.Helper()
@@ -79,16 +107,105 @@ func (mr *MockSpotUseCaseMockRecorder) GetSpot(ctx, spotID interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSpot", reflect.TypeOf((*MockSpotUseCase)(nil).GetSpot), ctx, spotID)
 }
 
+// GetSpotTile mocks base method.
+func (m *MockSpotUseCase) GetSpotTile(ctx context.Context, z, x, y int) (model.SpotClusters, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSpotTile", ctx, z, x, y)
+	ret0, _ := ret[0].(model.SpotClusters)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSpotTile indicates an expected call of GetSpotTile.
+func (mr *MockSpotUseCaseMockRecorder) GetSpotTile(ctx, z, x, y interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSpotTile", reflect.TypeOf((*MockSpotUseCase)(nil).GetSpotTile), ctx, z, x, y)
+}
+
+// ImportSpots mocks base method.
+func (m *MockSpotUseCase) ImportSpots(ctx context.Context, params *usecase.ImportSpotsParams) (*usecase.ImportSpotsReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportSpots", ctx, params)
+	ret0, _ := ret[0].(*usecase.ImportSpotsReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportSpots indicates an expected call of ImportSpots.
+func (mr *MockSpotUseCaseMockRecorder) ImportSpots(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportSpots", reflect.TypeOf((*MockSpotUseCase)(nil).ImportSpots), ctx, params)
+}
+
+// ListNearbySpots mocks base method.
+func (m *MockSpotUseCase) ListNearbySpots(ctx context.Context, params *usecase.ListNearbySpotsParams) ([]model.SpotWithDistance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNearbySpots", ctx, params)
+	ret0, _ := ret[0].([]model.SpotWithDistance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNearbySpots indicates an expected call of ListNearbySpots.
+func (mr *MockSpotUseCaseMockRecorder) ListNearbySpots(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNearbySpots", reflect.TypeOf((*MockSpotUseCase)(nil).ListNearbySpots), ctx, params)
+}
+
 // ListSpots mocks base method.
-func (m *MockSpotUseCase) ListSpots(ctx context.Context, categories []string) []model.Spot {
+func (m *MockSpotUseCase) ListSpots(ctx context.Context, params *usecase.ListSpotsParams) (*usecase.ListSpotsResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListSpots", ctx, categories)
-	ret0, _ := ret[0].([]model.Spot)
-	return ret0
+	ret := m.ctrl.Call(m, "ListSpots", ctx, params)
+	ret0, _ := ret[0].(*usecase.ListSpotsResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // ListSpots indicates an expected call of ListSpots.
-func (mr *MockSpotUseCaseMockRecorder) ListSpots(ctx, categories interface{}) *gomock.Call {
+func (mr *MockSpotUseCaseMockRecorder) ListSpots(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSpots", reflect.TypeOf((*MockSpotUseCase)(nil).ListSpots), ctx, params)
+}
+
+// PatchSpot mocks base method.
+func (m *MockSpotUseCase) PatchSpot(ctx context.Context, params *usecase.PatchSpotParams, user model.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchSpot", ctx, params, user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PatchSpot indicates an expected call of PatchSpot.
+func (mr *MockSpotUseCaseMockRecorder) PatchSpot(ctx, params, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchSpot", reflect.TypeOf((*MockSpotUseCase)(nil).PatchSpot), ctx, params, user)
+}
+
+// SearchSpots mocks base method.
+func (m *MockSpotUseCase) SearchSpots(ctx context.Context, params *usecase.SearchSpotsParams) ([]model.SpotWithScore, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchSpots", ctx, params)
+	ret0, _ := ret[0].([]model.SpotWithScore)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchSpots indicates an expected call of SearchSpots.
+func (mr *MockSpotUseCaseMockRecorder) SearchSpots(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchSpots", reflect.TypeOf((*MockSpotUseCase)(nil).SearchSpots), ctx, params)
+}
+
+// UpdateSpot mocks base method.
+func (m *MockSpotUseCase) UpdateSpot(ctx context.Context, params *usecase.UpdateSpotParams, user model.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSpot", ctx, params, user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSpot indicates an expected call of UpdateSpot.
+func (mr *MockSpotUseCaseMockRecorder) UpdateSpot(ctx, params, user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSpots", reflect.TypeOf((*MockSpotUseCase)(nil).ListSpots), ctx, categories)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSpot", reflect.TypeOf((*MockSpotUseCase)(nil).UpdateSpot), ctx, params, user)
 }
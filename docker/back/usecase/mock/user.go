@@ -9,6 +9,9 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+
+	model "github.com/tusmasoma/campfinder/docker/back/domain/model"
+	usecase "github.com/tusmasoma/campfinder/docker/back/usecase"
 )
 
 // MockUserUseCase is a mock of UserUseCase interface.
@@ -35,45 +38,233 @@ func (m *MockUserUseCase) EXPECT() *MockUserUseCaseMockRecorder {
 }
 
 // CreateUserAndGenerateToken mocks base method.
-func (m *MockUserUseCase) CreateUserAndGenerateToken(ctx context.Context, email, passward string) (string, error) {
+func (m *MockUserUseCase) CreateUserAndGenerateToken(ctx context.Context, email, passward string, device usecase.Device) (*usecase.TokenPair, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUserAndGenerateToken", ctx, email, passward)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "CreateUserAndGenerateToken", ctx, email, passward, device)
+	ret0, _ := ret[0].(*usecase.TokenPair)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateUserAndGenerateToken indicates an expected call of CreateUserAndGenerateToken.
-func (mr *MockUserUseCaseMockRecorder) CreateUserAndGenerateToken(ctx, email, passward interface{}) *gomock.Call {
+func (mr *MockUserUseCaseMockRecorder) CreateUserAndGenerateToken(ctx, email, passward, device interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserAndGenerateToken", reflect.TypeOf((*MockUserUseCase)(nil).CreateUserAndGenerateToken), ctx, email, passward, device)
+}
+
+// DisableTwoFactor mocks base method.
+func (m *MockUserUseCase) DisableTwoFactor(ctx context.Context, userID, password, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTwoFactor", ctx, userID, password, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTwoFactor indicates an expected call of DisableTwoFactor.
+func (mr *MockUserUseCaseMockRecorder) DisableTwoFactor(ctx, userID, password, code interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserAndGenerateToken", reflect.TypeOf((*MockUserUseCase)(nil).CreateUserAndGenerateToken), ctx, email, passward)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTwoFactor", reflect.TypeOf((*MockUserUseCase)(nil).DisableTwoFactor), ctx, userID, password, code)
+}
+
+// EnableTwoFactor mocks base method.
+func (m *MockUserUseCase) EnableTwoFactor(ctx context.Context, userID, code string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTwoFactor", ctx, userID, code)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableTwoFactor indicates an expected call of EnableTwoFactor.
+func (mr *MockUserUseCaseMockRecorder) EnableTwoFactor(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTwoFactor", reflect.TypeOf((*MockUserUseCase)(nil).EnableTwoFactor), ctx, userID, code)
+}
+
+// ListSessions mocks base method.
+func (m *MockUserUseCase) ListSessions(ctx context.Context, userID string) ([]model.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessions", ctx, userID)
+	ret0, _ := ret[0].([]model.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MockUserUseCaseMockRecorder) ListSessions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MockUserUseCase)(nil).ListSessions), ctx, userID)
 }
 
 // LoginAndGenerateToken mocks base method.
-func (m *MockUserUseCase) LoginAndGenerateToken(ctx context.Context, email, passward string) (string, error) {
+func (m *MockUserUseCase) LoginAndGenerateToken(ctx context.Context, email, passward string, device usecase.Device) (*usecase.TokenPair, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LoginAndGenerateToken", ctx, email, passward)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "LoginAndGenerateToken", ctx, email, passward, device)
+	ret0, _ := ret[0].(*usecase.TokenPair)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // LoginAndGenerateToken indicates an expected call of LoginAndGenerateToken.
-func (mr *MockUserUseCaseMockRecorder) LoginAndGenerateToken(ctx, email, passward interface{}) *gomock.Call {
+func (mr *MockUserUseCaseMockRecorder) LoginAndGenerateToken(ctx, email, passward, device interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginAndGenerateToken", reflect.TypeOf((*MockUserUseCase)(nil).LoginAndGenerateToken), ctx, email, passward)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginAndGenerateToken", reflect.TypeOf((*MockUserUseCase)(nil).LoginAndGenerateToken), ctx, email, passward, device)
 }
 
 // LogoutUser mocks base method.
-func (m *MockUserUseCase) LogoutUser(ctx context.Context, userID string) error {
+func (m *MockUserUseCase) LogoutUser(ctx context.Context, userID, sessionID string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LogoutUser", ctx, userID)
+	ret := m.ctrl.Call(m, "LogoutUser", ctx, userID, sessionID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // LogoutUser indicates an expected call of LogoutUser.
-func (mr *MockUserUseCaseMockRecorder) LogoutUser(ctx, userID interface{}) *gomock.Call {
+func (mr *MockUserUseCaseMockRecorder) LogoutUser(ctx, userID, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogoutUser", reflect.TypeOf((*MockUserUseCase)(nil).LogoutUser), ctx, userID, sessionID)
+}
+
+// RefreshToken mocks base method.
+func (m *MockUserUseCase) RefreshToken(ctx context.Context, refreshToken string) (*usecase.TokenPair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshToken", ctx, refreshToken)
+	ret0, _ := ret[0].(*usecase.TokenPair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshToken indicates an expected call of RefreshToken.
+func (mr *MockUserUseCaseMockRecorder) RefreshToken(ctx, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MockUserUseCase)(nil).RefreshToken), ctx, refreshToken)
+}
+
+// RegenerateRecoveryCodes mocks base method.
+func (m *MockUserUseCase) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegenerateRecoveryCodes", ctx, userID, code)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegenerateRecoveryCodes indicates an expected call of RegenerateRecoveryCodes.
+func (mr *MockUserUseCaseMockRecorder) RegenerateRecoveryCodes(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegenerateRecoveryCodes", reflect.TypeOf((*MockUserUseCase)(nil).RegenerateRecoveryCodes), ctx, userID, code)
+}
+
+// RequestEmailVerification mocks base method.
+func (m *MockUserUseCase) RequestEmailVerification(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestEmailVerification", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestEmailVerification indicates an expected call of RequestEmailVerification.
+func (mr *MockUserUseCaseMockRecorder) RequestEmailVerification(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestEmailVerification", reflect.TypeOf((*MockUserUseCase)(nil).RequestEmailVerification), ctx, userID)
+}
+
+// RequestPasswordReset mocks base method.
+func (m *MockUserUseCase) RequestPasswordReset(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestPasswordReset", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestPasswordReset indicates an expected call of RequestPasswordReset.
+func (mr *MockUserUseCaseMockRecorder) RequestPasswordReset(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestPasswordReset", reflect.TypeOf((*MockUserUseCase)(nil).RequestPasswordReset), ctx, email)
+}
+
+// ResetPassword mocks base method.
+func (m *MockUserUseCase) ResetPassword(ctx context.Context, token, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, token, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockUserUseCaseMockRecorder) ResetPassword(ctx, token, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockUserUseCase)(nil).ResetPassword), ctx, token, password)
+}
+
+// RevokeAllSessions mocks base method.
+func (m *MockUserUseCase) RevokeAllSessions(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAllSessions", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAllSessions indicates an expected call of RevokeAllSessions.
+func (mr *MockUserUseCaseMockRecorder) RevokeAllSessions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllSessions", reflect.TypeOf((*MockUserUseCase)(nil).RevokeAllSessions), ctx, userID)
+}
+
+// RevokeSession mocks base method.
+func (m *MockUserUseCase) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", ctx, userID, sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockUserUseCaseMockRecorder) RevokeSession(ctx, userID, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockUserUseCase)(nil).RevokeSession), ctx, userID, sessionID)
+}
+
+// SetupTwoFactor mocks base method.
+func (m *MockUserUseCase) SetupTwoFactor(ctx context.Context, userID string) (*usecase.TwoFactorSetup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetupTwoFactor", ctx, userID)
+	ret0, _ := ret[0].(*usecase.TwoFactorSetup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetupTwoFactor indicates an expected call of SetupTwoFactor.
+func (mr *MockUserUseCaseMockRecorder) SetupTwoFactor(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetupTwoFactor", reflect.TypeOf((*MockUserUseCase)(nil).SetupTwoFactor), ctx, userID)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockUserUseCase) VerifyEmail(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockUserUseCaseMockRecorder) VerifyEmail(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockUserUseCase)(nil).VerifyEmail), ctx, token)
+}
+
+// VerifyTwoFactorLogin mocks base method.
+func (m *MockUserUseCase) VerifyTwoFactorLogin(ctx context.Context, challengeToken, code string) (*usecase.TokenPair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyTwoFactorLogin", ctx, challengeToken, code)
+	ret0, _ := ret[0].(*usecase.TokenPair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyTwoFactorLogin indicates an expected call of VerifyTwoFactorLogin.
+func (mr *MockUserUseCaseMockRecorder) VerifyTwoFactorLogin(ctx, challengeToken, code interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogoutUser", reflect.TypeOf((*MockUserUseCase)(nil).LogoutUser), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyTwoFactorLogin", reflect.TypeOf((*MockUserUseCase)(nil).VerifyTwoFactorLogin), ctx, challengeToken, code)
 }
@@ -0,0 +1,210 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+	"github.com/tusmasoma/campfinder/docker/back/internal/auth"
+	"github.com/tusmasoma/campfinder/docker/back/internal/oidc"
+)
+
+// OIDCStateTTL は認可リクエストからコールバックまでに許容する時間です。
+const OIDCStateTTL = 10 * time.Minute
+
+// OIDCUseCase は外部のIDプロバイダによるログインです。
+// 認可コードフローにPKCEを併用し、IDプロバイダのアカウントをIdentityとしてユーザに紐付けます。
+type OIDCUseCase interface {
+	// StartLogin はIDプロバイダの認可エンドポイントのURLを返します。
+	StartLogin(ctx context.Context, provider string) (string, error)
+	// CompleteLogin は認可コードをIDトークンと交換し、紐付いたユーザのトークンを発行します。
+	CompleteLogin(ctx context.Context, provider string, state string, code string, device Device) (*TokenPair, error)
+}
+
+type oidcUseCase struct {
+	providers oidc.Providers
+	ur        repository.UserRepository
+	ir        repository.IdentityRepository
+	cr        repository.UserCacheRepository
+	sr        repository.OIDCStateCacheRepository
+	tfr       repository.TwoFactorCacheRepository
+}
+
+func NewOIDCUseCase(
+	providers oidc.Providers,
+	ur repository.UserRepository,
+	ir repository.IdentityRepository,
+	cr repository.UserCacheRepository,
+	sr repository.OIDCStateCacheRepository,
+	tfr repository.TwoFactorCacheRepository,
+) OIDCUseCase {
+	return &oidcUseCase{
+		providers: providers,
+		ur:        ur,
+		ir:        ir,
+		cr:        cr,
+		sr:        sr,
+		tfr:       tfr,
+	}
+}
+
+func (ouc *oidcUseCase) StartLogin(ctx context.Context, providerName string) (string, error) {
+	provider, err := ouc.providers.Get(providerName)
+	if err != nil {
+		return "", err
+	}
+
+	var state, nonce, verifier string
+	for _, token := range []*string{&state, &nonce, &verifier} {
+		if *token, err = oidc.RandomToken(); err != nil {
+			log.Printf("Failed to generate oidc parameter: %v", err)
+			return "", err
+		}
+	}
+	if err = ouc.sr.SetOIDCState(ctx, state, model.OIDCAuthState{
+		Provider:     provider.Name(),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		CreatedAt:    time.Now(),
+	}, OIDCStateTTL); err != nil {
+		log.Printf("Failed to set oidc state in cache: %v", err)
+		return "", err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		log.Printf("Failed to build authorization url for %v: %v", provider.Name(), err)
+		return "", err
+	}
+	return authURL, nil
+}
+
+func (ouc *oidcUseCase) CompleteLogin(
+	ctx context.Context,
+	providerName string,
+	state string,
+	code string,
+	device Device,
+) (*TokenPair, error) {
+	provider, err := ouc.providers.Get(providerName)
+	if err != nil {
+		return nil, err
+	}
+	// stateは一度しか使えず、別のIDプロバイダのコールバックにも使えない
+	authState, err := ouc.sr.ConsumeOIDCState(ctx, state)
+	if errors.Is(err, repository.ErrCacheMiss) {
+		return nil, ErrInvalidOIDCState
+	} else if err != nil {
+		log.Printf("Failed to get oidc state from cache: %v", err)
+		return nil, err
+	}
+	if authState.Provider != provider.Name() {
+		return nil, ErrInvalidOIDCState
+	}
+
+	claims, err := provider.Exchange(ctx, code, authState.CodeVerifier, authState.Nonce)
+	if err != nil {
+		log.Printf("Failed to exchange authorization code with %v: %v", provider.Name(), err)
+		return nil, err
+	}
+
+	user, err := ouc.findOrCreateUser(ctx, provider.Name(), claims)
+	if err != nil {
+		return nil, err
+	}
+	if user.Suspended {
+		return nil, ErrAccountSuspended
+	}
+	// 外部ログインでも、2段階認証が有効なユーザは2段階目の認証を求める
+	if user.TOTPEnabled {
+		return nil, startTwoFactorLogin(ctx, ouc.tfr, user, device)
+	}
+	return issueSessionTokens(ctx, ouc.cr, user.Email, newSession(user.ID.String(), device), "")
+}
+
+// findOrCreateUser はIDプロバイダのアカウントに紐付いたユーザを返します。紐付いていなければ、
+// IDプロバイダが確認済みとしたメールアドレスの既存ユーザ、またはそのメールアドレスで作成したユーザに紐付けます。
+func (ouc *oidcUseCase) findOrCreateUser(
+	ctx context.Context,
+	provider string,
+	claims *oidc.Claims,
+) (*model.User, error) {
+	identities, err := ouc.ir.List(ctx, []repository.QueryCondition{
+		{Field: "Provider", Value: provider},
+		{Field: "Subject", Value: claims.Subject},
+	})
+	if err != nil {
+		log.Printf("Failed to list identities: %v", err)
+		return nil, err
+	}
+	if len(identities) > 0 {
+		var user *model.User
+		if user, err = ouc.ur.Get(ctx, identities[0].UserID.String()); err != nil {
+			log.Printf("Failed to get user: %v", err)
+			return nil, err
+		}
+		return user, nil
+	}
+
+	if claims.Email == "" {
+		return nil, ErrOIDCEmailRequired
+	}
+	users, err := ouc.ur.List(ctx, []repository.QueryCondition{{Field: "Email", Value: claims.Email}})
+	if err != nil {
+		log.Printf("Failed to list users: %v", err)
+		return nil, err
+	}
+
+	var user model.User
+	switch {
+	case len(users) > 0 && claims.EmailVerified && users[0].EmailVerified:
+		// メールアドレスの所有を双方で確認できた場合だけ、既存のアカウントに紐付ける
+		user = users[0]
+	case len(users) > 0:
+		return nil, ErrOIDCAccountConflict
+	default:
+		user = model.User{
+			ID:            uuid.New(),
+			Name:          displayNameFromClaims(claims),
+			Email:         claims.Email,
+			Role:          model.RoleUser,
+			EmailVerified: claims.EmailVerified,
+		}
+		if err = ouc.ur.Create(ctx, user); err != nil {
+			log.Printf("Failed to create user: %v", err)
+			return nil, err
+		}
+	}
+
+	if err = ouc.ir.Create(ctx, model.Identity{
+		ID:       uuid.New(),
+		UserID:   user.ID,
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}); err != nil {
+		log.Printf("Failed to create identity: %v", err)
+		return nil, err
+	}
+	return &user, nil
+}
+
+// displayNameFromClaims はIDプロバイダの表示名を、ない場合はメールアドレスから作った名前を返します。
+// Userテーブルのnameに収まるよう、MaxDisplayNameLength文字までに切り詰めます。
+func displayNameFromClaims(claims *oidc.Claims) string {
+	name := claims.Name
+	if name == "" {
+		name = auth.ExtractUsernameFromEmail(claims.Email)
+	}
+	if utf8.RuneCountInString(name) > MaxDisplayNameLength {
+		name = string([]rune(name)[:MaxDisplayNameLength])
+	}
+	return name
+}
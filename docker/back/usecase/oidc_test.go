@@ -0,0 +1,316 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository/mock"
+	"github.com/tusmasoma/campfinder/docker/back/internal/auth"
+	"github.com/tusmasoma/campfinder/docker/back/internal/oidc"
+	oidcmock "github.com/tusmasoma/campfinder/docker/back/internal/oidc/mock"
+)
+
+func TestOIDCUseCase_StartLogin(t *testing.T) {
+	patterns := []struct {
+		name     string
+		provider string
+		setup    func(p *oidcmock.MockProvider, sr *mock.MockOIDCStateCacheRepository)
+		wantErr  error
+	}{
+		{
+			name:     "success",
+			provider: "google",
+			setup: func(p *oidcmock.MockProvider, sr *mock.MockOIDCStateCacheRepository) {
+				var saved model.OIDCAuthState
+				sr.EXPECT().SetOIDCState(gomock.Any(), gomock.Any(), gomock.Any(), OIDCStateTTL).DoAndReturn(
+					func(_ context.Context, _ string, authState model.OIDCAuthState, _ interface{}) error {
+						saved = authState
+						return nil
+					},
+				)
+				// 保存したcode_verifierに対応するcode_challengeを送る
+				p.EXPECT().AuthCodeURL(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, state, nonce, codeChallenge string) (string, error) {
+						if saved.Provider != "google" || saved.Nonce != nonce || oidc.CodeChallenge(saved.CodeVerifier) != codeChallenge {
+							t.Errorf("unexpected authorization request: state=%v saved=%+v", state, saved)
+						}
+						return "https://idp.example/authorize?state=" + state, nil
+					},
+				)
+			},
+		},
+		{
+			name:     "Fail: unknown provider",
+			provider: "github",
+			wantErr:  oidc.ErrUnknownProvider,
+		},
+	}
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			p := oidcmock.NewMockProvider(ctrl)
+			p.EXPECT().Name().Return("google").AnyTimes()
+			sr := mock.NewMockOIDCStateCacheRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(p, sr)
+			}
+
+			usecase := NewOIDCUseCase(
+				oidc.Providers{"google": p},
+				mock.NewMockUserRepository(ctrl),
+				mock.NewMockIdentityRepository(ctrl),
+				mock.NewMockUserCacheRepository(ctrl),
+				sr,
+				mock.NewMockTwoFactorCacheRepository(ctrl),
+			)
+			authURL, err := usecase.StartLogin(context.Background(), tt.provider)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("StartLogin() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && authURL == "" {
+				t.Error("StartLogin() returned empty url")
+			}
+		})
+	}
+}
+
+func TestOIDCUseCase_CompleteLogin(t *testing.T) {
+	userID := uuid.New()
+	authState := &model.OIDCAuthState{Provider: "google", Nonce: "nonce", CodeVerifier: "verifier"}
+	claims := &oidc.Claims{Subject: "sub-1", Email: "test@gmail.com", EmailVerified: true, Name: "Test"}
+	identityQuery := []repository.QueryCondition{{Field: "Provider", Value: "google"}, {Field: "Subject", Value: "sub-1"}}
+	emailQuery := []repository.QueryCondition{{Field: "Email", Value: "test@gmail.com"}}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			p *oidcmock.MockProvider,
+			ur *mock.MockUserRepository,
+			ir *mock.MockIdentityRepository,
+			cr *mock.MockUserCacheRepository,
+			sr *mock.MockOIDCStateCacheRepository,
+		)
+		wantErr error
+	}{
+		{
+			name: "success: linked identity",
+			setup: func(
+				p *oidcmock.MockProvider,
+				ur *mock.MockUserRepository,
+				ir *mock.MockIdentityRepository,
+				cr *mock.MockUserCacheRepository,
+				sr *mock.MockOIDCStateCacheRepository,
+			) {
+				t.Setenv("PRIVATE_KEY_PATH", "../../../.certificate/private_key.pem")
+				t.Setenv("PUBLIC_KEY_PATH", "../../../.certificate/public_key.pem")
+				sr.EXPECT().ConsumeOIDCState(gomock.Any(), "state").Return(authState, nil)
+				p.EXPECT().Exchange(gomock.Any(), "code", "verifier", "nonce").Return(claims, nil)
+				ir.EXPECT().List(gomock.Any(), identityQuery).Return([]model.Identity{{UserID: userID}}, nil)
+				ur.EXPECT().Get(gomock.Any(), userID.String()).Return(&model.User{ID: userID, Email: "test@gmail.com"}, nil)
+				cr.EXPECT().SetRefreshToken(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				cr.EXPECT().SetRefreshTokenFamily(gomock.Any(), gomock.Any(), gomock.Any(), auth.RefreshTokenTTL).Return(nil)
+				cr.EXPECT().SetSession(gomock.Any(), gomock.Any(), auth.RefreshTokenTTL).Return(nil)
+			},
+		},
+		{
+			name: "success: link verified email to existing user",
+			setup: func(
+				p *oidcmock.MockProvider,
+				ur *mock.MockUserRepository,
+				ir *mock.MockIdentityRepository,
+				cr *mock.MockUserCacheRepository,
+				sr *mock.MockOIDCStateCacheRepository,
+			) {
+				t.Setenv("PRIVATE_KEY_PATH", "../../../.certificate/private_key.pem")
+				t.Setenv("PUBLIC_KEY_PATH", "../../../.certificate/public_key.pem")
+				sr.EXPECT().ConsumeOIDCState(gomock.Any(), "state").Return(authState, nil)
+				p.EXPECT().Exchange(gomock.Any(), "code", "verifier", "nonce").Return(claims, nil)
+				ir.EXPECT().List(gomock.Any(), identityQuery).Return(nil, nil)
+				ur.EXPECT().List(gomock.Any(), emailQuery).Return(
+					[]model.User{{ID: userID, Email: "test@gmail.com", EmailVerified: true}}, nil,
+				)
+				ir.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, identity model.Identity) error {
+						if identity.UserID != userID || identity.Provider != "google" || identity.Subject != "sub-1" {
+							t.Errorf("unexpected identity: %+v", identity)
+						}
+						return nil
+					},
+				)
+				cr.EXPECT().SetRefreshToken(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				cr.EXPECT().SetRefreshTokenFamily(gomock.Any(), gomock.Any(), gomock.Any(), auth.RefreshTokenTTL).Return(nil)
+				cr.EXPECT().SetSession(gomock.Any(), gomock.Any(), auth.RefreshTokenTTL).Return(nil)
+			},
+		},
+		{
+			name: "success: create user",
+			setup: func(
+				p *oidcmock.MockProvider,
+				ur *mock.MockUserRepository,
+				ir *mock.MockIdentityRepository,
+				cr *mock.MockUserCacheRepository,
+				sr *mock.MockOIDCStateCacheRepository,
+			) {
+				t.Setenv("PRIVATE_KEY_PATH", "../../../.certificate/private_key.pem")
+				t.Setenv("PUBLIC_KEY_PATH", "../../../.certificate/public_key.pem")
+				sr.EXPECT().ConsumeOIDCState(gomock.Any(), "state").Return(authState, nil)
+				p.EXPECT().Exchange(gomock.Any(), "code", "verifier", "nonce").Return(claims, nil)
+				ir.EXPECT().List(gomock.Any(), identityQuery).Return(nil, nil)
+				ur.EXPECT().List(gomock.Any(), emailQuery).Return(nil, nil)
+				var created model.User
+				ur.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, user model.User) error {
+						// パスワードを持たず、IDプロバイダが確認したメールアドレスは確認済みとする
+						if user.Password != "" || !user.EmailVerified || user.Name != "Test" || user.Role != model.RoleUser {
+							t.Errorf("unexpected user: %+v", user)
+						}
+						created = user
+						return nil
+					},
+				)
+				ir.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, identity model.Identity) error {
+						if identity.UserID != created.ID {
+							t.Errorf("identity.UserID = %v, want %v", identity.UserID, created.ID)
+						}
+						return nil
+					},
+				)
+				cr.EXPECT().SetRefreshToken(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				cr.EXPECT().SetRefreshTokenFamily(gomock.Any(), gomock.Any(), gomock.Any(), auth.RefreshTokenTTL).Return(nil)
+				cr.EXPECT().SetSession(gomock.Any(), gomock.Any(), auth.RefreshTokenTTL).Return(nil)
+			},
+		},
+		{
+			name: "Fail: unverified email of existing user",
+			setup: func(
+				p *oidcmock.MockProvider,
+				ur *mock.MockUserRepository,
+				ir *mock.MockIdentityRepository,
+				_ *mock.MockUserCacheRepository,
+				sr *mock.MockOIDCStateCacheRepository,
+			) {
+				sr.EXPECT().ConsumeOIDCState(gomock.Any(), "state").Return(authState, nil)
+				p.EXPECT().Exchange(gomock.Any(), "code", "verifier", "nonce").Return(
+					&oidc.Claims{Subject: "sub-1", Email: "test@gmail.com"}, nil,
+				)
+				ir.EXPECT().List(gomock.Any(), identityQuery).Return(nil, nil)
+				ur.EXPECT().List(gomock.Any(), emailQuery).Return(
+					[]model.User{{ID: userID, Email: "test@gmail.com", EmailVerified: true}}, nil,
+				)
+			},
+			wantErr: ErrOIDCAccountConflict,
+		},
+		{
+			name: "Fail: state not found",
+			setup: func(
+				_ *oidcmock.MockProvider,
+				_ *mock.MockUserRepository,
+				_ *mock.MockIdentityRepository,
+				_ *mock.MockUserCacheRepository,
+				sr *mock.MockOIDCStateCacheRepository,
+			) {
+				sr.EXPECT().ConsumeOIDCState(gomock.Any(), "state").Return(nil, repository.ErrCacheMiss)
+			},
+			wantErr: ErrInvalidOIDCState,
+		},
+		{
+			name: "Fail: state issued for another provider",
+			setup: func(
+				_ *oidcmock.MockProvider,
+				_ *mock.MockUserRepository,
+				_ *mock.MockIdentityRepository,
+				_ *mock.MockUserCacheRepository,
+				sr *mock.MockOIDCStateCacheRepository,
+			) {
+				sr.EXPECT().ConsumeOIDCState(gomock.Any(), "state").Return(&model.OIDCAuthState{Provider: "github"}, nil)
+			},
+			wantErr: ErrInvalidOIDCState,
+		},
+		{
+			name: "Fail: invalid id token",
+			setup: func(
+				p *oidcmock.MockProvider,
+				_ *mock.MockUserRepository,
+				_ *mock.MockIdentityRepository,
+				_ *mock.MockUserCacheRepository,
+				sr *mock.MockOIDCStateCacheRepository,
+			) {
+				sr.EXPECT().ConsumeOIDCState(gomock.Any(), "state").Return(authState, nil)
+				p.EXPECT().Exchange(gomock.Any(), "code", "verifier", "nonce").Return(nil, oidc.ErrInvalidIDToken)
+			},
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+	}
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			p := oidcmock.NewMockProvider(ctrl)
+			p.EXPECT().Name().Return("google").AnyTimes()
+			ur := mock.NewMockUserRepository(ctrl)
+			ir := mock.NewMockIdentityRepository(ctrl)
+			cr := mock.NewMockUserCacheRepository(ctrl)
+			sr := mock.NewMockOIDCStateCacheRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(p, ur, ir, cr, sr)
+			}
+
+			usecase := NewOIDCUseCase(oidc.Providers{"google": p}, ur, ir, cr, sr, mock.NewMockTwoFactorCacheRepository(ctrl))
+			tokens, err := usecase.CompleteLogin(context.Background(), "google", "state", "code", Device{})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CompleteLogin() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (tokens.AccessToken == "" || tokens.RefreshToken == "") {
+				t.Error("Failed to generate token")
+			}
+		})
+	}
+}
+
+func Test_displayNameFromClaims(t *testing.T) {
+	t.Parallel()
+	patterns := []struct {
+		name   string
+		claims *oidc.Claims
+		want   string
+	}{
+		{
+			name:   "provider name",
+			claims: &oidc.Claims{Email: "test@gmail.com", Name: "Test"},
+			want:   "Test",
+		},
+		{
+			name:   "fallback to email",
+			claims: &oidc.Claims{Email: "test@gmail.com"},
+			want:   "test",
+		},
+		{
+			// Userテーブルのnameに収まるよう文字数で切り詰める
+			name:   "long provider name",
+			claims: &oidc.Claims{Email: "test@gmail.com", Name: strings.Repeat("あ", MaxDisplayNameLength+10)},
+			want:   strings.Repeat("あ", MaxDisplayNameLength),
+		},
+	}
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			if got := displayNameFromClaims(tt.claims); got != tt.want {
+				t.Errorf("displayNameFromClaims() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
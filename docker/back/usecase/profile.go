@@ -0,0 +1,230 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+	"github.com/tusmasoma/campfinder/docker/back/internal/auth"
+	"github.com/tusmasoma/campfinder/docker/back/internal/storage"
+)
+
+const (
+	// DefaultReviewsOrderBy は公開プロフィールの口コミを新しい順に並べるための既定の並び順です。
+	DefaultReviewsOrderBy = "-created"
+	// MaxDisplayNameLength は表示名の最大文字数で、Userテーブルのnameの長さに合わせています。
+	MaxDisplayNameLength = 50
+)
+
+// ProfileUseCase はログインしているユーザ自身のプロフィールとアカウントの操作、および他のユーザの公開プロフィールです。
+type ProfileUseCase interface {
+	UpdateProfile(ctx context.Context, userID string, params *UpdateProfileParams) (*model.User, error)
+	ChangePassword(ctx context.Context, userID string, sessionID string, currentPassword string, newPassword string) error
+	DeleteAccount(ctx context.Context, userID string, password string) error
+	GetPublicProfile(ctx context.Context, userID string, params *ListReviewsParams) (*PublicProfile, error)
+}
+
+type profileUseCase struct {
+	ur  repository.UserRepository
+	ucr repository.UserCacheRepository
+	cr  repository.CommentRepository
+	ir  repository.ImageRepository
+	sr  repository.SpotRepository
+	cc  repository.CommentsCacheRepository
+	ic  repository.ImagesCacheRepository
+	bs  storage.BlobStore
+}
+
+func NewProfileUseCase(
+	ur repository.UserRepository,
+	ucr repository.UserCacheRepository,
+	cr repository.CommentRepository,
+	ir repository.ImageRepository,
+	sr repository.SpotRepository,
+	cc repository.CommentsCacheRepository,
+	ic repository.ImagesCacheRepository,
+	bs storage.BlobStore,
+) ProfileUseCase {
+	return &profileUseCase{
+		ur:  ur,
+		ucr: ucr,
+		cr:  cr,
+		ir:  ir,
+		sr:  sr,
+		cc:  cc,
+		ic:  ic,
+		bs:  bs,
+	}
+}
+
+// UpdateProfileParams はプロフィールの変更内容です。PUTで受け取るため、すべての項目を置き換えます。
+type UpdateProfileParams struct {
+	Name      string
+	AvatarURL string
+}
+
+func (puc *profileUseCase) UpdateProfile(
+	ctx context.Context,
+	userID string,
+	params *UpdateProfileParams,
+) (*model.User, error) {
+	user, err := puc.ur.Get(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to get user: %v", err)
+		return nil, err
+	}
+	user.Name = params.Name
+	user.AvatarURL = params.AvatarURL
+	if err = puc.ur.Update(ctx, userID, *user); err != nil {
+		log.Printf("Failed to update user: %v", err)
+		return nil, err
+	}
+	return user, nil
+}
+
+// ChangePassword は現在のパスワードを確認してからパスワードを変更し、リクエストした端末以外のセッションを失効させます。
+// パスワードを持たない外部ログインのユーザは、パスワードの再設定でパスワードを設定します。
+func (puc *profileUseCase) ChangePassword(
+	ctx context.Context,
+	userID string,
+	sessionID string,
+	currentPassword string,
+	newPassword string,
+) error {
+	user, err := puc.ur.Get(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to get user: %v", err)
+		return err
+	}
+	if err = auth.CompareHashAndPassword(user.Password, currentPassword); err != nil {
+		return ErrIncorrectPassword
+	}
+
+	hashed, err := auth.PasswordEncrypt(newPassword)
+	if err != nil {
+		log.Printf("Internal server error: %v", err)
+		return err
+	}
+	user.Password = hashed
+	if err = puc.ur.Update(ctx, userID, *user); err != nil {
+		log.Printf("Failed to update user: %v", err)
+		return err
+	}
+	return revokeSessions(ctx, puc.ucr, userID, sessionID)
+}
+
+// DeleteAccount はパスワードを確認してからユーザを削除します。パスワードを持たない外部ログインのユーザは確認を省略します。
+// ユーザの口コミと画像は外部キーのON DELETE CASCADEで削除されるため、削除した口コミの評価をSpotの集計から除き、
+// 口コミと画像のキャッシュを削除します。
+func (puc *profileUseCase) DeleteAccount(ctx context.Context, userID string, password string) error {
+	user, err := puc.ur.Get(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to get user: %v", err)
+		return err
+	}
+	if user.Password != "" {
+		if err = auth.CompareHashAndPassword(user.Password, password); err != nil {
+			return ErrIncorrectPassword
+		}
+	}
+
+	qcs := []repository.QueryCondition{{Field: "user_id", Value: userID}}
+	comments, err := puc.cr.List(ctx, qcs)
+	if err != nil {
+		log.Printf("Failed to list comments of %v: %v", userID, err)
+		return err
+	}
+	images, err := puc.ir.List(ctx, qcs)
+	if err != nil {
+		log.Printf("Failed to list images of %v: %v", userID, err)
+		return err
+	}
+
+	if err = puc.ur.Delete(ctx, userID); err != nil {
+		log.Printf("Failed to delete user: %v", err)
+		return err
+	}
+
+	// ユーザは削除済みのため、以降は失敗しても処理を続ける
+	for _, comment := range comments {
+		// 返信は評価を持たず、Spotの集計に含まれていない
+		if !comment.IsReply() {
+			if err = puc.sr.AdjustRating(ctx, comment.SpotID.String(), comment.StarRate, -1); err != nil {
+				log.Printf("Failed to adjust rating of spot %v: %v", comment.SpotID, err)
+			}
+		}
+		if err = puc.cc.Delete(ctx, "comments_"+comment.SpotID.String()); err != nil {
+			log.Printf("Failed to delete comments cache of %v: %v", comment.SpotID, err)
+		}
+	}
+	// 画像の行はユーザとあわせて削除されるため、アップロードされた画像と縮小版のファイルもここで削除する
+	for _, img := range images {
+		deleteBlobs(ctx, puc.bs, imageKeys(&img))
+		if err = puc.ic.Delete(ctx, "images_"+img.SpotID.String()); err != nil {
+			log.Printf("Failed to delete images cache of %v: %v", img.SpotID, err)
+		}
+	}
+	if err = revokeSessions(ctx, puc.ucr, userID, ""); err != nil {
+		log.Printf("Failed to revoke sessions of deleted user %v: %v", userID, err)
+	}
+	return nil
+}
+
+type ListReviewsParams struct {
+	Limit   int
+	Cursor  string
+	OrderBy string
+}
+
+// PublicProfile は他のユーザにも公開するプロフィールと、そのユーザが投稿した口コミです。
+type PublicProfile struct {
+	User        model.User
+	Reviews     []model.Comment
+	NextCursor  string
+	ReviewCount int
+}
+
+func (puc *profileUseCase) GetPublicProfile(
+	ctx context.Context,
+	userID string,
+	params *ListReviewsParams,
+) (*PublicProfile, error) {
+	user, err := puc.ur.Get(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			log.Printf("Failed to get user: %v", err)
+		}
+		return nil, err
+	}
+
+	// 返信は口コミとして数えない
+	qcs := []repository.QueryCondition{
+		{Field: "user_id", Value: userID},
+		{Field: "parent_id", Operator: repository.OpIsNull, Value: true},
+	}
+	orderBy := params.OrderBy
+	if orderBy == "" {
+		orderBy = DefaultReviewsOrderBy
+	}
+	reviews, nextCursor, err := puc.cr.ListPage(ctx, qcs, repository.ListOptions{
+		Limit:   params.Limit,
+		Cursor:  params.Cursor,
+		OrderBy: orderBy,
+	})
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return nil, err
+	}
+	if err != nil {
+		log.Printf("Failed to get comments of %v: %v", userID, err)
+		return nil, err
+	}
+	count, err := puc.cr.Count(ctx, qcs)
+	if err != nil {
+		log.Printf("Failed to count comments of %v: %v", userID, err)
+		return nil, err
+	}
+	return &PublicProfile{User: *user, Reviews: reviews, NextCursor: nextCursor, ReviewCount: count}, nil
+}
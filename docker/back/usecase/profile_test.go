@@ -0,0 +1,278 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository/mock"
+	"github.com/tusmasoma/campfinder/docker/back/internal/auth"
+	storagemock "github.com/tusmasoma/campfinder/docker/back/internal/storage/mock"
+)
+
+type profileMocks struct {
+	ur  *mock.MockUserRepository
+	ucr *mock.MockUserCacheRepository
+	cr  *mock.MockCommentRepository
+	ir  *mock.MockImageRepository
+	sr  *mock.MockSpotRepository
+	cc  *mock.MockCommentsCacheRepository
+	ic  *mock.MockImagesCacheRepository
+	bs  *storagemock.MockBlobStore
+}
+
+func newProfileUseCaseWithMocks(ctrl *gomock.Controller) (ProfileUseCase, *profileMocks) {
+	m := &profileMocks{
+		ur:  mock.NewMockUserRepository(ctrl),
+		ucr: mock.NewMockUserCacheRepository(ctrl),
+		cr:  mock.NewMockCommentRepository(ctrl),
+		ir:  mock.NewMockImageRepository(ctrl),
+		sr:  mock.NewMockSpotRepository(ctrl),
+		cc:  mock.NewMockCommentsCacheRepository(ctrl),
+		ic:  mock.NewMockImagesCacheRepository(ctrl),
+		bs:  storagemock.NewMockBlobStore(ctrl),
+	}
+	return NewProfileUseCase(m.ur, m.ucr, m.cr, m.ir, m.sr, m.cc, m.ic, m.bs), m
+}
+
+func TestProfileUseCase_UpdateProfile(t *testing.T) {
+	userID := uuid.New()
+
+	ctrl := gomock.NewController(t)
+	usecase, m := newProfileUseCaseWithMocks(ctrl)
+	m.ur.EXPECT().Get(gomock.Any(), userID.String()).Return(
+		&model.User{ID: userID, Name: "test", Email: "test@gmail.com", AvatarURL: "https://example.com/old.png"}, nil,
+	)
+	want := model.User{ID: userID, Name: "Camper", Email: "test@gmail.com"}
+	m.ur.EXPECT().Update(gomock.Any(), userID.String(), want).Return(nil)
+
+	got, err := usecase.UpdateProfile(context.Background(), userID.String(), &UpdateProfileParams{Name: "Camper"})
+	if err != nil {
+		t.Fatalf("UpdateProfile() error = %v", err)
+	}
+	if *got != want {
+		t.Errorf("UpdateProfile() = %+v, want %+v", *got, want)
+	}
+}
+
+func TestProfileUseCase_ChangePassword(t *testing.T) {
+	userID := uuid.New()
+	hashed, _ := auth.PasswordEncrypt("password123")
+
+	patterns := []struct {
+		name    string
+		setup   func(m *profileMocks)
+		current string
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(m *profileMocks) {
+				m.ur.EXPECT().Get(gomock.Any(), userID.String()).Return(&model.User{ID: userID, Password: hashed}, nil)
+				m.ur.EXPECT().Update(gomock.Any(), userID.String(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ string, user model.User) error {
+						if err := auth.CompareHashAndPassword(user.Password, "newpassword"); err != nil {
+							t.Errorf("password was not changed: %v", err)
+						}
+						return nil
+					},
+				)
+				// リクエストした端末のセッションは残す
+				m.ucr.EXPECT().ListSessions(gomock.Any(), userID.String()).Return(
+					[]model.Session{{ID: "current"}, {ID: "other"}}, nil,
+				)
+				m.ucr.EXPECT().DeleteSession(gomock.Any(), userID.String(), "other").Return(nil)
+				m.ucr.EXPECT().DeleteRefreshTokenFamily(gomock.Any(), "other").Return(nil)
+			},
+			current: "password123",
+		},
+		{
+			name: "Fail: incorrect current password",
+			setup: func(m *profileMocks) {
+				m.ur.EXPECT().Get(gomock.Any(), userID.String()).Return(&model.User{ID: userID, Password: hashed}, nil)
+			},
+			current: "wrong",
+			wantErr: ErrIncorrectPassword,
+		},
+		{
+			name: "Fail: user without password",
+			setup: func(m *profileMocks) {
+				m.ur.EXPECT().Get(gomock.Any(), userID.String()).Return(&model.User{ID: userID}, nil)
+			},
+			current: "password123",
+			wantErr: ErrIncorrectPassword,
+		},
+	}
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			usecase, m := newProfileUseCaseWithMocks(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(m)
+			}
+
+			err := usecase.ChangePassword(context.Background(), userID.String(), "current", tt.current, "newpassword")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ChangePassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProfileUseCase_DeleteAccount(t *testing.T) {
+	userID := uuid.New()
+	spotID := uuid.New()
+	replySpotID := uuid.New()
+	hashed, _ := auth.PasswordEncrypt("password123")
+	qcs := []repository.QueryCondition{{Field: "user_id", Value: userID.String()}}
+
+	patterns := []struct {
+		name     string
+		setup    func(m *profileMocks)
+		password string
+		wantErr  error
+	}{
+		{
+			name: "success",
+			setup: func(m *profileMocks) {
+				m.ur.EXPECT().Get(gomock.Any(), userID.String()).Return(&model.User{ID: userID, Password: hashed}, nil)
+				m.cr.EXPECT().List(gomock.Any(), qcs).Return([]model.Comment{
+					{SpotID: spotID, UserID: userID, StarRate: 4, Kind: model.CommentKindReview},
+					{
+						SpotID:   replySpotID,
+						UserID:   userID,
+						ParentID: uuid.NullUUID{UUID: uuid.New(), Valid: true},
+						Depth:    1,
+						Kind:     model.CommentKindReply,
+					},
+				}, nil)
+				m.ir.EXPECT().List(gomock.Any(), qcs).Return([]model.Image{
+					{SpotID: spotID, UserID: userID, StorageKey: "spots/" + spotID.String() + "/a.png"},
+					{SpotID: spotID, UserID: userID},
+				}, nil)
+				m.ur.EXPECT().Delete(gomock.Any(), userID.String()).Return(nil)
+				// アップロードされた画像は元の画像と縮小版のファイルを削除し、URLだけの画像はファイルを持たない
+				m.bs.EXPECT().Delete(gomock.Any(), "spots/"+spotID.String()+"/a.png").Return(nil)
+				m.bs.EXPECT().Delete(gomock.Any(), "spots/"+spotID.String()+"/a_thumb.jpg").Return(nil)
+				m.bs.EXPECT().Delete(gomock.Any(), "spots/"+spotID.String()+"/a_thumb.webp").Return(nil)
+				m.bs.EXPECT().Delete(gomock.Any(), "spots/"+spotID.String()+"/a_medium.jpg").Return(nil)
+				m.bs.EXPECT().Delete(gomock.Any(), "spots/"+spotID.String()+"/a_medium.webp").Return(nil)
+				m.bs.EXPECT().Delete(gomock.Any(), "spots/"+spotID.String()+"/a_large.jpg").Return(errors.New("storage error"))
+				m.bs.EXPECT().Delete(gomock.Any(), "spots/"+spotID.String()+"/a_large.webp").Return(nil)
+				// 削除された口コミの評価を集計から除き、評価を持たない返信はキャッシュだけ削除する
+				m.sr.EXPECT().AdjustRating(gomock.Any(), spotID.String(), 4.0, -1).Return(nil)
+				m.cc.EXPECT().Delete(gomock.Any(), "comments_"+spotID.String()).Return(nil)
+				m.cc.EXPECT().Delete(gomock.Any(), "comments_"+replySpotID.String()).Return(nil)
+				m.ic.EXPECT().Delete(gomock.Any(), "images_"+spotID.String()).Return(nil).Times(2)
+				m.ucr.EXPECT().ListSessions(gomock.Any(), userID.String()).Return([]model.Session{{ID: "current"}}, nil)
+				m.ucr.EXPECT().DeleteSession(gomock.Any(), userID.String(), "current").Return(nil)
+				m.ucr.EXPECT().DeleteRefreshTokenFamily(gomock.Any(), "current").Return(nil)
+			},
+			password: "password123",
+		},
+		{
+			name: "success: user without password",
+			setup: func(m *profileMocks) {
+				m.ur.EXPECT().Get(gomock.Any(), userID.String()).Return(&model.User{ID: userID}, nil)
+				m.cr.EXPECT().List(gomock.Any(), qcs).Return(nil, nil)
+				m.ir.EXPECT().List(gomock.Any(), qcs).Return(nil, nil)
+				m.ur.EXPECT().Delete(gomock.Any(), userID.String()).Return(nil)
+				m.ucr.EXPECT().ListSessions(gomock.Any(), userID.String()).Return(nil, nil)
+			},
+		},
+		{
+			name: "Fail: incorrect password",
+			setup: func(m *profileMocks) {
+				m.ur.EXPECT().Get(gomock.Any(), userID.String()).Return(&model.User{ID: userID, Password: hashed}, nil)
+			},
+			password: "wrong",
+			wantErr:  ErrIncorrectPassword,
+		},
+	}
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			usecase, m := newProfileUseCaseWithMocks(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(m)
+			}
+
+			err := usecase.DeleteAccount(context.Background(), userID.String(), tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("DeleteAccount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProfileUseCase_GetPublicProfile(t *testing.T) {
+	userID := uuid.New()
+	// 返信を除いたレビューだけを一覧・件数の対象にする
+	qcs := []repository.QueryCondition{
+		{Field: "user_id", Value: userID.String()},
+		{Field: "parent_id", Operator: repository.OpIsNull, Value: true},
+	}
+
+	patterns := []struct {
+		name    string
+		setup   func(m *profileMocks)
+		params  *ListReviewsParams
+		want    *PublicProfile
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(m *profileMocks) {
+				m.ur.EXPECT().Get(gomock.Any(), userID.String()).Return(&model.User{ID: userID, Name: "test"}, nil)
+				m.cr.EXPECT().ListPage(gomock.Any(), qcs, repository.ListOptions{Limit: 1, OrderBy: "-created"}).Return(
+					[]model.Comment{{UserID: userID, StarRate: 5}}, "next", nil,
+				)
+				m.cr.EXPECT().Count(gomock.Any(), qcs).Return(2, nil)
+			},
+			params: &ListReviewsParams{Limit: 1},
+			want: &PublicProfile{
+				User:        model.User{ID: userID, Name: "test"},
+				Reviews:     []model.Comment{{UserID: userID, StarRate: 5}},
+				NextCursor:  "next",
+				ReviewCount: 2,
+			},
+		},
+		{
+			name: "Fail: user not found",
+			setup: func(m *profileMocks) {
+				m.ur.EXPECT().Get(gomock.Any(), userID.String()).Return(nil, repository.ErrNotFound)
+			},
+			params:  &ListReviewsParams{Limit: 1},
+			wantErr: repository.ErrNotFound,
+		},
+	}
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			usecase, m := newProfileUseCaseWithMocks(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(m)
+			}
+
+			got, err := usecase.GetPublicProfile(context.Background(), userID.String(), tt.params)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetPublicProfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.want != nil && (got.User != tt.want.User || got.NextCursor != tt.want.NextCursor ||
+				got.ReviewCount != tt.want.ReviewCount || len(got.Reviews) != len(tt.want.Reviews)) {
+				t.Errorf("GetPublicProfile() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,32 +3,47 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"strings"
-	"sync"
 
 	"github.com/tusmasoma/campfinder/docker/back/domain/model"
 	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+	"github.com/tusmasoma/campfinder/docker/back/internal/geo"
 )
 
 type SpotUseCase interface {
 	CreateSpot(ctx context.Context, params *CreateSpotParams) error
 	BatchCreateSpots(ctx context.Context, params *BatchCreateSpotParams) error
-	ListSpots(ctx context.Context, categories []string) []model.Spot
+	ListSpots(ctx context.Context, params *ListSpotsParams) (*ListSpotsResult, error)
 	GetSpot(ctx context.Context, spotID string) model.Spot
+	GetSpotTile(ctx context.Context, z, x, y int) (model.SpotClusters, error)
+	ListNearbySpots(ctx context.Context, params *ListNearbySpotsParams) ([]model.SpotWithDistance, error)
+	SearchSpots(ctx context.Context, params *SearchSpotsParams) ([]model.SpotWithScore, error)
+	UpdateSpot(ctx context.Context, params *UpdateSpotParams, user model.User) error
+	PatchSpot(ctx context.Context, params *PatchSpotParams, user model.User) error
+	DeleteSpot(ctx context.Context, spotID string, user model.User) error
+	ImportSpots(ctx context.Context, params *ImportSpotsParams) (*ImportSpotsReport, error)
+	ExportSpots(ctx context.Context, params *ExportSpotsParams, fn func(spot model.Spot) error) error
 }
 
 type spotUseCase struct {
-	sr repository.SpotRepository
-	cr repository.SpotsCacheRepository
+	sr  repository.SpotRepository
+	cr  repository.SpotsCacheRepository
+	tcr repository.SpotClustersCacheRepository
 }
 
-func NewSpotUseCase(sr repository.SpotRepository, cr repository.SpotsCacheRepository) SpotUseCase {
+func NewSpotUseCase(
+	sr repository.SpotRepository,
+	cr repository.SpotsCacheRepository,
+	tcr repository.SpotClustersCacheRepository,
+) SpotUseCase {
 	return &spotUseCase{
-		sr: sr,
-		cr: cr,
+		sr:  sr,
+		cr:  cr,
+		tcr: tcr,
 	}
 }
 
@@ -46,18 +61,12 @@ type CreateSpotParams struct {
 }
 
 func (suc *spotUseCase) CreateSpot(ctx context.Context, params *CreateSpotParams) error {
-	spots, err := suc.sr.List(
-		ctx,
-		[]repository.QueryCondition{
-			{Field: "Lat", Value: params.Lat},
-			{Field: "Lng", Value: params.Lng},
-		},
-	)
+	exists, err := suc.existsAt(ctx, params.Lat, params.Lng)
 	if err != nil {
 		log.Printf("Internal server error: %v", err)
 		return err
 	}
-	if len(spots) > 0 {
+	if exists {
 		log.Printf("Spot with this lat,lng already exists - status: %d", http.StatusConflict)
 		return fmt.Errorf("already exists")
 	}
@@ -79,9 +88,25 @@ func (suc *spotUseCase) CreateSpot(ctx context.Context, params *CreateSpotParams
 		log.Printf("Failed to create spot: %v", err)
 		return err
 	}
+	suc.deleteTiles(ctx)
 	return nil
 }
 
+// existsAt は同じ緯度経度のSpotが登録済みかを返します。
+func (suc *spotUseCase) existsAt(ctx context.Context, lat, lng float64) (bool, error) {
+	spots, err := suc.sr.List(
+		ctx,
+		[]repository.QueryCondition{
+			{Field: "Lat", Value: lat},
+			{Field: "Lng", Value: lng},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	return len(spots) > 0, nil
+}
+
 type BatchCreateSpotParams struct {
 	Spots []CreateSpotParams
 }
@@ -107,36 +132,77 @@ func (suc *spotUseCase) BatchCreateSpots(ctx context.Context, params *BatchCreat
 		log.Printf("Failed to batch create spots: %v", err)
 		return err
 	}
+	suc.deleteTiles(ctx)
 	return nil
 }
 
-func (suc *spotUseCase) ListSpots(ctx context.Context, categories []string) []model.Spot {
-	var allSpots []model.Spot
-	var mu sync.Mutex
-	wg := sync.WaitGroup{}
+// ListSpotsParams のMinRatingは0の場合は絞り込みません。
+type ListSpotsParams struct {
+	Categories []string
+	MinRating  float64
+	Limit      int
+	Cursor     string
+	OrderBy    string
+}
 
-	for _, category := range categories {
-		wg.Add(1)
-		go func(category string) {
-			defer wg.Done()
-
-			spots, err := suc.sr.List(ctx, []repository.QueryCondition{{Field: "Category", Value: category}})
-			if err != nil {
-				log.Printf("Failed to get spot of %v: %v", category, err)
-				spots = suc.getMasterData(ctx, category)
-			} else {
-				if cacheErr := suc.setMasterData(ctx, category, spots); cacheErr != nil {
-					log.Printf("Failed to set master data of %v: %v", category, err)
-				}
-			}
-			mu.Lock()
-			allSpots = append(allSpots, spots...)
-			mu.Unlock()
-		}(category)
+type ListSpotsResult struct {
+	Spots      []model.Spot
+	NextCursor string
+	Total      int
+}
+
+func (suc *spotUseCase) ListSpots(ctx context.Context, params *ListSpotsParams) (*ListSpotsResult, error) {
+	var qcs []repository.QueryCondition
+	if len(params.Categories) > 0 {
+		qcs = append(qcs, repository.QueryCondition{Field: "category", Operator: repository.OpIn, Value: params.Categories})
+	}
+	if params.MinRating > 0 {
+		qcs = append(qcs, repository.QueryCondition{Field: "rating_average", Operator: repository.OpGte, Value: params.MinRating})
 	}
 
-	wg.Wait()
-	return allSpots
+	spots, nextCursor, err := suc.sr.ListPage(ctx, qcs, repository.ListOptions{
+		Limit:   params.Limit,
+		Cursor:  params.Cursor,
+		OrderBy: params.OrderBy,
+	})
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return nil, err
+	}
+	if err != nil {
+		log.Printf("Failed to get spots of %v: %v", params.Categories, err)
+		// 先頭ページのみキャッシュのマスターデータで代替する
+		if params.Cursor != "" {
+			return nil, err
+		}
+		spots = filterByMinRating(suc.listMasterData(ctx, params.Categories), params.MinRating)
+		return &ListSpotsResult{Spots: spots, Total: len(spots)}, nil
+	}
+
+	total, err := suc.sr.Count(ctx, qcs)
+	if err != nil {
+		log.Printf("Failed to count spots of %v: %v", params.Categories, err)
+		return nil, err
+	}
+
+	// 評価で絞り込まず、全件が1ページに収まっている場合のみ、カテゴリごとのマスターデータを更新する
+	if params.MinRating == 0 && params.Cursor == "" && nextCursor == "" {
+		suc.setMasterDataByCategory(ctx, params.Categories, spots)
+	}
+
+	return &ListSpotsResult{Spots: spots, NextCursor: nextCursor, Total: total}, nil
+}
+
+func filterByMinRating(spots []model.Spot, minRating float64) []model.Spot {
+	if minRating <= 0 {
+		return spots
+	}
+	var filtered []model.Spot
+	for _, spot := range spots {
+		if spot.RatingAverage >= minRating {
+			filtered = append(filtered, spot)
+		}
+	}
+	return filtered
 }
 
 func (suc *spotUseCase) GetSpot(ctx context.Context, spotID string) model.Spot {
@@ -144,18 +210,7 @@ func (suc *spotUseCase) GetSpot(ctx context.Context, spotID string) model.Spot {
 	if err != nil {
 		log.Printf("Failed to get spot of %v: %v", spotID, err)
 
-		var allSpots []model.Spot
-		keys, scanErr := suc.cr.Scan(ctx, "spots_*")
-		if scanErr != nil {
-			log.Printf("Failed to scan cache: %v", scanErr)
-			return model.Spot{}
-		}
-		for _, key := range keys {
-			category := strings.TrimPrefix(key, "spots_")
-			spots := suc.getMasterData(ctx, category)
-			allSpots = append(allSpots, spots...)
-		}
-		for _, spot := range allSpots {
+		for _, spot := range suc.listMasterData(ctx, nil) {
 			if spot.ID.String() == spotID {
 				return spot
 			}
@@ -165,6 +220,207 @@ func (suc *spotUseCase) GetSpot(ctx context.Context, spotID string) model.Spot {
 	return *spot
 }
 
+// ListNearbySpotsParams はBoundsが指定されていれば矩形検索、なければ中心点と半径での検索を表します。
+type ListNearbySpotsParams struct {
+	Lat      float64
+	Lng      float64
+	RadiusKm float64
+	Bounds   *model.BoundingBox
+}
+
+func (suc *spotUseCase) ListNearbySpots(
+	ctx context.Context,
+	params *ListNearbySpotsParams,
+) ([]model.SpotWithDistance, error) {
+	if params.Bounds != nil {
+		spots, err := suc.sr.ListInBounds(ctx, *params.Bounds)
+		if err != nil {
+			log.Printf("Failed to list spots in bounds: %v", err)
+			return nil, err
+		}
+		return spots, nil
+	}
+
+	spots, err := suc.sr.ListNearby(ctx, params.Lat, params.Lng, params.RadiusKm)
+	if err != nil {
+		log.Printf("Failed to list nearby spots: %v", err)
+		return nil, err
+	}
+	return spots, nil
+}
+
+// SearchSpotsParams はKeywordでの全文検索に加え、Categoriesと位置(BoundsまたはLat, Lng, RadiusKm)で絞り込む条件を表します。
+type SearchSpotsParams struct {
+	Keyword    string
+	Categories []string
+	Lat        float64
+	Lng        float64
+	RadiusKm   float64
+	Bounds     *model.BoundingBox
+	Limit      int
+}
+
+func (suc *spotUseCase) SearchSpots(ctx context.Context, params *SearchSpotsParams) ([]model.SpotWithScore, error) {
+	query := repository.SpotSearchQuery{
+		Keyword:    params.Keyword,
+		Categories: params.Categories,
+		Bounds:     params.Bounds,
+		Limit:      params.Limit,
+	}
+	if query.Bounds == nil && params.RadiusKm > 0 {
+		bounds := geo.BoundsAround(params.Lat, params.Lng, params.RadiusKm)
+		query.Bounds = &bounds
+	}
+
+	spots, err := suc.sr.Search(ctx, query)
+	if err != nil {
+		log.Printf("Failed to search spots by %v: %v", params.Keyword, err)
+		return nil, err
+	}
+	if params.Bounds != nil || params.RadiusKm <= 0 {
+		return spots, nil
+	}
+
+	// 矩形の四隅は半径の外側になるため、距離で絞り込む
+	results := make([]model.SpotWithScore, 0, len(spots))
+	for _, spot := range spots {
+		if geo.Distance(params.Lat, params.Lng, spot.Lat, spot.Lng) <= params.RadiusKm {
+			results = append(results, spot)
+		}
+	}
+	return results, nil
+}
+
+type UpdateSpotParams struct {
+	ID          string
+	Category    string
+	Name        string
+	Address     string
+	Lat         float64
+	Lng         float64
+	Period      string
+	Phone       string
+	Price       string
+	Description string
+	IconPath    string
+}
+
+func (suc *spotUseCase) UpdateSpot(ctx context.Context, params *UpdateSpotParams, user model.User) error {
+	if !user.HasRole(model.RoleAdmin) {
+		log.Print("Don't have permission to update spot")
+		return ErrPermissionDenied
+	}
+
+	current, err := suc.sr.Get(ctx, params.ID)
+	if err != nil {
+		log.Printf("Failed to get spot of %v: %v", params.ID, err)
+		return err
+	}
+
+	spot := model.Spot{
+		ID:          current.ID,
+		Category:    params.Category,
+		Name:        params.Name,
+		Address:     params.Address,
+		Lat:         params.Lat,
+		Lng:         params.Lng,
+		Period:      params.Period,
+		Phone:       params.Phone,
+		Price:       params.Price,
+		Description: params.Description,
+		IconPath:    params.IconPath,
+	}
+	if err = suc.sr.Update(ctx, params.ID, spot); err != nil {
+		log.Printf("Failed to update spot: %v", err)
+		return err
+	}
+
+	suc.deleteMasterData(ctx, current.Category, spot.Category)
+	suc.deleteTiles(ctx)
+	return nil
+}
+
+// PatchSpotParams はnilでないフィールドのみを更新します。
+type PatchSpotParams struct {
+	ID          string
+	Category    *string
+	Name        *string
+	Address     *string
+	Lat         *float64
+	Lng         *float64
+	Period      *string
+	Phone       *string
+	Price       *string
+	Description *string
+	IconPath    *string
+}
+
+func (suc *spotUseCase) PatchSpot(ctx context.Context, params *PatchSpotParams, user model.User) error {
+	if !user.HasRole(model.RoleAdmin) {
+		log.Print("Don't have permission to patch spot")
+		return ErrPermissionDenied
+	}
+
+	current, err := suc.sr.Get(ctx, params.ID)
+	if err != nil {
+		log.Printf("Failed to get spot of %v: %v", params.ID, err)
+		return err
+	}
+
+	spot := *current
+	patchString(&spot.Category, params.Category)
+	patchString(&spot.Name, params.Name)
+	patchString(&spot.Address, params.Address)
+	patchString(&spot.Period, params.Period)
+	patchString(&spot.Phone, params.Phone)
+	patchString(&spot.Price, params.Price)
+	patchString(&spot.Description, params.Description)
+	patchString(&spot.IconPath, params.IconPath)
+	if params.Lat != nil {
+		spot.Lat = *params.Lat
+	}
+	if params.Lng != nil {
+		spot.Lng = *params.Lng
+	}
+
+	if err = suc.sr.Update(ctx, params.ID, spot); err != nil {
+		log.Printf("Failed to patch spot: %v", err)
+		return err
+	}
+
+	suc.deleteMasterData(ctx, current.Category, spot.Category)
+	suc.deleteTiles(ctx)
+	return nil
+}
+
+func patchString(dst *string, src *string) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func (suc *spotUseCase) DeleteSpot(ctx context.Context, spotID string, user model.User) error {
+	if !user.HasRole(model.RoleAdmin) {
+		log.Print("Don't have permission to delete spot")
+		return ErrPermissionDenied
+	}
+
+	current, err := suc.sr.Get(ctx, spotID)
+	if err != nil {
+		log.Printf("Failed to get spot of %v: %v", spotID, err)
+		return err
+	}
+
+	if err = suc.sr.Delete(ctx, spotID); err != nil {
+		log.Printf("Failed to delete spot: %v", err)
+		return err
+	}
+
+	suc.deleteMasterData(ctx, current.Category)
+	suc.deleteTiles(ctx)
+	return nil
+}
+
 func (suc *spotUseCase) getMasterData(ctx context.Context, category string) []model.Spot {
 	spots, cacheErr := suc.cr.Get(ctx, "spots_"+category)
 	if cacheErr != nil {
@@ -177,3 +433,49 @@ func (suc *spotUseCase) getMasterData(ctx context.Context, category string) []mo
 func (suc *spotUseCase) setMasterData(ctx context.Context, category string, spots []model.Spot) error {
 	return suc.cr.Set(ctx, "spots_"+category, spots)
 }
+
+// deleteMasterData は変更のあったカテゴリのマスターデータを削除します。次回のListSpotsでDBから再作成されます。
+func (suc *spotUseCase) deleteMasterData(ctx context.Context, categories ...string) {
+	deleted := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		if deleted[category] {
+			continue
+		}
+		deleted[category] = true
+		if err := suc.cr.Delete(ctx, "spots_"+category); err != nil {
+			log.Printf("Failed to delete master data of %v: %v", category, err)
+		}
+	}
+}
+
+// listMasterData はキャッシュから指定カテゴリのSpotを取得します。カテゴリ未指定の場合はキャッシュ済みの全カテゴリが対象です。
+func (suc *spotUseCase) listMasterData(ctx context.Context, categories []string) []model.Spot {
+	if len(categories) == 0 {
+		keys, err := suc.cr.Scan(ctx, "spots_*")
+		if err != nil {
+			log.Printf("Failed to scan cache: %v", err)
+			return nil
+		}
+		for _, key := range keys {
+			categories = append(categories, strings.TrimPrefix(key, "spots_"))
+		}
+	}
+
+	var allSpots []model.Spot
+	for _, category := range categories {
+		allSpots = append(allSpots, suc.getMasterData(ctx, category)...)
+	}
+	return allSpots
+}
+
+func (suc *spotUseCase) setMasterDataByCategory(ctx context.Context, categories []string, spots []model.Spot) {
+	spotsByCategory := make(map[string][]model.Spot, len(categories))
+	for _, spot := range spots {
+		spotsByCategory[spot.Category] = append(spotsByCategory[spot.Category], spot)
+	}
+	for _, category := range categories {
+		if err := suc.setMasterData(ctx, category, spotsByCategory[category]); err != nil {
+			log.Printf("Failed to set master data of %v: %v", category, err)
+		}
+	}
+}
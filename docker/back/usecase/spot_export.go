@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"log"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+	"github.com/tusmasoma/campfinder/docker/back/internal/geo"
+)
+
+// ExportPageSize はエクスポート時に1回のクエリで取得する件数です。
+const ExportPageSize = 500
+
+// ExportSpotsParams はListSpotsと同じCategories, MinRatingに加え、
+// ListNearbySpotsと同じ位置(BoundsまたはLat, Lng, RadiusKm)で絞り込む条件を表します。RadiusKmが0の場合は位置で絞り込みません。
+type ExportSpotsParams struct {
+	Categories []string
+	MinRating  float64
+	Lat        float64
+	Lng        float64
+	RadiusKm   float64
+	Bounds     *model.BoundingBox
+}
+
+// ExportSpots は条件に一致するSpotをid順にページ単位で取得し、1件ずつfnに渡します。
+// 全件をメモリに載せないため、件数が多くてもレスポンスへ逐次書き出せます。fnがエラーを返した場合はその時点で中断します。
+func (suc *spotUseCase) ExportSpots(
+	ctx context.Context,
+	params *ExportSpotsParams,
+	fn func(spot model.Spot) error,
+) error {
+	qcs := exportConditions(params)
+
+	cursor := ""
+	for {
+		spots, nextCursor, err := suc.sr.ListPage(ctx, qcs, repository.ListOptions{
+			Limit:  ExportPageSize,
+			Cursor: cursor,
+		})
+		if err != nil {
+			log.Printf("Failed to list spots for export: %v", err)
+			return err
+		}
+
+		for _, spot := range spots {
+			// 矩形の四隅は半径の外側になるため、距離で絞り込む
+			if params.Bounds == nil && params.RadiusKm > 0 &&
+				geo.Distance(params.Lat, params.Lng, spot.Lat, spot.Lng) > params.RadiusKm {
+				continue
+			}
+			if err = fn(spot); err != nil {
+				return err
+			}
+		}
+
+		if nextCursor == "" {
+			return nil
+		}
+		cursor = nextCursor
+	}
+}
+
+func exportConditions(params *ExportSpotsParams) []repository.QueryCondition {
+	var qcs []repository.QueryCondition
+	if len(params.Categories) > 0 {
+		qcs = append(qcs, repository.QueryCondition{Field: "category", Operator: repository.OpIn, Value: params.Categories})
+	}
+	if params.MinRating > 0 {
+		qcs = append(qcs, repository.QueryCondition{Field: "rating_average", Operator: repository.OpGte, Value: params.MinRating})
+	}
+
+	bounds := params.Bounds
+	if bounds == nil && params.RadiusKm > 0 {
+		around := geo.BoundsAround(params.Lat, params.Lng, params.RadiusKm)
+		bounds = &around
+	}
+	if bounds != nil {
+		qcs = append(qcs,
+			repository.QueryCondition{
+				Field:    "lat",
+				Operator: repository.OpBetween,
+				Value:    repository.Range{Start: bounds.MinLat, End: bounds.MaxLat},
+			},
+			repository.QueryCondition{
+				Field:    "lng",
+				Operator: repository.OpBetween,
+				Value:    repository.Range{Start: bounds.MinLng, End: bounds.MaxLng},
+			},
+		)
+	}
+	return qcs
+}
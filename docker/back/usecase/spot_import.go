@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/internal/geo"
+	"github.com/tusmasoma/campfinder/docker/back/internal/spotimport"
+)
+
+type ImportStatus string
+
+const (
+	ImportStatusInserted ImportStatus = "inserted"
+	ImportStatusSkipped  ImportStatus = "skipped"
+	ImportStatusRejected ImportStatus = "rejected"
+)
+
+type ImportSpotsParams struct {
+	Format  spotimport.Format
+	Data    io.Reader
+	Mapping spotimport.ColumnMapping
+}
+
+// ImportSpotResult は1行ごとの取り込み結果です。Reasonはskipped, rejectedの場合のみ設定します。
+type ImportSpotResult struct {
+	Row    int          `json:"row"`
+	Status ImportStatus `json:"status"`
+	Name   string       `json:"name,omitempty"`
+	Reason string       `json:"reason,omitempty"`
+}
+
+type ImportSpotsReport struct {
+	Inserted int                `json:"inserted"`
+	Skipped  int                `json:"skipped"`
+	Rejected int                `json:"rejected"`
+	Results  []ImportSpotResult `json:"results"`
+}
+
+func (r *ImportSpotsReport) add(result ImportSpotResult) {
+	switch result.Status {
+	case ImportStatusInserted:
+		r.Inserted++
+	case ImportStatusSkipped:
+		r.Skipped++
+	case ImportStatusRejected:
+		r.Rejected++
+	}
+	r.Results = append(r.Results, result)
+}
+
+// ImportSpots はCSVまたはGeoJSONからSpotを一括登録します。
+// 不正な行はrejected、同じ緯度経度のSpotが登録済み(またはファイル内で重複)の行はskippedとして、残りの行の登録を続けます。
+// ファイル自体が読めない場合とDBエラーの場合のみエラーを返します。
+func (suc *spotUseCase) ImportSpots(ctx context.Context, params *ImportSpotsParams) (*ImportSpotsReport, error) {
+	records, err := spotimport.Parse(params.Format, params.Data, params.Mapping)
+	if err != nil {
+		log.Printf("Failed to parse import data: %v", err)
+		return nil, err
+	}
+
+	type coordinate struct{ lat, lng float64 }
+	seen := make(map[coordinate]int, len(records))
+	report := &ImportSpotsReport{Results: make([]ImportSpotResult, 0, len(records))}
+	var categories []string
+
+	for _, record := range records {
+		result := ImportSpotResult{Row: record.Row, Name: record.Spot.Name}
+		if record.Err != nil {
+			result.Status, result.Reason = ImportStatusRejected, record.Err.Error()
+			report.add(result)
+			continue
+		}
+		if reason := validateImportSpot(record.Spot); reason != "" {
+			result.Status, result.Reason = ImportStatusRejected, reason
+			report.add(result)
+			continue
+		}
+
+		key := coordinate{record.Spot.Lat, record.Spot.Lng}
+		if row, ok := seen[key]; ok {
+			result.Status, result.Reason = ImportStatusSkipped, fmt.Sprintf("duplicate of row %d", row)
+			report.add(result)
+			continue
+		}
+		seen[key] = record.Row
+
+		exists, existsErr := suc.existsAt(ctx, record.Spot.Lat, record.Spot.Lng)
+		if existsErr != nil {
+			log.Printf("Internal server error: %v", existsErr)
+			return nil, existsErr
+		}
+		if exists {
+			result.Status, result.Reason = ImportStatusSkipped, "spot with this lat,lng already exists"
+			report.add(result)
+			continue
+		}
+
+		spot := record.Spot
+		spot.ID = uuid.New()
+		if err = suc.sr.Create(ctx, spot); err != nil {
+			log.Printf("Failed to create spot: %v", err)
+			return nil, err
+		}
+		categories = append(categories, spot.Category)
+		result.Status = ImportStatusInserted
+		report.add(result)
+	}
+
+	if len(categories) > 0 {
+		suc.deleteMasterData(ctx, categories...)
+		suc.deleteTiles(ctx)
+	}
+	return report, nil
+}
+
+// validateImportSpot はCreateSpotと同じ必須項目に加えて、緯度経度の範囲を検証します。問題がなければ空文字を返します。
+func validateImportSpot(spot model.Spot) string {
+	switch {
+	case spot.Category == "":
+		return "missing category"
+	case spot.Name == "":
+		return "missing name"
+	case spot.Address == "":
+		return "missing address"
+	case spot.Lat == 0 || spot.Lng == 0 || !geo.IsValidCoordinate(spot.Lat, spot.Lng):
+		return fmt.Sprintf("invalid coordinate: %v,%v", spot.Lat, spot.Lng)
+	}
+	return ""
+}
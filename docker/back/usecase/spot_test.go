@@ -3,23 +3,21 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"math"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
-	"github.com/stretchr/testify/assert"
 
 	"github.com/tusmasoma/campfinder/docker/back/domain/model"
 	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
 	"github.com/tusmasoma/campfinder/docker/back/domain/repository/mock"
+	"github.com/tusmasoma/campfinder/docker/back/internal/geo"
+	"github.com/tusmasoma/campfinder/docker/back/internal/spotimport"
 )
 
-type ListSpotsArg struct {
-	ctx        context.Context
-	categories []string
-}
-
 type GetSpotArg struct {
 	ctx    context.Context
 	spotID string
@@ -124,12 +122,14 @@ func TestSpotUseCase_CreateSpot(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			sr := mock.NewMockSpotRepository(ctrl)
 			cr := mock.NewMockSpotsCacheRepository(ctrl)
+			tcr := mock.NewMockSpotClustersCacheRepository(ctrl)
+			tcr.EXPECT().Scan(gomock.Any(), "tiles_*").Return(nil, nil).AnyTimes()
 
 			if tt.setup != nil {
 				tt.setup(sr)
 			}
 
-			usecase := NewSpotUseCase(sr, cr)
+			usecase := NewSpotUseCase(sr, cr, tcr)
 
 			err := usecase.CreateSpot(ctx, tt.params)
 
@@ -226,12 +226,14 @@ func TestSpotUseCase_BatchCreateSpots(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			sr := mock.NewMockSpotRepository(ctrl)
 			cr := mock.NewMockSpotsCacheRepository(ctrl)
+			tcr := mock.NewMockSpotClustersCacheRepository(ctrl)
+			tcr.EXPECT().Scan(gomock.Any(), "tiles_*").Return(nil, nil).AnyTimes()
 
 			if tt.setup != nil {
 				tt.setup(sr)
 			}
 
-			usecase := NewSpotUseCase(sr, cr)
+			usecase := NewSpotUseCase(sr, cr, tcr)
 
 			err := usecase.BatchCreateSpots(ctx, tt.params)
 
@@ -273,90 +275,133 @@ func TestSpotUseCase_ListSpots(t *testing.T) {
 		IconPath:    "/static/img/spaflag.jpeg",
 	}
 
+	categories := []string{"campsite", "spa"}
+	qcs := []repository.QueryCondition{{Field: "category", Operator: repository.OpIn, Value: categories}}
+
 	patterns := []struct {
 		name  string
 		setup func(
 			m *mock.MockSpotRepository,
 			m1 *mock.MockSpotsCacheRepository,
 		)
-		arg  ListSpotsArg
-		want []model.Spot
+		params  *ListSpotsParams
+		want    *ListSpotsResult
+		wantErr error
 	}{
 		{
 			name: "success",
 			setup: func(m *mock.MockSpotRepository, m1 *mock.MockSpotsCacheRepository) {
-				m.EXPECT().List(
+				m.EXPECT().ListPage(
 					gomock.Any(),
-					[]repository.QueryCondition{{Field: "Category", Value: "campsite"}},
-				).Return([]model.Spot{campsite}, nil)
+					qcs,
+					repository.ListOptions{Limit: 50},
+				).Return([]model.Spot{campsite, spa}, "", nil)
+				m.EXPECT().Count(gomock.Any(), qcs).Return(2, nil)
 				m1.EXPECT().Set(
 					gomock.Any(),
 					"spots_campsite",
 					[]model.Spot{campsite},
 				).Return(nil)
-				m.EXPECT().List(
-					gomock.Any(),
-					[]repository.QueryCondition{{Field: "Category", Value: "spa"}},
-				).Return([]model.Spot{spa}, nil)
 				m1.EXPECT().Set(
 					gomock.Any(),
 					"spots_spa",
 					[]model.Spot{spa},
 				).Return(nil)
 			},
-			arg: ListSpotsArg{
-				ctx:        context.Background(),
-				categories: []string{"campsite", "spa"},
+			params: &ListSpotsParams{Categories: categories, Limit: 50},
+			want:   &ListSpotsResult{Spots: []model.Spot{campsite, spa}, Total: 2},
+		},
+		{
+			name: "success: has next page",
+			setup: func(m *mock.MockSpotRepository, m1 *mock.MockSpotsCacheRepository) {
+				m.EXPECT().ListPage(
+					gomock.Any(),
+					qcs,
+					repository.ListOptions{Limit: 1, OrderBy: "-name"},
+				).Return([]model.Spot{campsite}, "cursor", nil)
+				m.EXPECT().Count(gomock.Any(), qcs).Return(2, nil)
 			},
-			want: []model.Spot{campsite, spa},
+			params: &ListSpotsParams{Categories: categories, Limit: 1, OrderBy: "-name"},
+			want:   &ListSpotsResult{Spots: []model.Spot{campsite}, NextCursor: "cursor", Total: 2},
+		},
+		{
+			name: "success: min rating does not update cache",
+			setup: func(m *mock.MockSpotRepository, m1 *mock.MockSpotsCacheRepository) {
+				ratingQcs := append(
+					qcs,
+					repository.QueryCondition{Field: "rating_average", Operator: repository.OpGte, Value: 4.0},
+				)
+				m.EXPECT().ListPage(
+					gomock.Any(),
+					ratingQcs,
+					repository.ListOptions{Limit: 50, OrderBy: "-rating_average"},
+				).Return([]model.Spot{campsite}, "", nil)
+				m.EXPECT().Count(gomock.Any(), ratingQcs).Return(1, nil)
+			},
+			params: &ListSpotsParams{Categories: categories, MinRating: 4, Limit: 50, OrderBy: "-rating_average"},
+			want:   &ListSpotsResult{Spots: []model.Spot{campsite}, Total: 1},
+		},
+		{
+			name: "success: without categories",
+			setup: func(m *mock.MockSpotRepository, m1 *mock.MockSpotsCacheRepository) {
+				m.EXPECT().ListPage(
+					gomock.Any(),
+					nil,
+					repository.ListOptions{Limit: 50},
+				).Return([]model.Spot{campsite, spa}, "", nil)
+				m.EXPECT().Count(gomock.Any(), nil).Return(2, nil)
+			},
+			params: &ListSpotsParams{Limit: 50},
+			want:   &ListSpotsResult{Spots: []model.Spot{campsite, spa}, Total: 2},
 		},
 		{
 			name: "fail: get spot from db",
 			setup: func(m *mock.MockSpotRepository, m1 *mock.MockSpotsCacheRepository) {
-				m.EXPECT().List(
+				m.EXPECT().ListPage(
 					gomock.Any(),
-					[]repository.QueryCondition{{Field: "Category", Value: "campsite"}},
-				).Return([]model.Spot{}, fmt.Errorf("fail to get spot from db"))
+					qcs,
+					repository.ListOptions{Limit: 50},
+				).Return(nil, "", fmt.Errorf("fail to get spot from db"))
 				m1.EXPECT().Get(gomock.Any(), "spots_campsite").Return(&model.Spots{campsite}, nil)
-				m.EXPECT().List(
-					gomock.Any(),
-					[]repository.QueryCondition{{Field: "Category", Value: "spa"}},
-				).Return([]model.Spot{}, fmt.Errorf("fail to get spot from db"))
 				m1.EXPECT().Get(gomock.Any(), "spots_spa").Return(&model.Spots{spa}, nil)
 			},
-			arg: ListSpotsArg{
-				ctx:        context.Background(),
-				categories: []string{"campsite", "spa"},
-			},
-			want: []model.Spot{campsite, spa},
+			params: &ListSpotsParams{Categories: categories, Limit: 50},
+			want:   &ListSpotsResult{Spots: []model.Spot{campsite, spa}, Total: 2},
 		},
 		{
 			name: "fail: set master data",
 			setup: func(m *mock.MockSpotRepository, m1 *mock.MockSpotsCacheRepository) {
-				m.EXPECT().List(
+				m.EXPECT().ListPage(
 					gomock.Any(),
-					[]repository.QueryCondition{{Field: "Category", Value: "campsite"}},
-				).Return([]model.Spot{campsite}, nil)
+					qcs,
+					repository.ListOptions{Limit: 50},
+				).Return([]model.Spot{campsite, spa}, "", nil)
+				m.EXPECT().Count(gomock.Any(), qcs).Return(2, nil)
 				m1.EXPECT().Set(
 					gomock.Any(),
 					"spots_campsite",
 					[]model.Spot{campsite},
 				).Return(fmt.Errorf("fail to set in cache"))
-				m.EXPECT().List(
-					gomock.Any(),
-					[]repository.QueryCondition{{Field: "Category", Value: "spa"}},
-				).Return([]model.Spot{spa}, nil)
 				m1.EXPECT().Set(
 					gomock.Any(),
 					"spots_spa",
 					[]model.Spot{spa},
 				).Return(fmt.Errorf("fail to set in cache"))
 			},
-			arg: ListSpotsArg{
-				ctx:        context.Background(),
-				categories: []string{"campsite", "spa"},
+			params: &ListSpotsParams{Categories: categories, Limit: 50},
+			want:   &ListSpotsResult{Spots: []model.Spot{campsite, spa}, Total: 2},
+		},
+		{
+			name: "fail: invalid cursor",
+			setup: func(m *mock.MockSpotRepository, m1 *mock.MockSpotsCacheRepository) {
+				m.EXPECT().ListPage(
+					gomock.Any(),
+					qcs,
+					repository.ListOptions{Limit: 50, Cursor: "invalid"},
+				).Return(nil, "", repository.ErrInvalidCursor)
 			},
-			want: []model.Spot{campsite, spa},
+			params:  &ListSpotsParams{Categories: categories, Limit: 50, Cursor: "invalid"},
+			wantErr: repository.ErrInvalidCursor,
 		},
 	}
 
@@ -367,16 +412,25 @@ func TestSpotUseCase_ListSpots(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			sr := mock.NewMockSpotRepository(ctrl)
 			cr := mock.NewMockSpotsCacheRepository(ctrl)
+			tcr := mock.NewMockSpotClustersCacheRepository(ctrl)
+			tcr.EXPECT().Scan(gomock.Any(), "tiles_*").Return(nil, nil).AnyTimes()
 
 			if tt.setup != nil {
 				tt.setup(sr, cr)
 			}
 
-			usecase := NewSpotUseCase(sr, cr)
+			usecase := NewSpotUseCase(sr, cr, tcr)
 
-			spots := usecase.ListSpots(tt.arg.ctx, tt.arg.categories)
+			result, err := usecase.ListSpots(context.Background(), tt.params)
 
-			assert.ElementsMatch(t, tt.want, spots, "ListSpots() should return the correct set of spots regardless of order")
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("ListSpots() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("ListSpots() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(result, tt.want) {
+				t.Errorf("ListSpots() \n got = %v,\n want %v", result, tt.want)
+			}
 		})
 	}
 }
@@ -496,12 +550,14 @@ func TestSpotUseCase_GetSpot(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			sr := mock.NewMockSpotRepository(ctrl)
 			cr := mock.NewMockSpotsCacheRepository(ctrl)
+			tcr := mock.NewMockSpotClustersCacheRepository(ctrl)
+			tcr.EXPECT().Scan(gomock.Any(), "tiles_*").Return(nil, nil).AnyTimes()
 
 			if tt.setup != nil {
 				tt.setup(sr, cr)
 			}
 
-			usecase := NewSpotUseCase(sr, cr)
+			usecase := NewSpotUseCase(sr, cr, tcr)
 
 			spots := usecase.GetSpot(tt.arg.ctx, tt.arg.spotID)
 
@@ -511,3 +567,757 @@ func TestSpotUseCase_GetSpot(t *testing.T) {
 		})
 	}
 }
+
+func TestSpotUseCase_ListNearbySpots(t *testing.T) {
+	t.Parallel()
+	campsite := model.SpotWithDistance{
+		Spot: model.Spot{
+			ID:       uuid.MustParse("5c5323e9-c78f-4dac-94ef-d34ab5ea8fed"),
+			Category: "campsite",
+			Name:     "旭川市21世紀の森ふれあい広場",
+			Lat:      43.7172721,
+			Lng:      142.6674615,
+		},
+		DistanceKm: 1.2,
+	}
+	bounds := model.BoundingBox{MinLat: 43, MinLng: 142, MaxLat: 44, MaxLng: 143}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockSpotRepository,
+		)
+		params  *ListNearbySpotsParams
+		want    []model.SpotWithDistance
+		wantErr error
+	}{
+		{
+			name: "success: radius",
+			setup: func(m *mock.MockSpotRepository) {
+				m.EXPECT().ListNearby(
+					gomock.Any(),
+					43.72,
+					142.68,
+					5.0,
+				).Return([]model.SpotWithDistance{campsite}, nil)
+			},
+			params: &ListNearbySpotsParams{Lat: 43.72, Lng: 142.68, RadiusKm: 5},
+			want:   []model.SpotWithDistance{campsite},
+		},
+		{
+			name: "success: bounding box",
+			setup: func(m *mock.MockSpotRepository) {
+				m.EXPECT().ListInBounds(gomock.Any(), bounds).Return([]model.SpotWithDistance{campsite}, nil)
+			},
+			params: &ListNearbySpotsParams{Bounds: &bounds},
+			want:   []model.SpotWithDistance{campsite},
+		},
+		{
+			name: "Fail: list nearby",
+			setup: func(m *mock.MockSpotRepository) {
+				m.EXPECT().ListNearby(
+					gomock.Any(),
+					43.72,
+					142.68,
+					5.0,
+				).Return(nil, fmt.Errorf("fail to list nearby spots"))
+			},
+			params:  &ListNearbySpotsParams{Lat: 43.72, Lng: 142.68, RadiusKm: 5},
+			wantErr: fmt.Errorf("fail to list nearby spots"),
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			sr := mock.NewMockSpotRepository(ctrl)
+			cr := mock.NewMockSpotsCacheRepository(ctrl)
+			tcr := mock.NewMockSpotClustersCacheRepository(ctrl)
+			tcr.EXPECT().Scan(gomock.Any(), "tiles_*").Return(nil, nil).AnyTimes()
+
+			if tt.setup != nil {
+				tt.setup(sr)
+			}
+
+			usecase := NewSpotUseCase(sr, cr, tcr)
+
+			spots, err := usecase.ListNearbySpots(context.Background(), tt.params)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("ListNearbySpots() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("ListNearbySpots() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(spots, tt.want) {
+				t.Errorf("ListNearbySpots() = %v, want %v", spots, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpotUseCase_SearchSpots(t *testing.T) {
+	t.Parallel()
+	asahikawa := model.SpotWithScore{
+		Spot: model.Spot{
+			ID:       uuid.MustParse("5c5323e9-c78f-4dac-94ef-d34ab5ea8fed"),
+			Category: "campsite",
+			Name:     "旭川市21世紀の森ふれあい広場",
+			Lat:      43.7172721,
+			Lng:      142.6674615,
+		},
+		Score: 2.5,
+	}
+	tomamae := model.SpotWithScore{
+		Spot: model.Spot{
+			ID:       uuid.MustParse("5c5323e9-c78f-4dac-94ef-d34ab8ea5fde"),
+			Category: "campsite",
+			Name:     "とままえ夕陽ヶ丘未来港公園",
+			Lat:      44.3153234,
+			Lng:      141.6563455,
+		},
+		Score: 1.5,
+	}
+	bounds := model.BoundingBox{MinLat: 43, MinLng: 141, MaxLat: 45, MaxLng: 143}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockSpotRepository,
+		)
+		params  *SearchSpotsParams
+		want    []model.SpotWithScore
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockSpotRepository) {
+				m.EXPECT().Search(
+					gomock.Any(),
+					repository.SpotSearchQuery{Keyword: "キャンプ", Categories: []string{"campsite"}, Limit: 50},
+				).Return([]model.SpotWithScore{asahikawa, tomamae}, nil)
+			},
+			params: &SearchSpotsParams{Keyword: "キャンプ", Categories: []string{"campsite"}, Limit: 50},
+			want:   []model.SpotWithScore{asahikawa, tomamae},
+		},
+		{
+			name: "success: bounding box",
+			setup: func(m *mock.MockSpotRepository) {
+				m.EXPECT().Search(
+					gomock.Any(),
+					repository.SpotSearchQuery{Keyword: "キャンプ", Bounds: &bounds, Limit: 50},
+				).Return([]model.SpotWithScore{asahikawa, tomamae}, nil)
+			},
+			params: &SearchSpotsParams{Keyword: "キャンプ", Bounds: &bounds, Limit: 50},
+			want:   []model.SpotWithScore{asahikawa, tomamae},
+		},
+		{
+			name: "success: radius excludes spots outside the circle",
+			setup: func(m *mock.MockSpotRepository) {
+				bounds := geo.BoundsAround(43.72, 142.68, 10)
+				m.EXPECT().Search(
+					gomock.Any(),
+					repository.SpotSearchQuery{Keyword: "キャンプ", Bounds: &bounds, Limit: 50},
+				).Return([]model.SpotWithScore{asahikawa, tomamae}, nil)
+			},
+			params: &SearchSpotsParams{Keyword: "キャンプ", Lat: 43.72, Lng: 142.68, RadiusKm: 10, Limit: 50},
+			want:   []model.SpotWithScore{asahikawa},
+		},
+		{
+			name: "Fail: search",
+			setup: func(m *mock.MockSpotRepository) {
+				m.EXPECT().Search(
+					gomock.Any(),
+					repository.SpotSearchQuery{Keyword: "キャンプ", Limit: 50},
+				).Return(nil, fmt.Errorf("fail to search spots"))
+			},
+			params:  &SearchSpotsParams{Keyword: "キャンプ", Limit: 50},
+			wantErr: fmt.Errorf("fail to search spots"),
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			sr := mock.NewMockSpotRepository(ctrl)
+			cr := mock.NewMockSpotsCacheRepository(ctrl)
+			tcr := mock.NewMockSpotClustersCacheRepository(ctrl)
+			tcr.EXPECT().Scan(gomock.Any(), "tiles_*").Return(nil, nil).AnyTimes()
+
+			if tt.setup != nil {
+				tt.setup(sr)
+			}
+
+			usecase := NewSpotUseCase(sr, cr, tcr)
+
+			spots, err := usecase.SearchSpots(context.Background(), tt.params)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("SearchSpots() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("SearchSpots() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(spots, tt.want) {
+				t.Errorf("SearchSpots() = %v, want %v", spots, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpotUseCase_UpdateSpot(t *testing.T) {
+	t.Parallel()
+	admin := model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"), IsAdmin: true}
+	current := model.Spot{
+		ID:       uuid.MustParse("5c5323e9-c78f-4dac-94ef-d34ab5ea8fed"),
+		Category: "campsite",
+		Name:     "旭川市21世紀の森ふれあい広場",
+		Address:  "北海道旭川市東旭川町瑞穂4288",
+		Lat:      43.7172721,
+		Lng:      142.6674615,
+	}
+	params := &UpdateSpotParams{
+		ID:       "5c5323e9-c78f-4dac-94ef-d34ab5ea8fed",
+		Category: "spa",
+		Name:     "旭川市21世紀の森ふれあい広場",
+		Address:  "北海道旭川市東旭川町瑞穂4288",
+		Lat:      43.7172721,
+		Lng:      142.6674615,
+		Phone:    "0166-76-2108",
+	}
+	updated := model.Spot{
+		ID:       current.ID,
+		Category: "spa",
+		Name:     current.Name,
+		Address:  current.Address,
+		Lat:      current.Lat,
+		Lng:      current.Lng,
+		Phone:    "0166-76-2108",
+	}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockSpotRepository,
+			m1 *mock.MockSpotsCacheRepository,
+		)
+		user    model.User
+		wantErr error
+	}{
+		{
+			name: "success: invalidate both categories",
+			setup: func(m *mock.MockSpotRepository, m1 *mock.MockSpotsCacheRepository) {
+				m.EXPECT().Get(gomock.Any(), params.ID).Return(&current, nil)
+				m.EXPECT().Update(gomock.Any(), params.ID, updated).Return(nil)
+				m1.EXPECT().Delete(gomock.Any(), "spots_campsite").Return(nil)
+				m1.EXPECT().Delete(gomock.Any(), "spots_spa").Return(nil)
+			},
+			user: admin,
+		},
+		{
+			name:    "Fail: not admin",
+			user:    model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")},
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			name: "Fail: spot not found",
+			setup: func(m *mock.MockSpotRepository, m1 *mock.MockSpotsCacheRepository) {
+				m.EXPECT().Get(gomock.Any(), params.ID).Return(nil, repository.ErrNotFound)
+			},
+			user:    admin,
+			wantErr: repository.ErrNotFound,
+		},
+		{
+			name: "Fail: update spot",
+			setup: func(m *mock.MockSpotRepository, m1 *mock.MockSpotsCacheRepository) {
+				m.EXPECT().Get(gomock.Any(), params.ID).Return(&current, nil)
+				m.EXPECT().Update(gomock.Any(), params.ID, updated).Return(fmt.Errorf("fail to update spot"))
+			},
+			user:    admin,
+			wantErr: fmt.Errorf("fail to update spot"),
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			sr := mock.NewMockSpotRepository(ctrl)
+			cr := mock.NewMockSpotsCacheRepository(ctrl)
+			tcr := mock.NewMockSpotClustersCacheRepository(ctrl)
+			tcr.EXPECT().Scan(gomock.Any(), "tiles_*").Return(nil, nil).AnyTimes()
+
+			if tt.setup != nil {
+				tt.setup(sr, cr)
+			}
+
+			usecase := NewSpotUseCase(sr, cr, tcr)
+
+			err := usecase.UpdateSpot(context.Background(), params, tt.user)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("UpdateSpot() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("UpdateSpot() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSpotUseCase_PatchSpot(t *testing.T) {
+	t.Parallel()
+	admin := model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"), IsAdmin: true}
+	current := model.Spot{
+		ID:       uuid.MustParse("5c5323e9-c78f-4dac-94ef-d34ab5ea8fed"),
+		Category: "campsite",
+		Name:     "旭川市21世紀の森ふれあい広場",
+		Address:  "北海道旭川市東旭川町瑞穂4288",
+		Lat:      43.7172721,
+		Lng:      142.6674615,
+		Phone:    "-",
+	}
+	phone := "0166-76-2108"
+	patched := current
+	patched.Phone = phone
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockSpotRepository,
+			m1 *mock.MockSpotsCacheRepository,
+		)
+		user    model.User
+		wantErr error
+	}{
+		{
+			name: "success: only given fields are updated",
+			setup: func(m *mock.MockSpotRepository, m1 *mock.MockSpotsCacheRepository) {
+				m.EXPECT().Get(gomock.Any(), "5c5323e9-c78f-4dac-94ef-d34ab5ea8fed").Return(&current, nil)
+				m.EXPECT().Update(gomock.Any(), "5c5323e9-c78f-4dac-94ef-d34ab5ea8fed", patched).Return(nil)
+				m1.EXPECT().Delete(gomock.Any(), "spots_campsite").Return(nil)
+			},
+			user: admin,
+		},
+		{
+			name: "success: cache delete failure is ignored",
+			setup: func(m *mock.MockSpotRepository, m1 *mock.MockSpotsCacheRepository) {
+				m.EXPECT().Get(gomock.Any(), "5c5323e9-c78f-4dac-94ef-d34ab5ea8fed").Return(&current, nil)
+				m.EXPECT().Update(gomock.Any(), "5c5323e9-c78f-4dac-94ef-d34ab5ea8fed", patched).Return(nil)
+				m1.EXPECT().Delete(gomock.Any(), "spots_campsite").Return(fmt.Errorf("fail to delete cache"))
+			},
+			user: admin,
+		},
+		{
+			name:    "Fail: not admin",
+			user:    model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")},
+			wantErr: ErrPermissionDenied,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			sr := mock.NewMockSpotRepository(ctrl)
+			cr := mock.NewMockSpotsCacheRepository(ctrl)
+			tcr := mock.NewMockSpotClustersCacheRepository(ctrl)
+			tcr.EXPECT().Scan(gomock.Any(), "tiles_*").Return(nil, nil).AnyTimes()
+
+			if tt.setup != nil {
+				tt.setup(sr, cr)
+			}
+
+			usecase := NewSpotUseCase(sr, cr, tcr)
+
+			err := usecase.PatchSpot(
+				context.Background(),
+				&PatchSpotParams{ID: "5c5323e9-c78f-4dac-94ef-d34ab5ea8fed", Phone: &phone},
+				tt.user,
+			)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("PatchSpot() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("PatchSpot() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSpotUseCase_DeleteSpot(t *testing.T) {
+	t.Parallel()
+	admin := model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"), IsAdmin: true}
+	current := model.Spot{
+		ID:       uuid.MustParse("5c5323e9-c78f-4dac-94ef-d34ab5ea8fed"),
+		Category: "campsite",
+	}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockSpotRepository,
+			m1 *mock.MockSpotsCacheRepository,
+		)
+		user    model.User
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockSpotRepository, m1 *mock.MockSpotsCacheRepository) {
+				m.EXPECT().Get(gomock.Any(), "5c5323e9-c78f-4dac-94ef-d34ab5ea8fed").Return(&current, nil)
+				m.EXPECT().Delete(gomock.Any(), "5c5323e9-c78f-4dac-94ef-d34ab5ea8fed").Return(nil)
+				m1.EXPECT().Delete(gomock.Any(), "spots_campsite").Return(nil)
+			},
+			user: admin,
+		},
+		{
+			name:    "Fail: not admin",
+			user:    model.User{ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")},
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			name: "Fail: delete spot",
+			setup: func(m *mock.MockSpotRepository, m1 *mock.MockSpotsCacheRepository) {
+				m.EXPECT().Get(gomock.Any(), "5c5323e9-c78f-4dac-94ef-d34ab5ea8fed").Return(&current, nil)
+				m.EXPECT().Delete(
+					gomock.Any(),
+					"5c5323e9-c78f-4dac-94ef-d34ab5ea8fed",
+				).Return(fmt.Errorf("fail to delete spot"))
+			},
+			user:    admin,
+			wantErr: fmt.Errorf("fail to delete spot"),
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			sr := mock.NewMockSpotRepository(ctrl)
+			cr := mock.NewMockSpotsCacheRepository(ctrl)
+			tcr := mock.NewMockSpotClustersCacheRepository(ctrl)
+			tcr.EXPECT().Scan(gomock.Any(), "tiles_*").Return(nil, nil).AnyTimes()
+
+			if tt.setup != nil {
+				tt.setup(sr, cr)
+			}
+
+			usecase := NewSpotUseCase(sr, cr, tcr)
+
+			err := usecase.DeleteSpot(context.Background(), "5c5323e9-c78f-4dac-94ef-d34ab5ea8fed", tt.user)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("DeleteSpot() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("DeleteSpot() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSpotUseCase_ImportSpots(t *testing.T) {
+	t.Parallel()
+	existsConditions := func(lat, lng float64) []repository.QueryCondition {
+		return []repository.QueryCondition{
+			{Field: "Lat", Value: lat},
+			{Field: "Lng", Value: lng},
+		}
+	}
+	data := "category,name,address,lat,lng\n" +
+		"campsite,旭川市21世紀の森,北海道旭川市,43.7172721,142.6674615\n" +
+		"campsite,登録済み,北海道旭川市,43.5,142.5\n" +
+		"campsite,重複,北海道旭川市,43.7172721,142.6674615\n" +
+		"campsite,,北海道旭川市,43.1,142.1\n" +
+		"campsite,範囲外,北海道旭川市,91,142.1\n"
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockSpotRepository,
+			m1 *mock.MockSpotsCacheRepository,
+		)
+		data    string
+		want    *ImportSpotsReport
+		wantErr bool
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockSpotRepository, m1 *mock.MockSpotsCacheRepository) {
+				m.EXPECT().List(gomock.Any(), existsConditions(43.7172721, 142.6674615)).Return(nil, nil)
+				m.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, spot model.Spot) error {
+						if spot.ID == uuid.Nil || spot.Name != "旭川市21世紀の森" {
+							t.Errorf("unexpected spot: %+v", spot)
+						}
+						return nil
+					},
+				)
+				m.EXPECT().List(gomock.Any(), existsConditions(43.5, 142.5)).Return([]model.Spot{{Name: "登録済み"}}, nil)
+				m1.EXPECT().Delete(gomock.Any(), "spots_campsite").Return(nil)
+			},
+			data: data,
+			want: &ImportSpotsReport{
+				Inserted: 1,
+				Skipped:  2,
+				Rejected: 2,
+				Results: []ImportSpotResult{
+					{Row: 1, Status: ImportStatusInserted, Name: "旭川市21世紀の森"},
+					{Row: 2, Status: ImportStatusSkipped, Name: "登録済み", Reason: "spot with this lat,lng already exists"},
+					{Row: 3, Status: ImportStatusSkipped, Name: "重複", Reason: "duplicate of row 1"},
+					{Row: 4, Status: ImportStatusRejected, Reason: "missing name"},
+					{Row: 5, Status: ImportStatusRejected, Name: "範囲外", Reason: "invalid coordinate: 91,142.1"},
+				},
+			},
+		},
+		{
+			name:    "Fail: invalid data",
+			data:    "",
+			wantErr: true,
+		},
+		{
+			name: "Fail: create spot",
+			setup: func(m *mock.MockSpotRepository, m1 *mock.MockSpotsCacheRepository) {
+				m.EXPECT().List(gomock.Any(), existsConditions(43.7172721, 142.6674615)).Return(nil, nil)
+				m.EXPECT().Create(gomock.Any(), gomock.Any()).Return(fmt.Errorf("fail to create spot"))
+			},
+			data:    "category,name,address,lat,lng\ncampsite,旭川市21世紀の森,北海道旭川市,43.7172721,142.6674615\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			sr := mock.NewMockSpotRepository(ctrl)
+			cr := mock.NewMockSpotsCacheRepository(ctrl)
+			tcr := mock.NewMockSpotClustersCacheRepository(ctrl)
+			tcr.EXPECT().Scan(gomock.Any(), "tiles_*").Return(nil, nil).AnyTimes()
+
+			if tt.setup != nil {
+				tt.setup(sr, cr)
+			}
+
+			usecase := NewSpotUseCase(sr, cr, tcr)
+
+			got, err := usecase.ImportSpots(context.Background(), &ImportSpotsParams{
+				Format: spotimport.FormatCSV,
+				Data:   strings.NewReader(tt.data),
+			})
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ImportSpots() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ImportSpots() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpotUseCase_ExportSpots(t *testing.T) {
+	t.Parallel()
+	near := model.Spot{ID: uuid.MustParse("5c5323e9-c78f-4dac-94ef-d34ab5ea8fed"), Lat: 43.7172721, Lng: 142.6674615}
+	// 矩形には含まれるが半径の外側
+	corner := model.Spot{ID: uuid.MustParse("2b5a4b1e-3f6c-4a8e-9d6b-7e8f9a0b1c2d"), Lat: 43.79, Lng: 142.79}
+	bounds := geo.BoundsAround(43.7172721, 142.6674615, 10)
+
+	patterns := []struct {
+		name    string
+		setup   func(m *mock.MockSpotRepository)
+		params  *ExportSpotsParams
+		fnErr   error
+		want    []model.Spot
+		wantErr bool
+	}{
+		{
+			name: "success: pages",
+			setup: func(m *mock.MockSpotRepository) {
+				qcs := []repository.QueryCondition{
+					{Field: "category", Operator: repository.OpIn, Value: []string{"campsite"}},
+					{Field: "rating_average", Operator: repository.OpGte, Value: 3.0},
+				}
+				m.EXPECT().ListPage(gomock.Any(), qcs, repository.ListOptions{Limit: ExportPageSize}).
+					Return([]model.Spot{near}, "next", nil)
+				m.EXPECT().ListPage(gomock.Any(), qcs, repository.ListOptions{Limit: ExportPageSize, Cursor: "next"}).
+					Return([]model.Spot{corner}, "", nil)
+			},
+			params: &ExportSpotsParams{Categories: []string{"campsite"}, MinRating: 3},
+			want:   []model.Spot{near, corner},
+		},
+		{
+			name: "success: radius",
+			setup: func(m *mock.MockSpotRepository) {
+				qcs := []repository.QueryCondition{
+					{
+						Field:    "lat",
+						Operator: repository.OpBetween,
+						Value:    repository.Range{Start: bounds.MinLat, End: bounds.MaxLat},
+					},
+					{
+						Field:    "lng",
+						Operator: repository.OpBetween,
+						Value:    repository.Range{Start: bounds.MinLng, End: bounds.MaxLng},
+					},
+				}
+				m.EXPECT().ListPage(gomock.Any(), qcs, repository.ListOptions{Limit: ExportPageSize}).
+					Return([]model.Spot{near, corner}, "", nil)
+			},
+			params: &ExportSpotsParams{Lat: 43.7172721, Lng: 142.6674615, RadiusKm: 10},
+			want:   []model.Spot{near},
+		},
+		{
+			name: "Fail: list spots",
+			setup: func(m *mock.MockSpotRepository) {
+				m.EXPECT().ListPage(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, "", fmt.Errorf("fail to list spots"))
+			},
+			params:  &ExportSpotsParams{},
+			wantErr: true,
+		},
+		{
+			name: "Fail: write spot",
+			setup: func(m *mock.MockSpotRepository) {
+				m.EXPECT().ListPage(gomock.Any(), gomock.Any(), gomock.Any()).Return([]model.Spot{near}, "next", nil)
+			},
+			params:  &ExportSpotsParams{},
+			fnErr:   fmt.Errorf("broken pipe"),
+			want:    []model.Spot{near},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			sr := mock.NewMockSpotRepository(ctrl)
+			cr := mock.NewMockSpotsCacheRepository(ctrl)
+			tcr := mock.NewMockSpotClustersCacheRepository(ctrl)
+			tcr.EXPECT().Scan(gomock.Any(), "tiles_*").Return(nil, nil).AnyTimes()
+
+			if tt.setup != nil {
+				tt.setup(sr)
+			}
+
+			usecase := NewSpotUseCase(sr, cr, tcr)
+
+			var got []model.Spot
+			err := usecase.ExportSpots(context.Background(), tt.params, func(spot model.Spot) error {
+				got = append(got, spot)
+				return tt.fnErr
+			})
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExportSpots() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExportSpots() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpotUseCase_GetSpotTile(t *testing.T) {
+	t.Parallel()
+	// 旭川市内の2件は同じセルに、西側の1件は別のセルに集約される
+	spots := []model.SpotWithDistance{
+		{Spot: model.Spot{
+			ID: uuid.MustParse("5c5323e9-c78f-4dac-94ef-d34ab5ea8fed"), Category: "campsite", Lat: 43.72, Lng: 142.66,
+		}},
+		{Spot: model.Spot{
+			ID: uuid.MustParse("2b5a4b1e-3f6c-4a8e-9d6b-7e8f9a0b1c2d"), Category: "campsite", Lat: 43.74, Lng: 142.68,
+		}},
+		{Spot: model.Spot{
+			ID: uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"), Category: "spa", Lat: 43.72, Lng: 142.40,
+		}},
+	}
+	clusters := model.SpotClusters{
+		{Lat: 43.72, Lng: 142.40, Count: 1, Category: "spa", SpotID: "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"},
+		{Lat: 43.73, Lng: 142.67, Count: 2, Category: "campsite"},
+	}
+
+	patterns := []struct {
+		name    string
+		setup   func(m *mock.MockSpotRepository, m1 *mock.MockSpotClustersCacheRepository)
+		want    model.SpotClusters
+		wantErr bool
+	}{
+		{
+			name: "success: cache hit",
+			setup: func(m *mock.MockSpotRepository, m1 *mock.MockSpotClustersCacheRepository) {
+				m1.EXPECT().Get(gomock.Any(), "tiles_9_458_186").Return(&clusters, nil)
+			},
+			want: clusters,
+		},
+		{
+			name: "success: cache miss",
+			setup: func(m *mock.MockSpotRepository, m1 *mock.MockSpotClustersCacheRepository) {
+				m1.EXPECT().Get(gomock.Any(), "tiles_9_458_186").Return(nil, fmt.Errorf("cache: key not found"))
+				m.EXPECT().ListInBounds(gomock.Any(), geo.TileBounds(9, 458, 186)).Return(spots, nil)
+				m1.EXPECT().Set(gomock.Any(), "tiles_9_458_186", gomock.Len(2)).Return(nil)
+			},
+			want: clusters,
+		},
+		{
+			name: "Fail: list spots in bounds",
+			setup: func(m *mock.MockSpotRepository, m1 *mock.MockSpotClustersCacheRepository) {
+				m1.EXPECT().Get(gomock.Any(), "tiles_9_458_186").Return(nil, fmt.Errorf("cache: key not found"))
+				m.EXPECT().ListInBounds(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("fail to list spots"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			sr := mock.NewMockSpotRepository(ctrl)
+			cr := mock.NewMockSpotsCacheRepository(ctrl)
+			tcr := mock.NewMockSpotClustersCacheRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(sr, tcr)
+			}
+
+			usecase := NewSpotUseCase(sr, cr, tcr)
+
+			got, err := usecase.GetSpotTile(context.Background(), 9, 458, 186)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetSpotTile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("GetSpotTile() = %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if math.Abs(got[i].Lat-tt.want[i].Lat) > 1e-9 || math.Abs(got[i].Lng-tt.want[i].Lng) > 1e-9 ||
+					got[i].Count != tt.want[i].Count || got[i].Category != tt.want[i].Category ||
+					got[i].SpotID != tt.want[i].SpotID {
+					t.Errorf("GetSpotTile()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSpotUseCase_deleteTiles(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	tcr := mock.NewMockSpotClustersCacheRepository(ctrl)
+	tcr.EXPECT().Scan(gomock.Any(), "tiles_*").Return([]string{"tiles_9_458_186", "tiles_10_917_373"}, nil)
+	tcr.EXPECT().Delete(gomock.Any(), "tiles_9_458_186").Return(nil)
+	tcr.EXPECT().Delete(gomock.Any(), "tiles_10_917_373").Return(nil)
+
+	suc := &spotUseCase{tcr: tcr}
+	suc.deleteTiles(context.Background())
+}
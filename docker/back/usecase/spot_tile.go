@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/internal/geo"
+)
+
+// ClusterCellPixels はクラスタリングのグリッド1辺のピクセル数です。タイル1枚を8x8のセルに分けて集約します。
+const ClusterCellPixels = 32
+
+func tileKey(z, x, y int) string {
+	return fmt.Sprintf("tiles_%d_%d_%d", z, x, y)
+}
+
+// GetSpotTile はWebメルカトルのタイル(z/x/y)内のSpotをグリッドで集約したクラスタを返します。
+// 結果はタイルごとにキャッシュし、Spotの作成・更新・削除時に全タイルのキャッシュを削除します。
+func (suc *spotUseCase) GetSpotTile(ctx context.Context, z, x, y int) (model.SpotClusters, error) {
+	key := tileKey(z, x, y)
+	if clusters, err := suc.tcr.Get(ctx, key); err == nil && *clusters != nil {
+		return *clusters, nil
+	}
+
+	spots, err := suc.sr.ListInBounds(ctx, geo.TileBounds(z, x, y))
+	if err != nil {
+		log.Printf("Failed to list spots in tile %v: %v", key, err)
+		return nil, err
+	}
+
+	clusters := clusterSpots(spots, z)
+	if err = suc.tcr.Set(ctx, key, clusters); err != nil {
+		log.Printf("Failed to set cache of %v: %v", key, err)
+	}
+	return clusters, nil
+}
+
+type clusterCell struct {
+	col, row   int
+	latSum     float64
+	lngSum     float64
+	spotIDs    []string
+	categories map[string]int
+}
+
+func clusterSpots(spots []model.SpotWithDistance, z int) model.SpotClusters {
+	cells := make(map[[2]int]*clusterCell)
+	for _, spot := range spots {
+		px, py := geo.TilePixel(spot.Lat, spot.Lng, z)
+		key := [2]int{int(px) / ClusterCellPixels, int(py) / ClusterCellPixels}
+		cell, ok := cells[key]
+		if !ok {
+			cell = &clusterCell{col: key[0], row: key[1], categories: map[string]int{}}
+			cells[key] = cell
+		}
+		cell.latSum += spot.Lat
+		cell.lngSum += spot.Lng
+		cell.spotIDs = append(cell.spotIDs, spot.ID.String())
+		cell.categories[spot.Category]++
+	}
+
+	ordered := make([]*clusterCell, 0, len(cells))
+	for _, cell := range cells {
+		ordered = append(ordered, cell)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].row != ordered[j].row {
+			return ordered[i].row < ordered[j].row
+		}
+		return ordered[i].col < ordered[j].col
+	})
+
+	clusters := make(model.SpotClusters, 0, len(ordered))
+	for _, cell := range ordered {
+		count := len(cell.spotIDs)
+		cluster := model.SpotCluster{
+			Lat:      cell.latSum / float64(count),
+			Lng:      cell.lngSum / float64(count),
+			Count:    count,
+			Category: dominantCategory(cell.categories),
+		}
+		if count == 1 {
+			cluster.SpotID = cell.spotIDs[0]
+		}
+		clusters = append(clusters, cluster)
+	}
+	return clusters
+}
+
+// dominantCategory は最も多いカテゴリを返します。同数の場合は名前順で先のものを返します。
+func dominantCategory(categories map[string]int) string {
+	var dominant string
+	for category, count := range categories {
+		if count > categories[dominant] || (count == categories[dominant] && category < dominant) {
+			dominant = category
+		}
+	}
+	return dominant
+}
+
+// deleteTiles はタイルのキャッシュをすべて削除します。Spotの位置やカテゴリが変わると複数のズームレベルのタイルに影響するためです。
+func (suc *spotUseCase) deleteTiles(ctx context.Context) {
+	keys, err := suc.tcr.Scan(ctx, "tiles_*")
+	if err != nil {
+		log.Printf("Failed to scan tile cache: %v", err)
+		return
+	}
+	for _, key := range keys {
+		if err = suc.tcr.Delete(ctx, key); err != nil {
+			log.Printf("Failed to delete tile cache %v: %v", key, err)
+		}
+	}
+}
@@ -3,47 +3,107 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/config"
 	"github.com/tusmasoma/campfinder/docker/back/domain/model"
 	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
 	"github.com/tusmasoma/campfinder/docker/back/internal/auth"
+	"github.com/tusmasoma/campfinder/docker/back/internal/mail"
 )
 
 type UserUseCase interface {
-	CreateUserAndGenerateToken(ctx context.Context, email string, passward string) (string, error)
-	LoginAndGenerateToken(ctx context.Context, email string, passward string) (string, error)
-	LogoutUser(ctx context.Context, userID string) error
+	CreateUserAndGenerateToken(ctx context.Context, email string, passward string, device Device) (*TokenPair, error)
+	LoginAndGenerateToken(ctx context.Context, email string, passward string, device Device) (*TokenPair, error)
+	RefreshToken(ctx context.Context, refreshToken string) (*TokenPair, error)
+	LogoutUser(ctx context.Context, userID string, sessionID string) error
+	ListSessions(ctx context.Context, userID string) ([]model.Session, error)
+	RevokeSession(ctx context.Context, userID string, sessionID string) error
+	RevokeAllSessions(ctx context.Context, userID string) error
+	RequestEmailVerification(ctx context.Context, userID string) error
+	VerifyEmail(ctx context.Context, token string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token string, password string) error
+	VerifyTwoFactorLogin(ctx context.Context, challengeToken string, code string) (*TokenPair, error)
+	SetupTwoFactor(ctx context.Context, userID string) (*TwoFactorSetup, error)
+	EnableTwoFactor(ctx context.Context, userID string, code string) ([]string, error)
+	DisableTwoFactor(ctx context.Context, userID string, password string, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID string, code string) ([]string, error)
+}
+
+// Device はログインした端末の情報で、セッション一覧に表示します。
+type Device struct {
+	Name      string
+	IP        string
+	UserAgent string
+}
+
+// TokenPair はログイン時とリフレッシュ時に発行するトークンです。ExpiresInはアクセストークンの有効期間(秒)です。
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64
 }
 
 type userUseCase struct {
-	ur repository.UserRepository
-	cr repository.UserCacheRepository
+	ur     repository.UserRepository
+	cr     repository.UserCacheRepository
+	lr     repository.LoginAttemptCacheRepository
+	rcr    repository.RecoveryCodeRepository
+	tfr    repository.TwoFactorCacheRepository
+	mailer mail.Mailer
+	mc     *config.MailConfig
+	lc     *config.LoginThrottleConfig
+	tc     *config.TwoFactorConfig
 }
 
-func NewUserUseCase(ur repository.UserRepository, cr repository.UserCacheRepository) UserUseCase {
+func NewUserUseCase(
+	ur repository.UserRepository,
+	cr repository.UserCacheRepository,
+	lr repository.LoginAttemptCacheRepository,
+	rcr repository.RecoveryCodeRepository,
+	tfr repository.TwoFactorCacheRepository,
+	mailer mail.Mailer,
+	mc *config.MailConfig,
+	lc *config.LoginThrottleConfig,
+	tc *config.TwoFactorConfig,
+) UserUseCase {
 	return &userUseCase{
-		ur: ur,
-		cr: cr,
+		ur:     ur,
+		cr:     cr,
+		lr:     lr,
+		rcr:    rcr,
+		tfr:    tfr,
+		mailer: mailer,
+		mc:     mc,
+		lc:     lc,
+		tc:     tc,
 	}
 }
 
-func (uuc *userUseCase) CreateUserAndGenerateToken(ctx context.Context, email string, passward string) (string, error) {
+func (uuc *userUseCase) CreateUserAndGenerateToken(
+	ctx context.Context,
+	email string,
+	passward string,
+	device Device,
+) (*TokenPair, error) {
 	user, err := uuc.CreateUser(ctx, email, passward)
 	if err != nil {
 		log.Printf("Internal server error while creating user")
-		return "", err
+		return nil, err
 	}
-
-	jwt, jti := auth.GenerateToken(user.ID.String(), user.Email)
-	if err = uuc.cr.SetUserSession(ctx, user.ID.String(), jti); err != nil {
-		log.Print("Failed to set access token in cache")
-		return "", err
+	// 確認メールを送れなくても登録は完了させ、ユーザは確認メールを再送できる
+	if err = uuc.sendVerificationEmail(ctx, user); err != nil {
+		log.Printf("Failed to send verification email to %v: %v", user.ID, err)
 	}
 
-	return jwt, nil
+	return uuc.issueTokens(ctx, user.Email, newSession(user.ID.String(), device))
 }
 
 func (uuc *userUseCase) CreateUser(ctx context.Context, email string, passward string) (*model.User, error) {
@@ -58,8 +118,10 @@ func (uuc *userUseCase) CreateUser(ctx context.Context, email string, passward s
 	}
 
 	var user model.User
+	user.ID = uuid.New()
 	user.Email = email
 	user.Name = auth.ExtractUsernameFromEmail(email)
+	user.Role = model.RoleUser
 	password, err := auth.PasswordEncrypt(passward)
 	if err != nil {
 		log.Printf("Internal server error: %v", err)
@@ -74,42 +136,278 @@ func (uuc *userUseCase) CreateUser(ctx context.Context, email string, passward s
 	return &user, nil
 }
 
-func (uuc *userUseCase) LoginAndGenerateToken(ctx context.Context, email string, passward string) (string, error) {
+func (uuc *userUseCase) LoginAndGenerateToken(
+	ctx context.Context,
+	email string,
+	passward string,
+	device Device,
+) (*TokenPair, error) {
+	// パスワードを確認する前に、失敗が続いているメールアドレス・IPアドレスからの試行を拒否する
+	targets := uuc.loginAttemptTargets(email, device.IP)
+	if err := uuc.checkLoginThrottle(ctx, targets); err != nil {
+		return nil, err
+	}
+
 	var user model.User
 	// emailでMySQLにユーザー情報問い合わせ
 	users, err := uuc.ur.List(ctx, []repository.QueryCondition{{Field: "Email", Value: email}})
 	if err != nil {
 		log.Printf("Error retrieving user by email")
-		return "", err
+		return nil, err
 	}
 	if len(users) > 0 {
 		user = users[0]
 	}
-	// 既にログイン済みかどうか確認する
-	session, _ := uuc.cr.GetUserSession(ctx, user.ID.String())
-	if session != "" {
-		log.Printf("Already logged in")
-		return "", fmt.Errorf("user id in cache")
-	}
-
 	// Clientから送られてきたpasswordをハッシュ化したものとMySQLから返されたハッシュ化されたpasswordを比較する
 	if err = auth.CompareHashAndPassword(user.Password, passward); err != nil {
 		log.Printf("password does not match")
-		return "", err
+		// 存在しないメールアドレスも同様に数え、登録の有無を知られないようにする
+		uuc.recordLoginFailure(ctx, targets, device.IP)
+		return nil, err
+	}
+	// IPアドレスの失敗回数は、攻撃者が自分のアカウントでログインしてリセットできないよう成功時もリセットしない
+	uuc.resetLoginFailures(ctx, targets[0])
+	if user.Suspended {
+		return nil, ErrAccountSuspended
+	}
+	// 2段階認証が有効な場合は、2段階目の認証(VerifyTwoFactorLogin)を終えるまでトークンを発行しない
+	if user.TOTPEnabled {
+		return nil, startTwoFactorLogin(ctx, uuc.tfr, &user, device)
+	}
+
+	// ログイン済みの端末があっても、端末ごとに別のセッションを作成する
+	return uuc.issueTokens(ctx, email, newSession(user.ID.String(), device))
+}
+
+// newSession は新しいセッションを作成します。セッションIDはリフレッシュトークンのFamilyIDとしても使います。
+func newSession(userID string, device Device) model.Session {
+	now := time.Now()
+	return model.Session{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		DeviceName: device.Name,
+		IP:         device.IP,
+		UserAgent:  device.UserAgent,
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}
+}
+
+// RefreshToken はリフレッシュトークンをローテーションし、新しいアクセストークンとリフレッシュトークンを発行します。
+// ローテーション済みのトークンが使われた場合は漏洩とみなし、同じFamilyのトークンとセッションをすべて失効させます。
+func (uuc *userUseCase) RefreshToken(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	tokenHash := auth.HashRefreshToken(refreshToken)
+	stored, err := uuc.cr.GetRefreshToken(ctx, tokenHash)
+	if errors.Is(err, repository.ErrCacheMiss) {
+		return nil, ErrInvalidRefreshToken
+	} else if err != nil {
+		log.Printf("Failed to get refresh token: %v", err)
+		return nil, err
+	}
+	if !time.Now().Before(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	current, err := uuc.cr.GetRefreshTokenFamily(ctx, stored.FamilyID)
+	if errors.Is(err, repository.ErrCacheMiss) {
+		// 再利用の検知やログアウトで失効済み
+		return nil, ErrInvalidRefreshToken
+	} else if err != nil {
+		log.Printf("Failed to get refresh token family: %v", err)
+		return nil, err
+	}
+	if current != tokenHash {
+		log.Printf("Refresh token reuse detected - user: %v, family: %v", stored.UserID, stored.FamilyID)
+		uuc.revokeTokenFamily(ctx, stored)
+		return nil, ErrRefreshTokenReused
+	}
+
+	// ログアウト済みの場合はセッションが削除されている
+	session, err := uuc.cr.GetSession(ctx, stored.UserID, stored.FamilyID)
+	if errors.Is(err, repository.ErrCacheMiss) {
+		uuc.revokeTokenFamily(ctx, stored)
+		return nil, ErrInvalidRefreshToken
+	} else if err != nil {
+		log.Printf("Failed to get user session: %v", err)
+		return nil, err
+	}
+
+	user, err := uuc.ur.Get(ctx, stored.UserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		uuc.revokeTokenFamily(ctx, stored)
+		return nil, ErrInvalidRefreshToken
+	} else if err != nil {
+		log.Printf("Failed to get user: %v", err)
+		return nil, err
+	}
+	if user.Suspended {
+		uuc.revokeTokenFamily(ctx, stored)
+		return nil, ErrAccountSuspended
+	}
+
+	// 同じトークンで同時にリフレッシュした場合は、Familyを置き換えられた1つだけが成功する
+	session.LastSeenAt = time.Now()
+	tokens, err := issueSessionTokens(ctx, uuc.cr, user.Email, *session, tokenHash)
+	if errors.Is(err, ErrRefreshTokenReused) {
+		log.Printf("Refresh token reuse detected - user: %v, family: %v", stored.UserID, stored.FamilyID)
+		uuc.revokeTokenFamily(ctx, stored)
+	}
+	return tokens, err
+}
+
+func (uuc *userUseCase) issueTokens(ctx context.Context, email string, session model.Session) (*TokenPair, error) {
+	return issueSessionTokens(ctx, uuc.cr, email, session, "")
+}
+
+// issueSessionTokens はセッションに対してアクセストークンとリフレッシュトークンを発行し、
+// セッションIDをFamilyIDとして現在有効なリフレッシュトークンを保存します。
+// previousHashを指定した場合は、Familyで現在有効なトークンがpreviousHashのときだけローテーションし、
+// 既に別のトークンに置き換わっていればErrRefreshTokenReusedを返します。
+func issueSessionTokens(
+	ctx context.Context,
+	cr repository.UserCacheRepository,
+	email string,
+	session model.Session,
+	previousHash string,
+) (*TokenPair, error) {
+	userID, familyID := session.UserID, session.ID
+	jwt, jti, err := auth.GenerateToken(userID, email, session.ID)
+	if err != nil {
+		log.Printf("Failed to generate access token: %v", err)
+		return nil, err
+	}
+	refreshToken, err := auth.GenerateRefreshToken()
+	if err != nil {
+		log.Printf("Failed to generate refresh token: %v", err)
+		return nil, err
 	}
 
-	jwt, jti := auth.GenerateToken(user.ID.String(), email)
-	if err = uuc.cr.SetUserSession(ctx, user.ID.String(), jti); err != nil {
+	tokenHash := auth.HashRefreshToken(refreshToken)
+	if err = setRefreshTokenFamily(ctx, cr, familyID, previousHash, tokenHash); err != nil {
+		return nil, err
+	}
+	if err = cr.SetRefreshToken(ctx, tokenHash, model.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		ExpiresAt: time.Now().Add(auth.RefreshTokenTTL),
+	}); err != nil {
+		log.Print("Failed to set refresh token in cache")
+		return nil, err
+	}
+	// アクセストークンの期限が切れても、リフレッシュトークンが有効な間はセッションを保持する
+	session.JTI = jti
+	if err = cr.SetSession(ctx, session, auth.RefreshTokenTTL); err != nil {
 		log.Print("Failed to set access token in cache")
-		return "", err
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  jwt,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(auth.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+// setRefreshTokenFamily はFamilyで現在有効なトークンをtokenHashにします。
+// previousHashを指定した場合は、比較と置き換えを不可分に行います。
+func setRefreshTokenFamily(
+	ctx context.Context,
+	cr repository.UserCacheRepository,
+	familyID string,
+	previousHash string,
+	tokenHash string,
+) error {
+	if previousHash == "" {
+		if err := cr.SetRefreshTokenFamily(ctx, familyID, tokenHash, auth.RefreshTokenTTL); err != nil {
+			log.Print("Failed to set refresh token family in cache")
+			return err
+		}
+		return nil
+	}
+	swapped, err := cr.SwapRefreshTokenFamily(ctx, familyID, previousHash, tokenHash, auth.RefreshTokenTTL)
+	if errors.Is(err, repository.ErrCacheMiss) {
+		return ErrInvalidRefreshToken
+	} else if err != nil {
+		log.Print("Failed to swap refresh token family in cache")
+		return err
+	}
+	if !swapped {
+		return ErrRefreshTokenReused
+	}
+	return nil
+}
+
+func (uuc *userUseCase) revokeTokenFamily(ctx context.Context, token *model.RefreshToken) {
+	if err := uuc.revokeSession(ctx, token.UserID, token.FamilyID); err != nil {
+		log.Printf("Failed to revoke session %v: %v", token.FamilyID, err)
+	}
+}
+
+func (uuc *userUseCase) revokeSession(ctx context.Context, userID, sessionID string) error {
+	return revokeSession(ctx, uuc.cr, userID, sessionID)
+}
+
+// revokeSession はセッションと、そのセッションのリフレッシュトークンを失効させます。
+func revokeSession(ctx context.Context, cr repository.UserCacheRepository, userID, sessionID string) error {
+	if err := cr.DeleteSession(ctx, userID, sessionID); err != nil {
+		return err
+	}
+	return cr.DeleteRefreshTokenFamily(ctx, sessionID)
+}
+
+// revokeSessions はexceptSessionID以外のすべてのセッションを失効させます。exceptSessionIDが空の場合はすべて失効させます。
+func revokeSessions(ctx context.Context, cr repository.UserCacheRepository, userID, exceptSessionID string) error {
+	sessions, err := cr.ListSessions(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to list sessions of %v: %v", userID, err)
+		return err
+	}
+	for _, session := range sessions {
+		if session.ID == exceptSessionID {
+			continue
+		}
+		if err = revokeSession(ctx, cr, userID, session.ID); err != nil {
+			log.Printf("Failed to revoke session %v: %v", session.ID, err)
+			return err
+		}
 	}
-	return jwt, nil
+	return nil
 }
 
-func (uuc *userUseCase) LogoutUser(ctx context.Context, userID string) error {
-	if err := uuc.cr.Delete(ctx, userID); err != nil {
-		log.Panicf("Failed to delete userID from cache")
+// LogoutUser はリクエストした端末のセッションのみを失効させます。
+func (uuc *userUseCase) LogoutUser(ctx context.Context, userID string, sessionID string) error {
+	if err := uuc.revokeSession(ctx, userID, sessionID); err != nil {
+		log.Printf("Failed to delete session from cache: %v", err)
 		return err
 	}
 	return nil
 }
+
+func (uuc *userUseCase) ListSessions(ctx context.Context, userID string) ([]model.Session, error) {
+	sessions, err := uuc.cr.ListSessions(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to list sessions of %v: %v", userID, err)
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// RevokeSession は指定したセッションを失効させます。他のユーザのセッションや失効済みのセッションの場合はErrNotFoundを返します。
+func (uuc *userUseCase) RevokeSession(ctx context.Context, userID string, sessionID string) error {
+	if _, err := uuc.cr.GetSession(ctx, userID, sessionID); errors.Is(err, repository.ErrCacheMiss) {
+		return repository.ErrNotFound
+	} else if err != nil {
+		log.Printf("Failed to get session %v: %v", sessionID, err)
+		return err
+	}
+	if err := uuc.revokeSession(ctx, userID, sessionID); err != nil {
+		log.Printf("Failed to revoke session %v: %v", sessionID, err)
+		return err
+	}
+	return nil
+}
+
+// RevokeAllSessions はリクエストした端末を含むすべてのセッションを失効させます。
+func (uuc *userUseCase) RevokeAllSessions(ctx context.Context, userID string) error {
+	return revokeSessions(ctx, uuc.cr, userID, "")
+}
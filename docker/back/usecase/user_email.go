@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/tusmasoma/campfinder/docker/back/config"
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+	"github.com/tusmasoma/campfinder/docker/back/internal/auth"
+	"github.com/tusmasoma/campfinder/docker/back/internal/mail"
+)
+
+const (
+	// EmailVerificationTokenTTL はメール確認のトークンの有効期間です。
+	EmailVerificationTokenTTL = 24 * time.Hour
+	// PasswordResetTokenTTL はパスワード再設定のトークンの有効期間です。
+	PasswordResetTokenTTL = time.Hour
+)
+
+// RequestEmailVerification は確認メールを再送します。以前に送ったトークンは使えなくなります。
+func (uuc *userUseCase) RequestEmailVerification(ctx context.Context, userID string) error {
+	user, err := uuc.ur.Get(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to get user: %v", err)
+		return err
+	}
+	if user.EmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+	if err = uuc.sendVerificationEmail(ctx, user); err != nil {
+		log.Printf("Failed to send verification email to %v: %v", user.ID, err)
+		return err
+	}
+	return nil
+}
+
+func (uuc *userUseCase) VerifyEmail(ctx context.Context, token string) error {
+	user, err := uuc.consumeUserToken(ctx, model.UserTokenPurposeVerifyEmail, token)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return nil
+	}
+	user.EmailVerified = true
+	if err = uuc.ur.Update(ctx, user.ID.String(), *user); err != nil {
+		log.Printf("Failed to update user: %v", err)
+		return err
+	}
+	return nil
+}
+
+// RequestPasswordReset はパスワード再設定のメールを送ります。登録済みのメールアドレスかどうかを知られないよう、
+// ユーザが存在しない場合もエラーを返しません。
+func (uuc *userUseCase) RequestPasswordReset(ctx context.Context, email string) error {
+	users, err := uuc.ur.List(ctx, []repository.QueryCondition{{Field: "Email", Value: email}})
+	if err != nil {
+		log.Printf("Error retrieving user by email")
+		return err
+	}
+	if len(users) == 0 {
+		log.Printf("Password reset requested for unknown email")
+		return nil
+	}
+	user := users[0]
+	return sendPasswordResetEmail(ctx, uuc.cr, uuc.mailer, uuc.mc, &user)
+}
+
+// sendPasswordResetEmail はパスワード再設定のトークンを発行し、ユーザのメールアドレスに送ります。
+func sendPasswordResetEmail(
+	ctx context.Context,
+	cr repository.UserCacheRepository,
+	mailer mail.Mailer,
+	mc *config.MailConfig,
+	user *model.User,
+) error {
+	token, err := issueUserToken(ctx, cr, user, model.UserTokenPurposeResetPassword, PasswordResetTokenTTL)
+	if err != nil {
+		return err
+	}
+	if err = mailer.Send(ctx, mail.Message{
+		From:    mc.From,
+		To:      user.Email,
+		Subject: "【CampFinder】パスワードの再設定",
+		Body: fmt.Sprintf(
+			"以下のリンクからパスワードを再設定してください。リンクの有効期限は%d分です。\n\n%s\n\n"+
+				"このメールに心当たりがない場合は破棄してください。パスワードは変更されません。\n",
+			int(PasswordResetTokenTTL.Minutes()),
+			userTokenLink(mc, "/reset-password", token),
+		),
+	}); err != nil {
+		log.Printf("Failed to send password reset email to %v: %v", user.ID, err)
+		return err
+	}
+	return nil
+}
+
+// ResetPassword はパスワードを再設定し、すべてのセッションを失効させます。
+// メールで届いたトークンを使えたことでメールアドレスの所有も確認できるため、メールアドレスを確認済みにします。
+func (uuc *userUseCase) ResetPassword(ctx context.Context, token string, password string) error {
+	user, err := uuc.consumeUserToken(ctx, model.UserTokenPurposeResetPassword, token)
+	if err != nil {
+		return err
+	}
+	hashed, err := auth.PasswordEncrypt(password)
+	if err != nil {
+		log.Printf("Internal server error: %v", err)
+		return err
+	}
+	user.Password = hashed
+	user.EmailVerified = true
+	if err = uuc.ur.Update(ctx, user.ID.String(), *user); err != nil {
+		log.Printf("Failed to update user: %v", err)
+		return err
+	}
+	return uuc.RevokeAllSessions(ctx, user.ID.String())
+}
+
+func (uuc *userUseCase) sendVerificationEmail(ctx context.Context, user *model.User) error {
+	token, err := issueUserToken(ctx, uuc.cr, user, model.UserTokenPurposeVerifyEmail, EmailVerificationTokenTTL)
+	if err != nil {
+		return err
+	}
+	return uuc.mailer.Send(ctx, mail.Message{
+		From:    uuc.mc.From,
+		To:      user.Email,
+		Subject: "【CampFinder】メールアドレスの確認",
+		Body: fmt.Sprintf(
+			"CampFinderにご登録いただきありがとうございます。\n"+
+				"以下のリンクからメールアドレスを確認してください。リンクの有効期限は%d時間です。\n\n%s\n",
+			int(EmailVerificationTokenTTL.Hours()),
+			userTokenLink(uuc.mc, "/verify-email", token),
+		),
+	})
+}
+
+// issueUserToken は使い捨てのトークンを発行し、ハッシュのみを保存します。
+func issueUserToken(
+	ctx context.Context,
+	cr repository.UserCacheRepository,
+	user *model.User,
+	purpose model.UserTokenPurpose,
+	ttl time.Duration,
+) (string, error) {
+	token, err := auth.GenerateUserToken()
+	if err != nil {
+		log.Printf("Failed to generate %v token: %v", purpose, err)
+		return "", err
+	}
+	if err = cr.SetUserToken(ctx, auth.HashToken(token), model.UserToken{
+		UserID:    user.ID.String(),
+		Email:     user.Email,
+		Purpose:   purpose,
+		ExpiresAt: time.Now().Add(ttl),
+	}); err != nil {
+		log.Printf("Failed to set %v token in cache: %v", purpose, err)
+		return "", err
+	}
+	return token, nil
+}
+
+// consumeUserToken はトークンを使用済みにし、トークンを発行したユーザを返します。
+// 発行後にメールアドレスが変更された場合は、古いメールアドレスに送ったトークンとして拒否します。
+func (uuc *userUseCase) consumeUserToken(
+	ctx context.Context,
+	purpose model.UserTokenPurpose,
+	token string,
+) (*model.User, error) {
+	stored, err := uuc.cr.ConsumeUserToken(ctx, purpose, auth.HashToken(token))
+	if errors.Is(err, repository.ErrCacheMiss) {
+		return nil, ErrInvalidUserToken
+	} else if err != nil {
+		log.Printf("Failed to get %v token: %v", purpose, err)
+		return nil, err
+	}
+	if !time.Now().Before(stored.ExpiresAt) {
+		return nil, ErrInvalidUserToken
+	}
+
+	user, err := uuc.ur.Get(ctx, stored.UserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvalidUserToken
+	} else if err != nil {
+		log.Printf("Failed to get user: %v", err)
+		return nil, err
+	}
+	if user.Email != stored.Email {
+		return nil, ErrInvalidUserToken
+	}
+	return user, nil
+}
+
+func userTokenLink(mc *config.MailConfig, path string, token string) string {
+	return mc.AppBaseURL + path + "?token=" + url.QueryEscape(token)
+}
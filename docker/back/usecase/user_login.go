@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+)
+
+// LoginLockoutsMaxLen は保持するロックの記録の件数です。
+const LoginLockoutsMaxLen = 1000
+
+// loginAttemptTarget はログインの失敗回数を数える対象と、ロックするまでの失敗回数です。
+type loginAttemptTarget struct {
+	kind        model.LoginAttemptKind
+	identifier  string
+	maxFailures int64
+}
+
+// loginAttemptTargets はメールアドレスと、分かる場合はIPアドレスを返します。先頭は常にメールアドレスです。
+func (uuc *userUseCase) loginAttemptTargets(email string, ip string) []loginAttemptTarget {
+	targets := []loginAttemptTarget{{
+		kind:        model.LoginAttemptKindEmail,
+		identifier:  strings.ToLower(strings.TrimSpace(email)),
+		maxFailures: uuc.lc.MaxFailures,
+	}}
+	if ip != "" {
+		targets = append(targets, loginAttemptTarget{
+			kind:        model.LoginAttemptKindIP,
+			identifier:  ip,
+			maxFailures: uuc.lc.IPMaxFailures,
+		})
+	}
+	return targets
+}
+
+// checkLoginThrottle はいずれかの対象がロック中の場合、最も長い待ち時間を持つLoginThrottledErrorを返します。
+func (uuc *userUseCase) checkLoginThrottle(ctx context.Context, targets []loginAttemptTarget) error {
+	var retryAfter time.Duration
+	for _, target := range targets {
+		remaining, err := uuc.lr.LockRemaining(ctx, target.kind, target.identifier)
+		if err != nil {
+			log.Printf("Failed to get login lock of %v: %v", target.kind, err)
+			return err
+		}
+		if remaining > retryAfter {
+			retryAfter = remaining
+		}
+	}
+	if retryAfter > 0 {
+		return &LoginThrottledError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// recordLoginFailure は失敗回数を増やし、次の試行まで待たせます。失敗回数が上限に達した場合はロックして記録を残します。
+// ログイン自体は失敗しているため、保存に失敗してもログに残すのみです。
+func (uuc *userUseCase) recordLoginFailure(ctx context.Context, targets []loginAttemptTarget, ip string) {
+	for _, target := range targets {
+		failures, err := uuc.lr.AddFailure(ctx, target.kind, target.identifier, uuc.lc.FailureWindow)
+		if err != nil {
+			log.Printf("Failed to record login failure of %v: %v", target.kind, err)
+			continue
+		}
+
+		if failures < target.maxFailures {
+			if err = uuc.lr.Lock(ctx, target.kind, target.identifier, uuc.loginBackoff(failures)); err != nil {
+				log.Printf("Failed to delay login of %v: %v", target.kind, err)
+			}
+			continue
+		}
+
+		if err = uuc.lr.Lock(ctx, target.kind, target.identifier, uuc.lc.LockoutDuration); err != nil {
+			log.Printf("Failed to lock login of %v: %v", target.kind, err)
+			continue
+		}
+		now := time.Now()
+		lockout := model.LoginLockout{
+			Kind:        target.kind,
+			Identifier:  target.identifier,
+			IP:          ip,
+			Failures:    failures,
+			LockedUntil: now.Add(uuc.lc.LockoutDuration),
+			CreatedAt:   now,
+		}
+		log.Printf("Login locked - %v: %v, failures: %v, ip: %v", target.kind, target.identifier, failures, ip)
+		if err = uuc.lr.AddLockout(ctx, lockout, LoginLockoutsMaxLen); err != nil {
+			log.Printf("Failed to record login lockout: %v", err)
+		}
+	}
+}
+
+func (uuc *userUseCase) resetLoginFailures(ctx context.Context, target loginAttemptTarget) {
+	if err := uuc.lr.Reset(ctx, target.kind, target.identifier); err != nil {
+		log.Printf("Failed to reset login failures of %v: %v", target.kind, err)
+	}
+}
+
+// loginBackoff はfailures回目の失敗の後に待たせる時間で、BaseDelayから倍々に増やしてMaxDelayで打ち止めにします。
+func (uuc *userUseCase) loginBackoff(failures int64) time.Duration {
+	delay := uuc.lc.BaseDelay
+	for i := int64(1); i < failures && delay < uuc.lc.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > uuc.lc.MaxDelay {
+		return uuc.lc.MaxDelay
+	}
+	return delay
+}
@@ -2,22 +2,42 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 
+	"github.com/tusmasoma/campfinder/docker/back/config"
 	"github.com/tusmasoma/campfinder/docker/back/domain/model"
 	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
 	"github.com/tusmasoma/campfinder/docker/back/domain/repository/mock"
 	"github.com/tusmasoma/campfinder/docker/back/internal/auth"
+	"github.com/tusmasoma/campfinder/docker/back/internal/mail"
+	mailmock "github.com/tusmasoma/campfinder/docker/back/internal/mail/mock"
+)
+
+var (
+	testMailConfig          = &config.MailConfig{From: "no-reply@campfinder.local", AppBaseURL: "https://campfinder.example"}
+	testLoginThrottleConfig = &config.LoginThrottleConfig{
+		MaxFailures:     5,
+		IPMaxFailures:   20,
+		BaseDelay:       time.Second,
+		MaxDelay:        time.Minute,
+		LockoutDuration: 15 * time.Minute,
+		FailureWindow:   15 * time.Minute,
+	}
+	testTwoFactorConfig = &config.TwoFactorConfig{Issuer: "CampFinder", RequireForAdmins: true}
 )
 
 type CreateUserAndGenerateTokenArg struct {
 	ctx      context.Context
 	email    string
 	passward string
+	device   Device
 }
 
 func TestUserUseCase_CreateUserAndGenerateToken(t *testing.T) {
@@ -26,14 +46,16 @@ func TestUserUseCase_CreateUserAndGenerateToken(t *testing.T) {
 		setup func(
 			m *mock.MockUserRepository,
 			m1 *mock.MockUserCacheRepository,
+			m2 *mailmock.MockMailer,
 		)
 		arg     CreateUserAndGenerateTokenArg
 		wantErr error
 	}{
 		{
 			name: "success",
-			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository) {
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository, m2 *mailmock.MockMailer) {
 				t.Setenv("PRIVATE_KEY_PATH", "../../../.certificate/private_key.pem")
+				t.Setenv("PUBLIC_KEY_PATH", "../../../.certificate/public_key.pem")
 				m.EXPECT().List(
 					gomock.Any(),
 					[]repository.QueryCondition{{Field: "Email", Value: "test@gmail.com"}},
@@ -42,7 +64,26 @@ func TestUserUseCase_CreateUserAndGenerateToken(t *testing.T) {
 					gomock.Any(),
 					gomock.Any(),
 				).Return(nil)
-				m1.EXPECT().SetUserSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				m1.EXPECT().SetRefreshToken(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				m1.EXPECT().SetRefreshTokenFamily(gomock.Any(), gomock.Any(), gomock.Any(), auth.RefreshTokenTTL).Return(nil)
+				m1.EXPECT().SetSession(gomock.Any(), gomock.Any(), auth.RefreshTokenTTL).Return(nil)
+				// 登録したメールアドレスに確認メールを送る
+				m1.EXPECT().SetUserToken(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ string, token model.UserToken) error {
+						if token.Purpose != model.UserTokenPurposeVerifyEmail || token.Email != "test@gmail.com" {
+							t.Errorf("unexpected user token: %+v", token)
+						}
+						return nil
+					},
+				)
+				m2.EXPECT().Send(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, msg mail.Message) error {
+						if msg.To != "test@gmail.com" || !strings.Contains(msg.Body, "https://campfinder.example/verify-email?token=") {
+							t.Errorf("unexpected message: %+v", msg)
+						}
+						return nil
+					},
+				)
 			},
 			arg: CreateUserAndGenerateTokenArg{
 				ctx:      context.Background(),
@@ -53,7 +94,7 @@ func TestUserUseCase_CreateUserAndGenerateToken(t *testing.T) {
 		},
 		{
 			name: "Fail: Username already exists",
-			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository) {
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository, m2 *mailmock.MockMailer) {
 				m.EXPECT().List(
 					gomock.Any(),
 					[]repository.QueryCondition{{Field: "Email", Value: "test@gmail.com"}},
@@ -73,13 +114,14 @@ func TestUserUseCase_CreateUserAndGenerateToken(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			ur := mock.NewMockUserRepository(ctrl)
 			cr := mock.NewMockUserCacheRepository(ctrl)
+			mm := mailmock.NewMockMailer(ctrl)
 
 			if tt.setup != nil {
-				tt.setup(ur, cr)
+				tt.setup(ur, cr, mm)
 			}
 
-			usecase := NewUserUseCase(ur, cr)
-			jwt, err := usecase.CreateUserAndGenerateToken(tt.arg.ctx, tt.arg.email, tt.arg.passward)
+			usecase := NewUserUseCase(ur, cr, mock.NewMockLoginAttemptCacheRepository(ctrl), mock.NewMockRecoveryCodeRepository(ctrl), mock.NewMockTwoFactorCacheRepository(ctrl), mm, testMailConfig, testLoginThrottleConfig, testTwoFactorConfig)
+			tokens, err := usecase.CreateUserAndGenerateToken(tt.arg.ctx, tt.arg.email, tt.arg.passward, tt.arg.device)
 
 			if (err != nil) != (tt.wantErr != nil) {
 				t.Errorf("CreateUserAndGenerateToken() error = %v, wantErr %v", err, tt.wantErr)
@@ -87,7 +129,7 @@ func TestUserUseCase_CreateUserAndGenerateToken(t *testing.T) {
 				t.Errorf("CreateUserAndGenerateToken() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
-			if tt.wantErr == nil && jwt == "" {
+			if tt.wantErr == nil && (tokens.AccessToken == "" || tokens.RefreshToken == "") {
 				t.Error("Failed to generate token")
 			}
 		})
@@ -100,14 +142,23 @@ func TestUserUseCase_LoginAndGenerateToken(t *testing.T) {
 		setup func(
 			m *mock.MockUserRepository,
 			m1 *mock.MockUserCacheRepository,
+			m2 *mock.MockLoginAttemptCacheRepository,
 		)
 		arg     CreateUserAndGenerateTokenArg
 		wantErr error
 	}{
 		{
 			name: "success",
-			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository) {
+			setup: func(
+				m *mock.MockUserRepository,
+				m1 *mock.MockUserCacheRepository,
+				m2 *mock.MockLoginAttemptCacheRepository,
+			) {
+				m2.EXPECT().LockRemaining(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(time.Duration(0), nil)
+				m2.EXPECT().LockRemaining(gomock.Any(), model.LoginAttemptKindIP, "192.0.2.1").Return(time.Duration(0), nil)
+				m2.EXPECT().Reset(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(nil)
 				t.Setenv("PRIVATE_KEY_PATH", "../../../.certificate/private_key.pem")
+				t.Setenv("PUBLIC_KEY_PATH", "../../../.certificate/public_key.pem")
 				passward, _ := auth.PasswordEncrypt("password123")
 				m.EXPECT().List(
 					gomock.Any(),
@@ -123,56 +174,87 @@ func TestUserUseCase_LoginAndGenerateToken(t *testing.T) {
 						},
 					}, nil,
 				)
-				m1.EXPECT().GetUserSession(
-					gomock.Any(),
-					"f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
-				).Return("", nil)
-				m1.EXPECT().SetUserSession(
-					gomock.Any(),
-					"f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
-					gomock.Any(),
-				).Return(nil)
+				// 他の端末でログイン済みでも、新しいセッションを作成する
+				var familyID string
+				m1.EXPECT().SetRefreshToken(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				m1.EXPECT().SetRefreshTokenFamily(gomock.Any(), gomock.Any(), gomock.Any(), auth.RefreshTokenTTL).DoAndReturn(
+					func(_ context.Context, id string, _ string, _ time.Duration) error {
+						familyID = id
+						return nil
+					},
+				)
+				m1.EXPECT().SetSession(gomock.Any(), gomock.Any(), auth.RefreshTokenTTL).DoAndReturn(
+					func(_ context.Context, session model.Session, _ time.Duration) error {
+						if session.ID != familyID || session.UserID != "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2" ||
+							session.JTI == "" || session.DeviceName != "laptop" || session.IP != "192.0.2.1" ||
+							session.UserAgent != "Mozilla/5.0" || session.CreatedAt.IsZero() {
+							t.Errorf("unexpected session: %+v", session)
+						}
+						return nil
+					},
+				)
 			},
 			arg: CreateUserAndGenerateTokenArg{
 				ctx:      context.Background(),
 				email:    "test@gmail.com",
 				passward: "password123",
+				device:   Device{Name: "laptop", IP: "192.0.2.1", UserAgent: "Mozilla/5.0"},
 			},
 			wantErr: nil,
 		},
 		{
-			name: "Fail: already logged in",
-			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository) {
+			name: "Fail: suspended user",
+			setup: func(
+				m *mock.MockUserRepository,
+				_ *mock.MockUserCacheRepository,
+				m2 *mock.MockLoginAttemptCacheRepository,
+			) {
+				m2.EXPECT().LockRemaining(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(time.Duration(0), nil)
+				m2.EXPECT().Reset(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(nil)
 				passward, _ := auth.PasswordEncrypt("password123")
 				m.EXPECT().List(
 					gomock.Any(),
 					[]repository.QueryCondition{{Field: "Email", Value: "test@gmail.com"}},
-				).Return(
-					[]model.User{
-						{
-							ID:       uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"),
-							Name:     "test",
-							Email:    "test@gmail.com",
-							Password: passward,
-							IsAdmin:  false,
-						},
-					}, nil,
-				)
-				m1.EXPECT().GetUserSession(
+				).Return([]model.User{{Email: "test@gmail.com", Password: passward, Suspended: true}}, nil)
+			},
+			arg: CreateUserAndGenerateTokenArg{
+				ctx:      context.Background(),
+				email:    "test@gmail.com",
+				passward: "password123",
+			},
+			wantErr: ErrAccountSuspended,
+		},
+		{
+			name: "Fail: user not found",
+			setup: func(
+				m *mock.MockUserRepository,
+				m1 *mock.MockUserCacheRepository,
+				m2 *mock.MockLoginAttemptCacheRepository,
+			) {
+				m2.EXPECT().LockRemaining(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(time.Duration(0), nil)
+				m.EXPECT().List(
 					gomock.Any(),
-					"f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
-				).Return("session", nil)
+					[]repository.QueryCondition{{Field: "Email", Value: "test@gmail.com"}},
+				).Return([]model.User{}, nil)
+				// 存在しないメールアドレスでも失敗として数える
+				m2.EXPECT().AddFailure(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com", 15*time.Minute).Return(int64(1), nil)
+				m2.EXPECT().Lock(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com", time.Second).Return(nil)
 			},
 			arg: CreateUserAndGenerateTokenArg{
 				ctx:      context.Background(),
 				email:    "test@gmail.com",
 				passward: "password123",
 			},
-			wantErr: fmt.Errorf("user id in cache"),
+			wantErr: fmt.Errorf("crypto/bcrypt: hashedSecret too short to be a bcrypted password"),
 		},
 		{
 			name: "Fail: invalid passward",
-			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository) {
+			setup: func(
+				m *mock.MockUserRepository,
+				m1 *mock.MockUserCacheRepository,
+				m2 *mock.MockLoginAttemptCacheRepository,
+			) {
+				m2.EXPECT().LockRemaining(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(time.Duration(0), nil)
 				passward, _ := auth.PasswordEncrypt("password456")
 				m.EXPECT().List(
 					gomock.Any(),
@@ -188,10 +270,8 @@ func TestUserUseCase_LoginAndGenerateToken(t *testing.T) {
 						},
 					}, nil,
 				)
-				m1.EXPECT().GetUserSession(
-					gomock.Any(),
-					"f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
-				).Return("", nil)
+				m2.EXPECT().AddFailure(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com", 15*time.Minute).Return(int64(3), nil)
+				m2.EXPECT().Lock(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com", 4*time.Second).Return(nil)
 			},
 			arg: CreateUserAndGenerateTokenArg{
 				ctx:      context.Background(),
@@ -200,6 +280,62 @@ func TestUserUseCase_LoginAndGenerateToken(t *testing.T) {
 			},
 			wantErr: fmt.Errorf("crypto/bcrypt: hashedPassword is not the hash of the given password"),
 		},
+		{
+			name: "Fail: invalid passward reaches lockout",
+			setup: func(
+				m *mock.MockUserRepository,
+				m1 *mock.MockUserCacheRepository,
+				m2 *mock.MockLoginAttemptCacheRepository,
+			) {
+				m2.EXPECT().LockRemaining(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(time.Duration(0), nil)
+				m2.EXPECT().LockRemaining(gomock.Any(), model.LoginAttemptKindIP, "192.0.2.1").Return(time.Duration(0), nil)
+				passward, _ := auth.PasswordEncrypt("password456")
+				m.EXPECT().List(
+					gomock.Any(),
+					[]repository.QueryCondition{{Field: "Email", Value: "Test@gmail.com"}},
+				).Return([]model.User{{Email: "test@gmail.com", Password: passward}}, nil)
+				m2.EXPECT().AddFailure(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com", 15*time.Minute).Return(int64(5), nil)
+				m2.EXPECT().Lock(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com", 15*time.Minute).Return(nil)
+				m2.EXPECT().AddLockout(gomock.Any(), gomock.Any(), int64(LoginLockoutsMaxLen)).DoAndReturn(
+					func(_ context.Context, lockout model.LoginLockout, _ int64) error {
+						if lockout.Kind != model.LoginAttemptKindEmail || lockout.Identifier != "test@gmail.com" ||
+							lockout.IP != "192.0.2.1" || lockout.Failures != 5 || !lockout.LockedUntil.After(lockout.CreatedAt) {
+							t.Errorf("unexpected lockout: %+v", lockout)
+						}
+						return nil
+					},
+				)
+				// IPアドレスの失敗回数はまだ上限に達していない
+				m2.EXPECT().AddFailure(gomock.Any(), model.LoginAttemptKindIP, "192.0.2.1", 15*time.Minute).Return(int64(7), nil)
+				m2.EXPECT().Lock(gomock.Any(), model.LoginAttemptKindIP, "192.0.2.1", time.Minute).Return(nil)
+			},
+			arg: CreateUserAndGenerateTokenArg{
+				ctx:      context.Background(),
+				email:    "Test@gmail.com",
+				passward: "password123",
+				device:   Device{IP: "192.0.2.1"},
+			},
+			wantErr: fmt.Errorf("crypto/bcrypt: hashedPassword is not the hash of the given password"),
+		},
+		{
+			name: "Fail: locked out",
+			setup: func(
+				m *mock.MockUserRepository,
+				m1 *mock.MockUserCacheRepository,
+				m2 *mock.MockLoginAttemptCacheRepository,
+			) {
+				// パスワードを確認せずに、長い方の待ち時間を返す
+				m2.EXPECT().LockRemaining(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(30*time.Second, nil)
+				m2.EXPECT().LockRemaining(gomock.Any(), model.LoginAttemptKindIP, "192.0.2.1").Return(2*time.Second, nil)
+			},
+			arg: CreateUserAndGenerateTokenArg{
+				ctx:      context.Background(),
+				email:    "test@gmail.com",
+				passward: "password123",
+				device:   Device{IP: "192.0.2.1"},
+			},
+			wantErr: &LoginThrottledError{RetryAfter: 30 * time.Second},
+		},
 	}
 
 	for _, tt := range patterns {
@@ -208,13 +344,14 @@ func TestUserUseCase_LoginAndGenerateToken(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			ur := mock.NewMockUserRepository(ctrl)
 			cr := mock.NewMockUserCacheRepository(ctrl)
+			lr := mock.NewMockLoginAttemptCacheRepository(ctrl)
 
 			if tt.setup != nil {
-				tt.setup(ur, cr)
+				tt.setup(ur, cr, lr)
 			}
 
-			usecase := NewUserUseCase(ur, cr)
-			jwt, err := usecase.LoginAndGenerateToken(tt.arg.ctx, tt.arg.email, tt.arg.passward)
+			usecase := NewUserUseCase(ur, cr, lr, mock.NewMockRecoveryCodeRepository(ctrl), mock.NewMockTwoFactorCacheRepository(ctrl), mailmock.NewMockMailer(ctrl), testMailConfig, testLoginThrottleConfig, testTwoFactorConfig)
+			tokens, err := usecase.LoginAndGenerateToken(tt.arg.ctx, tt.arg.email, tt.arg.passward, tt.arg.device)
 
 			if (err != nil) != (tt.wantErr != nil) {
 				t.Errorf("LoginAndGenerateToken() error = %v, wantErr %v", err, tt.wantErr)
@@ -222,9 +359,513 @@ func TestUserUseCase_LoginAndGenerateToken(t *testing.T) {
 				t.Errorf("LoginAndGenerateToken() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
-			if tt.wantErr == nil && jwt == "" {
+			if tt.wantErr == nil && (tokens.AccessToken == "" || tokens.RefreshToken == "") {
 				t.Error("Failed to generate token")
 			}
 		})
 	}
 }
+
+func TestUserUseCase_RefreshToken(t *testing.T) {
+	refreshToken := "refresh-token"
+	tokenHash := auth.HashRefreshToken(refreshToken)
+	session := model.Session{
+		ID:         "2b5a4b1e-3f6c-4a8e-9d6b-7e8f9a0b1c2d",
+		UserID:     "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
+		JTI:        "d3b07384-d113-4ec6-a7d7-9a3bb5d3c8f5",
+		DeviceName: "phone",
+		CreatedAt:  time.Now().Add(-time.Hour),
+		LastSeenAt: time.Now().Add(-time.Hour),
+	}
+	stored := model.RefreshToken{
+		UserID:    "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2",
+		FamilyID:  "2b5a4b1e-3f6c-4a8e-9d6b-7e8f9a0b1c2d",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockUserRepository,
+			m1 *mock.MockUserCacheRepository,
+		)
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository) {
+				t.Setenv("PRIVATE_KEY_PATH", "../../../.certificate/private_key.pem")
+				t.Setenv("PUBLIC_KEY_PATH", "../../../.certificate/public_key.pem")
+				m1.EXPECT().GetRefreshToken(gomock.Any(), tokenHash).Return(&stored, nil)
+				m1.EXPECT().GetRefreshTokenFamily(gomock.Any(), stored.FamilyID).Return(tokenHash, nil)
+				current := session
+				m1.EXPECT().GetSession(gomock.Any(), stored.UserID, stored.FamilyID).Return(&current, nil)
+				m.EXPECT().Get(gomock.Any(), stored.UserID).Return(&model.User{
+					ID:    uuid.MustParse(stored.UserID),
+					Email: "test@gmail.com",
+				}, nil)
+				// 同じFamilyで新しいトークンにローテーションする
+				m1.EXPECT().SetRefreshToken(gomock.Any(), gomock.Not(tokenHash), gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ string, token model.RefreshToken) error {
+						if token.FamilyID != stored.FamilyID || token.UserID != stored.UserID {
+							t.Errorf("unexpected refresh token: %+v", token)
+						}
+						return nil
+					},
+				)
+				m1.EXPECT().SwapRefreshTokenFamily(
+					gomock.Any(),
+					stored.FamilyID,
+					tokenHash,
+					gomock.Not(tokenHash),
+					auth.RefreshTokenTTL,
+				).Return(true, nil)
+				// 端末情報を引き継ぎ、jtiと最終アクセス日時のみ更新する
+				m1.EXPECT().SetSession(gomock.Any(), gomock.Any(), auth.RefreshTokenTTL).DoAndReturn(
+					func(_ context.Context, got model.Session, _ time.Duration) error {
+						if got.ID != session.ID || got.DeviceName != session.DeviceName || !got.CreatedAt.Equal(session.CreatedAt) ||
+							got.JTI == session.JTI || !got.LastSeenAt.After(session.LastSeenAt) {
+							t.Errorf("unexpected session: %+v", got)
+						}
+						return nil
+					},
+				)
+			},
+		},
+		{
+			name: "Fail: unknown token",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository) {
+				m1.EXPECT().GetRefreshToken(gomock.Any(), tokenHash).Return(nil, repository.ErrCacheMiss)
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "Fail: expired",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository) {
+				expired := stored
+				expired.ExpiresAt = time.Now().Add(-time.Second)
+				m1.EXPECT().GetRefreshToken(gomock.Any(), tokenHash).Return(&expired, nil)
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "Fail: family revoked",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository) {
+				m1.EXPECT().GetRefreshToken(gomock.Any(), tokenHash).Return(&stored, nil)
+				m1.EXPECT().GetRefreshTokenFamily(gomock.Any(), stored.FamilyID).Return("", repository.ErrCacheMiss)
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "Fail: reuse of rotated token revokes family",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository) {
+				m1.EXPECT().GetRefreshToken(gomock.Any(), tokenHash).Return(&stored, nil)
+				m1.EXPECT().GetRefreshTokenFamily(gomock.Any(), stored.FamilyID).Return("rotated-token-hash", nil)
+				m1.EXPECT().DeleteSession(gomock.Any(), stored.UserID, stored.FamilyID).Return(nil)
+				m1.EXPECT().DeleteRefreshTokenFamily(gomock.Any(), stored.FamilyID).Return(nil)
+			},
+			wantErr: ErrRefreshTokenReused,
+		},
+		{
+			// 同じトークンでの同時のリフレッシュで、先に別のトークンへローテーションされていた
+			name: "Fail: concurrent refresh with same token revokes family",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository) {
+				t.Setenv("PRIVATE_KEY_PATH", "../../../.certificate/private_key.pem")
+				t.Setenv("PUBLIC_KEY_PATH", "../../../.certificate/public_key.pem")
+				m1.EXPECT().GetRefreshToken(gomock.Any(), tokenHash).Return(&stored, nil)
+				m1.EXPECT().GetRefreshTokenFamily(gomock.Any(), stored.FamilyID).Return(tokenHash, nil)
+				current := session
+				m1.EXPECT().GetSession(gomock.Any(), stored.UserID, stored.FamilyID).Return(&current, nil)
+				m.EXPECT().Get(gomock.Any(), stored.UserID).Return(&model.User{
+					ID:    uuid.MustParse(stored.UserID),
+					Email: "test@gmail.com",
+				}, nil)
+				m1.EXPECT().SwapRefreshTokenFamily(
+					gomock.Any(), stored.FamilyID, tokenHash, gomock.Any(), auth.RefreshTokenTTL,
+				).Return(false, nil)
+				m1.EXPECT().DeleteSession(gomock.Any(), stored.UserID, stored.FamilyID).Return(nil)
+				m1.EXPECT().DeleteRefreshTokenFamily(gomock.Any(), stored.FamilyID).Return(nil)
+			},
+			wantErr: ErrRefreshTokenReused,
+		},
+		{
+			name: "Fail: logged out",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository) {
+				m1.EXPECT().GetRefreshToken(gomock.Any(), tokenHash).Return(&stored, nil)
+				m1.EXPECT().GetRefreshTokenFamily(gomock.Any(), stored.FamilyID).Return(tokenHash, nil)
+				m1.EXPECT().GetSession(gomock.Any(), stored.UserID, stored.FamilyID).Return(nil, repository.ErrCacheMiss)
+				m1.EXPECT().DeleteSession(gomock.Any(), stored.UserID, stored.FamilyID).Return(nil)
+				m1.EXPECT().DeleteRefreshTokenFamily(gomock.Any(), stored.FamilyID).Return(nil)
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			ur := mock.NewMockUserRepository(ctrl)
+			cr := mock.NewMockUserCacheRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ur, cr)
+			}
+
+			usecase := NewUserUseCase(ur, cr, mock.NewMockLoginAttemptCacheRepository(ctrl), mock.NewMockRecoveryCodeRepository(ctrl), mock.NewMockTwoFactorCacheRepository(ctrl), mailmock.NewMockMailer(ctrl), testMailConfig, testLoginThrottleConfig, testTwoFactorConfig)
+			tokens, err := usecase.RefreshToken(context.Background(), refreshToken)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("RefreshToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (tokens.AccessToken == "" || tokens.RefreshToken == refreshToken) {
+				t.Errorf("RefreshToken() = %+v, want rotated tokens", tokens)
+			}
+		})
+	}
+}
+
+func TestUserUseCase_RevokeSession(t *testing.T) {
+	userID := "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"
+	sessionID := "2b5a4b1e-3f6c-4a8e-9d6b-7e8f9a0b1c2d"
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockUserCacheRepository,
+		)
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockUserCacheRepository) {
+				m.EXPECT().GetSession(gomock.Any(), userID, sessionID).Return(&model.Session{ID: sessionID, UserID: userID}, nil)
+				m.EXPECT().DeleteSession(gomock.Any(), userID, sessionID).Return(nil)
+				m.EXPECT().DeleteRefreshTokenFamily(gomock.Any(), sessionID).Return(nil)
+			},
+		},
+		{
+			name: "Fail: session not found",
+			setup: func(m *mock.MockUserCacheRepository) {
+				m.EXPECT().GetSession(gomock.Any(), userID, sessionID).Return(nil, repository.ErrCacheMiss)
+			},
+			wantErr: repository.ErrNotFound,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			ur := mock.NewMockUserRepository(ctrl)
+			cr := mock.NewMockUserCacheRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(cr)
+			}
+
+			usecase := NewUserUseCase(ur, cr, mock.NewMockLoginAttemptCacheRepository(ctrl), mock.NewMockRecoveryCodeRepository(ctrl), mock.NewMockTwoFactorCacheRepository(ctrl), mailmock.NewMockMailer(ctrl), testMailConfig, testLoginThrottleConfig, testTwoFactorConfig)
+			err := usecase.RevokeSession(context.Background(), userID, sessionID)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("RevokeSession() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserUseCase_RevokeAllSessions(t *testing.T) {
+	userID := "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockUserCacheRepository,
+		)
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockUserCacheRepository) {
+				m.EXPECT().ListSessions(gomock.Any(), userID).Return([]model.Session{
+					{ID: "2b5a4b1e-3f6c-4a8e-9d6b-7e8f9a0b1c2d", UserID: userID},
+					{ID: "8c1f0e6a-52a4-4d3b-9f0e-1a2b3c4d5e6f", UserID: userID},
+				}, nil)
+				m.EXPECT().DeleteSession(gomock.Any(), userID, "2b5a4b1e-3f6c-4a8e-9d6b-7e8f9a0b1c2d").Return(nil)
+				m.EXPECT().DeleteRefreshTokenFamily(gomock.Any(), "2b5a4b1e-3f6c-4a8e-9d6b-7e8f9a0b1c2d").Return(nil)
+				m.EXPECT().DeleteSession(gomock.Any(), userID, "8c1f0e6a-52a4-4d3b-9f0e-1a2b3c4d5e6f").Return(nil)
+				m.EXPECT().DeleteRefreshTokenFamily(gomock.Any(), "8c1f0e6a-52a4-4d3b-9f0e-1a2b3c4d5e6f").Return(nil)
+			},
+		},
+		{
+			name: "Fail: list sessions",
+			setup: func(m *mock.MockUserCacheRepository) {
+				m.EXPECT().ListSessions(gomock.Any(), userID).Return(nil, errors.New("connection refused"))
+			},
+			wantErr: errors.New("connection refused"),
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			ur := mock.NewMockUserRepository(ctrl)
+			cr := mock.NewMockUserCacheRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(cr)
+			}
+
+			usecase := NewUserUseCase(ur, cr, mock.NewMockLoginAttemptCacheRepository(ctrl), mock.NewMockRecoveryCodeRepository(ctrl), mock.NewMockTwoFactorCacheRepository(ctrl), mailmock.NewMockMailer(ctrl), testMailConfig, testLoginThrottleConfig, testTwoFactorConfig)
+			err := usecase.RevokeAllSessions(context.Background(), userID)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("RevokeAllSessions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserUseCase_VerifyEmail(t *testing.T) {
+	token := "verify-token"
+	tokenHash := auth.HashToken(token)
+	userID := "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"
+	stored := model.UserToken{
+		UserID:    userID,
+		Email:     "test@gmail.com",
+		Purpose:   model.UserTokenPurposeVerifyEmail,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	user := model.User{ID: uuid.MustParse(userID), Email: "test@gmail.com"}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockUserRepository,
+			m1 *mock.MockUserCacheRepository,
+		)
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository) {
+				m1.EXPECT().ConsumeUserToken(gomock.Any(), model.UserTokenPurposeVerifyEmail, tokenHash).Return(&stored, nil)
+				current := user
+				m.EXPECT().Get(gomock.Any(), userID).Return(&current, nil)
+				verified := user
+				verified.EmailVerified = true
+				m.EXPECT().Update(gomock.Any(), userID, verified).Return(nil)
+			},
+		},
+		{
+			name: "Fail: used or unknown token",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository) {
+				m1.EXPECT().ConsumeUserToken(gomock.Any(), model.UserTokenPurposeVerifyEmail, tokenHash).Return(
+					nil,
+					repository.ErrCacheMiss,
+				)
+			},
+			wantErr: ErrInvalidUserToken,
+		},
+		{
+			name: "Fail: expired",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository) {
+				expired := stored
+				expired.ExpiresAt = time.Now().Add(-time.Second)
+				m1.EXPECT().ConsumeUserToken(gomock.Any(), model.UserTokenPurposeVerifyEmail, tokenHash).Return(&expired, nil)
+			},
+			wantErr: ErrInvalidUserToken,
+		},
+		{
+			name: "Fail: email changed after token was issued",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository) {
+				m1.EXPECT().ConsumeUserToken(gomock.Any(), model.UserTokenPurposeVerifyEmail, tokenHash).Return(&stored, nil)
+				changed := user
+				changed.Email = "new@gmail.com"
+				m.EXPECT().Get(gomock.Any(), userID).Return(&changed, nil)
+			},
+			wantErr: ErrInvalidUserToken,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			ur := mock.NewMockUserRepository(ctrl)
+			cr := mock.NewMockUserCacheRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ur, cr)
+			}
+
+			usecase := NewUserUseCase(ur, cr, mock.NewMockLoginAttemptCacheRepository(ctrl), mock.NewMockRecoveryCodeRepository(ctrl), mock.NewMockTwoFactorCacheRepository(ctrl), mailmock.NewMockMailer(ctrl), testMailConfig, testLoginThrottleConfig, testTwoFactorConfig)
+			err := usecase.VerifyEmail(context.Background(), token)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyEmail() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserUseCase_RequestPasswordReset(t *testing.T) {
+	userID := "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockUserRepository,
+			m1 *mock.MockUserCacheRepository,
+			m2 *mailmock.MockMailer,
+		)
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository, m2 *mailmock.MockMailer) {
+				m.EXPECT().List(
+					gomock.Any(),
+					[]repository.QueryCondition{{Field: "Email", Value: "test@gmail.com"}},
+				).Return([]model.User{{ID: uuid.MustParse(userID), Email: "test@gmail.com"}}, nil)
+				var tokenHash string
+				m1.EXPECT().SetUserToken(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, hash string, token model.UserToken) error {
+						tokenHash = hash
+						if token.Purpose != model.UserTokenPurposeResetPassword || token.UserID != userID ||
+							token.ExpiresAt.After(time.Now().Add(PasswordResetTokenTTL)) {
+							t.Errorf("unexpected user token: %+v", token)
+						}
+						return nil
+					},
+				)
+				// メールには保存したハッシュのトークンそのものが記載される
+				m2.EXPECT().Send(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, msg mail.Message) error {
+						_, token, _ := strings.Cut(msg.Body, "https://campfinder.example/reset-password?token=")
+						token, _, _ = strings.Cut(token, "\n")
+						if msg.To != "test@gmail.com" || auth.HashToken(token) != tokenHash {
+							t.Errorf("unexpected message: %+v", msg)
+						}
+						return nil
+					},
+				)
+			},
+		},
+		{
+			name: "success: unknown email sends nothing",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository, m2 *mailmock.MockMailer) {
+				m.EXPECT().List(
+					gomock.Any(),
+					[]repository.QueryCondition{{Field: "Email", Value: "test@gmail.com"}},
+				).Return([]model.User{}, nil)
+			},
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			ur := mock.NewMockUserRepository(ctrl)
+			cr := mock.NewMockUserCacheRepository(ctrl)
+			mm := mailmock.NewMockMailer(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ur, cr, mm)
+			}
+
+			usecase := NewUserUseCase(ur, cr, mock.NewMockLoginAttemptCacheRepository(ctrl), mock.NewMockRecoveryCodeRepository(ctrl), mock.NewMockTwoFactorCacheRepository(ctrl), mm, testMailConfig, testLoginThrottleConfig, testTwoFactorConfig)
+			err := usecase.RequestPasswordReset(context.Background(), "test@gmail.com")
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("RequestPasswordReset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserUseCase_ResetPassword(t *testing.T) {
+	token := "reset-token"
+	tokenHash := auth.HashToken(token)
+	userID := "f6db2530-cd9b-4ac1-8dc1-38c795e6eec2"
+	sessionID := "2b5a4b1e-3f6c-4a8e-9d6b-7e8f9a0b1c2d"
+	stored := model.UserToken{
+		UserID:    userID,
+		Email:     "test@gmail.com",
+		Purpose:   model.UserTokenPurposeResetPassword,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockUserRepository,
+			m1 *mock.MockUserCacheRepository,
+		)
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository) {
+				m1.EXPECT().ConsumeUserToken(gomock.Any(), model.UserTokenPurposeResetPassword, tokenHash).Return(&stored, nil)
+				m.EXPECT().Get(gomock.Any(), userID).Return(&model.User{
+					ID:       uuid.MustParse(userID),
+					Email:    "test@gmail.com",
+					Password: "old-hash",
+				}, nil)
+				m.EXPECT().Update(gomock.Any(), userID, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ string, user model.User) error {
+						if err := auth.CompareHashAndPassword(user.Password, "new-password"); err != nil || !user.EmailVerified {
+							t.Errorf("unexpected user: %+v", user)
+						}
+						return nil
+					},
+				)
+				// パスワードを再設定したらすべての端末をログアウトさせる
+				m1.EXPECT().ListSessions(gomock.Any(), userID).Return([]model.Session{{ID: sessionID, UserID: userID}}, nil)
+				m1.EXPECT().DeleteSession(gomock.Any(), userID, sessionID).Return(nil)
+				m1.EXPECT().DeleteRefreshTokenFamily(gomock.Any(), sessionID).Return(nil)
+			},
+		},
+		{
+			name: "Fail: used or unknown token",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository) {
+				m1.EXPECT().ConsumeUserToken(gomock.Any(), model.UserTokenPurposeResetPassword, tokenHash).Return(
+					nil,
+					repository.ErrCacheMiss,
+				)
+			},
+			wantErr: ErrInvalidUserToken,
+		},
+		{
+			name: "Fail: user deleted",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockUserCacheRepository) {
+				m1.EXPECT().ConsumeUserToken(gomock.Any(), model.UserTokenPurposeResetPassword, tokenHash).Return(&stored, nil)
+				m.EXPECT().Get(gomock.Any(), userID).Return(nil, repository.ErrNotFound)
+			},
+			wantErr: ErrInvalidUserToken,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			ur := mock.NewMockUserRepository(ctrl)
+			cr := mock.NewMockUserCacheRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ur, cr)
+			}
+
+			usecase := NewUserUseCase(ur, cr, mock.NewMockLoginAttemptCacheRepository(ctrl), mock.NewMockRecoveryCodeRepository(ctrl), mock.NewMockTwoFactorCacheRepository(ctrl), mailmock.NewMockMailer(ctrl), testMailConfig, testLoginThrottleConfig, testTwoFactorConfig)
+			err := usecase.ResetPassword(context.Background(), token, "new-password")
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ResetPassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
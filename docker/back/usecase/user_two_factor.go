@@ -0,0 +1,340 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/config"
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+	"github.com/tusmasoma/campfinder/docker/back/internal/auth"
+)
+
+const (
+	// TwoFactorChallengeTTL はパスワードを確認してから2段階目の認証までに許容する時間です。
+	TwoFactorChallengeTTL = 5 * time.Minute
+	// TwoFactorSetupTTL は2段階認証の設定を開始してから、認証アプリのコードで確認するまでに許容する時間です。
+	TwoFactorSetupTTL = 10 * time.Minute
+	// totpUsedTTL は使用済みの時間ステップを記録しておく時間で、前後のずれを含めてコードが有効な間は保持します。
+	totpUsedTTL = 3 * auth.TOTPPeriod
+)
+
+// TwoFactorSetup は認証アプリに登録する秘密鍵です。ProvisioningURIはQRコードにして読み込ませます。
+type TwoFactorSetup struct {
+	Secret          string
+	ProvisioningURI string
+}
+
+// startTwoFactorLogin はパスワードなどの1段階目の認証を終えたユーザのチャレンジを保存し、
+// チャレンジトークンを持つTwoFactorRequiredErrorを返します。トークンはこの時点では発行しません。
+func startTwoFactorLogin(
+	ctx context.Context,
+	tfr repository.TwoFactorCacheRepository,
+	user *model.User,
+	device Device,
+) error {
+	token, err := auth.GenerateUserToken()
+	if err != nil {
+		log.Printf("Failed to generate two-factor challenge: %v", err)
+		return err
+	}
+	if err = tfr.SetChallenge(ctx, auth.HashToken(token), model.TwoFactorChallenge{
+		UserID:     user.ID.String(),
+		Email:      user.Email,
+		DeviceName: device.Name,
+		IP:         device.IP,
+		UserAgent:  device.UserAgent,
+		ExpiresAt:  time.Now().Add(TwoFactorChallengeTTL),
+	}); err != nil {
+		log.Printf("Failed to set two-factor challenge in cache: %v", err)
+		return err
+	}
+	return &TwoFactorRequiredError{ChallengeToken: token, ExpiresIn: TwoFactorChallengeTTL}
+}
+
+// VerifyTwoFactorLogin はログインの2段階目で、ワンタイムパスワードまたはリカバリーコードを確認してからトークンを発行します。
+// コードの誤りはパスワードの誤りと同様にログインの失敗として数えます。
+func (uuc *userUseCase) VerifyTwoFactorLogin(
+	ctx context.Context,
+	challengeToken string,
+	code string,
+) (*TokenPair, error) {
+	challengeHash := auth.HashToken(challengeToken)
+	challenge, err := uuc.tfr.GetChallenge(ctx, challengeHash)
+	if errors.Is(err, repository.ErrCacheMiss) {
+		return nil, ErrInvalidTwoFactorChallenge
+	} else if err != nil {
+		log.Printf("Failed to get two-factor challenge: %v", err)
+		return nil, err
+	}
+	if !time.Now().Before(challenge.ExpiresAt) {
+		return nil, ErrInvalidTwoFactorChallenge
+	}
+
+	targets := uuc.loginAttemptTargets(challenge.Email, challenge.IP)
+	if err = uuc.checkLoginThrottle(ctx, targets); err != nil {
+		return nil, err
+	}
+
+	user, err := uuc.ur.Get(ctx, challenge.UserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvalidTwoFactorChallenge
+	} else if err != nil {
+		log.Printf("Failed to get user: %v", err)
+		return nil, err
+	}
+	if user.Suspended {
+		return nil, ErrAccountSuspended
+	}
+
+	if err = uuc.verifySecondFactor(ctx, user, code); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			uuc.recordLoginFailure(ctx, targets, challenge.IP)
+		}
+		return nil, err
+	}
+	// 同じチャレンジで同時にログインした場合も、セッションは一つしか作成しない
+	if _, err = uuc.tfr.ConsumeChallenge(ctx, challengeHash); errors.Is(err, repository.ErrCacheMiss) {
+		return nil, ErrInvalidTwoFactorChallenge
+	} else if err != nil {
+		log.Printf("Failed to consume two-factor challenge: %v", err)
+		return nil, err
+	}
+	uuc.resetLoginFailures(ctx, targets[0])
+
+	device := Device{Name: challenge.DeviceName, IP: challenge.IP, UserAgent: challenge.UserAgent}
+	return uuc.issueTokens(ctx, user.Email, newSession(user.ID.String(), device))
+}
+
+// SetupTwoFactor は新しい秘密鍵を発行します。EnableTwoFactorで認証アプリのコードを確認するまで2段階認証は有効になりません。
+func (uuc *userUseCase) SetupTwoFactor(ctx context.Context, userID string) (*TwoFactorSetup, error) {
+	user, err := uuc.ur.Get(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to get user: %v", err)
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		log.Printf("Failed to generate totp secret: %v", err)
+		return nil, err
+	}
+	if err = uuc.tfr.SetPendingSecret(ctx, userID, secret, TwoFactorSetupTTL); err != nil {
+		log.Printf("Failed to set pending totp secret in cache: %v", err)
+		return nil, err
+	}
+	return &TwoFactorSetup{
+		Secret:          secret,
+		ProvisioningURI: auth.TOTPProvisioningURI(uuc.tc.Issuer, user.Email, secret),
+	}, nil
+}
+
+// EnableTwoFactor は認証アプリのコードで秘密鍵の登録を確認して2段階認証を有効にし、リカバリーコードを返します。
+// リカバリーコードはハッシュのみを保存するため、平文を返すのはこの時だけです。
+func (uuc *userUseCase) EnableTwoFactor(ctx context.Context, userID string, code string) ([]string, error) {
+	user, err := uuc.ur.Get(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to get user: %v", err)
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	secret, err := uuc.tfr.GetPendingSecret(ctx, userID)
+	if errors.Is(err, repository.ErrCacheMiss) {
+		return nil, ErrTwoFactorSetupNotStarted
+	} else if err != nil {
+		log.Printf("Failed to get pending totp secret: %v", err)
+		return nil, err
+	}
+	if err = uuc.verifyThrottled(ctx, user, func() error {
+		return uuc.verifyTOTP(ctx, userID, secret, code)
+	}); err != nil {
+		return nil, err
+	}
+
+	// 有効にする前にリカバリーコードを保存し、リカバリーコードを持たずに2段階認証が有効になることを防ぐ
+	codes, err := uuc.replaceRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	user.TOTPSecret = secret
+	user.TOTPEnabled = true
+	if err = uuc.ur.Update(ctx, userID, *user); err != nil {
+		log.Printf("Failed to update user: %v", err)
+		return nil, err
+	}
+	if err = uuc.tfr.DeletePendingSecret(ctx, userID); err != nil {
+		log.Printf("Failed to delete pending totp secret of %v: %v", userID, err)
+	}
+	return codes, nil
+}
+
+// DisableTwoFactor はパスワードと2段階目の認証を確認してから2段階認証を無効にします。
+// 外部ログインのみでパスワードを持たないユーザは、2段階目の認証のみを確認します。
+func (uuc *userUseCase) DisableTwoFactor(ctx context.Context, userID string, password string, code string) error {
+	user, err := uuc.ur.Get(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to get user: %v", err)
+		return err
+	}
+	if !user.TOTPEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+	if uuc.twoFactorMandatory(user) {
+		return ErrTwoFactorMandatory
+	}
+	if err = uuc.verifyThrottled(ctx, user, func() error {
+		if user.Password != "" {
+			if err := auth.CompareHashAndPassword(user.Password, password); err != nil {
+				return ErrIncorrectPassword
+			}
+		}
+		return uuc.verifySecondFactor(ctx, user, code)
+	}); err != nil {
+		return err
+	}
+
+	user.TOTPSecret = ""
+	user.TOTPEnabled = false
+	if err = uuc.ur.Update(ctx, userID, *user); err != nil {
+		log.Printf("Failed to update user: %v", err)
+		return err
+	}
+	if err = uuc.rcr.DeleteByUserID(ctx, userID); err != nil {
+		log.Printf("Failed to delete recovery codes of %v: %v", userID, err)
+		return err
+	}
+	return nil
+}
+
+// RegenerateRecoveryCodes は以前のリカバリーコードを無効にし、新しいリカバリーコードを返します。
+func (uuc *userUseCase) RegenerateRecoveryCodes(ctx context.Context, userID string, code string) ([]string, error) {
+	user, err := uuc.ur.Get(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to get user: %v", err)
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	if err = uuc.verifyThrottled(ctx, user, func() error {
+		return uuc.verifySecondFactor(ctx, user, code)
+	}); err != nil {
+		return nil, err
+	}
+	return uuc.replaceRecoveryCodes(ctx, user.ID)
+}
+
+// verifyThrottled は2段階認証の設定の変更でverifyによる確認を行います。アクセストークンを盗まれてもコードを総当たりできないよう、
+// 誤りはログインの失敗としてユーザごとに数え、ロック中はLoginThrottledErrorを返します。
+func (uuc *userUseCase) verifyThrottled(ctx context.Context, user *model.User, verify func() error) error {
+	targets := uuc.loginAttemptTargets(user.Email, "")
+	if err := uuc.checkLoginThrottle(ctx, targets); err != nil {
+		return err
+	}
+	if err := verify(); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) || errors.Is(err, ErrIncorrectPassword) {
+			uuc.recordLoginFailure(ctx, targets, "")
+		}
+		return err
+	}
+	uuc.resetLoginFailures(ctx, targets[0])
+	return nil
+}
+
+// twoFactorMandatory は設定により2段階認証が必須のユーザかどうかを返します。
+func (uuc *userUseCase) twoFactorMandatory(user *model.User) bool {
+	return uuc.tc.RequireForAdmins && user.HasRole(model.RoleAdmin)
+}
+
+// actsAsAdmin はuserが管理者として他のユーザのコンテンツを操作できるかを返します。
+// RequireRoleと同じく、2段階認証が必須の管理者は2段階認証を設定するまで管理者として扱いません。
+func actsAsAdmin(tc *config.TwoFactorConfig, user model.User) bool {
+	if !user.HasRole(model.RoleAdmin) {
+		return false
+	}
+	return !tc.RequireForAdmins || user.TOTPEnabled
+}
+
+// verifySecondFactor は6桁の数字はワンタイムパスワードとして、それ以外はリカバリーコードとして確認します。
+func (uuc *userUseCase) verifySecondFactor(ctx context.Context, user *model.User, code string) error {
+	if auth.IsTOTPCodeFormat(code) {
+		return uuc.verifyTOTP(ctx, user.ID.String(), user.TOTPSecret, code)
+	}
+	return uuc.consumeRecoveryCode(ctx, user.ID.String(), code)
+}
+
+// verifyTOTP はワンタイムパスワードを確認し、盗み見られたコードを再利用されないよう一致した時間ステップを使用済みにします。
+func (uuc *userUseCase) verifyTOTP(ctx context.Context, userID string, secret string, code string) error {
+	counter, ok := auth.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+	fresh, err := uuc.tfr.MarkTOTPUsed(ctx, userID, counter, totpUsedTTL)
+	if err != nil {
+		log.Printf("Failed to mark totp code as used: %v", err)
+		return err
+	}
+	if !fresh {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+func (uuc *userUseCase) consumeRecoveryCode(ctx context.Context, userID string, code string) error {
+	codes, err := uuc.rcr.List(ctx, []repository.QueryCondition{
+		{Field: "user_id", Value: userID},
+		{Field: "code_hash", Value: auth.HashRecoveryCode(code)},
+	})
+	if err != nil {
+		log.Printf("Failed to list recovery codes: %v", err)
+		return err
+	}
+	if len(codes) == 0 {
+		return ErrInvalidTwoFactorCode
+	}
+	consumed, err := uuc.rcr.Consume(ctx, codes[0].ID.String())
+	if err != nil {
+		log.Printf("Failed to consume recovery code: %v", err)
+		return err
+	}
+	if !consumed {
+		return ErrInvalidTwoFactorCode
+	}
+	log.Printf("Recovery code was used by %v", userID)
+	return nil
+}
+
+// replaceRecoveryCodes は以前のリカバリーコードを削除し、新しいリカバリーコードのハッシュを保存して平文を返します。
+func (uuc *userUseCase) replaceRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	codes, err := auth.GenerateRecoveryCodes()
+	if err != nil {
+		log.Printf("Failed to generate recovery codes: %v", err)
+		return nil, err
+	}
+	if err = uuc.rcr.DeleteByUserID(ctx, userID.String()); err != nil {
+		log.Printf("Failed to delete recovery codes of %v: %v", userID, err)
+		return nil, err
+	}
+	records := make([]model.RecoveryCode, 0, len(codes))
+	for _, code := range codes {
+		records = append(records, model.RecoveryCode{
+			ID:       uuid.New(),
+			UserID:   userID,
+			CodeHash: auth.HashRecoveryCode(code),
+		})
+	}
+	if err = uuc.rcr.BatchCreate(ctx, records); err != nil {
+		log.Printf("Failed to create recovery codes: %v", err)
+		return nil, err
+	}
+	return codes, nil
+}
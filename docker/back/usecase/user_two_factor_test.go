@@ -0,0 +1,647 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/campfinder/docker/back/domain/model"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository"
+	"github.com/tusmasoma/campfinder/docker/back/domain/repository/mock"
+	"github.com/tusmasoma/campfinder/docker/back/internal/auth"
+	mailmock "github.com/tusmasoma/campfinder/docker/back/internal/mail/mock"
+)
+
+const testTOTPSecret = "JBSWY3DPEHPK3PXP"
+
+func TestUserUseCase_LoginAndGenerateToken_TwoFactor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ur := mock.NewMockUserRepository(ctrl)
+	lr := mock.NewMockLoginAttemptCacheRepository(ctrl)
+	tfr := mock.NewMockTwoFactorCacheRepository(ctrl)
+
+	userID := uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")
+	passward, _ := auth.PasswordEncrypt("password123")
+	lr.EXPECT().LockRemaining(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(time.Duration(0), nil)
+	lr.EXPECT().Reset(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(nil)
+	ur.EXPECT().List(
+		gomock.Any(),
+		[]repository.QueryCondition{{Field: "Email", Value: "test@gmail.com"}},
+	).Return([]model.User{{
+		ID:          userID,
+		Email:       "test@gmail.com",
+		Password:    passward,
+		TOTPSecret:  testTOTPSecret,
+		TOTPEnabled: true,
+	}}, nil)
+	// トークンは発行せず、端末の情報を持つチャレンジを保存する
+	var challengeHash string
+	tfr.EXPECT().SetChallenge(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, hash string, challenge model.TwoFactorChallenge) error {
+			challengeHash = hash
+			if challenge.UserID != userID.String() || challenge.Email != "test@gmail.com" ||
+				challenge.DeviceName != "laptop" || !challenge.ExpiresAt.After(time.Now()) {
+				t.Errorf("unexpected challenge: %+v", challenge)
+			}
+			return nil
+		},
+	)
+
+	usecase := NewUserUseCase(
+		ur,
+		mock.NewMockUserCacheRepository(ctrl),
+		lr,
+		mock.NewMockRecoveryCodeRepository(ctrl),
+		tfr,
+		mailmock.NewMockMailer(ctrl),
+		testMailConfig,
+		testLoginThrottleConfig,
+		testTwoFactorConfig,
+	)
+	tokens, err := usecase.LoginAndGenerateToken(
+		context.Background(), "test@gmail.com", "password123", Device{Name: "laptop"},
+	)
+
+	var challenge *TwoFactorRequiredError
+	if !errors.As(err, &challenge) {
+		t.Fatalf("LoginAndGenerateToken() error = %v, want TwoFactorRequiredError", err)
+	}
+	if tokens != nil {
+		t.Errorf("LoginAndGenerateToken() issued tokens before the second step")
+	}
+	if challenge.ChallengeToken == "" || auth.HashToken(challenge.ChallengeToken) != challengeHash {
+		t.Errorf("challenge token does not match the saved hash")
+	}
+	if challenge.ExpiresIn != TwoFactorChallengeTTL {
+		t.Errorf("ExpiresIn = %v, want %v", challenge.ExpiresIn, TwoFactorChallengeTTL)
+	}
+}
+
+func TestUserUseCase_VerifyTwoFactorLogin(t *testing.T) {
+	challengeToken := "challenge-token"
+	challengeHash := auth.HashToken(challengeToken)
+	userID := uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")
+	user := model.User{ID: userID, Email: "test@gmail.com", TOTPSecret: testTOTPSecret, TOTPEnabled: true}
+	challenge := model.TwoFactorChallenge{
+		UserID:     userID.String(),
+		Email:      "test@gmail.com",
+		DeviceName: "laptop",
+		ExpiresAt:  time.Now().Add(time.Minute),
+	}
+	code, _ := auth.TOTPCode(testTOTPSecret, time.Now())
+	recoveryCodeID := uuid.MustParse("8a2d2a7e-5b1e-4f7a-9f0c-3c6f1e2d4b5a")
+
+	expectIssueTokens := func(cr *mock.MockUserCacheRepository) {
+		cr.EXPECT().SetRefreshToken(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		cr.EXPECT().SetRefreshTokenFamily(gomock.Any(), gomock.Any(), gomock.Any(), auth.RefreshTokenTTL).Return(nil)
+		cr.EXPECT().SetSession(gomock.Any(), gomock.Any(), auth.RefreshTokenTTL).DoAndReturn(
+			func(_ context.Context, session model.Session, _ time.Duration) error {
+				// ログイン時の端末の情報でセッションを作成する
+				if session.UserID != userID.String() || session.DeviceName != "laptop" {
+					t.Errorf("unexpected session: %+v", session)
+				}
+				return nil
+			},
+		)
+	}
+
+	patterns := []struct {
+		name  string
+		code  string
+		setup func(
+			ur *mock.MockUserRepository,
+			cr *mock.MockUserCacheRepository,
+			lr *mock.MockLoginAttemptCacheRepository,
+			rcr *mock.MockRecoveryCodeRepository,
+			tfr *mock.MockTwoFactorCacheRepository,
+		)
+		wantErr error
+	}{
+		{
+			name: "success: totp code",
+			code: code,
+			setup: func(
+				ur *mock.MockUserRepository,
+				cr *mock.MockUserCacheRepository,
+				lr *mock.MockLoginAttemptCacheRepository,
+				_ *mock.MockRecoveryCodeRepository,
+				tfr *mock.MockTwoFactorCacheRepository,
+			) {
+				tfr.EXPECT().GetChallenge(gomock.Any(), challengeHash).Return(&challenge, nil)
+				lr.EXPECT().LockRemaining(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(time.Duration(0), nil)
+				ur.EXPECT().Get(gomock.Any(), userID.String()).Return(&user, nil)
+				tfr.EXPECT().MarkTOTPUsed(gomock.Any(), userID.String(), gomock.Any(), totpUsedTTL).Return(true, nil)
+				tfr.EXPECT().ConsumeChallenge(gomock.Any(), challengeHash).Return(&challenge, nil)
+				lr.EXPECT().Reset(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(nil)
+				expectIssueTokens(cr)
+			},
+		},
+		{
+			name: "success: recovery code",
+			code: "ABCDE-fghij",
+			setup: func(
+				ur *mock.MockUserRepository,
+				cr *mock.MockUserCacheRepository,
+				lr *mock.MockLoginAttemptCacheRepository,
+				rcr *mock.MockRecoveryCodeRepository,
+				tfr *mock.MockTwoFactorCacheRepository,
+			) {
+				tfr.EXPECT().GetChallenge(gomock.Any(), challengeHash).Return(&challenge, nil)
+				lr.EXPECT().LockRemaining(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(time.Duration(0), nil)
+				ur.EXPECT().Get(gomock.Any(), userID.String()).Return(&user, nil)
+				rcr.EXPECT().List(gomock.Any(), []repository.QueryCondition{
+					{Field: "user_id", Value: userID.String()},
+					{Field: "code_hash", Value: auth.HashRecoveryCode("abcdefghij")},
+				}).Return([]model.RecoveryCode{{ID: recoveryCodeID, UserID: userID}}, nil)
+				rcr.EXPECT().Consume(gomock.Any(), recoveryCodeID.String()).Return(true, nil)
+				tfr.EXPECT().ConsumeChallenge(gomock.Any(), challengeHash).Return(&challenge, nil)
+				lr.EXPECT().Reset(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(nil)
+				expectIssueTokens(cr)
+			},
+		},
+		{
+			name: "Fail: reused totp code",
+			code: code,
+			setup: func(
+				ur *mock.MockUserRepository,
+				_ *mock.MockUserCacheRepository,
+				lr *mock.MockLoginAttemptCacheRepository,
+				_ *mock.MockRecoveryCodeRepository,
+				tfr *mock.MockTwoFactorCacheRepository,
+			) {
+				tfr.EXPECT().GetChallenge(gomock.Any(), challengeHash).Return(&challenge, nil)
+				lr.EXPECT().LockRemaining(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(time.Duration(0), nil)
+				ur.EXPECT().Get(gomock.Any(), userID.String()).Return(&user, nil)
+				tfr.EXPECT().MarkTOTPUsed(gomock.Any(), userID.String(), gomock.Any(), totpUsedTTL).Return(false, nil)
+				// 誤ったコードはログインの失敗として数える
+				lr.EXPECT().AddFailure(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com", 15*time.Minute).Return(int64(1), nil)
+				lr.EXPECT().Lock(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com", time.Second).Return(nil)
+			},
+			wantErr: ErrInvalidTwoFactorCode,
+		},
+		{
+			name: "Fail: wrong totp code",
+			code: "000000",
+			setup: func(
+				ur *mock.MockUserRepository,
+				_ *mock.MockUserCacheRepository,
+				lr *mock.MockLoginAttemptCacheRepository,
+				_ *mock.MockRecoveryCodeRepository,
+				tfr *mock.MockTwoFactorCacheRepository,
+			) {
+				tfr.EXPECT().GetChallenge(gomock.Any(), challengeHash).Return(&challenge, nil)
+				lr.EXPECT().LockRemaining(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(time.Duration(0), nil)
+				ur.EXPECT().Get(gomock.Any(), userID.String()).Return(&user, nil)
+				lr.EXPECT().AddFailure(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com", 15*time.Minute).Return(int64(2), nil)
+				lr.EXPECT().Lock(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com", 2*time.Second).Return(nil)
+			},
+			wantErr: ErrInvalidTwoFactorCode,
+		},
+		{
+			name: "Fail: used recovery code",
+			code: "abcde-fghij",
+			setup: func(
+				ur *mock.MockUserRepository,
+				_ *mock.MockUserCacheRepository,
+				lr *mock.MockLoginAttemptCacheRepository,
+				rcr *mock.MockRecoveryCodeRepository,
+				tfr *mock.MockTwoFactorCacheRepository,
+			) {
+				tfr.EXPECT().GetChallenge(gomock.Any(), challengeHash).Return(&challenge, nil)
+				lr.EXPECT().LockRemaining(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(time.Duration(0), nil)
+				ur.EXPECT().Get(gomock.Any(), userID.String()).Return(&user, nil)
+				rcr.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.RecoveryCode{}, nil)
+				lr.EXPECT().AddFailure(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com", 15*time.Minute).Return(int64(1), nil)
+				lr.EXPECT().Lock(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com", time.Second).Return(nil)
+			},
+			wantErr: ErrInvalidTwoFactorCode,
+		},
+		{
+			name: "Fail: unknown challenge",
+			code: code,
+			setup: func(
+				_ *mock.MockUserRepository,
+				_ *mock.MockUserCacheRepository,
+				_ *mock.MockLoginAttemptCacheRepository,
+				_ *mock.MockRecoveryCodeRepository,
+				tfr *mock.MockTwoFactorCacheRepository,
+			) {
+				tfr.EXPECT().GetChallenge(gomock.Any(), challengeHash).Return(nil, repository.ErrCacheMiss)
+			},
+			wantErr: ErrInvalidTwoFactorChallenge,
+		},
+		{
+			name: "Fail: locked out",
+			code: code,
+			setup: func(
+				_ *mock.MockUserRepository,
+				_ *mock.MockUserCacheRepository,
+				lr *mock.MockLoginAttemptCacheRepository,
+				_ *mock.MockRecoveryCodeRepository,
+				tfr *mock.MockTwoFactorCacheRepository,
+			) {
+				tfr.EXPECT().GetChallenge(gomock.Any(), challengeHash).Return(&challenge, nil)
+				lr.EXPECT().LockRemaining(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(time.Minute, nil)
+			},
+			wantErr: ErrTooManyLoginAttempts,
+		},
+		{
+			name: "Fail: challenge already consumed",
+			code: code,
+			setup: func(
+				ur *mock.MockUserRepository,
+				_ *mock.MockUserCacheRepository,
+				lr *mock.MockLoginAttemptCacheRepository,
+				_ *mock.MockRecoveryCodeRepository,
+				tfr *mock.MockTwoFactorCacheRepository,
+			) {
+				tfr.EXPECT().GetChallenge(gomock.Any(), challengeHash).Return(&challenge, nil)
+				lr.EXPECT().LockRemaining(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(time.Duration(0), nil)
+				ur.EXPECT().Get(gomock.Any(), userID.String()).Return(&user, nil)
+				tfr.EXPECT().MarkTOTPUsed(gomock.Any(), userID.String(), gomock.Any(), totpUsedTTL).Return(true, nil)
+				tfr.EXPECT().ConsumeChallenge(gomock.Any(), challengeHash).Return(nil, repository.ErrCacheMiss)
+			},
+			wantErr: ErrInvalidTwoFactorChallenge,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Setenv("PRIVATE_KEY_PATH", "../../../.certificate/private_key.pem")
+			t.Setenv("PUBLIC_KEY_PATH", "../../../.certificate/public_key.pem")
+			ctrl := gomock.NewController(t)
+			ur := mock.NewMockUserRepository(ctrl)
+			cr := mock.NewMockUserCacheRepository(ctrl)
+			lr := mock.NewMockLoginAttemptCacheRepository(ctrl)
+			rcr := mock.NewMockRecoveryCodeRepository(ctrl)
+			tfr := mock.NewMockTwoFactorCacheRepository(ctrl)
+
+			tt.setup(ur, cr, lr, rcr, tfr)
+
+			usecase := NewUserUseCase(
+				ur, cr, lr, rcr, tfr, mailmock.NewMockMailer(ctrl), testMailConfig, testLoginThrottleConfig, testTwoFactorConfig,
+			)
+			tokens, err := usecase.VerifyTwoFactorLogin(context.Background(), challengeToken, tt.code)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyTwoFactorLogin() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (tokens.AccessToken == "" || tokens.RefreshToken == "") {
+				t.Error("Failed to generate token")
+			}
+		})
+	}
+}
+
+func TestUserUseCase_EnableTwoFactor(t *testing.T) {
+	userID := uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")
+	code, _ := auth.TOTPCode(testTOTPSecret, time.Now())
+
+	patterns := []struct {
+		name  string
+		code  string
+		setup func(
+			ur *mock.MockUserRepository,
+			lr *mock.MockLoginAttemptCacheRepository,
+			rcr *mock.MockRecoveryCodeRepository,
+			tfr *mock.MockTwoFactorCacheRepository,
+		)
+		wantErr error
+	}{
+		{
+			name: "success",
+			code: code,
+			setup: func(
+				ur *mock.MockUserRepository,
+				lr *mock.MockLoginAttemptCacheRepository,
+				rcr *mock.MockRecoveryCodeRepository,
+				tfr *mock.MockTwoFactorCacheRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), userID.String()).Return(&model.User{ID: userID, Email: "test@gmail.com"}, nil)
+				tfr.EXPECT().GetPendingSecret(gomock.Any(), userID.String()).Return(testTOTPSecret, nil)
+				lr.EXPECT().LockRemaining(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(time.Duration(0), nil)
+				tfr.EXPECT().MarkTOTPUsed(gomock.Any(), userID.String(), gomock.Any(), totpUsedTTL).Return(true, nil)
+				lr.EXPECT().Reset(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(nil)
+				rcr.EXPECT().DeleteByUserID(gomock.Any(), userID.String()).Return(nil)
+				rcr.EXPECT().BatchCreate(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, codes []model.RecoveryCode) error {
+						if len(codes) != auth.RecoveryCodeCount || codes[0].UserID != userID || codes[0].CodeHash == "" {
+							t.Errorf("unexpected recovery codes: %+v", codes)
+						}
+						return nil
+					},
+				)
+				ur.EXPECT().Update(gomock.Any(), userID.String(), model.User{
+					ID:          userID,
+					Email:       "test@gmail.com",
+					TOTPSecret:  testTOTPSecret,
+					TOTPEnabled: true,
+				}).Return(nil)
+				tfr.EXPECT().DeletePendingSecret(gomock.Any(), userID.String()).Return(nil)
+			},
+		},
+		{
+			name: "Fail: wrong code",
+			code: "000000",
+			setup: func(
+				ur *mock.MockUserRepository,
+				lr *mock.MockLoginAttemptCacheRepository,
+				_ *mock.MockRecoveryCodeRepository,
+				tfr *mock.MockTwoFactorCacheRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), userID.String()).Return(&model.User{ID: userID, Email: "test@gmail.com"}, nil)
+				tfr.EXPECT().GetPendingSecret(gomock.Any(), userID.String()).Return(testTOTPSecret, nil)
+				// コードの誤りはログインの失敗として数える
+				lr.EXPECT().LockRemaining(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(time.Duration(0), nil)
+				lr.EXPECT().AddFailure(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com", 15*time.Minute).Return(int64(1), nil)
+				lr.EXPECT().Lock(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com", time.Second).Return(nil)
+			},
+			wantErr: ErrInvalidTwoFactorCode,
+		},
+		{
+			// ロック中はコードを確認しない
+			name: "Fail: locked",
+			code: code,
+			setup: func(
+				ur *mock.MockUserRepository,
+				lr *mock.MockLoginAttemptCacheRepository,
+				_ *mock.MockRecoveryCodeRepository,
+				tfr *mock.MockTwoFactorCacheRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), userID.String()).Return(&model.User{ID: userID, Email: "test@gmail.com"}, nil)
+				tfr.EXPECT().GetPendingSecret(gomock.Any(), userID.String()).Return(testTOTPSecret, nil)
+				lr.EXPECT().LockRemaining(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(time.Minute, nil)
+			},
+			wantErr: ErrTooManyLoginAttempts,
+		},
+		{
+			name: "Fail: setup not started",
+			code: code,
+			setup: func(
+				ur *mock.MockUserRepository,
+				lr *mock.MockLoginAttemptCacheRepository,
+				_ *mock.MockRecoveryCodeRepository,
+				tfr *mock.MockTwoFactorCacheRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), userID.String()).Return(&model.User{ID: userID, Email: "test@gmail.com"}, nil)
+				tfr.EXPECT().GetPendingSecret(gomock.Any(), userID.String()).Return("", repository.ErrCacheMiss)
+			},
+			wantErr: ErrTwoFactorSetupNotStarted,
+		},
+		{
+			name: "Fail: already enabled",
+			code: code,
+			setup: func(
+				ur *mock.MockUserRepository,
+				lr *mock.MockLoginAttemptCacheRepository,
+				_ *mock.MockRecoveryCodeRepository,
+				_ *mock.MockTwoFactorCacheRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), userID.String()).Return(
+					&model.User{ID: userID, TOTPSecret: testTOTPSecret, TOTPEnabled: true}, nil,
+				)
+			},
+			wantErr: ErrTwoFactorAlreadyEnabled,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			ur := mock.NewMockUserRepository(ctrl)
+			rcr := mock.NewMockRecoveryCodeRepository(ctrl)
+			tfr := mock.NewMockTwoFactorCacheRepository(ctrl)
+			lr := mock.NewMockLoginAttemptCacheRepository(ctrl)
+
+			tt.setup(ur, lr, rcr, tfr)
+
+			usecase := NewUserUseCase(
+				ur,
+				mock.NewMockUserCacheRepository(ctrl),
+				lr,
+				rcr,
+				tfr,
+				mailmock.NewMockMailer(ctrl),
+				testMailConfig,
+				testLoginThrottleConfig,
+				testTwoFactorConfig,
+			)
+			codes, err := usecase.EnableTwoFactor(context.Background(), userID.String(), tt.code)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("EnableTwoFactor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && len(codes) != auth.RecoveryCodeCount {
+				t.Errorf("EnableTwoFactor() returned %d recovery codes, want %d", len(codes), auth.RecoveryCodeCount)
+			}
+		})
+	}
+}
+
+func TestUserUseCase_DisableTwoFactor(t *testing.T) {
+	userID := uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")
+	code, _ := auth.TOTPCode(testTOTPSecret, time.Now())
+	passward, _ := auth.PasswordEncrypt("password123")
+	user := model.User{
+		ID: userID, Email: "test@gmail.com", Password: passward, TOTPSecret: testTOTPSecret, TOTPEnabled: true,
+	}
+
+	patterns := []struct {
+		name     string
+		password string
+		setup    func(
+			ur *mock.MockUserRepository,
+			lr *mock.MockLoginAttemptCacheRepository,
+			rcr *mock.MockRecoveryCodeRepository,
+			tfr *mock.MockTwoFactorCacheRepository,
+		)
+		wantErr error
+	}{
+		{
+			name:     "success",
+			password: "password123",
+			setup: func(
+				ur *mock.MockUserRepository,
+				lr *mock.MockLoginAttemptCacheRepository,
+				rcr *mock.MockRecoveryCodeRepository,
+				tfr *mock.MockTwoFactorCacheRepository,
+			) {
+				u := user
+				ur.EXPECT().Get(gomock.Any(), userID.String()).Return(&u, nil)
+				lr.EXPECT().LockRemaining(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(time.Duration(0), nil)
+				tfr.EXPECT().MarkTOTPUsed(gomock.Any(), userID.String(), gomock.Any(), totpUsedTTL).Return(true, nil)
+				lr.EXPECT().Reset(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(nil)
+				ur.EXPECT().Update(
+					gomock.Any(), userID.String(), model.User{ID: userID, Email: "test@gmail.com", Password: passward},
+				).Return(nil)
+				rcr.EXPECT().DeleteByUserID(gomock.Any(), userID.String()).Return(nil)
+			},
+		},
+		{
+			name:     "Fail: incorrect password",
+			password: "password456",
+			setup: func(
+				ur *mock.MockUserRepository,
+				lr *mock.MockLoginAttemptCacheRepository,
+				_ *mock.MockRecoveryCodeRepository,
+				_ *mock.MockTwoFactorCacheRepository,
+			) {
+				u := user
+				ur.EXPECT().Get(gomock.Any(), userID.String()).Return(&u, nil)
+				// パスワードの誤りもログインの失敗として数える
+				lr.EXPECT().LockRemaining(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(time.Duration(0), nil)
+				lr.EXPECT().AddFailure(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com", 15*time.Minute).Return(int64(1), nil)
+				lr.EXPECT().Lock(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com", time.Second).Return(nil)
+			},
+			wantErr: ErrIncorrectPassword,
+		},
+		{
+			// パスワードを持たない外部ログインのユーザも、ロック中は2段階認証を無効にできない
+			name: "Fail: locked user without password",
+			setup: func(
+				ur *mock.MockUserRepository,
+				lr *mock.MockLoginAttemptCacheRepository,
+				_ *mock.MockRecoveryCodeRepository,
+				_ *mock.MockTwoFactorCacheRepository,
+			) {
+				u := user
+				u.Password = ""
+				ur.EXPECT().Get(gomock.Any(), userID.String()).Return(&u, nil)
+				lr.EXPECT().LockRemaining(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(time.Minute, nil)
+			},
+			wantErr: ErrTooManyLoginAttempts,
+		},
+		{
+			name:     "Fail: mandatory for admin",
+			password: "password123",
+			setup: func(
+				ur *mock.MockUserRepository,
+				lr *mock.MockLoginAttemptCacheRepository,
+				_ *mock.MockRecoveryCodeRepository,
+				_ *mock.MockTwoFactorCacheRepository,
+			) {
+				u := user
+				u.Role = model.RoleAdmin
+				ur.EXPECT().Get(gomock.Any(), userID.String()).Return(&u, nil)
+			},
+			wantErr: ErrTwoFactorMandatory,
+		},
+		{
+			name:     "Fail: not enabled",
+			password: "password123",
+			setup: func(
+				ur *mock.MockUserRepository,
+				lr *mock.MockLoginAttemptCacheRepository,
+				_ *mock.MockRecoveryCodeRepository,
+				_ *mock.MockTwoFactorCacheRepository,
+			) {
+				ur.EXPECT().Get(gomock.Any(), userID.String()).Return(&model.User{ID: userID, Password: passward}, nil)
+			},
+			wantErr: ErrTwoFactorNotEnabled,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			ur := mock.NewMockUserRepository(ctrl)
+			rcr := mock.NewMockRecoveryCodeRepository(ctrl)
+			tfr := mock.NewMockTwoFactorCacheRepository(ctrl)
+			lr := mock.NewMockLoginAttemptCacheRepository(ctrl)
+
+			tt.setup(ur, lr, rcr, tfr)
+
+			usecase := NewUserUseCase(
+				ur,
+				mock.NewMockUserCacheRepository(ctrl),
+				lr,
+				rcr,
+				tfr,
+				mailmock.NewMockMailer(ctrl),
+				testMailConfig,
+				testLoginThrottleConfig,
+				testTwoFactorConfig,
+			)
+			err := usecase.DisableTwoFactor(context.Background(), userID.String(), tt.password, code)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("DisableTwoFactor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserUseCase_RegenerateRecoveryCodes(t *testing.T) {
+	userID := uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")
+	user := model.User{ID: userID, Email: "test@gmail.com", TOTPSecret: testTOTPSecret, TOTPEnabled: true}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			lr *mock.MockLoginAttemptCacheRepository,
+			rcr *mock.MockRecoveryCodeRepository,
+		)
+		wantErr error
+	}{
+		{
+			// 誤ったコードはログインの失敗として数え、リカバリーコードは作り直さない
+			name: "Fail: wrong code",
+			setup: func(lr *mock.MockLoginAttemptCacheRepository, rcr *mock.MockRecoveryCodeRepository) {
+				lr.EXPECT().LockRemaining(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(time.Duration(0), nil)
+				rcr.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, nil)
+				lr.EXPECT().AddFailure(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com", 15*time.Minute).Return(int64(5), nil)
+				lr.EXPECT().Lock(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com", 15*time.Minute).Return(nil)
+				lr.EXPECT().AddLockout(gomock.Any(), gomock.Any(), int64(LoginLockoutsMaxLen)).Return(nil)
+			},
+			wantErr: ErrInvalidTwoFactorCode,
+		},
+		{
+			name: "Fail: locked",
+			setup: func(lr *mock.MockLoginAttemptCacheRepository, _ *mock.MockRecoveryCodeRepository) {
+				lr.EXPECT().LockRemaining(gomock.Any(), model.LoginAttemptKindEmail, "test@gmail.com").Return(time.Minute, nil)
+			},
+			wantErr: ErrTooManyLoginAttempts,
+		},
+	}
+
+	for _, tt := range patterns {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			ctrl := gomock.NewController(t)
+			ur := mock.NewMockUserRepository(ctrl)
+			lr := mock.NewMockLoginAttemptCacheRepository(ctrl)
+			rcr := mock.NewMockRecoveryCodeRepository(ctrl)
+
+			u := user
+			ur.EXPECT().Get(gomock.Any(), userID.String()).Return(&u, nil)
+			tt.setup(lr, rcr)
+
+			usecase := NewUserUseCase(
+				ur,
+				mock.NewMockUserCacheRepository(ctrl),
+				lr,
+				rcr,
+				mock.NewMockTwoFactorCacheRepository(ctrl),
+				mailmock.NewMockMailer(ctrl),
+				testMailConfig,
+				testLoginThrottleConfig,
+				testTwoFactorConfig,
+			)
+			codes, err := usecase.RegenerateRecoveryCodes(context.Background(), userID.String(), "ABCD-EFGH")
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("RegenerateRecoveryCodes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if codes != nil {
+				t.Errorf("RegenerateRecoveryCodes() = %v, want nil", codes)
+			}
+		})
+	}
+}
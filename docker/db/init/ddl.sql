@@ -5,13 +5,23 @@ DROP TABLE IF EXISTS User CASCADE;
 DROP TABLE IF EXISTS Spot CASCADE;
 DROP TABLE IF EXISTS Comment CASCADE;
 DROP TABLE IF EXISTS Image CASCADE;
+DROP TABLE IF EXISTS ImageReport CASCADE;
+DROP TABLE IF EXISTS Identity CASCADE;
+DROP TABLE IF EXISTS RecoveryCode CASCADE;
+DROP TABLE IF EXISTS SpotOwner CASCADE;
 
 CREATE TABLE User (
     id CHAR(36) PRIMARY KEY, -- UUIDは36文字の文字列として格納されます
     name VARCHAR(50) NOT NULL,
     email VARCHAR(150) UNIQUE NOT NULL,
     password VARCHAR(255) NOT NULL,  -- 暗号化されたパスワードを格納
-    is_admin BOOLEAN DEFAULT FALSE
+    is_admin BOOLEAN DEFAULT FALSE,
+    role VARCHAR(20) NOT NULL DEFAULT 'user', -- user, contributor, moderator, admin
+    email_verified BOOLEAN NOT NULL DEFAULT FALSE,
+    avatar_url VARCHAR(255) NOT NULL DEFAULT '',
+    suspended BOOLEAN NOT NULL DEFAULT FALSE,
+    totp_secret VARCHAR(64) NOT NULL DEFAULT '', -- 2段階認証のTOTPの秘密鍵(Base32)
+    totp_enabled BOOLEAN NOT NULL DEFAULT FALSE
 );
 
 CREATE TABLE Spot (
@@ -25,7 +35,16 @@ CREATE TABLE Spot (
     phone VARCHAR(100) DEFAULT '-',
     price VARCHAR(400) DEFAULT '-',
     description TEXT ,
-    iconpath VARCHAR(30) DEFAULT 'iconpath'
+    iconpath VARCHAR(30) DEFAULT 'iconpath',
+    geohash VARCHAR(12) NOT NULL DEFAULT '', -- 近傍検索用。緯度経度から算出したgeohash。空の行は緯度経度の範囲だけで検索される
+    rating_average DOUBLE NOT NULL DEFAULT 0,
+    rating_count INT NOT NULL DEFAULT 0,
+    rating_sum DOUBLE NOT NULL DEFAULT 0,
+    rating_histogram JSON, -- 星1〜5それぞれの評価数。NULLは評価なし
+    INDEX idx_spot_geohash (geohash),
+    INDEX idx_spot_lat_lng (lat, lng),
+    INDEX idx_spot_rating_average (rating_average),
+    FULLTEXT INDEX ftx_spot_keyword (name, address, description) WITH PARSER ngram -- 日本語を検索できるようngramパーサーを使用
 );
 
 CREATE TABLE Comment (
@@ -35,8 +54,16 @@ CREATE TABLE Comment (
     star_rate DECIMAL(2,1) NOT NULL,
     text TEXT NOT NULL,
     created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    parent_id CHAR(36) NULL, -- 返信先のコメント。レビューではNULL
+    root_id CHAR(36) NULL, -- スレッドの起点のレビュー。レビューではNULL
+    depth INT NOT NULL DEFAULT 0,
+    kind VARCHAR(20) NOT NULL DEFAULT 'review', -- review, reply, owner_response
     FOREIGN KEY (spot_id) REFERENCES Spot(id) ON DELETE CASCADE,
-    FOREIGN KEY (user_id) REFERENCES User(id) ON DELETE CASCADE
+    FOREIGN KEY (user_id) REFERENCES User(id) ON DELETE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES Comment(id) ON DELETE CASCADE,
+    FOREIGN KEY (root_id) REFERENCES Comment(id) ON DELETE CASCADE,
+    INDEX idx_comment_spot_created (spot_id, created),
+    INDEX idx_comment_root_created (root_id, created)
 );
 
 CREATE TABLE Image (
@@ -45,6 +72,60 @@ CREATE TABLE Image (
     user_id CHAR(36) NOT NULL,
     url VARCHAR(255) NOT NULL,
     created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    storage_key VARCHAR(255) NOT NULL DEFAULT '', -- アップロードされた画像の保存先のキー
+    width INT NOT NULL DEFAULT 0,
+    height INT NOT NULL DEFAULT 0,
+    thumbnail_url VARCHAR(255) NOT NULL DEFAULT '',
+    thumbnail_webp_url VARCHAR(255) NOT NULL DEFAULT '',
+    medium_url VARCHAR(255) NOT NULL DEFAULT '',
+    medium_webp_url VARCHAR(255) NOT NULL DEFAULT '',
+    large_url VARCHAR(255) NOT NULL DEFAULT '',
+    large_webp_url VARCHAR(255) NOT NULL DEFAULT '',
+    blurhash VARCHAR(64) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'approved', -- pending, approved, rejected。既存の画像は承認済みとして扱う
     FOREIGN KEY (spot_id) REFERENCES Spot(id) ON DELETE CASCADE,
-    FOREIGN KEY (user_id) REFERENCES User(id) ON DELETE CASCADE
+    FOREIGN KEY (user_id) REFERENCES User(id) ON DELETE CASCADE,
+    INDEX idx_image_spot_created (spot_id, created),
+    INDEX idx_image_status_created (status, created)
+);
+
+CREATE TABLE ImageReport (
+    id CHAR(36) PRIMARY KEY, -- UUIDは36文字の文字列として格納されます
+    image_id CHAR(36) NOT NULL,
+    user_id CHAR(36) NOT NULL,
+    reason VARCHAR(500) NOT NULL,
+    created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (image_id) REFERENCES Image(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES User(id) ON DELETE CASCADE,
+    UNIQUE INDEX uq_image_report_image_user (image_id, user_id)
+);
+
+CREATE TABLE Identity (
+    id CHAR(36) PRIMARY KEY, -- UUIDは36文字の文字列として格納されます
+    user_id CHAR(36) NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL, -- IDプロバイダ内で一意なユーザID(IDトークンのsub)
+    email VARCHAR(150) NOT NULL DEFAULT '',
+    created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES User(id) ON DELETE CASCADE,
+    UNIQUE INDEX uq_identity_provider_subject (provider, subject)
+);
+
+CREATE TABLE RecoveryCode (
+    id CHAR(36) PRIMARY KEY, -- UUIDは36文字の文字列として格納されます
+    user_id CHAR(36) NOT NULL,
+    code_hash CHAR(64) NOT NULL, -- リカバリーコードのSHA-256ハッシュ
+    created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES User(id) ON DELETE CASCADE,
+    INDEX idx_recovery_code_user_id (user_id)
+);
+
+CREATE TABLE SpotOwner (
+    id CHAR(36) PRIMARY KEY, -- UUIDは36文字の文字列として格納されます
+    spot_id CHAR(36) NOT NULL,
+    user_id CHAR(36) NOT NULL,
+    created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (spot_id) REFERENCES Spot(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES User(id) ON DELETE CASCADE,
+    UNIQUE INDEX uq_spot_owner_spot_user (spot_id, user_id)
 );
\ No newline at end of file